@@ -237,7 +237,7 @@ func (h *UserHandlers) CreateUser(c *gin.Context) {
 	}
 
 	// Validar datos
-	validator := validation.NewValidator()
+	validator := validation.FromContext(c)
 	if err := validator.ValidateStruct(req); err != nil {
 		validationErrors := validation.FormatValidationErrors(err)
 		var responseErrors []responses.ResponseValidationError
@@ -283,7 +283,7 @@ func (h *UserHandlers) UpdateUser(c *gin.Context) {
 	}
 
 	// Validar datos
-	validator := validation.NewValidator()
+	validator := validation.FromContext(c)
 	if err := validator.ValidateStruct(req); err != nil {
 		validationErrors := validation.FormatValidationErrors(err)
 		var responseErrors []responses.ResponseValidationError
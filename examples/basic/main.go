@@ -84,6 +84,7 @@ func (h *ItemHandler) GetItemByID(c *gin.Context) {
 // @Produce      json
 // @Param        item  body      models.Item  true  "Datos del nuevo item"
 // @Success      201   {object}  models.Item
+// @Failure      409   {object}  map[string]string
 // @Router       /api/v1/items [post]
 func (h *ItemHandler) CreateItem(c *gin.Context) {
 	var newItem models.Item
@@ -92,7 +93,10 @@ func (h *ItemHandler) CreateItem(c *gin.Context) {
 		return
 	}
 
-	h.store.Create(newItem)
+	if err := h.store.Create(newItem); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Ya existe un item con ese ID"})
+		return
+	}
 	c.JSON(http.StatusCreated, newItem)
 }
 
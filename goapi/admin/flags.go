@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// ErrUnknownFlag is returned by Flags.Set for flags that were not declared
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Flag is a feature that can be switched at runtime
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// Flags holds the feature flags of the API, in memory: every instance has its own
+type Flags struct {
+	mutex sync.RWMutex
+	flags map[string]Flag
+}
+
+// NewFlags declares flags with their initial state
+func NewFlags(flags ...Flag) *Flags {
+	f := &Flags{flags: make(map[string]Flag, len(flags))}
+	for _, flag := range flags {
+		f.flags[flag.Name] = flag
+	}
+	return f
+}
+
+// Enabled reports whether a flag is on; unknown flags are off
+func (f *Flags) Enabled(name string) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.flags[name].Enabled
+}
+
+// Get returns a flag
+func (f *Flags) Get(name string) (Flag, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	flag, found := f.flags[name]
+	return flag, found
+}
+
+// Set switches a declared flag
+func (f *Flags) Set(name string, enabled bool) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	flag, found := f.flags[name]
+	if !found {
+		return ErrUnknownFlag
+	}
+	flag.Enabled = enabled
+	f.flags[name] = flag
+	return nil
+}
+
+// List returns the flags by name
+func (f *Flags) List() []Flag {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	flags := make([]Flag, 0, len(f.flags))
+	for _, flag := range f.flags {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags
+}
+
+// Require answers 404 while a flag is off, to hide the routes of unreleased features
+//
+//	api.GET("/beta/reports", reports, goapi.WithMiddleware(flags.Require("reports")))
+func (f *Flags) Require(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !f.Enabled(name) {
+			responses.NotFound(c, i18n.M(i18n.KeyNotFound))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Maintenance switches the API to maintenance: requests get a 503 with Retry-After, except those
+// to the exempt paths (the dashboard)
+type Maintenance struct {
+	mutex      sync.RWMutex
+	enabled    bool
+	message    string
+	retryAfter time.Duration
+	since      time.Time
+}
+
+// maintenanceKey marks the requests rejected by the maintenance mode, left out of the ErrorLog
+const maintenanceKey = "goapi.admin.maintenance"
+
+// MaintenanceState is the state of the maintenance mode
+type MaintenanceState struct {
+	Enabled    bool       `json:"enabled"`
+	Message    string     `json:"message,omitempty"`
+	RetryAfter int        `json:"retry_after,omitempty"` // Seconds announced to the clients
+	Since      *time.Time `json:"since,omitempty"`
+}
+
+// Set switches the maintenance mode; message is returned to the clients (default
+// "Service under maintenance")
+func (m *Maintenance) Set(enabled bool, message string, retryAfter time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if enabled && !m.enabled {
+		m.since = time.Now()
+	}
+	m.enabled, m.message, m.retryAfter = enabled, message, retryAfter
+}
+
+// State returns the state of the maintenance mode
+func (m *Maintenance) State() MaintenanceState {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	state := MaintenanceState{Enabled: m.enabled}
+	if m.enabled {
+		since := m.since
+		state.Message, state.RetryAfter, state.Since = m.message, int(m.retryAfter/time.Second), &since
+	}
+	return state
+}
+
+// Middleware rejects the requests during maintenance, except those to the exempt path prefixes
+func (m *Maintenance) Middleware(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := m.State()
+		if !state.Enabled {
+			c.Next()
+			return
+		}
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+		c.Set(maintenanceKey, true)
+		if state.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(state.RetryAfter))
+		}
+		message := state.Message
+		if message == "" {
+			message = "Service under maintenance"
+		}
+		responses.JSON(c, http.StatusServiceUnavailable, responses.ErrorResponse{
+			Detail:    i18n.Localize(c, message),
+			Type:      "maintenance",
+			RequestID: c.GetString(responses.RequestIDKey),
+		})
+		c.Abort()
+	}
+}
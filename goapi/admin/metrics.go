@@ -0,0 +1,210 @@
+package admin
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+)
+
+// Metrics counts the requests of every route, in memory
+type Metrics struct {
+	started  time.Time
+	inFlight atomic.Int64
+
+	mutex  sync.Mutex
+	routes map[string]*RouteMetrics
+	second int64     // Unix second of counts[second%60]
+	counts [60]int64 // Requests of each of the last 60 seconds
+}
+
+// RouteMetrics is the traffic of a route
+type RouteMetrics struct {
+	Method       string  `json:"method"`
+	Path         string  `json:"path"`
+	Requests     int64   `json:"requests"`
+	ClientErrors int64   `json:"client_errors"` // 4xx responses
+	ServerErrors int64   `json:"server_errors"` // 5xx responses
+	AverageMS    float64 `json:"average_ms"`
+	MaxMS        float64 `json:"max_ms"`
+
+	total time.Duration
+}
+
+// MetricsSnapshot is the traffic of the API
+type MetricsSnapshot struct {
+	Uptime            string         `json:"uptime"`
+	InFlight          int64          `json:"in_flight"`
+	Requests          int64          `json:"requests"`
+	RequestsPerSecond float64        `json:"requests_per_second"` // Over the last minute
+	Routes            []RouteMetrics `json:"routes"`              // Busiest first
+}
+
+// NewMetrics creates empty metrics
+func NewMetrics() *Metrics {
+	return &Metrics{started: time.Now(), routes: make(map[string]*RouteMetrics)}
+}
+
+// Middleware records the requests; requests to unknown routes are not recorded
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		m.inFlight.Add(1)
+		start := time.Now()
+		defer func() {
+			m.inFlight.Add(-1)
+			status, recovered := c.Writer.Status(), recover()
+			if recovered != nil {
+				// El recovery responde 500 después, fuera de este middleware
+				status = 500
+			}
+			if route := goapi.CurrentRoute(c); route != nil {
+				m.record(route.Method, route.Path, status, time.Since(start))
+			}
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// record adds a request to the metrics of its route
+func (m *Metrics) record(method, path string, status int, duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key := method + " " + path
+	route, exists := m.routes[key]
+	if !exists {
+		route = &RouteMetrics{Method: method, Path: path}
+		m.routes[key] = route
+	}
+	route.Requests++
+	route.total += duration
+	if milliseconds := float64(duration) / float64(time.Millisecond); milliseconds > route.MaxMS {
+		route.MaxMS = milliseconds
+	}
+	switch {
+	case status >= 500:
+		route.ServerErrors++
+	case status >= 400:
+		route.ClientErrors++
+	}
+
+	now := time.Now().Unix()
+	m.advance(now)
+	m.counts[now%60]++
+}
+
+// advance clears the seconds elapsed since the last request; the caller holds the mutex
+func (m *Metrics) advance(now int64) {
+	for second := max(m.second+1, now-59); second <= now; second++ {
+		m.counts[second%60] = 0
+	}
+	m.second = max(m.second, now)
+}
+
+// Snapshot returns the current metrics
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	snapshot := MetricsSnapshot{
+		Uptime:   time.Since(m.started).Round(time.Second).String(),
+		InFlight: m.inFlight.Load(),
+		Routes:   make([]RouteMetrics, 0, len(m.routes)),
+	}
+	for _, route := range m.routes {
+		metrics := *route
+		metrics.AverageMS = float64(route.total) / float64(route.Requests) / float64(time.Millisecond)
+		snapshot.Routes = append(snapshot.Routes, metrics)
+		snapshot.Requests += route.Requests
+	}
+	sort.Slice(snapshot.Routes, func(i, j int) bool {
+		if snapshot.Routes[i].Requests != snapshot.Routes[j].Requests {
+			return snapshot.Routes[i].Requests > snapshot.Routes[j].Requests
+		}
+		return snapshot.Routes[i].Method+snapshot.Routes[i].Path < snapshot.Routes[j].Method+snapshot.Routes[j].Path
+	})
+
+	m.advance(time.Now().Unix())
+	var lastMinute int64
+	for _, count := range m.counts {
+		lastMinute += count
+	}
+	window := min(60, max(1, time.Since(m.started).Seconds()))
+	snapshot.RequestsPerSecond = float64(lastMinute) / window
+	return snapshot
+}
+
+// ErrorEntry is an error of a request
+type ErrorEntry struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	Error     string    `json:"error"`
+	Panic     bool      `json:"panic"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// ErrorLog keeps the last errors of the API, fed by goapi.OnError
+type ErrorLog struct {
+	mutex   sync.Mutex
+	entries []ErrorEntry
+	next    int
+	size    int
+}
+
+// NewErrorLog creates a log keeping the last size errors
+func NewErrorLog(size int) *ErrorLog {
+	if size <= 0 {
+		size = 50
+	}
+	return &ErrorLog{size: size}
+}
+
+// Hook is the goapi.ErrorHook recording the errors; the rejections of the maintenance mode are not
+// recorded
+func (l *ErrorLog) Hook(c *gin.Context, err error, _ []byte) {
+	if c.GetBool(maintenanceKey) {
+		return
+	}
+	entry := ErrorEntry{
+		Time:      time.Now(),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Status:    c.Writer.Status(),
+		Error:     err.Error(),
+		RequestID: middleware.GetRequestID(c),
+	}
+	var panicked *middleware.PanicError
+	if entry.Panic = errors.As(err, &panicked); entry.Panic || entry.Status < 500 {
+		// El hook corre antes de que se envíe el 500
+		entry.Status = 500
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if len(l.entries) < l.size {
+		l.entries = append(l.entries, entry)
+	} else {
+		l.entries[l.next] = entry
+	}
+	l.next = (l.next + 1) % l.size
+}
+
+// Entries returns the errors, newest first
+func (l *ErrorLog) Entries() []ErrorEntry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	entries := make([]ErrorEntry, 0, len(l.entries))
+	for i := 1; i <= len(l.entries); i++ {
+		entries = append(entries, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+	return entries
+}
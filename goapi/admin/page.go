@@ -0,0 +1,193 @@
+package admin
+
+import "html/template"
+
+// dashboardData is the data of the dashboard page
+type dashboardData struct {
+	Path string
+}
+
+// dashboardPage renders the dashboard; its script polls {path}/api/state and renders the values
+// as text, never as HTML, since paths and errors come from the clients
+var dashboardPage = template.Must(template.New("admin").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Admin</title>
+    <meta charset="utf-8"/>
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/bootstrap@5.1.3/dist/css/bootstrap.min.css">
+    <style>
+        body { background-color: #f5f5f5; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; }
+        .header { background-color: #1a1a1a; color: white; padding: 16px 20px; display: flex; justify-content: space-between; align-items: center; }
+        .header h1 { font-size: 1.4rem; margin: 0; }
+        .card { margin-bottom: 20px; box-shadow: 0 1px 3px rgba(0,0,0,0.1); border: none; }
+        .stat { font-size: 1.8rem; font-weight: bold; }
+        .maintenance-on { background-color: #f93e3e; }
+        td, th { font-size: 0.85rem; }
+        .mono { font-family: SFMono-Regular, Menlo, monospace; }
+    </style>
+</head>
+<body data-path="{{.Path}}">
+    <div class="header" id="header">
+        <h1>Admin dashboard</h1>
+        <span id="updated" class="small"></span>
+    </div>
+    <div class="container-fluid mt-3">
+        <div class="row">
+            <div class="col-md-3"><div class="card"><div class="card-body"><div class="text-muted">Uptime</div><div class="stat" id="uptime">-</div></div></div></div>
+            <div class="col-md-3"><div class="card"><div class="card-body"><div class="text-muted">Requests</div><div class="stat" id="requests">-</div></div></div></div>
+            <div class="col-md-3"><div class="card"><div class="card-body"><div class="text-muted">Requests/s (last minute)</div><div class="stat" id="rate">-</div></div></div></div>
+            <div class="col-md-3"><div class="card"><div class="card-body"><div class="text-muted">In flight</div><div class="stat" id="in-flight">-</div></div></div></div>
+        </div>
+        <div class="row">
+            <div class="col-md-6">
+                <div class="card"><div class="card-body">
+                    <h5>Maintenance</h5>
+                    <p id="maintenance-state" class="mb-2"></p>
+                    <div class="input-group input-group-sm">
+                        <input class="form-control" id="maintenance-message" placeholder="Message for the clients">
+                        <input class="form-control" id="maintenance-retry" type="number" min="0" placeholder="Retry-After (s)">
+                        <button class="btn btn-dark" id="maintenance-toggle">Toggle</button>
+                    </div>
+                </div></div>
+                <div class="card"><div class="card-body">
+                    <h5>Feature flags</h5>
+                    <table class="table table-sm"><tbody id="flags"></tbody></table>
+                </div></div>
+                <div class="card"><div class="card-body">
+                    <h5>Rate limits</h5>
+                    <table class="table table-sm">
+                        <thead><tr><th>Limiter</th><th>Client</th><th>Remaining</th></tr></thead>
+                        <tbody id="rate-limits"></tbody>
+                    </table>
+                </div></div>
+            </div>
+            <div class="col-md-6">
+                <div class="card"><div class="card-body">
+                    <h5>Recent errors</h5>
+                    <table class="table table-sm">
+                        <thead><tr><th>Time</th><th>Request</th><th>Status</th><th>Error</th></tr></thead>
+                        <tbody id="errors"></tbody>
+                    </table>
+                </div></div>
+            </div>
+        </div>
+        <div class="card"><div class="card-body">
+            <h5>Routes</h5>
+            <table class="table table-sm">
+                <thead><tr><th>Method</th><th>Path</th><th>Requests</th><th>4xx</th><th>5xx</th><th>Average ms</th><th>Max ms</th><th>Handler</th></tr></thead>
+                <tbody id="routes"></tbody>
+            </table>
+        </div></div>
+    </div>
+    <script>
+    var path = document.body.dataset.path;
+    var maintenance = {enabled: false};
+
+    // row adds a table row whose cells are set as text
+    function row(body, cells) {
+        var tr = document.createElement('tr');
+        cells.forEach(function (cell) {
+            var td = document.createElement('td');
+            if (cell instanceof Node) {
+                td.appendChild(cell);
+            } else {
+                td.textContent = cell;
+            }
+            tr.appendChild(td);
+        });
+        body.appendChild(tr);
+    }
+
+    function table(id) {
+        var body = document.getElementById(id);
+        body.replaceChildren();
+        return body;
+    }
+
+    function put(url, value) {
+        return fetch(url, {
+            method: 'PUT',
+            credentials: 'same-origin',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify(value)
+        }).then(refresh);
+    }
+
+    function render(state) {
+        var metrics = state.metrics;
+        document.getElementById('uptime').textContent = metrics.uptime;
+        document.getElementById('requests').textContent = metrics.requests;
+        document.getElementById('rate').textContent = metrics.requests_per_second.toFixed(2);
+        document.getElementById('in-flight').textContent = metrics.in_flight;
+        document.getElementById('updated').textContent = 'Updated ' + new Date(state.time).toLocaleTimeString();
+
+        maintenance = state.maintenance;
+        document.getElementById('header').classList.toggle('maintenance-on', maintenance.enabled);
+        document.getElementById('maintenance-state').textContent = maintenance.enabled
+            ? 'On since ' + new Date(maintenance.since).toLocaleString() + (maintenance.message ? ': ' + maintenance.message : '')
+            : 'Off: requests are served';
+
+        var flags = table('flags');
+        state.flags.forEach(function (flag) {
+            var toggle = document.createElement('input');
+            toggle.type = 'checkbox';
+            toggle.className = 'form-check-input';
+            toggle.checked = flag.enabled;
+            toggle.addEventListener('change', function () {
+                put(path + '/api/flags/' + encodeURIComponent(flag.name), {enabled: toggle.checked});
+            });
+            row(flags, [toggle, flag.name, flag.description || '']);
+        });
+        if (!state.flags.length) {
+            row(flags, ['No feature flags declared']);
+        }
+
+        var limits = table('rate-limits');
+        Object.keys(state.rate_limits).sort().forEach(function (name) {
+            state.rate_limits[name].forEach(function (bucket) {
+                row(limits, [name, bucket.key, bucket.remaining + ' / ' + bucket.limit]);
+            });
+        });
+        if (!limits.children.length) {
+            row(limits, ['No client is below its limit', '', '']);
+        }
+
+        var errors = table('errors');
+        state.errors.forEach(function (entry) {
+            row(errors, [new Date(entry.time).toLocaleTimeString(), entry.method + ' ' + entry.path,
+                entry.status + (entry.panic ? ' (panic)' : ''), entry.error]);
+        });
+        if (!state.errors.length) {
+            row(errors, ['No errors', '', '', '']);
+        }
+
+        var traffic = {};
+        metrics.routes.forEach(function (route) { traffic[route.method + ' ' + route.path] = route; });
+        var routes = table('routes');
+        state.routes.forEach(function (route) {
+            var stats = traffic[route.method + ' ' + route.path] || {requests: 0, client_errors: 0, server_errors: 0, average_ms: 0, max_ms: 0};
+            row(routes, [route.method, route.path, stats.requests, stats.client_errors, stats.server_errors,
+                stats.average_ms.toFixed(1), stats.max_ms.toFixed(1), route.handler]);
+        });
+    }
+
+    function refresh() {
+        return fetch(path + '/api/state', {credentials: 'same-origin'})
+            .then(function (response) { return response.json(); })
+            .then(function (body) { render(body.data); })
+            .catch(function (err) { document.getElementById('updated').textContent = 'Update failed: ' + err; });
+    }
+
+    document.getElementById('maintenance-toggle').addEventListener('click', function () {
+        put(path + '/api/maintenance', {
+            enabled: !maintenance.enabled,
+            message: document.getElementById('maintenance-message').value,
+            retry_after: parseInt(document.getElementById('maintenance-retry').value, 10) || 0
+        });
+    });
+    refresh();
+    setInterval(refresh, 2000);
+    </script>
+</body>
+</html>
+`))
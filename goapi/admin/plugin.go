@@ -0,0 +1,250 @@
+// Package admin serves an HTML dashboard to operate a running API
+// The dashboard shows live metrics, the recent errors, the routes and the clients close to their
+// rate limits, and switches the feature flags and the maintenance mode. It is disabled until
+// Enabled is set, and only serves the users Authorize accepts (default: role "admin"), so an
+// authentication middleware must run first. Metrics, flags and maintenance live in memory: every
+// instance has its own
+//
+//	dashboard := admin.NewPlugin()
+//	dashboard.Enabled = os.Getenv("ADMIN_DASHBOARD") == "on"
+//	dashboard.Flags = admin.NewFlags(admin.Flag{Name: "new-checkout", Description: "Checkout v2"})
+//	api.UsePlugin(dashboard)
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// Plugin serves the dashboard of a GoAPI instance
+type Plugin struct {
+	// Enabled serves the dashboard; a disabled plugin installs nothing (false with NewPlugin)
+	Enabled bool
+	Path    string // Path of the dashboard (default "/admin")
+	// Authorize decides who can use the dashboard (default: users with the "admin" role)
+	Authorize func(c *gin.Context, user *dependencies.CurrentUser) bool
+
+	Metrics     *Metrics
+	Errors      *ErrorLog
+	Flags       *Flags
+	Maintenance *Maintenance
+	// MaintenanceExempt lists path prefixes served during maintenance besides the dashboard, such
+	// as the login and health endpoints
+	MaintenanceExempt []string
+	Options           []router.RouteOption // Added to every endpoint
+
+	api *goapi.GoAPI
+}
+
+// NewPlugin creates a disabled dashboard
+func NewPlugin() *Plugin {
+	return &Plugin{
+		Path: "/admin",
+		Authorize: func(_ *gin.Context, user *dependencies.CurrentUser) bool {
+			return user.HasRole("admin")
+		},
+		Metrics:     NewMetrics(),
+		Errors:      NewErrorLog(50),
+		Flags:       NewFlags(),
+		Maintenance: &Maintenance{},
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "admin"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	if !p.Enabled {
+		return nil
+	}
+	if p.Authorize == nil {
+		return errors.New("admin: the dashboard needs an Authorize function")
+	}
+	if p.Metrics == nil {
+		p.Metrics = NewMetrics()
+	}
+	if p.Errors == nil {
+		p.Errors = NewErrorLog(50)
+	}
+	if p.Flags == nil {
+		p.Flags = NewFlags()
+	}
+	if p.Maintenance == nil {
+		p.Maintenance = &Maintenance{}
+	}
+	p.api = api
+	path := strings.TrimSuffix(p.Path, "/")
+
+	api.AddMiddleware(p.Metrics.Middleware())
+	api.AddMiddleware(p.Maintenance.Middleware(append([]string{path}, p.MaintenanceExempt...)...))
+	api.OnError(p.Errors.Hook)
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Flags, nil
+	}, (*Flags)(nil))
+
+	api.GET(path, p.page, p.options(
+		goapi.WithSummary("Admin dashboard"),
+		router.WithProduces("text/html"),
+	)...)
+	api.GET(path+"/api/state", p.state, p.options(
+		goapi.WithSummary("State of the admin dashboard"),
+		goapi.WithDescription("Metrics, recent errors, routes, rate limits, feature flags and maintenance mode"),
+		goapi.WithResponseModel(http.StatusOK, State{}, "State"),
+	)...)
+	api.PUT(path+"/api/maintenance", p.setMaintenance, p.options(
+		goapi.WithSummary("Switch the maintenance mode"),
+		goapi.WithRequestBody(MaintenanceRequest{}, "Maintenance mode"),
+		goapi.WithResponseModel(http.StatusOK, MaintenanceState{}, "Maintenance mode"),
+	)...)
+	api.PUT(path+"/api/flags/:name", p.setFlag, p.options(
+		goapi.WithSummary("Switch a feature flag"),
+		goapi.WithPathParameter("name", "string", "Flag name"),
+		goapi.WithRequestBody(FlagRequest{}, "State of the flag"),
+		goapi.WithResponseModel(http.StatusOK, Flag{}, "Flag"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown flag"),
+	)...)
+	return nil
+}
+
+// options adds the common options of the endpoints
+func (p *Plugin) options(options ...router.RouteOption) []router.RouteOption {
+	options = append(options,
+		goapi.WithTags("admin"),
+		goapi.WithAuthRequired(),
+		goapi.WithMiddleware(p.authorize),
+		goapi.WithResponse(http.StatusForbidden, "Not an administrator"),
+	)
+	return append(options, p.Options...)
+}
+
+// authorize rejects the requests of users Authorize does not accept
+func (p *Plugin) authorize(c *gin.Context) {
+	user, err := dependencies.Resolve[*dependencies.CurrentUser](p.api.GetDependencyContainer(), c)
+	if err != nil || user == nil {
+		responses.Unauthorized(c, i18n.M(i18n.KeyAuthenticationRequired))
+		c.Abort()
+		return
+	}
+	if !p.Authorize(c, user) {
+		responses.Forbidden(c, "Not an administrator")
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// State is the data shown by the dashboard
+type State struct {
+	Time        time.Time                               `json:"time"`
+	Metrics     MetricsSnapshot                         `json:"metrics"`
+	Errors      []ErrorEntry                            `json:"errors"`
+	Routes      []goapi.RouteInfo                       `json:"routes"`
+	RateLimits  map[string][]middleware.RateLimitBucket `json:"rate_limits"` // Clients below their limit, by limiter
+	Flags       []Flag                                  `json:"flags"`
+	Maintenance MaintenanceState                        `json:"maintenance"`
+}
+
+// maxRateLimitClients is the number of clients shown per limiter
+const maxRateLimitClients = 20
+
+// state handles GET {path}/api/state
+func (p *Plugin) state(c *gin.Context) {
+	routes := p.api.RouteTable()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	state := State{
+		Time:        time.Now(),
+		Metrics:     p.Metrics.Snapshot(),
+		Errors:      p.Errors.Entries(),
+		Routes:      routes,
+		RateLimits:  make(map[string][]middleware.RateLimitBucket),
+		Flags:       p.Flags.List(),
+		Maintenance: p.Maintenance.State(),
+	}
+	for name, limiter := range p.api.RateLimiters() {
+		buckets := limiter.Buckets()
+		if len(buckets) > maxRateLimitClients {
+			buckets = buckets[:maxRateLimitClients]
+		}
+		for i := range buckets {
+			buckets[i].Key = maskKey(buckets[i].Key)
+		}
+		state.RateLimits[name] = buckets
+	}
+	c.Header("Cache-Control", "no-store")
+	responses.Success(c, state)
+}
+
+// MaintenanceRequest is the body switching the maintenance mode
+type MaintenanceRequest struct {
+	Enabled    bool   `json:"enabled"`
+	Message    string `json:"message,omitempty" example:"Back at 10:00 UTC"`
+	RetryAfter int    `json:"retry_after,omitempty"` // Seconds announced with Retry-After
+}
+
+// setMaintenance handles PUT {path}/api/maintenance
+func (p *Plugin) setMaintenance(c *gin.Context) {
+	var request MaintenanceRequest
+	if !goapi.DecodeJSONBody(c, &request) {
+		return
+	}
+	p.Maintenance.Set(request.Enabled, request.Message, time.Duration(request.RetryAfter)*time.Second)
+	responses.Success(c, p.Maintenance.State())
+}
+
+// FlagRequest is the body switching a feature flag
+type FlagRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// setFlag handles PUT {path}/api/flags/:name
+func (p *Plugin) setFlag(c *gin.Context) {
+	var request FlagRequest
+	if !goapi.DecodeJSONBody(c, &request) {
+		return
+	}
+	if err := p.Flags.Set(c.Param("name"), request.Enabled); errors.Is(err, ErrUnknownFlag) {
+		responses.NotFound(c, "Feature flag not found")
+		return
+	}
+	flag, _ := p.Flags.Get(c.Param("name"))
+	responses.Success(c, flag)
+}
+
+// page handles GET {path}
+func (p *Plugin) page(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline'; style-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net; frame-ancestors 'none'")
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardPage.Execute(c.Writer, dashboardData{Path: strings.TrimSuffix(p.Path, "/")}); err != nil {
+		c.Error(err)
+	}
+}
+
+// maskKey hides the header values used as rate limit keys (KeyByHeader), which may be credentials
+func maskKey(key string) string {
+	if value, found := strings.CutPrefix(key, "header:"); found && len(value) > 6 {
+		return "header:" + value[:6] + "…"
+	}
+	return key
+}
@@ -0,0 +1,277 @@
+// Package apikeys issues, verifies, rotates and revokes API keys
+// A key reads "gk_3f9a1c2e_<secret>": its ID, the part before the last underscore, is kept in the
+// clear to find it, show it in listings and let secret scanners recognize leaked keys; the whole
+// key is only stored as a SHA-256 hash and shown once, when issued. The Manager's middleware
+// authenticates the X-API-Key header as the owner of the key, and QuotaConfig limits the keys by
+// the quota plan they were issued with
+//
+//	manager := apikeys.NewManager(apikeys.NewMemoryStore())
+//	limiter := quota.NewLimiter(manager.QuotaConfig(freePlan, proPlan))
+//	keys := apikeys.NewPlugin(manager)
+//	keys.Path = "/admin/api-keys"
+//	keys.Options = []router.RouteOption{authz.WithRequiredRoles("admin")}
+//	keys.Quota = limiter
+//	api.UsePlugin(keys)
+//	api.UsePlugin(quota.NewPlugin(limiter))
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/quota"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// KeyContextKey is the context key holding the verified Key of the request
+const KeyContextKey = "goapi.api_key"
+
+// Errors returned by the Manager
+var (
+	// ErrInvalidKey is returned by Verify for malformed, unknown, revoked and expired keys
+	ErrInvalidKey = errors.New("invalid API key")
+	// ErrKeyRevoked is returned when rotating or revoking a key that is no longer valid
+	ErrKeyRevoked = errors.New("API key revoked or expired")
+)
+
+// Key is an API key as stored, without its secret
+type Key struct {
+	ID          string     `json:"id"`             // "gk_3f9a1c2e", the start of the key
+	Hash        string     `json:"hash,omitempty"` // SHA-256 of the whole key, never sent by the endpoints
+	Name        string     `json:"name"`
+	Owner       string     `json:"owner,omitempty"` // User the key acts as (default: the key itself)
+	Plan        string     `json:"plan,omitempty"`  // Quota plan (see QuotaConfig)
+	Scopes      []string   `json:"scopes,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	RotatedFrom string     `json:"rotated_from,omitempty"` // Key replaced by this one
+}
+
+// Active reports whether the key is neither revoked nor expired
+func (k Key) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// Manager issues and verifies the keys of a store
+type Manager struct {
+	Store  Store
+	Prefix string // Start of the keys (default "gk")
+	Header string // Header carrying the keys (default "X-API-Key")
+	// RotationGrace is how long a rotated key keeps working, so clients can switch (default 24 hours)
+	RotationGrace time.Duration
+}
+
+// NewManager creates a manager of the keys of a store
+func NewManager(store Store) *Manager {
+	return &Manager{Store: store, Prefix: "gk", Header: "X-API-Key", RotationGrace: 24 * time.Hour}
+}
+
+// Issue creates a key from a template (Name, Owner, Plan, Scopes, ExpiresAt) and returns it with
+// the stored record; the key cannot be recovered afterwards
+func (m *Manager) Issue(ctx context.Context, template Key) (string, Key, error) {
+	id := m.prefix() + "_" + randomHex(4)
+	raw := id + "_" + randomHex(32)
+	key := template
+	key.ID = id
+	key.Hash = hashKey(raw)
+	key.CreatedAt = time.Now()
+	key.RevokedAt = nil
+	if err := m.Store.Save(ctx, key); err != nil {
+		return "", Key{}, err
+	}
+	return raw, key, nil
+}
+
+// Verify returns the key of a raw key; ErrInvalidKey when it is malformed, unknown, revoked or
+// expired
+func (m *Manager) Verify(ctx context.Context, raw string) (Key, error) {
+	separator := strings.LastIndex(raw, "_")
+	if separator <= 0 {
+		return Key{}, ErrInvalidKey
+	}
+	key, err := m.Store.Get(ctx, raw[:separator])
+	if errors.Is(err, ErrKeyNotFound) {
+		return Key{}, ErrInvalidKey
+	}
+	if err != nil {
+		return Key{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashKey(raw))) != 1 || !key.Active(time.Now()) {
+		return Key{}, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// Rotate issues a new key with the settings of an active one, which keeps working for
+// RotationGrace
+func (m *Manager) Rotate(ctx context.Context, id string) (string, Key, error) {
+	old, err := m.Store.Get(ctx, id)
+	if err != nil {
+		return "", Key{}, err
+	}
+	now := time.Now()
+	if !old.Active(now) {
+		return "", Key{}, ErrKeyRevoked
+	}
+	template := old
+	template.RotatedFrom = old.ID
+	raw, key, err := m.Issue(ctx, template)
+	if err != nil {
+		return "", Key{}, err
+	}
+	// La clave anterior caduca tras el periodo de gracia, salvo que ya caducara antes
+	if graceEnd := now.Add(m.RotationGrace); old.ExpiresAt == nil || graceEnd.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &graceEnd
+	}
+	if err := m.Store.Save(ctx, old); err != nil {
+		return "", Key{}, err
+	}
+	return raw, key, nil
+}
+
+// Revoke invalidates a key at once
+func (m *Manager) Revoke(ctx context.Context, id string) error {
+	key, err := m.Store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return ErrKeyRevoked
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return m.Store.Save(ctx, key)
+}
+
+// Middleware authenticates the requests carrying a key as the owner of the key
+// Requests without a key are left to other authentication middlewares, which let the requests
+// authenticated here through; add this one first. Invalid keys get a 401, except on routes
+// declared with router.WithAllowAnonymous
+func (m *Manager) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := strings.TrimSpace(c.GetHeader(m.header()))
+		if raw == "" {
+			c.Next()
+			return
+		}
+		key, err := m.Verify(c.Request.Context(), raw)
+		switch {
+		case err == nil:
+			SetKey(c, key)
+		case middleware.RouteAuthMode(c) == router.AuthAnonymous:
+		case errors.Is(err, ErrInvalidKey):
+			responses.JSON(c, http.StatusUnauthorized, responses.ErrorResponse{
+				Detail:    i18n.Localize(c, "Invalid API key"),
+				Type:      "authentication_error",
+				RequestID: c.GetString(responses.RequestIDKey),
+			})
+			c.Abort()
+			return
+		default:
+			responses.InternalServerError(c, "Error verifying API key")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// SetKey stores a verified key as the authentication of the request, as the middleware does
+// The owner becomes the *dependencies.CurrentUser, with the scopes of the key
+func SetKey(c *gin.Context, key Key) {
+	owner := key.Owner
+	if owner == "" {
+		owner = key.ID
+	}
+	c.Set(KeyContextKey, key)
+	c.Set(dependencies.CurrentUserKey, &dependencies.CurrentUser{ID: owner, Scopes: key.Scopes})
+	c.Set(dependencies.UserIDKey, owner)
+	// También en el contexto de la petición, donde lo leen los planes de cuota
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, key))
+}
+
+// GetKey returns the verified key of a request
+func GetKey(c *gin.Context) (Key, bool) {
+	key, ok := c.Value(KeyContextKey).(Key)
+	return key, ok
+}
+
+// contextKey is the context.Context key of the verified key
+type contextKey struct{}
+
+// QuotaConfig returns a quota configuration limiting every key by its plan; keys without a plan
+// get the first one. Quotas are counted by key ID, so Limiter.Status takes the ID, and a rotated
+// key keeps no consumption. The Manager's middleware must run before the quota's
+func (m *Manager) QuotaConfig(plans ...quota.Plan) quota.Config {
+	return quota.Config{
+		Key: func(c *gin.Context) string {
+			key, _ := GetKey(c)
+			return key.ID
+		},
+		Plans: m.Plans(plans...),
+	}
+}
+
+// Plans returns the quota plans of the keys, by key ID (see QuotaConfig)
+func (m *Manager) Plans(plans ...quota.Plan) quota.Plans {
+	return func(ctx context.Context, id string) (*quota.Plan, error) {
+		key, ok := ctx.Value(contextKey{}).(Key)
+		if !ok || key.ID != id {
+			stored, err := m.Store.Get(ctx, id)
+			if errors.Is(err, ErrKeyNotFound) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			key = stored
+		}
+		for i := range plans {
+			if plans[i].Name == key.Plan || (key.Plan == "" && i == 0) {
+				return &plans[i], nil
+			}
+		}
+		return nil, errors.New("apikeys: unknown quota plan " + key.Plan)
+	}
+}
+
+func (m *Manager) prefix() string {
+	if m.Prefix == "" {
+		return "gk"
+	}
+	return m.Prefix
+}
+
+func (m *Manager) header() string {
+	if m.Header == "" {
+		return "X-API-Key"
+	}
+	return m.Header
+}
+
+// hashKey returns the stored hash of a key
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns size random bytes in hexadecimal
+func randomHex(size int) string {
+	value := make([]byte, size)
+	_, _ = rand.Read(value)
+	return hex.EncodeToString(value)
+}
@@ -0,0 +1,248 @@
+package apikeys
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/quota"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// IssueKeyRequest is the body of the endpoint issuing keys
+type IssueKeyRequest struct {
+	Name      string     `json:"name" validate:"required" example:"CI deploys"`
+	Owner     string     `json:"owner,omitempty"` // Default: the key acts as itself
+	Plan      string     `json:"plan,omitempty" example:"pro"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// IssuedKey is a new key, the only response that contains it
+type IssuedKey struct {
+	Key    string `json:"key"`
+	APIKey Key    `json:"api_key"`
+}
+
+// Plugin wires a Manager into a GoAPI instance
+// It installs the Manager's middleware, registers *Manager as a dependency and documents the
+// X-API-Key security scheme. With a Path, it serves the admin endpoints issuing, listing,
+// rotating and revoking keys; protect them with Middlewares or Options
+type Plugin struct {
+	Manager *Manager
+	// Authenticate installs the Manager's middleware on every route (true with NewPlugin)
+	Authenticate bool
+	// Quota, when set, serves the consumption of the keys at {path}/:id/quota
+	Quota *quota.Limiter
+
+	Path         string               // Path of the admin endpoints, such as "/admin/api-keys"; empty serves none
+	Tags         []string             // Documentation tags of the endpoints
+	Middlewares  []gin.HandlerFunc    // Run before the endpoints, for example to require an administrator
+	Options      []router.RouteOption // Added to every endpoint
+	SecurityName string               // Name of the security scheme in the specification (default "APIKey")
+}
+
+// NewPlugin creates a plugin authenticating the keys of a manager
+func NewPlugin(manager *Manager) *Plugin {
+	return &Plugin{
+		Manager:      manager,
+		Authenticate: true,
+		Tags:         []string{"api-keys"},
+		SecurityName: "APIKey",
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "apikeys"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	if p.Manager == nil || p.Manager.Store == nil {
+		return errors.New("apikeys: the plugin needs a Manager with a Store")
+	}
+	if p.Authenticate {
+		api.AddMiddleware(p.Manager.Middleware())
+	}
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Manager, nil
+	}, (*Manager)(nil))
+	api.OnOpenAPIDocument(p.document)
+
+	if p.Path == "" {
+		return nil
+	}
+	idParameter := goapi.WithPathParameter("id", "string", `Key ID ("gk_3f9a1c2e")`)
+	api.POST(p.Path, p.issue, p.options(
+		goapi.WithSummary("Issue an API key"),
+		goapi.WithDescription("Creates a key. The key is only returned by this response: store it now"),
+		goapi.WithRequestBody(IssueKeyRequest{}, "Settings of the key"),
+		goapi.WithResponseModel(http.StatusCreated, IssuedKey{}, "Key issued"),
+	)...)
+	api.GET(p.Path, p.list, p.options(
+		goapi.WithSummary("List API keys"),
+		goapi.WithDescription("Lists the keys, newest first, including the revoked and expired ones"),
+		goapi.WithQueryParameter("owner", "string", "Filter by owner", false),
+		goapi.WithResponseModel(http.StatusOK, []Key{}, "Keys"),
+	)...)
+	api.GET(p.Path+"/:id", p.get, p.options(
+		goapi.WithSummary("Get an API key"),
+		idParameter,
+		goapi.WithResponseModel(http.StatusOK, Key{}, "Key"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown key"),
+	)...)
+	api.POST(p.Path+"/:id/rotate", p.rotate, p.options(
+		goapi.WithSummary("Rotate an API key"),
+		goapi.WithDescription("Issues a new key with the same settings. The old key keeps working during the rotation grace period"),
+		idParameter,
+		goapi.WithResponseModel(http.StatusCreated, IssuedKey{}, "New key"),
+		goapi.WithResponse(http.StatusConflict, "The key is revoked or expired"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown key"),
+	)...)
+	api.DELETE(p.Path+"/:id", p.revoke, p.options(
+		goapi.WithSummary("Revoke an API key"),
+		idParameter,
+		goapi.WithResponse(http.StatusNoContent, "Key revoked"),
+		goapi.WithResponse(http.StatusConflict, "The key is already revoked"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown key"),
+	)...)
+	if p.Quota != nil {
+		api.GET(p.Path+"/:id/quota", p.quota, p.options(
+			goapi.WithSummary("Get the quota of an API key"),
+			idParameter,
+			goapi.WithResponseModel(http.StatusOK, quota.Status{}, "Consumption of the current day"),
+			goapi.WithResponse(http.StatusNotFound, "Unknown key"),
+		)...)
+	}
+	return nil
+}
+
+// options adds the common options of the endpoints
+func (p *Plugin) options(options ...router.RouteOption) []router.RouteOption {
+	options = append(options, goapi.WithTags(p.Tags...), goapi.WithMiddleware(p.Middlewares...))
+	return append(options, p.Options...)
+}
+
+// document declares the X-API-Key header as a security scheme, accepted on the operations
+func (p *Plugin) document(document *openapi.Document) {
+	if document.SecurityDefinitions == nil {
+		document.SecurityDefinitions = make(map[string]*openapi.SecurityScheme)
+	}
+	name := p.SecurityName
+	if name == "" {
+		name = "APIKey"
+	}
+	document.SecurityDefinitions[name] = &openapi.SecurityScheme{
+		Type:        "apiKey",
+		Description: "API key issued by an administrator",
+		Name:        p.Manager.header(),
+		In:          "header",
+	}
+	document.Security = append(document.Security, openapi.SecurityRequirement{name: {}})
+}
+
+// issue handles POST {path}
+func (p *Plugin) issue(c *gin.Context) {
+	var request IssueKeyRequest
+	if !goapi.DecodeJSONBody(c, &request) {
+		return
+	}
+	if err := validation.FromContext(c).ValidateStruct(request); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+	raw, key, err := p.Manager.Issue(c.Request.Context(), Key{
+		Name:      request.Name,
+		Owner:     request.Owner,
+		Plan:      request.Plan,
+		Scopes:    request.Scopes,
+		ExpiresAt: request.ExpiresAt,
+	})
+	if err != nil {
+		responses.InternalServerError(c, "Error issuing API key")
+		return
+	}
+	responses.Created(c, IssuedKey{Key: raw, APIKey: public(key)})
+}
+
+// list handles GET {path}
+func (p *Plugin) list(c *gin.Context) {
+	keys, err := p.Manager.Store.List(c.Request.Context(), c.Query("owner"))
+	if err != nil {
+		responses.InternalServerError(c, "Error listing API keys")
+		return
+	}
+	for i := range keys {
+		keys[i] = public(keys[i])
+	}
+	responses.Success(c, keys)
+}
+
+// get handles GET {path}/:id
+func (p *Plugin) get(c *gin.Context) {
+	key, err := p.Manager.Store.Get(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		responses.NotFound(c, "API key not found")
+	case err != nil:
+		responses.InternalServerError(c, "Error reading API key")
+	default:
+		responses.Success(c, public(key))
+	}
+}
+
+// rotate handles POST {path}/:id/rotate
+func (p *Plugin) rotate(c *gin.Context) {
+	raw, key, err := p.Manager.Rotate(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		responses.NotFound(c, "API key not found")
+	case errors.Is(err, ErrKeyRevoked):
+		responses.Conflict(c, "Only active API keys can be rotated")
+	case err != nil:
+		responses.InternalServerError(c, "Error rotating API key")
+	default:
+		responses.Created(c, IssuedKey{Key: raw, APIKey: public(key)})
+	}
+}
+
+// revoke handles DELETE {path}/:id
+func (p *Plugin) revoke(c *gin.Context) {
+	err := p.Manager.Revoke(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		responses.NotFound(c, "API key not found")
+	case errors.Is(err, ErrKeyRevoked):
+		responses.Conflict(c, "API key already revoked")
+	case err != nil:
+		responses.InternalServerError(c, "Error revoking API key")
+	default:
+		responses.NoContent(c)
+	}
+}
+
+// quota handles GET {path}/:id/quota
+func (p *Plugin) quota(c *gin.Context) {
+	status, err := p.Quota.Status(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, quota.ErrUnknownKey):
+		responses.NotFound(c, "API key not found")
+	case err != nil:
+		responses.InternalServerError(c, "Error reading quota")
+	default:
+		responses.Success(c, status)
+	}
+}
+
+// public drops the hash of a key before sending it
+func public(key Key) Key {
+	key.Hash = ""
+	return key
+}
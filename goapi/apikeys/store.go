@@ -0,0 +1,68 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrKeyNotFound is returned by the stores for unknown key IDs
+var ErrKeyNotFound = errors.New("API key not found")
+
+// Store keeps the API keys, by ID
+// Revoked and expired keys stay in the store, so listings keep their history
+type Store interface {
+	// Save creates or replaces a key
+	Save(ctx context.Context, key Key) error
+	// Get returns a key; ErrKeyNotFound when it is unknown
+	Get(ctx context.Context, id string) (Key, error)
+	// List returns the keys of an owner, every key when owner is "", newest first
+	List(ctx context.Context, owner string) ([]Key, error)
+}
+
+// MemoryStore keeps the keys in memory, for tests and single instance deployments
+type MemoryStore struct {
+	keys  map[string]Key
+	mutex sync.RWMutex
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: make(map[string]Key)}
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(_ context.Context, key Key) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys[key.ID] = key
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(_ context.Context, id string) (Key, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	key, found := s.keys[id]
+	if !found {
+		return Key{}, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// List implements Store
+func (s *MemoryStore) List(_ context.Context, owner string) ([]Key, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	keys := make([]Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		if owner == "" || key.Owner == owner {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
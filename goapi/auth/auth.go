@@ -0,0 +1,244 @@
+// Package auth serves login, refresh and logout endpoints for a GoAPI instance
+// Logins are checked by the application's Credentials function. A successful login gets a
+// short-lived JWT access token, verified by middleware.JWTAuth, and a refresh token. Refresh
+// tokens are rotated on every use; presenting a used one again revokes every token of that login,
+// since only a stolen copy can be replayed. The endpoints follow the OAuth2 password flow, which
+// the specification documents so the "Authorize" button of the docs works
+//
+//	api.UsePlugin(auth.NewPlugin(func(ctx context.Context, username, password string) (*dependencies.CurrentUser, error) {
+//		user, err := users.Check(ctx, username, password)
+//		if err != nil {
+//			return nil, auth.ErrInvalidCredentials
+//		}
+//		return &dependencies.CurrentUser{ID: user.ID, Username: username, Roles: user.Roles}, nil
+//	}, []byte(os.Getenv("JWT_SECRET"))))
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+)
+
+// ErrInvalidCredentials is returned by Credentials functions for a wrong username or password
+// The login answers 401 without telling which one was wrong
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Authentication methods of the "amr" claim of the access tokens (RFC 8176)
+const (
+	MethodPassword = "pwd"
+	MethodOTP      = "otp"
+)
+
+// Errors of SecondFactor functions
+var (
+	// ErrSecondFactorRequired is returned when the user has a second factor and the login has no code
+	ErrSecondFactorRequired = errors.New("second factor required")
+	// ErrInvalidSecondFactor is returned for a wrong or replayed code
+	ErrInvalidSecondFactor = errors.New("invalid second factor")
+)
+
+// SecondFactor checks the one-time code of a login, after its password
+// It reports whether a second factor was verified: false, nil for users without one. See
+// mfa.Verifier
+type SecondFactor func(ctx context.Context, user *dependencies.CurrentUser, code string) (bool, error)
+
+// Credentials checks a username and password and returns the user they sign in
+type Credentials func(ctx context.Context, username, password string) (*dependencies.CurrentUser, error)
+
+// Token is the token response of the OAuth2 token endpoint (RFC 6749, section 5.1)
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Seconds the access token is valid
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// Tokens issues access tokens and rotates refresh tokens
+type Tokens struct {
+	Signer     middleware.ResponseSigner // Signs the access tokens
+	KeyID      string                    // "kid" header of the access tokens
+	Issuer     string                    // "iss" claim
+	Audience   string                    // "aud" claim
+	AccessTTL  time.Duration             // Lifetime of the access tokens (default 15 minutes)
+	RefreshTTL time.Duration             // Lifetime of the refresh tokens (default 30 days)
+	Store      Store                     // Refresh tokens (default: in memory)
+	// Lookup reloads the user when a refresh token is used, so role changes and removed users
+	// apply without a new login (nil user: the login ends); the scopes stay within those of the
+	// login. nil reuses the user of the login
+	Lookup func(ctx context.Context, user *dependencies.CurrentUser) (*dependencies.CurrentUser, error)
+}
+
+// NewTokens creates the tokens of a signer, with the default lifetimes and an in-memory store
+// HMAC signers with a secret shorter than middleware.MinHMACSecretSize issue no tokens: Issue
+// returns middleware.ErrWeakSecret
+func NewTokens(signer middleware.ResponseSigner) *Tokens {
+	return &Tokens{
+		Signer:     signer,
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 30 * 24 * time.Hour,
+		Store:      NewMemoryStore(),
+	}
+}
+
+// checkSigner rejects the HMAC signers whose secret is too short, trying a signature
+func checkSigner(signer middleware.ResponseSigner) error {
+	if !strings.HasPrefix(signer.Algorithm(), "HS") {
+		return nil
+	}
+	if _, err := signer.Sign(nil); errors.Is(err, middleware.ErrWeakSecret) {
+		return err
+	}
+	return nil
+}
+
+// Issue signs in a user: it returns an access token and the refresh token of a new login
+// methods lists how the user authenticated ("pwd", "otp"), for the "amr" claim
+func (t *Tokens) Issue(ctx context.Context, user *dependencies.CurrentUser, methods ...string) (*Token, error) {
+	return t.issue(ctx, user, login{family: newID(), methods: methods, time: time.Now()})
+}
+
+// login identifies the login a token belongs to
+type login struct {
+	family  string
+	methods []string
+	time    time.Time
+}
+
+// Refresh exchanges a refresh token for new tokens, rotating it
+// A token used twice revokes its login and returns ErrTokenReused; unknown and expired tokens
+// return ErrTokenNotFound
+func (t *Tokens) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	stored, err := t.Store.Consume(ctx, hashToken(refreshToken))
+	if errors.Is(err, ErrTokenReused) {
+		if revokeErr := t.Store.RevokeFamily(ctx, stored.Family); revokeErr != nil {
+			return nil, revokeErr
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user := stored.User
+	if t.Lookup != nil {
+		if user, err = t.Lookup(ctx, user); err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrTokenNotFound
+		}
+		// Un refresco nunca amplía los scopes concedidos en el login
+		scopes := user.Scopes[:0:0]
+		for _, scope := range user.Scopes {
+			if stored.User.HasScope(scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		reloaded := *user
+		reloaded.Scopes = scopes
+		user = &reloaded
+	}
+	return t.issue(ctx, user, login{family: stored.Family, methods: stored.Methods, time: stored.AuthTime})
+}
+
+// Revoke ends the login of a refresh token; unknown tokens are ignored
+func (t *Tokens) Revoke(ctx context.Context, refreshToken string) error {
+	stored, err := t.Store.Consume(ctx, hashToken(refreshToken))
+	if errors.Is(err, ErrTokenNotFound) {
+		return nil
+	}
+	if err != nil && !errors.Is(err, ErrTokenReused) {
+		return err
+	}
+	return t.Store.RevokeFamily(ctx, stored.Family)
+}
+
+// issue signs an access token and saves a new refresh token of a login
+func (t *Tokens) issue(ctx context.Context, user *dependencies.CurrentUser, login login) (*Token, error) {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"sub":       user.ID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(t.AccessTTL).Unix(),
+		"jti":       newID(),
+		"auth_time": login.time.Unix(),
+	}
+	if len(login.methods) > 0 {
+		claims["amr"] = login.methods
+	}
+	if user.Username != "" {
+		claims["preferred_username"] = user.Username
+	}
+	if user.Email != "" {
+		claims["email"] = user.Email
+	}
+	if len(user.Roles) > 0 {
+		claims["roles"] = user.Roles
+	}
+	if len(user.Scopes) > 0 {
+		claims["scope"] = strings.Join(user.Scopes, " ")
+	}
+	if t.Issuer != "" {
+		claims["iss"] = t.Issuer
+	}
+	if t.Audience != "" {
+		claims["aud"] = t.Audience
+	}
+	accessToken, err := middleware.SignJWT(t.Signer, t.KeyID, claims)
+	if err != nil {
+		return nil, fmt.Errorf("error signing access token: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(secret)
+	// Los claims del token de acceso no se guardan con el de refresco
+	stored := *user
+	stored.Claims = nil
+	err = t.Store.Save(ctx, RefreshToken{
+		ID:        hashToken(refreshToken),
+		Family:    login.family,
+		Methods:   login.methods,
+		AuthTime:  login.time,
+		User:      &stored,
+		CreatedAt: now,
+		ExpiresAt: now.Add(t.RefreshTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error saving refresh token: %w", err)
+	}
+
+	return &Token{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(t.AccessTTL / time.Second),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(user.Scopes, " "),
+	}, nil
+}
+
+// hashToken returns the ID under which a refresh token is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newID returns a random identifier
+func newID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
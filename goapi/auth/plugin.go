@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// Plugin serves the login, refresh and logout endpoints of an API
+// With Authenticate, it also installs middleware.JWTAuth for the access tokens it issues; its
+// endpoints are declared with WithAllowAnonymous. Key must then verify the tokens of the signer
+type Plugin struct {
+	Credentials Credentials
+	// SecondFactor, when set, checks the "otp" field of the logins (see mfa.Verifier)
+	SecondFactor SecondFactor
+	Tokens       *Tokens
+	// Key verifies the access tokens: the HMAC secret or the public key of Tokens.Signer
+	Key interface{}
+	// Authenticate installs JWTAuth on every route (true with NewPlugin)
+	Authenticate bool
+	// Optional lets requests without a token through (see middleware.JWTConfig)
+	Optional bool
+
+	Path         string               // Path of the endpoints (default "/auth")
+	Tags         []string             // Documentation tags of the endpoints
+	Options      []router.RouteOption // Added to every endpoint
+	Scopes       map[string]string    // Scopes documented in the OAuth2 security scheme, with their description
+	SecurityName string               // Name of the security scheme in the specification (default "OAuth2Password")
+}
+
+// NewPlugin creates a plugin issuing HS256 access tokens signed with secret
+// The secret needs middleware.MinHMACSecretSize bytes at least: Install rejects shorter ones
+func NewPlugin(credentials Credentials, secret []byte) *Plugin {
+	return &Plugin{
+		Credentials:  credentials,
+		Tokens:       NewTokens(middleware.NewHMACSigner(secret)),
+		Key:          secret,
+		Authenticate: true,
+		Path:         "/auth",
+		Tags:         []string{"auth"},
+		SecurityName: "OAuth2Password",
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "auth"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	if p.Credentials == nil || p.Tokens == nil || p.Tokens.Signer == nil {
+		return errors.New("auth: the plugin needs Credentials and Tokens with a Signer")
+	}
+	// Un secreto vacío (JWT_SECRET sin definir) permitiría falsificar cualquier token
+	if secret, ok := p.Key.([]byte); ok {
+		if err := middleware.CheckHMACSecret(secret); err != nil {
+			return fmt.Errorf("auth: Key: %w", err)
+		}
+	}
+	if err := checkSigner(p.Tokens.Signer); err != nil {
+		return fmt.Errorf("auth: Tokens.Signer: %w", err)
+	}
+	if p.Tokens.Store == nil {
+		p.Tokens.Store = NewMemoryStore()
+	}
+	if p.Authenticate {
+		if p.Key == nil {
+			return errors.New("auth: Authenticate needs the Key verifying the access tokens")
+		}
+		api.AddMiddleware(middleware.JWTAuth(middleware.JWTConfig{
+			Key:      p.Key,
+			Issuer:   p.Tokens.Issuer,
+			Audience: p.Tokens.Audience,
+			Optional: p.Optional,
+		}))
+	}
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Tokens, nil
+	}, (*Tokens)(nil))
+
+	refreshParameter := goapi.WithParameter("refresh_token", "formData", "string", "Refresh token of the login", true)
+	api.POST(p.Path+"/login", p.login, p.options(
+		goapi.WithSummary("Log in"),
+		goapi.WithDescription("OAuth2 password grant: exchanges a username and password for an access token and a refresh token. The fields can also be sent as JSON"),
+		goapi.WithParameter("grant_type", "formData", "string", `"password" when given`, false),
+		goapi.WithParameter("username", "formData", "string", "Username", true),
+		goapi.WithParameter("password", "formData", "string", "Password", true),
+		goapi.WithParameter("scope", "formData", "string", "Space separated scopes requested; default: every scope of the user", false),
+		goapi.WithParameter("otp", "formData", "string", "One-time code, for users with two-factor authentication", false),
+		goapi.WithResponseModel(http.StatusOK, Token{}, "Tokens"),
+		goapi.WithResponse(http.StatusUnauthorized, "Invalid username or password"),
+	)...)
+	api.POST(p.Path+"/refresh", p.refresh, p.options(
+		goapi.WithSummary("Refresh the access token"),
+		goapi.WithDescription("Exchanges a refresh token for new tokens. The refresh token is rotated: using it again revokes the login"),
+		goapi.WithParameter("grant_type", "formData", "string", `"refresh_token" when given`, false),
+		refreshParameter,
+		goapi.WithResponseModel(http.StatusOK, Token{}, "Tokens"),
+		goapi.WithResponse(http.StatusUnauthorized, "Invalid, expired or reused refresh token"),
+	)...)
+	api.POST(p.Path+"/logout", p.logout, p.options(
+		goapi.WithSummary("Log out"),
+		goapi.WithDescription("Revokes the refresh tokens of the login"),
+		refreshParameter,
+		goapi.WithResponse(http.StatusNoContent, "Logged out"),
+	)...)
+	api.OnOpenAPIDocument(p.document)
+	return nil
+}
+
+// options adds the common options of the endpoints
+func (p *Plugin) options(options ...router.RouteOption) []router.RouteOption {
+	options = append(options,
+		goapi.WithTags(p.Tags...),
+		goapi.WithAllowAnonymous(),
+		router.WithConsumes("application/x-www-form-urlencoded", "application/json"),
+	)
+	return append(options, p.Options...)
+}
+
+// document declares the OAuth2 password flow and requires it on the operations, except on the
+// endpoints of the plugin, which take credentials or refresh tokens instead of access tokens
+func (p *Plugin) document(document *openapi.Document) {
+	if document.SecurityDefinitions == nil {
+		document.SecurityDefinitions = make(map[string]*openapi.SecurityScheme)
+	}
+	scopes := p.Scopes
+	if scopes == nil {
+		scopes = map[string]string{}
+	}
+	document.SecurityDefinitions[p.SecurityName] = &openapi.SecurityScheme{
+		Type:        "oauth2",
+		Description: "Bearer access tokens issued by " + p.Path + "/login",
+		Flow:        "password",
+		TokenURL:    strings.TrimSuffix(document.BasePath, "/") + p.Path + "/login",
+		Scopes:      scopes,
+	}
+	document.Security = append(document.Security, openapi.SecurityRequirement{p.SecurityName: {}})
+
+	for _, endpoint := range []string{"/login", "/refresh", "/logout"} {
+		if item := document.Paths[p.Path+endpoint]; item != nil && item.Post != nil {
+			item.Post.Security = []openapi.SecurityRequirement{}
+		}
+	}
+}
+
+// tokenRequest is the body of the endpoints, as a form or as JSON
+type tokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type"`
+	Username     string `form:"username" json:"username"`
+	Password     string `form:"password" json:"password"`
+	Scope        string `form:"scope" json:"scope"`
+	OTP          string `form:"otp" json:"otp"`
+	RefreshToken string `form:"refresh_token" json:"refresh_token"`
+}
+
+// bindRequest reads the body of an endpoint, checking its grant type
+func bindRequest(c *gin.Context, grantType string) (tokenRequest, bool) {
+	var request tokenRequest
+	if err := c.ShouldBind(&request); err != nil {
+		responses.BadRequest(c, i18n.M(i18n.KeyInvalidRequest))
+		return request, false
+	}
+	if grantType != "" && request.GrantType != "" && request.GrantType != grantType {
+		responses.BadRequest(c, i18n.M(i18n.KeyInvalidGrantType, i18n.Params{"grant_type": grantType}))
+		return request, false
+	}
+	return request, true
+}
+
+// login handles POST {path}/login
+func (p *Plugin) login(c *gin.Context) {
+	request, ok := bindRequest(c, "password")
+	if !ok {
+		return
+	}
+	if request.Username == "" || request.Password == "" {
+		responses.BadRequest(c, i18n.M(i18n.KeyCredentialsRequired))
+		return
+	}
+	user, err := p.Credentials(c.Request.Context(), request.Username, request.Password)
+	switch {
+	case errors.Is(err, ErrInvalidCredentials) || (err == nil && user == nil):
+		responses.Unauthorized(c, i18n.M(i18n.KeyInvalidCredentials))
+		return
+	case err != nil:
+		responses.InternalServerError(c, i18n.M(i18n.KeyCredentialsError))
+		return
+	}
+
+	methods := []string{MethodPassword}
+	if p.SecondFactor != nil {
+		verified, err := p.SecondFactor(c.Request.Context(), user, request.OTP)
+		switch {
+		case errors.Is(err, ErrSecondFactorRequired):
+			// El cliente pide el código y repite el login
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_user_authentication"`)
+			responses.JSON(c, http.StatusUnauthorized, responses.ErrorResponse{
+				Detail:    i18n.Localize(c, i18n.M(i18n.KeyOTPRequired)),
+				Type:      "mfa_required",
+				RequestID: c.GetString(responses.RequestIDKey),
+			})
+			return
+		case errors.Is(err, ErrInvalidSecondFactor):
+			responses.Unauthorized(c, i18n.M(i18n.KeyInvalidOTP))
+			return
+		case err != nil:
+			responses.InternalServerError(c, i18n.M(i18n.KeyOTPError))
+			return
+		case verified:
+			methods = append(methods, MethodOTP)
+		}
+	}
+
+	// Los scopes pedidos reducen los del usuario, nunca los amplían
+	if requested := strings.Fields(request.Scope); len(requested) > 0 {
+		granted := *user
+		granted.Scopes = nil
+		for _, scope := range requested {
+			if user.HasScope(scope) {
+				granted.Scopes = append(granted.Scopes, scope)
+			}
+		}
+		user = &granted
+	}
+	token, err := p.Tokens.Issue(c.Request.Context(), user, methods...)
+	if err != nil {
+		responses.InternalServerError(c, i18n.M(i18n.KeyTokenIssueError))
+		return
+	}
+	sendToken(c, token)
+}
+
+// refresh handles POST {path}/refresh
+func (p *Plugin) refresh(c *gin.Context) {
+	request, ok := bindRequest(c, "refresh_token")
+	if !ok {
+		return
+	}
+	token, err := p.Tokens.Refresh(c.Request.Context(), request.RefreshToken)
+	switch {
+	case errors.Is(err, ErrTokenNotFound) || errors.Is(err, ErrTokenReused):
+		responses.Unauthorized(c, i18n.M(i18n.KeyInvalidRefreshToken))
+	case err != nil:
+		responses.InternalServerError(c, i18n.M(i18n.KeyTokenRefreshError))
+	default:
+		sendToken(c, token)
+	}
+}
+
+// logout handles POST {path}/logout
+func (p *Plugin) logout(c *gin.Context) {
+	request, ok := bindRequest(c, "")
+	if !ok {
+		return
+	}
+	if err := p.Tokens.Revoke(c.Request.Context(), request.RefreshToken); err != nil {
+		responses.InternalServerError(c, i18n.M(i18n.KeyTokenRevokeError))
+		return
+	}
+	responses.NoContent(c)
+}
+
+// sendToken sends a token response, which caches must not keep (RFC 6749, section 5.1)
+// The body is the bare OAuth2 token object, without the envelope, as OAuth2 clients expect
+func sendToken(c *gin.Context, token *Token) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, token)
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+)
+
+// Errors returned by the stores
+var (
+	// ErrTokenNotFound is returned for unknown or expired refresh tokens
+	ErrTokenNotFound = errors.New("refresh token not found")
+	// ErrTokenReused is returned by Consume for a refresh token that was already exchanged
+	ErrTokenReused = errors.New("refresh token already used")
+)
+
+// RefreshToken is a refresh token as stored: its ID is the SHA-256 of the token given to the
+// client, so a leaked store does not leak usable tokens
+type RefreshToken struct {
+	ID        string                    `json:"id"`
+	Family    string                    `json:"family"`            // Tokens rotated from the same login
+	Methods   []string                  `json:"methods,omitempty"` // How the user authenticated at login
+	AuthTime  time.Time                 `json:"auth_time"`
+	User      *dependencies.CurrentUser `json:"user"`
+	CreatedAt time.Time                 `json:"created_at"`
+	ExpiresAt time.Time                 `json:"expires_at"`
+	Used      bool                      `json:"used"`
+}
+
+// Store keeps the refresh tokens
+type Store interface {
+	// Save stores a new token
+	Save(ctx context.Context, token RefreshToken) error
+	// Consume marks a token as used and returns it; ErrTokenReused when it was already used,
+	// with the token, and ErrTokenNotFound when it is unknown or expired. It must be atomic: two
+	// requests exchanging the same token concurrently get one success and one ErrTokenReused
+	Consume(ctx context.Context, id string) (RefreshToken, error)
+	// RevokeFamily deletes the tokens of a family
+	RevokeFamily(ctx context.Context, family string) error
+}
+
+// MemoryStore keeps the refresh tokens in memory, for tests and single instance deployments
+type MemoryStore struct {
+	tokens map[string]RefreshToken
+	mutex  sync.Mutex
+	swept  time.Time
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: make(map[string]RefreshToken)}
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(_ context.Context, token RefreshToken) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sweep(time.Now())
+	s.tokens[token.ID] = token
+	return nil
+}
+
+// Consume implements Store
+func (s *MemoryStore) Consume(_ context.Context, id string) (RefreshToken, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	token, found := s.tokens[id]
+	if !found || !time.Now().Before(token.ExpiresAt) {
+		return RefreshToken{}, ErrTokenNotFound
+	}
+	if token.Used {
+		return token, ErrTokenReused
+	}
+	token.Used = true
+	s.tokens[id] = token
+	return token, nil
+}
+
+// RevokeFamily implements Store
+func (s *MemoryStore) RevokeFamily(_ context.Context, family string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for id, token := range s.tokens {
+		if token.Family == family {
+			delete(s.tokens, id)
+		}
+	}
+	return nil
+}
+
+// sweep drops the expired tokens, at most once a minute; the caller holds the mutex
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for id, token := range s.tokens {
+		if !now.Before(token.ExpiresAt) {
+			delete(s.tokens, id)
+		}
+	}
+}
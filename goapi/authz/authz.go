@@ -0,0 +1,267 @@
+// Package authz enforces role and scope requirements on routes
+// Routes declare what they require with WithRequiredRoles and WithScopes; a middleware on the route
+// resolves the Principal of the request (set by the authentication layer, read from JWT claims or
+// from a dependencies.CurrentUser) and asks a Policy whether it satisfies the requirement.
+// Requests without a principal get 401, principals lacking a role or scope get 403
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// Context keys read by the default resolver
+const (
+	// PrincipalKey holds the *Principal of the request, set with SetPrincipal
+	PrincipalKey = "goapi.principal"
+	// ClaimsKey holds verified JWT claims as map[string]interface{}
+	ClaimsKey = dependencies.ClaimsKey
+	// CurrentUserKey holds a *dependencies.CurrentUser
+	CurrentUserKey = dependencies.CurrentUserKey
+)
+
+// configKey holds the Config installed by the plugin
+const configKey = "goapi.authz"
+
+// Errors returned by Authorize
+var (
+	// ErrUnauthenticated is returned when the request has no principal
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden is wrapped by the errors of DefaultPolicy
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	ID     string
+	Roles  []string
+	Scopes []string
+	Claims map[string]interface{} // Raw token claims, when the principal comes from a token
+}
+
+// HasRole reports whether the principal has a role
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// HasScope reports whether the principal was granted a scope
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// Requirement is what a route requires from the principal
+type Requirement struct {
+	Roles  []string // At least one of them (empty = no role required)
+	Scopes []string // All of them
+}
+
+// Resolver returns the principal of a request, or nil when it is not authenticated
+type Resolver func(c *gin.Context) (*Principal, error)
+
+// Policy decides whether a principal satisfies a requirement
+// Authorize returns nil to allow the request; the message of the error is sent in the 403 response
+type Policy interface {
+	Authorize(c *gin.Context, principal *Principal, requirement Requirement) error
+}
+
+// PolicyFunc adapts a function to Policy
+type PolicyFunc func(c *gin.Context, principal *Principal, requirement Requirement) error
+
+// Authorize implements Policy
+func (f PolicyFunc) Authorize(c *gin.Context, principal *Principal, requirement Requirement) error {
+	return f(c, principal, requirement)
+}
+
+// DefaultPolicy requires one of the roles and every scope of the requirement
+// Inherits expands roles into the roles they include, e.g. {"admin": {"editor"}, "editor": {"viewer"}}
+type DefaultPolicy struct {
+	Inherits map[string][]string
+}
+
+// Authorize implements Policy
+func (p DefaultPolicy) Authorize(_ *gin.Context, principal *Principal, requirement Requirement) error {
+	if len(requirement.Roles) > 0 && !slices.ContainsFunc(requirement.Roles, p.roles(principal).contains) {
+		return fmt.Errorf("%w: requires role %s", ErrForbidden, strings.Join(requirement.Roles, " or "))
+	}
+	var missing []string
+	for _, scope := range requirement.Scopes {
+		if !principal.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing scope %s", ErrForbidden, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// roleSet is a set of roles
+type roleSet map[string]bool
+
+func (s roleSet) contains(role string) bool {
+	return s[role]
+}
+
+// roles returns the roles of the principal with the inherited ones
+func (p DefaultPolicy) roles(principal *Principal) roleSet {
+	roles := make(roleSet)
+	pending := slices.Clone(principal.Roles)
+	for len(pending) > 0 {
+		role := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if roles[role] {
+			continue
+		}
+		roles[role] = true
+		pending = append(pending, p.Inherits[role]...)
+	}
+	return roles
+}
+
+// Config configures the authorization of an API, installed with NewPlugin
+type Config struct {
+	Resolver Resolver // Default: DefaultResolver
+	Policy   Policy   // Default: DefaultPolicy without inheritance
+}
+
+// DefaultConfig returns the configuration used when the plugin is not installed
+func DefaultConfig() Config {
+	return Config{
+		Resolver: DefaultResolver,
+		Policy:   DefaultPolicy{},
+	}
+}
+
+// SetPrincipal stores the principal of the request, for authentication middlewares
+func SetPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(PrincipalKey, principal)
+}
+
+// GetPrincipal returns the principal resolved for the request, or nil
+func GetPrincipal(c *gin.Context) *Principal {
+	principal, _ := c.Value(PrincipalKey).(*Principal)
+	return principal
+}
+
+// DefaultResolver reads the principal set with SetPrincipal, then JWT claims under ClaimsKey
+// ("sub", "roles" or "role", and "scope" as a space separated string or "scp" as a list), then a
+// *dependencies.CurrentUser under CurrentUserKey
+func DefaultResolver(c *gin.Context) (*Principal, error) {
+	if principal := GetPrincipal(c); principal != nil {
+		return principal, nil
+	}
+	if claims, ok := c.Value(ClaimsKey).(map[string]interface{}); ok {
+		return PrincipalFromClaims(claims), nil
+	}
+	if user, ok := c.Value(CurrentUserKey).(*dependencies.CurrentUser); ok && user != nil {
+		return &Principal{ID: user.ID, Roles: user.Roles, Scopes: user.Scopes, Claims: user.Claims}, nil
+	}
+	return nil, nil
+}
+
+// PrincipalFromClaims builds a principal from JWT claims
+func PrincipalFromClaims(claims map[string]interface{}) *Principal {
+	principal := &Principal{Claims: claims}
+	principal.ID, _ = claims["sub"].(string)
+	principal.Roles = dependencies.ClaimList(claims["roles"])
+	if principal.Roles == nil {
+		principal.Roles = dependencies.ClaimList(claims["role"])
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		principal.Scopes = strings.Fields(scope)
+	} else {
+		principal.Scopes = dependencies.ClaimList(claims["scp"])
+	}
+	return principal
+}
+
+// Authorize checks a requirement against the principal of the request
+// It returns an error wrapping ErrUnauthenticated when there is no principal or the resolver fails,
+// or the error of the policy
+func Authorize(c *gin.Context, requirement Requirement) error {
+	config := DefaultConfig()
+	if installed, ok := c.Value(configKey).(Config); ok {
+		config = installed
+	}
+	principal, err := config.Resolver(c)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if principal == nil {
+		return ErrUnauthenticated
+	}
+	c.Set(PrincipalKey, principal)
+	return config.Policy.Authorize(c, principal, requirement)
+}
+
+// Middleware enforces a requirement, answering 401 or 403 when it is not met
+func Middleware(requirement Requirement) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := Authorize(c, requirement)
+		switch {
+		case err == nil:
+			c.Next()
+			return
+		case errors.Is(err, ErrUnauthenticated):
+			responses.Unauthorized(c, err.Error())
+		default:
+			responses.Forbidden(c, err.Error())
+		}
+		c.Abort()
+	}
+}
+
+// WithRequiredRoles requires the principal to have at least one of the roles
+func WithRequiredRoles(roles ...string) router.RouteOption {
+	return WithRequirement(Requirement{Roles: roles})
+}
+
+// WithScopes requires the principal to have been granted every scope
+func WithScopes(scopes ...string) router.RouteOption {
+	return WithRequirement(Requirement{Scopes: scopes})
+}
+
+// WithRequirement enforces a requirement on a route and documents its 401 and 403 responses
+// Several requirements on a route must all be met
+func WithRequirement(requirement Requirement) router.RouteOption {
+	return func(route *router.Route) {
+		router.WithMiddleware(Middleware(requirement))(route)
+		if _, declared := route.Responses[http.StatusUnauthorized]; !declared {
+			router.WithResponse(http.StatusUnauthorized, "Authentication required")(route)
+		}
+		route.Responses[http.StatusForbidden] = forbiddenDescription(route.Responses[http.StatusForbidden], requirement)
+	}
+}
+
+// forbiddenPrefix starts the 403 descriptions generated from requirements
+const forbiddenPrefix = "Forbidden: requires "
+
+// forbiddenDescription documents the requirements of a route in its 403 response
+func forbiddenDescription(description string, requirement Requirement) string {
+	var parts []string
+	if len(requirement.Roles) > 0 {
+		parts = append(parts, "role "+strings.Join(requirement.Roles, " or "))
+	}
+	if len(requirement.Scopes) > 0 {
+		parts = append(parts, "scopes "+strings.Join(requirement.Scopes, ", "))
+	}
+	switch {
+	case len(parts) == 0:
+		return description
+	case description == "":
+		return forbiddenPrefix + strings.Join(parts, " and ")
+	case strings.HasPrefix(description, forbiddenPrefix):
+		return description + " and " + strings.Join(parts, " and ")
+	}
+	// Descriptions declared by the route are kept
+	return description
+}
@@ -0,0 +1,64 @@
+package authz
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+)
+
+// Plugin installs the resolver and policy used by the route requirements of an API
+// Without it requirements use DefaultConfig
+type Plugin struct {
+	Config Config
+}
+
+// NewPlugin creates a plugin; unset fields of the config take their default
+func NewPlugin(config Config) *Plugin {
+	defaults := DefaultConfig()
+	if config.Resolver == nil {
+		config.Resolver = defaults.Resolver
+	}
+	if config.Policy == nil {
+		config.Policy = defaults.Policy
+	}
+	return &Plugin{Config: config}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "authz"
+}
+
+// Install implements goapi.Plugin
+// When the resolver finds no principal, the *dependencies.CurrentUser of the API is used, so
+// users signed in with sessions.Login satisfy the requirements too
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	resolver, container := p.Config.Resolver, api.GetDependencyContainer()
+	p.Config.Resolver = func(c *gin.Context) (*Principal, error) {
+		principal, err := resolver(c)
+		if err != nil || principal != nil {
+			return principal, err
+		}
+		user, err := dependencies.Resolve[*dependencies.CurrentUser](container, c)
+		if err != nil || user == nil {
+			return nil, nil
+		}
+		return &Principal{ID: user.ID, Roles: user.Roles, Scopes: user.Scopes, Claims: user.Claims}, nil
+	}
+	api.AddMiddleware(func(c *gin.Context) {
+		c.Set(configKey, p.Config)
+		c.Next()
+	})
+	api.RegisterDependency(func(c *gin.Context) (interface{}, error) {
+		if principal := GetPrincipal(c); principal != nil {
+			return principal, nil
+		}
+		principal, err := p.Config.Resolver(c)
+		if err == nil && principal == nil {
+			err = ErrUnauthenticated
+		}
+		return principal, err
+	}, (*Principal)(nil))
+	return nil
+}
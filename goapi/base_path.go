@@ -0,0 +1,108 @@
+package goapi
+
+import (
+	"log"
+	"strings"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// BasePathMode selects how APIConfig.BasePath relates to the paths of the routes
+type BasePathMode int
+
+const (
+	// BasePathStrip is for routes registered with their full path (/api/v1/users): they are
+	// served as registered and the specification lists them relative to the base path (/users)
+	BasePathStrip BasePathMode = iota
+	// BasePathApply is for routes registered relative to the base path (/users): they are
+	// served under it (/api/v1/users) and listed in the specification as registered
+	BasePathApply
+)
+
+// basePath returns the configured base path without a trailing slash ("" for the root)
+func (apiInstance *GoAPI) basePath() string {
+	basePath := strings.TrimRight(apiInstance.config.BasePath, "/")
+	if basePath != "" && !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+	return basePath
+}
+
+// ServedPath returns the URL path a route path is served on, prefixed with BasePath in
+// BasePathApply mode, for links and Location headers
+func (apiInstance *GoAPI) ServedPath(path string) string {
+	return apiInstance.mountPath(path)
+}
+
+// mountPath returns the path a route is served on
+func (apiInstance *GoAPI) mountPath(path string) string {
+	basePath := apiInstance.basePath()
+	if apiInstance.config.BasePathMode != BasePathApply || basePath == "" {
+		return path
+	}
+	if path == "/" {
+		return basePath
+	}
+	return basePath + path
+}
+
+// underBasePath reports whether a path served as registered lies below the base path
+func underBasePath(path, basePath string) bool {
+	return path == basePath || strings.HasPrefix(path, basePath+"/")
+}
+
+// specPaths returns the base path of the specification and the path documenting each route
+// In BasePathStrip mode the prefix is removed from the routes; when some route lies outside
+// the base path, the specification keeps the full paths under "/" so every URL stays valid
+func (apiInstance *GoAPI) specPaths(routes []router.Route) (string, func(string) string) {
+	basePath := apiInstance.basePath()
+	unchanged := func(path string) string { return path }
+	if basePath == "" {
+		return "", unchanged
+	}
+	if apiInstance.config.BasePathMode == BasePathApply {
+		return basePath, unchanged
+	}
+
+	if _, outside := apiInstance.routeOutsideBasePath(routes, basePath); outside {
+		return "/", unchanged
+	}
+	return basePath, func(path string) string {
+		if relative := strings.TrimPrefix(path, basePath); relative != "" {
+			return relative
+		}
+		return "/"
+	}
+}
+
+// routeOutsideBasePath returns the first route, other than the documentation ones, whose path
+// does not lie below the base path
+func (apiInstance *GoAPI) routeOutsideBasePath(routes []router.Route, basePath string) (router.Route, bool) {
+	for _, route := range routes {
+		if !apiInstance.isDocumentationPath(route.Path) && !underBasePath(route.Path, basePath) {
+			return route, true
+		}
+	}
+	return router.Route{}, false
+}
+
+// warnOutsideBasePath logs, in debug mode, that a route prevents stripping the base path
+func (apiInstance *GoAPI) warnOutsideBasePath() {
+	basePath := apiInstance.basePath()
+	if !apiInstance.config.Debug || basePath == "" || apiInstance.config.BasePathMode == BasePathApply {
+		return
+	}
+	if route, outside := apiInstance.routeOutsideBasePath(apiInstance.routes, basePath); outside {
+		log.Printf("[GoAPI] route %s %s is outside BasePath %s; the specification lists full paths", route.Method, route.Path, basePath)
+	}
+}
+
+// servedRoutes returns the routes with the paths they are served on, for the index page
+func (apiInstance *GoAPI) servedRoutes() []router.Route {
+	routes := make([]router.Route, len(apiInstance.routes))
+	for i, route := range apiInstance.routes {
+		route.Path = apiInstance.mountPath(route.Path)
+		routes[i] = route
+	}
+	return routes
+}
@@ -0,0 +1,162 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// Request is a sub-request of a batch
+type Request struct {
+	ID      string            `json:"id,omitempty" example:"user"` // Echoed in the result; defaults to the index
+	Method  string            `json:"method" validate:"required" example:"GET"`
+	Path    string            `json:"path" validate:"required" example:"/api/v1/users/5"` // Path with query string
+	Headers map[string]string `json:"headers,omitempty"`                                  // Override the forwarded headers (e.g. Authorization)
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Result is the response of a sub-request
+type Result struct {
+	ID      string            `json:"id" example:"user"`
+	Status  int               `json:"status" example:"200"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"` // JSON bodies as is, other bodies as a string
+}
+
+// Config controls how batches are executed
+type Config struct {
+	MaxRequests int      // Sub-requests accepted per batch (default 20)
+	Concurrency int      // Sub-requests executed at the same time (default 5)
+	Methods     []string // Allowed methods (default GET, HEAD, POST, PUT, PATCH and DELETE)
+	Exclude     []string // Path prefixes that cannot be called from a batch
+	Headers     []string // Response headers copied to the results (default Content-Type, Location and ETag)
+}
+
+// DefaultConfig returns the default batch settings
+func DefaultConfig() Config {
+	return Config{
+		MaxRequests: 20,
+		Concurrency: 5,
+		Methods: []string{
+			http.MethodGet, http.MethodHead, http.MethodPost,
+			http.MethodPut, http.MethodPatch, http.MethodDelete,
+		},
+		Headers: []string{"Content-Type", "Location", "ETag"},
+	}
+}
+
+// Execute runs the sub-requests in-process through the full middleware chain of the API
+// Every sub-request carries the identity of the batch request (Authorization, Cookie, client
+// address) unless it sets its own headers, so authentication and authorization apply per item
+// Results keep the order of the requests; a failing sub-request does not stop the others
+func Execute(c *gin.Context, api *goapi.GoAPI, requests []Request, config Config) []Result {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]Result, len(requests))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for index, request := range requests {
+		if request.ID == "" {
+			request.ID = fmt.Sprint(index)
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(index int, request Request) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[index] = execute(c, api, request, config)
+		}(index, request)
+	}
+	wg.Wait()
+	return results
+}
+
+// execute runs a single sub-request
+func execute(c *gin.Context, api *goapi.GoAPI, request Request, config Config) Result {
+	method := strings.ToUpper(request.Method)
+	if err := config.check(method, request.Path); err != nil {
+		return errorResult(request.ID, http.StatusBadRequest, err.Error())
+	}
+
+	var options []goapi.InvokeOption
+	hasContentType := false
+	for name, value := range request.Headers {
+		options = append(options, goapi.WithInvokeHeader(name, value))
+		hasContentType = hasContentType || strings.EqualFold(name, "Content-Type")
+	}
+	options = append(options, goapi.WithInvokeFrom(c))
+	if len(request.Body) > 0 {
+		if !hasContentType {
+			options = append(options, goapi.WithInvokeHeader("Content-Type", "application/json"))
+		}
+		options = append(options, goapi.WithInvokeBody([]byte(request.Body)))
+	}
+
+	response, err := api.Invoke(c.Request.Context(), method, request.Path, options...)
+	if err != nil {
+		return errorResult(request.ID, http.StatusInternalServerError, err.Error())
+	}
+
+	result := Result{ID: request.ID, Status: response.StatusCode}
+	for _, name := range config.Headers {
+		if value := response.Header.Get(name); value != "" {
+			if result.Headers == nil {
+				result.Headers = make(map[string]string)
+			}
+			result.Headers[name] = value
+		}
+	}
+	if len(response.Body) > 0 {
+		if json.Valid(response.Body) {
+			result.Body = response.Body
+		} else {
+			result.Body, _ = json.Marshal(string(response.Body))
+		}
+	}
+	return result
+}
+
+// check validates the method and path of a sub-request
+func (config Config) check(method, path string) error {
+	methods := config.Methods
+	if len(methods) == 0 {
+		methods = DefaultConfig().Methods
+	}
+	allowed := false
+	for _, candidate := range methods {
+		if strings.EqualFold(candidate, method) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("method %q is not allowed in a batch", method)
+	}
+
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		return fmt.Errorf("path %q must be relative to the API root", path)
+	}
+	for _, prefix := range config.Exclude {
+		if strings.HasPrefix(path, prefix) {
+			return fmt.Errorf("path %q cannot be called from a batch", path)
+		}
+	}
+	return nil
+}
+
+// errorResult builds the result of a sub-request that could not be executed
+func errorResult(id string, status int, detail string) Result {
+	body, _ := json.Marshal(responses.ErrorResponse{Detail: detail, Type: responses.StatusType(status)})
+	return Result{ID: id, Status: status, Body: body}
+}
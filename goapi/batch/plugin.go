@@ -0,0 +1,87 @@
+package batch
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// Plugin serves a batch endpoint that executes several sub-requests in a single round trip
+type Plugin struct {
+	Path   string   // Path of the endpoint (default "/batch")
+	Config Config   // Execution limits
+	Tags   []string // Documentation tags of the endpoint
+
+	api *goapi.GoAPI
+}
+
+// NewPlugin creates a plugin serving POST /batch with the default limits
+func NewPlugin() *Plugin {
+	return &Plugin{
+		Path:   "/batch",
+		Config: DefaultConfig(),
+		Tags:   []string{"batch"},
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "batch"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	p.api = api
+	// A batch cannot contain another batch
+	p.Config.Exclude = append([]string{p.Path}, p.Config.Exclude...)
+
+	api.POST(p.Path, p.handle,
+		goapi.WithSummary("Execute a batch of requests"),
+		goapi.WithDescription(fmt.Sprintf(
+			"Executes up to %d sub-requests with the credentials of the batch request, unless a sub-request sets its own headers. "+
+				"Results keep the order of the requests and carry their own status", p.maxRequests())),
+		goapi.WithTags(p.Tags...),
+		goapi.WithRequestBody([]Request{{}}, "Sub-requests to execute"),
+		goapi.WithResponseModel(http.StatusOK, []Result{{}}, "Results of the sub-requests"),
+		goapi.WithResponse(http.StatusBadRequest, "Invalid batch"),
+	)
+	return nil
+}
+
+// handle handles POST {path}
+func (p *Plugin) handle(c *gin.Context) {
+	var requests []Request
+	if !goapi.DecodeJSONBody(c, &requests) {
+		return
+	}
+	if len(requests) == 0 {
+		responses.BadRequest(c, "The batch is empty")
+		return
+	}
+	if len(requests) > p.maxRequests() {
+		responses.BadRequest(c, fmt.Sprintf("A batch accepts at most %d requests", p.maxRequests()))
+		return
+	}
+	validator := validation.FromContext(c)
+	for _, request := range requests {
+		if err := validator.ValidateStruct(request); err != nil {
+			responses.ValidationFailed(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, Execute(c, p.api, requests, p.Config))
+}
+
+// maxRequests returns the configured batch size limit
+func (p *Plugin) maxRequests() int {
+	if p.Config.MaxRequests <= 0 {
+		return DefaultConfig().MaxRequests
+	}
+	return p.Config.MaxRequests
+}
@@ -0,0 +1,198 @@
+// Package bench measures the request overhead of GoAPI against plain gin
+// Each Case serves the same request with a GoAPI application and with the equivalent gin engine,
+// so the difference is the cost of the framework: its default middlewares, validation and
+// response helpers. Results are saved as a JSON baseline and compared on later runs to catch
+// performance regressions:
+//
+//	results, err := bench.Run(bench.Suite())
+//	regressions := bench.Compare(results, baseline, 0.10)
+//
+// The goapi bench command runs the suite from the command line
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"testing"
+	"text/tabwriter"
+)
+
+// Case is a request served by a GoAPI application and by the equivalent plain gin engine
+type Case struct {
+	Name    string
+	GoAPI   http.Handler
+	Gin     http.Handler
+	Request func() *http.Request // Called for every iteration, so bodies can be read again
+	Status  int                  // Expected status of both handlers (default 200)
+}
+
+// Measurement is the cost of serving one request
+type Measurement struct {
+	NsPerOp     int64 `json:"ns_per_op"`
+	AllocsPerOp int64 `json:"allocs_per_op"`
+	BytesPerOp  int64 `json:"bytes_per_op"`
+}
+
+// Result is the measurement of a case with GoAPI and with plain gin
+type Result struct {
+	Name  string      `json:"name"`
+	GoAPI Measurement `json:"goapi"`
+	Gin   Measurement `json:"gin"`
+}
+
+// Overhead returns how much slower GoAPI is than gin, as a ratio (0.25 = 25% slower)
+func (r Result) Overhead() float64 {
+	if r.Gin.NsPerOp == 0 {
+		return 0
+	}
+	return float64(r.GoAPI.NsPerOp-r.Gin.NsPerOp) / float64(r.Gin.NsPerOp)
+}
+
+// Run measures every case, checking first that both handlers answer the expected status
+func Run(cases []Case) ([]Result, error) {
+	results := make([]Result, 0, len(cases))
+	for _, c := range cases {
+		status := c.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		for side, handler := range map[string]http.Handler{"goapi": c.GoAPI, "gin": c.Gin} {
+			writer := newResponseWriter()
+			handler.ServeHTTP(writer, c.Request())
+			if writer.status != status {
+				return nil, fmt.Errorf("bench: %s (%s) answered %d, expected %d", c.Name, side, writer.status, status)
+			}
+		}
+		results = append(results, Result{
+			Name:  c.Name,
+			GoAPI: measure(c.GoAPI, c.Request),
+			Gin:   measure(c.Gin, c.Request),
+		})
+	}
+	return results, nil
+}
+
+// measure benchmarks a handler
+func measure(handler http.Handler, request func() *http.Request) Measurement {
+	writer := newResponseWriter()
+	result := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			writer.reset()
+			handler.ServeHTTP(writer, request())
+		}
+	})
+	return Measurement{NsPerOp: result.NsPerOp(), AllocsPerOp: result.AllocsPerOp(), BytesPerOp: result.AllocedBytesPerOp()}
+}
+
+// Regression is a case that got slower, or allocates more, than in the baseline
+type Regression struct {
+	Name     string
+	Metric   string // "ns/op", "allocs/op" or "B/op"
+	Baseline int64
+	Current  int64
+}
+
+// Change returns the relative growth of the metric (0.15 = 15% worse)
+func (r Regression) Change() float64 {
+	if r.Baseline == 0 {
+		return 0
+	}
+	return float64(r.Current-r.Baseline) / float64(r.Baseline)
+}
+
+// String describes the regression
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %s went from %d to %d (%+.1f%%)", r.Name, r.Metric, r.Baseline, r.Current, r.Change()*100)
+}
+
+// Compare reports the GoAPI measurements that grew more than tolerance (0.10 = 10%) over the
+// baseline; cases missing from the baseline are skipped
+// Times depend on the machine, so they are compared as the overhead over gin measured in the
+// same run; allocations are compared as they are
+func Compare(results, baseline []Result, tolerance float64) []Regression {
+	previous := make(map[string]Result, len(baseline))
+	for _, result := range baseline {
+		previous[result.Name] = result
+	}
+
+	var regressions []Regression
+	for _, current := range results {
+		base, found := previous[current.Name]
+		if !found {
+			continue
+		}
+		// El tiempo de GoAPI se proyecta sobre el gin de la línea base para descontar la máquina
+		if current.Gin.NsPerOp > 0 {
+			projected := int64(float64(base.Gin.NsPerOp) * (1 + current.Overhead()))
+			if exceeds(base.GoAPI.NsPerOp, projected, tolerance) {
+				regressions = append(regressions, Regression{Name: current.Name, Metric: "ns/op", Baseline: base.GoAPI.NsPerOp, Current: projected})
+			}
+		}
+		if exceeds(base.GoAPI.AllocsPerOp, current.GoAPI.AllocsPerOp, tolerance) {
+			regressions = append(regressions, Regression{Name: current.Name, Metric: "allocs/op", Baseline: base.GoAPI.AllocsPerOp, Current: current.GoAPI.AllocsPerOp})
+		}
+		if exceeds(base.GoAPI.BytesPerOp, current.GoAPI.BytesPerOp, tolerance) {
+			regressions = append(regressions, Regression{Name: current.Name, Metric: "B/op", Baseline: base.GoAPI.BytesPerOp, Current: current.GoAPI.BytesPerOp})
+		}
+	}
+	sort.SliceStable(regressions, func(i, j int) bool { return regressions[i].Name < regressions[j].Name })
+	return regressions
+}
+
+// exceeds reports whether current grew more than tolerance over baseline
+func exceeds(baseline, current int64, tolerance float64) bool {
+	return float64(current) > float64(baseline)*(1+tolerance)
+}
+
+// WriteBaseline saves results as JSON, to be loaded with ReadBaseline
+func WriteBaseline(w io.Writer, results []Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+// ReadBaseline loads results saved with WriteBaseline
+func ReadBaseline(r io.Reader) ([]Result, error) {
+	var results []Result
+	if err := json.NewDecoder(r).Decode(&results); err != nil {
+		return nil, fmt.Errorf("bench: invalid baseline: %w", err)
+	}
+	return results, nil
+}
+
+// WriteTable prints results as an aligned table
+func WriteTable(w io.Writer, results []Result) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "CASE\tGOAPI ns/op\tGIN ns/op\tOVERHEAD\tGOAPI allocs\tGIN allocs\tGOAPI B/op\tGIN B/op\t")
+	for _, r := range results {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%+.0f%%\t%d\t%d\t%d\t%d\t\n",
+			r.Name, r.GoAPI.NsPerOp, r.Gin.NsPerOp, r.Overhead()*100,
+			r.GoAPI.AllocsPerOp, r.Gin.AllocsPerOp, r.GoAPI.BytesPerOp, r.Gin.BytesPerOp)
+	}
+	return table.Flush()
+}
+
+// responseWriter discards responses, keeping only the status
+type responseWriter struct {
+	header http.Header
+	status int
+}
+
+// newResponseWriter creates a discarding response writer
+func newResponseWriter() *responseWriter {
+	return &responseWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+// reset prepares the writer for the next iteration
+func (w *responseWriter) reset() {
+	clear(w.header)
+	w.status = http.StatusOK
+}
+
+func (w *responseWriter) Header() http.Header            { return w.header }
+func (w *responseWriter) WriteHeader(status int)         { w.status = status }
+func (w *responseWriter) Write(data []byte) (int, error) { return len(data), nil }
@@ -0,0 +1,158 @@
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// user is the payload of the serialization cases
+type user struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email"`
+	Roles []string `json:"roles"`
+}
+
+// createUser is the validated body of the binding case; gin reads the binding tags and GoAPI the
+// validate ones
+type createUser struct {
+	Name  string `json:"name" validate:"required,min=2" binding:"required,min=2"`
+	Email string `json:"email" validate:"required,email" binding:"required,email"`
+	Age   int    `json:"age" validate:"gte=0,lte=150" binding:"gte=0,lte=150"`
+}
+
+// createUserBody is the request body of the binding case
+var createUserBody = []byte(`{"name":"Ada Lovelace","email":"ada@example.com","age":36}`)
+
+// Suite returns the standard cases: routing, binding and validation, response envelope and
+// middleware chain
+func Suite() []Case {
+	return []Case{Routing(), Binding(), Envelope(), Middleware()}
+}
+
+// newAPI creates a GoAPI application in release mode with its default middlewares
+func newAPI() *goapi.GoAPI {
+	config := goapi.DefaultConfig()
+	config.Debug = false
+	return goapi.New(config)
+}
+
+// newGin creates a plain gin engine with panic recovery, the minimum a production engine has
+func newGin() *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	return engine
+}
+
+// Routing resolves a request with two path parameters among 50 routes
+func Routing() Case {
+	api, engine := newAPI(), newGin()
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, c.Param("item"))
+	}
+	for i := 0; i < 25; i++ {
+		for _, path := range []string{fmt.Sprintf("/resource%d/:id", i), fmt.Sprintf("/resource%d/:id/items/:item", i)} {
+			api.GET(path, handler)
+			engine.GET(path, handler)
+		}
+	}
+	api.SetupRoutes()
+	return Case{
+		Name:    "routing",
+		GoAPI:   api,
+		Gin:     engine,
+		Request: get("/resource20/42/items/7"),
+	}
+}
+
+// Binding decodes and validates a JSON body, answering 201 with it
+func Binding() Case {
+	api, engine := newAPI(), newGin()
+	api.POST("/users", func(c *gin.Context) {
+		responses.Created(c, middleware.ValidatedBody(c))
+	}, goapi.WithRequestBody(createUser{}, "User to create"), goapi.WithRequestValidation(true))
+	api.SetupRoutes()
+	engine.POST("/users", func(c *gin.Context) {
+		var body createUser
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"detail": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"data": body, "success": true})
+	})
+	return Case{
+		Name:  "binding",
+		GoAPI: api,
+		Gin:   engine,
+		Request: func() *http.Request {
+			request := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(createUserBody))
+			request.Header.Set("Content-Type", "application/json")
+			return request
+		},
+		Status: http.StatusCreated,
+	}
+}
+
+// Envelope serializes a list of 20 users in the standard response envelope
+func Envelope() Case {
+	users := make([]user, 20)
+	for i := range users {
+		users[i] = user{ID: i, Name: fmt.Sprintf("User %d", i), Email: fmt.Sprintf("user%d@example.com", i), Roles: []string{"reader", "writer"}}
+	}
+	api, engine := newAPI(), newGin()
+	api.GET("/users", func(c *gin.Context) {
+		responses.Success(c, users)
+	})
+	api.SetupRoutes()
+	engine.GET("/users", func(c *gin.Context) {
+		c.JSON(http.StatusOK, responses.Response{Data: users, Success: true})
+	})
+	return Case{
+		Name:    "envelope",
+		GoAPI:   api,
+		Gin:     engine,
+		Request: get("/users"),
+	}
+}
+
+// Middleware runs a request through five global middlewares that set and read a context value
+func Middleware() Case {
+	api, engine := newAPI(), newGin()
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("step%d", i)
+		step := func(c *gin.Context) {
+			c.Set(key, true)
+			c.Next()
+		}
+		api.AddMiddleware(step)
+		engine.Use(step)
+	}
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, "%t", c.GetBool("step4"))
+	}
+	api.GET("/ping", handler)
+	api.SetupRoutes()
+	engine.GET("/ping", handler)
+	return Case{
+		Name:    "middleware",
+		GoAPI:   api,
+		Gin:     engine,
+		Request: get("/ping"),
+	}
+}
+
+// get returns a request factory for a GET path
+func get(path string) func() *http.Request {
+	return func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, path, nil)
+	}
+}
@@ -0,0 +1,248 @@
+// Package billing meters API usage for billing integrations
+// A Meter assigns a cost to every request (per route, or decided by the handler), aggregates
+// the usage of each principal over a period and hands it to pluggable exporters (CSV, Stripe)
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// Context keys holding the cost of the current request
+const (
+	RouteCostKey   = "goapi.billing.route_cost"
+	RequestCostKey = "goapi.billing.request_cost"
+)
+
+// Usage is the aggregated consumption of a principal over a period
+type Usage struct {
+	Principal string    `json:"principal"`
+	Requests  int64     `json:"requests"`
+	Units     float64   `json:"units"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// Exporter receives the usage of every principal when a period is flushed
+type Exporter interface {
+	Export(ctx context.Context, usage []Usage) error
+}
+
+// ExporterFunc adapts a function to the Exporter interface
+type ExporterFunc func(ctx context.Context, usage []Usage) error
+
+// Export implements Exporter
+func (f ExporterFunc) Export(ctx context.Context, usage []Usage) error {
+	return f(ctx, usage)
+}
+
+// Config configures a Meter
+type Config struct {
+	// DefaultCost of requests to routes without a cost (default 1; 0 meters only priced routes)
+	DefaultCost *float64
+	// Principal identifies who is billed (default: the "user_id" context value)
+	// Requests without a principal are not metered
+	Principal func(*gin.Context) string
+	// ChargeErrors also meters responses with status >= 500 (client errors are always metered)
+	ChargeErrors bool
+}
+
+// Meter aggregates request costs per principal
+// It is safe for concurrent use
+type Meter struct {
+	config     Config
+	routeCosts map[string]float64 // "METHOD path" -> cost
+	usage      map[string]*Usage
+	periodFrom time.Time
+	exporters  []*exporterState
+	mutex      sync.Mutex
+	flushMutex sync.Mutex // Serializes flushes so batches are exported in order
+}
+
+// exporterState holds the batches an exporter failed to export, retried on the next flush
+type exporterState struct {
+	exporter Exporter
+	pending  [][]Usage
+}
+
+// NewMeter creates a meter exporting to the given exporters
+func NewMeter(config Config, exporters ...Exporter) *Meter {
+	if config.Principal == nil {
+		config.Principal = func(c *gin.Context) string {
+			return c.GetString("user_id")
+		}
+	}
+	meter := &Meter{
+		config:     config,
+		routeCosts: make(map[string]float64),
+		usage:      make(map[string]*Usage),
+		periodFrom: time.Now(),
+	}
+	for _, exporter := range exporters {
+		meter.AddExporter(exporter)
+	}
+	return meter
+}
+
+// AddExporter adds an exporter
+func (m *Meter) AddExporter(exporter Exporter) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.exporters = append(m.exporters, &exporterState{exporter: exporter})
+}
+
+// SetRouteCost sets the cost of a route by method and path pattern ("/users/:id")
+func (m *Meter) SetRouteCost(method, path string, cost float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.routeCosts[method+" "+path] = cost
+}
+
+// WithCost is a route option setting the cost of a route
+func WithCost(cost float64) router.RouteOption {
+	return router.WithMiddleware(func(c *gin.Context) {
+		c.Set(RouteCostKey, cost)
+		c.Next()
+	})
+}
+
+// SetCost overrides the cost of the current request from a handler (e.g. per processed item)
+func SetCost(c *gin.Context, cost float64) {
+	c.Set(RequestCostKey, cost)
+}
+
+// AddCost adds to the cost of the current request
+func AddCost(c *gin.Context, cost float64) {
+	SetCost(c, requestCost(c)+cost)
+}
+
+// requestCost returns the cost set by the handler, or 0
+func requestCost(c *gin.Context) float64 {
+	if value, exists := c.Get(RequestCostKey); exists {
+		if cost, ok := value.(float64); ok {
+			return cost
+		}
+	}
+	return 0
+}
+
+// Middleware meters every request once the handler has run
+func (m *Meter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() >= 500 && !m.config.ChargeErrors {
+			return
+		}
+		principal := m.config.Principal(c)
+		if principal == "" {
+			return
+		}
+		m.Record(principal, m.cost(c))
+	}
+}
+
+// cost resolves the cost of a request: handler override, route option, route table, default
+func (m *Meter) cost(c *gin.Context) float64 {
+	if _, exists := c.Get(RequestCostKey); exists {
+		return requestCost(c)
+	}
+	if value, exists := c.Get(RouteCostKey); exists {
+		if cost, ok := value.(float64); ok {
+			return cost
+		}
+	}
+
+	m.mutex.Lock()
+	cost, exists := m.routeCosts[c.Request.Method+" "+c.FullPath()]
+	m.mutex.Unlock()
+	if exists {
+		return cost
+	}
+	if m.config.DefaultCost != nil {
+		return *m.config.DefaultCost
+	}
+	return 1
+}
+
+// Record adds usage for a principal; it is also useful to meter work done outside of requests
+// Zero costs still count the request
+func (m *Meter) Record(principal string, units float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	usage, exists := m.usage[principal]
+	if !exists {
+		usage = &Usage{Principal: principal}
+		m.usage[principal] = usage
+	}
+	usage.Requests++
+	usage.Units += units
+}
+
+// Snapshot returns the usage of the current period without resetting it
+func (m *Meter) Snapshot() []Usage {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.collect(time.Now())
+}
+
+// collect copies the current usage sorted by principal; the caller holds the lock
+func (m *Meter) collect(now time.Time) []Usage {
+	usage := make([]Usage, 0, len(m.usage))
+	for _, current := range m.usage {
+		entry := *current
+		entry.From = m.periodFrom
+		entry.To = now
+		usage = append(usage, entry)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Principal < usage[j].Principal
+	})
+	return usage
+}
+
+// Flush closes the current period and exports its usage
+// A batch an exporter fails to export is kept and retried unchanged on the next flush, before
+// newer batches, so exporters can deduplicate retries by period (see StripeExporter)
+func (m *Meter) Flush(ctx context.Context) error {
+	m.flushMutex.Lock()
+	defer m.flushMutex.Unlock()
+
+	m.mutex.Lock()
+	now := time.Now()
+	batch := m.collect(now)
+	m.usage = make(map[string]*Usage)
+	m.periodFrom = now
+	exporters := append([]*exporterState(nil), m.exporters...)
+	m.mutex.Unlock()
+
+	var exportErrors []error
+	for _, state := range exporters {
+		batches := state.pending
+		if len(batch) > 0 {
+			batches = append(batches, batch)
+		}
+		state.pending = nil
+
+		for i, current := range batches {
+			if err := state.exporter.Export(ctx, current); err != nil {
+				state.pending = batches[i:]
+				exportErrors = append(exportErrors, err)
+				break
+			}
+		}
+	}
+	if len(exportErrors) > 0 {
+		return fmt.Errorf("error exporting usage: %w", errors.Join(exportErrors...))
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package billing
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CSVExporter appends usage rows to a writer: period_from, period_to, principal, requests, units
+// The header row is written before the first export
+type CSVExporter struct {
+	writer        io.Writer
+	headerWritten bool
+	mutex         sync.Mutex
+}
+
+// NewCSVExporter creates an exporter writing to w (typically a file opened in append mode)
+func NewCSVExporter(w io.Writer) *CSVExporter {
+	return &CSVExporter{writer: w}
+}
+
+// Export implements Exporter
+func (e *CSVExporter) Export(ctx context.Context, usage []Usage) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	writer := csv.NewWriter(e.writer)
+	if !e.headerWritten {
+		if err := writer.Write([]string{"period_from", "period_to", "principal", "requests", "units"}); err != nil {
+			return err
+		}
+	}
+	for _, entry := range usage {
+		if err := writer.Write([]string{
+			entry.From.UTC().Format(time.RFC3339),
+			entry.To.UTC().Format(time.RFC3339),
+			entry.Principal,
+			strconv.FormatInt(entry.Requests, 10),
+			strconv.FormatFloat(entry.Units, 'f', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	e.headerWritten = true
+	return nil
+}
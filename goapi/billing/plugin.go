@@ -0,0 +1,86 @@
+package billing
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+)
+
+// Plugin wires a Meter into a GoAPI instance
+// It registers the meter as a singleton dependency, meters every request and flushes
+// the usage every interval while the server runs, plus once on shutdown
+type Plugin struct {
+	Meter    *Meter
+	Interval time.Duration
+	OnError  func(error)
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewPlugin creates a plugin flushing the usage every interval
+// A zero interval disables periodic flushes; Flush can still be called manually
+func NewPlugin(meter *Meter, interval time.Duration) *Plugin {
+	return &Plugin{
+		Meter:    meter,
+		Interval: interval,
+		OnError: func(err error) {
+			log.Printf("billing export failed: %v", err)
+		},
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "billing"
+}
+
+// Install implements goapi.Plugin
+// The authentication middleware must be added before the plugin so the principal is known
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Meter, nil
+	}, (*Meter)(nil))
+	api.AddMiddleware(p.Meter.Middleware())
+	return nil
+}
+
+// OnStartup implements goapi.StartupPlugin
+func (p *Plugin) OnStartup(ctx context.Context) error {
+	if p.Interval <= 0 {
+		return nil
+	}
+	flushContext, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-flushContext.Done():
+				return
+			case <-ticker.C:
+				if err := p.Meter.Flush(flushContext); err != nil {
+					p.OnError(err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// OnShutdown implements goapi.ShutdownPlugin
+// The usage of the last period is exported before the server stops
+func (p *Plugin) OnShutdown(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+		<-p.done
+	}
+	return p.Meter.Flush(ctx)
+}
@@ -0,0 +1,107 @@
+package billing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stripeMeterEventsEndpoint is the Stripe Billing meter events endpoint
+const stripeMeterEventsEndpoint = "https://api.stripe.com/v1/billing/meter_events"
+
+// StripeExporter reports usage to Stripe metered billing as meter events
+// Every principal of a period becomes one event whose identifier is derived from the principal
+// and the period, so retried batches are deduplicated by Stripe
+type StripeExporter struct {
+	SecretKey string
+	EventName string // event_name of the Stripe meter
+	// CustomerID maps a principal to its Stripe customer ("cus_..."); "" skips the principal
+	CustomerID func(ctx context.Context, principal string) (string, error)
+	// Quantity converts usage into the event value (default: units rounded up)
+	Quantity func(usage Usage) int64
+	Client   *http.Client
+	Endpoint string // Overrides the Stripe endpoint (testing, stripe-mock)
+}
+
+// NewStripeExporter creates an exporter sending meter events with a secret key
+func NewStripeExporter(secretKey, eventName string, customerID func(ctx context.Context, principal string) (string, error)) *StripeExporter {
+	return &StripeExporter{
+		SecretKey:  secretKey,
+		EventName:  eventName,
+		CustomerID: customerID,
+		Client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Export implements Exporter
+func (e *StripeExporter) Export(ctx context.Context, usage []Usage) error {
+	for _, entry := range usage {
+		quantity := int64(math.Ceil(entry.Units))
+		if e.Quantity != nil {
+			quantity = e.Quantity(entry)
+		}
+		if quantity <= 0 {
+			continue
+		}
+
+		customerID, err := e.CustomerID(ctx, entry.Principal)
+		if err != nil {
+			return fmt.Errorf("error resolving Stripe customer of %s: %w", entry.Principal, err)
+		}
+		if customerID == "" {
+			continue
+		}
+
+		if err := e.sendEvent(ctx, entry, customerID, quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendEvent creates a single meter event
+func (e *StripeExporter) sendEvent(ctx context.Context, entry Usage, customerID string, quantity int64) error {
+	form := url.Values{}
+	form.Set("event_name", e.EventName)
+	form.Set("identifier", eventIdentifier(entry))
+	form.Set("timestamp", strconv.FormatInt(entry.To.Unix(), 10))
+	form.Set("payload[stripe_customer_id]", customerID)
+	form.Set("payload[value]", strconv.FormatInt(quantity, 10))
+
+	endpoint := e.Endpoint
+	if endpoint == "" {
+		endpoint = stripeMeterEventsEndpoint
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Authorization", "Bearer "+e.SecretKey)
+
+	response, err := e.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending Stripe meter event: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	return fmt.Errorf("Stripe returned %d: %s", response.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// eventIdentifier derives a stable identifier of the usage of a principal in a period
+func eventIdentifier(entry Usage) string {
+	sum := sha256.Sum256([]byte(entry.Principal + "|" + strconv.FormatInt(entry.From.UnixNano(), 10)))
+	return "goapi-" + hex.EncodeToString(sum[:20])
+}
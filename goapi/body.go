@@ -0,0 +1,200 @@
+package goapi
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bodyKey holds the Body of a request, shared by the middlewares and the handler
+const bodyKey = "goapi.body"
+
+// Body is the request body of a context as a stream that keeps what it reads
+// It replaces c.Request.Body, so whoever reads the request (the handler, a proxy, binding) goes
+// through it, and middlewares running around the handler can then inspect what was read
+// without the body being loaded in memory first
+type Body struct {
+	body      io.ReadCloser
+	buffer    bytes.Buffer
+	keep      int64
+	limit     int64
+	tee       []io.Writer
+	pending   []byte // Read ahead by Peek, not delivered yet
+	peekErr   error  // Error that ended the read ahead
+	fetched   int64
+	size      int64
+	truncated bool
+	eof       bool
+}
+
+// BodyOption configures the Body of a request
+type BodyOption func(*Body)
+
+// WithBodyBuffer keeps the first n bytes read in memory, for Bytes; n < 0 keeps them all
+// With several calls, the largest buffer applies to the bytes not read yet
+func WithBodyBuffer(n int64) BodyOption {
+	return func(b *Body) {
+		if n < 0 || b.keep < 0 {
+			b.keep = -1
+			return
+		}
+		b.keep = max(b.keep, n)
+	}
+}
+
+// WithBodyTee copies the bytes read to each writer as they are read (a hash, an audit log, a
+// second backend); a write error fails the read, as with io.TeeReader
+func WithBodyTee(writers ...io.Writer) BodyOption {
+	return func(b *Body) {
+		b.tee = append(b.tee, writers...)
+	}
+}
+
+// WithBodyLimit fails reads past n bytes with *http.MaxBytesError
+// With several calls, the smallest limit applies
+func WithBodyLimit(n int64) BodyOption {
+	return func(b *Body) {
+		if b.limit == 0 || n < b.limit {
+			b.limit = n
+		}
+	}
+}
+
+// BodyReader returns the body of the request, installing it as c.Request.Body on the first call
+// Later calls return the same Body, adding their options, so an audit middleware can keep the
+// bytes a handler streams to a backend:
+//
+//	api.AddMiddleware(func(c *gin.Context) {
+//		body := goapi.BodyReader(c, goapi.WithBodyBuffer(64<<10))
+//		c.Next()
+//		audit.Log(c.Request.URL.Path, body.Size(), body.Bytes())
+//	})
+//
+//	api.POST("/ingest", func(c *gin.Context) {
+//		_, err := io.Copy(storage, goapi.BodyReader(c))
+//		...
+//	})
+func BodyReader(c *gin.Context, options ...BodyOption) *Body {
+	value, _ := c.Get(bodyKey)
+	body, _ := value.(*Body)
+	if body == nil || c.Request.Body != body {
+		// Un middleware pudo reemplazar el cuerpo (Decompress, la validación): se envuelve el actual
+		source := c.Request.Body
+		if source == nil {
+			source = http.NoBody
+		}
+		body = &Body{body: source}
+		c.Request.Body = body
+		c.Set(bodyKey, body)
+	}
+	for _, option := range options {
+		option(body)
+	}
+	return body
+}
+
+// Read reads from the request body, keeping and copying what it reads
+func (b *Body) Read(data []byte) (int, error) {
+	var n int
+	var err error
+	switch {
+	case len(b.pending) > 0:
+		n = copy(data, b.pending)
+		b.pending = b.pending[n:]
+		if len(b.pending) == 0 {
+			err = b.peekErr
+		}
+	case b.peekErr != nil:
+		err = b.peekErr
+	default:
+		n, err = b.fetch(data)
+	}
+	if n > 0 {
+		b.keepBytes(data[:n])
+		for _, writer := range b.tee {
+			if _, writeErr := writer.Write(data[:n]); writeErr != nil {
+				return n, writeErr
+			}
+		}
+		b.size += int64(n)
+	}
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+// Peek returns the next n bytes without consuming them, so a middleware can inspect the start
+// of the body before the handler streams it; fewer bytes come with the error that ended the body
+// Peeked bytes are kept and copied to the tee writers when they are read
+func (b *Body) Peek(n int) ([]byte, error) {
+	for len(b.pending) < n && b.peekErr == nil {
+		chunk := make([]byte, n-len(b.pending))
+		read, err := b.fetch(chunk)
+		b.pending = append(b.pending, chunk[:read]...)
+		b.peekErr = err
+	}
+	if len(b.pending) < n {
+		return b.pending, b.peekErr
+	}
+	return b.pending[:n], nil
+}
+
+// fetch reads from the request body, enforcing the limit
+func (b *Body) fetch(data []byte) (int, error) {
+	if b.limit > 0 && b.fetched > b.limit {
+		return 0, &http.MaxBytesError{Limit: b.limit}
+	}
+	if b.limit > 0 && int64(len(data)) > b.limit-b.fetched+1 {
+		// Se lee un byte más del límite para detectar que se superó
+		data = data[:b.limit-b.fetched+1]
+	}
+	n, err := b.body.Read(data)
+	if b.limit > 0 && b.fetched+int64(n) > b.limit {
+		n = int(b.limit - b.fetched)
+		err = &http.MaxBytesError{Limit: b.limit}
+	}
+	b.fetched += int64(n)
+	return n, err
+}
+
+// keepBytes buffers what fits of the bytes read
+func (b *Body) keepBytes(data []byte) {
+	if b.keep < 0 {
+		b.buffer.Write(data)
+		return
+	}
+	room := b.keep - int64(b.buffer.Len())
+	if room < int64(len(data)) {
+		b.truncated = true
+		data = data[:max(0, room)]
+	}
+	b.buffer.Write(data)
+}
+
+// Close closes the request body
+func (b *Body) Close() error {
+	return b.body.Close()
+}
+
+// Bytes returns the buffered bytes of what was read so far
+func (b *Body) Bytes() []byte {
+	return b.buffer.Bytes()
+}
+
+// Size returns the number of bytes read so far
+func (b *Body) Size() int64 {
+	return b.size
+}
+
+// Truncated reports whether bytes were read past the buffer, so Bytes is not the whole body read
+func (b *Body) Truncated() bool {
+	return b.truncated
+}
+
+// Complete reports whether the body was read to the end
+func (b *Body) Complete() bool {
+	return b.eof
+}
@@ -0,0 +1,152 @@
+// Package broker connects GoAPI applications to message queues
+// Producers publish messages and consumers deliver the messages of a topic to a Handler. Adapters
+// wrap NATS and Kafka clients of the application, and Memory runs in process for development and
+// tests. The Plugin registers the producer as a dependency and runs the consumers between the
+// startup and shutdown of the API
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrClosed is returned by brokers that were closed
+var ErrClosed = errors.New("broker is closed")
+
+// Message is a message published on or received from a topic
+type Message struct {
+	Topic   string
+	Key     []byte // Partitioning key (Kafka); ignored by brokers without partitions
+	Value   []byte
+	Headers map[string]string
+}
+
+// Producer publishes messages
+type Producer interface {
+	Publish(ctx context.Context, message Message) error
+	Close() error
+}
+
+// Consumer delivers the messages of a topic
+type Consumer interface {
+	// Consume calls the handler for each message of the topic until ctx is canceled
+	// The consumer group shares the messages between the instances consuming a topic (NATS queue
+	// group, Kafka consumer group). A nil error from the handler acknowledges the message
+	Consume(ctx context.Context, topic, group string, handler Handler) error
+	Close() error
+}
+
+// Handler processes a received message
+type Handler func(ctx context.Context, message *Message) error
+
+// Middleware wraps a handler, e.g. to log, retry or recover from panics
+type Middleware func(Handler) Handler
+
+// Chain applies middlewares to a handler; the first one is the outermost
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Logging logs every handled message with its duration and error (nil logger = log.Default())
+func Logging(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, message *Message) error {
+			start := time.Now()
+			err := next(ctx, message)
+			if err != nil {
+				logger.Printf("[broker] %s failed after %s: %v", message.Topic, time.Since(start), err)
+			} else {
+				logger.Printf("[broker] %s handled in %s", message.Topic, time.Since(start))
+			}
+			return err
+		}
+	}
+}
+
+// Recover turns panics of the handler into errors
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, message *Message) (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					err = fmt.Errorf("panic handling %s: %v", message.Topic, recovered)
+				}
+			}()
+			return next(ctx, message)
+		}
+	}
+}
+
+// RetryConfig configures the Retry middleware
+type RetryConfig struct {
+	Attempts   int           // Total attempts, including the first (default 3)
+	Backoff    time.Duration // Wait before the first retry, doubled on each one (default 100ms)
+	MaxBackoff time.Duration // Maximum wait between attempts (default 5s)
+}
+
+// DefaultRetryConfig returns the default retry configuration
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		Attempts:   3,
+		Backoff:    100 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+	}
+}
+
+// Retry calls the handler again when it fails, with exponential backoff
+// The error of the last attempt is returned; waiting stops when the context is canceled
+func Retry(config ...RetryConfig) Middleware {
+	cfg := DefaultRetryConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, message *Message) error {
+			backoff := cfg.Backoff
+			var err error
+			for attempt := 1; ; attempt++ {
+				if err = next(ctx, message); err == nil || attempt >= cfg.Attempts {
+					return err
+				}
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+					return err
+				}
+				backoff = min(backoff*2, cfg.MaxBackoff)
+			}
+		}
+	}
+}
+
+// DeadLetter publishes the messages the handler failed to process on another topic and
+// acknowledges them; place it outside Retry so only exhausted messages are forwarded
+func DeadLetter(producer Producer, topic string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, message *Message) error {
+			err := next(ctx, message)
+			if err == nil {
+				return nil
+			}
+			headers := make(map[string]string, len(message.Headers)+2)
+			for name, value := range message.Headers {
+				headers[name] = value
+			}
+			headers["x-original-topic"] = message.Topic
+			headers["x-error"] = err.Error()
+			if publishErr := producer.Publish(ctx, Message{Topic: topic, Key: message.Key, Value: message.Value, Headers: headers}); publishErr != nil {
+				return errors.Join(err, fmt.Errorf("dead letter: %w", publishErr))
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// KafkaClient is the part of a Kafka client used by Kafka
+// GoAPI does not depend on a Kafka driver; wrap the client of the application, for kafka-go:
+//
+//	type kafkaClient struct {
+//		writer  *kafka.Writer
+//		brokers []string
+//	}
+//
+//	func (k *kafkaClient) Produce(ctx context.Context, message broker.Message) error {
+//		headers := make([]kafka.Header, 0, len(message.Headers))
+//		for key, value := range message.Headers {
+//			headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+//		}
+//		return k.writer.WriteMessages(ctx, kafka.Message{Topic: message.Topic, Key: message.Key, Value: message.Value, Headers: headers})
+//	}
+//	func (k *kafkaClient) Reader(topic, group string) broker.KafkaReader {
+//		return kafkaReader{kafka.NewReader(kafka.ReaderConfig{Brokers: k.brokers, Topic: topic, GroupID: group})}
+//	}
+//	func (k *kafkaClient) Close() error {
+//		return k.writer.Close()
+//	}
+//
+//	type kafkaReader struct{ *kafka.Reader }
+//
+//	func (r kafkaReader) Fetch(ctx context.Context) (broker.Message, func(context.Context) error, error) {
+//		msg, err := r.Reader.FetchMessage(ctx)
+//		if err != nil {
+//			return broker.Message{}, nil, err
+//		}
+//		headers := make(map[string]string, len(msg.Headers))
+//		for _, header := range msg.Headers {
+//			headers[header.Key] = string(header.Value)
+//		}
+//		commit := func(ctx context.Context) error { return r.Reader.CommitMessages(ctx, msg) }
+//		return broker.Message{Topic: msg.Topic, Key: msg.Key, Value: msg.Value, Headers: headers}, commit, nil
+//	}
+type KafkaClient interface {
+	Produce(ctx context.Context, message Message) error
+	Reader(topic, group string) KafkaReader
+	Close() error
+}
+
+// KafkaReader reads the messages of a topic for a consumer group
+type KafkaReader interface {
+	// Fetch waits for the next message and returns the function committing its offset
+	Fetch(ctx context.Context) (message Message, commit func(context.Context) error, err error)
+	Close() error
+}
+
+// Kafka publishes and consumes messages on Kafka topics
+// Offsets are committed after the handler succeeds, so messages are delivered at least once; a
+// failed message blocks its partition until it succeeds, unless KeepGoing is set
+type Kafka struct {
+	Client     KafkaClient
+	KeepGoing  bool                              // Commit failed messages too, after reporting them to OnError
+	RetryDelay time.Duration                     // Wait before handling a failed message again (default 1s)
+	OnError    func(message *Message, err error) // Default: ignore
+}
+
+// NewKafka creates a broker on a Kafka client
+func NewKafka(client KafkaClient) *Kafka {
+	return &Kafka{Client: client, RetryDelay: time.Second}
+}
+
+// Publish implements Producer
+func (k *Kafka) Publish(ctx context.Context, message Message) error {
+	return k.Client.Produce(ctx, message)
+}
+
+// Consume implements Consumer
+func (k *Kafka) Consume(ctx context.Context, topic, group string, handler Handler) error {
+	reader := k.Client.Reader(topic, group)
+	defer reader.Close()
+
+	for {
+		message, commit, err := reader.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+		for {
+			err = handler(ctx, &message)
+			if err == nil {
+				break
+			}
+			if k.OnError != nil {
+				k.OnError(&message, err)
+			}
+			if k.KeepGoing {
+				break
+			}
+			select {
+			case <-time.After(k.RetryDelay):
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				// Left uncommitted, the message is delivered again to the next consumer
+				return nil
+			}
+		}
+		if err := commit(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Close implements Producer and Consumer
+func (k *Kafka) Close() error {
+	return k.Client.Close()
+}
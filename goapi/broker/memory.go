@@ -0,0 +1,84 @@
+package broker
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory is an in-process broker, for development and tests
+// Every consumer group of a topic receives each message once; consumers of the same group share
+// them. Messages published while a group has no consumer running are dropped
+type Memory struct {
+	mutex  sync.Mutex
+	groups map[string]map[string]chan Message // topic => group => queue
+	closed bool
+	buffer int
+}
+
+// NewMemory creates an in-process broker; buffer is the number of messages each group queues
+// before Publish waits (default 256)
+func NewMemory(buffer ...int) *Memory {
+	size := 256
+	if len(buffer) > 0 && buffer[0] > 0 {
+		size = buffer[0]
+	}
+	return &Memory{groups: make(map[string]map[string]chan Message), buffer: size}
+}
+
+// Publish implements Producer
+func (m *Memory) Publish(ctx context.Context, message Message) error {
+	m.mutex.Lock()
+	if m.closed {
+		m.mutex.Unlock()
+		return ErrClosed
+	}
+	queues := make([]chan Message, 0, len(m.groups[message.Topic]))
+	for _, queue := range m.groups[message.Topic] {
+		queues = append(queues, queue)
+	}
+	m.mutex.Unlock()
+
+	for _, queue := range queues {
+		select {
+		case queue <- message:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Consume implements Consumer; handler errors are dropped, use the Retry and DeadLetter middlewares
+func (m *Memory) Consume(ctx context.Context, topic, group string, handler Handler) error {
+	m.mutex.Lock()
+	if m.closed {
+		m.mutex.Unlock()
+		return ErrClosed
+	}
+	if m.groups[topic] == nil {
+		m.groups[topic] = make(map[string]chan Message)
+	}
+	queue, exists := m.groups[topic][group]
+	if !exists {
+		queue = make(chan Message, m.buffer)
+		m.groups[topic][group] = queue
+	}
+	m.mutex.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message := <-queue:
+			_ = handler(ctx, &message)
+		}
+	}
+}
+
+// Close implements Producer and Consumer
+func (m *Memory) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.closed = true
+	return nil
+}
@@ -0,0 +1,88 @@
+package broker
+
+import (
+	"context"
+	"sync"
+)
+
+// NATSConn is the part of a NATS connection used by NATS
+// GoAPI does not depend on a NATS driver; wrap the connection of the application, for nats.go:
+//
+//	type natsConn struct{ *nats.Conn }
+//
+//	func (n natsConn) Publish(subject string, header map[string][]string, data []byte) error {
+//		return n.Conn.PublishMsg(&nats.Msg{Subject: subject, Header: nats.Header(header), Data: data})
+//	}
+//	func (n natsConn) QueueSubscribe(subject, queue string, handler func(subject string, header map[string][]string, data []byte)) (func() error, error) {
+//		subscription, err := n.Conn.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
+//			handler(msg.Subject, msg.Header, msg.Data)
+//		})
+//		if err != nil {
+//			return nil, err
+//		}
+//		return subscription.Drain, nil
+//	}
+//	func (n natsConn) Close() error {
+//		return n.Conn.Drain()
+//	}
+type NATSConn interface {
+	Publish(subject string, header map[string][]string, data []byte) error
+	QueueSubscribe(subject, queue string, handler func(subject string, header map[string][]string, data []byte)) (unsubscribe func() error, err error)
+	Close() error
+}
+
+// NATS publishes and consumes messages on NATS subjects
+// Core NATS does not redeliver messages: failed ones are reported to OnError
+type NATS struct {
+	Conn    NATSConn
+	OnError func(message *Message, err error) // Default: ignore
+}
+
+// NewNATS creates a broker on a NATS connection
+func NewNATS(conn NATSConn) *NATS {
+	return &NATS{Conn: conn}
+}
+
+// Publish implements Producer; the topic is the subject and the key is not used
+func (n *NATS) Publish(_ context.Context, message Message) error {
+	var header map[string][]string
+	if len(message.Headers) > 0 {
+		header = make(map[string][]string, len(message.Headers))
+		for name, value := range message.Headers {
+			header[name] = []string{value}
+		}
+	}
+	return n.Conn.Publish(message.Topic, header, message.Value)
+}
+
+// Consume implements Consumer; the group is the queue group of the subscription
+// Messages are handled one at a time, in the order they arrive
+func (n *NATS) Consume(ctx context.Context, topic, group string, handler Handler) error {
+	var mutex sync.Mutex
+	unsubscribe, err := n.Conn.QueueSubscribe(topic, group, func(subject string, header map[string][]string, data []byte) {
+		message := &Message{Topic: subject, Value: data}
+		if len(header) > 0 {
+			message.Headers = make(map[string]string, len(header))
+			for name, values := range header {
+				if len(values) > 0 {
+					message.Headers[name] = values[0]
+				}
+			}
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err := handler(ctx, message); err != nil && n.OnError != nil {
+			n.OnError(message, err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return unsubscribe()
+}
+
+// Close implements Producer and Consumer
+func (n *NATS) Close() error {
+	return n.Conn.Close()
+}
@@ -0,0 +1,152 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+)
+
+// subscription is a consumer started by the plugin
+type subscription struct {
+	topic   string
+	group   string
+	handler Handler
+}
+
+// Plugin wires a producer and a consumer into a GoAPI instance
+// It registers the Producer as a dependency, starts the handlers registered with Handle when the
+// server starts and, on shutdown, stops them, waits for the messages being handled and closes the
+// producer and the consumer
+type Plugin struct {
+	Producer     Producer
+	Consumer     Consumer
+	Middlewares  []Middleware  // Applied to every handler, before its own middlewares
+	RestartDelay time.Duration // Wait before restarting a consumer whose Consume failed (default 5s)
+	OnError      func(topic string, err error)
+
+	subscriptions []subscription
+	cancel        context.CancelFunc
+	running       sync.WaitGroup
+}
+
+// NewPlugin creates a plugin; producer or consumer can be nil when the API only does one of them
+// Handlers get the Logging middleware by default
+func NewPlugin(producer Producer, consumer Consumer) *Plugin {
+	return &Plugin{
+		Producer:     producer,
+		Consumer:     consumer,
+		Middlewares:  []Middleware{Logging(nil)},
+		RestartDelay: 5 * time.Second,
+		OnError: func(topic string, err error) {
+			log.Printf("[broker] consumer of %s stopped: %v", topic, err)
+		},
+	}
+}
+
+// Use adds middlewares applied to every handler
+func (p *Plugin) Use(middlewares ...Middleware) *Plugin {
+	p.Middlewares = append(p.Middlewares, middlewares...)
+	return p
+}
+
+// Handle registers the handler of a topic for a consumer group, started with the server
+// Panics of the handler are recovered as errors, so its middlewares (Retry, DeadLetter) see them
+func (p *Plugin) Handle(topic, group string, handler Handler, middlewares ...Middleware) *Plugin {
+	p.subscriptions = append(p.subscriptions, subscription{
+		topic:   topic,
+		group:   group,
+		handler: Chain(Recover()(handler), middlewares...),
+	})
+	return p
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "broker"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	if len(p.subscriptions) > 0 && p.Consumer == nil {
+		return errors.New("broker: handlers registered without a consumer")
+	}
+	if p.Producer != nil {
+		api.RegisterDependency(func(c *gin.Context) (interface{}, error) {
+			return p.Producer, nil
+		}, (*Producer)(nil))
+	}
+	return nil
+}
+
+// OnStartup implements goapi.StartupPlugin
+func (p *Plugin) OnStartup(_ context.Context) error {
+	// Consumers live until shutdown, not until the startup context ends
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	for _, sub := range p.subscriptions {
+		handler := Chain(sub.handler, p.Middlewares...)
+		p.running.Add(1)
+		go p.consume(ctx, sub, handler)
+	}
+	return nil
+}
+
+// consume runs a consumer until ctx is canceled, restarting it when it fails
+func (p *Plugin) consume(ctx context.Context, sub subscription, handler Handler) {
+	defer p.running.Done()
+	for {
+		err := p.Consumer.Consume(ctx, sub.topic, sub.group, handler)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && p.OnError != nil {
+			p.OnError(sub.topic, err)
+		}
+		select {
+		case <-time.After(p.RestartDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// OnShutdown implements goapi.ShutdownPlugin
+func (p *Plugin) OnShutdown(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	stopped := make(chan struct{})
+	go func() {
+		p.running.Wait()
+		close(stopped)
+	}()
+
+	var errs []error
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		errs = append(errs, errors.New("broker: consumers still running at shutdown"))
+	}
+	if p.Consumer != nil {
+		errs = append(errs, p.Consumer.Close())
+	}
+	if p.Producer != nil && !sameBroker(p.Producer, p.Consumer) {
+		errs = append(errs, p.Producer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// sameBroker reports whether the producer and the consumer are the same value, closed only once
+func sameBroker(producer Producer, consumer Consumer) bool {
+	if consumer == nil || !reflect.TypeOf(producer).Comparable() {
+		return false
+	}
+	return any(producer) == any(consumer)
+}
@@ -0,0 +1,204 @@
+// Package cache keeps handler responses in memory with stale-while-revalidate semantics
+// Fresh responses are served from the cache; once stale they are still served, right away,
+// while a background request refreshes them. Responses carry Age and X-Cache (HIT, STALE or
+// MISS) headers, and entries are invalidated by key or by tag:
+//
+//	api.GET("/users", listUsers, goapi.WithCache(cache.Policy{
+//		TTL:                  time.Minute,
+//		StaleWhileRevalidate: 10 * time.Minute,
+//		Tags:                 []string{"users"},
+//	}))
+//
+//	api.POST("/users", func(c *gin.Context) {
+//		...
+//		cache.InvalidateTag("users")
+//	})
+package cache
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default is the cache used by policies without a Cache, and by the package-level functions
+var Default = New()
+
+// Invalidate removes the entries of keys from the default cache
+func Invalidate(keys ...string) int {
+	return Default.Invalidate(keys...)
+}
+
+// InvalidateTag removes the entries tagged with any of the tags from the default cache
+func InvalidateTag(tags ...string) int {
+	return Default.InvalidateTag(tags...)
+}
+
+// Entry is a cached response
+type Entry struct {
+	Status     int
+	Header     http.Header
+	Body       []byte
+	Tags       []string
+	StoredAt   time.Time
+	FreshUntil time.Time // Served as a HIT until then
+	StaleUntil time.Time // Then served as STALE, while revalidating, until then
+
+	refreshing bool
+}
+
+// Age returns the time since the response was stored
+func (e *Entry) Age(now time.Time) time.Duration {
+	return now.Sub(e.StoredAt)
+}
+
+// Cache keeps responses in memory
+// Entries are grouped by key (the request path and query by default); the variants of a key are
+// the responses for different values of the varying headers, and are invalidated together
+type Cache struct {
+	MaxEntries   int   // Cached responses kept (default 1000)
+	MaxBodyBytes int64 // Larger responses are not cached (default 1 MB)
+
+	mutex   sync.Mutex
+	entries map[string]map[string]*Entry // key => variant => entry
+	tags    map[string]map[string]bool   // tag => keys
+	count   int
+}
+
+// New creates an empty cache
+func New() *Cache {
+	return &Cache{
+		MaxEntries:   1000,
+		MaxBodyBytes: 1 << 20,
+		entries:      make(map[string]map[string]*Entry),
+		tags:         make(map[string]map[string]bool),
+	}
+}
+
+// Get returns the entry of a key variant, stale or not, until it expires
+func (c *Cache) Get(key, variant string) (*Entry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, found := c.entries[key][variant]
+	if !found || time.Now().After(entry.StaleUntil) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Set stores the entry of a key variant, replacing the previous one
+func (c *Cache) Set(key, variant string, entry *Entry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, exists := c.entries[key][variant]; !exists {
+		if c.count >= c.MaxEntries {
+			c.evict()
+		}
+		c.count++
+	}
+	if c.entries[key] == nil {
+		c.entries[key] = make(map[string]*Entry)
+	}
+	c.entries[key][variant] = entry
+	for _, tag := range entry.Tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]bool)
+		}
+		c.tags[tag][key] = true
+	}
+}
+
+// Invalidate removes every variant of the keys, returning the number of removed entries
+func (c *Cache) Invalidate(keys ...string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	removed := 0
+	for _, key := range keys {
+		removed += c.remove(key)
+	}
+	return removed
+}
+
+// InvalidateTag removes the entries tagged with any of the tags, returning their number
+func (c *Cache) InvalidateTag(tags ...string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	removed := 0
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			removed += c.remove(key)
+		}
+	}
+	return removed
+}
+
+// Purge removes every entry
+func (c *Cache) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[string]map[string]*Entry)
+	c.tags = make(map[string]map[string]bool)
+	c.count = 0
+}
+
+// Len returns the number of cached responses
+func (c *Cache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.count
+}
+
+// startRefresh marks an entry as being revalidated, reporting whether it was not already
+func (c *Cache) startRefresh(entry *Entry) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if entry.refreshing {
+		return false
+	}
+	entry.refreshing = true
+	return true
+}
+
+// endRefresh clears the mark of an entry whose revalidation did not replace it
+func (c *Cache) endRefresh(entry *Entry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry.refreshing = false
+}
+
+// remove deletes the variants of a key and its tag references; the caller holds the mutex
+func (c *Cache) remove(key string) int {
+	variants := c.entries[key]
+	for _, entry := range variants {
+		for _, tag := range entry.Tags {
+			delete(c.tags[tag], key)
+			if len(c.tags[tag]) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+	c.count -= len(variants)
+	delete(c.entries, key)
+	return len(variants)
+}
+
+// evict makes room for a new entry: expired keys go first, then an arbitrary one
+// The caller holds the mutex
+func (c *Cache) evict() {
+	now := time.Now()
+	for key, variants := range c.entries {
+		expired := true
+		for _, entry := range variants {
+			expired = expired && now.After(entry.StaleUntil)
+		}
+		if expired {
+			c.remove(key)
+		}
+	}
+	for key := range c.entries {
+		if c.count < c.MaxEntries {
+			return
+		}
+		c.remove(key)
+	}
+}
@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Policy configures the caching of a route
+// Only 200 responses to GET requests without Authorization or Cookie headers are cached, and never
+// when the handler answers Cache-Control no-store, no-cache or private, or sets cookies. A
+// max-age or stale-while-revalidate directive of the handler shortens the lifetimes
+type Policy struct {
+	Cache                *Cache        // Default: cache.Default
+	TTL                  time.Duration // Time a response is fresh (default 1 minute)
+	StaleWhileRevalidate time.Duration // Time a stale response is still served while it is refreshed
+	// Tags label the cached responses for InvalidateTag; "{name}" is replaced by the path
+	// parameter name ("user:{id}")
+	Tags []string
+	Vary []string                    // Request headers that select a variant (Accept-Language, for example)
+	Key  func(c *gin.Context) string // Default: the path and the query
+}
+
+// revalidationKey marks the background requests refreshing stale entries
+type revalidationKey struct{}
+
+// Middleware returns the caching middleware of the policy
+// Stale responses are refreshed by sending the request again to revalidate (the API) in the
+// background; with a nil handler they are not served
+func (p Policy) Middleware(revalidate http.Handler) gin.HandlerFunc {
+	cache := p.Cache
+	if cache == nil {
+		cache = Default
+	}
+	if p.TTL <= 0 {
+		p.TTL = time.Minute
+	}
+	if revalidate == nil {
+		p.StaleWhileRevalidate = 0
+	}
+
+	return func(c *gin.Context) {
+		request := c.Request
+		if request.Method != http.MethodGet || request.Header.Get("Authorization") != "" || request.Header.Get("Cookie") != "" {
+			c.Next()
+			return
+		}
+		key, variant := p.key(c), p.variant(request)
+
+		_, revalidating := request.Context().Value(revalidationKey{}).(bool)
+		if !revalidating && request.Header.Get("Cache-Control") != "no-cache" {
+			if entry, found := cache.Get(key, variant); found {
+				now := time.Now()
+				if now.Before(entry.FreshUntil) {
+					serve(c, entry, "HIT", now)
+					return
+				}
+				serve(c, entry, "STALE", now)
+				if revalidate != nil && cache.startRefresh(entry) {
+					go refresh(cache, entry, revalidate, request)
+				}
+				return
+			}
+		}
+
+		writer := &recorder{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		defer func() { c.Writer = writer.ResponseWriter }()
+		c.Header("X-Cache", "MISS")
+		c.Next()
+
+		if writer.streaming {
+			return
+		}
+		if fresh, stale, storable := p.lifetimes(writer); storable && int64(writer.body.Len()) <= cache.MaxBodyBytes {
+			header := writer.Header().Clone()
+			header.Del("X-Cache")
+			now := time.Now()
+			cache.Set(key, variant, &Entry{
+				Status:     writer.status,
+				Header:     header,
+				Body:       bytes.Clone(writer.body.Bytes()),
+				Tags:       p.tags(c),
+				StoredAt:   now,
+				FreshUntil: now.Add(fresh),
+				StaleUntil: now.Add(fresh + stale),
+			})
+		}
+		if writer.written {
+			writer.ResponseWriter.WriteHeader(writer.status)
+			_, _ = writer.ResponseWriter.Write(writer.body.Bytes())
+		}
+	}
+}
+
+// serve writes a cached response with its Age and X-Cache headers
+func serve(c *gin.Context, entry *Entry, state string, now time.Time) {
+	header := c.Writer.Header()
+	for name, values := range entry.Header {
+		if _, exists := header[name]; !exists {
+			header[name] = values
+		}
+	}
+	header.Set("Age", strconv.Itoa(int(entry.Age(now)/time.Second)))
+	header.Set("X-Cache", state)
+	c.Status(entry.Status)
+	_, _ = c.Writer.Write(entry.Body)
+	c.Abort()
+}
+
+// refresh sends a request again through the API to replace a stale entry
+func refresh(cache *Cache, entry *Entry, revalidate http.Handler, request *http.Request) {
+	defer cache.endRefresh(entry)
+	ctx := context.WithValue(context.WithoutCancel(request.Context()), revalidationKey{}, true)
+	revalidate.ServeHTTP(discardWriter{header: make(http.Header)}, request.Clone(ctx))
+}
+
+// key returns the cache key of a request
+func (p Policy) key(c *gin.Context) string {
+	if p.Key != nil {
+		return p.Key(c)
+	}
+	if c.Request.URL.RawQuery == "" {
+		return c.Request.URL.Path
+	}
+	return c.Request.URL.Path + "?" + c.Request.URL.RawQuery
+}
+
+// variant identifies the response variant of a request; bodies are cached as encoded
+func (p Policy) variant(request *http.Request) string {
+	var variant strings.Builder
+	variant.WriteString(request.Header.Get("Accept-Encoding"))
+	for _, name := range p.Vary {
+		variant.WriteString("\n")
+		variant.WriteString(request.Header.Get(name))
+	}
+	return variant.String()
+}
+
+// tags expands the path parameters in the tags of the policy
+func (p Policy) tags(c *gin.Context) []string {
+	tags := make([]string, len(p.Tags))
+	for i, tag := range p.Tags {
+		for _, param := range c.Params {
+			tag = strings.ReplaceAll(tag, "{"+param.Key+"}", param.Value)
+		}
+		tags[i] = tag
+	}
+	return tags
+}
+
+// lifetimes returns how long a response is fresh and then served stale, and whether it may be cached
+func (p Policy) lifetimes(writer *recorder) (time.Duration, time.Duration, bool) {
+	if writer.status != http.StatusOK || writer.Header().Get("Set-Cookie") != "" {
+		return 0, 0, false
+	}
+	fresh, stale := p.TTL, p.StaleWhileRevalidate
+	for _, directive := range strings.Split(writer.Header().Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		name, value, _ := strings.Cut(directive, "=")
+		seconds, err := strconv.Atoi(value)
+		switch {
+		case directive == "no-store", directive == "no-cache", directive == "private":
+			return 0, 0, false
+		case name == "max-age" && err == nil:
+			fresh = min(fresh, time.Duration(seconds)*time.Second)
+		case name == "stale-while-revalidate" && err == nil:
+			stale = min(stale, time.Duration(seconds)*time.Second)
+		}
+	}
+	return fresh, stale, fresh > 0
+}
+
+// recorder holds the response until the handler returns so it can be cached
+// Flushing (streaming responses) switches it to pass-through mode
+type recorder struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	status    int
+	written   bool
+	streaming bool
+}
+
+// WriteHeader records the status code
+func (w *recorder) WriteHeader(statusCode int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	w.status = statusCode
+}
+
+// WriteHeaderNow marks the response as written without sending it
+func (w *recorder) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+// Write buffers the body
+func (w *recorder) Write(data []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	w.written = true
+	return w.body.Write(data)
+}
+
+// WriteString buffers the body
+func (w *recorder) WriteString(data string) (int, error) {
+	return w.Write([]byte(data))
+}
+
+// Status returns the recorded status code
+func (w *recorder) Status() int {
+	if w.streaming {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+// Size returns the buffered body size
+func (w *recorder) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Written reports whether the handler produced a response
+func (w *recorder) Written() bool {
+	if w.streaming {
+		return w.ResponseWriter.Written()
+	}
+	return w.written
+}
+
+// Flush sends the buffered data and streams the rest of the response uncached
+func (w *recorder) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		w.ResponseWriter.WriteHeader(w.status)
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+		w.body.Reset()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// discardWriter receives the responses of background revalidations
+type discardWriter struct {
+	header http.Header
+}
+
+func (w discardWriter) Header() http.Header            { return w.header }
+func (w discardWriter) Write(data []byte) (int, error) { return len(data), nil }
+func (w discardWriter) WriteHeader(int)                {}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/bench"
+)
+
+// runBench handles "goapi bench"
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	baselineFile := flags.String("baseline", "", "compare against the results saved in this file")
+	saveFile := flags.String("save", "", "save the results to this file, to be used as baseline")
+	tolerance := flags.Float64("tolerance", 0.10, "growth over the baseline reported as a regression (0.10 = 10%)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var baseline []bench.Result
+	if *baselineFile != "" {
+		file, err := os.Open(*baselineFile)
+		if err != nil {
+			return err
+		}
+		baseline, err = bench.ReadBaseline(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	results, err := bench.Run(bench.Suite())
+	if err != nil {
+		return err
+	}
+	if err := bench.WriteTable(os.Stdout, results); err != nil {
+		return err
+	}
+
+	if *saveFile != "" {
+		file, err := os.Create(*saveFile)
+		if err != nil {
+			return err
+		}
+		if err := errors.Join(bench.WriteBaseline(file, results), file.Close()); err != nil {
+			return err
+		}
+	}
+
+	if baseline != nil {
+		regressions := bench.Compare(results, baseline, *tolerance)
+		if len(regressions) > 0 {
+			fmt.Println()
+			for _, regression := range regressions {
+				fmt.Println("regression:", regression)
+			}
+			return fmt.Errorf("%d performance regressions over %s", len(regressions), *baselineFile)
+		}
+		fmt.Printf("\nNo regressions over %s\n", *baselineFile)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+)
+
+// runDiff handles "goapi diff": it compares a committed document with the current one and fails
+// when there are breaking changes
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	previous := flags.String("spec", "openapi.json", "URL or file of the committed OpenAPI document")
+	current := flags.String("url", defaultSpec, "URL or file of the current OpenAPI document")
+	all := flags.Bool("all", false, "list compatible changes too")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	previousDocument, err := readDocument(*previous)
+	if err != nil {
+		return err
+	}
+	currentDocument, err := readDocument(*current)
+	if err != nil {
+		return err
+	}
+
+	changes := openapi.Diff(previousDocument, currentDocument)
+	breaking := openapi.Breaking(changes)
+	for _, change := range changes {
+		switch {
+		case change.Breaking:
+			fmt.Println("BREAKING  " + change.String())
+		case *all:
+			fmt.Println("          " + change.String())
+		}
+	}
+	if len(breaking) > 0 {
+		return fmt.Errorf("%d breaking changes", len(breaking))
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(os.Stderr, "No changes")
+	}
+	return nil
+}
+
+// readDocument reads and parses an OpenAPI document from a URL or a file
+func readDocument(location string) (*openapi.Document, error) {
+	data, err := readLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	document, err := openapi.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", location, err)
+	}
+	return document, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/codegen"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+)
+
+// defaultSpec is the document served by a GoAPI application running locally
+const defaultSpec = "http://localhost:8080/openapi.json"
+
+// runGenerate handles "goapi generate <target>"
+func runGenerate(args []string) error {
+	if len(args) == 0 || args[0] != "client" {
+		fmt.Fprintln(os.Stderr, "Usage: goapi generate client --lang go|ts [--spec URL|file] [--out file] [--package name]")
+		return errors.New("generate expects a target: client")
+	}
+
+	flags := flag.NewFlagSet("generate client", flag.ContinueOnError)
+	lang := flags.String("lang", "", "client language: "+strings.Join(codegen.Languages(), ", "))
+	spec := flags.String("spec", defaultSpec, "URL or file of the OpenAPI document")
+	out := flags.String("out", "", "output file (default: standard output)")
+	pkg := flags.String("package", "client", "package name of Go clients")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *lang == "" {
+		flags.Usage()
+		return errors.New("--lang is required")
+	}
+
+	data, err := readLocation(*spec)
+	if err != nil {
+		return err
+	}
+	document, err := openapi.Parse(data)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", *spec, err)
+	}
+
+	source, err := codegen.Generate(document, codegen.Options{Language: *lang, Package: *pkg})
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return os.WriteFile(*out, source, 0o644)
+}
+
+// readLocation reads a document from a URL or a file
+func readLocation(location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(location)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", location, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d", location, response.StatusCode)
+	}
+	return io.ReadAll(response.Body)
+}
@@ -0,0 +1,61 @@
+// Command goapi provides development tools for GoAPI applications
+//
+// Usage:
+//
+//	goapi generate client --lang go|ts [--spec URL|file] [--out file] [--package name]
+//	goapi routes [--url URL|file] [--json]
+//	goapi diff [--spec URL|file] [--url URL|file] [--all]
+//	goapi bench [--baseline file] [--save file] [--tolerance 0.10]
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a subcommand of the CLI
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+// commands lists the top-level commands
+var commands = []command{
+	{name: "generate", description: "Generate code from the OpenAPI document", run: runGenerate},
+	{name: "routes", description: "List the routes of a running application", run: runRoutes},
+	{name: "diff", description: "Report breaking changes against a committed OpenAPI document", run: runDiff},
+	{name: "bench", description: "Measure the framework overhead against plain gin", run: runBench},
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "goapi:", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches the arguments to a command
+func run(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		return nil
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+	usage()
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// usage prints the available commands
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: goapi <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+}
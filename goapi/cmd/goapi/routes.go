@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+)
+
+// defaultRoutes is the route table served by a GoAPI application running locally in debug mode
+const defaultRoutes = "http://localhost:8080" + goapi.DebugRoutesPath
+
+// runRoutes handles "goapi routes"
+func runRoutes(args []string) error {
+	flags := flag.NewFlagSet("routes", flag.ContinueOnError)
+	location := flags.String("url", defaultRoutes, "URL or file of the route table (served in debug mode)")
+	asJSON := flags.Bool("json", false, "print the raw JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := readLocation(*location)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	var routes []goapi.RouteInfo
+	if err := json.Unmarshal(data, &routes); err != nil {
+		return fmt.Errorf("error parsing %s: %w", *location, err)
+	}
+	return goapi.WriteRouteTable(os.Stdout, routes)
+}
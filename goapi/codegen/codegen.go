@@ -0,0 +1,449 @@
+// Package codegen generates typed API clients from the OpenAPI document of a GoAPI application
+// The document is turned into a language-neutral model (types, operations, parameters) that is
+// rendered with a template per language, so clients stay in sync with the route definitions
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+)
+
+// Options configures the generated client
+type Options struct {
+	Language string // "go" or "ts"
+	Package  string // Package name of Go clients (default "client")
+}
+
+// language renders a client model in a target language
+type language interface {
+	// Type expressions
+	scalar(typeName, format string) string
+	array(item string) string
+	dictionary(value string) string
+	unknown() string
+
+	// Identifiers
+	typeName(name string) string
+	operationName(name string) string
+	fieldName(jsonName string) string
+	paramName(name string) string
+	paramField(name string) string
+
+	// pathExpression builds the expression evaluating to the request path
+	pathExpression(segments []pathSegment) string
+	template() string
+	format(source []byte) ([]byte, error)
+}
+
+// languages holds the supported languages by name
+var languages = map[string]language{
+	"go": golang{},
+	"ts": typescript{},
+}
+
+// Languages returns the names of the supported languages
+func Languages() []string {
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Generate renders the client of a document
+func Generate(document *openapi.Document, options Options) ([]byte, error) {
+	lang, supported := languages[options.Language]
+	if !supported {
+		return nil, fmt.Errorf("unsupported language %q (supported: %s)", options.Language, strings.Join(Languages(), ", "))
+	}
+	if options.Package == "" {
+		options.Package = "client"
+	}
+
+	model := newBuilder(lang, document).build()
+	model.Package = options.Package
+
+	tmpl, err := template.New(options.Language).Funcs(template.FuncMap{
+		"comment": comment,
+		"quote":   strconv.Quote,
+	}).Parse(lang.template())
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, model); err != nil {
+		return nil, err
+	}
+	return lang.format(buffer.Bytes())
+}
+
+// clientModel is the data rendered by the language templates
+type clientModel struct {
+	Package     string
+	Title       string
+	Version     string
+	Description string
+	Types       []*typeModel
+	Operations  []*operationModel
+}
+
+// typeModel is an object schema rendered as a named type
+type typeModel struct {
+	Name        string
+	Description string
+	Fields      []fieldModel
+}
+
+// fieldModel is a property of an object schema
+type fieldModel struct {
+	Name        string
+	JSONName    string
+	Type        string
+	Description string
+	Required    bool
+}
+
+// operationModel is an operation rendered as a client method
+type operationModel struct {
+	Name         string
+	TypeName     string // Prefix of the types generated for the operation
+	Method       string
+	Path         string
+	PathExpr     string
+	Summary      string
+	Deprecated   bool
+	PathParams   []paramModel
+	QueryParams  []paramModel
+	HeaderParams []paramModel
+	Body         string // Type of the JSON body, "" without body
+	Result       string // Type of the successful response, "" without content
+}
+
+// HasParams reports whether the operation takes query or header parameters
+func (o *operationModel) HasParams() bool {
+	return len(o.QueryParams)+len(o.HeaderParams) > 0
+}
+
+// RequiresParams reports whether any query or header parameter is required
+func (o *operationModel) RequiresParams() bool {
+	for _, params := range [][]paramModel{o.QueryParams, o.HeaderParams} {
+		for _, param := range params {
+			if param.Required {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// paramModel is a path, query or header parameter
+type paramModel struct {
+	Name     string // Argument name
+	Field    string // Field name in the parameters type
+	Original string // Name on the wire
+	Type     string
+	Required bool
+	Array    bool
+}
+
+// pathSegment is a literal part of a path or a parameter reference
+type pathSegment struct {
+	Literal string
+	Param   *paramModel
+}
+
+// builder converts a document into a client model
+type builder struct {
+	lang        language
+	document    *openapi.Document
+	types       []*typeModel
+	names       map[string]bool   // Type and operation names in use
+	definitions map[string]string // Definition name -> type name
+}
+
+// newBuilder creates a builder for a document
+func newBuilder(lang language, document *openapi.Document) *builder {
+	return &builder{
+		lang:        lang,
+		document:    document,
+		names:       make(map[string]bool),
+		definitions: make(map[string]string),
+	}
+}
+
+// build converts the whole document
+func (b *builder) build() *clientModel {
+	model := &clientModel{
+		Title:       b.document.Info.Title,
+		Version:     b.document.Info.Version,
+		Description: b.document.Info.Description,
+	}
+
+	// Definitions keep their names, so they are reserved before inline types are named
+	definitionNames := make([]string, 0, len(b.document.Definitions))
+	for name := range b.document.Definitions {
+		definitionNames = append(definitionNames, name)
+	}
+	sort.Strings(definitionNames)
+	for _, name := range definitionNames {
+		b.definition(name)
+	}
+
+	paths := make([]string, 0, len(b.document.Paths))
+	for path := range b.document.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	operationNames := make(map[string]bool)
+	for _, path := range paths {
+		item := b.document.Paths[path]
+		for _, method := range openapi.Methods {
+			if operation := item.Operation(method); operation != nil {
+				model.Operations = append(model.Operations, b.operation(method, path, item, operation, operationNames))
+			}
+		}
+	}
+
+	sort.Slice(b.types, func(i, j int) bool {
+		return b.types[i].Name < b.types[j].Name
+	})
+	model.Types = b.types
+	return model
+}
+
+// operation converts a single operation
+func (b *builder) operation(method, path string, item *openapi.PathItem, operation *openapi.Operation, used map[string]bool) *operationModel {
+	name := operation.OperationID
+	if name == "" {
+		name = method + " " + strings.NewReplacer("{", "by ", "}", "").Replace(path)
+	}
+	name = b.lang.operationName(name)
+	for base, i := name, 2; used[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	used[name] = true
+	b.names[b.lang.typeName(name)+"Params"] = true // Type of the query and header parameters
+
+	model := &operationModel{
+		Name:       name,
+		TypeName:   b.lang.typeName(name),
+		Method:     method,
+		Path:       path,
+		Summary:    operation.Summary,
+		Deprecated: operation.Deprecated,
+	}
+
+	parameters := append(append([]openapi.Parameter(nil), item.Parameters...), operation.Parameters...)
+	for _, parameter := range parameters {
+		switch parameter.In {
+		case "path":
+			model.PathParams = append(model.PathParams, b.param(parameter))
+		case "query":
+			model.QueryParams = append(model.QueryParams, b.param(parameter))
+		case "header":
+			model.HeaderParams = append(model.HeaderParams, b.param(parameter))
+		case "body":
+			model.Body = b.typeOf(parameter.Schema, model.TypeName+"Request")
+		}
+	}
+
+	segments := b.pathSegments(path, model)
+	model.PathExpr = b.lang.pathExpression(segments)
+
+	for _, code := range operation.Responses.StatusCodes() {
+		status, err := strconv.Atoi(code)
+		if err != nil || status < 200 || status >= 300 {
+			continue
+		}
+		if response := operation.Responses[code]; response.Schema != nil {
+			model.Result = b.typeOf(response.Schema, model.TypeName+"Response")
+		}
+		break
+	}
+	return model
+}
+
+// pathSegments splits a templated path, adding undeclared parameters as string path parameters
+func (b *builder) pathSegments(path string, model *operationModel) []pathSegment {
+	var segments []pathSegment
+	for len(path) > 0 {
+		start := strings.Index(path, "{")
+		end := strings.Index(path, "}")
+		if start < 0 || end < start {
+			segments = append(segments, pathSegment{Literal: path})
+			break
+		}
+		if start > 0 {
+			segments = append(segments, pathSegment{Literal: path[:start]})
+		}
+
+		name := path[start+1 : end]
+		index := -1
+		for i, param := range model.PathParams {
+			if param.Original == name {
+				index = i
+			}
+		}
+		if index < 0 {
+			model.PathParams = append(model.PathParams, b.param(openapi.Parameter{Name: name, In: "path", Required: true, Type: "string"}))
+			index = len(model.PathParams) - 1
+		}
+		param := model.PathParams[index]
+		segments = append(segments, pathSegment{Param: &param})
+		path = path[end+1:]
+	}
+	return segments
+}
+
+// param converts a non-body parameter
+func (b *builder) param(parameter openapi.Parameter) paramModel {
+	param := paramModel{
+		Name:     b.lang.paramName(parameter.Name),
+		Field:    b.lang.paramField(parameter.Name),
+		Original: parameter.Name,
+		Required: parameter.Required || parameter.In == "path",
+	}
+	if parameter.Type == "array" {
+		param.Array = true
+		item := "string"
+		format := ""
+		if parameter.Items != nil {
+			item, format = parameter.Items.Type, parameter.Items.Format
+		}
+		param.Type = b.lang.array(b.lang.scalar(item, format))
+	} else {
+		param.Type = b.lang.scalar(parameter.Type, parameter.Format)
+	}
+	return param
+}
+
+// typeOf returns the type expression of a schema, naming inline objects after the hint
+func (b *builder) typeOf(schema *openapi.Schema, hint string) string {
+	if schema == nil {
+		return b.lang.unknown()
+	}
+	if schema.Ref != "" {
+		return b.definition(strings.TrimPrefix(schema.Ref, "#/definitions/"))
+	}
+
+	switch schema.Type {
+	case "array":
+		return b.lang.array(b.typeOf(schema.Items, hint+"Item"))
+	case "object", "":
+		if len(schema.Properties) > 0 {
+			return b.object(schema, b.unique(b.lang.typeName(hint)))
+		}
+		if schema.AdditionalProperties != nil {
+			return b.lang.dictionary(b.typeOf(schema.AdditionalProperties, hint+"Value"))
+		}
+		if schema.Type == "object" {
+			return b.lang.dictionary(b.lang.unknown())
+		}
+		return b.lang.unknown()
+	}
+	return b.lang.scalar(schema.Type, schema.Format)
+}
+
+// definition returns the type of a named definition, converting it on first use
+func (b *builder) definition(name string) string {
+	if typeName, converted := b.definitions[name]; converted {
+		return typeName
+	}
+	schema, exists := b.document.Definitions[name]
+	if !exists {
+		return b.lang.unknown()
+	}
+
+	typeName := b.unique(b.lang.typeName(name))
+	b.definitions[name] = typeName // Registered first so recursive schemas terminate
+	if len(schema.Properties) == 0 {
+		// Non-object definitions are inlined
+		resolved := b.typeOf(schema, name)
+		b.definitions[name] = resolved
+		return resolved
+	}
+	return b.object(schema, typeName)
+}
+
+// object converts an object schema into a named type
+func (b *builder) object(schema *openapi.Schema, name string) string {
+	model := &typeModel{Name: name, Description: schema.Description}
+	b.types = append(b.types, model)
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, property := range schema.Required {
+		required[property] = true
+	}
+
+	properties := make([]string, 0, len(schema.Properties))
+	for property := range schema.Properties {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	for _, property := range properties {
+		propertySchema := schema.Properties[property]
+		model.Fields = append(model.Fields, fieldModel{
+			Name:        b.lang.fieldName(property),
+			JSONName:    property,
+			Type:        b.typeOf(propertySchema, name+" "+property),
+			Description: propertySchema.Description,
+			Required:    required[property],
+		})
+	}
+	return name
+}
+
+// unique returns a type name not used yet
+func (b *builder) unique(name string) string {
+	for base, i := name, 2; b.names[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	b.names[name] = true
+	return name
+}
+
+// words splits an identifier, path or sentence into lower-case words
+// "listUsers", "list_users" and "/list/users" all give ["list", "users"]
+func words(s string) []string {
+	var result []string
+	var current []rune
+	runes := []rune(s)
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return result
+}
+
+// comment collapses a description into a single comment line
+func comment(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,320 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// golang renders Go clients built on net/http
+type golang struct{}
+
+// goInitialisms are words written in upper case in Go identifiers
+var goInitialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "https": true, "id": true, "ip": true,
+	"json": true, "jwt": true, "sql": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// goReserved are the names a parameter argument cannot take
+var goReserved = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true,
+	// Names used by the generated methods and their imports
+	"body": true, "c": true, "ctx": true, "err": true, "header": true, "params": true, "query": true,
+	"result": true, "bytes": true, "context": true, "fmt": true, "http": true, "io": true, "json": true,
+	"strings": true, "url": true,
+}
+
+func (golang) scalar(typeName, format string) string {
+	switch typeName {
+	case "integer":
+		if format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "string", "file":
+		return "string"
+	}
+	return "interface{}"
+}
+
+func (golang) array(item string) string {
+	return "[]" + item
+}
+
+func (golang) dictionary(value string) string {
+	return "map[string]" + value
+}
+
+func (golang) unknown() string {
+	return "interface{}"
+}
+
+func (golang) typeName(name string) string {
+	return goIdentifier(words(name), true)
+}
+
+func (golang) operationName(name string) string {
+	return goIdentifier(words(name), true)
+}
+
+func (golang) fieldName(jsonName string) string {
+	return goIdentifier(words(jsonName), true)
+}
+
+func (golang) paramName(name string) string {
+	identifier := goIdentifier(words(name), false)
+	if goReserved[identifier] {
+		identifier += "Param"
+	}
+	return identifier
+}
+
+func (golang) paramField(name string) string {
+	return goIdentifier(words(name), true)
+}
+
+func (golang) pathExpression(segments []pathSegment) string {
+	parts := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment.Param != nil {
+			parts = append(parts, fmt.Sprintf("url.PathEscape(formatValue(%s))", segment.Param.Name))
+		} else {
+			parts = append(parts, strconv.Quote(segment.Literal))
+		}
+	}
+	if len(parts) == 0 {
+		return `"/"`
+	}
+	return strings.Join(parts, " + ")
+}
+
+func (golang) template() string {
+	return goTemplate
+}
+
+func (golang) format(source []byte) ([]byte, error) {
+	formatted, err := format.Source(source)
+	if err != nil {
+		return nil, fmt.Errorf("generated Go code is invalid: %w", err)
+	}
+	return formatted, nil
+}
+
+// goIdentifier joins words in camel case, exported or not
+func goIdentifier(parts []string, exported bool) string {
+	var builder strings.Builder
+	for i, part := range parts {
+		switch {
+		case i == 0 && !exported:
+			builder.WriteString(part)
+		case goInitialisms[part]:
+			builder.WriteString(strings.ToUpper(part))
+		default:
+			builder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+
+	identifier := builder.String()
+	if identifier == "" {
+		identifier = "value"
+	}
+	if identifier[0] >= '0' && identifier[0] <= '9' {
+		identifier = "N" + identifier
+	}
+	if exported {
+		identifier = strings.ToUpper(identifier[:1]) + identifier[1:]
+	}
+	return identifier
+}
+
+const goTemplate = `// Code generated by goapi generate client. DO NOT EDIT.
+
+// Package {{.Package}} is a typed client of {{if .Title}}{{comment .Title}}{{else}}the API{{end}}{{if .Version}} {{comment .Version}}{{end}}
+package {{.Package}}
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the API
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Header is sent with every request (e.g. Authorization)
+	Header http.Header
+}
+
+// NewClient creates a client of the API served at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Header:     make(http.Header),
+	}
+}
+
+// Error is returned for responses with a non-2xx status
+type Error struct {
+	StatusCode int    ` + "`json:\"-\"`" + `
+	Detail     string ` + "`json:\"detail\"`" + `
+	Type       string ` + "`json:\"type\"`" + `
+	Body       []byte ` + "`json:\"-\"`" + `
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%d: %s", e.StatusCode, e.Detail)
+	}
+	return fmt.Sprintf("unexpected status %d", e.StatusCode)
+}
+{{range .Types}}
+// {{.Name}} {{if .Description}}{{comment .Description}}{{else}}is generated from the API schema{{end}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{if .Description}}// {{comment .Description}}
+	{{end}}{{.Name}} {{.Type}} ` + "`json:\"{{.JSONName}}{{if not .Required}},omitempty{{end}}\"`" + `
+{{- end}}
+}
+{{end}}
+{{- range .Operations}}
+{{- if .HasParams}}
+
+// {{.TypeName}}Params holds the query and header parameters of {{.Name}}
+type {{.TypeName}}Params struct {
+{{- range .QueryParams}}
+	{{.Field}} {{if not (or .Required .Array)}}*{{end}}{{.Type}}
+{{- end}}
+{{- range .HeaderParams}}
+	{{.Field}} {{if not (or .Required .Array)}}*{{end}}{{.Type}}
+{{- end}}
+}
+{{- end}}
+
+// {{.Name}} calls {{.Method}} {{.Path}}{{if .Summary}}: {{comment .Summary}}{{end}}
+{{- if .Deprecated}}
+//
+// Deprecated: the operation is deprecated by the API
+{{- end}}
+func (c *Client) {{.Name}}(ctx context.Context{{range .PathParams}}, {{.Name}} {{.Type}}{{end}}{{if .Body}}, body {{.Body}}{{end}}{{if .HasParams}}, params *{{.TypeName}}Params{{end}}) {{if .Result}}({{.Result}}, error){{else}}error{{end}} {
+	query := url.Values{}
+	header := http.Header{}
+{{- if .HasParams}}
+	if params != nil {
+{{- range .QueryParams}}
+{{- if .Array}}
+		for _, value := range params.{{.Field}} {
+			query.Add({{quote .Original}}, formatValue(value))
+		}
+{{- else if .Required}}
+		query.Set({{quote .Original}}, formatValue(params.{{.Field}}))
+{{- else}}
+		if params.{{.Field}} != nil {
+			query.Set({{quote .Original}}, formatValue(*params.{{.Field}}))
+		}
+{{- end}}
+{{- end}}
+{{- range .HeaderParams}}
+{{- if .Array}}
+		for _, value := range params.{{.Field}} {
+			header.Add({{quote .Original}}, formatValue(value))
+		}
+{{- else if .Required}}
+		header.Set({{quote .Original}}, formatValue(params.{{.Field}}))
+{{- else}}
+		if params.{{.Field}} != nil {
+			header.Set({{quote .Original}}, formatValue(*params.{{.Field}}))
+		}
+{{- end}}
+{{- end}}
+	}
+{{- end}}
+{{- if .Result}}
+	var result {{.Result}}
+	err := c.do(ctx, {{quote .Method}}, {{.PathExpr}}, query, header, {{if .Body}}body{{else}}nil{{end}}, &result)
+	return result, err
+{{- else}}
+	return c.do(ctx, {{quote .Method}}, {{.PathExpr}}, query, header, {{if .Body}}body{{else}}nil{{end}}, nil)
+{{- end}}
+}
+{{- end}}
+
+// do sends a request and decodes the JSON response into result
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	target := strings.TrimRight(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range c.Header {
+		request.Header[name] = append([]string(nil), values...)
+	}
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		apiError := &Error{StatusCode: response.StatusCode, Body: data}
+		_ = json.Unmarshal(data, apiError)
+		return apiError
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+// formatValue formats a parameter value
+func formatValue(value interface{}) string {
+	return fmt.Sprint(value)
+}
+`
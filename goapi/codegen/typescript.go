@@ -0,0 +1,254 @@
+package codegen
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// typescript renders TypeScript clients built on fetch
+type typescript struct{}
+
+// tsIdentifierPattern matches names usable as TypeScript identifiers and unquoted property names
+var tsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsReserved are the names a parameter argument cannot take
+var tsReserved = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+	"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	// Names used by the generated methods
+	"body": true, "headers": true, "params": true, "query": true,
+}
+
+func (typescript) scalar(typeName, format string) string {
+	switch typeName {
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "string", "file":
+		return "string"
+	}
+	return "unknown"
+}
+
+func (typescript) array(item string) string {
+	if strings.ContainsAny(item, " <|") {
+		return "Array<" + item + ">"
+	}
+	return item + "[]"
+}
+
+func (typescript) dictionary(value string) string {
+	return "Record<string, " + value + ">"
+}
+
+func (typescript) unknown() string {
+	return "unknown"
+}
+
+func (typescript) typeName(name string) string {
+	return tsIdentifier(words(name), true)
+}
+
+func (typescript) operationName(name string) string {
+	return tsIdentifier(words(name), false)
+}
+
+func (typescript) fieldName(jsonName string) string {
+	return tsProperty(jsonName)
+}
+
+func (typescript) paramName(name string) string {
+	identifier := tsIdentifier(words(name), false)
+	if tsReserved[identifier] {
+		identifier += "Param"
+	}
+	return identifier
+}
+
+func (typescript) paramField(name string) string {
+	return tsProperty(name)
+}
+
+func (typescript) pathExpression(segments []pathSegment) string {
+	var builder strings.Builder
+	builder.WriteString("`")
+	for _, segment := range segments {
+		if segment.Param != nil {
+			builder.WriteString("${encodeURIComponent(String(" + segment.Param.Name + "))}")
+		} else {
+			builder.WriteString(strings.NewReplacer("`", "\\`", "${", "\\${").Replace(segment.Literal))
+		}
+	}
+	builder.WriteString("`")
+	return builder.String()
+}
+
+func (typescript) template() string {
+	return typescriptTemplate
+}
+
+func (typescript) format(source []byte) ([]byte, error) {
+	return source, nil
+}
+
+// tsIdentifier joins words in camel case, upper or lower
+func tsIdentifier(parts []string, upper bool) string {
+	var builder strings.Builder
+	for i, part := range parts {
+		if i == 0 && !upper {
+			builder.WriteString(part)
+		} else {
+			builder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+
+	identifier := builder.String()
+	if identifier == "" {
+		identifier = "value"
+	}
+	if identifier[0] >= '0' && identifier[0] <= '9' {
+		identifier = "_" + identifier
+	}
+	return identifier
+}
+
+// tsProperty returns a property name, quoted when it is not an identifier
+func tsProperty(name string) string {
+	if tsIdentifierPattern.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+const typescriptTemplate = `// Code generated by goapi generate client. DO NOT EDIT.
+// Typed client of {{if .Title}}{{comment .Title}}{{else}}the API{{end}}{{if .Version}} {{comment .Version}}{{end}}
+{{range .Types}}
+/** {{if .Description}}{{comment .Description}}{{else}}Generated from the API schema{{end}} */
+export interface {{.Name}} {
+{{- range .Fields}}
+{{- if .Description}}
+  /** {{comment .Description}} */
+{{- end}}
+  {{.Name}}{{if not .Required}}?{{end}}: {{.Type}};
+{{- end}}
+}
+{{end}}
+{{- range .Operations}}
+{{- if .HasParams}}
+/** Query and header parameters of {{.Name}} */
+export interface {{.TypeName}}Params {
+{{- range .QueryParams}}
+  {{.Field}}{{if not .Required}}?{{end}}: {{.Type}};
+{{- end}}
+{{- range .HeaderParams}}
+  {{.Field}}{{if not .Required}}?{{end}}: {{.Type}};
+{{- end}}
+}
+{{end}}
+{{- end}}
+/** Error thrown for responses with a non-2xx status */
+export class ApiError extends Error {
+  constructor(
+    public readonly status: number,
+    public readonly body: unknown,
+    public readonly detail?: string,
+  ) {
+    super(detail ? ` + "`${status}: ${detail}`" + ` : ` + "`unexpected status ${status}`" + `);
+    this.name = "ApiError";
+  }
+}
+
+export interface ClientOptions {
+  /** Headers sent with every request (e.g. Authorization) */
+  headers?: Record<string, string>;
+  /** fetch implementation (default: the global fetch) */
+  fetch?: typeof fetch;
+}
+
+/** Client of the API */
+export class Client {
+  private readonly baseURL: string;
+  private readonly headers: Record<string, string>;
+  private readonly fetchImpl: typeof fetch;
+
+  constructor(baseURL: string, options: ClientOptions = {}) {
+    this.baseURL = baseURL.replace(/\/+$/, "");
+    this.headers = options.headers ?? {};
+    this.fetchImpl = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+{{range .Operations}}
+  /**
+   * {{.Method}} {{.Path}}{{if .Summary}}: {{comment .Summary}}{{end}}
+{{- if .Deprecated}}
+   * @deprecated
+{{- end}}
+   */
+  async {{.Name}}({{range $i, $param := .PathParams}}{{if $i}}, {{end}}{{$param.Name}}: {{$param.Type}}{{end}}{{if .Body}}{{if .PathParams}}, {{end}}body: {{.Body}}{{end}}{{if .HasParams}}{{if or .PathParams .Body}}, {{end}}params: {{.TypeName}}Params{{if not .RequiresParams}} = {}{{end}}{{end}}): Promise<{{if .Result}}{{.Result}}{{else}}void{{end}}> {
+    const query = new URLSearchParams();
+    const headers: Record<string, string> = {};
+{{- range .QueryParams}}
+{{- if .Array}}
+    for (const value of params[{{quote .Original}}] ?? []) {
+      query.append({{quote .Original}}, String(value));
+    }
+{{- else}}
+    if (params[{{quote .Original}}] !== undefined) {
+      query.set({{quote .Original}}, String(params[{{quote .Original}}]));
+    }
+{{- end}}
+{{- end}}
+{{- range .HeaderParams}}
+{{- if .Array}}
+    if (params[{{quote .Original}}] !== undefined) {
+      headers[{{quote .Original}}] = params[{{quote .Original}}].map(String).join(", ");
+    }
+{{- else}}
+    if (params[{{quote .Original}}] !== undefined) {
+      headers[{{quote .Original}}] = String(params[{{quote .Original}}]);
+    }
+{{- end}}
+{{- end}}
+    {{if .Result}}return {{else}}await {{end}}this.request<{{if .Result}}{{.Result}}{{else}}void{{end}}>({{quote .Method}}, {{.PathExpr}}, query, headers{{if .Body}}, body{{end}});
+  }
+{{end}}
+  private async request<T>(
+    method: string,
+    path: string,
+    query: URLSearchParams,
+    headers: Record<string, string>,
+    body?: unknown,
+  ): Promise<T> {
+    const search = query.toString();
+    const init: RequestInit = {
+      method,
+      headers: { Accept: "application/json", ...this.headers, ...headers },
+    };
+    if (body !== undefined) {
+      init.body = JSON.stringify(body);
+      (init.headers as Record<string, string>)["Content-Type"] = "application/json";
+    }
+
+    const response = await this.fetchImpl(this.baseURL + path + (search ? "?" + search : ""), init);
+    const text = await response.text();
+    let data: unknown = undefined;
+    if (text) {
+      try {
+        data = JSON.parse(text);
+      } catch {
+        data = text;
+      }
+    }
+    if (!response.ok) {
+      const detail = typeof data === "object" && data !== null ? (data as { detail?: string }).detail : undefined;
+      throw new ApiError(response.status, data, detail);
+    }
+    return data as T;
+  }
+}
+`
@@ -0,0 +1,468 @@
+// Package config provides runtime configuration with hot-reload and change notifications for GoAPI
+// Values are loaded from one or more sources (JSON files, environment variables, flags) and
+// components can subscribe to changes to react without restarting the server
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source provides configuration values as a flat map of dotted keys ("rate_limit.requests_per_minute")
+type Source interface {
+	Name() string
+	Load() (map[string]interface{}, error)
+}
+
+// ChangeEvent describes a configuration value that changed after a reload
+type ChangeEvent struct {
+	Key      string
+	OldValue interface{}
+	NewValue interface{}
+	Deleted  bool
+}
+
+// ChangeHandler is a function notified about configuration changes
+type ChangeHandler func(event ChangeEvent)
+
+// subscription represents a registered change handler
+type subscription struct {
+	id      int
+	pattern string
+	handler ChangeHandler
+}
+
+// Manager holds the current configuration and notifies subscribers about changes
+// It is safe for concurrent use
+type Manager struct {
+	sources       []Source
+	values        map[string]interface{}
+	overrides     map[string]interface{}
+	subscriptions []subscription
+	nextID        int
+	mutex         sync.RWMutex
+}
+
+// NewManager creates a configuration manager and performs the initial load
+// Later sources override the values of earlier ones
+func NewManager(sources ...Source) (*Manager, error) {
+	manager := &Manager{
+		sources:   sources,
+		values:    make(map[string]interface{}),
+		overrides: make(map[string]interface{}),
+	}
+	if err := manager.Reload(); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// Reload loads every source again and publishes a ChangeEvent for each modified key
+func (m *Manager) Reload() error {
+	loaded := make(map[string]interface{})
+	for _, source := range m.sources {
+		values, err := source.Load()
+		if err != nil {
+			return fmt.Errorf("error loading config source %s: %w", source.Name(), err)
+		}
+		for key, value := range values {
+			loaded[key] = value
+		}
+	}
+
+	m.mutex.Lock()
+	for key, value := range m.overrides {
+		loaded[key] = value
+	}
+	events := diff(m.values, loaded)
+	m.values = loaded
+	m.mutex.Unlock()
+
+	m.publish(events)
+	return nil
+}
+
+// Set overrides a value at runtime and notifies subscribers
+// Overrides survive reloads until they are removed with Unset
+func (m *Manager) Set(key string, value interface{}) {
+	m.mutex.Lock()
+	oldValue, existed := m.values[key]
+	m.overrides[key] = value
+	m.values[key] = value
+	m.mutex.Unlock()
+
+	if !existed || !reflect.DeepEqual(oldValue, value) {
+		m.publish([]ChangeEvent{{Key: key, OldValue: oldValue, NewValue: value}})
+	}
+}
+
+// Unset removes a runtime override and reloads the sources
+func (m *Manager) Unset(key string) error {
+	m.mutex.Lock()
+	delete(m.overrides, key)
+	m.mutex.Unlock()
+	return m.Reload()
+}
+
+// Subscribe registers a handler for changes of the keys matching a pattern
+// Patterns are an exact key, a prefix ending with ".*" ("rate_limit.*") or "*" for every key
+// The returned function removes the subscription
+func (m *Manager) Subscribe(pattern string, handler ChangeHandler) func() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.nextID++
+	id := m.nextID
+	m.subscriptions = append(m.subscriptions, subscription{id: id, pattern: pattern, handler: handler})
+
+	return func() {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		for i, current := range m.subscriptions {
+			if current.id == id {
+				m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// publish notifies the matching subscribers about each event
+func (m *Manager) publish(events []ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	m.mutex.RLock()
+	subscriptions := append([]subscription(nil), m.subscriptions...)
+	m.mutex.RUnlock()
+
+	for _, event := range events {
+		for _, current := range subscriptions {
+			if matches(current.pattern, event.Key) {
+				current.handler(event)
+			}
+		}
+	}
+}
+
+// Watch reloads the configuration every interval until the context is cancelled
+// Reload errors are reported to onError (when not nil) and the previous values are kept
+func (m *Manager) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// WatchSignals reloads the configuration every time one of the signals is received (e.g. syscall.SIGHUP)
+func (m *Manager) WatchSignals(ctx context.Context, onError func(error), signals ...os.Signal) {
+	signalChannel := make(chan os.Signal, 1)
+	signal.Notify(signalChannel, signals...)
+	defer signal.Stop(signalChannel)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signalChannel:
+			if err := m.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Get returns the raw value of a key
+func (m *Manager) Get(key string) (interface{}, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Keys returns every configured key in alphabetical order
+func (m *Manager) Keys() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	keys := make([]string, 0, len(m.values))
+	for key := range m.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetString returns a value as a string, or the fallback when missing
+func (m *Manager) GetString(key, fallback string) string {
+	value, ok := m.Get(key)
+	if !ok {
+		return fallback
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// GetInt returns a value as an int, or the fallback when missing or invalid
+func (m *Manager) GetInt(key string, fallback int) int {
+	value, ok := m.Get(key)
+	if !ok {
+		return fallback
+	}
+	switch typed := value.(type) {
+	case int:
+		return typed
+	case int64:
+		return int(typed)
+	case float64:
+		return int(typed)
+	case string:
+		if parsed, err := strconv.Atoi(typed); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// GetFloat returns a value as a float64, or the fallback when missing or invalid
+func (m *Manager) GetFloat(key string, fallback float64) float64 {
+	value, ok := m.Get(key)
+	if !ok {
+		return fallback
+	}
+	switch typed := value.(type) {
+	case float64:
+		return typed
+	case int:
+		return float64(typed)
+	case int64:
+		return float64(typed)
+	case string:
+		if parsed, err := strconv.ParseFloat(typed, 64); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// GetBool returns a value as a bool, or the fallback when missing or invalid
+func (m *Manager) GetBool(key string, fallback bool) bool {
+	value, ok := m.Get(key)
+	if !ok {
+		return fallback
+	}
+	switch typed := value.(type) {
+	case bool:
+		return typed
+	case string:
+		if parsed, err := strconv.ParseBool(typed); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// GetDuration returns a value as a time.Duration ("1m30s" or a number of seconds), or the fallback
+func (m *Manager) GetDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := m.Get(key)
+	if !ok {
+		return fallback
+	}
+	switch typed := value.(type) {
+	case time.Duration:
+		return typed
+	case float64:
+		return time.Duration(typed * float64(time.Second))
+	case int:
+		return time.Duration(typed) * time.Second
+	case string:
+		if parsed, err := time.ParseDuration(typed); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// matches reports whether a key matches a subscription pattern
+func matches(pattern, key string) bool {
+	if pattern == "*" || pattern == key {
+		return true
+	}
+	if prefix, found := strings.CutSuffix(pattern, ".*"); found {
+		return strings.HasPrefix(key, prefix+".")
+	}
+	return false
+}
+
+// diff computes the change events between two sets of values
+func diff(oldValues, newValues map[string]interface{}) []ChangeEvent {
+	var events []ChangeEvent
+	for key, newValue := range newValues {
+		oldValue, existed := oldValues[key]
+		if !existed || !reflect.DeepEqual(oldValue, newValue) {
+			events = append(events, ChangeEvent{Key: key, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for key, oldValue := range oldValues {
+		if _, exists := newValues[key]; !exists {
+			events = append(events, ChangeEvent{Key: key, OldValue: oldValue, Deleted: true})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Key < events[j].Key })
+	return events
+}
+
+// flatten converts nested maps into dotted keys
+func flatten(prefix string, values map[string]interface{}, result map[string]interface{}) {
+	for key, value := range values {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(fullKey, nested, result)
+			continue
+		}
+		result[fullKey] = value
+	}
+}
+
+// FileSource loads configuration from a JSON file
+// Nested objects are flattened into dotted keys
+type FileSource struct {
+	Path string
+	// Optional allows the file to be missing
+	Optional bool
+}
+
+// NewFileSource creates a JSON file source
+func NewFileSource(path string) *FileSource {
+	return &FileSource{Path: path}
+}
+
+// Name implements Source
+func (s *FileSource) Name() string {
+	return "file:" + s.Path
+}
+
+// Load implements Source
+func (s *FileSource) Load() (map[string]interface{}, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		if s.Optional && os.IsNotExist(err) {
+			return map[string]interface{}{}, nil
+		}
+		return nil, err
+	}
+
+	raw := make(map[string]interface{})
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %w", s.Path, err)
+	}
+
+	values := make(map[string]interface{})
+	flatten("", raw, values)
+	return values, nil
+}
+
+// EnvSource loads configuration from environment variables with a prefix
+// APP_LOG_LEVEL becomes "log_level" and APP_RATE_LIMIT__RPS becomes "rate_limit.rps"
+type EnvSource struct {
+	Prefix string
+}
+
+// NewEnvSource creates an environment variable source
+func NewEnvSource(prefix string) *EnvSource {
+	return &EnvSource{Prefix: prefix}
+}
+
+// Name implements Source
+func (s *EnvSource) Name() string {
+	return "env:" + s.Prefix
+}
+
+// Load implements Source
+func (s *EnvSource) Load() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	prefix := s.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		trimmed, found := strings.CutPrefix(name, prefix)
+		if !found || trimmed == "" {
+			continue
+		}
+		key := strings.ToLower(strings.ReplaceAll(trimmed, "__", "."))
+		values[key] = value
+	}
+	return values, nil
+}
+
+// FlagSource exposes the flags that were explicitly set on a FlagSet
+// Flag names are used as keys ("rate-limit.rps")
+type FlagSource struct {
+	FlagSet *flag.FlagSet
+}
+
+// NewFlagSource creates a flag source; a nil FlagSet uses flag.CommandLine
+func NewFlagSource(flagSet *flag.FlagSet) *FlagSource {
+	if flagSet == nil {
+		flagSet = flag.CommandLine
+	}
+	return &FlagSource{FlagSet: flagSet}
+}
+
+// Name implements Source
+func (s *FlagSource) Name() string {
+	return "flags"
+}
+
+// Load implements Source
+func (s *FlagSource) Load() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	s.FlagSet.Visit(func(current *flag.Flag) {
+		if getter, ok := current.Value.(flag.Getter); ok {
+			values[current.Name] = getter.Get()
+			return
+		}
+		values[current.Name] = current.Value.String()
+	})
+	return values, nil
+}
+
+// MapSource provides static values, useful for defaults and tests
+type MapSource map[string]interface{}
+
+// Name implements Source
+func (s MapSource) Name() string {
+	return "map"
+}
+
+// Load implements Source
+func (s MapSource) Load() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	flatten("", map[string]interface{}(s), values)
+	return values, nil
+}
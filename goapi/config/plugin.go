@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+)
+
+// Plugin wires a Manager into a GoAPI instance
+// It registers the manager as a singleton dependency and watches the sources while the server runs
+type Plugin struct {
+	Manager  *Manager
+	Interval time.Duration
+	OnError  func(error)
+	cancel   context.CancelFunc
+}
+
+// NewPlugin creates a plugin that reloads the configuration every interval
+// A zero interval disables polling; Reload can still be called manually
+func NewPlugin(manager *Manager, interval time.Duration) *Plugin {
+	return &Plugin{
+		Manager:  manager,
+		Interval: interval,
+		OnError: func(err error) {
+			log.Printf("config reload failed: %v", err)
+		},
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "config"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Manager, nil
+	}, (*Manager)(nil))
+	return nil
+}
+
+// OnStartup implements goapi.StartupPlugin
+func (p *Plugin) OnStartup(ctx context.Context) error {
+	if p.Interval <= 0 {
+		return nil
+	}
+	watchContext, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go p.Manager.Watch(watchContext, p.Interval, p.OnError)
+	return nil
+}
+
+// OnShutdown implements goapi.ShutdownPlugin
+func (p *Plugin) OnShutdown(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+// Package consent enforces the acceptance of legal documents (terms of service, privacy policy...)
+// A Manager tracks the current version of each document and the versions accepted by every
+// principal, and its middleware blocks principals who have not accepted the latest versions
+package consent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors returned by the manager
+var (
+	// ErrDocumentNotFound is returned when accepting an unknown document
+	ErrDocumentNotFound = errors.New("consent document not found")
+	// ErrVersionMismatch is returned when accepting a version that is not the current one
+	ErrVersionMismatch = errors.New("consent document version is not the current one")
+)
+
+// Document is a legal document principals must accept
+type Document struct {
+	ID      string `json:"id" example:"terms"`        // Stable identifier ("terms", "privacy")
+	Version string `json:"version" example:"2024-06"` // Current version; changing it requires a new acceptance
+	URL     string `json:"url,omitempty"`             // Where the document can be read
+}
+
+// Acceptance records that a principal accepted a document version
+type Acceptance struct {
+	Principal  string    `json:"principal"`
+	DocumentID string    `json:"document_id"`
+	Version    string    `json:"version"`
+	AcceptedAt time.Time `json:"accepted_at"`
+	IP         string    `json:"ip,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// Store persists acceptances
+type Store interface {
+	// AcceptedVersion returns the last version of a document accepted by a principal ("" if none)
+	AcceptedVersion(ctx context.Context, principal, documentID string) (string, error)
+	// Accept records an acceptance
+	Accept(ctx context.Context, acceptance Acceptance) error
+}
+
+// Manager holds the current documents and checks acceptances against a store
+// It is safe for concurrent use; documents can be updated while serving to require a new acceptance
+type Manager struct {
+	store     Store
+	documents map[string]Document
+	mutex     sync.RWMutex
+}
+
+// NewManager creates a manager requiring the given documents
+// A nil store defaults to an in-memory store
+func NewManager(store Store, documents ...Document) *Manager {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	manager := &Manager{store: store, documents: make(map[string]Document)}
+	for _, document := range documents {
+		manager.SetDocument(document)
+	}
+	return manager
+}
+
+// SetDocument adds a document or publishes a new version of it
+func (m *Manager) SetDocument(document Document) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.documents[document.ID] = document
+}
+
+// RemoveDocument stops requiring a document
+func (m *Manager) RemoveDocument(documentID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.documents, documentID)
+}
+
+// Documents returns the current documents sorted by identifier
+func (m *Manager) Documents() []Document {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	documents := make([]Document, 0, len(m.documents))
+	for _, document := range m.documents {
+		documents = append(documents, document)
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].ID < documents[j].ID
+	})
+	return documents
+}
+
+// Store returns the acceptance store of the manager
+func (m *Manager) Store() Store {
+	return m.store
+}
+
+// Pending returns the documents whose current version the principal has not accepted
+func (m *Manager) Pending(ctx context.Context, principal string) ([]Document, error) {
+	var pending []Document
+	for _, document := range m.Documents() {
+		accepted, err := m.store.AcceptedVersion(ctx, principal, document.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error reading acceptance of %s: %w", document.ID, err)
+		}
+		if accepted != document.Version {
+			pending = append(pending, document)
+		}
+	}
+	return pending, nil
+}
+
+// Accept records that a principal accepted the current version of a document
+// Accepting an outdated version fails with ErrVersionMismatch, so clients always show the latest text
+func (m *Manager) Accept(ctx context.Context, acceptance Acceptance) error {
+	m.mutex.RLock()
+	document, exists := m.documents[acceptance.DocumentID]
+	m.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrDocumentNotFound, acceptance.DocumentID)
+	}
+	if acceptance.Version != document.Version {
+		return fmt.Errorf("%w: %s is at version %s", ErrVersionMismatch, document.ID, document.Version)
+	}
+	if acceptance.AcceptedAt.IsZero() {
+		acceptance.AcceptedAt = time.Now()
+	}
+	return m.store.Accept(ctx, acceptance)
+}
+
+// MiddlewareConfig configures the enforcement middleware
+type MiddlewareConfig struct {
+	// Principal identifies the caller (default: the "user_id" context value)
+	// Requests without a principal are let through; authentication is enforced elsewhere
+	Principal func(*gin.Context) string
+	// StatusCode of blocked requests: http.StatusForbidden (default) or http.StatusUnavailableForLegalReasons
+	StatusCode int
+	// AcceptURL is the remediation link returned to blocked clients (e.g. the acceptance endpoint)
+	AcceptURL string
+	// SkipPaths are path prefixes that never require consent (login, the acceptance endpoint...)
+	SkipPaths []string
+}
+
+// BlockedResponse is the body of requests blocked for missing consent
+type BlockedResponse struct {
+	Detail    string     `json:"detail"`
+	Type      string     `json:"type"`
+	Pending   []Document `json:"pending"`
+	AcceptURL string     `json:"accept_url,omitempty"`
+}
+
+// Middleware blocks principals who have not accepted the current version of every document
+// Blocked responses list the pending documents and carry a Link header to the remediation URL
+func (m *Manager) Middleware(config ...MiddlewareConfig) gin.HandlerFunc {
+	var cfg MiddlewareConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Principal == nil {
+		cfg.Principal = func(c *gin.Context) string {
+			return c.GetString("user_id")
+		}
+	}
+	if cfg.StatusCode == 0 {
+		cfg.StatusCode = http.StatusForbidden
+	}
+
+	return func(c *gin.Context) {
+		for _, prefix := range cfg.SkipPaths {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		principal := cfg.Principal(c)
+		if principal == "" {
+			c.Next()
+			return
+		}
+
+		pending, err := m.Pending(c.Request.Context(), principal)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"detail": "Error checking consent",
+				"type":   "internal_server_error",
+			})
+			return
+		}
+		if len(pending) == 0 {
+			c.Next()
+			return
+		}
+
+		if cfg.AcceptURL != "" {
+			c.Header("Link", "<"+cfg.AcceptURL+`>; rel="terms-of-service"`)
+		}
+		c.AbortWithStatusJSON(cfg.StatusCode, BlockedResponse{
+			Detail:    "The latest version of the required documents must be accepted",
+			Type:      "consent_required",
+			Pending:   pending,
+			AcceptURL: cfg.AcceptURL,
+		})
+	}
+}
+
+// MemoryStore keeps acceptances in memory
+// It is meant for development and tests; production deployments should persist acceptances
+type MemoryStore struct {
+	acceptances map[string]map[string]Acceptance // principal -> document -> last acceptance
+	mutex       sync.RWMutex
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{acceptances: make(map[string]map[string]Acceptance)}
+}
+
+// AcceptedVersion implements Store
+func (s *MemoryStore) AcceptedVersion(ctx context.Context, principal, documentID string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.acceptances[principal][documentID].Version, nil
+}
+
+// Accept implements Store
+func (s *MemoryStore) Accept(ctx context.Context, acceptance Acceptance) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.acceptances[acceptance.Principal] == nil {
+		s.acceptances[acceptance.Principal] = make(map[string]Acceptance)
+	}
+	s.acceptances[acceptance.Principal][acceptance.DocumentID] = acceptance
+	return nil
+}
+
+// Acceptances returns the acceptances of a principal, for auditing
+func (s *MemoryStore) Acceptances(principal string) []Acceptance {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	acceptances := make([]Acceptance, 0, len(s.acceptances[principal]))
+	for _, acceptance := range s.acceptances[principal] {
+		acceptances = append(acceptances, acceptance)
+	}
+	sort.Slice(acceptances, func(i, j int) bool {
+		return acceptances[i].DocumentID < acceptances[j].DocumentID
+	})
+	return acceptances
+}
@@ -0,0 +1,151 @@
+package consent
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// AcceptRequest is the body of the acceptance endpoint
+type AcceptRequest struct {
+	Document string `json:"document" validate:"required" example:"terms"`
+	Version  string `json:"version" validate:"required" example:"2024-06"`
+}
+
+// StatusResponse is the body of the status endpoint
+type StatusResponse struct {
+	Documents []Document `json:"documents"`
+	Pending   []Document `json:"pending"`
+}
+
+// Plugin wires a Manager into a GoAPI instance
+// It registers the manager as a singleton dependency, scaffolds the acceptance endpoints and,
+// when Enforce is set, blocks principals with pending documents on every other route
+type Plugin struct {
+	Manager *Manager
+	Prefix  string                    // Path prefix of the endpoints (default "/consent")
+	Enforce bool                      // Install the blocking middleware globally (default true)
+	Config  MiddlewareConfig          // Enforcement settings; the endpoints are always skipped
+	UserID  func(*gin.Context) string // Identifies the authenticated user (default: the "user_id" context value)
+	Tags    []string                  // Documentation tags of the endpoints
+}
+
+// NewPlugin creates a plugin serving the acceptance endpoints under /consent
+// The authentication middleware must be added before the plugin so the principal is known
+func NewPlugin(manager *Manager) *Plugin {
+	return &Plugin{
+		Manager: manager,
+		Prefix:  "/consent",
+		Enforce: true,
+		UserID: func(c *gin.Context) string {
+			return c.GetString("user_id")
+		},
+		Tags: []string{"consent"},
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "consent"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Manager, nil
+	}, (*Manager)(nil))
+
+	api.GET(p.Prefix, p.status,
+		goapi.WithSummary("Consent status"),
+		goapi.WithDescription("Lists the current documents and those the authenticated user still has to accept"),
+		goapi.WithTags(p.Tags...),
+		goapi.WithResponse(http.StatusUnauthorized, "Not authenticated"),
+	)
+	api.POST(p.Prefix+"/accept", p.accept,
+		goapi.WithSummary("Accept a document"),
+		goapi.WithDescription("Records the acceptance of the current version of a document"),
+		goapi.WithTags(p.Tags...),
+		goapi.WithRequestBody(AcceptRequest{}, "Document and version accepted"),
+		goapi.WithResponse(http.StatusNoContent, "Acceptance recorded"),
+		goapi.WithResponse(http.StatusUnauthorized, "Not authenticated"),
+		goapi.WithResponse(http.StatusNotFound, "Document not found"),
+		goapi.WithResponse(http.StatusConflict, "Version is not the current one"),
+	)
+
+	if p.Enforce {
+		config := p.Config
+		if config.Principal == nil {
+			config.Principal = p.UserID
+		}
+		if config.AcceptURL == "" {
+			config.AcceptURL = p.Prefix + "/accept"
+		}
+		config.SkipPaths = append([]string{p.Prefix}, config.SkipPaths...)
+		api.AddMiddleware(p.Manager.Middleware(config))
+	}
+	return nil
+}
+
+// status handles GET {prefix}
+func (p *Plugin) status(c *gin.Context) {
+	userID := p.UserID(c)
+	if userID == "" {
+		responses.Unauthorized(c, i18n.M(i18n.KeyAuthenticationRequired))
+		return
+	}
+
+	pending, err := p.Manager.Pending(c.Request.Context(), userID)
+	if err != nil {
+		responses.InternalServerError(c, "Error checking consent")
+		return
+	}
+	if pending == nil {
+		pending = []Document{}
+	}
+	responses.Success(c, StatusResponse{Documents: p.Manager.Documents(), Pending: pending})
+}
+
+// accept handles POST {prefix}/accept
+func (p *Plugin) accept(c *gin.Context) {
+	userID := p.UserID(c)
+	if userID == "" {
+		responses.Unauthorized(c, i18n.M(i18n.KeyAuthenticationRequired))
+		return
+	}
+
+	var request AcceptRequest
+	if !goapi.DecodeJSONBody(c, &request) {
+		return
+	}
+	if err := validation.FromContext(c).ValidateStruct(request); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	err := p.Manager.Accept(c.Request.Context(), Acceptance{
+		Principal:  userID,
+		DocumentID: request.Document,
+		Version:    request.Version,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+	})
+	switch {
+	case errors.Is(err, ErrDocumentNotFound):
+		responses.NotFound(c, "Document not found")
+	case errors.Is(err, ErrVersionMismatch):
+		c.JSON(http.StatusConflict, responses.ErrorResponse{
+			Detail: err.Error(),
+			Type:   "version_mismatch",
+		})
+	case err != nil:
+		responses.InternalServerError(c, "Error recording acceptance")
+	default:
+		responses.NoContent(c)
+	}
+}
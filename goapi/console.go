@@ -0,0 +1,93 @@
+package goapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/core"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// routeOperation returns the operation documenting a route in a document
+func (apiInstance *GoAPI) routeOperation(document *openapi.Document, route router.Route) *openapi.Operation {
+	_, specPath := apiInstance.specPaths(apiInstance.routes)
+	if pathItem := document.Paths[apiInstance.convertToOpenAPIPath(specPath(route.Path))]; pathItem != nil {
+		return pathItem.Operation(route.Method)
+	}
+	return nil
+}
+
+// indexConsoles builds the "try it" forms of the index page from the documented operations,
+// keyed by method and served path
+func (apiInstance *GoAPI) indexConsoles() map[string]*core.Console {
+	document := apiInstance.buildDocument("")
+	consoles := make(map[string]*core.Console, len(apiInstance.routes))
+	for _, route := range apiInstance.routes {
+		if route.Method == http.MethodHead || route.Method == http.MethodOptions || route.Streaming {
+			continue
+		}
+		operation := apiInstance.routeOperation(document, route)
+		if operation == nil {
+			continue
+		}
+		consoles[route.Method+" "+apiInstance.mountPath(route.Path)] = operationConsole(route, operation, document.Definitions)
+	}
+	return consoles
+}
+
+// operationConsole builds the form of an operation: a field per parameter, path segments
+// included, and for operations with a body the first named example or one generated from
+// the body schema
+func operationConsole(route router.Route, operation *openapi.Operation, definitions map[string]*openapi.Schema) *core.Console {
+	console := &core.Console{BodyType: gin.MIMEJSON}
+	if len(operation.Consumes) > 0 {
+		console.BodyType = operation.Consumes[0]
+	}
+
+	// Los parámetros de ruta no documentados también necesitan un campo para construir la URL
+	documented := make(map[string]bool)
+	for _, parameter := range operation.Parameters {
+		if parameter.In == "path" {
+			documented[parameter.Name] = true
+		}
+	}
+	for _, segment := range strings.Split(route.Path, "/") {
+		if name := strings.TrimLeft(segment, ":*"); name != segment && !documented[name] {
+			console.Parameters = append(console.Parameters, core.ConsoleParameter{Name: name, In: "path", Type: "string", Required: true})
+		}
+	}
+
+	for _, parameter := range operation.Parameters {
+		if parameter.In == "body" {
+			var example interface{}
+			if len(route.RequestExamples) > 0 {
+				example = route.RequestExamples[0].Value
+			} else {
+				example = generateExample(parameter.Schema, definitions, 0)
+			}
+			console.Body = "{}"
+			if body, err := json.MarshalIndent(example, "", "  "); err == nil && example != nil {
+				console.Body = string(body)
+			}
+			continue
+		}
+
+		field := core.ConsoleParameter{
+			Name:        parameter.Name,
+			In:          parameter.In,
+			Type:        parameter.Type,
+			Description: parameter.Description,
+			Required:    parameter.Required,
+		}
+		if parameter.Default != nil {
+			field.Value = fmt.Sprint(parameter.Default)
+		}
+		console.Parameters = append(console.Parameters, field)
+	}
+	return console
+}
@@ -0,0 +1,88 @@
+package goapi
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// Key is a context key that carries the type of its value
+// Values are stored in the gin.Context under the name of the key, so middlewares that use plain
+// c.Set and c.Get with the same name see the same value:
+//
+//	var OrgKey = goapi.NewKey[*Org]("org")
+//
+//	goapi.Set(c, OrgKey, org)
+//	org, ok := goapi.Get(c, OrgKey)
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a key; prefix the name with the package to avoid collisions
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name returns the name of the key in the gin.Context
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Well-known keys of the request context
+var (
+	// RequestIDKey holds the ID set by the RequestID middleware
+	RequestIDKey = NewKey[string](responses.RequestIDKey)
+	// CurrentUserKey holds the authenticated user; authz reads it as authz.CurrentUserKey
+	CurrentUserKey = NewKey[*dependencies.CurrentUser](dependencies.CurrentUserKey)
+	// TenantKey holds the tenant of the request, for multi-tenant APIs
+	TenantKey = NewKey[string]("goapi.tenant")
+	// LoggerKey holds the logger of the request, see Logger
+	LoggerKey = NewKey[*slog.Logger]("goapi.logger")
+)
+
+// Set stores a value under a key for the rest of the request
+func Set[T any](c *gin.Context, key Key[T], value T) {
+	c.Set(key.name, value)
+}
+
+// Get returns the value of a key; false when it is not set or holds a value of another type
+func Get[T any](c *gin.Context, key Key[T]) (T, bool) {
+	value, ok := c.Get(key.name)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	typed, ok := value.(T)
+	return typed, ok
+}
+
+// MustGet returns the value of a key and panics when it is not set or holds another type
+// Use it for values a middleware of the route always sets
+func MustGet[T any](c *gin.Context, key Key[T]) T {
+	value, exists := c.Get(key.name)
+	if !exists {
+		panic(fmt.Sprintf("goapi: context key %q is not set", key.name))
+	}
+	typed, ok := value.(T)
+	if !ok {
+		var zero T
+		panic(fmt.Sprintf("goapi: context key %q holds %T, not %T", key.name, value, zero))
+	}
+	return typed
+}
+
+// Logger returns the logger of the request: the one under LoggerKey or, when there is none, the
+// default slog logger with the request ID
+func Logger(c *gin.Context) *slog.Logger {
+	if logger, ok := Get(c, LoggerKey); ok && logger != nil {
+		return logger
+	}
+	if requestID, ok := Get(c, RequestIDKey); ok && requestID != "" {
+		return slog.Default().With("request_id", requestID)
+	}
+	return slog.Default()
+}
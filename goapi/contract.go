@@ -0,0 +1,68 @@
+package goapi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+)
+
+// ContractError lists the breaking changes of the API against a committed specification
+type ContractError struct {
+	File    string           // Committed specification
+	Changes []openapi.Change // Breaking changes, sorted by path and method
+}
+
+func (e *ContractError) Error() string {
+	lines := make([]string, 0, len(e.Changes)+1)
+	lines = append(lines, fmt.Sprintf("%d breaking changes against %s:", len(e.Changes), e.File))
+	for _, change := range e.Changes {
+		lines = append(lines, "  "+change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// VerifySpec compares the generated specification with a committed one and returns a
+// *ContractError listing the breaking changes: removed operations, parameters or response fields,
+// narrowed types and new required inputs. Compatible changes, such as new routes, are accepted
+// It does not need a running server, so it fits in a test:
+//
+//	func TestContract(t *testing.T) {
+//		if err := newAPI().VerifySpec("openapi.json"); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func (a *GoAPI) VerifySpec(file string) error {
+	changes, err := a.DiffSpec(file)
+	if err != nil {
+		return err
+	}
+	if breaking := openapi.Breaking(changes); len(breaking) > 0 {
+		return &ContractError{File: file, Changes: breaking}
+	}
+	return nil
+}
+
+// DiffSpec returns every change of the generated specification against a committed one,
+// breaking or not
+func (a *GoAPI) DiffSpec(file string) ([]openapi.Change, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading specification: %w", err)
+	}
+	committed, err := openapi.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	return openapi.Diff(committed, a.OpenAPIDocument()), nil
+}
+
+// WriteSpec writes the generated specification to a file, to commit it as the new contract
+func (a *GoAPI) WriteSpec(file string) error {
+	data, err := a.OpenAPIDocument().JSON()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
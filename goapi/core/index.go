@@ -0,0 +1,333 @@
+package core
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// Theme customizes the colors and logo of the index page
+// Empty fields keep the default look
+type Theme struct {
+	PrimaryColor    string // Documentation buttons (default #1a1a1a)
+	HeaderColor     string // Header background (default #1a1a1a)
+	HeaderTextColor string // Header text (default white)
+	BackgroundColor string // Page background (default #f5f5f5)
+	LogoURL         string // Image shown above the title
+	FaviconURL      string
+	StylesheetURL   string // Additional stylesheet, loaded after the default styles
+}
+
+// withDefaults fills the empty colors of a theme with the default ones
+func (t Theme) withDefaults() Theme {
+	if t.PrimaryColor == "" {
+		t.PrimaryColor = "#1a1a1a"
+	}
+	if t.HeaderColor == "" {
+		t.HeaderColor = "#1a1a1a"
+	}
+	if t.HeaderTextColor == "" {
+		t.HeaderTextColor = "white"
+	}
+	if t.BackgroundColor == "" {
+		t.BackgroundColor = "#f5f5f5"
+	}
+	return t
+}
+
+// IndexPage describes the index page: the API, its routes and how to render them
+type IndexPage struct {
+	Title       string
+	Description string
+	Version     string
+	BasePath    string
+	// Config is the configuration of the API, available to custom templates as .Config
+	Config interface{}
+	Theme  Theme
+	// Template replaces the default page; it is executed with an IndexData
+	Template *template.Template
+	Routes   []router.Route
+	// Consoles holds the request forms of the endpoints, keyed by "METHOD path" (nil = no forms)
+	Consoles map[string]*Console
+	// Specs links the documentation of the other specifications of the API
+	Specs []SpecLink
+}
+
+// SpecLink links the documentation of a named specification
+type SpecLink struct {
+	Name     string
+	Title    string
+	DocsURL  string
+	RedocURL string
+	SpecURL  string
+}
+
+// IndexData is the data the index template is executed with
+type IndexData struct {
+	Title       string
+	Description string
+	Version     string
+	BasePath    string // Root of the API ("/" when it has no base path)
+	Config      interface{}
+	Theme       Theme
+	DocsURL     string
+	RedocURL    string
+	SpecURL     string
+	Endpoints   []Endpoint      // Every endpoint, in registration order
+	Groups      []EndpointGroup // Endpoints grouped by tag, in order of first appearance
+	Specs       []SpecLink      // Other specifications of the API
+}
+
+// Endpoint is a route listed on the index page
+type Endpoint struct {
+	router.Route
+	Console *Console // Request form of the endpoint (nil = not tryable)
+}
+
+// Console is the "try it" form of an endpoint, sending requests from the index page
+type Console struct {
+	Parameters []ConsoleParameter // Path, query, header and form parameters
+	Body       string             // Sample request body the form starts with ("" = no body)
+	BodyType   string             // Media type of the body
+}
+
+// ConsoleParameter is a field of a console form
+type ConsoleParameter struct {
+	Name        string
+	In          string // "path", "query", "header" or "formData"
+	Type        string
+	Description string
+	Required    bool
+	Value       string // Initial value: the default or example of the parameter
+}
+
+// Class returns the CSS class of the method badge ("get", "post"...)
+func (e Endpoint) Class() string {
+	return strings.ToLower(e.Method)
+}
+
+// EndpointGroup holds the endpoints sharing a tag
+// Untagged endpoints are grouped under "default", as in Swagger UI
+type EndpointGroup struct {
+	Tag       string
+	Endpoints []Endpoint
+}
+
+// IndexPageHandler serves an index page
+// A template failing to execute is logged and answered with a 500
+func IndexPageHandler(page IndexPage) gin.HandlerFunc {
+	tmpl := page.Template
+	if tmpl == nil {
+		tmpl = defaultIndexTemplate
+	}
+	data := newIndexData(page)
+	return func(c *gin.Context) {
+		var body bytes.Buffer
+		if err := tmpl.Execute(&body, data); err != nil {
+			log.Printf("[GoAPI] index template: %v", err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", body.Bytes())
+	}
+}
+
+// newIndexData builds the template data of a page, leaving out the documentation routes
+func newIndexData(page IndexPage) IndexData {
+	data := IndexData{
+		Title:       page.Title,
+		Description: page.Description,
+		Version:     page.Version,
+		BasePath:    page.BasePath,
+		Config:      page.Config,
+		Theme:       page.Theme.withDefaults(),
+		DocsURL:     "/docs",
+		RedocURL:    "/redoc",
+		SpecURL:     "/openapi.json",
+		Specs:       page.Specs,
+	}
+	if data.BasePath == "" {
+		data.BasePath = "/"
+	}
+
+	groups := make(map[string]int)
+	for _, route := range page.Routes {
+		if isDocumentationRoute(route.Path) {
+			continue
+		}
+		endpoint := Endpoint{Route: route, Console: page.Consoles[route.Method+" "+route.Path]}
+		data.Endpoints = append(data.Endpoints, endpoint)
+
+		tags := route.Tags
+		if len(tags) == 0 {
+			tags = []string{"default"}
+		}
+		for _, tag := range tags {
+			index, exists := groups[tag]
+			if !exists {
+				index = len(data.Groups)
+				groups[tag] = index
+				data.Groups = append(data.Groups, EndpointGroup{Tag: tag})
+			}
+			data.Groups[index].Endpoints = append(data.Groups[index].Endpoints, endpoint)
+		}
+	}
+	return data
+}
+
+// isDocumentationRoute reports whether a path is served by the documentation handlers
+func isDocumentationRoute(path string) bool {
+	switch path {
+	case "/", "/docs", "/redoc", "/swagger/*any", "/redoc/index.html":
+		return true
+	}
+	return false
+}
+
+// defaultIndexTemplate renders the FastAPI-style index page
+var defaultIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>{{.Title}}</title>
+    <meta charset="utf-8"/>
+    {{- with .Theme.FaviconURL}}
+    <link rel="icon" href="{{.}}">
+    {{- end}}
+    <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/bootstrap@5.1.3/dist/css/bootstrap.min.css">
+    <style>
+        body { margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background-color: {{.Theme.BackgroundColor}}; }
+        .container { max-width: 800px; margin: 0 auto; padding: 20px; }
+        .header { background-color: {{.Theme.HeaderColor}}; color: {{.Theme.HeaderTextColor}}; padding: 20px; text-align: center; }
+        .header h1 { margin: 0; }
+        .header .logo { max-height: 64px; margin-bottom: 10px; }
+        .main { background-color: white; border-radius: 5px; padding: 20px; margin-top: 20px; box-shadow: 0 1px 3px rgba(0,0,0,0.1); }
+        .tag { margin-top: 20px; text-transform: capitalize; }
+        .endpoint { border: 1px solid #eee; border-radius: 5px; margin-bottom: 10px; padding: 10px; }
+        .method { display: inline-block; padding: 3px 8px; border-radius: 3px; font-weight: bold; margin-right: 10px; }
+        .get { background-color: #61affe; color: white; }
+        .post { background-color: #49cc90; color: white; }
+        .put { background-color: #fca130; color: white; }
+        .delete { background-color: #f93e3e; color: white; }
+        .patch { background-color: #50e3c2; color: white; }
+        .head, .options { background-color: #9012fe; color: white; }
+        .docs-link { margin-top: 20px; text-align: center; }
+        .docs-button { display: inline-block; background-color: {{.Theme.PrimaryColor}}; color: white; padding: 10px 20px;
+                       border-radius: 5px; text-decoration: none; font-weight: bold; margin: 0 10px; transition: opacity 0.2s; }
+        .docs-button:hover { opacity: 0.8; color: white; text-decoration: none; }
+        .console summary { cursor: pointer; color: {{.Theme.PrimaryColor}}; font-weight: bold; }
+        .console form { margin-top: 10px; }
+        .console label { display: block; margin-bottom: 8px; }
+        .console .result { background-color: #f8f8f8; border-radius: 5px; padding: 10px; margin-top: 10px; max-height: 400px; }
+    </style>
+    {{- with .Theme.StylesheetURL}}
+    <link rel="stylesheet" href="{{.}}">
+    {{- end}}
+</head>
+<body>
+    <div class="header">
+        {{- with .Theme.LogoURL}}
+        <img src="{{.}}" alt="" class="logo">
+        {{- end}}
+        <h1>{{.Title}}</h1>
+        <p>{{.Description}}</p>
+    </div>
+    <div class="container">
+        <div class="main">
+            <h2>API Endpoints</h2>
+            {{- range .Groups}}
+            <h4 class="tag">{{.Tag}}</h4>
+            {{- range $endpoint := .Endpoints}}
+            <div class="endpoint">
+                <span class="method {{.Class}}">{{.Method}}</span>
+                <span class="path">{{.Path}}</span>
+                <p>{{or .Description .Summary}}</p>
+                {{- with .Console}}
+                <details class="console">
+                    <summary>Try it</summary>
+                    <form data-method="{{$endpoint.Method}}" data-path="{{$endpoint.Path}}" data-body-type="{{.BodyType}}">
+                        {{- range .Parameters}}
+                        <label class="form-label">{{.Name}} <small class="text-muted">{{.In}}{{if .Required}}, required{{end}}</small>
+                            <input class="form-control form-control-sm" name="{{.Name}}" data-in="{{.In}}" value="{{.Value}}" placeholder="{{.Type}}"{{if .Required}} required{{end}} title="{{.Description}}">
+                        </label>
+                        {{- end}}
+                        {{- if .Body}}
+                        <label class="form-label">body <small class="text-muted">{{.BodyType}}</small>
+                            <textarea class="form-control form-control-sm" name="body" rows="6">{{.Body}}</textarea>
+                        </label>
+                        {{- end}}
+                        <button type="submit" class="btn btn-sm btn-dark">Send</button>
+                    </form>
+                    <pre class="result" hidden></pre>
+                </details>
+                {{- end}}
+            </div>
+            {{- end}}
+            {{- end}}
+        </div>
+        <div class="docs-link">
+            <a href="{{.DocsURL}}" class="docs-button">Swagger UI</a>
+            <a href="{{.RedocURL}}" class="docs-button">ReDoc</a>
+            <a href="{{.BasePath}}" class="docs-button">API Root</a>
+        </div>
+        {{- with .Specs}}
+        <div class="docs-link">
+            {{- range .}}
+            <a href="{{.DocsURL}}" class="docs-button">{{.Title}}</a>
+            {{- end}}
+        </div>
+        {{- end}}
+    </div>
+    <script>
+    // Each console sends its request with fetch and prints the response below the form
+    document.querySelectorAll('.console form').forEach(function (form) {
+        form.addEventListener('submit', async function (event) {
+            event.preventDefault();
+            var result = form.parentElement.querySelector('.result');
+            var fields = Array.from(form.querySelectorAll('input[data-in]'));
+            var value = function (parameter) {
+                var field = fields.find(function (f) { return f.dataset.in === 'path' && f.name === parameter; });
+                return field ? field.value : '';
+            };
+            var path = form.dataset.path
+                .replace(/:([^/]+)/g, function (_, name) { return encodeURIComponent(value(name)); })
+                .replace(/\*([^/]+)$/, function (_, name) { return value(name).replace(/^\/*/, ''); });
+            var query = new URLSearchParams();
+            var headers = {};
+            var formData = new URLSearchParams();
+            fields.forEach(function (field) {
+                if (field.value === '') { return; }
+                if (field.dataset.in === 'query') { query.append(field.name, field.value); }
+                if (field.dataset.in === 'header') { headers[field.name] = field.value; }
+                if (field.dataset.in === 'formData') { formData.append(field.name, field.value); }
+            });
+            var options = { method: form.dataset.method, headers: headers };
+            var body = form.querySelector('textarea[name=body]');
+            if (body) {
+                options.body = body.value;
+                headers['Content-Type'] = form.dataset.bodyType;
+            } else if (Array.from(formData).length > 0) {
+                options.body = formData;
+            }
+            var url = path + (Array.from(query).length > 0 ? '?' + query : '');
+            result.hidden = false;
+            result.textContent = options.method + ' ' + url + ' ...';
+            try {
+                var response = await fetch(url, options);
+                var text = await response.text();
+                try { text = JSON.stringify(JSON.parse(text), null, 2); } catch (e) {}
+                result.textContent = response.status + ' ' + response.statusText + '\n\n' + text;
+            } catch (error) {
+                result.textContent = 'Request failed: ' + error;
+            }
+        });
+    });
+    </script>
+</body>
+</html>
+`))
@@ -0,0 +1,116 @@
+// Package db integrates SQL databases with GoAPI
+// Open creates a *sql.DB with a configured connection pool and slow query logging; ORMs and query
+// builders (GORM, sqlx) are built on top of it so they share the pool. The Plugin registers the
+// database as a dependency, checks it on startup and from a health route, and closes it on
+// shutdown; WithTx and the Transaction middleware run request-scoped transactions
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Config configures a database
+type Config struct {
+	Driver string // Registered database/sql driver name ("postgres", "mysql", "sqlite3"...)
+	DSN    string // Data source name
+
+	MaxOpenConns    int           // Connections open at most (default 25; negative = unlimited)
+	MaxIdleConns    int           // Idle connections kept (default 5)
+	ConnMaxLifetime time.Duration // Connections older than this are replaced (default 30 minutes)
+	ConnMaxIdleTime time.Duration // Idle connections are closed after this (default 5 minutes)
+
+	SlowQueryThreshold time.Duration // Queries slower than this are logged (default 200ms; negative disables it)
+	Logger             *log.Logger   // Logger of slow queries (nil = log.Default())
+}
+
+// DefaultConfig returns the default pool and logging settings for a driver and DSN
+func DefaultConfig(driverName, dsn string) Config {
+	return Config{
+		Driver:             driverName,
+		DSN:                dsn,
+		MaxOpenConns:       25,
+		MaxIdleConns:       5,
+		ConnMaxLifetime:    30 * time.Minute,
+		ConnMaxIdleTime:    5 * time.Minute,
+		SlowQueryThreshold: 200 * time.Millisecond,
+	}
+}
+
+// Open opens a database with the pool settings of the config
+// Like sql.Open it does not connect; the plugin pings the database on startup
+func Open(config Config) (*sql.DB, error) {
+	opened, err := sql.Open(config.Driver, config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+	if config.SlowQueryThreshold == 0 {
+		config.SlowQueryThreshold = DefaultConfig(config.Driver, config.DSN).SlowQueryThreshold
+	}
+	if config.SlowQueryThreshold < 0 {
+		Configure(opened, config)
+		return opened, nil
+	}
+
+	// Reopen the database on a connector that times the queries
+	var connector driver.Connector
+	if driverContext, ok := opened.Driver().(driver.DriverContext); ok {
+		if connector, err = driverContext.OpenConnector(config.DSN); err != nil {
+			opened.Close()
+			return nil, fmt.Errorf("db: %w", err)
+		}
+	} else {
+		connector = dsnConnector{dsn: config.DSN, driver: opened.Driver()}
+	}
+	opened.Close()
+
+	database := sql.OpenDB(SlowQueryLogger(connector, config.SlowQueryThreshold, config.Logger))
+	Configure(database, config)
+	return database, nil
+}
+
+// Configure applies the pool settings of a config to a database; zero values take the defaults
+func Configure(database *sql.DB, config Config) {
+	defaults := DefaultConfig(config.Driver, config.DSN)
+	if config.MaxOpenConns == 0 {
+		config.MaxOpenConns = defaults.MaxOpenConns
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = defaults.MaxIdleConns
+	}
+	if config.ConnMaxLifetime == 0 {
+		config.ConnMaxLifetime = defaults.ConnMaxLifetime
+	}
+	if config.ConnMaxIdleTime == 0 {
+		config.ConnMaxIdleTime = defaults.ConnMaxIdleTime
+	}
+	database.SetMaxOpenConns(max(config.MaxOpenConns, 0))
+	database.SetMaxIdleConns(config.MaxIdleConns)
+	database.SetConnMaxLifetime(config.ConnMaxLifetime)
+	database.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+}
+
+// Ping checks that the database is reachable within a timeout
+func Ping(ctx context.Context, database *sql.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return database.PingContext(ctx)
+}
+
+// dsnConnector opens connections of drivers that do not implement driver.DriverContext
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// ErrNoTransaction is returned by the *sql.Tx dependency outside of a transaction
+var ErrNoTransaction = errors.New("db: the request has no transaction")
+
+// TransactionConfig configures the Transaction middleware
+type TransactionConfig struct {
+	Options *sql.TxOptions          // Isolation level and read-only mode (nil = driver defaults)
+	Skip    func(*gin.Context) bool // Requests that run without a transaction (nil = none)
+}
+
+// Transaction runs each request in a transaction, available with Tx, WithTx and as a *sql.Tx
+// dependency. The transaction is committed before the response is sent when the status is 2xx and
+// no error was added with c.Error, and rolled back otherwise or when the handler panics. When the
+// commit fails the client gets a 500 instead of the response of the handler
+// Apply it to the routes that write (goapi.WithMiddleware or a group) rather than globally, so
+// reads do not hold a connection for the whole request
+func Transaction(config ...TransactionConfig) gin.HandlerFunc {
+	var cfg TransactionConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	return func(c *gin.Context) {
+		if Tx(c) != nil || (cfg.Skip != nil && cfg.Skip(c)) {
+			c.Next()
+			return
+		}
+		database := FromContext(c)
+		if database == nil {
+			responses.InternalServerError(c, ErrNoDatabase.Error())
+			c.Abort()
+			return
+		}
+		tx, err := database.BeginTx(c.Request.Context(), cfg.Options)
+		if err != nil {
+			log.Printf("[DB] begin transaction: %v", err)
+			c.JSON(http.StatusServiceUnavailable, responses.ErrorResponse{
+				Detail:    "Database unavailable",
+				Type:      "service_unavailable",
+				RequestID: c.GetString(responses.RequestIDKey),
+			})
+			c.Abort()
+			return
+		}
+
+		state := &requestTx{c: c, tx: tx}
+		c.Set(txKey, tx)
+		writer := &txWriter{ResponseWriter: c.Writer, state: state}
+		c.Writer = writer
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				state.rollback()
+				panic(recovered)
+			}
+		}()
+		c.Next()
+		// Responses without a body have not been written yet
+		writer.settle()
+	}
+}
+
+// requestTx is the transaction of a request
+type requestTx struct {
+	c         *gin.Context
+	tx        *sql.Tx
+	once      sync.Once
+	commitErr error
+}
+
+// finish commits or rolls back the transaction once, from the status of the response
+func (state *requestTx) finish() {
+	state.once.Do(func() {
+		state.c.Set(txKey, nil)
+		status := state.c.Writer.Status()
+		if status < 200 || status > 299 || len(state.c.Errors) > 0 {
+			_ = state.tx.Rollback()
+			return
+		}
+		if err := state.tx.Commit(); err != nil {
+			log.Printf("[DB] commit transaction: %v", err)
+			state.commitErr = err
+		}
+	})
+}
+
+// rollback aborts the transaction when the handler panics
+func (state *requestTx) rollback() {
+	state.once.Do(func() {
+		state.c.Set(txKey, nil)
+		_ = state.tx.Rollback()
+	})
+}
+
+// txWriter finishes the transaction right before the response is sent
+type txWriter struct {
+	gin.ResponseWriter
+	state  *requestTx
+	failed bool // The commit failed and the error response was written
+}
+
+// settle finishes the transaction and, when the commit failed, replaces the response by a 500
+// It reports whether the response of the handler can still be written
+func (w *txWriter) settle() bool {
+	w.state.finish()
+	if w.state.commitErr == nil {
+		return true
+	}
+	if !w.failed {
+		w.failed = true
+		w.ResponseWriter.Header().Del("Content-Length")
+		w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		body, _ := json.Marshal(responses.ErrorResponse{
+			Detail:    "Transaction could not be committed",
+			Type:      "internal_server_error",
+			RequestID: w.state.c.GetString(responses.RequestIDKey),
+		})
+		_, _ = w.ResponseWriter.Write(body)
+	}
+	return false
+}
+
+// Write implements http.ResponseWriter
+func (w *txWriter) Write(data []byte) (int, error) {
+	if !w.settle() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter
+func (w *txWriter) WriteString(s string) (int, error) {
+	if !w.settle() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// WriteHeaderNow implements gin.ResponseWriter
+func (w *txWriter) WriteHeaderNow() {
+	if w.settle() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Flush implements http.Flusher
+func (w *txWriter) Flush() {
+	w.settle()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// Plugin wires a database into a GoAPI instance
+// It registers *sql.DB and the *sql.Tx of the request as dependencies, makes the database available
+// to WithTx and Transaction, pings it on startup, serves
+// its health and pool statistics on HealthPath and closes it on shutdown
+type Plugin struct {
+	DB          *sql.DB
+	HealthPath  string        // Health route (default "/health/db"; empty disables it)
+	PingTimeout time.Duration // Timeout of the startup and health pings (default 5s)
+}
+
+// NewPlugin creates a plugin for a database
+func NewPlugin(database *sql.DB) *Plugin {
+	return &Plugin{
+		DB:          database,
+		HealthPath:  "/health/db",
+		PingTimeout: 5 * time.Second,
+	}
+}
+
+// Provide registers a database handle built on the database, such as *gorm.DB or *sqlx.DB, as a
+// dependency resolved to that same value
+func Provide[T any](api *goapi.GoAPI, handle T) {
+	api.RegisterDependency(func(c *gin.Context) (interface{}, error) {
+		return handle, nil
+	}, (*T)(nil))
+}
+
+// HealthStatus is the body of the health route
+type HealthStatus struct {
+	Status          string `json:"status" example:"ok"`
+	OpenConnections int    `json:"open_connections" example:"4"`
+	InUse           int    `json:"in_use" example:"1"`
+	Idle            int    `json:"idle" example:"3"`
+	WaitCount       int64  `json:"wait_count" example:"0"`
+	WaitDurationMS  int64  `json:"wait_duration_ms" example:"0"`
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "db"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.AddMiddleware(func(c *gin.Context) {
+		c.Set(dbKey, p.DB)
+		c.Next()
+	})
+	api.RegisterDependency(func(c *gin.Context) (interface{}, error) {
+		return p.DB, nil
+	}, (*sql.DB)(nil))
+	api.RegisterDependency(func(c *gin.Context) (interface{}, error) {
+		if tx := Tx(c); tx != nil {
+			return tx, nil
+		}
+		return nil, ErrNoTransaction
+	}, (*sql.Tx)(nil))
+
+	if p.HealthPath != "" {
+		api.GET(p.HealthPath, p.health,
+			router.WithTags("Health"),
+			router.WithSummary("Database health"),
+			router.WithResponseModel(http.StatusOK, HealthStatus{}, "The database answers"),
+			router.WithResponseModel(http.StatusServiceUnavailable, HealthStatus{}, "The database is unreachable"),
+		)
+	}
+	return nil
+}
+
+// OnStartup implements goapi.StartupPlugin
+func (p *Plugin) OnStartup(ctx context.Context) error {
+	return Ping(ctx, p.DB, p.PingTimeout)
+}
+
+// OnShutdown implements goapi.ShutdownPlugin
+func (p *Plugin) OnShutdown(ctx context.Context) error {
+	return p.DB.Close()
+}
+
+// health pings the database and reports the pool statistics
+func (p *Plugin) health(c *gin.Context) {
+	stats := p.DB.Stats()
+	status := HealthStatus{
+		Status:          "ok",
+		OpenConnections: stats.OpenConnections,
+		InUse:           stats.InUse,
+		Idle:            stats.Idle,
+		WaitCount:       stats.WaitCount,
+		WaitDurationMS:  stats.WaitDuration.Milliseconds(),
+	}
+	// Driver errors can include internal addresses, so they are not published
+	if err := Ping(c.Request.Context(), p.DB, p.PingTimeout); err != nil {
+		status.Status = "unavailable"
+		c.JSON(http.StatusServiceUnavailable, status)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
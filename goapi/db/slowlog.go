@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// maxLoggedQuery is the length of the query text included in slow query logs
+const maxLoggedQuery = 1000
+
+// SlowQueryLogger wraps a connector so statements slower than threshold are logged
+// Only the query text and the duration are logged, never the arguments; use it with sql.OpenDB
+// for databases not opened with Open
+func SlowQueryLogger(connector driver.Connector, threshold time.Duration, logger *log.Logger) driver.Connector {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &slowConnector{Connector: connector, threshold: threshold, logger: logger}
+}
+
+// slowConnector opens timed connections
+type slowConnector struct {
+	driver.Connector
+	threshold time.Duration
+	logger    *log.Logger
+}
+
+func (c *slowConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &slowConn{Conn: conn, connector: c}, nil
+}
+
+// observe logs a statement that took longer than the threshold
+func (c *slowConnector) observe(query string, start time.Time) {
+	elapsed := time.Since(start)
+	if elapsed < c.threshold {
+		return
+	}
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedQuery {
+		query = query[:maxLoggedQuery] + "..."
+	}
+	c.logger.Printf("[DB] slow query (%s): %s", elapsed.Round(time.Millisecond), query)
+}
+
+// slowConn times the statements run on a connection
+// It implements the optional driver interfaces, delegating to the wrapped connection or answering
+// driver.ErrSkip so database/sql falls back to prepared statements
+type slowConn struct {
+	driver.Conn
+	connector *slowConnector
+}
+
+func (c *slowConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *slowConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &slowStmt{Stmt: stmt, query: query, connector: c.connector}, nil
+}
+
+func (c *slowConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer c.connector.observe(query, time.Now())
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *slowConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	defer c.connector.observe(query, time.Now())
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *slowConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *slowConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *slowConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *slowConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *slowConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// slowStmt times the executions of a prepared statement
+type slowStmt struct {
+	driver.Stmt
+	query     string
+	connector *slowConnector
+}
+
+func (s *slowStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	defer s.connector.observe(s.query, time.Now())
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := namedToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+func (s *slowStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	defer s.connector.observe(s.query, time.Now())
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := namedToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values)
+}
+
+func (s *slowStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (s *slowStmt) ColumnConverter(index int) driver.ValueConverter {
+	if converter, ok := s.Stmt.(driver.ColumnConverter); ok {
+		return converter.ColumnConverter(index)
+	}
+	return driver.DefaultParameterConverter
+}
+
+// namedToValues converts positional arguments for drivers without context support
+func namedToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("db: the driver does not support named arguments")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys of the request-scoped database state
+const (
+	dbKey = "goapi.db"
+	txKey = "goapi.db.tx"
+)
+
+// ErrNoDatabase is returned when the plugin did not run for the request
+var ErrNoDatabase = errors.New("db: database plugin is not installed")
+
+// FromContext returns the database installed by the plugin, or nil
+func FromContext(c *gin.Context) *sql.DB {
+	database, _ := c.Value(dbKey).(*sql.DB)
+	return database
+}
+
+// Tx returns the transaction of the request started by WithTx or Transaction, or nil outside of it
+func Tx(c *gin.Context) *sql.Tx {
+	tx, _ := c.Value(txKey).(*sql.Tx)
+	return tx
+}
+
+// WithTx runs fn in a transaction bound to the request
+// The transaction is committed when fn returns nil and rolled back when it returns an error or
+// panics. Calls nested inside fn reuse the same transaction, so helpers can call WithTx without
+// knowing whether a transaction is already open; it is canceled with the request context
+func WithTx(c *gin.Context, fn func(tx *sql.Tx) error) error {
+	return WithTxOptions(c, nil, fn)
+}
+
+// WithTxOptions is WithTx with isolation level and read-only options
+func WithTxOptions(c *gin.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
+	if tx := Tx(c); tx != nil {
+		return fn(tx)
+	}
+	database := FromContext(c)
+	if database == nil {
+		return ErrNoDatabase
+	}
+
+	tx, err := database.BeginTx(c.Request.Context(), opts)
+	if err != nil {
+		return fmt.Errorf("db: begin transaction: %w", err)
+	}
+	c.Set(txKey, tx)
+	defer func() {
+		c.Set(txKey, nil)
+		if recovered := recover(); recovered != nil {
+			_ = tx.Rollback()
+			panic(recovered)
+		}
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+				err = errors.Join(err, fmt.Errorf("db: rollback: %w", rollbackErr))
+			}
+			return
+		}
+		if commitErr := tx.Commit(); commitErr != nil {
+			err = fmt.Errorf("db: commit: %w", commitErr)
+		}
+	}()
+	return fn(tx)
+}
@@ -2,11 +2,15 @@
 package dependencies
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
 )
 
 // DependencyProvider is a function that provides a dependency
@@ -78,16 +82,20 @@ func (dc *DependencyContainer) RegisterSingleton(provider DependencyProvider, ta
 
 // Resolve resolves a dependency
 func (dc *DependencyContainer) Resolve(c *gin.Context, target interface{}) error {
-	dc.mutex.RLock()
-	defer dc.mutex.RUnlock()
-	
 	targetType := reflect.TypeOf(target)
 	if targetType.Kind() != reflect.Ptr {
 		return fmt.Errorf("target must be a pointer")
 	}
 	
 	elementType := targetType.Elem()
+	dc.mutex.RLock()
 	provider, exists := dc.providers[elementType]
+	if !exists && elementType.Kind() == reflect.Ptr {
+		// Providers registered with a (*T)(nil) target are stored under T
+		provider, exists = dc.providers[elementType.Elem()]
+	}
+	// Singleton providers take the lock themselves, so call the provider unlocked
+	dc.mutex.RUnlock()
 	if !exists {
 		return fmt.Errorf("no provider registered for type %s", elementType.String())
 	}
@@ -101,6 +109,15 @@ func (dc *DependencyContainer) Resolve(c *gin.Context, target interface{}) error
 	return nil
 }
 
+// Resolve resolves the dependency of type T
+//
+//	user, err := dependencies.Resolve[*dependencies.CurrentUser](api.GetDependencyContainer(), c)
+func Resolve[T any](dc *DependencyContainer, c *gin.Context) (T, error) {
+	var target T
+	err := dc.Resolve(c, &target)
+	return target, err
+}
+
 // Dependency represents a dependency that can be injected
 type Dependency interface {
 	GetType() reflect.Type
@@ -130,62 +147,128 @@ func (cd *CommonDependencies) GetContainer() *DependencyContainer {
 	return cd.container
 }
 
-// Database dependency example
-type Database struct {
-	ConnectionString string
-	Connected        bool
+// Context keys of the authenticated user, set by the authentication middlewares
+const (
+	// CurrentUserKey holds the *CurrentUser of the request
+	CurrentUserKey = "current_user"
+	// ClaimsKey holds verified JWT claims as map[string]interface{}
+	ClaimsKey = "claims"
+	// UserIDKey holds the ID of the user as a string
+	UserIDKey = "user_id"
+)
+
+// ErrNotAuthenticated is returned by CurrentUserProvider when the request has no authenticated user
+// The ErrorHandler middleware answers 401 to handlers passing it, or an error wrapping it, to c.Error
+var ErrNotAuthenticated = errors.New("not authenticated")
+
+// CurrentUser represents the current authenticated user
+type CurrentUser struct {
+	ID       string                 `json:"id"`
+	Username string                 `json:"username,omitempty"`
+	Email    string                 `json:"email,omitempty"`
+	Roles    []string               `json:"roles,omitempty"`
+	Scopes   []string               `json:"scopes,omitempty"`
+	Claims   map[string]interface{} `json:"-"` // Verified JWT claims, when authenticated by a token
 }
 
-// Connect simulates database connection
-func (db *Database) Connect() error {
-	db.Connected = true
-	return nil
+// HasRole reports whether the user has a role
+func (u *CurrentUser) HasRole(role string) bool {
+	return contains(u.Roles, role)
 }
 
-// Close simulates database disconnection
-func (db *Database) Close() error {
-	db.Connected = false
-	return nil
+// HasScope reports whether the user was granted a scope
+func (u *CurrentUser) HasScope(scope string) bool {
+	return contains(u.Scopes, scope)
 }
 
-// DatabaseProvider provides a database dependency
-func DatabaseProvider(connectionString string) DependencyProvider {
-	return func(c *gin.Context) (interface{}, error) {
-		db := &Database{
-			ConnectionString: connectionString,
+// contains reports whether a list has a value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
 		}
-		if err := db.Connect(); err != nil {
-			return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return false
+}
+
+// UserSource finds the authenticated user of a request; nil, nil when it has none
+type UserSource func(c *gin.Context) (*CurrentUser, error)
+
+// CurrentUserProvider provides the user authenticated by the middlewares of the request
+// The sources run after ContextUser, in order, until one finds a user (sessions.UserSource reads
+// the user of the session). Without a user it fails with ErrNotAuthenticated: request headers
+// are never trusted as a user
+func CurrentUserProvider(sources ...UserSource) DependencyProvider {
+	sources = append([]UserSource{ContextUser}, sources...)
+	return func(c *gin.Context) (interface{}, error) {
+		for _, source := range sources {
+			user, err := source(c)
+			if err != nil {
+				return nil, err
+			}
+			if user != nil {
+				return user, nil
+			}
 		}
-		return db, nil
+		return nil, ErrNotAuthenticated
 	}
 }
 
-// CurrentUser represents the current authenticated user
-type CurrentUser struct {
-	ID       string
-	Username string
-	Email    string
-	Roles    []string
+// ContextUser reads the user set by the authentication middlewares: a *CurrentUser under
+// CurrentUserKey, then the claims under ClaimsKey, then a user ID under UserIDKey
+func ContextUser(c *gin.Context) (*CurrentUser, error) {
+	if user, ok := c.Value(CurrentUserKey).(*CurrentUser); ok && user != nil {
+		return user, nil
+	}
+	if claims, ok := c.Value(ClaimsKey).(map[string]interface{}); ok {
+		return CurrentUserFromClaims(claims), nil
+	}
+	if userID := c.GetString(UserIDKey); userID != "" {
+		return &CurrentUser{ID: userID, Username: c.GetString("username")}, nil
+	}
+	return nil, nil
 }
 
-// CurrentUserProvider provides the current user from the request context
-func CurrentUserProvider() DependencyProvider {
-	return func(c *gin.Context) (interface{}, error) {
-		// This would typically extract user information from JWT token or session
-		// For now, we'll return a mock user
-		userID := c.GetHeader("X-User-ID")
-		if userID == "" {
-			return nil, fmt.Errorf("user not authenticated")
+// CurrentUserFromClaims builds the user of verified JWT claims: "sub", "preferred_username" or
+// "name", "email", "roles" or "role", and "scope" as a space separated string or "scp" as a list
+func CurrentUserFromClaims(claims map[string]interface{}) *CurrentUser {
+	user := &CurrentUser{Claims: claims}
+	user.ID, _ = claims["sub"].(string)
+	if username, ok := claims["preferred_username"].(string); ok {
+		user.Username = username
+	} else {
+		user.Username, _ = claims["name"].(string)
+	}
+	user.Email, _ = claims["email"].(string)
+	user.Roles = ClaimList(claims["roles"])
+	if user.Roles == nil {
+		user.Roles = ClaimList(claims["role"])
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		user.Scopes = strings.Fields(scope)
+	} else {
+		user.Scopes = ClaimList(claims["scp"])
+	}
+	return user
+}
+
+// ClaimList reads a claim holding a list of strings or a single space separated string
+func ClaimList(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []string:
+		return value
+	case []interface{}:
+		list := make([]string, 0, len(value))
+		for _, item := range value {
+			if text, ok := item.(string); ok {
+				list = append(list, text)
+			}
 		}
-		
-		return &CurrentUser{
-			ID:       userID,
-			Username: c.GetHeader("X-Username"),
-			Email:    c.GetHeader("X-User-Email"),
-			Roles:    []string{"user"}, // This would come from the token/session
-		}, nil
+		return list
 	}
+	return nil
 }
 
 // Settings represents application settings
@@ -213,7 +296,8 @@ type Logger interface {
 
 // SimpleLogger is a simple logger implementation
 type SimpleLogger struct {
-	prefix string
+	prefix    string
+	requestID string
 }
 
 // NewSimpleLogger creates a new simple logger
@@ -221,29 +305,43 @@ func NewSimpleLogger(prefix string) *SimpleLogger {
 	return &SimpleLogger{prefix: prefix}
 }
 
+// WithRequestID returns a copy of the logger tagging every message with a request ID
+func (l *SimpleLogger) WithRequestID(requestID string) *SimpleLogger {
+	return &SimpleLogger{prefix: l.prefix, requestID: requestID}
+}
+
 // Info logs an info message
 func (l *SimpleLogger) Info(msg string, fields ...interface{}) {
-	fmt.Printf("[INFO] %s: %s\n", l.prefix, fmt.Sprintf(msg, fields...))
+	l.log("INFO", msg, fields)
 }
 
 // Error logs an error message
 func (l *SimpleLogger) Error(msg string, fields ...interface{}) {
-	fmt.Printf("[ERROR] %s: %s\n", l.prefix, fmt.Sprintf(msg, fields...))
+	l.log("ERROR", msg, fields)
 }
 
 // Debug logs a debug message
 func (l *SimpleLogger) Debug(msg string, fields ...interface{}) {
-	fmt.Printf("[DEBUG] %s: %s\n", l.prefix, fmt.Sprintf(msg, fields...))
+	l.log("DEBUG", msg, fields)
 }
 
 // Warn logs a warning message
 func (l *SimpleLogger) Warn(msg string, fields ...interface{}) {
-	fmt.Printf("[WARN] %s: %s\n", l.prefix, fmt.Sprintf(msg, fields...))
+	l.log("WARN", msg, fields)
+}
+
+// log prints a message with its level, prefix and request ID
+func (l *SimpleLogger) log(level, msg string, fields []interface{}) {
+	if l.requestID != "" {
+		fmt.Printf("[%s] %s [%s]: %s\n", level, l.prefix, l.requestID, fmt.Sprintf(msg, fields...))
+		return
+	}
+	fmt.Printf("[%s] %s: %s\n", level, l.prefix, fmt.Sprintf(msg, fields...))
 }
 
-// LoggerProvider provides a logger dependency
+// LoggerProvider provides a logger dependency tagging messages with the ID of the request
 func LoggerProvider(prefix string) DependencyProvider {
 	return func(c *gin.Context) (interface{}, error) {
-		return NewSimpleLogger(prefix), nil
+		return NewSimpleLogger(prefix).WithRequestID(c.GetString(responses.RequestIDKey)), nil
 	}
 }
@@ -0,0 +1,173 @@
+package deprecation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/config"
+)
+
+// LoadConfig reads the schedules configured under a prefix (default "deprecations") of a config.Manager
+// Every schedule is a nested object named freely; dates are RFC 3339 or YYYY-MM-DD (UTC):
+//
+//	APP_DEPRECATIONS__V1__PATH=/v1/*
+//	APP_DEPRECATIONS__V1__DEPRECATED_AT=2025-01-01
+//	APP_DEPRECATIONS__V1__BROWNOUT_AT=2025-05-01T14:00:00Z
+//	APP_DEPRECATIONS__V1__BROWNOUT_EVERY=168h
+//	APP_DEPRECATIONS__V1__BROWNOUTS=2025-06-02T00:00:00Z/2025-06-03T00:00:00Z
+//	APP_DEPRECATIONS__V1__SUNSET_AT=2025-07-01
+//	APP_DEPRECATIONS__V1__SUCCESSOR=/v2
+//
+// Other keys are method, brownout_duration, link and message; brownouts is a comma separated
+// list (or a JSON array) of start/end windows
+func LoadConfig(manager *config.Manager, prefix string) ([]Schedule, error) {
+	if prefix == "" {
+		prefix = "deprecations"
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, key := range manager.Keys() {
+		rest, found := strings.CutPrefix(key, prefix+".")
+		if !found {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, ".")
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	schedules := make([]Schedule, 0, len(names))
+	for _, name := range names {
+		schedule, err := loadSchedule(manager, prefix+"."+name)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// BindConfig loads the schedules under a prefix and replaces them every time they change
+// onChange (when not nil) is called after every reload with its error; on error the previous
+// schedules are kept. The returned function stops following the configuration
+func (r *Registry) BindConfig(manager *config.Manager, prefix string, onChange func(error)) (func(), error) {
+	schedules, err := LoadConfig(manager, prefix)
+	if err != nil {
+		return nil, err
+	}
+	r.Replace(schedules...)
+
+	if prefix == "" {
+		prefix = "deprecations"
+	}
+	return manager.Subscribe(prefix+".*", func(event config.ChangeEvent) {
+		schedules, err := LoadConfig(manager, prefix)
+		if err == nil {
+			r.Replace(schedules...)
+		}
+		if onChange != nil {
+			onChange(err)
+		}
+	}), nil
+}
+
+// loadSchedule reads a single schedule
+func loadSchedule(manager *config.Manager, key string) (Schedule, error) {
+	schedule := Schedule{
+		Method:           strings.ToUpper(manager.GetString(key+".method", "")),
+		Path:             manager.GetString(key+".path", ""),
+		BrownoutEvery:    manager.GetDuration(key+".brownout_every", 0),
+		BrownoutDuration: manager.GetDuration(key+".brownout_duration", 0),
+		Link:             manager.GetString(key+".link", ""),
+		Successor:        manager.GetString(key+".successor", ""),
+		Message:          manager.GetString(key+".message", ""),
+	}
+	if schedule.Path == "" {
+		return Schedule{}, fmt.Errorf("%s.path is not set", key)
+	}
+
+	dates := []struct {
+		name   string
+		target *time.Time
+	}{
+		{"deprecated_at", &schedule.DeprecatedAt},
+		{"brownout_at", &schedule.BrownoutAt},
+		{"sunset_at", &schedule.SunsetAt},
+	}
+	for _, date := range dates {
+		value := manager.GetString(key+"."+date.name, "")
+		if value == "" {
+			continue
+		}
+		parsed, err := parseDate(value)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("%s.%s: %w", key, date.name, err)
+		}
+		*date.target = parsed
+	}
+
+	windows, err := parseWindows(manager, key+".brownouts")
+	if err != nil {
+		return Schedule{}, err
+	}
+	schedule.Brownouts = windows
+	return schedule, nil
+}
+
+// parseWindows reads a list of "start/end" windows from a comma separated string or an array
+func parseWindows(manager *config.Manager, key string) ([]Window, error) {
+	raw, exists := manager.Get(key)
+	if !exists {
+		return nil, nil
+	}
+
+	var values []string
+	switch typed := raw.(type) {
+	case []interface{}:
+		for _, value := range typed {
+			values = append(values, fmt.Sprintf("%v", value))
+		}
+	default:
+		for _, value := range strings.Split(fmt.Sprintf("%v", typed), ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+
+	windows := make([]Window, 0, len(values))
+	for _, value := range values {
+		start, end, found := strings.Cut(value, "/")
+		if !found {
+			return nil, fmt.Errorf("%s: window %q is not start/end", key, value)
+		}
+		startTime, err := parseDate(strings.TrimSpace(start))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		endTime, err := parseDate(strings.TrimSpace(end))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		windows = append(windows, Window{Start: startTime, End: endTime})
+	}
+	return windows, nil
+}
+
+// parseDate parses an RFC 3339 timestamp or a YYYY-MM-DD date (midnight UTC)
+func parseDate(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected RFC 3339 or YYYY-MM-DD)", value)
+	}
+	return parsed, nil
+}
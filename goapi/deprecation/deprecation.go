@@ -0,0 +1,282 @@
+// Package deprecation sunsets endpoints on a declarative schedule
+// A Schedule gives the dates at which a route (or a whole API version) is deprecated, starts
+// failing during brown-out windows and is finally gone; the Registry middleware escalates the
+// behaviour of each request from warning headers to 410 Gone as those dates pass
+package deprecation
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// Phase is the stage of a schedule at a given time
+type Phase int
+
+// Phases of a schedule, in escalation order
+const (
+	PhaseActive     Phase = iota // Not deprecated yet
+	PhaseDeprecated              // Served with deprecation headers
+	PhaseBrownout                // Temporarily rejected with 410 Gone, inside a brown-out window
+	PhaseSunset                  // Permanently rejected with 410 Gone
+)
+
+// String returns the name of the phase
+func (p Phase) String() string {
+	switch p {
+	case PhaseDeprecated:
+		return "deprecated"
+	case PhaseBrownout:
+		return "brownout"
+	case PhaseSunset:
+		return "sunset"
+	}
+	return "active"
+}
+
+// Window is a time range [Start, End)
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Contains reports whether t is inside the window
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// Schedule is the deprecation timeline of the routes matching a method and path
+type Schedule struct {
+	Method string `json:"method"` // HTTP method; "" or "*" matches every method
+	// Path is a route pattern ("/v1/users/:id") or a prefix ending with "/*" ("/v1/*")
+	Path string `json:"path"`
+
+	DeprecatedAt time.Time `json:"deprecated_at"` // Deprecation headers from this date (zero: immediately)
+	// BrownoutAt starts periodic brown-outs: a BrownoutDuration window every BrownoutEvery
+	// (defaults 1h every 24h); zero disables periodic brown-outs
+	BrownoutAt       time.Time     `json:"brownout_at"`
+	BrownoutEvery    time.Duration `json:"brownout_every"`
+	BrownoutDuration time.Duration `json:"brownout_duration"`
+	Brownouts        []Window      `json:"brownouts"` // Additional explicit brown-out windows
+	SunsetAt         time.Time     `json:"sunset_at"` // 410 Gone from this date (zero: never)
+
+	Link      string `json:"link"`      // Migration guide, sent as Link rel="deprecation"
+	Successor string `json:"successor"` // Replacement endpoint, sent as Link rel="successor-version"
+	Message   string `json:"message"`   // Detail of 410 responses
+}
+
+// Matches reports whether the schedule covers a route
+func (s Schedule) Matches(method, path string) bool {
+	if s.Method != "" && s.Method != "*" && !strings.EqualFold(s.Method, method) {
+		return false
+	}
+	if prefix, isPrefix := strings.CutSuffix(s.Path, "/*"); isPrefix {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+	return s.Path == path
+}
+
+// PhaseAt returns the phase of the schedule at a given time
+func (s Schedule) PhaseAt(now time.Time) Phase {
+	switch {
+	case !s.SunsetAt.IsZero() && !now.Before(s.SunsetAt):
+		return PhaseSunset
+	case s.inBrownout(now):
+		return PhaseBrownout
+	case s.DeprecatedAt.IsZero() || !now.Before(s.DeprecatedAt):
+		return PhaseDeprecated
+	}
+	return PhaseActive
+}
+
+// inBrownout reports whether t is inside an explicit or periodic brown-out window
+func (s Schedule) inBrownout(t time.Time) bool {
+	for _, window := range s.Brownouts {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	if s.BrownoutAt.IsZero() || t.Before(s.BrownoutAt) {
+		return false
+	}
+
+	every, duration := s.BrownoutEvery, s.BrownoutDuration
+	if every <= 0 {
+		every = 24 * time.Hour
+	}
+	if duration <= 0 {
+		duration = time.Hour
+	}
+	return t.Sub(s.BrownoutAt)%every < duration
+}
+
+// specificity ranks schedules so exact routes win over prefixes and longer prefixes over shorter ones
+func (s Schedule) specificity() int {
+	score := len(s.Path) * 2
+	if !strings.HasSuffix(s.Path, "/*") {
+		score += 1 << 20
+	}
+	if s.Method != "" && s.Method != "*" {
+		score++
+	}
+	return score
+}
+
+// Registry holds the schedules and enforces them
+// It is safe for concurrent use; schedules can be replaced while serving (see BindConfig)
+type Registry struct {
+	// Now returns the current time (default time.Now); set before serving
+	Now func() time.Time
+	// OnCall is notified of every request to a scheduled route, e.g. to count remaining callers
+	OnCall    func(c *gin.Context, schedule Schedule, phase Phase)
+	schedules []Schedule
+	mutex     sync.RWMutex
+}
+
+// NewRegistry creates a registry with the given schedules
+func NewRegistry(schedules ...Schedule) *Registry {
+	registry := &Registry{Now: time.Now}
+	registry.Replace(schedules...)
+	return registry
+}
+
+// Set adds a schedule or replaces the one with the same method and path
+func (r *Registry) Set(schedule Schedule) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, current := range r.schedules {
+		if current.Method == schedule.Method && current.Path == schedule.Path {
+			r.schedules[i] = schedule
+			return
+		}
+	}
+	r.schedules = append(r.schedules, schedule)
+	r.sort()
+}
+
+// Remove deletes the schedule of a method and path
+func (r *Registry) Remove(method, path string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, current := range r.schedules {
+		if current.Method == method && current.Path == path {
+			r.schedules = append(r.schedules[:i], r.schedules[i+1:]...)
+			return
+		}
+	}
+}
+
+// Replace swaps every schedule at once
+func (r *Registry) Replace(schedules ...Schedule) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.schedules = append([]Schedule(nil), schedules...)
+	r.sort()
+}
+
+// sort orders the schedules by specificity; the caller holds the lock
+func (r *Registry) sort() {
+	sort.SliceStable(r.schedules, func(i, j int) bool {
+		return r.schedules[i].specificity() > r.schedules[j].specificity()
+	})
+}
+
+// Schedules returns a copy of the schedules, most specific first
+func (r *Registry) Schedules() []Schedule {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]Schedule(nil), r.schedules...)
+}
+
+// Lookup returns the most specific schedule covering a route
+func (r *Registry) Lookup(method, path string) (Schedule, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, schedule := range r.schedules {
+		if schedule.Matches(method, path) {
+			return schedule, true
+		}
+	}
+	return Schedule{}, false
+}
+
+// now returns the current time of the registry clock
+func (r *Registry) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// Middleware applies the phase of the schedule covering each request
+// Deprecated routes get Deprecation, Sunset and Link headers; routes in a brown-out window or past
+// their sunset date are rejected with 410 Gone and the same headers
+func (r *Registry) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" {
+			path = c.Request.URL.Path
+		}
+		schedule, scheduled := r.Lookup(c.Request.Method, path)
+		if !scheduled {
+			c.Next()
+			return
+		}
+
+		phase := schedule.PhaseAt(r.now())
+		if r.OnCall != nil {
+			r.OnCall(c, schedule, phase)
+		}
+		if phase == PhaseActive {
+			c.Next()
+			return
+		}
+
+		setHeaders(c, schedule)
+		if phase == PhaseDeprecated {
+			c.Next()
+			return
+		}
+
+		detail := schedule.Message
+		if detail == "" {
+			detail = "This endpoint is no longer available"
+			if phase == PhaseBrownout {
+				detail = "This endpoint is deprecated and temporarily unavailable during a scheduled brown-out"
+			}
+		}
+		errorType := "gone"
+		if phase == PhaseBrownout {
+			errorType = "deprecated_brownout"
+		}
+		c.AbortWithStatusJSON(http.StatusGone, responses.ErrorResponse{Detail: detail, Type: errorType})
+	}
+}
+
+// setHeaders writes the Deprecation (RFC 9745), Sunset (RFC 8594) and Link headers of a schedule
+func setHeaders(c *gin.Context, schedule Schedule) {
+	if schedule.DeprecatedAt.IsZero() {
+		c.Header("Deprecation", "true")
+	} else {
+		c.Header("Deprecation", "@"+strconv.FormatInt(schedule.DeprecatedAt.Unix(), 10))
+	}
+	if !schedule.SunsetAt.IsZero() {
+		c.Header("Sunset", schedule.SunsetAt.UTC().Format(http.TimeFormat))
+	}
+	if schedule.Link != "" {
+		c.Writer.Header().Add("Link", "<"+schedule.Link+`>; rel="deprecation"`)
+	}
+	if schedule.Successor != "" {
+		c.Writer.Header().Add("Link", "<"+schedule.Successor+`>; rel="successor-version"`)
+	}
+}
@@ -0,0 +1,112 @@
+package deprecation
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/config"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+)
+
+// Plugin wires a Registry into a GoAPI instance
+// It enforces the schedules on every route, marks the scheduled operations as deprecated in the
+// OpenAPI document and, when Config is set, follows the schedules configured under Prefix
+type Plugin struct {
+	Registry *Registry
+	Config   *config.Manager // Optional source of the schedules
+	Prefix   string          // Configuration prefix of the schedules (default "deprecations")
+	OnError  func(error)
+	stop     func()
+}
+
+// NewPlugin creates a plugin enforcing the schedules of a registry
+func NewPlugin(registry *Registry) *Plugin {
+	return &Plugin{
+		Registry: registry,
+		Prefix:   "deprecations",
+		OnError: func(err error) {
+			log.Printf("deprecation schedules not reloaded: %v", err)
+		},
+	}
+}
+
+// NewConfigPlugin creates a plugin whose schedules are read from the configuration
+func NewConfigPlugin(manager *config.Manager, prefix string) *Plugin {
+	plugin := NewPlugin(NewRegistry())
+	plugin.Config = manager
+	if prefix != "" {
+		plugin.Prefix = prefix
+	}
+	return plugin
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "deprecation"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	if p.Config != nil {
+		stop, err := p.Registry.BindConfig(p.Config, p.Prefix, func(err error) {
+			if err != nil {
+				p.OnError(err)
+				return
+			}
+			api.InvalidateSpec()
+		})
+		if err != nil {
+			return err
+		}
+		p.stop = stop
+	}
+
+	api.AddMiddleware(p.Registry.Middleware())
+	api.OnOpenAPIDocument(p.document)
+	return nil
+}
+
+// OnShutdown implements goapi.ShutdownPlugin
+func (p *Plugin) OnShutdown(ctx context.Context) error {
+	if p.stop != nil {
+		p.stop()
+	}
+	return nil
+}
+
+// document marks the scheduled operations as deprecated and documents their timeline
+func (p *Plugin) document(document *openapi.Document) {
+	for path, item := range document.Paths {
+		routePath := openAPIToRoutePath(path)
+		for method, operation := range item.Operations() {
+			schedule, scheduled := p.Registry.Lookup(method, routePath)
+			if !scheduled {
+				continue
+			}
+			operation.Deprecated = true
+			if !schedule.DeprecatedAt.IsZero() {
+				operation.Extensions.Set("deprecated-at", schedule.DeprecatedAt.UTC().Format(time.RFC3339))
+			}
+			if !schedule.SunsetAt.IsZero() {
+				operation.Extensions.Set("sunset", schedule.SunsetAt.UTC().Format(time.RFC3339))
+			}
+			if schedule.Successor != "" {
+				operation.Extensions.Set("successor", schedule.Successor)
+			}
+		}
+	}
+}
+
+// openAPIToRoutePath converts "/users/{id}" back to the route pattern "/users/:id"
+func openAPIToRoutePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + segment[1:len(segment)-1]
+		}
+	}
+	return strings.Join(segments, "/")
+}
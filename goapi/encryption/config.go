@@ -0,0 +1,54 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/config"
+)
+
+// ConfigKeyProvider reads the keys from a config.Manager, so they can be served by any of its
+// sources (environment variables, mounted secret files) and rotated with a reload
+// Keys are base64 encoded under "<prefix>.keys.<id>" and the current one is named by "<prefix>.current_key":
+//
+//	APP_ENCRYPTION__CURRENT_KEY=k2
+//	APP_ENCRYPTION__KEYS__K1=<base64 key>
+//	APP_ENCRYPTION__KEYS__K2=<base64 key>
+type ConfigKeyProvider struct {
+	Manager *config.Manager
+	Prefix  string
+}
+
+// NewConfigKeyProvider creates a provider reading the keys under a prefix (default "encryption")
+func NewConfigKeyProvider(manager *config.Manager, prefix string) *ConfigKeyProvider {
+	if prefix == "" {
+		prefix = "encryption"
+	}
+	return &ConfigKeyProvider{Manager: manager, Prefix: prefix}
+}
+
+// CurrentKey implements KeyProvider
+func (p *ConfigKeyProvider) CurrentKey(ctx context.Context) (string, []byte, error) {
+	keyID := p.Manager.GetString(p.Prefix+".current_key", "")
+	if keyID == "" {
+		return "", nil, fmt.Errorf("%w: %s.current_key is not set", ErrKeyNotFound, p.Prefix)
+	}
+	key, err := p.Key(ctx, keyID)
+	if err != nil {
+		return "", nil, err
+	}
+	return keyID, key, nil
+}
+
+// Key implements KeyProvider
+func (p *ConfigKeyProvider) Key(ctx context.Context, keyID string) ([]byte, error) {
+	encoded := p.Manager.GetString(p.Prefix+".keys."+keyID, "")
+	if encoded == "" {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	key, err := ParseKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", keyID, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,402 @@
+// Package encryption provides field-level encryption for GoAPI models
+// Fields tagged `encrypt:"aes"` are sealed with AES-256-GCM before they are persisted and
+// opened transparently when they are read back; keys come from a KeyProvider so they can be
+// rotated and kept in a secrets manager instead of the code
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TagName is the struct tag that marks encrypted fields
+const TagName = "encrypt"
+
+// AlgorithmAES is the only supported tag value: AES-256-GCM
+const AlgorithmAES = "aes"
+
+// KeySize is the size of the AES-256 keys returned by providers
+const KeySize = 32
+
+// prefix marks encrypted values: enc:v1:<key id>:<base64(nonce || ciphertext)>
+const prefix = "enc:v1:"
+
+// Errors returned by the encryptor and the providers
+var (
+	// ErrKeyNotFound is returned by providers for unknown key identifiers
+	ErrKeyNotFound = errors.New("encryption key not found")
+	// ErrInvalidCiphertext is returned when an encrypted value is malformed or was tampered with
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+	// ErrUnsupportedField is returned for tagged fields that are not strings or byte slices
+	ErrUnsupportedField = errors.New("unsupported encrypted field")
+)
+
+// KeyProvider supplies the encryption keys
+// Implementations typically read them from a secrets manager; keys must be KeySize bytes long
+type KeyProvider interface {
+	// CurrentKey returns the key used to encrypt new values
+	CurrentKey(ctx context.Context) (keyID string, key []byte, err error)
+	// Key returns the key with the given identifier, to decrypt values written with older keys
+	Key(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// StaticKeyProvider keeps keys in memory
+// It suits keys loaded once at startup (environment variables, mounted secrets)
+type StaticKeyProvider struct {
+	currentID string
+	keys      map[string][]byte
+	mutex     sync.RWMutex
+}
+
+// NewStaticKeyProvider creates a provider whose current key is keyID
+func NewStaticKeyProvider(keyID string, key []byte) (*StaticKeyProvider, error) {
+	provider := &StaticKeyProvider{keys: make(map[string][]byte)}
+	if err := provider.AddKey(keyID, key); err != nil {
+		return nil, err
+	}
+	provider.currentID = keyID
+	return provider, nil
+}
+
+// AddKey adds a key that can decrypt existing values
+func (p *StaticKeyProvider) AddKey(keyID string, key []byte) error {
+	if len(key) != KeySize {
+		return fmt.Errorf("key %s must be %d bytes, got %d", keyID, KeySize, len(key))
+	}
+	if keyID == "" || strings.Contains(keyID, ":") {
+		return fmt.Errorf("invalid key identifier %q", keyID)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.keys[keyID] = append([]byte(nil), key...)
+	return nil
+}
+
+// Rotate makes an added key the current one; values encrypted with older keys remain readable
+func (p *StaticKeyProvider) Rotate(keyID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, exists := p.keys[keyID]; !exists {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	p.currentID = keyID
+	return nil
+}
+
+// CurrentKey implements KeyProvider
+func (p *StaticKeyProvider) CurrentKey(ctx context.Context) (string, []byte, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.currentID, p.keys[p.currentID], nil
+}
+
+// Key implements KeyProvider
+func (p *StaticKeyProvider) Key(ctx context.Context, keyID string) ([]byte, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	key, exists := p.keys[keyID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	return key, nil
+}
+
+// ParseKey decodes a base64 (standard or URL encoding) key, as stored in secrets managers
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := encoding.DecodeString(encoded); err == nil {
+			if len(key) != KeySize {
+				return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+			}
+			return key, nil
+		}
+	}
+	return nil, errors.New("key is not valid base64")
+}
+
+// GenerateKey creates a random AES-256 key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Encryptor encrypts and decrypts the tagged fields of models
+// It is safe for concurrent use
+type Encryptor struct {
+	provider KeyProvider
+}
+
+// NewEncryptor creates an encryptor using the keys of a provider
+func NewEncryptor(provider KeyProvider) *Encryptor {
+	return &Encryptor{provider: provider}
+}
+
+// EncryptStruct encrypts in place the tagged fields of a pointer to a struct
+// Nested structs, pointers, slices and maps are walked; values already encrypted are left untouched
+func (e *Encryptor) EncryptStruct(ctx context.Context, model interface{}) error {
+	value, err := structPointer(model)
+	if err != nil {
+		return err
+	}
+	return e.walk(value, "", func(field string, plaintext []byte) ([]byte, error) {
+		if isEncrypted(plaintext) {
+			return plaintext, nil
+		}
+		return e.encrypt(ctx, plaintext)
+	})
+}
+
+// DecryptStruct decrypts in place the tagged fields of a pointer to a struct
+// Values that are not encrypted (e.g. written before the field was tagged) are left untouched
+func (e *Encryptor) DecryptStruct(ctx context.Context, model interface{}) error {
+	value, err := structPointer(model)
+	if err != nil {
+		return err
+	}
+	return e.walk(value, "", func(field string, ciphertext []byte) ([]byte, error) {
+		if !isEncrypted(ciphertext) {
+			return ciphertext, nil
+		}
+		plaintext, err := e.decrypt(ctx, ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field, err)
+		}
+		return plaintext, nil
+	})
+}
+
+// Marshal encodes a model as JSON with its tagged fields encrypted
+// The model itself is not modified
+func (e *Encryptor) Marshal(ctx context.Context, model interface{}) ([]byte, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	modelType := reflect.TypeOf(model)
+	for modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	if modelType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got %s", modelType)
+	}
+
+	// Work on a JSON copy so nested pointers and slices of the caller are not encrypted
+	duplicate := reflect.New(modelType).Interface()
+	if err := json.Unmarshal(data, duplicate); err != nil {
+		return nil, err
+	}
+	if err := e.EncryptStruct(ctx, duplicate); err != nil {
+		return nil, err
+	}
+	return json.Marshal(duplicate)
+}
+
+// Unmarshal decodes JSON into a pointer to a struct and decrypts its tagged fields
+func (e *Encryptor) Unmarshal(ctx context.Context, data []byte, model interface{}) error {
+	if err := json.Unmarshal(data, model); err != nil {
+		return err
+	}
+	return e.DecryptStruct(ctx, model)
+}
+
+// EncryptString encrypts a single value, for columns handled outside of structs
+func (e *Encryptor) EncryptString(ctx context.Context, plaintext string) (string, error) {
+	ciphertext, err := e.encrypt(ctx, []byte(plaintext))
+	return string(ciphertext), err
+}
+
+// DecryptString decrypts a value produced by EncryptString
+func (e *Encryptor) DecryptString(ctx context.Context, ciphertext string) (string, error) {
+	plaintext, err := e.decrypt(ctx, []byte(ciphertext))
+	return string(plaintext), err
+}
+
+// IsEncrypted reports whether a value was produced by the encryptor
+func IsEncrypted(value string) bool {
+	return isEncrypted([]byte(value))
+}
+
+func isEncrypted(value []byte) bool {
+	return strings.HasPrefix(string(value), prefix)
+}
+
+// encrypt seals a value with the current key
+func (e *Encryptor) encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	keyID, key, err := e.provider.CurrentKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting encryption key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(keyID))
+	return []byte(prefix + keyID + ":" + base64.RawStdEncoding.EncodeToString(sealed)), nil
+}
+
+// decrypt opens a value with the key it was encrypted with
+func (e *Encryptor) decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	keyID, encoded, found := strings.Cut(strings.TrimPrefix(string(ciphertext), prefix), ":")
+	if !found {
+		return nil, ErrInvalidCiphertext
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	key, err := e.provider.Key(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting decryption key: %w", err)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+// newAEAD creates the AES-GCM cipher of a key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// structPointer checks that a model is a non-nil pointer to a struct
+func structPointer(model interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(model)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("expected a pointer to a struct, got %T", model)
+	}
+	return value.Elem(), nil
+}
+
+// walk applies transform to every tagged field reachable from a value
+func (e *Encryptor) walk(value reflect.Value, path string, transform func(field string, data []byte) ([]byte, error)) error {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		if value.Kind() == reflect.Interface {
+			// Values stored in interfaces are not addressable; only pointers can be updated
+			if value.Elem().Kind() != reflect.Ptr {
+				return nil
+			}
+		}
+		return e.walk(value.Elem(), path, transform)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := e.walk(value.Index(i), fmt.Sprintf("%s[%d]", path, i), transform); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Map values are not addressable: walk copies and store them back
+		iterator := value.MapRange()
+		for iterator.Next() {
+			element := reflect.New(iterator.Value().Type()).Elem()
+			element.Set(iterator.Value())
+			if err := e.walk(element, fmt.Sprintf("%s[%v]", path, iterator.Key()), transform); err != nil {
+				return err
+			}
+			value.SetMapIndex(iterator.Key(), element)
+		}
+	case reflect.Struct:
+		structType := value.Type()
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+
+			algorithm, tagged := field.Tag.Lookup(TagName)
+			if !tagged {
+				if err := e.walk(value.Field(i), fieldPath, transform); err != nil {
+					return err
+				}
+				continue
+			}
+			if algorithm != AlgorithmAES {
+				return fmt.Errorf("field %s: unsupported encryption algorithm %q", fieldPath, algorithm)
+			}
+			if err := transformField(value.Field(i), fieldPath, transform); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// transformField applies transform to a string, *string or []byte field
+func transformField(field reflect.Value, path string, transform func(field string, data []byte) ([]byte, error)) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	switch {
+	case field.Kind() == reflect.String:
+		if field.Len() == 0 {
+			return nil
+		}
+		result, err := transform(path, []byte(field.String()))
+		if err != nil {
+			return err
+		}
+		field.SetString(string(result))
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+		if field.Len() == 0 {
+			return nil
+		}
+		result, err := transform(path, field.Bytes())
+		if err != nil {
+			return err
+		}
+		field.SetBytes(result)
+	default:
+		return fmt.Errorf("%w: %s is %s, expected string or []byte", ErrUnsupportedField, path, field.Type())
+	}
+	return nil
+}
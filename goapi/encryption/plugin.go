@@ -0,0 +1,31 @@
+package encryption
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+)
+
+// Plugin wires an Encryptor into a GoAPI instance
+// It registers the encryptor as a singleton dependency for the persistence layer of the handlers
+type Plugin struct {
+	Encryptor *Encryptor
+}
+
+// NewPlugin creates a plugin sharing an encryptor built from a key provider
+func NewPlugin(provider KeyProvider) *Plugin {
+	return &Plugin{Encryptor: NewEncryptor(provider)}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "encryption"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Encryptor, nil
+	}, (*Encryptor)(nil))
+	return nil
+}
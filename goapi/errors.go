@@ -2,6 +2,8 @@ package goapi
 
 import (
 	"fmt"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
 )
 
 // APIError representa un error de la API
@@ -37,7 +39,7 @@ func NewAPIError(code int, message string, details ...interface{}) *APIError {
 func NotFoundError(resource string, id interface{}) *APIError {
 	return NewAPIError(
 		404,
-		fmt.Sprintf("%s con id %v no encontrado", resource, id),
+		i18n.Translate(i18n.DefaultLocale, i18n.KeyResourceNotFound, i18n.Params{"resource": resource, "id": id}),
 	)
 }
 
@@ -53,5 +55,5 @@ func ValidationError(message string, details interface{}) *APIError {
 
 // InternalError crea un error interno del servidor
 func InternalError(err error) *APIError {
-	return NewAPIError(500, "Error interno del servidor: "+err.Error())
+	return NewAPIError(500, i18n.Translate(i18n.DefaultLocale, i18n.KeyInternalError)+": "+err.Error())
 }
@@ -0,0 +1,253 @@
+// Package events provides an in-process event bus
+// Services publish events on topics and modules subscribe to them, so cross-cutting features
+// (audit logs, webhooks, cache invalidation) react to what handlers do without being called by them.
+// Subscribers run synchronously, inside Publish, or asynchronously on a pool of workers
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when publishing on a bus that was closed
+var ErrClosed = errors.New("event bus is closed")
+
+// Event is a published event
+type Event struct {
+	Topic     string
+	Payload   interface{}
+	Published time.Time
+}
+
+// Handler processes an event
+// Handlers of synchronous subscriptions receive the context of Publish; asynchronous ones receive
+// a context with the same values that is not canceled when the request ends
+type Handler func(ctx context.Context, event Event) error
+
+// Typed adapts a handler of a payload type; events carrying another payload type are ignored
+func Typed[T any](handler func(ctx context.Context, payload T) error) Handler {
+	return func(ctx context.Context, event Event) error {
+		payload, ok := event.Payload.(T)
+		if !ok {
+			return nil
+		}
+		return handler(ctx, payload)
+	}
+}
+
+// Config configures a Bus
+type Config struct {
+	Workers   int                // Goroutines running asynchronous handlers (default 4)
+	QueueSize int                // Pending asynchronous deliveries; Publish waits when full (default 1024)
+	OnError   func(Event, error) // Receives errors and panics of asynchronous handlers (default: log)
+}
+
+// DefaultConfig returns the default bus configuration
+func DefaultConfig() Config {
+	return Config{
+		Workers:   4,
+		QueueSize: 1024,
+		OnError: func(event Event, err error) {
+			log.Printf("event handler for %s failed: %v", event.Topic, err)
+		},
+	}
+}
+
+// SubscribeOption customizes a subscription
+type SubscribeOption func(*subscription)
+
+// Async delivers the events to the handler on the worker pool instead of inside Publish
+func Async() SubscribeOption {
+	return func(s *subscription) {
+		s.async = true
+	}
+}
+
+// subscription is a handler subscribed to a topic pattern
+type subscription struct {
+	id      uint64
+	pattern string
+	handler Handler
+	async   bool
+}
+
+// delivery is an event queued for an asynchronous handler
+type delivery struct {
+	ctx     context.Context
+	event   Event
+	handler Handler
+}
+
+// Bus dispatches events to the handlers subscribed to their topic
+type Bus struct {
+	config Config
+
+	mutex         sync.RWMutex
+	subscriptions []*subscription
+	nextID        uint64
+	closed        bool
+
+	queue   chan delivery
+	workers sync.WaitGroup
+	pending sync.WaitGroup // Asynchronous deliveries not finished yet
+}
+
+// New creates a bus and starts its workers
+func New(config ...Config) *Bus {
+	cfg := DefaultConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultConfig().Workers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultConfig().QueueSize
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = DefaultConfig().OnError
+	}
+
+	bus := &Bus{config: cfg, queue: make(chan delivery, cfg.QueueSize)}
+	bus.workers.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go bus.work()
+	}
+	return bus
+}
+
+// Subscribe registers a handler for a topic and returns a function removing it
+// Topics are matched exactly, "*" matches every topic and "users.*" every topic starting with "users."
+func (b *Bus) Subscribe(topic string, handler Handler, opts ...SubscribeOption) (unsubscribe func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.nextID++
+	sub := &subscription{id: b.nextID, pattern: topic, handler: handler}
+	for _, option := range opts {
+		option(sub)
+	}
+	b.subscriptions = append(b.subscriptions, sub)
+
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		for i, current := range b.subscriptions {
+			if current.id == sub.id {
+				b.subscriptions = append(b.subscriptions[:i:i], b.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish dispatches an event; see PublishContext
+func (b *Bus) Publish(topic string, payload interface{}) error {
+	return b.PublishContext(context.Background(), topic, payload)
+}
+
+// PublishContext dispatches an event to the handlers subscribed to its topic
+// Synchronous handlers run in subscription order and their errors are returned joined; asynchronous
+// handlers are queued, waiting for room in the queue until ctx is done
+func (b *Bus) PublishContext(ctx context.Context, topic string, payload interface{}) error {
+	event := Event{Topic: topic, Payload: payload, Published: time.Now()}
+
+	b.mutex.RLock()
+	if b.closed {
+		b.mutex.RUnlock()
+		return ErrClosed
+	}
+	var syncHandlers, asyncHandlers []Handler
+	for _, sub := range b.subscriptions {
+		switch {
+		case !matches(sub.pattern, topic):
+		case sub.async:
+			asyncHandlers = append(asyncHandlers, sub.handler)
+		default:
+			syncHandlers = append(syncHandlers, sub.handler)
+		}
+	}
+	// Deliveries are reserved before releasing the lock, so Close also waits for them
+	b.pending.Add(len(asyncHandlers))
+	b.mutex.RUnlock()
+
+	asyncCtx := context.WithoutCancel(ctx)
+	for i, handler := range asyncHandlers {
+		select {
+		case b.queue <- delivery{ctx: asyncCtx, event: event, handler: handler}:
+		case <-ctx.Done():
+			b.pending.Add(i - len(asyncHandlers))
+			return ctx.Err()
+		}
+	}
+
+	var errs []error
+	for _, handler := range syncHandlers {
+		if err := run(ctx, handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops accepting events and waits until the queued ones are handled or ctx is done
+func (b *Bus) Close(ctx context.Context) error {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.pending.Wait()
+		close(b.queue)
+		b.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event bus: %w with asynchronous events pending", ctx.Err())
+	}
+}
+
+// work runs asynchronous deliveries until the queue is closed
+func (b *Bus) work() {
+	defer b.workers.Done()
+	for delivery := range b.queue {
+		if err := run(delivery.ctx, delivery.handler, delivery.event); err != nil {
+			b.config.OnError(delivery.event, err)
+		}
+		b.pending.Done()
+	}
+}
+
+// run calls a handler, turning panics into errors
+func run(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic in event handler: %v", recovered)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// matches reports whether a topic matches a subscription pattern
+func matches(pattern, topic string) bool {
+	switch {
+	case pattern == "*" || pattern == topic:
+		return true
+	case strings.HasSuffix(pattern, ".*"):
+		return strings.HasPrefix(topic, pattern[:len(pattern)-1])
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+)
+
+// Plugin wires a Bus into a GoAPI instance
+// It registers *Bus as a dependency and, on shutdown, waits for the asynchronous handlers to finish
+// the queued events
+type Plugin struct {
+	Bus *Bus
+}
+
+// NewPlugin creates a plugin for a bus
+func NewPlugin(bus *Bus) *Plugin {
+	return &Plugin{Bus: bus}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "events"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.RegisterDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Bus, nil
+	}, (*Bus)(nil))
+	return nil
+}
+
+// OnShutdown implements goapi.ShutdownPlugin
+func (p *Plugin) OnShutdown(ctx context.Context) error {
+	return p.Bus.Close(ctx)
+}
@@ -0,0 +1,342 @@
+// Package expand embeds related resources in responses on request, with ?expand=orders
+// Expanders are registered per model type and load the related values of a whole batch of
+// models at once, so expanding a page of users runs one query for their orders instead of one
+// per user. Paths can be nested (orders.items) up to a maximum depth
+package expand
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/models"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// QueryParameter is the query parameter listing the expansions of a request
+const QueryParameter = "expand"
+
+// Errors returned for invalid expansions; handlers usually answer them with 400
+var (
+	ErrUnknownExpansion = errors.New("expand: unknown expansion")
+	ErrTooDeep          = errors.New("expand: expansion too deep")
+)
+
+// Config configures a Registry
+type Config struct {
+	MaxDepth int // Maximum number of segments of a path such as orders.items (default 3)
+}
+
+// Registry holds the expanders of the model types
+// It is safe for concurrent use; expanders are usually registered at startup
+type Registry struct {
+	config    Config
+	mutex     sync.RWMutex
+	expanders map[reflect.Type]map[string]expander
+}
+
+// expander loads a relation for a batch of models of one type
+type expander struct {
+	load func(ctx context.Context, parents reflect.Value) (reflect.Value, error)
+}
+
+// New creates a registry
+func New(config ...Config) *Registry {
+	var cfg Config
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = 3
+	}
+	return &Registry{config: cfg, expanders: make(map[reflect.Type]map[string]expander)}
+}
+
+// Register adds the expansion name to models of type P, which embeds the value load returns for
+// each of them under that name. load receives every model being expanded at once and must return
+// one value per model, in the same order: a slice for to-many relations (orders), a value or a
+// pointer for to-one relations (author, nil when missing). Expanders of the related type expand
+// its values in turn, for nested paths
+//
+//	expand.Register(registry, "orders", func(ctx context.Context, users []User) ([][]Order, error) {
+//		return orders.ByUsers(ctx, userIDs(users)) // one query for the whole page
+//	})
+func Register[P, R any](registry *Registry, name string, load func(ctx context.Context, parents []P) ([]R, error)) {
+	parentType := reflect.TypeOf((*P)(nil)).Elem()
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if registry.expanders[parentType] == nil {
+		registry.expanders[parentType] = make(map[string]expander)
+	}
+	registry.expanders[parentType][name] = expander{
+		load: func(ctx context.Context, parents reflect.Value) (reflect.Value, error) {
+			related, err := load(ctx, parents.Interface().([]P))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if len(related) != parents.Len() {
+				return reflect.Value{}, fmt.Errorf("expand: %s returned %d values for %d models", name, len(related), parents.Len())
+			}
+			return reflect.ValueOf(related), nil
+		},
+	}
+}
+
+// Each adapts a loader of one model to Register, for relations that cannot be batched;
+// it runs one load per model
+func Each[P, R any](load func(ctx context.Context, parent P) (R, error)) func(ctx context.Context, parents []P) ([]R, error) {
+	return func(ctx context.Context, parents []P) ([]R, error) {
+		related := make([]R, len(parents))
+		for i, parent := range parents {
+			value, err := load(ctx, parent)
+			if err != nil {
+				return nil, err
+			}
+			related[i] = value
+		}
+		return related, nil
+	}
+}
+
+// Names returns the expansions registered for the type of a model, sorted
+func (r *Registry) Names(model interface{}) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var names []string
+	for name := range r.expanders[modelType(reflect.TypeOf(model))] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parameter documents the expand query parameter of a route returning models like model
+func (r *Registry) Parameter(model interface{}) router.RouteOption {
+	description := "Related resources to embed, separated by commas; nest them with dots"
+	if names := r.Names(model); len(names) > 0 {
+		description += " (" + strings.Join(names, ", ") + ")"
+	}
+	return router.WithQueryParameter(QueryParameter, "string", description, false)
+}
+
+// Parse returns the expansions of a request, from ?expand=a,b.c and repeated expand parameters
+func Parse(c *gin.Context) []string {
+	var paths []string
+	for _, value := range c.QueryArray(QueryParameter) {
+		for _, path := range strings.Split(value, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// Apply expands data, a model or a slice of models, with the expansions of the request
+// Without expansions data is returned unchanged
+func (r *Registry) Apply(c *gin.Context, data interface{}) (interface{}, error) {
+	return r.Expand(c, data, Parse(c))
+}
+
+// Expand embeds the related resources of paths in data, a model or a slice of models
+// Models are serialized with models.Serialize and decoded into JSON objects that receive one
+// member per expansion; a slice gives a slice of objects. Unknown names return
+// ErrUnknownExpansion and paths longer than MaxDepth ErrTooDeep
+func (r *Registry) Expand(ctx context.Context, data interface{}, paths []string) (interface{}, error) {
+	if len(paths) == 0 || data == nil {
+		return data, nil
+	}
+	tree := make(pathTree)
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		if len(segments) > r.config.MaxDepth {
+			return nil, fmt.Errorf("%w: %s (at most %d levels)", ErrTooDeep, path, r.config.MaxDepth)
+		}
+		tree.add(segments)
+	}
+
+	value := reflect.ValueOf(data)
+	single := value.Kind() != reflect.Slice && value.Kind() != reflect.Array
+	if single {
+		slice := reflect.MakeSlice(reflect.SliceOf(value.Type()), 1, 1)
+		slice.Index(0).Set(value)
+		value = slice
+	}
+	objects, err := r.expand(ctx, value, tree, "")
+	if err != nil {
+		return nil, err
+	}
+	if single {
+		return objects[0], nil
+	}
+	return objects, nil
+}
+
+// pathTree holds the expansions requested below a level
+type pathTree map[string]pathTree
+
+func (t pathTree) add(segments []string) {
+	if len(segments) == 0 {
+		return
+	}
+	if t[segments[0]] == nil {
+		t[segments[0]] = make(pathTree)
+	}
+	t[segments[0]].add(segments[1:])
+}
+
+// expand converts a slice of models to JSON objects and embeds the expansions of a level
+func (r *Registry) expand(ctx context.Context, items reflect.Value, tree pathTree, prefix string) ([]interface{}, error) {
+	objects := make([]interface{}, items.Len())
+	for i := range objects {
+		object, err := toJSON(ctx, items.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		objects[i] = object
+	}
+	if len(tree) == 0 {
+		return objects, nil
+	}
+
+	// Expanders receive the models themselves, without pointers or nil entries
+	elementType := modelType(items.Type().Elem())
+	parents := reflect.MakeSlice(reflect.SliceOf(elementType), 0, items.Len())
+	var positions []int
+	for i := 0; i < items.Len(); i++ {
+		element := items.Index(i)
+		for element.Kind() == reflect.Ptr || element.Kind() == reflect.Interface {
+			if element.IsNil() {
+				break
+			}
+			element = element.Elem()
+		}
+		if element.Kind() == reflect.Ptr || element.Kind() == reflect.Interface || element.Type() != elementType {
+			continue
+		}
+		if _, isObject := objects[i].(map[string]interface{}); !isObject {
+			continue
+		}
+		parents = reflect.Append(parents, element)
+		positions = append(positions, i)
+	}
+
+	names := make([]string, 0, len(tree))
+	for name := range tree {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r.mutex.RLock()
+		e, ok := r.expanders[elementType][name]
+		r.mutex.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("%w: %s%s", ErrUnknownExpansion, prefix, name)
+		}
+		if parents.Len() == 0 {
+			continue
+		}
+		related, err := e.load(ctx, parents)
+		if err != nil {
+			return nil, err
+		}
+		embedded, err := r.related(ctx, related, tree[name], prefix+name+".")
+		if err != nil {
+			return nil, err
+		}
+		for j, position := range positions {
+			objects[position].(map[string]interface{})[name] = embedded[j]
+		}
+	}
+	return objects, nil
+}
+
+// related converts the values loaded for each parent to JSON, expanding them as a single batch
+// A parent's value is a slice of models (to-many) or a model, possibly a nil pointer (to-one)
+func (r *Registry) related(ctx context.Context, related reflect.Value, tree pathTree, prefix string) ([]interface{}, error) {
+	valueType := related.Type().Elem()
+	toMany := valueType.Kind() == reflect.Slice || valueType.Kind() == reflect.Array
+	childType := valueType
+	if toMany {
+		childType = valueType.Elem()
+	}
+
+	// Every related model of every parent, flattened so the next level loads them at once
+	children := reflect.MakeSlice(reflect.SliceOf(childType), 0, related.Len())
+	counts := make([]int, related.Len())
+	for i := 0; i < related.Len(); i++ {
+		value := related.Index(i)
+		switch {
+		case toMany:
+			for j := 0; j < value.Len(); j++ {
+				children = reflect.Append(children, value.Index(j))
+			}
+			counts[i] = value.Len()
+			if value.Kind() == reflect.Slice && value.IsNil() {
+				counts[i] = -1
+			}
+		case (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && value.IsNil():
+			counts[i] = -1
+		default:
+			children = reflect.Append(children, value)
+			counts[i] = 1
+		}
+	}
+	objects, err := r.expand(ctx, children, tree, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	embedded := make([]interface{}, related.Len())
+	next := 0
+	for i, count := range counts {
+		switch {
+		case count < 0 && toMany:
+			embedded[i] = []interface{}{}
+		case count < 0:
+			embedded[i] = nil
+		case toMany:
+			embedded[i] = objects[next : next+count]
+			next += count
+		default:
+			embedded[i] = objects[next]
+			next++
+		}
+	}
+	return embedded, nil
+}
+
+// toJSON serializes a model and decodes it into generic JSON values, keeping numbers exact
+func toJSON(ctx context.Context, model interface{}) (interface{}, error) {
+	serialized, err := models.Serialize(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(serialized)
+	if err != nil {
+		return nil, fmt.Errorf("expand: encoding %T: %w", model, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("expand: decoding %T: %w", model, err)
+	}
+	return value, nil
+}
+
+// modelType returns the type expanders are registered for, without pointers
+func modelType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
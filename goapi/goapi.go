@@ -2,21 +2,29 @@
 package goapi
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"log"
 	"net/http"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"github.com/swaggo/swag"
 
+	"github.com/esteban-ll-aguilar/goapi/goapi/cache"
 	"github.com/esteban-ll-aguilar/goapi/goapi/core"
 	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
 	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
 	"github.com/esteban-ll-aguilar/goapi/goapi/router"
 	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
 )
@@ -32,8 +40,48 @@ type APIConfig struct {
 	Contact     Contact
 	License     License
 	Debug       bool
+
+	// BasePathMode selects whether routes include BasePath (BasePathStrip, the default: the
+	// specification lists them relative to it) or are mounted under it (BasePathApply)
+	BasePathMode BasePathMode
+
+	// IndexTemplate replaces the index page served on "/"; it is executed with a core.IndexData
+	// holding the configuration, the endpoints and their groups by tag (nil = default page)
+	IndexTemplate *template.Template
+	// IndexTheme sets the colors and logo of the default index page
+	IndexTheme IndexTheme
+	// DisableIndex stops serving the index page, so an application route can use "/"
+	DisableIndex bool
+	// DisableIndexConsole removes the "try it" forms the index page shows for each endpoint
+	DisableIndexConsole bool
+
+	// ValidationStatusCode is the status of validation failures across the framework
+	// (0 = 400 for handlers and 422 for request validation; use 422 for FastAPI behaviour)
+	ValidationStatusCode int
+	// ValidationErrorFormat renders the body of validation failures
+	// (nil = responses.DefaultValidationFormat; see responses.FastAPIValidationFormat)
+	ValidationErrorFormat responses.ValidationErrorFormat
+	// JSON selects the indentation and HTML escaping of the responses (nil = indented, with the
+	// ?pretty=false toggle, in debug mode and compact in production)
+	JSON *responses.JSONConfig
+	// StrictJSON rejects JSON bodies with properties that match no field of the declared request
+	// body, to catch client typos; routes override it with WithStrictJSON
+	StrictJSON bool
+
+	// Server configures the timeouts, header limit and h2c of the server started by Run
+	Server ServerConfig
+
+	// RequestIDHeader is the header carrying request IDs (default X-Request-ID)
+	RequestIDHeader string
+
+	// SecurityHeaders configures the security headers of every response
+	// (nil = middleware.DefaultSecurityHeadersConfig; routes can adjust them with WithSecurityHeaders)
+	SecurityHeaders *middleware.SecurityHeadersConfig
 }
 
+// IndexTheme customizes the colors and logo of the index page (see core.Theme)
+type IndexTheme = core.Theme
+
 // Contact contains contact information for the API
 type Contact struct {
 	Name  string
@@ -67,19 +115,52 @@ func DefaultConfig() APIConfig {
 			Name: "MIT",
 			URL:  "https://opensource.org/licenses/MIT",
 		},
+		Server: DefaultServerConfig(),
 	}
 }
 
 // GoAPI is the main structure that encapsulates all API functionality
 // It provides a FastAPI-like interface for building REST APIs in Go
 type GoAPI struct {
-	config       APIConfig                         // API configuration settings
-	router       *gin.Engine                       // Underlying Gin router instance
-	routes       []router.Route                    // Collection of registered API routes
-	endpoints    map[string]interface{}            // Map of endpoint configurations
-	dependencies *dependencies.DependencyContainer // Dependency injection container
-	validator    *validation.Validator             // Request validation handler
-	middlewares  []gin.HandlerFunc                 // Collection of registered middlewares
+	config        APIConfig                         // API configuration settings
+	engine        atomic.Pointer[gin.Engine]        // Gin engine serving requests, swapped when routes change at runtime
+	routes        []router.Route                    // Collection of registered API routes
+	endpoints     map[string]interface{}            // Map of endpoint configurations
+	dependencies  *dependencies.DependencyContainer // Dependency injection container
+	validator     *validation.Validator             // Request validation handler
+	middlewares   []gin.HandlerFunc                 // Global middlewares, replayed on every rebuilt engine
+	tags          []openapi.Tag                     // Documented tags, in registration order
+	tagLocales    map[string]map[string]string      // Localized tag descriptions (tag -> locale -> description)
+	schemaTypes   map[reflect.Type]*openapi.Schema  // Schemas of custom types (RegisterSchemaType)
+	documentHooks []DocumentHook                    // Hooks that customize the generated OpenAPI document
+	specConfigs   map[string]SpecConfig             // Configuration of the specifications (DefineSpec)
+	webhooks      []router.Route                    // Events sent to consumers (DocumentWebhook)
+	plugins       []Plugin                          // Installed plugins
+	startupHooks  []LifecycleHook                   // Hooks executed before serving requests
+	shutdownHooks []LifecycleHook                   // Hooks executed on shutdown
+	reportHooks   []ShutdownReportHook              // Hooks receiving the shutdown report
+	errorHooks    []ErrorHook                       // Hooks receiving panics and 5xx responses (OnError)
+	errorMutex    sync.RWMutex                      // Protects the errorHooks field
+	jobs          backgroundJobs                    // Background tasks started with Go
+	inFlight      atomic.Int64                      // Requests being served (in-process calls excluded)
+	connections   atomic.Int64                      // Open client connections of the servers
+	servers       []*http.Server                    // Servers started by the Run methods, stopped by Shutdown
+	serverMutex   sync.Mutex                        // Protects the servers field
+
+	requestValidation  bool                                 // Validate requests against the declared route schemas
+	responseValidation *middleware.ResponseValidationConfig // Check responses against declared models (debug only)
+
+	rateLimiters map[string]*middleware.RateLimiter // Route limiters by method, path and limits (WithRateLimit), and AddRateLimit ones
+
+	notFoundHandler         gin.HandlerFunc // Handles requests to unknown routes
+	methodNotAllowedHandler gin.HandlerFunc // Handles requests with a method the route does not register
+
+	spec    atomic.Pointer[specSnapshot] // Generated OpenAPI documents served by /openapi.json
+	mounted atomic.Pointer[routeLookup]  // Per-request data of the mounted routes (CurrentRoute, streaming, CORS)
+
+	routesMutex   sync.Mutex // Serializes route changes, engine rebuilds and spec generation
+	routesMounted bool       // SetupRoutes was called; later route changes rebuild the engine
+	mock          bool       // Routes answer documented examples instead of calling handlers (RunMock)
 }
 
 // New creates and initializes a new GoAPI instance with the provided configuration
@@ -97,20 +178,30 @@ func New(configuration APIConfig) *GoAPI {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Create new Gin router instance
-	ginRouterInstance := gin.New()
-
 	// Initialize GoAPI instance with all components
 	apiInstance := &GoAPI{
 		config:       configuration,
-		router:       ginRouterInstance,
 		routes:       make([]router.Route, 0),
 		endpoints:    make(map[string]interface{}),
 		dependencies: dependencies.NewDependencyContainer(),
 		validator:    validation.NewValidator(),
 		middlewares:  make([]gin.HandlerFunc, 0),
+		tagLocales:   make(map[string]map[string]string),
+
+		notFoundHandler:         defaultNotFoundHandler,
+		methodNotAllowedHandler: defaultMethodNotAllowedHandler,
 	}
 
+	apiInstance.engine.Store(apiInstance.newEngine())
+
+	// Share the validator through the DI container
+	apiInstance.dependencies.RegisterSingleton(func(c *gin.Context) (interface{}, error) {
+		return apiInstance.validator, nil
+	}, (*validation.Validator)(nil))
+
+	// The user authenticated by the JWT or session middlewares
+	apiInstance.dependencies.Register(dependencies.CurrentUserProvider(), (*dependencies.CurrentUser)(nil))
+
 	// Setup default middleware stack
 	apiInstance.setupDefaultMiddleware()
 
@@ -136,7 +227,59 @@ func (apiInstance *GoAPI) AddRoute(method, path string, handler gin.HandlerFunc,
 		routeOption(&newRoute)
 	}
 
-	apiInstance.routes = append(apiInstance.routes, newRoute)
+	apiInstance.routesMutex.Lock()
+	defer apiInstance.routesMutex.Unlock()
+
+	previousRoutes := apiInstance.routes
+	apiInstance.routes = append(slices.Clip(apiInstance.routes), newRoute)
+
+	// Routes added while serving are mounted by swapping in a rebuilt engine
+	if apiInstance.routesMounted {
+		if err := apiInstance.rebuildEngine(); err != nil {
+			apiInstance.routes = previousRoutes
+			log.Printf("[GoAPI] route %s %s not registered: %v", method, path, err)
+		}
+	}
+}
+
+// Errors returned by route changes
+var (
+	// ErrRouteNotFound is returned when removing a route that is not registered
+	ErrRouteNotFound = errors.New("route not found")
+	// ErrRouteConflict is returned when a route added at runtime conflicts with a registered one
+	ErrRouteConflict = errors.New("route conflicts with a registered route")
+)
+
+// RemoveRoute unregisters a route while the API is running (or before SetupRoutes)
+// The OpenAPI document is regenerated without it
+func (apiInstance *GoAPI) RemoveRoute(method, path string) error {
+	apiInstance.routesMutex.Lock()
+	defer apiInstance.routesMutex.Unlock()
+
+	index := slices.IndexFunc(apiInstance.routes, func(route router.Route) bool {
+		return route.Method == method && route.Path == path
+	})
+	if index < 0 {
+		return fmt.Errorf("%w: %s %s", ErrRouteNotFound, method, path)
+	}
+
+	previousRoutes := apiInstance.routes
+	apiInstance.routes = slices.Delete(slices.Clone(apiInstance.routes), index, index+1)
+
+	if apiInstance.routesMounted {
+		if err := apiInstance.rebuildEngine(); err != nil {
+			apiInstance.routes = previousRoutes
+			return err
+		}
+	}
+	return nil
+}
+
+// Routes returns a copy of the registered routes
+func (apiInstance *GoAPI) Routes() []router.Route {
+	apiInstance.routesMutex.Lock()
+	defer apiInstance.routesMutex.Unlock()
+	return slices.Clone(apiInstance.routes)
 }
 
 // WithTags adds tags to a route for API documentation grouping
@@ -157,6 +300,138 @@ func WithDescription(description string) router.RouteOption {
 	return router.WithDescription(description)
 }
 
+// WithLocalizedSummary adds a localized summary to a route for API documentation
+// It is served when the specification is requested with ?lang=<locale>
+func WithLocalizedSummary(locale, summary string) router.RouteOption {
+	return router.WithLocalizedSummary(locale, summary)
+}
+
+// WithLocalizedDescription adds a localized description to a route for API documentation
+// It is served when the specification is requested with ?lang=<locale>
+func WithLocalizedDescription(locale, description string) router.RouteOption {
+	return router.WithLocalizedDescription(locale, description)
+}
+
+// WithOperationID sets the operationId of a route in the specification
+// Client code generators use it to name the generated method, so it must be unique
+func WithOperationID(operationID string) router.RouteOption {
+	return router.WithOperationID(operationID)
+}
+
+// WithExternalDocs links a route to external documentation in the specification
+func WithExternalDocs(url, description string) router.RouteOption {
+	return router.WithExternalDocs(url, description)
+}
+
+// WithErrorCodes declares the registered error codes a route can return
+// They are documented as enumerated error responses and, in debug mode, returning any other code is logged
+func WithErrorCodes(codes ...string) router.RouteOption {
+	return router.WithErrorCodes(codes...)
+}
+
+// WithRateLimit limits the requests to a route per client: burst requests at once, refilled at
+// requestsPerSecond. The optional key identifies clients (middleware.KeyByUser...; default the
+// client IP) and runs after the route middlewares, so they can authenticate first
+func WithRateLimit(requestsPerSecond float64, burst int, key ...func(c *gin.Context) string) router.RouteOption {
+	return router.WithRateLimit(requestsPerSecond, burst, key...)
+}
+
+// WithCoalescing lets identical concurrent GET requests to a route share one execution of the
+// handler, so a burst of clients asking for the same resource runs the backend query once
+// The optional key identifies identical requests (default middleware.CoalesceKey: path, query,
+// credentials and content negotiation headers); it runs after the route middlewares and request
+// validation, right before the handler
+func WithCoalescing(key ...func(c *gin.Context) string) router.RouteOption {
+	return router.WithCoalescing(key...)
+}
+
+// WithCache caches the responses of a GET route in memory with stale-while-revalidate semantics
+// Fresh responses are served with X-Cache: HIT; stale ones with X-Cache: STALE while the request
+// is sent again to the API in the background to refresh them. Invalidate them with
+// cache.Invalidate(path) or, for tagged policies, cache.InvalidateTag(tag)
+func WithCache(policy cache.Policy) router.RouteOption {
+	return router.WithCache(policy.Middleware)
+}
+
+// WithStreaming marks a route as streaming, so middlewares leave the request body unread
+// Request validation still checks parameters but not the body; see middleware.IsStreaming
+func WithStreaming() router.RouteOption {
+	return router.WithStreaming()
+}
+
+// WithAuthRequired requires credentials on a route even when the authentication middleware lets
+// anonymous requests through (JWTConfig.Optional); see router.WithAuthRequired
+func WithAuthRequired() router.RouteOption {
+	return router.WithAuthRequired()
+}
+
+// WithAllowAnonymous keeps a route public when authentication is enabled for the whole API
+// (health checks, login); see router.WithAllowAnonymous
+func WithAllowAnonymous() router.RouteOption {
+	return router.WithAllowAnonymous()
+}
+
+// AddTag documents a tag in the top-level "tags" section of the specification
+// Tags are listed in registration order; registering a tag again replaces its metadata
+func (apiInstance *GoAPI) AddTag(name, description string, externalDocs ...openapi.ExternalDocs) {
+	tag := openapi.Tag{Name: name, Description: description}
+	if len(externalDocs) > 0 {
+		docs := externalDocs[0]
+		tag.ExternalDocs = &docs
+	}
+
+	apiInstance.routesMutex.Lock()
+	defer apiInstance.routesMutex.Unlock()
+	defer apiInstance.tagsChanged()
+
+	for i := range apiInstance.tags {
+		if apiInstance.tags[i].Name == name {
+			apiInstance.tags[i] = tag
+			return
+		}
+	}
+	apiInstance.tags = append(apiInstance.tags, tag)
+}
+
+// AddTagTranslation registers the description of a tag for a specific locale
+// Tag descriptions are emitted in the top-level "tags" section of the localized specification
+func (apiInstance *GoAPI) AddTagTranslation(tag, locale, description string) {
+	apiInstance.routesMutex.Lock()
+	defer apiInstance.routesMutex.Unlock()
+
+	if apiInstance.tagLocales[tag] == nil {
+		apiInstance.tagLocales[tag] = make(map[string]string)
+	}
+	apiInstance.tagLocales[tag][router.NormalizeLocale(locale)] = description
+	apiInstance.tagsChanged()
+}
+
+// tagsChanged regenerates the served specification after a tag change while serving; the caller
+// holds routesMutex
+func (apiInstance *GoAPI) tagsChanged() {
+	if apiInstance.routesMounted {
+		apiInstance.refreshSpec()
+	}
+}
+
+// WithResponse documents a possible response of a route
+// Declared responses replace the generic 200 response in the specification
+func WithResponse(statusCode int, description string) router.RouteOption {
+	return router.WithResponse(statusCode, description)
+}
+
+// WithRequestExample adds a named example of the request body to the documentation
+// Swagger UI shows the first one; every example is listed in the x-examples extension
+func WithRequestExample(name string, value interface{}) router.RouteOption {
+	return router.WithRequestExample(name, value)
+}
+
+// WithResponseExample adds a named example of the response body for a status code
+// The first example of a status is its response example; every one is listed in x-examples
+func WithResponseExample(statusCode int, name string, value interface{}) router.RouteOption {
+	return router.WithResponseExample(statusCode, name, value)
+}
+
 // WithPathParameter adds a path parameter configuration to a route
 // Path parameters are part of the URL path (e.g., /users/{id})
 func WithPathParameter(name, paramType, description string) router.RouteOption {
@@ -175,6 +450,12 @@ func WithParameter(name, in, paramType, description string, required bool) route
 	return router.WithParameter(name, in, paramType, description, required)
 }
 
+// WithNullableQueryParameter adds an optional query parameter that also accepts the literal "null"
+// Read it with middleware.NullableQuery to distinguish not provided, null and a value
+func WithNullableQueryParameter(name, paramType, description string) router.RouteOption {
+	return router.WithNullableQueryParameter(name, paramType, description)
+}
+
 // WithRequestBody adds a request body schema configuration to a route
 // This defines the expected structure and format of the request payload
 func WithRequestBody(schema interface{}, description string) router.RouteOption {
@@ -187,6 +468,56 @@ func WithJSONSchema(example interface{}, description string) router.RouteOption
 	return router.WithJSONSchema(example, description)
 }
 
+// WithMiddleware adds middlewares that run only for this route, before its handler
+func WithMiddleware(middlewares ...gin.HandlerFunc) router.RouteOption {
+	return router.WithMiddleware(middlewares...)
+}
+
+// WithResponseSigning signs the responses of a single route with a Content-Digest header
+// and, when the config has a Signer, a detached JWS
+func WithResponseSigning(config middleware.ResponseSigningConfig) router.RouteOption {
+	return router.WithMiddleware(middleware.ResponseSigning(config))
+}
+
+// WithCORS gives a route its own CORS policy, replacing the global one for its requests and preflights
+// Apply it to a group with Group(prefix).WithOptions(goapi.WithCORS(config))
+func WithCORS(config middleware.CORSConfig) router.RouteOption {
+	return router.WithCORS(middleware.RouteCORS(config))
+}
+
+// WithSecurityHeaders adjusts the security headers of a single route, starting from the global ones
+// (e.g. a stricter CSP, or allowing a page to be embedded in an iframe)
+func WithSecurityHeaders(override func(config *middleware.SecurityHeadersConfig)) router.RouteOption {
+	return router.WithMiddleware(middleware.SecurityHeadersOverride(override))
+}
+
+// WithRequestValidation enables or disables request validation for a single route
+func WithRequestValidation(enabled bool) router.RouteOption {
+	return router.WithRequestValidation(enabled)
+}
+
+// EnableRequestValidation validates every request against the schemas declared on its route
+// (query, path and header parameters and the request body) and rejects mismatches with 422
+// before the handler runs; routes can opt out with WithRequestValidation(false)
+func (apiInstance *GoAPI) EnableRequestValidation() {
+	apiInstance.requestValidation = true
+}
+
+// WithResponseModel declares the model of the response body for a status code
+func WithResponseModel(statusCode int, model interface{}, description string) router.RouteOption {
+	return router.WithResponseModel(statusCode, model, description)
+}
+
+// EnableResponseValidation checks handler responses against the models declared with WithResponseModel
+// It only takes effect in debug mode: mismatches are logged and, with FailOnMismatch, replaced by a 500
+func (apiInstance *GoAPI) EnableResponseValidation(config ...middleware.ResponseValidationConfig) {
+	validationConfig := middleware.ResponseValidationConfig{}
+	if len(config) > 0 {
+		validationConfig = config[0]
+	}
+	apiInstance.responseValidation = &validationConfig
+}
+
 // GET registers a new GET route with the specified path and handler
 // GET routes are typically used for retrieving data without side effects
 func (apiInstance *GoAPI) GET(path string, handler gin.HandlerFunc, opts ...router.RouteOption) {
@@ -217,6 +548,26 @@ func (apiInstance *GoAPI) PATCH(path string, handler gin.HandlerFunc, opts ...ro
 	apiInstance.AddRoute(http.MethodPatch, path, handler, opts...)
 }
 
+// HEAD registers a new HEAD route with the specified path and handler
+// GET routes already answer HEAD automatically; use it only for a dedicated handler
+func (apiInstance *GoAPI) HEAD(path string, handler gin.HandlerFunc, opts ...router.RouteOption) {
+	apiInstance.AddRoute(http.MethodHead, path, handler, opts...)
+}
+
+// OPTIONS registers a new OPTIONS route with the specified path and handler
+// Every path already answers OPTIONS automatically with its Allow header; use it only for a dedicated handler
+func (apiInstance *GoAPI) OPTIONS(path string, handler gin.HandlerFunc, opts ...router.RouteOption) {
+	apiInstance.AddRoute(http.MethodOptions, path, handler, opts...)
+}
+
+// Any registers the handler for every method of router.AnyMethods
+// Each method is documented as its own operation; WithOperationID gets the method as suffix
+func (apiInstance *GoAPI) Any(path string, handler gin.HandlerFunc, opts ...router.RouteOption) {
+	for _, method := range router.AnyMethods {
+		apiInstance.AddRoute(method, path, handler, router.AnyMethodOptions(method, opts)...)
+	}
+}
+
 // Group creates a new route group with the specified path prefix
 // Route groups allow for organizing related routes and applying common middleware
 func (apiInstance *GoAPI) Group(path string) *router.RouterGroup {
@@ -225,524 +576,569 @@ func (apiInstance *GoAPI) Group(path string) *router.RouterGroup {
 
 // SetupRoutes configures and registers all defined routes with the underlying router
 // This method should be called before starting the server to ensure all routes are available
+// Routes added or removed afterwards are applied immediately by rebuilding the router
 func (apiInstance *GoAPI) SetupRoutes() {
-	// Configure API documentation routes
-	apiInstance.setupDocs()
+	apiInstance.routesMutex.Lock()
+	defer apiInstance.routesMutex.Unlock()
 
-	// Register all defined API routes with the Gin router
-	for _, currentRoute := range apiInstance.routes {
-		apiInstance.router.Handle(currentRoute.Method, currentRoute.Path, currentRoute.Handler)
+	if apiInstance.routesMounted {
+		return
 	}
+	apiInstance.mountRoutes(apiInstance.engine.Load())
+	apiInstance.routesMounted = true
 }
 
-// setupDocs configures documentation routes
-func (a *GoAPI) setupDocs() {
-	// Generar documentaciรณn automรกticamente basรกndose en las rutas
-	a.generateSwaggerSpec()
-
-	// Escribir el archivo swagger.json dinรกmicamente ANTES del wildcard
-	a.writeSwaggerFile()
-
-	// Main route in FastAPI style
-	a.router.GET("/", core.IndexHandler(a.config, a.routes))
+// mountRoutes registers the documentation and every defined route on an engine
+func (apiInstance *GoAPI) mountRoutes(engine *gin.Engine) {
+	// Configure API documentation routes
+	apiInstance.setupDocs(engine)
 
-	// Documentation routes
-	a.router.GET("/docs", func(c *gin.Context) {
-		c.Redirect(http.StatusMovedPermanently, "/swagger/index.html")
-	})
+	// Register all defined API routes with the Gin router
+	lookup := make(routeLookup)
+	global := apiInstance.globalMiddlewareNames()
+	var document *openapi.Document
+	if apiInstance.mock {
+		document = apiInstance.buildDocument("")
+	}
+	getHandlers := make(map[string][]gin.HandlerFunc) // HEAD implícito: la misma cadena que GET
+	for _, currentRoute := range apiInstance.routes {
+		path := apiInstance.mountPath(currentRoute.Path)
+		var handlers []gin.HandlerFunc
+		if apiInstance.mock {
+			handlers = apiInstance.mockHandlers(currentRoute, document)
+		} else {
+			handlers = apiInstance.routeHandlers(currentRoute)
+		}
+		engine.Handle(currentRoute.Method, path, handlers...)
+		if currentRoute.Method == http.MethodGet {
+			getHandlers[path] = handlers
+		}
 
-	a.router.GET("/redoc", func(c *gin.Context) {
-		c.Redirect(http.StatusMovedPermanently, "/redoc/index.html")
-	})
+		info := apiInstance.routeInfo(currentRoute, handlers, global)
+		lookup.add(currentRoute.Method, path, &mountedRoute{
+			info:       &info,
+			streaming:  currentRoute.Streaming,
+			strictJSON: apiInstance.strictJSON(currentRoute),
+			cors:       currentRoute.CORS,
+			auth:       currentRoute.Auth,
+		})
+	}
+	apiInstance.mounted.Store(&lookup)
+	apiInstance.warnOutsideBasePath()
+
+	// En modo debug se publica la tabla de rutas, salvo que la aplicación use esa ruta
+	if apiInstance.config.Debug && !slices.ContainsFunc(apiInstance.routes, func(route router.Route) bool {
+		return route.Method == http.MethodGet && route.Path == DebugRoutesPath
+	}) {
+		engine.Match(documentationMethods, DebugRoutesPath, apiInstance.serveRouteTable)
+	}
 
-	// Servir archivos estรกticos de documentaciรณn
-	a.router.Static("/docs-static", "./goapi/docs")
+	// Answer HEAD and OPTIONS on paths that do not register them
+	apiInstance.setupImplicitMethods(engine, getHandlers)
+}
 
-	// Swagger documentation con URL personalizada
-	a.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler,
-		ginSwagger.URL("/openapi.json")))
+// newEngine creates a Gin engine with the error handlers and the global middlewares of the API
+func (apiInstance *GoAPI) newEngine() *gin.Engine {
+	engine := gin.New()
+	apiInstance.setupErrorHandlers(engine)
+	engine.Use(apiInstance.markRoute)
+	engine.Use(apiInstance.middlewares...)
+	return engine
+}
 
-	// ReDoc documentation
-	a.router.GET("/redoc/index.html", core.RedocHandler())
+// markRoute attaches the metadata of the matched route, flags requests to streaming routes and
+// attaches the CORS policy and the authentication mode of their route before any global
+// middleware runs
+func (apiInstance *GoAPI) markRoute(c *gin.Context) {
+	lookup := apiInstance.mounted.Load()
+	if lookup == nil {
+		c.Next()
+		return
+	}
+	// HEAD usa la ruta GET cuando no registra la suya
+	if route := lookup.find(c.Request.Method, c.FullPath()); route != nil {
+		c.Set(RouteInfoKey, route.info)
+		if route.streaming {
+			c.Set(middleware.StreamingKey, true)
+		}
+		if route.strictJSON {
+			c.Set(middleware.StrictJSONKey, true)
+		}
+		if route.auth != router.AuthDefault {
+			c.Set(middleware.AuthModeKey, route.auth)
+		}
+	} else if c.FullPath() != "" {
+		// Las rutas del framework (documentación, tabla de rutas, OPTIONS implícitos) son públicas;
+		// los middlewares de la especificación siguen protegiendo la documentación
+		c.Set(middleware.AuthModeKey, router.AuthAnonymous)
+	}
+	// Los preflights usan la política del método que anuncian
+	method := c.Request.Method
+	if middleware.IsPreflightRequest(c.Request) {
+		method = c.Request.Header.Get("Access-Control-Request-Method")
+	}
+	if route := lookup.find(method, c.FullPath()); route != nil && route.cors != nil {
+		c.Set(middleware.RouteCORSKey, route.cors)
+	}
+	c.Next()
 }
 
-// writeSwaggerFile escribe el archivo swagger.json dinรกmicamente
-func (a *GoAPI) writeSwaggerFile() {
-	// Generar el contenido del archivo swagger.json
-	swaggerContent := a.getSwaggerJSON()
+// rebuildEngine mounts the current routes on a new engine and swaps it in
+// In-flight requests finish on the previous engine; the caller holds routesMutex
+func (apiInstance *GoAPI) rebuildEngine() (err error) {
+	engine := apiInstance.newEngine()
 
-	// Servir dinรกmicamente en una ruta que no conflicte con el wildcard
-	a.router.GET("/openapi.json", func(c *gin.Context) {
-		c.Header("Content-Type", "application/json")
-		c.String(http.StatusOK, swaggerContent)
-	})
+	// Gin panics on conflicting routes; report them instead of crashing the server
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w: %v", ErrRouteConflict, recovered)
+		}
+	}()
+	apiInstance.mountRoutes(engine)
+
+	apiInstance.engine.Store(engine)
+	return nil
 }
 
-// generateSwaggerSpec genera automรกticamente la especificaciรณn Swagger
-func (a *GoAPI) generateSwaggerSpec() {
-	// Crear la especificaciรณn Swagger dinรกmicamente
-	spec := &swag.Spec{
-		Version:          a.config.Version,
-		Host:             a.config.Host,
-		BasePath:         a.config.BasePath,
-		Schemes:          a.config.Schemes,
-		Title:            a.config.Title,
-		Description:      a.config.Description,
-		InfoInstanceName: "swagger",
-		SwaggerTemplate:  a.getSwaggerJSON(),
-		LeftDelim:        "",
-		RightDelim:       "",
+// ServeHTTP implements http.Handler with the current engine
+func (apiInstance *GoAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Las llamadas en proceso ya cuentan en la petición que las origina
+	if r.Context().Value(invokeDepthKey{}) == nil {
+		apiInstance.inFlight.Add(1)
+		defer apiInstance.inFlight.Add(-1)
 	}
-
-	// Registrar la especificaciรณn
-	swag.Register(spec.InstanceName(), spec)
+	apiInstance.engine.Load().ServeHTTP(w, r)
 }
 
-// generateSwaggerTemplate genera el template de Swagger basรกndose en las rutas
-func (a *GoAPI) generateSwaggerTemplate() string {
-	paths := make(map[string]interface{})
-
-	// Generar paths basรกndose en las rutas registradas
-	for _, route := range a.routes {
-		if route.Path == "/" || route.Path == "/docs" || route.Path == "/redoc" ||
-			route.Path == "/swagger/*any" || route.Path == "/redoc/index.html" {
-			continue // Skip documentation routes
+// setupImplicitMethods registers HEAD for GET routes, with the chain mounted for GET (the mock in
+// mock mode), and OPTIONS for every path
+// Implicit routes are not part of the documentation. Methods restricted with WithListeners are
+// left out of the Allow headers of the other listeners
+func (apiInstance *GoAPI) setupImplicitMethods(engine *gin.Engine, getHandlers map[string][]gin.HandlerFunc) {
+	methodsByPath := make(map[string]map[string]bool)
+	listeners := make(listenerTable)
+	restricted := false
+	var paths []string
+	for _, currentRoute := range apiInstance.routes {
+		path := apiInstance.mountPath(currentRoute.Path)
+		if methodsByPath[path] == nil {
+			methodsByPath[path] = make(map[string]bool)
+			paths = append(paths, path)
 		}
+		methodsByPath[path][currentRoute.Method] = true
+		listeners.add(currentRoute.Method, path, currentRoute.Listeners)
+		restricted = restricted || len(currentRoute.Listeners) > 0
+	}
 
-		pathItem := make(map[string]interface{})
-		operation := map[string]interface{}{
-			"summary":     route.Summary,
-			"description": route.Description,
-			"tags":        route.Tags,
-			"parameters":  a.getRouteParameters(route),
-			"responses": map[string]interface{}{
-				"200": map[string]interface{}{
-					"description": "Successful response",
-					"schema": map[string]interface{}{
-						"type": "object",
-					},
-				},
-			},
+	for _, path := range paths {
+		if handlers, found := getHandlers[path]; found && !methodsByPath[path][http.MethodHead] {
+			engine.Handle(http.MethodHead, path, handlers...)
 		}
+	}
 
-		if route.Summary == "" {
-			operation["summary"] = "API endpoint"
+	for _, path := range paths {
+		methods := methodsByPath[path]
+		if methods[http.MethodOptions] {
+			continue
 		}
-		if route.Description == "" {
-			operation["description"] = "API endpoint description"
+		allow := strings.Join(allowedMethods(methods), ", ")
+		engine.OPTIONS(path, func(c *gin.Context) {
+			if restricted {
+				visible := listeners.visibleMethods(methods, path, ListenerName(c))
+				if len(visible) == 0 {
+					apiInstance.notFoundHandler(c)
+					return
+				}
+				c.Header("Allow", strings.Join(allowedMethods(visible), ", "))
+			} else {
+				c.Header("Allow", allow)
+			}
+			c.Status(http.StatusNoContent)
+		})
+	}
+
+	// El 405 de gin lista los métodos de todos los listeners
+	if restricted {
+		engine.NoMethod(apiInstance.listenerMethodNotAllowed(listeners))
+	}
+}
+
+// allowedMethods lists the methods answered on a path, including the implicit HEAD and OPTIONS
+func allowedMethods(methods map[string]bool) []string {
+	var allowed []string
+	for _, method := range router.AnyMethods {
+		switch {
+		case methods[method],
+			method == http.MethodHead && methods[http.MethodGet],
+			method == http.MethodOptions:
+			allowed = append(allowed, method)
 		}
-		if len(route.Tags) == 0 {
-			operation["tags"] = []string{"default"}
+	}
+	for method := range methods {
+		if !slices.Contains(router.AnyMethods, method) {
+			allowed = append(allowed, method)
 		}
+	}
+	return allowed
+}
 
-		methodLower := strings.ToLower(route.Method)
-		pathItem[methodLower] = operation
-		paths[route.Path] = pathItem
-	}
-
-	// Crear el template completo
-	template := map[string]interface{}{
-		"swagger": "2.0",
-		"info": map[string]interface{}{
-			"title":       "{{.Title}}",
-			"description": "{{escape .Description}}",
-			"version":     "{{.Version}}",
-			"contact": map[string]interface{}{
-				"name":  a.config.Contact.Name,
-				"url":   a.config.Contact.URL,
-				"email": a.config.Contact.Email,
-			},
-			"license": map[string]interface{}{
-				"name": a.config.License.Name,
-				"url":  a.config.License.URL,
-			},
-		},
-		"host":     "{{.Host}}",
-		"basePath": "{{.BasePath}}",
-		"schemes":  []string{"{{range .Schemes}}{{.}}{{end}}"},
-		"paths":    paths,
+// routeHandlers builds the handler chain of a route: listener guard, response validation, error code
+// verification, route middlewares, request validation, caching, coalescing and the handler
+func (apiInstance *GoAPI) routeHandlers(route router.Route) []gin.HandlerFunc {
+	handlers := make([]gin.HandlerFunc, 0, len(route.Middlewares)+4)
+
+	// Las rutas limitadas a ciertos listeners no existen en los demás
+	if len(route.Listeners) > 0 {
+		handlers = append(handlers, apiInstance.listenerGuard(route.Listeners))
+	}
+
+	// La validación de respuestas envuelve toda la cadena de la ruta
+	if apiInstance.config.Debug && apiInstance.responseValidation != nil && len(route.ResponseModels) > 0 {
+		handlers = append(handlers, middleware.ResponseValidation(route, apiInstance.validator, *apiInstance.responseValidation))
 	}
 
-	// Convertir a JSON string
-	templateBytes, _ := json.MarshalIndent(template, "", "  ")
-	return string(templateBytes)
+	// Los códigos de error declarados se verifican en modo debug
+	if apiInstance.config.Debug && len(route.ErrorCodes) > 0 {
+		handlers = append(handlers, middleware.ErrorCodeVerification(route, nil))
+	}
+
+	handlers = append(handlers, route.Middlewares...)
+
+	if route.RateLimit != nil {
+		handlers = append(handlers, apiInstance.routeRateLimiter(route).Middleware())
+	}
+
+	if apiInstance.validatesRequest(route) {
+		handlers = append(handlers, middleware.RequestValidation(route, apiInstance.validator))
+	}
+
+	if route.Cache != nil {
+		handlers = append(handlers, route.Cache(apiInstance))
+	}
+
+	// Las peticiones que no encuentran respuesta en caché comparten la ejecución del handler
+	if route.Coalesce != nil {
+		handlers = append(handlers, middleware.Coalesce(middleware.CoalesceConfig{Key: route.Coalesce.Key}))
+	}
+
+	return append(handlers, route.Handler)
 }
 
-// getSwaggerJSON devuelve el JSON de Swagger
-func (a *GoAPI) getSwaggerJSON() string {
-	paths := make(map[string]interface{})
+// routeRateLimiter returns the limiter of a route, kept across engine rebuilds so buckets survive
+// route changes; HEAD requests share the budget of their GET route. The caller holds routesMutex
+func (apiInstance *GoAPI) routeRateLimiter(route router.Route) *middleware.RateLimiter {
+	// Los límites forman parte de la clave: una ruta redefinida con otros límites empieza de cero
+	key := fmt.Sprintf("%s %s %g/%d", route.Method, route.Path, route.RateLimit.RequestsPerSecond, route.RateLimit.Burst)
+	if limiter, exists := apiInstance.rateLimiters[key]; exists {
+		return limiter
+	}
+	limiter := middleware.NewRateLimiter(middleware.RateLimitConfig{
+		RequestsPerSecond: route.RateLimit.RequestsPerSecond,
+		BurstSize:         route.RateLimit.Burst,
+		Key:               route.RateLimit.Key,
+	})
+	if apiInstance.rateLimiters == nil {
+		apiInstance.rateLimiters = make(map[string]*middleware.RateLimiter)
+	}
+	apiInstance.rateLimiters[key] = limiter
+	return limiter
+}
 
-	// Generar paths basรกndose en las rutas registradas
-	for _, route := range a.routes {
-		if route.Path == "/" || route.Path == "/docs" || route.Path == "/redoc" ||
-			route.Path == "/swagger/*any" || route.Path == "/redoc/index.html" ||
-			route.Path == "/openapi.json" || route.Path == "/docs-static/*filepath" {
-			continue // Skip documentation routes
-		}
+// validatesRequest reports whether request validation applies to a route
+func (apiInstance *GoAPI) validatesRequest(route router.Route) bool {
+	if route.ValidateRequest != nil {
+		return *route.ValidateRequest
+	}
+	return apiInstance.requestValidation
+}
 
-		// Convertir ruta de Gin (:id) a formato OpenAPI ({id})
-		openAPIPath := a.convertToOpenAPIPath(route.Path)
+// jsonConfig returns the JSON configuration of the responses
+func (a *GoAPI) jsonConfig() responses.JSONConfig {
+	if a.config.JSON != nil {
+		return *a.config.JSON
+	}
+	return responses.JSONConfig{Pretty: a.config.Debug, PrettyQuery: a.config.Debug}
+}
 
-		// Obtener o crear el pathItem para esta ruta
-		pathItem, exists := paths[openAPIPath]
-		if !exists {
-			pathItem = make(map[string]interface{})
-		}
+// strictJSON reports whether unknown JSON properties are rejected on a route
+func (apiInstance *GoAPI) strictJSON(route router.Route) bool {
+	if route.StrictJSON != nil {
+		return *route.StrictJSON
+	}
+	return apiInstance.config.StrictJSON
+}
 
-		operation := map[string]interface{}{
-			"summary":     route.Summary,
-			"description": route.Description,
-			"tags":        route.Tags,
-			"parameters":  a.getRouteParameters(route),
-			"responses": map[string]interface{}{
-				"200": map[string]interface{}{
-					"description": "Successful response",
-					"schema": map[string]interface{}{
-						"type": "object",
-					},
-				},
-			},
-		}
+// setupDocs configures documentation routes
+func (a *GoAPI) setupDocs(engine *gin.Engine) {
+	// Generar la documentación una sola vez; se sirve cacheada hasta el próximo cambio
+	a.refreshSpec()
 
-		if route.Summary == "" {
-			operation["summary"] = "API endpoint"
-		}
-		if route.Description == "" {
-			operation["description"] = "API endpoint description"
-		}
-		if len(route.Tags) == 0 {
-			operation["tags"] = []string{"default"}
-		}
+	// Los middlewares de la especificación principal protegen toda su documentación
+	protected := func(handler gin.HandlerFunc) []gin.HandlerFunc {
+		return append(slices.Clip(a.specConfigs[""].Middlewares), handler)
+	}
 
-		methodLower := strings.ToLower(route.Method)
-		pathItem.(map[string]interface{})[methodLower] = operation
-		paths[openAPIPath] = pathItem
-	}
-
-	// Crear la especificaciรณn completa
-	spec := map[string]interface{}{
-		"swagger": "2.0",
-		"info": map[string]interface{}{
-			"title":       a.config.Title,
-			"description": a.config.Description,
-			"version":     a.config.Version,
-			"contact": map[string]interface{}{
-				"name":  a.config.Contact.Name,
-				"url":   a.config.Contact.URL,
-				"email": a.config.Contact.Email,
-			},
-			"license": map[string]interface{}{
-				"name": a.config.License.Name,
-				"url":  a.config.License.URL,
-			},
-		},
-		"host":     a.config.Host,
-		"basePath": a.config.BasePath,
-		"schemes":  a.config.Schemes,
-		"paths":    paths,
+	// Servir openapi.json ANTES del wildcard
+	engine.Match(documentationMethods, "/openapi.json", protected(a.serveSpec)...)
+
+	// Documentation of the named specifications (WithSpec)
+	specs := a.setupSpecDocs(engine)
+
+	// Main route in FastAPI style, listing the paths the routes of the main specification are served on
+	if !a.config.DisableIndex {
+		var consoles map[string]*core.Console
+		if !a.config.DisableIndexConsole {
+			consoles = a.indexConsoles()
+		}
+		engine.Match(documentationMethods, "/", protected(core.IndexPageHandler(core.IndexPage{
+			Title:       a.config.Title,
+			Description: a.config.Description,
+			Version:     a.config.Version,
+			BasePath:    a.basePath(),
+			Config:      a.config,
+			Theme:       a.config.IndexTheme,
+			Template:    a.config.IndexTemplate,
+			Routes:      a.specRoutes(a.servedRoutes()),
+			Consoles:    consoles,
+			Specs:       specs,
+		}))...)
 	}
 
-	// Convertir a JSON string
-	specBytes, _ := json.MarshalIndent(spec, "", "  ")
-	return string(specBytes)
+	// Documentation routes
+	engine.Match(documentationMethods, "/docs", protected(func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, "/swagger/index.html")
+	})...)
+
+	engine.Match(documentationMethods, "/redoc", protected(func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, "/redoc/index.html")
+	})...)
+
+	// Servir archivos estáticos de documentación
+	engine.Static("/docs-static", "./goapi/docs")
+
+	// Swagger documentation con URL personalizada
+	engine.Match(documentationMethods, "/swagger/*any", protected(headAsGet(ginSwagger.WrapHandler(swaggerFiles.Handler,
+		ginSwagger.URL("/openapi.json"))))...)
+
+	// ReDoc documentation
+	engine.Match(documentationMethods, "/redoc/index.html", protected(core.RedocHandler())...)
+}
+
+// documentationMethods are the methods answered by the documentation routes; HEAD is implicit
+// as on the GET routes of the API
+var documentationMethods = []string{http.MethodGet, http.MethodHead}
+
+// headAsGet runs a handler that only accepts GET on HEAD requests too; the server drops the body
+// of HEAD responses by itself
+func headAsGet(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead {
+			original := c.Request
+			c.Request = original.WithContext(original.Context())
+			c.Request.Method = http.MethodGet
+			defer func() { c.Request = original }()
+		}
+		handler(c)
+	}
 }
 
 // Run runs the server on the specified port
+// Startup hooks are executed before listening, and the call returns nil after a graceful Shutdown
 func (a *GoAPI) Run(addr ...string) error {
-	// Configure routes
-	a.SetupRoutes()
-
-	// Show server information
 	serverAddr := ":8080"
 	if len(addr) > 0 {
 		serverAddr = addr[0]
 	}
 
-	log.Println("Server started at http://localhost" + serverAddr)
-	log.Println("Documentation available at:")
-	log.Println("- Swagger UI: http://localhost" + serverAddr + "/docs")
-	log.Println("- ReDoc: http://localhost" + serverAddr + "/redoc")
-
 	// Ejecutar servidor
-	return a.router.Run(addr...)
+	server := a.newServer(serverAddr)
+	return a.serve(server, "http", server.ListenAndServe)
 }
 
 // setupDefaultMiddleware configura middleware por defecto
 func (a *GoAPI) setupDefaultMiddleware() {
-	// Recovery middleware
-	a.router.Use(middleware.Recovery())
+	// Una sola respuesta por petición: los middlewares de error no escriben sobre la del handler
+	a.use(middleware.ResponseGuard())
+
+	// Recovery middleware; en modo debug la respuesta describe el pánico
+	if a.config.Debug {
+		a.use(middleware.DebugRecovery(a.reportError))
+	} else {
+		a.use(middleware.Recovery(a.reportError))
+	}
 
 	// Request logger
 	if a.config.Debug {
-		a.router.Use(middleware.RequestLogger())
+		a.use(middleware.RequestLogger())
 	}
 
 	// Error handler
-	a.router.Use(middleware.ErrorHandler())
+	a.use(middleware.ErrorHandler(a.reportError))
 
 	// Security headers
-	a.router.Use(middleware.SecurityHeaders())
+	if a.config.SecurityHeaders != nil {
+		a.use(middleware.SecurityHeaders(*a.config.SecurityHeaders))
+	} else {
+		a.use(middleware.SecurityHeaders())
+	}
 
 	// Request ID
-	a.router.Use(middleware.RequestID())
+	a.use(middleware.RequestID(middleware.RequestIDConfig{Header: a.config.RequestIDHeader}))
 
-	// CORS con configuraciรณn por defecto
-	a.router.Use(middleware.CORS())
-}
+	// Validador compartido, accesible con validation.FromContext
+	a.use(validation.Middleware(a.validator))
 
-// AddMiddleware agrega middleware personalizado
-func (a *GoAPI) AddMiddleware(middlewareFunc gin.HandlerFunc) {
-	a.middlewares = append(a.middlewares, middlewareFunc)
-	a.router.Use(middlewareFunc)
-}
+	// Código de estado y formato de los errores de validación
+	a.use(responses.ValidationConfigMiddleware(responses.ValidationConfig{
+		StatusCode: a.config.ValidationStatusCode,
+		Format:     a.config.ValidationErrorFormat,
+	}))
 
-// AddCORS configura CORS con configuraciรณn personalizada
-func (a *GoAPI) AddCORS(config middleware.CORSConfig) {
-	a.router.Use(middleware.CORS(config))
-}
+	// Formato de las respuestas JSON
+	a.use(responses.JSONConfigMiddleware(a.jsonConfig()))
 
-// AddRateLimit agrega rate limiting
-func (a *GoAPI) AddRateLimit(config middleware.RateLimitConfig) {
-	a.router.Use(middleware.RateLimit(config))
+	// CORS con configuración por defecto
+	a.use(middleware.CORS())
 }
 
-// AddAuthentication agrega autenticaciรณn
-func (a *GoAPI) AddAuthentication(secretKey string) {
-	a.router.Use(middleware.Authentication(secretKey))
-}
+// use registra un middleware global en el engine actual y en los que se reconstruyan
+func (a *GoAPI) use(middlewares ...gin.HandlerFunc) {
+	a.routesMutex.Lock()
+	defer a.routesMutex.Unlock()
 
-// RegisterDependency registra una dependencia
-func (a *GoAPI) RegisterDependency(provider dependencies.DependencyProvider, target interface{}) {
-	a.dependencies.Register(provider, target)
+	a.middlewares = append(a.middlewares, middlewares...)
+	if a.routesMounted {
+		// Las rutas ya montadas no ven los middlewares nuevos: reconstruir el engine
+		if err := a.rebuildEngine(); err != nil {
+			log.Printf("[GoAPI] error applying middleware: %v", err)
+		}
+		return
+	}
+	a.engine.Load().Use(middlewares...)
 }
 
-// RegisterSingletonDependency registra una dependencia singleton
-func (a *GoAPI) RegisterSingletonDependency(provider dependencies.DependencyProvider, target interface{}) {
-	a.dependencies.RegisterSingleton(provider, target)
-}
+// setupErrorHandlers configura las respuestas 404 y 405 de un engine
+// Los handlers se resuelven en cada petición, así que pueden cambiarse después de New
+func (a *GoAPI) setupErrorHandlers(engine *gin.Engine) {
+	// Gin responde 405 con el header Allow generado a partir de las rutas registradas
+	engine.HandleMethodNotAllowed = true
 
-// GetDependencyContainer devuelve el contenedor de dependencias
-func (a *GoAPI) GetDependencyContainer() *dependencies.DependencyContainer {
-	return a.dependencies
+	engine.NoRoute(func(c *gin.Context) {
+		a.notFoundHandler(c)
+	})
+	engine.NoMethod(func(c *gin.Context) {
+		a.methodNotAllowedHandler(c)
+	})
 }
 
-// GetValidator devuelve el validador
-func (a *GoAPI) GetValidator() *validation.Validator {
-	return a.validator
+// defaultNotFoundHandler responde {"detail": "Not Found", "type": "not_found"}
+func defaultNotFoundHandler(c *gin.Context) {
+	responses.NotFound(c, i18n.M(i18n.KeyNotFound))
 }
 
-// getRouteParameters obtiene los parรกmetros de una ruta, priorizando los configurados por el usuario
-func (a *GoAPI) getRouteParameters(route router.Route) []map[string]interface{} {
-	var parameters []map[string]interface{}
-
-	// Primero, usar parรกmetros configurados por el usuario
-	for _, param := range route.Parameters {
-		parameter := map[string]interface{}{
-			"name":        param.Name,
-			"in":          param.In,
-			"required":    param.Required,
-			"description": param.Description,
-		}
-
-		// Manejar parรกmetros de body con schema
-		if param.In == "body" && param.Schema != nil {
-			parameter["schema"] = a.generateSchemaFromStruct(param.Schema)
-		} else {
-			parameter["type"] = param.Type
-			if param.Format != "" {
-				parameter["format"] = param.Format
-			}
-		}
-
-		parameters = append(parameters, parameter)
-	}
+// defaultMethodNotAllowedHandler responde {"detail": "Method Not Allowed", "type": "method_not_allowed"}
+func defaultMethodNotAllowedHandler(c *gin.Context) {
+	responses.MethodNotAllowed(c, i18n.M(i18n.KeyMethodNotAllowed))
+}
 
-	// Si no hay parรกmetros configurados, usar detecciรณn automรกtica para parรกmetros de ruta
-	if len(route.Parameters) == 0 {
-		parameters = a.extractParameters(route.Path)
+// SetNotFoundHandler define el handler de las rutas inexistentes (404)
+// Por defecto responde {"detail": "Not Found", "type": "not_found"}
+func (a *GoAPI) SetNotFoundHandler(handler gin.HandlerFunc) {
+	if handler == nil {
+		handler = defaultNotFoundHandler
 	}
-
-	return parameters
+	a.notFoundHandler = handler
 }
 
-// convertToOpenAPIPath convierte rutas de Gin (:id) a formato OpenAPI ({id})
-func (a *GoAPI) convertToOpenAPIPath(path string) string {
-	// Reemplazar :param con {param}
-	segments := strings.Split(path, "/")
-
-	for i, segment := range segments {
-		if strings.HasPrefix(segment, ":") {
-			paramName := segment[1:] // Remover el ":"
-			segments[i] = "{" + paramName + "}"
-		}
+// SetMethodNotAllowedHandler define el handler de los métodos no registrados en una ruta existente (405)
+// El header Allow ya contiene los métodos permitidos cuando se ejecuta el handler
+func (a *GoAPI) SetMethodNotAllowedHandler(handler gin.HandlerFunc) {
+	if handler == nil {
+		handler = defaultMethodNotAllowedHandler
 	}
-
-	return strings.Join(segments, "/")
+	a.methodNotAllowedHandler = handler
 }
 
-// extractParameters extrae parรกmetros de una ruta para la especificaciรณn OpenAPI
-func (a *GoAPI) extractParameters(path string) []map[string]interface{} {
-	var parameters []map[string]interface{}
-
-	// Dividir la ruta en segmentos
-	segments := strings.Split(path, "/")
-
-	for _, segment := range segments {
-		// Buscar parรกmetros de ruta (formato :param)
-		if strings.HasPrefix(segment, ":") {
-			paramName := segment[1:] // Remover el ":"
-
-			parameter := map[string]interface{}{
-				"name":        paramName,
-				"in":          "path",
-				"required":    true,
-				"type":        "string",
-				"description": fmt.Sprintf("ID del %s", paramName),
-			}
+// AddMiddleware agrega middleware personalizado
+func (a *GoAPI) AddMiddleware(middlewareFunc gin.HandlerFunc) {
+	a.use(middlewareFunc)
+}
 
-			// Personalizar descripciรณn segรบn el nombre del parรกmetro
-			switch paramName {
-			case "id":
-				parameter["description"] = "ID del recurso"
-				parameter["type"] = "integer"
-				parameter["format"] = "int64"
-			case "userId", "user_id":
-				parameter["description"] = "ID del usuario"
-				parameter["type"] = "integer"
-				parameter["format"] = "int64"
-			default:
-				parameter["description"] = fmt.Sprintf("Parรกmetro %s", paramName)
-			}
+// AddCORS configura CORS con configuración personalizada
+func (a *GoAPI) AddCORS(config middleware.CORSConfig) {
+	a.use(middleware.CORS(config))
+}
 
-			parameters = append(parameters, parameter)
-		}
+// AddRateLimit agrega rate limiting
+func (a *GoAPI) AddRateLimit(config middleware.RateLimitConfig) {
+	limiter := middleware.NewRateLimiter(config)
+	a.routesMutex.Lock()
+	if a.rateLimiters == nil {
+		a.rateLimiters = make(map[string]*middleware.RateLimiter)
 	}
-
-	return parameters
+	// Los límites globales se registran como "global", "global 2"...
+	name := "global"
+	for i := 2; a.rateLimiters[name] != nil; i++ {
+		name = fmt.Sprintf("global %d", i)
+	}
+	a.rateLimiters[name] = limiter
+	a.routesMutex.Unlock()
+	a.use(limiter.Middleware())
 }
 
-// generateSchemaFromStruct genera un schema OpenAPI desde un struct de Go
-func (a *GoAPI) generateSchemaFromStruct(example interface{}) map[string]interface{} {
-	schema := map[string]interface{}{
-		"type":       "object",
-		"properties": make(map[string]interface{}),
+// RateLimiters returns the limiters of the API, to monitor them: those of AddRateLimit, named
+// "global", and those of WithRateLimit, named by method, path and limits ("POST /search 2/5")
+func (a *GoAPI) RateLimiters() map[string]*middleware.RateLimiter {
+	a.routesMutex.Lock()
+	defer a.routesMutex.Unlock()
+	limiters := make(map[string]*middleware.RateLimiter, len(a.rateLimiters))
+	for name, limiter := range a.rateLimiters {
+		limiters[name] = limiter
 	}
+	return limiters
+}
 
-	// Si el ejemplo es directamente un valor, usarlo como ejemplo
-	if example != nil {
-		schema["example"] = example
-
-		// Usar reflection para generar propiedades del schema
-		v := reflect.ValueOf(example)
-		t := reflect.TypeOf(example)
-
-		// Si es un puntero, obtener el valor al que apunta
-		if v.Kind() == reflect.Ptr {
-			if !v.IsNil() {
-				v = v.Elem()
-				t = t.Elem()
-			}
-		}
-
-		// Solo procesar structs
-		if v.Kind() == reflect.Struct {
-			properties := make(map[string]interface{})
-			var required []string
-
-			for i := 0; i < v.NumField(); i++ {
-				field := t.Field(i)
-				fieldValue := v.Field(i)
-
-				// Obtener el nombre del campo JSON
-				jsonTag := field.Tag.Get("json")
-				fieldName := field.Name
-				if jsonTag != "" && jsonTag != "-" {
-					// Usar el nombre del tag JSON
-					parts := strings.Split(jsonTag, ",")
-					if parts[0] != "" {
-						fieldName = parts[0]
-					}
-
-					// Verificar si es omitempty
-					isOptional := false
-					for _, part := range parts[1:] {
-						if part == "omitempty" {
-							isOptional = true
-							break
-						}
-					}
-
-					if !isOptional {
-						required = append(required, fieldName)
-					}
-				} else {
-					// Si no hay tag JSON, el campo es requerido por defecto
-					required = append(required, fieldName)
-				}
-
-				// Generar el tipo del campo
-				fieldSchema := a.getFieldSchema(fieldValue, field)
-				properties[fieldName] = fieldSchema
-			}
+// AddMaxInFlight limita las peticiones simultáneas; las que no consiguen turno en queueTimeout reciben 503
+func (a *GoAPI) AddMaxInFlight(n int, queueTimeout time.Duration) {
+	a.use(middleware.MaxInFlight(n, queueTimeout))
+}
 
-			schema["properties"] = properties
-			if len(required) > 0 {
-				schema["required"] = required
-			}
-		}
-	}
+// AddConnectionLimit limita las conexiones websocket/SSE simultáneas (global y por usuario)
+// Devuelve el limitador para consultar sus métricas con Stats
+func (a *GoAPI) AddConnectionLimit(config middleware.ConnectionLimitConfig) *middleware.ConnectionLimiter {
+	limiter := middleware.NewConnectionLimiter(config)
+	a.use(limiter.Middleware())
+	return limiter
+}
 
-	return schema
+// AddAuthentication agrega autenticación
+func (a *GoAPI) AddAuthentication(secretKey string) {
+	a.use(middleware.Authentication(secretKey))
 }
 
-// getFieldSchema obtiene el schema de un campo especรญfico
-func (a *GoAPI) getFieldSchema(fieldValue reflect.Value, field reflect.StructField) map[string]interface{} {
-	fieldSchema := make(map[string]interface{})
+// RegisterDependency registra una dependencia
+func (a *GoAPI) RegisterDependency(provider dependencies.DependencyProvider, target interface{}) {
+	a.dependencies.Register(provider, target)
+}
 
-	// Obtener el ejemplo del tag
-	if example := field.Tag.Get("example"); example != "" {
-		fieldSchema["example"] = example
-	}
+// RegisterSingletonDependency registra una dependencia singleton
+func (a *GoAPI) RegisterSingletonDependency(provider dependencies.DependencyProvider, target interface{}) {
+	a.dependencies.RegisterSingleton(provider, target)
+}
 
-	// Determinar el tipo basรกndose en el tipo de Go
-	switch fieldValue.Kind() {
-	case reflect.String:
-		fieldSchema["type"] = "string"
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		fieldSchema["type"] = "integer"
-		fieldSchema["format"] = "int64"
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		fieldSchema["type"] = "integer"
-		fieldSchema["format"] = "int64"
-	case reflect.Float32, reflect.Float64:
-		fieldSchema["type"] = "number"
-		if fieldValue.Kind() == reflect.Float32 {
-			fieldSchema["format"] = "float"
-		} else {
-			fieldSchema["format"] = "double"
-		}
-	case reflect.Bool:
-		fieldSchema["type"] = "boolean"
-	case reflect.Slice, reflect.Array:
-		fieldSchema["type"] = "array"
-		// Para arrays/slices, podrรญamos analizar el tipo del elemento
-		fieldSchema["items"] = map[string]interface{}{
-			"type": "string", // Por defecto
-		}
-	case reflect.Ptr:
-		// Para punteros, analizar el tipo al que apuntan
-		if !fieldValue.IsNil() {
-			return a.getFieldSchema(fieldValue.Elem(), field)
-		}
-		fieldSchema["type"] = "string" // Por defecto para punteros nulos
-	default:
-		fieldSchema["type"] = "string" // Por defecto
-	}
+// GetDependencyContainer devuelve el contenedor de dependencias
+func (a *GoAPI) GetDependencyContainer() *dependencies.DependencyContainer {
+	return a.dependencies
+}
 
-	return fieldSchema
+// GetValidator devuelve el validador
+func (a *GoAPI) GetValidator() *validation.Validator {
+	return a.validator
 }
 
-// Router devuelve el router Gin subyacente
+// Router devuelve el router Gin que atiende las peticiones
+// Cambiar rutas después de SetupRoutes reemplaza el engine: las rutas, middlewares y opciones
+// configurados directamente en él no se conservan; usar AddRoute y AddMiddleware
 func (a *GoAPI) Router() *gin.Engine {
-	return a.router
+	return a.engine.Load()
 }
@@ -0,0 +1,66 @@
+// Package httpclient creates HTTP clients for outbound calls made from handlers, forwarding
+// the trace and correlation headers of the incoming request (see middleware.Correlation)
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+)
+
+// DefaultTimeout bounds the outbound calls of the clients created by New
+const DefaultTimeout = 30 * time.Second
+
+// New returns a client forwarding the propagated headers of the request being handled
+// Build requests with http.NewRequestWithContext(c.Request.Context(), ...) so calls are also
+// cancelled when the client of the incoming request goes away
+func New(c *gin.Context) *http.Client {
+	return &http.Client{
+		Timeout: DefaultTimeout,
+		Transport: &Transport{
+			Base:   http.DefaultTransport,
+			header: middleware.PropagationHeaders(c.Request.Context()),
+		},
+	}
+}
+
+// Transport adds the propagated headers to outbound requests
+// Used directly, for a client shared across requests, it reads them from the context of each
+// outbound request, which must derive from c.Request.Context()
+type Transport struct {
+	Base http.RoundTripper // Transport sending the requests (default http.DefaultTransport)
+
+	header http.Header // Headers bound by New; nil to read them from the request context
+}
+
+// NewTransport returns a transport reading the propagated headers from the request contexts
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper; headers already set on the request are kept
+func (t *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	header := t.header
+	if header == nil {
+		header = middleware.PropagationHeaders(request.Context())
+	}
+
+	if len(header) > 0 {
+		// A RoundTripper must not modify the request it receives
+		request = request.Clone(request.Context())
+		for name, values := range header {
+			if request.Header.Get(name) == "" {
+				request.Header[name] = values
+			}
+		}
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(request)
+}
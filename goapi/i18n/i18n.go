@@ -0,0 +1,349 @@
+// Package i18n provides the message catalogs of GoAPI responses and negotiates the locale
+// of each request from its Accept-Language header
+//
+// Framework messages ("Resource not found", "Rate limit exceeded"...) are keyed, so an API
+// can translate or reword them by registering a catalog, and handlers can send their own
+// keyed messages through the responses helpers:
+//
+//	i18n.Register("es", i18n.Catalog{"order.not_found": "El pedido {id} no existe"})
+//	i18n.Register("en", i18n.Catalog{"order.not_found": "Order {id} does not exist"})
+//
+//	responses.NotFound(c, i18n.M("order.not_found", i18n.Params{"id": id}))
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultLocale is the locale used when no supported locale is requested
+const DefaultLocale = "en"
+
+// LocaleKey is the context key holding the locale of a request, set by SetLocale
+const LocaleKey = "goapi.locale"
+
+// Keys of the messages sent by the framework
+const (
+	KeyNotFound               = "error.not_found"
+	KeyMethodNotAllowed       = "error.method_not_allowed"
+	KeyResourceNotFound       = "error.resource_not_found"
+	KeyInvalidData            = "error.invalid_data"
+	KeyInvalidRequest         = "error.invalid_request"
+	KeyAuthenticationRequired = "error.authentication_required"
+	KeyAuthorizationRequired  = "error.authorization_required"
+	KeyInvalidToken           = "error.invalid_token"
+	KeyForbidden              = "error.forbidden"
+	KeyRateLimitExceeded      = "error.rate_limit_exceeded"
+	KeyOverloaded             = "error.overloaded"
+	KeyConnectionLimit        = "error.connection_limit"
+	KeyTooManyConnections     = "error.too_many_connections"
+	KeyInternalError          = "error.internal"
+	KeyPayloadTooLarge        = "error.payload_too_large"
+	KeyUnsupportedEncoding    = "error.unsupported_encoding"
+	KeyInvalidEncoding        = "error.invalid_encoding"
+)
+
+// Keys of the messages of the auth plugin
+const (
+	KeyCredentialsRequired = "auth.credentials_required"
+	KeyInvalidCredentials  = "auth.invalid_credentials"
+	KeyInvalidGrantType    = "auth.invalid_grant_type"
+	KeyOTPRequired         = "auth.otp_required"
+	KeyInvalidOTP          = "auth.invalid_otp"
+	KeyInvalidRefreshToken = "auth.invalid_refresh_token"
+	KeyCredentialsError    = "auth.credentials_error"
+	KeyOTPError            = "auth.otp_error"
+	KeyTokenIssueError     = "auth.token_issue_error"
+	KeyTokenRefreshError   = "auth.token_refresh_error"
+	KeyTokenRevokeError    = "auth.token_revoke_error"
+)
+
+// Catalog maps a message key to a message template
+// Templates may use {name} placeholders, replaced by the Params of the message
+type Catalog map[string]string
+
+// Params are the values of the placeholders of a message
+type Params map[string]interface{}
+
+// English is the default catalog of framework messages
+var English = Catalog{
+	KeyNotFound:               "Not Found",
+	KeyMethodNotAllowed:       "Method Not Allowed",
+	KeyResourceNotFound:       "{resource} with id {id} not found",
+	KeyInvalidData:            "Invalid data format",
+	KeyInvalidRequest:         "Invalid request format",
+	KeyAuthenticationRequired: "Authentication required",
+	KeyAuthorizationRequired:  "Authorization header required",
+	KeyInvalidToken:           "Invalid token",
+	KeyForbidden:              "Forbidden",
+	KeyRateLimitExceeded:      "Rate limit exceeded",
+	KeyOverloaded:             "Server is overloaded, try again later",
+	KeyConnectionLimit:        "Server connection limit reached, please retry later",
+	KeyTooManyConnections:     "Too many open connections for this client",
+	KeyInternalError:          "Internal server error",
+	KeyPayloadTooLarge:        "Request body too large",
+	KeyUnsupportedEncoding:    "Unsupported Content-Encoding {encoding}",
+	KeyInvalidEncoding:        "Request body is not valid {encoding} data",
+
+	KeyCredentialsRequired: "username and password are required",
+	KeyInvalidCredentials:  "Invalid username or password",
+	KeyInvalidGrantType:    `grant_type must be "{grant_type}"`,
+	KeyOTPRequired:         "A one-time code is required",
+	KeyInvalidOTP:          "Invalid one-time code",
+	KeyInvalidRefreshToken: "Invalid refresh token",
+	KeyCredentialsError:    "Error checking credentials",
+	KeyOTPError:            "Error checking the one-time code",
+	KeyTokenIssueError:     "Error issuing tokens",
+	KeyTokenRefreshError:   "Error refreshing tokens",
+	KeyTokenRevokeError:    "Error revoking tokens",
+}
+
+// Spanish is the Spanish catalog of framework messages
+var Spanish = Catalog{
+	KeyNotFound:               "No encontrado",
+	KeyMethodNotAllowed:       "Método no permitido",
+	KeyResourceNotFound:       "{resource} con id {id} no encontrado",
+	KeyInvalidData:            "Formato de datos inválido",
+	KeyInvalidRequest:         "Formato de solicitud inválido",
+	KeyAuthenticationRequired: "Se requiere autenticación",
+	KeyAuthorizationRequired:  "Se requiere el encabezado Authorization",
+	KeyInvalidToken:           "Token inválido",
+	KeyForbidden:              "Acceso denegado",
+	KeyRateLimitExceeded:      "Límite de solicitudes excedido",
+	KeyOverloaded:             "El servidor está sobrecargado, intente más tarde",
+	KeyConnectionLimit:        "Se alcanzó el límite de conexiones del servidor, intente más tarde",
+	KeyTooManyConnections:     "Demasiadas conexiones abiertas para este cliente",
+	KeyInternalError:          "Error interno del servidor",
+	KeyPayloadTooLarge:        "El cuerpo de la solicitud es demasiado grande",
+	KeyUnsupportedEncoding:    "Content-Encoding {encoding} no soportado",
+	KeyInvalidEncoding:        "El cuerpo de la solicitud no es {encoding} válido",
+
+	KeyCredentialsRequired: "Se requieren username y password",
+	KeyInvalidCredentials:  "Usuario o contraseña inválidos",
+	KeyInvalidGrantType:    `grant_type debe ser "{grant_type}"`,
+	KeyOTPRequired:         "Se requiere un código de un solo uso",
+	KeyInvalidOTP:          "Código de un solo uso inválido",
+	KeyInvalidRefreshToken: "Refresh token inválido",
+	KeyCredentialsError:    "Error al verificar las credenciales",
+	KeyOTPError:            "Error al verificar el código de un solo uso",
+	KeyTokenIssueError:     "Error al emitir los tokens",
+	KeyTokenRefreshError:   "Error al renovar los tokens",
+	KeyTokenRevokeError:    "Error al revocar los tokens",
+}
+
+var (
+	catalogs = map[string]Catalog{
+		"en": copyCatalog(English),
+		"es": copyCatalog(Spanish),
+	}
+	catalogsMutex sync.RWMutex
+)
+
+// Register registers (or extends) the catalog of a locale
+// Existing messages are overwritten by the entries of the new catalog
+func Register(locale string, catalog Catalog) {
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+
+	locale = NormalizeLocale(locale)
+	if catalogs[locale] == nil {
+		catalogs[locale] = make(Catalog)
+	}
+	for key, message := range catalog {
+		catalogs[locale][key] = message
+	}
+}
+
+// SupportedLocales returns the locales with a registered catalog
+func SupportedLocales() []string {
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Lookup returns the template of a key for a locale, trying the base language and English
+func Lookup(locale, key string) (string, bool) {
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	for _, candidate := range Candidates(locale) {
+		if message, ok := catalogs[candidate][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Translate renders the message of a key for a locale
+// Unknown keys are returned as they are, so a plain text passed as a key is still readable
+func Translate(locale, key string, params ...Params) string {
+	template, found := Lookup(locale, key)
+	if !found {
+		template = key
+	}
+	for _, values := range params {
+		for name, value := range values {
+			template = strings.ReplaceAll(template, "{"+name+"}", fmt.Sprint(value))
+		}
+	}
+	return template
+}
+
+// Negotiate returns the best supported locale for an Accept-Language header value
+// Languages are ranked by their quality value; DefaultLocale is returned when none is supported
+func Negotiate(acceptLanguage string) string {
+	type weightedLocale struct {
+		locale  string
+		quality float64
+	}
+
+	var requested []weightedLocale
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		locale, parameters, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(parameters), "q="); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		requested = append(requested, weightedLocale{locale: NormalizeLocale(locale), quality: quality})
+	}
+	sort.SliceStable(requested, func(i, j int) bool {
+		return requested[i].quality > requested[j].quality
+	})
+
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+	for _, candidate := range requested {
+		if _, ok := catalogs[candidate.locale]; ok {
+			return candidate.locale
+		}
+		if base, _, found := strings.Cut(candidate.locale, "-"); found {
+			if _, ok := catalogs[base]; ok {
+				return base
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// LocaleFromRequest negotiates the locale of a request from its Accept-Language header
+func LocaleFromRequest(request *http.Request) string {
+	if request == nil {
+		return DefaultLocale
+	}
+	return Negotiate(request.Header.Get("Accept-Language"))
+}
+
+// SetLocale fixes the locale of a request, for APIs that take it from the user profile
+// or a query parameter instead of Accept-Language
+func SetLocale(c *gin.Context, locale string) {
+	c.Set(LocaleKey, NormalizeLocale(locale))
+}
+
+// Locale returns the locale of a request: the one set with SetLocale, or the negotiated one
+func Locale(c *gin.Context) string {
+	if c == nil {
+		return DefaultLocale
+	}
+	if locale := c.GetString(LocaleKey); locale != "" {
+		return locale
+	}
+	return LocaleFromRequest(c.Request)
+}
+
+// T renders the message of a key in the locale of a request
+func T(c *gin.Context, key string, params ...Params) string {
+	return Translate(Locale(c), key, params...)
+}
+
+// Message is a keyed message, rendered in the locale of the request it is sent in
+// The responses helpers and the middleware error bodies accept it as detail
+type Message struct {
+	Key    string
+	Params Params
+}
+
+// M creates a keyed message
+func M(key string, params ...Params) Message {
+	message := Message{Key: key}
+	for _, values := range params {
+		if message.Params == nil {
+			message.Params = make(Params, len(values))
+		}
+		for name, value := range values {
+			message.Params[name] = value
+		}
+	}
+	return message
+}
+
+// In renders the message for a locale
+func (m Message) In(locale string) string {
+	return Translate(locale, m.Key, m.Params)
+}
+
+// String renders the message in DefaultLocale
+func (m Message) String() string {
+	return m.In(DefaultLocale)
+}
+
+// Localize renders a detail in the locale of a request when it is a Message
+// Other values are returned unchanged
+func Localize(c *gin.Context, detail interface{}) interface{} {
+	switch message := detail.(type) {
+	case Message:
+		return message.In(Locale(c))
+	case *Message:
+		if message != nil {
+			return message.In(Locale(c))
+		}
+	}
+	return detail
+}
+
+// Candidates returns the locales to try for a requested locale, most specific first and
+// ending with DefaultLocale
+func Candidates(locale string) []string {
+	locale = NormalizeLocale(locale)
+	candidates := make([]string, 0, 3)
+	if locale != "" {
+		candidates = append(candidates, locale)
+		if base, _, found := strings.Cut(locale, "-"); found {
+			candidates = append(candidates, base)
+		}
+	}
+	return append(candidates, DefaultLocale)
+}
+
+// NormalizeLocale normalizes a locale identifier ("es_MX", "ES-mx" -> "es-mx")
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// copyCatalog returns a copy of a catalog so built-in catalogs are never mutated
+func copyCatalog(catalog Catalog) Catalog {
+	copied := make(Catalog, len(catalog))
+	for key, message := range catalog {
+		copied[key] = message
+	}
+	return copied
+}
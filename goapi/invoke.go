@@ -0,0 +1,211 @@
+package goapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxInvokeDepth limits nested in-process calls, so a route invoking itself fails instead of looping
+const MaxInvokeDepth = 8
+
+// ErrInvokeDepth is returned when in-process calls are nested deeper than MaxInvokeDepth
+var ErrInvokeDepth = errors.New("in-process call depth exceeded")
+
+// invokeDepthKey holds the nesting level of in-process calls in the request context
+type invokeDepthKey struct{}
+
+// InvokeOption configures an in-process call
+type InvokeOption func(*invocation) error
+
+// invocation is the request being built by Invoke
+type invocation struct {
+	header     http.Header
+	query      url.Values
+	body       io.Reader
+	remoteAddr string
+}
+
+// WithInvokeBody sets the request body: []byte, string and io.Reader are sent as is, other values as JSON
+func WithInvokeBody(body interface{}) InvokeOption {
+	return func(inv *invocation) error {
+		switch typed := body.(type) {
+		case []byte:
+			inv.body = bytes.NewReader(typed)
+		case string:
+			inv.body = strings.NewReader(typed)
+		case io.Reader:
+			inv.body = typed
+		default:
+			data, err := json.Marshal(body)
+			if err != nil {
+				return fmt.Errorf("error encoding invoke body: %w", err)
+			}
+			inv.body = bytes.NewReader(data)
+			if inv.header.Get("Content-Type") == "" {
+				inv.header.Set("Content-Type", "application/json")
+			}
+		}
+		return nil
+	}
+}
+
+// WithInvokeHeader sets a request header
+func WithInvokeHeader(name, value string) InvokeOption {
+	return func(inv *invocation) error {
+		inv.header.Set(name, value)
+		return nil
+	}
+}
+
+// WithInvokeQuery adds a query parameter
+func WithInvokeQuery(name, value string) InvokeOption {
+	return func(inv *invocation) error {
+		inv.query.Add(name, value)
+		return nil
+	}
+}
+
+// WithInvokeFrom forwards the identity of an incoming request: its Authorization, Cookie,
+// Accept-Language and X-Request-ID headers and its client address
+func WithInvokeFrom(c *gin.Context) InvokeOption {
+	return func(inv *invocation) error {
+		for _, name := range []string{"Authorization", "Cookie", "Accept-Language", "X-Request-ID"} {
+			if value := c.GetHeader(name); value != "" && inv.header.Get(name) == "" {
+				inv.header.Set(name, value)
+			}
+		}
+		inv.remoteAddr = c.Request.RemoteAddr
+		return nil
+	}
+}
+
+// InvokeResponse is the response of an in-process call
+type InvokeResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// OK reports whether the status code is 2xx
+func (r *InvokeResponse) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Decode unmarshals the JSON body into target
+func (r *InvokeResponse) Decode(target interface{}) error {
+	return json.Unmarshal(r.Body, target)
+}
+
+// Invoke dispatches a request through the middleware and handler chain of the API in-process,
+// without an HTTP round trip; useful for aggregation (BFF) endpoints composing other routes
+// Pass the context of the incoming request (c.Request.Context()) so nested calls are bounded
+// by MaxInvokeDepth. Non-2xx statuses are not errors: check StatusCode or OK
+func (a *GoAPI) Invoke(ctx context.Context, method, path string, opts ...InvokeOption) (*InvokeResponse, error) {
+	depth, _ := ctx.Value(invokeDepthKey{}).(int)
+	if depth >= MaxInvokeDepth {
+		return nil, fmt.Errorf("%w: %s %s", ErrInvokeDepth, method, path)
+	}
+
+	target, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid invoke path %q: %w", path, err)
+	}
+	inv := &invocation{header: make(http.Header), query: target.Query(), remoteAddr: "127.0.0.1:0"}
+	for _, opt := range opts {
+		if err := opt(inv); err != nil {
+			return nil, err
+		}
+	}
+	target.RawQuery = inv.query.Encode()
+
+	request, err := http.NewRequestWithContext(context.WithValue(ctx, invokeDepthKey{}, depth+1), method, target.String(), inv.body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header = inv.header
+	request.RemoteAddr = inv.remoteAddr
+	if request.Header.Get("Accept") == "" {
+		request.Header.Set("Accept", "application/json")
+	}
+
+	a.SetupRoutes()
+	recorder := &invokeRecorder{header: make(http.Header)}
+	a.ServeHTTP(recorder, request)
+
+	status := recorder.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &InvokeResponse{StatusCode: status, Header: recorder.header, Body: recorder.body.Bytes()}, nil
+}
+
+// InvokeJSON calls a route in-process and decodes its JSON response into T
+// Non-2xx responses are returned with an *InvokeError carrying the response
+func InvokeJSON[T any](ctx context.Context, api *GoAPI, method, path string, opts ...InvokeOption) (T, error) {
+	var result T
+	response, err := api.Invoke(ctx, method, path, opts...)
+	if err != nil {
+		return result, err
+	}
+	if !response.OK() {
+		return result, &InvokeError{Method: method, Path: path, Response: response}
+	}
+	if len(response.Body) == 0 {
+		return result, nil
+	}
+	if err := response.Decode(&result); err != nil {
+		return result, fmt.Errorf("error decoding response of %s %s: %w", method, path, err)
+	}
+	return result, nil
+}
+
+// InvokeError reports a non-2xx response of an in-process call
+type InvokeError struct {
+	Method   string
+	Path     string
+	Response *InvokeResponse
+}
+
+// Error implements error
+func (e *InvokeError) Error() string {
+	return fmt.Sprintf("%s %s returned %d", e.Method, e.Path, e.Response.StatusCode)
+}
+
+// invokeRecorder collects the response of an in-process call
+type invokeRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+// Header implements http.ResponseWriter
+func (r *invokeRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *invokeRecorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+}
+
+// Write implements http.ResponseWriter
+func (r *invokeRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(data)
+}
+
+// Flush implements http.Flusher; streamed responses are collected whole
+func (r *invokeRecorder) Flush() {}
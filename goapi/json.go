@@ -0,0 +1,107 @@
+package goapi
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// WithStrictJSON enables or disables the rejection of unknown JSON properties for a single route
+func WithStrictJSON(enabled bool) router.RouteOption {
+	return router.WithStrictJSON(enabled)
+}
+
+// BindJSON decodes the JSON body of the request into target and applies its validate tags
+// On strict routes (APIConfig.StrictJSON, WithStrictJSON) properties that match no field are
+// rejected, every one reported with its path. Errors are validation.ValidationErrors, ready for
+// responses.ValidationFailed
+//
+//	var order Order
+//	if err := goapi.BindJSON(c, &order); err != nil {
+//		responses.ValidationFailed(c, err)
+//		return
+//	}
+func BindJSON(c *gin.Context, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("BindJSON: target must be a non-nil pointer")
+	}
+	locale := i18n.Locale(c)
+
+	err := decodeBody(c, target)
+	var typeError *json.UnmarshalTypeError
+	var unknownFields *validation.UnknownFieldsError
+	switch {
+	case errors.As(err, &typeError):
+		return locateBody(validation.ValidationErrors{validation.FromUnmarshalTypeError(typeError, locale)})
+	case errors.As(err, &unknownFields):
+		return locateBody(unknownFields.ValidationErrors(locale))
+	case err != nil:
+		return validation.ValidationErrors{{
+			Field:   "body",
+			Tag:     validation.MessageBodyInvalid,
+			Message: validation.Message(locale, validation.MessageBodyInvalid, nil),
+			In:      "body",
+		}}
+	}
+	if value.Elem().Kind() == reflect.Struct {
+		if err := validation.FromContext(c).ValidateStruct(target); err != nil {
+			return validation.FormatValidationErrorsLocale(err, locale)
+		}
+	}
+	return nil
+}
+
+// DecodeJSONBody decodes the JSON body of the request into target like BindJSON, without the
+// validate tags, and answers the errors itself: 400 for an invalid body and the validation error
+// response for the properties rejected on strict routes. It reports whether the body was decoded
+//
+//	var request CreateRequest
+//	if !goapi.DecodeJSONBody(c, &request) {
+//		return
+//	}
+func DecodeJSONBody(c *gin.Context, target interface{}, opts ...responses.ValidationOption) bool {
+	err := decodeBody(c, target)
+	var unknownFields *validation.UnknownFieldsError
+	switch {
+	case errors.As(err, &unknownFields):
+		responses.ValidationFailed(c, locateBody(unknownFields.ValidationErrors(i18n.Locale(c))), opts...)
+		return false
+	case err != nil:
+		responses.BadRequest(c, i18n.M(i18n.KeyInvalidData))
+		return false
+	}
+	return true
+}
+
+// decodeBody decodes the JSON body of the request, strictly on strict routes; the body already
+// decoded by request validation is reused
+func decodeBody(c *gin.Context, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if validated := reflect.ValueOf(middleware.ValidatedBody(c)); validated.IsValid() && validated.Type() == value.Type() {
+		value.Elem().Set(validated.Elem())
+		return nil
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+	return validation.DecodeJSON(body, target, middleware.IsStrictJSON(c))
+}
+
+// locateBody marks validation errors as found in the request body
+func locateBody(validationErrors validation.ValidationErrors) validation.ValidationErrors {
+	for i := range validationErrors {
+		validationErrors[i].In = "body"
+	}
+	return validationErrors
+}
@@ -0,0 +1,408 @@
+// Package jsonrpc serves JSON-RPC 2.0 methods at a single POST endpoint
+// Methods are registered with typed parameters, bound from named (object) or positional (array)
+// params and validated with the validation package. Batches run their calls concurrently and
+// notifications get no response. The plugin documents every method in the OpenAPI document
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// Version is the protocol version of requests and responses
+const Version = "2.0"
+
+// Error codes defined by the specification; application errors use codes outside -32768..-32000
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error is the error object of a response
+// Handlers return it to answer with an application error; other errors become internal errors
+type Error struct {
+	Code    int         `json:"code" example:"-32602"`
+	Message string      `json:"message" example:"Invalid params"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (%d)", e.Message, e.Code)
+}
+
+// NewError creates an error with an application code and optional data
+func NewError(code int, message string, data ...interface{}) *Error {
+	err := &Error{Code: code, Message: message}
+	if len(data) > 0 {
+		err.Data = data[0]
+	}
+	return err
+}
+
+// Request is a call; requests without id are notifications
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// Response is the result or the error of a call
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Config configures a Server
+type Config struct {
+	MaxBatchSize int // Calls accepted in a batch (default 100)
+	Concurrency  int // Calls of a batch executed at the same time (default 5)
+}
+
+// DefaultConfig returns the default server settings
+func DefaultConfig() Config {
+	return Config{MaxBatchSize: 100, Concurrency: 5}
+}
+
+// Server is a registry of methods served over HTTP
+// It is safe for concurrent use; methods are usually registered at startup
+type Server struct {
+	config  Config
+	mutex   sync.RWMutex
+	methods map[string]*method
+}
+
+// method is a registered method and its documentation
+type method struct {
+	name        string
+	summary     string
+	description string
+	params      reflect.Type
+	result      reflect.Type
+	call        func(c *gin.Context, params json.RawMessage) (interface{}, error)
+}
+
+// MethodOption configures a registered method
+type MethodOption func(*method)
+
+// WithSummary sets the summary of a method in the documentation
+func WithSummary(summary string) MethodOption {
+	return func(m *method) {
+		m.summary = summary
+	}
+}
+
+// WithDescription sets the description of a method in the documentation
+func WithDescription(description string) MethodOption {
+	return func(m *method) {
+		m.description = description
+	}
+}
+
+// New creates a server; zero fields of the configuration take their default value
+func New(config ...Config) *Server {
+	cfg := DefaultConfig()
+	if len(config) > 0 {
+		if config[0].MaxBatchSize > 0 {
+			cfg.MaxBatchSize = config[0].MaxBatchSize
+		}
+		if config[0].Concurrency > 0 {
+			cfg.Concurrency = config[0].Concurrency
+		}
+	}
+	return &Server{config: cfg, methods: make(map[string]*method)}
+}
+
+// Register adds a method whose params bind to P and whose result R is encoded as JSON
+// Named params fill the fields of P by their json names; positional params fill them in
+// declaration order. Struct params are validated with the validator of the request, and failures
+// answer CodeInvalidParams with the validation errors as data. ctx is the *gin.Context of the
+// HTTP request, shared by the calls of a batch
+//
+//	jsonrpc.Register(server, "users.get", func(ctx context.Context, params GetUser) (User, error) {
+//		return users.Get(ctx, params.ID)
+//	}, jsonrpc.WithSummary("Get a user"))
+func Register[P, R any](server *Server, name string, handler func(ctx context.Context, params P) (R, error), options ...MethodOption) {
+	m := &method{
+		name:   name,
+		params: reflect.TypeOf((*P)(nil)).Elem(),
+		result: reflect.TypeOf((*R)(nil)).Elem(),
+		call: func(c *gin.Context, raw json.RawMessage) (interface{}, error) {
+			var params P
+			if err := bind(raw, &params); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+			}
+			if structType(reflect.TypeOf(params)) {
+				if err := validation.FromContext(c).ValidateStruct(params); err != nil {
+					return nil, &Error{Code: CodeInvalidParams, Message: "Invalid params",
+						Data: validation.FormatValidationErrorsLocale(err, i18n.Locale(c))}
+				}
+			}
+			return handler(c, params)
+		},
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.methods[name] = m
+}
+
+// Methods returns the names of the registered methods, sorted
+func (s *Server) Methods() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns a registered method
+func (s *Server) lookup(name string) (*method, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	m, found := s.methods[name]
+	return m, found
+}
+
+// Handler serves the requests POSTed to a route: a call or a batch of calls
+// Responses are sent with status 200, errors included; requests made only of notifications get 204
+func (s *Server) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := c.GetRawData()
+		if err != nil || !json.Valid(body) {
+			c.JSON(http.StatusOK, errorResponse(nil, CodeParseError, "Parse error"))
+			return
+		}
+
+		if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '[' {
+			response, reply := s.call(c, body)
+			if !reply {
+				c.Status(http.StatusNoContent)
+				return
+			}
+			c.JSON(http.StatusOK, response)
+			return
+		}
+
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil || len(batch) == 0 {
+			c.JSON(http.StatusOK, errorResponse(nil, CodeInvalidRequest, "Invalid Request"))
+			return
+		}
+		if len(batch) > s.config.MaxBatchSize {
+			c.JSON(http.StatusOK, errorResponse(nil, CodeInvalidRequest,
+				fmt.Sprintf("Invalid Request: a batch accepts at most %d calls", s.config.MaxBatchSize)))
+			return
+		}
+
+		responses := s.batch(c, batch)
+		if len(responses) == 0 {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.JSON(http.StatusOK, responses)
+	}
+}
+
+// batch runs the calls of a batch concurrently and returns the responses in request order,
+// without the notifications
+func (s *Server) batch(c *gin.Context, batch []json.RawMessage) []Response {
+	results := make([]*Response, len(batch))
+	semaphore := make(chan struct{}, s.config.Concurrency)
+	var wg sync.WaitGroup
+	for index, raw := range batch {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if response, reply := s.call(c, raw); reply {
+				results[index] = &response
+			}
+		}()
+	}
+	wg.Wait()
+
+	responses := make([]Response, 0, len(batch))
+	for _, response := range results {
+		if response != nil {
+			responses = append(responses, *response)
+		}
+	}
+	return responses
+}
+
+// call runs a single call and reports whether it gets a response
+func (s *Server) call(c *gin.Context, raw json.RawMessage) (Response, bool) {
+	var request Request
+	if err := json.Unmarshal(raw, &request); err != nil || request.JSONRPC != Version || request.Method == "" ||
+		!validID(request.ID) || !validParams(request.Params) {
+		var id json.RawMessage
+		if validID(request.ID) {
+			id = request.ID
+		}
+		return errorResponse(id, CodeInvalidRequest, "Invalid Request"), true
+	}
+	notification := len(request.ID) == 0
+
+	m, found := s.lookup(request.Method)
+	if !found {
+		return errorResponse(request.ID, CodeMethodNotFound, "Method not found"), !notification
+	}
+
+	result, err := invoke(c, m, request.Params)
+	if notification {
+		return Response{}, false
+	}
+	if err != nil {
+		response := Response{JSONRPC: Version, ID: request.ID, Error: toError(err)}
+		return response, true
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(request.ID, CodeInternalError, "Internal error"), true
+	}
+	return Response{JSONRPC: Version, Result: encoded, ID: request.ID}, true
+}
+
+// invoke calls a method, turning its panics into internal errors so one call cannot fail a batch
+func invoke(c *gin.Context, m *method, params json.RawMessage) (result interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic in %s: %v", m.name, recovered)
+		}
+	}()
+	return m.call(c, params)
+}
+
+// toError converts the error of a method to an error object
+// Validation errors answer CodeInvalidParams; the details of other errors are only sent in debug mode
+func toError(err error) *Error {
+	var rpcError *Error
+	if errors.As(err, &rpcError) {
+		return rpcError
+	}
+	var validationErrors validation.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return &Error{Code: CodeInvalidParams, Message: "Invalid params", Data: validationErrors}
+	}
+	internal := &Error{Code: CodeInternalError, Message: "Internal error"}
+	if gin.IsDebugging() {
+		internal.Data = err.Error()
+	}
+	return internal
+}
+
+// errorResponse builds an error response; a missing id is sent as null
+func errorResponse(id json.RawMessage, code int, message string) Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return Response{JSONRPC: Version, Error: &Error{Code: code, Message: message}, ID: id}
+}
+
+// validID reports whether an id is absent, a string, a number or null
+func validID(id json.RawMessage) bool {
+	if len(id) == 0 {
+		return true
+	}
+	switch id[0] {
+	case '"', 'n', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	}
+	return false
+}
+
+// validParams reports whether params are absent, an object or an array
+func validParams(params json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(params)
+	return len(trimmed) == 0 || trimmed[0] == '{' || trimmed[0] == '[' || string(trimmed) == "null"
+}
+
+// bind decodes params into target, a pointer; arrays fill the fields of structs in order
+func bind(params json.RawMessage, target interface{}) error {
+	trimmed := bytes.TrimSpace(params)
+	if len(trimmed) == 0 || string(trimmed) == "null" {
+		return nil
+	}
+	value := reflect.ValueOf(target).Elem()
+	if trimmed[0] != '[' || !structType(value.Type()) {
+		return json.Unmarshal(trimmed, target)
+	}
+
+	var positional []json.RawMessage
+	if err := json.Unmarshal(trimmed, &positional); err != nil {
+		return err
+	}
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	fields := positionalFields(value.Type())
+	if len(positional) > len(fields) {
+		return fmt.Errorf("expected at most %d params, got %d", len(fields), len(positional))
+	}
+	for i, raw := range positional {
+		field := value.FieldByIndex(fields[i].Index)
+		if err := json.Unmarshal(raw, field.Addr().Interface()); err != nil {
+			return fmt.Errorf("param %d (%s): %w", i, jsonName(fields[i]), err)
+		}
+	}
+	return nil
+}
+
+// positionalFields returns the fields filled by positional params: the exported fields encoded
+// in JSON, in declaration order
+func positionalFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// jsonName returns the name of a field in JSON
+func jsonName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// structType reports whether a type is a struct or a pointer to one
+func structType(t reflect.Type) bool {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t != nil && t.Kind() == reflect.Struct
+}
@@ -0,0 +1,150 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+)
+
+// Plugin serves the methods of a Server at POST Path and documents them
+// The operation lists every method, its params and result schemas are added to the definitions
+// as jsonrpc.<method>.params and jsonrpc.<method>.result, and the x-jsonrpc-methods extension
+// of the operation references them for tools
+type Plugin struct {
+	Server *Server
+	Path   string   // Path of the endpoint (default "/rpc")
+	Tags   []string // Documentation tags of the endpoint
+
+	api *goapi.GoAPI
+}
+
+// NewPlugin creates a plugin serving a server at POST /rpc
+func NewPlugin(server *Server) *Plugin {
+	return &Plugin{Server: server, Path: "/rpc", Tags: []string{"jsonrpc"}}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "jsonrpc"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	if p.Path == "" {
+		p.Path = "/rpc"
+	}
+	p.api = api
+	api.POST(p.Path, p.Server.Handler(),
+		goapi.WithSummary("JSON-RPC 2.0 endpoint"),
+		goapi.WithTags(p.Tags...),
+		goapi.WithResponse(http.StatusOK, "Response, or array of responses for a batch"),
+		goapi.WithResponse(http.StatusNoContent, "Only notifications were sent"),
+	)
+	// Los métodos registrados después de Install también se documentan
+	api.OnOpenAPIDocument(p.document)
+	return nil
+}
+
+// document describes the methods in the operation of the endpoint
+func (p *Plugin) document(document *openapi.Document) {
+	item := document.Paths[p.Path]
+	if item == nil || item.Post == nil {
+		return
+	}
+	operation := item.Post
+	if document.Definitions == nil {
+		document.Definitions = make(map[string]*openapi.Schema)
+	}
+
+	names := p.Server.Methods()
+	lines := []string{
+		"Calls the JSON-RPC 2.0 methods below. Send one request object, or an array of them as a batch. " +
+			"Requests without an id are notifications and get no response.",
+		"",
+		"Methods:",
+	}
+	methods := make([]interface{}, 0, len(names))
+	enum := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		m, found := p.Server.lookup(name)
+		if !found {
+			continue
+		}
+		params, result := "jsonrpc."+name+".params", "jsonrpc."+name+".result"
+		document.Definitions[params] = p.schemaOf(m.params)
+		document.Definitions[result] = p.schemaOf(m.result)
+
+		line := "- `" + name + "`"
+		if m.summary != "" {
+			line += ": " + m.summary
+		}
+		lines = append(lines, line)
+		enum = append(enum, name)
+		methods = append(methods, map[string]interface{}{
+			"name":        name,
+			"summary":     m.summary,
+			"description": m.description,
+			"params":      map[string]string{"$ref": "#/definitions/" + params},
+			"result":      map[string]string{"$ref": "#/definitions/" + result},
+		})
+	}
+	operation.Description = strings.Join(lines, "\n")
+	operation.Extensions.Set("x-jsonrpc-methods", methods)
+
+	errorSchema := p.api.SchemaOf(Error{})
+	operation.Parameters = append(operation.Parameters, openapi.Parameter{
+		Name:     "body",
+		In:       "body",
+		Required: true,
+		Schema: &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"jsonrpc": {Type: "string", Enum: []interface{}{Version}},
+				"method":  {Type: "string", Enum: enum},
+				"params":  {Type: "object", Description: "Named params, or an array of positional params"},
+				"id":      {Type: "string", Description: "String or number; omitted for notifications"},
+			},
+			Required: []string{"jsonrpc", "method"},
+		},
+	})
+	if response := operation.Responses["200"]; response != nil {
+		response.Schema = &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"jsonrpc": {Type: "string", Enum: []interface{}{Version}},
+				"result":  {Type: "object", Description: "Result of the method, absent on errors"},
+				"error":   errorSchema,
+				"id":      {Type: "string", Description: "id of the request, null when it could not be read"},
+			},
+			Required: []string{"jsonrpc", "id"},
+		}
+	}
+}
+
+// schemaOf documents the params or result type of a method
+func (p *Plugin) schemaOf(t reflect.Type) *openapi.Schema {
+	base := t
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	var schema *openapi.Schema
+	switch base.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array:
+		schema = p.api.SchemaOf(reflect.Zero(base).Interface())
+	case reflect.String:
+		schema = &openapi.Schema{Type: "string"}
+	case reflect.Bool:
+		schema = &openapi.Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema = &openapi.Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		schema = &openapi.Schema{Type: "number"}
+	default:
+		schema = &openapi.Schema{Type: "object"}
+	}
+	return schema
+}
@@ -0,0 +1,249 @@
+package goapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// Listener is one of the addresses served by RunListeners
+type Listener struct {
+	Name        string                            // Identifies the listener for WithListeners and ListenerName
+	Addr        string                            // TCP address (":8080") or Unix socket ("unix:/run/api.sock")
+	Listener    net.Listener                      // Already open listener, used instead of Addr
+	Middlewares []func(http.Handler) http.Handler // net/http middlewares applied only on this listener
+}
+
+// listenerNameKey holds the name of the listener that accepted a request in its context
+type listenerNameKey struct{}
+
+// ListenerName returns the name of the listener that accepted the request ("" for Run)
+func ListenerName(c *gin.Context) string {
+	name, _ := c.Request.Context().Value(listenerNameKey{}).(string)
+	return name
+}
+
+// WithListeners restricts a route to the named listeners of RunListeners (an admin port, for example)
+// On any other listener the route answers 404, as if it did not exist
+func WithListeners(names ...string) router.RouteOption {
+	return router.WithListeners(names...)
+}
+
+// RunListener serves the API on an already open listener (a Unix socket, a socket activated by systemd...)
+// The call returns nil after a graceful Shutdown
+func (a *GoAPI) RunListener(listener net.Listener) error {
+	server := a.newServer(listener.Addr().String())
+	return a.serve(server, schemeOf(listener), func() error {
+		return server.Serve(listener)
+	})
+}
+
+// RunListeners serves the API on several addresses at once, each with its own name and middlewares
+// Addresses are opened before the startup hooks run, so a busy port fails early; the call returns
+// when every listener has stopped, with the errors of those that failed
+func (a *GoAPI) RunListeners(listeners ...Listener) error {
+	if len(listeners) == 0 {
+		return errors.New("at least one listener is required")
+	}
+
+	opened := make([]net.Listener, 0, len(listeners))
+	for _, listener := range listeners {
+		netListener, err := openListener(listener)
+		if err != nil {
+			for _, previous := range opened {
+				_ = previous.Close()
+			}
+			return err
+		}
+		opened = append(opened, netListener)
+	}
+
+	a.SetupRoutes()
+	if err := a.Startup(context.Background()); err != nil {
+		for _, netListener := range opened {
+			_ = netListener.Close()
+		}
+		return err
+	}
+
+	errs := make([]error, len(listeners))
+	var wg sync.WaitGroup
+	for i, listener := range listeners {
+		server := a.newServer(opened[i].Addr().String())
+		server.Handler = wrapHandler(a, listener.Middlewares)
+		name := listener.Name
+		server.BaseContext = func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), listenerNameKey{}, name)
+		}
+		a.trackServer(server)
+
+		label := name
+		if label == "" {
+			label = "default"
+		}
+		log.Printf("Server listening on %s://%s (%s)", schemeOf(opened[i]), opened[i].Addr(), label)
+
+		wg.Add(1)
+		go func(i int, server *http.Server) {
+			defer wg.Done()
+			if err := server.Serve(opened[i]); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs[i] = fmt.Errorf("listener %s: %w", label, err)
+			}
+		}(i, server)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// openListener opens the address of a listener; stale Unix socket files are removed first
+func openListener(listener Listener) (net.Listener, error) {
+	if listener.Listener != nil {
+		return listener.Listener, nil
+	}
+	if path, found := strings.CutPrefix(listener.Addr, "unix:"); found {
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(path)
+		}
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", listener.Addr)
+}
+
+// wrapHandler applies net/http middlewares; the first one is the outermost
+func wrapHandler(handler http.Handler, middlewares []func(http.Handler) http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// schemeOf names the network of a listener for the startup logs
+func schemeOf(listener net.Listener) string {
+	if listener.Addr().Network() == "unix" {
+		return "unix"
+	}
+	return "http"
+}
+
+// listenerGuard answers 404 on listeners a route is not bound to
+func (a *GoAPI) listenerGuard(names []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(names, ListenerName(c)) {
+			a.notFoundHandler(c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// listenerTable holds the listeners of the mounted routes by method and template (none for every
+// listener), to leave restricted methods out of the Allow headers of the other listeners
+type listenerTable map[string]map[string][]string
+
+// add records the listeners of a route
+func (t listenerTable) add(method, template string, listeners []string) {
+	if t[method] == nil {
+		t[method] = make(map[string][]string)
+	}
+	t[method][template] = listeners
+}
+
+// allows reports whether a route answers on a listener; an implicit HEAD follows its GET route
+func (t listenerTable) allows(method, template, listener string) bool {
+	listeners, found := t[method][template]
+	if !found && method == http.MethodHead {
+		listeners = t[http.MethodGet][template]
+	}
+	return len(listeners) == 0 || slices.Contains(listeners, listener)
+}
+
+// visibleMethods returns the methods of a template that answer on a listener
+func (t listenerTable) visibleMethods(methods map[string]bool, template, listener string) map[string]bool {
+	visible := make(map[string]bool, len(methods))
+	for method := range methods {
+		if t.allows(method, template, listener) {
+			visible[method] = true
+		}
+	}
+	return visible
+}
+
+// match returns the template of a method that matches a path, preferring static segments to
+// parameters and parameters to wildcards as gin does; an implicit HEAD matches its GET route
+func (t listenerTable) match(method, path string) (string, bool) {
+	var best string
+	var bestRank []int
+	for template := range t[method] {
+		rank, ok := templateRank(template, path)
+		if ok && (bestRank == nil || slices.Compare(rank, bestRank) > 0) {
+			best, bestRank = template, rank
+		}
+	}
+	if bestRank == nil && method == http.MethodHead {
+		return t.match(http.MethodGet, path)
+	}
+	return best, bestRank != nil
+}
+
+// templateRank matches a path against a route template and ranks each segment of the match:
+// 2 for a static segment, 1 for a parameter and 0 for a wildcard
+func templateRank(template, path string) ([]int, bool) {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	rank := make([]int, 0, len(templateSegments))
+	for i, segment := range templateSegments {
+		switch {
+		case strings.HasPrefix(segment, "*"):
+			return append(rank, 0), true
+		case i >= len(pathSegments):
+			return nil, false
+		case strings.HasPrefix(segment, ":"):
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+			rank = append(rank, 1)
+		case segment == pathSegments[i]:
+			rank = append(rank, 2)
+		default:
+			return nil, false
+		}
+	}
+	return rank, len(templateSegments) == len(pathSegments)
+}
+
+// listenerMethodNotAllowed answers 405 with the methods of the path that answer on the listener of
+// the request, or 404 when none does
+func (a *GoAPI) listenerMethodNotAllowed(table listenerTable) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listener := ListenerName(c)
+		visible := make(map[string]bool)
+		for _, method := range strings.Split(c.Writer.Header().Get("Allow"), ", ") {
+			if method == "" || method == http.MethodOptions {
+				continue
+			}
+			// Los métodos de rutas del framework no tienen listeners
+			if template, found := table.match(method, c.Request.URL.Path); !found || table.allows(method, template, listener) {
+				visible[method] = true
+			}
+		}
+		if len(visible) == 0 {
+			c.Writer.Header().Del("Allow")
+			a.notFoundHandler(c)
+			return
+		}
+		c.Header("Allow", strings.Join(allowedMethods(visible), ", "))
+		a.methodNotAllowedHandler(c)
+	}
+}
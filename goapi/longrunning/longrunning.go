@@ -0,0 +1,110 @@
+// Package longrunning implements the asynchronous request-response pattern: a handler answers
+// 202 Accepted with an operation ID, the work continues in the background and clients poll
+// GET /operations/{id} until the operation is done
+//
+//	operations := longrunning.NewPlugin(nil)
+//	api.UsePlugin(operations)
+//
+//	api.POST("/reports", func(c *gin.Context) {
+//		operations.Start(c, "report", func(ctx context.Context, progress longrunning.Progress) (interface{}, error) {
+//			return buildReport(ctx, progress)
+//		})
+//	}, operations.Accepted(Report{}))
+package longrunning
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Status is the state of an operation
+type Status string
+
+// Operation states; succeeded, failed and canceled operations are done
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Done reports whether the operation has finished
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCanceled
+}
+
+// ErrOperationNotFound is returned by stores for unknown operations
+var ErrOperationNotFound = errors.New("operation not found")
+
+// Operation is the state of a background task, as returned to polling clients
+type Operation struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name,omitempty"` // Kind of work ("report", "import"...)
+	Status    Status      `json:"status" validate:"oneof=pending running succeeded failed canceled"`
+	Progress  int         `json:"progress" validate:"min=0,max=100"` // Percentage reported by the task
+	Message   string      `json:"message,omitempty"`                 // Last progress message
+	Result    interface{} `json:"result,omitempty"`                  // Value returned by a succeeded task
+	Error     string      `json:"error,omitempty"`                   // Error of a failed task
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Task is the work of an operation
+// ctx is canceled when the operation is canceled or the server shuts down; progress reports
+// how far the task is, for polling clients
+type Task func(ctx context.Context, progress Progress) (interface{}, error)
+
+// Progress reports the percentage done (0-100) and an optional message
+type Progress func(percent int, message string)
+
+// Store persists operations, so they can be polled from any instance
+type Store interface {
+	Save(ctx context.Context, operation Operation) error
+	Get(ctx context.Context, id string) (Operation, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore keeps operations in memory
+// It is meant for single-instance deployments and tests; clustered deployments need a shared store
+type MemoryStore struct {
+	operations map[string]Operation
+	mutex      sync.RWMutex
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{operations: make(map[string]Operation)}
+}
+
+// Save implements Store
+func (m *MemoryStore) Save(ctx context.Context, operation Operation) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.operations[operation.ID] = operation
+	return nil
+}
+
+// Get implements Store
+func (m *MemoryStore) Get(ctx context.Context, id string) (Operation, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	operation, exists := m.operations[id]
+	if !exists {
+		return Operation{}, ErrOperationNotFound
+	}
+	return operation, nil
+}
+
+// Delete implements Store
+func (m *MemoryStore) Delete(ctx context.Context, id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, exists := m.operations[id]; !exists {
+		return ErrOperationNotFound
+	}
+	delete(m.operations, id)
+	return nil
+}
@@ -0,0 +1,259 @@
+package longrunning
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// Plugin runs operations in the background and serves their state on {Path}/{id}
+type Plugin struct {
+	Path        string            // Path of the operations (default "/operations")
+	Tags        []string          // Documentation tags of the polling endpoints
+	Retention   time.Duration     // How long done operations can be polled (default 24h)
+	RetryAfter  time.Duration     // Polling interval suggested to clients (default 1s)
+	Middlewares []gin.HandlerFunc // Run before the polling endpoints, for example to authenticate
+
+	store   Store
+	api     *goapi.GoAPI
+	cancels map[string]context.CancelFunc // Running operations of this instance
+	mutex   sync.Mutex
+}
+
+// NewPlugin creates a plugin serving GET and DELETE /operations/{id}
+// A nil store defaults to an in-memory store
+func NewPlugin(store Store) *Plugin {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Plugin{
+		Path:       "/operations",
+		Tags:       []string{"operations"},
+		Retention:  24 * time.Hour,
+		RetryAfter: time.Second,
+		store:      store,
+		cancels:    make(map[string]context.CancelFunc),
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "longrunning"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	p.api = api
+	api.GET(p.Path+"/:id", p.poll,
+		goapi.WithSummary("Get an operation"),
+		goapi.WithDescription("Returns the state of an asynchronous operation. Poll it, waiting the Retry-After seconds, until the status is succeeded, failed or canceled"),
+		goapi.WithTags(p.Tags...),
+		goapi.WithPathParameter("id", "string", "Operation ID"),
+		goapi.WithResponseModel(http.StatusOK, Operation{}, "State of the operation"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown or expired operation"),
+		goapi.WithMiddleware(p.Middlewares...),
+	)
+	api.DELETE(p.Path+"/:id", p.cancel,
+		goapi.WithSummary("Cancel or delete an operation"),
+		goapi.WithDescription("Requests the cancellation of a running operation, or deletes a done one"),
+		goapi.WithTags(p.Tags...),
+		goapi.WithPathParameter("id", "string", "Operation ID"),
+		goapi.WithResponseModel(http.StatusAccepted, Operation{}, "Cancellation requested"),
+		goapi.WithResponse(http.StatusNoContent, "Operation deleted"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown or expired operation"),
+		goapi.WithMiddleware(p.Middlewares...),
+	)
+	return nil
+}
+
+// Store returns the operation store of the plugin
+func (p *Plugin) Store() Store {
+	return p.store
+}
+
+// Accepted documents a route starting operations: its 202 response and the result model
+// the operation returns once it succeeds
+func (p *Plugin) Accepted(resultModel interface{}) router.RouteOption {
+	return goapi.WithResponseModel(http.StatusAccepted, Operation{Result: resultModel},
+		"Operation started. Poll the URL of the Location header until it is done; the result is returned in \"result\"")
+}
+
+// Start runs a task in the background and answers 202 Accepted with the operation, its URL in
+// the Location header and the suggested polling interval in Retry-After
+// It returns the operation ID, or "" when the operation could not start (the response is then
+// already an error)
+func (p *Plugin) Start(c *gin.Context, name string, task Task) string {
+	now := time.Now()
+	operation := Operation{ID: middleware.NewRequestID(), Name: name, Status: StatusPending, CreatedAt: now, UpdatedAt: now}
+	if err := p.store.Save(c.Request.Context(), operation); err != nil {
+		log.Printf("[GoAPI] longrunning: saving operation: %v", err)
+		responses.InternalServerError(c, "Error starting the operation")
+		return ""
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.mutex.Lock()
+	p.cancels[operation.ID] = cancel
+	p.mutex.Unlock()
+
+	// Las tareas son trabajos en segundo plano de la API: un apagado ordenado las cancela y espera
+	started := p.api.Go("longrunning "+name+" "+operation.ID, func(shutdown context.Context) {
+		stop := context.AfterFunc(shutdown, cancel)
+		defer stop()
+		p.run(ctx, operation, task)
+	})
+	if !started {
+		p.forget(operation.ID)
+		operation.Status, operation.Error, operation.UpdatedAt = StatusCanceled, "server shutting down", time.Now()
+		p.finish(operation)
+		responses.Message(c, http.StatusServiceUnavailable, "The server is shutting down")
+		return ""
+	}
+
+	c.Header("Location", p.api.ServedPath(p.Path+"/"+operation.ID))
+	c.Header("Retry-After", p.retryAfter())
+	c.JSON(http.StatusAccepted, operation)
+	return operation.ID
+}
+
+// run executes a task, saving its progress and final state
+func (p *Plugin) run(ctx context.Context, operation Operation, task Task) {
+	defer p.forget(operation.ID)
+
+	var mutex sync.Mutex // Las tareas pueden informar de su progreso desde varias goroutines
+	done := false
+	save := func(update func(*Operation)) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if done {
+			return // El progreso informado tras terminar no reemplaza el estado final
+		}
+		update(&operation)
+		operation.UpdatedAt = time.Now()
+		if err := p.store.Save(context.Background(), operation); err != nil {
+			log.Printf("[GoAPI] longrunning: saving operation %s: %v", operation.ID, err)
+		}
+	}
+
+	save(func(operation *Operation) { operation.Status = StatusRunning })
+	result, err := runTask(ctx, task, func(percent int, message string) {
+		save(func(operation *Operation) {
+			operation.Progress = min(max(percent, 0), 100)
+			operation.Message = message
+		})
+	})
+
+	mutex.Lock()
+	switch {
+	case ctx.Err() != nil && (err == nil || errors.Is(err, context.Canceled)):
+		operation.Status = StatusCanceled
+	case err != nil:
+		operation.Status, operation.Error = StatusFailed, err.Error()
+	default:
+		operation.Status, operation.Result, operation.Progress = StatusSucceeded, result, 100
+	}
+	operation.UpdatedAt = time.Now()
+	done = true
+	mutex.Unlock()
+	p.finish(operation)
+}
+
+// runTask calls a task, turning a panic into an error so the operation does not stay running
+func runTask(ctx context.Context, task Task, progress Progress) (result interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("[GoAPI] longrunning: task panicked: %v", recovered)
+			err = errors.New("internal error")
+		}
+	}()
+	return task(ctx, progress)
+}
+
+// finish saves a done operation and schedules its deletion after the retention period
+func (p *Plugin) finish(operation Operation) {
+	if err := p.store.Save(context.Background(), operation); err != nil {
+		log.Printf("[GoAPI] longrunning: saving operation %s: %v", operation.ID, err)
+	}
+	if p.Retention > 0 {
+		time.AfterFunc(p.Retention, func() {
+			_ = p.store.Delete(context.Background(), operation.ID)
+		})
+	}
+}
+
+// forget releases the cancel function of an operation
+func (p *Plugin) forget(id string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if cancel, exists := p.cancels[id]; exists {
+		cancel()
+		delete(p.cancels, id)
+	}
+}
+
+// poll handles GET {path}/:id
+func (p *Plugin) poll(c *gin.Context) {
+	operation, err := p.store.Get(c.Request.Context(), c.Param("id"))
+	if p.sendStoreError(c, err) {
+		return
+	}
+	if !operation.Status.Done() {
+		c.Header("Retry-After", p.retryAfter())
+	}
+	c.JSON(http.StatusOK, operation)
+}
+
+// cancel handles DELETE {path}/:id
+// Only the instance running an operation can cancel it; on others the request is accepted and
+// the operation keeps its state
+func (p *Plugin) cancel(c *gin.Context) {
+	operation, err := p.store.Get(c.Request.Context(), c.Param("id"))
+	if p.sendStoreError(c, err) {
+		return
+	}
+	if operation.Status.Done() {
+		if p.sendStoreError(c, p.store.Delete(c.Request.Context(), operation.ID)) {
+			return
+		}
+		responses.NoContent(c)
+		return
+	}
+
+	p.mutex.Lock()
+	if cancel, running := p.cancels[operation.ID]; running {
+		cancel()
+	}
+	p.mutex.Unlock()
+	c.JSON(http.StatusAccepted, operation)
+}
+
+// sendStoreError answers a store error, reporting whether there was one
+func (p *Plugin) sendStoreError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, ErrOperationNotFound):
+		responses.NotFound(c, "Operation not found")
+	case err != nil:
+		log.Printf("[GoAPI] longrunning: %v", err)
+		responses.InternalServerError(c, "Error reading the operation")
+	default:
+		return false
+	}
+	return true
+}
+
+// retryAfter returns the Retry-After value, in whole seconds
+func (p *Plugin) retryAfter() string {
+	seconds := int(p.RetryAfter.Round(time.Second) / time.Second)
+	return strconv.Itoa(max(seconds, 1))
+}
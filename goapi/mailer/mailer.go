@@ -0,0 +1,254 @@
+// Package mailer sends emails for GoAPI
+// A Mailer renders templated messages and delivers them through a sender adapter (SMTP, Amazon
+// SES, SendGrid), right away or in the background through the tasks plugin
+//
+//	mails := mailer.New(mailer.NewSMTPSender("smtp.example.com", 587, user, password), "App <no-reply@example.com>")
+//	mails.RegisterTemplate("welcome", "Welcome, {{.Name}}", "<p>Hello {{.Name}}</p>", "Hello {{.Name}}")
+//	api.UsePlugin(mailer.NewPlugin(mails, pool)) // pool: the *tasks.Plugin delivering queued emails
+//
+//	api.POST("/users", func(c *gin.Context) {
+//		...
+//		mails.EnqueueTemplate(c.Request.Context(), "welcome", user, mailer.Message{To: []string{user.Email}})
+//	})
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Errors returned by the mailer and the senders
+var (
+	// ErrNoRecipients is returned when a message has no To, Cc or Bcc address
+	ErrNoRecipients = errors.New("email has no recipients")
+	// ErrTemplateNotFound is returned when rendering an unknown template
+	ErrTemplateNotFound = errors.New("email template not found")
+	// ErrNoQueue is returned when enqueuing while the plugin has no task queue
+	ErrNoQueue = errors.New("mailer has no task queue")
+)
+
+// Message is an email
+// Addresses are RFC 5322 addresses: "ada@example.com" or "Ada Lovelace <ada@example.com>"
+type Message struct {
+	From        string            `json:"from,omitempty"` // Default: the From of the mailer
+	To          []string          `json:"to,omitempty"`
+	Cc          []string          `json:"cc,omitempty"`
+	Bcc         []string          `json:"bcc,omitempty"`
+	ReplyTo     string            `json:"reply_to,omitempty"`
+	Subject     string            `json:"subject"`
+	Text        string            `json:"text,omitempty"` // Plain text body
+	HTML        string            `json:"html,omitempty"` // HTML body; with Text, clients choose
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"` // Default: inferred from the filename
+	Data        []byte `json:"data"`
+}
+
+// recipients returns every address the message is delivered to
+func (m Message) recipients() []string {
+	recipients := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	recipients = append(recipients, m.To...)
+	recipients = append(recipients, m.Cc...)
+	return append(recipients, m.Bcc...)
+}
+
+// Sender delivers messages; the mailer has already set From and checked the recipients
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// enqueuer queues messages for background delivery; implemented by the plugin
+type enqueuer func(ctx context.Context, message Message) error
+
+// Mailer renders and sends emails
+type Mailer struct {
+	Sender Sender
+	From   string // Sender address of messages without From
+
+	templates map[string]*mailTemplate
+	mutex     sync.RWMutex
+	enqueue   enqueuer
+}
+
+// mailTemplate holds the parsed templates of an email
+type mailTemplate struct {
+	subject *template.Template
+	html    *htmltemplate.Template
+	text    *template.Template
+}
+
+// New creates a mailer
+func New(sender Sender, from string) *Mailer {
+	return &Mailer{Sender: sender, From: from, templates: make(map[string]*mailTemplate)}
+}
+
+// RegisterTemplate registers an email template
+// The subject and the text body use text/template syntax, the HTML body html/template, which
+// escapes the data; an empty body is left out of the message
+func (m *Mailer) RegisterTemplate(name, subject, html, text string) error {
+	parsed := &mailTemplate{}
+	var err error
+	if parsed.subject, err = template.New(name + ".subject").Parse(subject); err != nil {
+		return fmt.Errorf("error parsing subject of template %s: %w", name, err)
+	}
+	if html != "" {
+		if parsed.html, err = htmltemplate.New(name + ".html").Parse(html); err != nil {
+			return fmt.Errorf("error parsing HTML of template %s: %w", name, err)
+		}
+	}
+	if text != "" {
+		if parsed.text, err = template.New(name + ".txt").Parse(text); err != nil {
+			return fmt.Errorf("error parsing text of template %s: %w", name, err)
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.templates[name] = parsed
+	return nil
+}
+
+// LoadTemplates registers the templates of a directory: <name>.html is the HTML body and
+// defines the subject in a "subject" block, and the optional <name>.txt is the text body
+//
+//	{{define "subject"}}Confirm your email{{end}}
+//	<p>Hello {{.Name}}, <a href="{{.URL}}">confirm your email</a>.</p>
+func (m *Mailer) LoadTemplates(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.html"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".html")
+		html, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		text, err := fs.ReadFile(fsys, path.Join(dir, name+".txt"))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		parsed := &mailTemplate{}
+		if parsed.html, err = htmltemplate.New(name + ".html").Parse(string(html)); err != nil {
+			return fmt.Errorf("error parsing template %s: %w", file, err)
+		}
+		// El asunto es texto: se toma del archivo analizado sin el escape HTML
+		textSet, err := template.New(name + ".html").Parse(string(html))
+		if err != nil {
+			return fmt.Errorf("error parsing template %s: %w", file, err)
+		}
+		if parsed.subject = textSet.Lookup("subject"); parsed.subject == nil {
+			return fmt.Errorf("template %s does not define a subject block", file)
+		}
+		if len(text) > 0 {
+			if parsed.text, err = template.New(name + ".txt").Parse(string(text)); err != nil {
+				return fmt.Errorf("error parsing template %s.txt: %w", name, err)
+			}
+		}
+
+		m.mutex.Lock()
+		m.templates[name] = parsed
+		m.mutex.Unlock()
+	}
+	return nil
+}
+
+// Render renders a template into a message
+// base provides the remaining fields (recipients, attachments...) of the message
+func (m *Mailer) Render(name string, data interface{}, base ...Message) (Message, error) {
+	m.mutex.RLock()
+	parsed, exists := m.templates[name]
+	m.mutex.RUnlock()
+	if !exists {
+		return Message{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	var message Message
+	if len(base) > 0 {
+		message = base[0]
+	}
+	var buffer bytes.Buffer
+	if err := parsed.subject.Execute(&buffer, data); err != nil {
+		return Message{}, fmt.Errorf("error rendering subject of template %s: %w", name, err)
+	}
+	message.Subject = strings.TrimSpace(buffer.String())
+	if parsed.html != nil {
+		buffer.Reset()
+		if err := parsed.html.Execute(&buffer, data); err != nil {
+			return Message{}, fmt.Errorf("error rendering HTML of template %s: %w", name, err)
+		}
+		message.HTML = strings.TrimSpace(buffer.String())
+	}
+	if parsed.text != nil {
+		buffer.Reset()
+		if err := parsed.text.Execute(&buffer, data); err != nil {
+			return Message{}, fmt.Errorf("error rendering text of template %s: %w", name, err)
+		}
+		message.Text = strings.TrimSpace(buffer.String())
+	}
+	return message, nil
+}
+
+// Send delivers a message right away
+func (m *Mailer) Send(ctx context.Context, message Message) error {
+	message, err := m.prepare(message)
+	if err != nil {
+		return err
+	}
+	return m.Sender.Send(ctx, message)
+}
+
+// SendTemplate renders a template and delivers it right away
+func (m *Mailer) SendTemplate(ctx context.Context, name string, data interface{}, base Message) error {
+	message, err := m.Render(name, data, base)
+	if err != nil {
+		return err
+	}
+	return m.Send(ctx, message)
+}
+
+// Enqueue queues a message for delivery in the background, with the retries of the task queue
+func (m *Mailer) Enqueue(ctx context.Context, message Message) error {
+	message, err := m.prepare(message)
+	if err != nil {
+		return err
+	}
+	if m.enqueue == nil {
+		return ErrNoQueue
+	}
+	return m.enqueue(ctx, message)
+}
+
+// EnqueueTemplate renders a template and queues it; rendering errors are returned right away
+func (m *Mailer) EnqueueTemplate(ctx context.Context, name string, data interface{}, base Message) error {
+	message, err := m.Render(name, data, base)
+	if err != nil {
+		return err
+	}
+	return m.Enqueue(ctx, message)
+}
+
+// prepare sets the default sender and checks the recipients
+func (m *Mailer) prepare(message Message) (Message, error) {
+	if message.From == "" {
+		message.From = m.From
+	}
+	if len(message.recipients()) == 0 {
+		return message, ErrNoRecipients
+	}
+	return message, nil
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// MemorySender keeps the messages instead of delivering them, for development and previews
+type MemorySender struct {
+	mutex    sync.Mutex
+	messages []Message
+}
+
+// NewMemorySender creates a memory sender
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+// Send implements Sender
+func (m *MemorySender) Send(_ context.Context, message Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (m *MemorySender) Messages() []Message {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,185 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Build encodes a message as a MIME document (RFC 5322), as SMTP and raw email APIs expect
+// Bcc addresses are not written: they only go to the envelope
+func Build(message Message) ([]byte, error) {
+	var buffer bytes.Buffer
+	header := textproto.MIMEHeader{}
+	from, err := encodeAddresses(message.From)
+	if err != nil {
+		return nil, err
+	}
+	header.Set("From", from)
+	for name, addresses := range map[string][]string{"To": message.To, "Cc": message.Cc} {
+		if len(addresses) == 0 {
+			continue
+		}
+		encoded, err := encodeAddresses(addresses...)
+		if err != nil {
+			return nil, err
+		}
+		header.Set(name, encoded)
+	}
+	if message.ReplyTo != "" {
+		replyTo, err := encodeAddresses(message.ReplyTo)
+		if err != nil {
+			return nil, err
+		}
+		header.Set("Reply-To", replyTo)
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-Id", messageID(message.From))
+	header.Set("MIME-Version", "1.0")
+	for name, value := range message.Headers {
+		header.Set(name, mime.QEncoding.Encode("utf-8", value))
+	}
+
+	bodyHeader, body := bodyPart(message)
+	if len(message.Attachments) == 0 {
+		for name, values := range bodyHeader {
+			header[name] = values
+		}
+		writeHeader(&buffer, header)
+		buffer.Write(body)
+		return buffer.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buffer)
+	header.Set("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	writeHeader(&buffer, header)
+	part, _ := mixed.CreatePart(bodyHeader)
+	_, _ = part.Write(body)
+	for _, attachment := range message.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(filepath.Ext(attachment.Filename))
+		}
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, _ := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		writeBase64(part, attachment.Data)
+	}
+	_ = mixed.Close()
+	return buffer.Bytes(), nil
+}
+
+// bodyPart encodes the text and HTML bodies of a message, as alternatives when there are both
+func bodyPart(message Message) (textproto.MIMEHeader, []byte) {
+	var buffer bytes.Buffer
+	if message.Text == "" || message.HTML == "" {
+		content, contentType := message.Text, "text/plain; charset=utf-8"
+		if message.HTML != "" {
+			content, contentType = message.HTML, "text/html; charset=utf-8"
+		}
+		writeQuotedPrintable(&buffer, content)
+		return textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}, buffer.Bytes()
+	}
+
+	alternative := multipart.NewWriter(&buffer)
+	for _, body := range []struct{ content, contentType string }{
+		{message.Text, "text/plain; charset=utf-8"},
+		{message.HTML, "text/html; charset=utf-8"},
+	} {
+		part, _ := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		writeQuotedPrintable(part, body.content)
+	}
+	_ = alternative.Close()
+	return textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()}}, buffer.Bytes()
+}
+
+// writeHeader writes the header fields in a stable order, followed by the blank line
+func writeHeader(buffer *bytes.Buffer, header textproto.MIMEHeader) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(buffer, "%s: %s\r\n", name, value)
+		}
+	}
+	buffer.WriteString("\r\n")
+}
+
+// writeQuotedPrintable writes text with CRLF line endings in quoted-printable
+func writeQuotedPrintable(w io.Writer, content string) {
+	writer := quotedprintable.NewWriter(w)
+	_, _ = writer.Write([]byte(strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\n", "\r\n")))
+	_ = writer.Close()
+}
+
+// writeBase64 writes data in base64 lines of 76 characters
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		_, _ = w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	_, _ = w.Write([]byte(encoded + "\r\n"))
+}
+
+// encodeAddresses parses addresses and formats them for a header, encoding non-ASCII names
+func encodeAddresses(addresses ...string) (string, error) {
+	formatted := make([]string, len(addresses))
+	for i, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return "", fmt.Errorf("invalid email address %q: %w", address, err)
+		}
+		formatted[i] = parsed.String()
+	}
+	return strings.Join(formatted, ", "), nil
+}
+
+// addressOf returns the bare email of an address
+func addressOf(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid email address %q: %w", address, err)
+	}
+	return parsed.Address, nil
+}
+
+// messageID generates a Message-ID in the domain of the sender
+func messageID(from string) string {
+	domain := "localhost"
+	if address, err := addressOf(from); err == nil {
+		if at := strings.LastIndex(address, "@"); at >= 0 {
+			domain = address[at+1:]
+		}
+	}
+	random := make([]byte, 12)
+	_, _ = rand.Read(random)
+	return "<" + hex.EncodeToString(random) + "@" + domain + ">"
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/tasks"
+)
+
+// Plugin wires a Mailer into a GoAPI instance
+// It registers the mailer as a singleton dependency and, with a task queue, registers the task
+// delivering the messages of Enqueue, so failed deliveries are retried with backoff
+type Plugin struct {
+	Mailer   *Mailer
+	Tasks    *tasks.Plugin  // Pool delivering queued messages; nil disables Enqueue
+	TaskName string         // Name of the delivery task (default "mailer.send")
+	Options  []tasks.Option // Settings of the delivery task, such as tasks.WithMaxRetries
+}
+
+// NewPlugin creates a plugin for mailer; pool may be nil to only send right away
+func NewPlugin(mailer *Mailer, pool *tasks.Plugin) *Plugin {
+	return &Plugin{Mailer: mailer, Tasks: pool, TaskName: "mailer.send"}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "mailer"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	if p.Mailer == nil || p.Mailer.Sender == nil {
+		return errors.New("mailer: the plugin needs a Mailer with a Sender")
+	}
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Mailer, nil
+	}, (*Mailer)(nil))
+
+	if p.Tasks == nil {
+		return nil
+	}
+	p.Tasks.Register(p.TaskName, p.deliver, p.Options...)
+	p.Mailer.enqueue = func(ctx context.Context, message Message) error {
+		_, err := p.Tasks.Enqueue(ctx, p.TaskName, message)
+		return err
+	}
+	return nil
+}
+
+// deliver is the handler of the delivery task
+func (p *Plugin) deliver(ctx context.Context, task *tasks.Task) error {
+	var message Message
+	if err := task.Bind(&message); err != nil {
+		return tasks.Permanent(err)
+	}
+	return p.Mailer.Sender.Send(ctx, message)
+}
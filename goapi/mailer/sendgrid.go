@@ -0,0 +1,137 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// sendGridEndpoint is the mail send endpoint of the SendGrid v3 API
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridSender delivers messages through the SendGrid v3 API
+type SendGridSender struct {
+	APIKey   string
+	Endpoint string // Default: the SendGrid API
+	Client   *http.Client
+}
+
+// NewSendGridSender creates a SendGrid sender
+func NewSendGridSender(apiKey string) *SendGridSender {
+	return &SendGridSender{APIKey: apiKey, Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// sendGridAddress is an address of the SendGrid API
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// Send implements Sender
+func (s *SendGridSender) Send(ctx context.Context, message Message) error {
+	body, err := s.buildRequest(message)
+	if err != nil {
+		return err
+	}
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = sendGridEndpoint
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+s.APIKey)
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending SendGrid email: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	return fmt.Errorf("SendGrid returned %d: %s", response.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// buildRequest converts a message to the body of the mail send endpoint
+func (s *SendGridSender) buildRequest(message Message) ([]byte, error) {
+	from, err := sendGridAddresses(message.From)
+	if err != nil {
+		return nil, err
+	}
+	personalization := map[string]interface{}{}
+	for field, addresses := range map[string][]string{"to": message.To, "cc": message.Cc, "bcc": message.Bcc} {
+		if len(addresses) == 0 {
+			continue
+		}
+		converted, err := sendGridAddresses(addresses...)
+		if err != nil {
+			return nil, err
+		}
+		personalization[field] = converted
+	}
+
+	// SendGrid exige text/plain antes que text/html
+	var content []map[string]string
+	if message.Text != "" {
+		content = append(content, map[string]string{"type": "text/plain", "value": message.Text})
+	}
+	if message.HTML != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": message.HTML})
+	}
+	body := map[string]interface{}{
+		"personalizations": []interface{}{personalization},
+		"from":             from[0],
+		"subject":          message.Subject,
+		"content":          content,
+	}
+	if message.ReplyTo != "" {
+		replyTo, err := sendGridAddresses(message.ReplyTo)
+		if err != nil {
+			return nil, err
+		}
+		body["reply_to"] = replyTo[0]
+	}
+	if len(message.Headers) > 0 {
+		body["headers"] = message.Headers
+	}
+	if len(message.Attachments) > 0 {
+		attachments := make([]map[string]string, len(message.Attachments))
+		for i, attachment := range message.Attachments {
+			attachments[i] = map[string]string{
+				"content":     base64.StdEncoding.EncodeToString(attachment.Data),
+				"filename":    attachment.Filename,
+				"disposition": "attachment",
+			}
+			if attachment.ContentType != "" {
+				attachments[i]["type"] = attachment.ContentType
+			}
+		}
+		body["attachments"] = attachments
+	}
+	return json.Marshal(body)
+}
+
+// sendGridAddresses parses RFC 5322 addresses into SendGrid addresses
+func sendGridAddresses(addresses ...string) ([]sendGridAddress, error) {
+	converted := make([]sendGridAddress, len(addresses))
+	for i, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email address %q: %w", address, err)
+		}
+		converted[i] = sendGridAddress{Email: parsed.Address, Name: parsed.Name}
+	}
+	return converted, nil
+}
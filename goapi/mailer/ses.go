@@ -0,0 +1,155 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SESSender delivers messages through the Amazon SES v2 API
+// Messages are sent as raw MIME, so attachments and custom headers are kept; requests are
+// signed with AWS Signature Version 4
+type SESSender struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Temporary credentials only
+	Endpoint        string // Default: https://email.<region>.amazonaws.com
+	Client          *http.Client
+}
+
+// NewSESSender creates an SES sender with static credentials
+func NewSESSender(region, accessKeyID, secretAccessKey string) *SESSender {
+	return &SESSender{
+		Region:          region,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		Client:          &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send implements Sender
+func (s *SESSender) Send(ctx context.Context, message Message) error {
+	data, err := Build(message)
+	if err != nil {
+		return err
+	}
+	destination := map[string][]string{}
+	for field, addresses := range map[string][]string{"ToAddresses": message.To, "CcAddresses": message.Cc, "BccAddresses": message.Bcc} {
+		for _, address := range addresses {
+			email, err := addressOf(address)
+			if err != nil {
+				return err
+			}
+			destination[field] = append(destination[field], email)
+		}
+	}
+	from, err := encodeAddresses(message.From)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": from,
+		"Destination":      destination,
+		"Content":          map[string]interface{}{"Raw": map[string][]byte{"Data": data}},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://email." + s.Region + ".amazonaws.com"
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	s.sign(request, body, time.Now().UTC())
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending SES email: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	return fmt.Errorf("SES returned %d: %s", response.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// sign adds the Signature Version 4 authorization of the "ses" service to a request
+func (s *SESSender) sign(request *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256.Sum256(body)
+	request.Header.Set("X-Amz-Date", amzDate)
+	if s.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	// Cabeceras firmadas en minúsculas y en orden alfabético
+	headers := []string{"content-type", "host", "x-amz-date"}
+	if s.SessionToken != "" {
+		headers = append(headers, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, header := range headers {
+		value := request.Header.Get(header)
+		if header == "host" {
+			value = request.URL.Host
+		}
+		canonicalHeaders.WriteString(header + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+	canonicalPath := request.URL.EscapedPath()
+	if canonicalPath == "" {
+		canonicalPath = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalPath,
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/ses/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// canonicalQuery encodes a query string as Signature Version 4 expects
+func canonicalQuery(query url.Values) string {
+	// Encode ordena por clave; SigV4 pide además %20 en lugar de +
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
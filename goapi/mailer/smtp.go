@@ -0,0 +1,122 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPSender delivers messages through an SMTP server
+// It upgrades the connection with STARTTLS when the server offers it, or connects over TLS
+// directly with ImplicitTLS (port 465)
+type SMTPSender struct {
+	Host        string
+	Port        int
+	Username    string // Empty to send without authentication
+	Password    string
+	ImplicitTLS bool
+	TLSConfig   *tls.Config   // Default: verifies the certificate of Host
+	Timeout     time.Duration // Limit of each delivery (default 30s)
+	LocalName   string        // Name sent in HELO/EHLO (default "localhost")
+}
+
+// NewSMTPSender creates an SMTP sender; port 465 uses implicit TLS
+func NewSMTPSender(host string, port int, username, password string) *SMTPSender {
+	return &SMTPSender{
+		Host:        host,
+		Port:        port,
+		Username:    username,
+		Password:    password,
+		ImplicitTLS: port == 465,
+		Timeout:     30 * time.Second,
+	}
+}
+
+// Send implements Sender
+func (s *SMTPSender) Send(ctx context.Context, message Message) error {
+	data, err := Build(message)
+	if err != nil {
+		return err
+	}
+	from, err := addressOf(message.From)
+	if err != nil {
+		return err
+	}
+
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	tlsConfig := s.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: s.Host, MinVersion: tls.VersionTLS12}
+	}
+
+	address := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	var connection net.Conn
+	if s.ImplicitTLS {
+		connection, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", address)
+	} else {
+		connection, err = (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return fmt.Errorf("error connecting to SMTP server: %w", err)
+	}
+	// net/smtp no acepta un contexto: el plazo de la conexión lo aplica
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = connection.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(connection, s.Host)
+	if err != nil {
+		connection.Close()
+		return fmt.Errorf("error connecting to SMTP server: %w", err)
+	}
+	defer client.Close()
+
+	if s.LocalName != "" {
+		if err := client.Hello(s.LocalName); err != nil {
+			return err
+		}
+	}
+	if !s.ImplicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("error starting TLS: %w", err)
+			}
+		}
+	}
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range message.recipients() {
+		to, err := addressOf(recipient)
+		if err != nil {
+			return err
+		}
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP server rejected %s: %w", to, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,195 @@
+// Package mfa adds two-factor authentication with time-based one-time passwords (TOTP)
+// Users enroll by scanning the provisioning URI of a new secret with an authenticator app. A
+// Verifier checks their codes, refusing a code used twice: as the SecondFactor of the auth plugin,
+// which then adds "otp" to the "amr" claim of the tokens, or with VerifySession, which marks the
+// session as verified. Require and WithRequired protect the routes that need a second factor
+//
+//	verifier := mfa.NewVerifier(func(ctx context.Context, user *dependencies.CurrentUser) (string, error) {
+//		return users.TOTPSecret(ctx, user.ID) // "" when the user has not enrolled
+//	})
+//	plugin := auth.NewPlugin(checkPassword, secret)
+//	plugin.SecondFactor = verifier.Check
+//
+//	api.POST("/transfers", createTransfer, mfa.WithRequired(10*time.Minute))
+package mfa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/auth"
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+	"github.com/esteban-ll-aguilar/goapi/goapi/sessions"
+)
+
+// Errors returned by the Verifier; the code errors are those of the auth plugin
+var (
+	// ErrCodeRequired is returned when an enrolled user gives no code
+	ErrCodeRequired = auth.ErrSecondFactorRequired
+	// ErrInvalidCode is returned for a wrong code or a code already used
+	ErrInvalidCode = auth.ErrInvalidSecondFactor
+	// ErrNotEnrolled is returned by VerifySession for users without a secret
+	ErrNotEnrolled = errors.New("two-factor authentication is not enabled for the user")
+)
+
+// Enrollment is a new secret of a user, with the URI their authenticator app imports
+type Enrollment struct {
+	Secret string `json:"secret"` // Store it once the user confirmed a first code
+	URI    string `json:"uri"`    // otpauth:// URI, shown as a QR code
+}
+
+// Enroll generates a secret for an account with DefaultTOTP
+// Keep the secret pending until the user sends a valid code, so a mistyped scan does not lock
+// them out
+func Enroll(issuer, account string) (Enrollment, error) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		return Enrollment{}, err
+	}
+	return Enrollment{Secret: secret, URI: DefaultTOTP.ProvisioningURI(secret, issuer, account)}, nil
+}
+
+// Verifier checks the codes of users
+// The last step accepted for each user is kept in memory, so a code cannot be used twice on an
+// instance
+type Verifier struct {
+	TOTP TOTP
+	// Secret returns the secret of a user; "" when the user has not enrolled
+	Secret func(ctx context.Context, user *dependencies.CurrentUser) (string, error)
+
+	mutex sync.Mutex
+	last  map[string]int64
+}
+
+// NewVerifier creates a verifier with DefaultTOTP
+func NewVerifier(secret func(ctx context.Context, user *dependencies.CurrentUser) (string, error)) *Verifier {
+	return &Verifier{TOTP: DefaultTOTP, Secret: secret, last: make(map[string]int64)}
+}
+
+// Check implements auth.SecondFactor: it reports whether the user verified a code, false for
+// users who have not enrolled
+func (v *Verifier) Check(ctx context.Context, user *dependencies.CurrentUser, code string) (bool, error) {
+	secret, err := v.Secret(ctx, user)
+	if err != nil || secret == "" {
+		return false, err
+	}
+	if code == "" {
+		return false, ErrCodeRequired
+	}
+	step, valid := v.TOTP.Verify(secret, code, time.Now())
+	if !valid {
+		return false, ErrInvalidCode
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.last == nil {
+		v.last = make(map[string]int64)
+	}
+	if last, used := v.last[user.ID]; used && step <= last {
+		return false, ErrInvalidCode
+	}
+	v.last[user.ID] = step
+	return true, nil
+}
+
+// VerifySession checks a code of the user signed in with sessions.Login and marks the session
+// as verified
+func (v *Verifier) VerifySession(c *gin.Context, code string) error {
+	user, err := sessions.UserSource()(c)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return dependencies.ErrNotAuthenticated
+	}
+	verified, err := v.Check(c.Request.Context(), user, code)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrNotEnrolled
+	}
+	return MarkVerified(c, user)
+}
+
+// verification is the session value of MarkVerified
+type verification struct {
+	UserID string    `json:"user_id"`
+	At     time.Time `json:"at"`
+}
+
+// MarkVerified records in the session that the user verified a second factor now
+// The session gets a new ID, as for any change of privileges. The mark only counts for that user:
+// signing in as someone else in the same session does not carry it over
+func MarkVerified(c *gin.Context, user *dependencies.CurrentUser) error {
+	session := sessions.Get(c)
+	if session == nil {
+		return sessions.ErrNoSession
+	}
+	session.Regenerate()
+	return session.Set(sessions.MFAKey, verification{UserID: user.ID, At: time.Now()})
+}
+
+// VerifiedAt returns when the user of the request verified a second factor: from the "amr" and
+// "auth_time" claims of its token, or from the mark of its session. It reports false when the
+// request carries no verification
+func VerifiedAt(c *gin.Context) (time.Time, bool) {
+	if claims, ok := c.Value(dependencies.ClaimsKey).(map[string]interface{}); ok {
+		for _, method := range dependencies.ClaimList(claims["amr"]) {
+			if method == auth.MethodOTP || method == "mfa" {
+				authTime, _ := claims["auth_time"].(float64)
+				return time.Unix(int64(authTime), 0), true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	session := sessions.Get(c)
+	if session == nil {
+		return time.Time{}, false
+	}
+	user, _ := sessions.UserSource()(c)
+	mark, found := sessions.Value[verification](session, sessions.MFAKey)
+	if !found || user == nil || mark.UserID != user.ID {
+		return time.Time{}, false
+	}
+	return mark.At, true
+}
+
+// Require rejects requests whose user has not verified a second factor, or did it more than
+// maxAge ago (0: any age), with a 401 of type "mfa_required" (RFC 9470 step-up)
+func Require(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		at, verified := VerifiedAt(c)
+		if verified && (maxAge <= 0 || time.Since(at) <= maxAge) {
+			c.Next()
+			return
+		}
+		c.Header("WWW-Authenticate", `Bearer error="insufficient_user_authentication"`)
+		responses.JSON(c, http.StatusUnauthorized, responses.ErrorResponse{
+			Detail:    i18n.Localize(c, "Two-factor authentication required"),
+			Type:      "mfa_required",
+			RequestID: c.GetString(responses.RequestIDKey),
+		})
+		c.Abort()
+	}
+}
+
+// WithRequired requires a second factor on a route, see Require, and documents its 401
+func WithRequired(maxAge time.Duration) router.RouteOption {
+	return func(route *router.Route) {
+		router.WithMiddleware(Require(maxAge))(route)
+		if _, declared := route.Responses[http.StatusUnauthorized]; !declared {
+			router.WithResponse(http.StatusUnauthorized, "Authentication or two-factor authentication required")(route)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package mfa
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSecret is returned for secrets that are not base32
+var ErrInvalidSecret = errors.New("invalid TOTP secret")
+
+// secretEncoding is the base32 of authenticator apps, without padding
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTP generates and checks time-based one-time passwords (RFC 6238)
+// The defaults match authenticator apps: 6 digits, 30 seconds, HMAC-SHA1
+type TOTP struct {
+	Digits    int           // Default 6
+	Period    time.Duration // Default 30 seconds
+	Algorithm string        // "SHA1" (default), "SHA256" or "SHA512"
+	Skew      int           // Periods accepted before and after the current one, for clock drift
+}
+
+// DefaultTOTP is the configuration of authenticator apps
+var DefaultTOTP = TOTP{Digits: 6, Period: 30 * time.Second, Algorithm: "SHA1", Skew: 1}
+
+// GenerateSecret returns a random 160-bit secret in base32
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+// Code returns the code of a secret at a time
+func (t TOTP) Code(secret string, at time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return t.code(key, t.step(at)), nil
+}
+
+// Verify checks a code at a time and returns the step it belongs to, so callers can refuse a
+// code used twice (see Verifier)
+func (t TOTP) Verify(secret, code string, at time.Time) (int64, bool) {
+	key, err := decodeSecret(secret)
+	code = strings.ReplaceAll(code, " ", "")
+	if err != nil || len(code) != t.digits() {
+		return 0, false
+	}
+	current := t.step(at)
+	for offset := -t.skew(); offset <= t.skew(); offset++ {
+		step := current + int64(offset)
+		if subtle.ConstantTimeCompare([]byte(t.code(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// ProvisioningURI returns the otpauth:// URI authenticator apps import, usually from a QR code
+// issuer names the service and account the user ("ana@example.com")
+func (t TOTP) ProvisioningURI(secret, issuer, account string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", t.algorithm())
+	query.Set("digits", strconv.Itoa(t.digits()))
+	query.Set("period", strconv.Itoa(int(t.period()/time.Second)))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// code computes the code of a step (RFC 4226, section 5.3)
+func (t TOTP) code(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(t.hash(), key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < t.digits(); i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", t.digits(), value%modulo)
+}
+
+// step returns the time step of a time
+func (t TOTP) step(at time.Time) int64 {
+	return at.Unix() / int64(t.period()/time.Second)
+}
+
+func (t TOTP) digits() int {
+	if t.Digits <= 0 {
+		return 6
+	}
+	return t.Digits
+}
+
+func (t TOTP) period() time.Duration {
+	if t.Period < time.Second {
+		return 30 * time.Second
+	}
+	return t.Period
+}
+
+func (t TOTP) skew() int {
+	if t.Skew < 0 {
+		return 0
+	}
+	return t.Skew
+}
+
+func (t TOTP) algorithm() string {
+	switch strings.ToUpper(t.Algorithm) {
+	case "SHA256":
+		return "SHA256"
+	case "SHA512":
+		return "SHA512"
+	}
+	return "SHA1"
+}
+
+func (t TOTP) hash() func() hash.Hash {
+	switch t.algorithm() {
+	case "SHA256":
+		return sha256.New
+	case "SHA512":
+		return sha512.New
+	}
+	return sha1.New
+}
+
+// decodeSecret decodes a base32 secret, as typed by users: any case, spaces and padding
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.TrimRight(strings.ToUpper(strings.ReplaceAll(secret, " ", "")), "=")
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// CoalesceConfig configures request coalescing
+type CoalesceConfig struct {
+	// Key identifies identical requests, which share a single execution (default CoalesceKey)
+	Key func(c *gin.Context) string
+}
+
+// coalescedResponse is the response of a leader request, replayed to the requests waiting for it
+type coalescedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// CoalesceKey is the default coalescing key: the method, the path, the sorted query and the
+// headers that change the response (credentials, content negotiation), so clients only share
+// the responses they would have received anyway
+func CoalesceKey(c *gin.Context) string {
+	var key strings.Builder
+	key.WriteString(c.Request.Method)
+	key.WriteByte(' ')
+	key.WriteString(c.Request.URL.Path)
+	key.WriteByte('?')
+	key.WriteString(c.Request.URL.Query().Encode())
+	for _, name := range []string{"Authorization", "Cookie", "Accept", "Accept-Language", "Accept-Encoding"} {
+		key.WriteByte('\n')
+		key.WriteString(c.GetHeader(name))
+	}
+	return key.String()
+}
+
+// Coalesce deduplicates identical concurrent GET and HEAD requests: the first one runs the rest
+// of the chain while the others wait for it and get a copy of its response, so an expensive
+// query runs once when many clients ask for the same resource at the same time (cache stampedes)
+// Place it after authentication; waiting requests keep their own headers (request ID...) and
+// never receive the Set-Cookie of the leader. Streaming responses are not shared: the waiting
+// requests then run the handler themselves
+func Coalesce(config ...CoalesceConfig) gin.HandlerFunc {
+	cfg := CoalesceConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Key == nil {
+		cfg.Key = CoalesceKey
+	}
+	var group singleflight.Group
+
+	return func(c *gin.Context) {
+		if (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) || IsStreaming(c) {
+			c.Next()
+			return
+		}
+
+		leader := false
+		value, _, _ := group.Do(cfg.Key(c), func() (interface{}, error) {
+			leader = true
+			writer := &bufferedResponseWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+			c.Writer = writer
+			defer func() { c.Writer = writer.ResponseWriter }() // Also when the handler panics
+			c.Next()
+
+			if writer.streaming {
+				return nil, nil
+			}
+			response := &coalescedResponse{status: writer.status, header: writer.Header().Clone(), body: writer.body.Bytes()}
+			response.header.Del("Set-Cookie")
+			if writer.written {
+				writer.ResponseWriter.WriteHeader(writer.status)
+				_, _ = writer.ResponseWriter.Write(response.body)
+			}
+			return response, nil
+		})
+		if leader {
+			return
+		}
+
+		response, _ := value.(*coalescedResponse)
+		if response == nil {
+			c.Next()
+			return
+		}
+		header := c.Writer.Header()
+		for name, values := range response.header {
+			if _, exists := header[name]; !exists {
+				header[name] = slices.Clone(values)
+			}
+		}
+		c.Status(response.status)
+		_, _ = c.Writer.Write(response.body)
+		c.Abort()
+	}
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+)
+
+// ConnectionLimitConfig configures the limits applied to long-lived connections (websocket, SSE)
+type ConnectionLimitConfig struct {
+	MaxConnections  int                       // Global cap of simultaneous connections (0 = unlimited)
+	MaxPerPrincipal int                       // Cap per user or client (0 = unlimited)
+	Principal       func(*gin.Context) string // Identifies the caller; defaults to "user_id" or the client IP
+	Match           func(*gin.Context) bool   // Selects the limited requests; defaults to IsLongLivedRequest
+	RetryAfter      time.Duration             // Sent as Retry-After on rejection (0 = omitted)
+}
+
+// ConnectionStats is a snapshot of the connection limiter metrics
+type ConnectionStats struct {
+	Active              int            `json:"active"`
+	ByPrincipal         map[string]int `json:"by_principal"`
+	Accepted            uint64         `json:"accepted"`
+	RejectedGlobal      uint64         `json:"rejected_global"`
+	RejectedByPrincipal uint64         `json:"rejected_by_principal"`
+}
+
+// ConnectionLimiter tracks the open long-lived connections of every principal
+type ConnectionLimiter struct {
+	config      ConnectionLimitConfig
+	mutex       sync.Mutex
+	active      int
+	byPrincipal map[string]int
+
+	accepted            atomic.Uint64
+	rejectedGlobal      atomic.Uint64
+	rejectedByPrincipal atomic.Uint64
+}
+
+// NewConnectionLimiter creates a connection limiter
+func NewConnectionLimiter(config ConnectionLimitConfig) *ConnectionLimiter {
+	if config.Principal == nil {
+		config.Principal = defaultPrincipal
+	}
+	if config.Match == nil {
+		config.Match = IsLongLivedRequest
+	}
+	return &ConnectionLimiter{
+		config:      config,
+		byPrincipal: make(map[string]int),
+	}
+}
+
+// ConnectionLimit returns a middleware limiting simultaneous long-lived connections
+// Use NewConnectionLimiter instead when the metrics are needed
+func ConnectionLimit(config ConnectionLimitConfig) gin.HandlerFunc {
+	return NewConnectionLimiter(config).Middleware()
+}
+
+// Middleware returns the gin middleware enforcing the limits
+// The slot is held until the handler returns, i.e. for the whole life of the stream
+func (l *ConnectionLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !l.config.Match(c) {
+			c.Next()
+			return
+		}
+
+		principal := l.config.Principal(c)
+		if status, key := l.acquire(principal); status != 0 {
+			if l.config.RetryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(l.config.RetryAfter.Seconds())))
+			}
+			c.JSON(status, errorBody(c, i18n.M(key), "connection_limit_error"))
+			c.Abort()
+			return
+		}
+		defer l.release(principal)
+
+		c.Next()
+	}
+}
+
+// Stats returns a snapshot of the limiter metrics
+func (l *ConnectionLimiter) Stats() ConnectionStats {
+	l.mutex.Lock()
+	byPrincipal := make(map[string]int, len(l.byPrincipal))
+	for principal, count := range l.byPrincipal {
+		byPrincipal[principal] = count
+	}
+	active := l.active
+	l.mutex.Unlock()
+
+	return ConnectionStats{
+		Active:              active,
+		ByPrincipal:         byPrincipal,
+		Accepted:            l.accepted.Load(),
+		RejectedGlobal:      l.rejectedGlobal.Load(),
+		RejectedByPrincipal: l.rejectedByPrincipal.Load(),
+	}
+}
+
+// acquire reserves a connection slot, returning the rejection status and message key when a cap is reached
+func (l *ConnectionLimiter) acquire(principal string) (int, string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.config.MaxConnections > 0 && l.active >= l.config.MaxConnections {
+		l.rejectedGlobal.Add(1)
+		return http.StatusServiceUnavailable, i18n.KeyConnectionLimit
+	}
+	if l.config.MaxPerPrincipal > 0 && l.byPrincipal[principal] >= l.config.MaxPerPrincipal {
+		l.rejectedByPrincipal.Add(1)
+		return http.StatusTooManyRequests, i18n.KeyTooManyConnections
+	}
+
+	l.active++
+	l.byPrincipal[principal]++
+	l.accepted.Add(1)
+	return 0, ""
+}
+
+// release frees the slot of a closed connection
+func (l *ConnectionLimiter) release(principal string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.active--
+	if l.byPrincipal[principal] <= 1 {
+		delete(l.byPrincipal, principal)
+	} else {
+		l.byPrincipal[principal]--
+	}
+}
+
+// IsLongLivedRequest reports whether a request opens a websocket or a server-sent events stream
+func IsLongLivedRequest(c *gin.Context) bool {
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		return true
+	}
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// defaultPrincipal identifies the caller by the authenticated user ID, falling back to the client IP
+func defaultPrincipal(c *gin.Context) string {
+	if userID := c.GetString("user_id"); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Propagated headers
+const (
+	TraceParentHeader   = "traceparent"
+	TraceStateHeader    = "tracestate"
+	CorrelationIDHeader = "X-Correlation-ID"
+)
+
+// traceParentPattern matches a W3C trace context traceparent (version-traceid-parentid-flags)
+var traceParentPattern = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// CorrelationConfig configures the Correlation middleware
+type CorrelationConfig struct {
+	Headers []string // Extra headers propagated besides traceparent, tracestate and X-Correlation-ID (baggage, for example)
+}
+
+// propagationContextKey holds the propagated headers in the request context
+type propagationContextKey struct{}
+
+// Correlation extracts the trace and correlation headers of a request and stores them in its
+// context.Context, so outbound calls made from handlers forward them (see httpclient.New)
+// Requests without X-Correlation-ID get one: the request ID when RequestID ran before, or a new one.
+// It is echoed in the response; invalid traceparent values are dropped, as the W3C spec requires
+func Correlation(config ...CorrelationConfig) gin.HandlerFunc {
+	names := []string{TraceParentHeader, TraceStateHeader, CorrelationIDHeader}
+	if len(config) > 0 {
+		names = append(names, config[0].Headers...)
+	}
+
+	return func(c *gin.Context) {
+		// In-process calls (Invoke, batch) inherit the headers of the calling request
+		inherited := PropagationHeaders(c.Request.Context())
+		propagated := make(http.Header, len(names))
+		for _, name := range names {
+			value := c.GetHeader(name)
+			if value == "" {
+				value = inherited.Get(name)
+			}
+			if value != "" {
+				propagated.Set(name, value)
+			}
+		}
+
+		if traceParent := propagated.Get(TraceParentHeader); traceParent != "" && !traceParentPattern.MatchString(traceParent) {
+			propagated.Del(TraceParentHeader)
+			propagated.Del(TraceStateHeader)
+		}
+		if !validRequestID(propagated.Get(CorrelationIDHeader)) {
+			correlationID := GetRequestID(c)
+			if correlationID == "" {
+				correlationID = NewRequestID()
+			}
+			propagated.Set(CorrelationIDHeader, correlationID)
+		}
+
+		c.Header(CorrelationIDHeader, propagated.Get(CorrelationIDHeader))
+		c.Request = c.Request.WithContext(ContextWithPropagation(c.Request.Context(), propagated))
+		c.Next()
+	}
+}
+
+// PropagationHeaders returns a copy of the headers stored by Correlation (empty when it did not run)
+func PropagationHeaders(ctx context.Context) http.Header {
+	header, _ := ctx.Value(propagationContextKey{}).(http.Header)
+	return header.Clone()
+}
+
+// ContextWithPropagation returns a copy of ctx carrying headers to forward on outbound calls
+func ContextWithPropagation(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, propagationContextKey{}, header)
+}
+
+// CorrelationIDFromContext returns the correlation ID stored by Correlation
+func CorrelationIDFromContext(ctx context.Context) string {
+	header, _ := ctx.Value(propagationContextKey{}).(http.Header)
+	return header.Get(CorrelationIDHeader)
+}
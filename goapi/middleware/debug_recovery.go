@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// debugSourceFrames is how many frames of the debug page show their source
+const debugSourceFrames = 12
+
+// debugHiddenHeaders are masked in the debug responses, which end up in browsers and logs
+var debugHiddenHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+}
+
+// PanicDetails describes a recovered panic in the debug responses
+type PanicDetails struct {
+	Panic   string         `json:"panic"`
+	Type    string         `json:"type"`              // Go type of the panic value
+	Route   string         `json:"route,omitempty"`   // Method and route pattern: "GET /users/:id"
+	Handler string         `json:"handler,omitempty"` // Name of the handler function
+	Request RequestDetails `json:"request"`
+	Stack   []StackFrame   `json:"stack"` // Most recent call first
+}
+
+// RequestDetails describes the request of a recovered panic
+type RequestDetails struct {
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	ClientIP string            `json:"client_ip"`
+	Headers  map[string]string `json:"headers"` // Credentials and cookies are masked
+}
+
+// DebugRecovery is the Recovery of debug mode: the 500 response describes the panic, with the
+// route, the request and the stack trace, and the panic is logged with the route and request ID
+// Clients asking for text/html, such as browsers, get an HTML page showing the source around
+// each call; the others get the usual error body with a "debug" member. It exposes the code and
+// the request headers: GoAPI only uses it when Debug is true. A panic after the handler sent its
+// response is only logged
+func DebugRecovery(reporters ...ErrorReporter) gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		stack := debug.Stack()
+		if len(reporters) > 0 {
+			report(c, reporters, &PanicError{Value: recovered}, stack)
+		}
+		details := panicDetails(c, recovered, stack)
+		log.Printf("[GoAPI] panic in %s (request %s): %s", details.Route, GetRequestID(c), details.Panic)
+
+		if c.Writer.Written() {
+			// El handler ya envió su respuesta: el pánico solo queda en el log
+			log.Printf("[GoAPI] the response was already sent, the panic details are not returned")
+		} else if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+			c.Header("Content-Type", "text/html; charset=utf-8")
+			c.Status(http.StatusInternalServerError)
+			if err := debugPage.Execute(c.Writer, debugPageData{PanicDetails: details, RequestID: GetRequestID(c), Frames: sourceFrames(details.Stack)}); err != nil {
+				log.Printf("[GoAPI] error rendering debug page: %v", err)
+			}
+		} else {
+			body := errorBody(c, "panic: "+details.Panic, "panic_error")
+			body["debug"] = details
+			c.JSON(http.StatusInternalServerError, body)
+		}
+		c.Abort()
+	})
+}
+
+// panicDetails collects the details of a panic
+func panicDetails(c *gin.Context, recovered interface{}, stack []byte) PanicDetails {
+	details := PanicDetails{
+		Panic:   fmt.Sprint(recovered),
+		Type:    fmt.Sprintf("%T", recovered),
+		Handler: c.HandlerName(),
+		Stack:   ParseStack(stack),
+		Request: RequestDetails{
+			Method:   c.Request.Method,
+			URL:      c.Request.URL.RequestURI(),
+			ClientIP: c.ClientIP(),
+			Headers:  make(map[string]string, len(c.Request.Header)),
+		},
+	}
+	if route := c.FullPath(); route != "" {
+		details.Route = c.Request.Method + " " + route
+	}
+	for name, values := range c.Request.Header {
+		details.Request.Headers[name] = strings.Join(values, ", ")
+		if debugHiddenHeaders[name] {
+			details.Request.Headers[name] = "********"
+		}
+	}
+	return details
+}
+
+// debugPageData is the data of the debug page
+type debugPageData struct {
+	PanicDetails
+	RequestID string
+	Frames    []debugFrame
+}
+
+// debugFrame is a frame of the debug page, with the source around its line
+type debugFrame struct {
+	StackFrame
+	Framework bool // Frame of Gin, GoAPI or the standard library
+	Source    []sourceLine
+}
+
+// sourceLine is a line of source code
+type sourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// sourceFrames adds the source around the line of the first frames
+func sourceFrames(stack []StackFrame) []debugFrame {
+	frames := make([]debugFrame, len(stack))
+	for i, frame := range stack {
+		frames[i] = debugFrame{
+			StackFrame: frame,
+			Framework: strings.HasPrefix(frame.Function, "github.com/gin-gonic/") ||
+				strings.HasPrefix(frame.Function, "github.com/esteban-ll-aguilar/goapi/goapi") ||
+				strings.HasPrefix(frame.Function, "net/"),
+		}
+		if i < debugSourceFrames {
+			frames[i].Source = readSource(frame.File, frame.Line, 3)
+		}
+	}
+	return frames
+}
+
+// readSource returns the lines of a file around a line; nil when the file cannot be read
+func readSource(file string, line, around int) []sourceLine {
+	handle, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer handle.Close()
+
+	var lines []sourceLine
+	scanner := bufio.NewScanner(handle)
+	for number := 1; scanner.Scan() && number <= line+around; number++ {
+		if number >= line-around {
+			lines = append(lines, sourceLine{Number: number, Text: scanner.Text(), Current: number == line})
+		}
+	}
+	return lines
+}
+
+// sortedHeaders lists headers by name for the debug page
+func sortedHeaders(headers map[string]string) [][2]string {
+	sorted := make([][2]string, 0, len(headers))
+	for name, value := range headers {
+		sorted = append(sorted, [2]string{name, value})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
+	return sorted
+}
+
+// debugPage is the HTML page of DebugRecovery
+var debugPage = template.Must(template.New("debug").Funcs(template.FuncMap{"headers": sortedHeaders}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>panic: {{.Panic}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 0; color: #1f2933; background: #f5f7fa; }
+header { background: #b42318; color: #fff; padding: 24px 32px; }
+header h1 { margin: 0 0 8px; font-size: 22px; word-break: break-word; }
+header p { margin: 0; opacity: .85; }
+main { padding: 24px 32px; }
+h2 { font-size: 16px; margin: 24px 0 8px; }
+table { border-collapse: collapse; background: #fff; width: 100%; }
+td { border-bottom: 1px solid #e4e7eb; padding: 6px 10px; font-size: 13px; vertical-align: top; word-break: break-all; }
+td:first-child { font-weight: 600; width: 220px; word-break: normal; }
+.frame { background: #fff; border: 1px solid #e4e7eb; margin-bottom: 8px; }
+.frame summary { cursor: pointer; padding: 8px 10px; font-family: monospace; font-size: 13px; }
+.frame.framework summary { color: #7b8794; }
+.frame span { color: #7b8794; }
+pre { margin: 0; padding: 8px 0; background: #1f2933; color: #e4e7eb; font-size: 12px; overflow-x: auto; }
+pre div { padding: 0 10px; }
+pre .current { background: #7a271a; }
+</style>
+</head>
+<body>
+<header>
+<h1>panic: {{.Panic}}</h1>
+<p>{{.Type}}{{if .Route}} in {{.Route}}{{end}}{{if .RequestID}} &middot; request {{.RequestID}}{{end}}</p>
+</header>
+<main>
+<h2>Stack trace</h2>
+{{range .Frames}}<details class="frame{{if .Framework}} framework{{end}}"{{if and (not .Framework) .Source}} open{{end}}>
+<summary>{{.Function}} <span>{{.File}}:{{.Line}}</span></summary>
+{{if .Source}}<pre>{{range .Source}}<div{{if .Current}} class="current"{{end}}>{{printf "%5d" .Number}}  {{.Text}}</div>{{end}}</pre>{{end}}
+</details>
+{{end}}
+<h2>Request</h2>
+<table>
+<tr><td>Method</td><td>{{.Request.Method}}</td></tr>
+<tr><td>URL</td><td>{{.Request.URL}}</td></tr>
+<tr><td>Client IP</td><td>{{.Request.ClientIP}}</td></tr>
+{{if .Handler}}<tr><td>Handler</td><td>{{.Handler}}</td></tr>{{end}}
+</table>
+<h2>Headers</h2>
+<table>
+{{range headers .Request.Headers}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>
+</main>
+</body>
+</html>
+`))
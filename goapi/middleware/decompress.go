@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+)
+
+// DecompressConfig configures the Decompress middleware
+type DecompressConfig struct {
+	MaxSize           int64 // Decompressed body bytes accepted (default 10 MB)
+	MaxCompressedSize int64 // Compressed body bytes read (default MaxSize)
+	// MaxRatio bounds how much a body may inflate, checked once it passes 1 MB decompressed, so
+	// compression bombs are rejected before reaching MaxSize (default 100)
+	MaxRatio int64
+}
+
+// ratioCheckSize is the decompressed size from which MaxRatio is checked; small bodies of
+// repetitive JSON legitimately compress far better than large ones
+const ratioCheckSize = 1 << 20
+
+// errBodyTooLarge reports a body over the limits of DecompressConfig
+var errBodyTooLarge = errors.New("request body too large")
+
+// gzipReaders pools gzip readers, which allocate their window on creation
+var gzipReaders sync.Pool
+
+// Decompress decodes request bodies sent with Content-Encoding gzip or deflate, so handlers and
+// binding read them as if they were sent uncompressed
+// The body is decompressed before calling the handler: bodies over the limits are answered 413,
+// corrupt ones 400, and other encodings 415 with the accepted ones in Accept-Encoding. The
+// decoded request has no Content-Encoding and its real Content-Length
+func Decompress(config ...DecompressConfig) gin.HandlerFunc {
+	var cfg DecompressConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 10 << 20
+	}
+	if cfg.MaxCompressedSize <= 0 {
+		cfg.MaxCompressedSize = cfg.MaxSize
+	}
+	if cfg.MaxRatio <= 0 {
+		cfg.MaxRatio = 100
+	}
+
+	return func(c *gin.Context) {
+		header := c.Request.Header.Get("Content-Encoding")
+		encodings := contentEncodings(header)
+		if len(encodings) == 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		for _, encoding := range encodings {
+			if encoding != "gzip" && encoding != "x-gzip" && encoding != "deflate" {
+				c.Header("Accept-Encoding", "gzip, deflate")
+				c.JSON(http.StatusUnsupportedMediaType, errorBody(c, i18n.M(i18n.KeyUnsupportedEncoding, i18n.Params{"encoding": encoding}), "unsupported_encoding"))
+				c.Abort()
+				return
+			}
+		}
+
+		body, err := decompressBody(c.Request.Body, encodings, cfg)
+		_ = c.Request.Body.Close()
+		switch {
+		case errors.Is(err, errBodyTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, errorBody(c, i18n.M(i18n.KeyPayloadTooLarge), "payload_too_large"))
+			c.Abort()
+			return
+		case err != nil:
+			c.JSON(http.StatusBadRequest, errorBody(c, i18n.M(i18n.KeyInvalidEncoding, i18n.Params{"encoding": header}), "invalid_encoding"))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		c.Request.Header.Del("Content-Encoding")
+		c.Next()
+	}
+}
+
+// contentEncodings returns the codings of a Content-Encoding header in the order they were
+// applied, without identity
+func contentEncodings(header string) []string {
+	var encodings []string
+	for _, encoding := range strings.Split(header, ",") {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding != "" && encoding != "identity" {
+			encodings = append(encodings, encoding)
+		}
+	}
+	return encodings
+}
+
+// decompressBody decodes a body, undoing the codings from the last one applied
+func decompressBody(body io.Reader, encodings []string, cfg DecompressConfig) ([]byte, error) {
+	compressed := &countingReader{reader: body, limit: cfg.MaxCompressedSize}
+	reader := io.Reader(compressed)
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder, err := newDecoder(encodings[i], reader)
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.release()
+		reader = decoder
+	}
+
+	var buffer bytes.Buffer
+	chunk := make([]byte, 32<<10)
+	for {
+		n, err := reader.Read(chunk)
+		buffer.Write(chunk[:n])
+		size := int64(buffer.Len())
+		if size > cfg.MaxSize || (size > ratioCheckSize && size > compressed.read*cfg.MaxRatio) {
+			return nil, errBodyTooLarge
+		}
+		if err == io.EOF {
+			return buffer.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// decoder is a decompressing reader that is returned to its pool when released
+type decoder struct {
+	io.Reader
+	release func()
+}
+
+// newDecoder returns the decompressing reader of a coding
+// Deflate is zlib-wrapped according to HTTP, but some clients send raw deflate data; both are read
+func newDecoder(encoding string, compressed io.Reader) (*decoder, error) {
+	if encoding == "deflate" {
+		buffered := bufio.NewReader(compressed)
+		if header, err := buffered.Peek(2); err == nil && isZlibHeader(header) {
+			reader, err := zlib.NewReader(buffered)
+			if err != nil {
+				return nil, err
+			}
+			return &decoder{Reader: reader, release: func() { _ = reader.Close() }}, nil
+		}
+		reader := flate.NewReader(buffered)
+		return &decoder{Reader: reader, release: func() { _ = reader.Close() }}, nil
+	}
+
+	reader, _ := gzipReaders.Get().(*gzip.Reader)
+	var err error
+	if reader == nil {
+		reader, err = gzip.NewReader(compressed)
+	} else {
+		err = reader.Reset(compressed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &decoder{Reader: reader, release: func() { gzipReaders.Put(reader) }}, nil
+}
+
+// isZlibHeader reports whether data starts with a zlib header (RFC 1950)
+func isZlibHeader(data []byte) bool {
+	return data[0]&0x0f == 8 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0
+}
+
+// countingReader counts the compressed bytes read, failing past its limit
+type countingReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+}
+
+// Read reads from the body
+func (r *countingReader) Read(data []byte) (int, error) {
+	n, err := r.reader.Read(data)
+	r.read += int64(n)
+	if r.read > r.limit {
+		return n, errBodyTooLarge
+	}
+	return n, err
+}
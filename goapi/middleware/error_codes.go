@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// ErrorCodeVerification reports error codes a route returns without declaring them (WithErrorCodes)
+// Declared codes that are not registered are reported on the first request
+// Only codes sent through responses.SendErrorCode are seen; it is meant for development only
+func ErrorCodeVerification(route router.Route, logger func(format string, args ...interface{})) gin.HandlerFunc {
+	if logger == nil {
+		logger = log.Printf
+	}
+	var checkRegistration sync.Once
+
+	return func(c *gin.Context) {
+		checkRegistration.Do(func() {
+			for _, code := range route.ErrorCodes {
+				if _, registered := responses.LookupErrorCode(code); !registered {
+					logger("[GoAPI] route %s %s declares unregistered error code %s", route.Method, route.Path, code)
+				}
+			}
+		})
+
+		c.Next()
+
+		code := c.GetString(responses.ErrorCodeKey)
+		if code != "" && !slices.Contains(route.ErrorCodes, code) {
+			logger("[GoAPI] route %s %s returned undeclared error code %s (declared: %v)",
+				route.Method, route.Path, code, route.ErrorCodes)
+		}
+	}
+}
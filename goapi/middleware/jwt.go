@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// AuthModeKey holds the router.AuthMode of the route a request targets, set from
+// router.WithAuthRequired and router.WithAllowAnonymous
+const AuthModeKey = "goapi.auth_mode"
+
+// RouteAuthMode returns the authentication mode of the route a request targets
+func RouteAuthMode(c *gin.Context) router.AuthMode {
+	mode, _ := c.Value(AuthModeKey).(router.AuthMode)
+	return mode
+}
+
+// ErrInvalidToken is returned by ParseJWT for malformed, badly signed, expired or foreign tokens
+var ErrInvalidToken = errors.New("invalid token")
+
+// JWTConfig configures JWTAuth
+type JWTConfig struct {
+	// Key verifies the signatures: the HMAC secret ([]byte), *rsa.PublicKey, *ecdsa.PublicKey or
+	// ed25519.PublicKey. An empty secret verifies no token
+	Key interface{}
+	// Keys returns the key of the "kid" header of a token, to rotate keys; it replaces Key
+	Keys func(keyID string) (interface{}, error)
+	// Issuer, when set, must be the "iss" claim
+	Issuer string
+	// Audience, when set, must be in the "aud" claim
+	Audience string
+	// Leeway is the clock skew allowed when checking "exp" and "nbf"
+	Leeway time.Duration
+	// Optional lets requests without a token through, anonymously; invalid tokens are still rejected
+	Optional bool
+}
+
+// JWTAuth authenticates requests with a bearer JWT
+// Requests without a valid token get a 401. The verified claims are stored under
+// dependencies.ClaimsKey, the "sub" claim under dependencies.UserIDKey and the user built from the
+// claims under dependencies.CurrentUserKey, where authz and CurrentUserProvider read them
+// Routes declared with router.WithAllowAnonymous are served without a token, and ignore invalid
+// ones; routes declared with router.WithAuthRequired need a token even when Optional is set.
+// Requests without a token that an earlier middleware authenticated (an API key) are let through
+func JWTAuth(config JWTConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mode := RouteAuthMode(c)
+		token, found := bearerToken(c)
+		if mode == router.AuthAnonymous {
+			if claims, err := ParseJWT(token, config); found && err == nil {
+				SetClaims(c, claims)
+			}
+			c.Next()
+			return
+		}
+		if !found {
+			if _, authenticated := c.Get(dependencies.CurrentUserKey); authenticated {
+				c.Next()
+				return
+			}
+			if config.Optional && mode != router.AuthRequired {
+				c.Next()
+				return
+			}
+			c.Header("WWW-Authenticate", "Bearer")
+			abortWithJSON(c, http.StatusUnauthorized, errorBody(c, i18n.M(i18n.KeyAuthorizationRequired), "authentication_error"))
+			return
+		}
+
+		claims, err := ParseJWT(token, config)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			abortWithJSON(c, http.StatusUnauthorized, errorBody(c, i18n.M(i18n.KeyInvalidToken), "authentication_error"))
+			return
+		}
+		SetClaims(c, claims)
+		c.Next()
+	}
+}
+
+// SetClaims stores verified claims as the authenticated user of the request, as JWTAuth does
+// Authentication middlewares that verify other credentials can use it too
+func SetClaims(c *gin.Context, claims map[string]interface{}) {
+	user := dependencies.CurrentUserFromClaims(claims)
+	c.Set(dependencies.ClaimsKey, claims)
+	c.Set(dependencies.CurrentUserKey, user)
+	c.Set(dependencies.UserIDKey, user.ID)
+	if user.Username != "" {
+		c.Set("username", user.Username)
+	}
+}
+
+// bearerToken returns the token of the Authorization header
+// A header without a scheme is taken as the token; other schemes (Basic) are not bearer tokens
+func bearerToken(c *gin.Context) (string, bool) {
+	header := strings.TrimSpace(c.GetHeader("Authorization"))
+	if header == "" {
+		return "", false
+	}
+	scheme, token, found := strings.Cut(header, " ")
+	if !found {
+		return header, true
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// ParseJWT verifies a compact JWT and returns its claims
+// The signature, "exp", "nbf" and, when configured, "iss" and "aud" are checked; tokens with the
+// "none" algorithm or an algorithm that does not match the key are rejected, and so is every token
+// when the key is nil or an empty secret
+func ParseJWT(token string, config JWTConfig) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	key := config.Key
+	if config.Keys != nil {
+		var err error
+		if key, err = config.Keys(header.Kid); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if key == nil || !verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil || claims == nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	if err := checkClaims(claims, config); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token
+func decodeSegment(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// checkClaims checks the registered claims of a token
+func checkClaims(claims map[string]interface{}, config JWTConfig) error {
+	now := time.Now()
+	if expires, ok := claims["exp"].(float64); ok && !now.Before(time.Unix(int64(expires), 0).Add(config.Leeway)) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if notBefore, ok := claims["nbf"].(float64); ok && now.Add(config.Leeway).Before(time.Unix(int64(notBefore), 0)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if config.Issuer != "" && claims["iss"] != config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if config.Audience != "" {
+		audiences := dependencies.ClaimList(claims["aud"])
+		for _, audience := range audiences {
+			if audience == config.Audience {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return nil
+}
+
+// SignJWT builds a compact JWT of claims, for tests and token endpoints
+func SignJWT(signer ResponseSigner, keyID string, claims map[string]interface{}) (string, error) {
+	header := map[string]string{"alg": signer.Algorithm(), "typ": "JWT"}
+	if keyID != "" {
+		header["kid"] = keyID
+	}
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(encodedClaims)
+	signature, err := signer.Sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
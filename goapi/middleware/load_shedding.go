@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+)
+
+// MaxInFlight bounds the requests served at the same time to protect downstream databases
+// A request arriving when n requests are running waits up to queueTimeout for a slot and is then
+// shed with 503 and Retry-After. Websocket and SSE requests are not counted (see ConnectionLimit)
+func MaxInFlight(n int, queueTimeout time.Duration) gin.HandlerFunc {
+	slots := make(chan struct{}, max(1, n))
+	retryAfter := strconv.Itoa(max(1, ceilSeconds(queueTimeout)))
+
+	return func(c *gin.Context) {
+		if IsLongLivedRequest(c) {
+			c.Next()
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			if !waitForSlot(c, slots, queueTimeout) {
+				c.Header("Retry-After", retryAfter)
+				c.JSON(http.StatusServiceUnavailable, errorBody(c, i18n.M(i18n.KeyOverloaded), "overloaded"))
+				c.Abort()
+				return
+			}
+		}
+		defer func() { <-slots }()
+		c.Next()
+	}
+}
+
+// waitForSlot queues a request until a slot is free, the timeout expires or the client goes away
+func waitForSlot(c *gin.Context, slots chan struct{}, timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
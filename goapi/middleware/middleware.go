@@ -3,12 +3,18 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"runtime/debug"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
 	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
 )
 
@@ -16,6 +22,8 @@ import (
 type MiddlewareFunc func() gin.HandlerFunc
 
 // CORSConfig represents CORS configuration
+// AllowOrigins accepts exact origins, "*" and one-level wildcard patterns like "https://*.example.com",
+// which match any subdomain of example.com over https but not example.com itself
 type CORSConfig struct {
 	AllowOrigins     []string
 	AllowMethods     []string
@@ -37,7 +45,15 @@ func DefaultCORSConfig() CORSConfig {
 	}
 }
 
+// RouteCORSKey holds the CORS handler of the route a request targets (see RouteCORS)
+// It is set before the global middlewares run, also for preflights of the route
+const RouteCORSKey = "goapi.route_cors"
+
+// corsHandledKey is set once a CORS policy has been applied to the request
+const corsHandledKey = "goapi.cors_handled"
+
 // CORS returns a CORS middleware
+// Requests to routes with their own policy (goapi.WithCORS) get that policy instead
 func CORS(config ...CORSConfig) gin.HandlerFunc {
 	var cfg CORSConfig
 	if len(config) > 0 {
@@ -45,59 +61,54 @@ func CORS(config ...CORSConfig) gin.HandlerFunc {
 	} else {
 		cfg = DefaultCORSConfig()
 	}
+	policy := RouteCORS(cfg)
 
 	return func(c *gin.Context) {
-		origin := c.Request.Header.Get("Origin")
-
-		// Check if origin is allowed
-		allowed := false
-		for _, allowedOrigin := range cfg.AllowOrigins {
-			if allowedOrigin == "*" || allowedOrigin == origin {
-				allowed = true
-				break
-			}
+		if c.GetBool(corsHandledKey) {
+			c.Next()
+			return
+		}
+		if routeCORS, exists := c.Get(RouteCORSKey); exists {
+			routeCORS.(gin.HandlerFunc)(c)
+			return
 		}
+		policy(c)
+	}
+}
+
+// RouteCORS returns the CORS handler of a single route, which replaces the global policy
+// It is not meant to be added as a middleware: the global CORS middleware runs it for the requests
+// and preflights of the route
+func RouteCORS(cfg CORSConfig) gin.HandlerFunc {
+	methods := strings.Join(cfg.AllowMethods, ", ")
+	allowHeaders := strings.Join(cfg.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposeHeaders, ", ")
+
+	return func(c *gin.Context) {
+		c.Set(corsHandledKey, true)
+		origin := c.Request.Header.Get("Origin")
 
-		if allowed {
-			if origin != "" {
+		// Check if origin is allowed; the response depends on it, so caches must key on it
+		if origin != "" {
+			c.Writer.Header().Add("Vary", "Origin")
+			if originAllowed(cfg.AllowOrigins, origin) {
 				c.Header("Access-Control-Allow-Origin", origin)
-			} else if len(cfg.AllowOrigins) == 1 && cfg.AllowOrigins[0] == "*" {
-				c.Header("Access-Control-Allow-Origin", "*")
 			}
+		} else if len(cfg.AllowOrigins) == 1 && cfg.AllowOrigins[0] == "*" {
+			c.Header("Access-Control-Allow-Origin", "*")
 		}
 
 		// Set other CORS headers
-		if len(cfg.AllowMethods) > 0 {
-			methods := ""
-			for i, method := range cfg.AllowMethods {
-				if i > 0 {
-					methods += ", "
-				}
-				methods += method
-			}
+		if methods != "" {
 			c.Header("Access-Control-Allow-Methods", methods)
 		}
 
-		if len(cfg.AllowHeaders) > 0 {
-			headers := ""
-			for i, header := range cfg.AllowHeaders {
-				if i > 0 {
-					headers += ", "
-				}
-				headers += header
-			}
-			c.Header("Access-Control-Allow-Headers", headers)
+		if allowHeaders != "" {
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
 		}
 
-		if len(cfg.ExposeHeaders) > 0 {
-			headers := ""
-			for i, header := range cfg.ExposeHeaders {
-				if i > 0 {
-					headers += ", "
-				}
-				headers += header
-			}
-			c.Header("Access-Control-Expose-Headers", headers)
+		if exposeHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", exposeHeaders)
 		}
 
 		if cfg.AllowCredentials {
@@ -108,8 +119,8 @@ func CORS(config ...CORSConfig) gin.HandlerFunc {
 			c.Header("Access-Control-Max-Age", fmt.Sprintf("%.0f", cfg.MaxAge.Seconds()))
 		}
 
-		// Handle preflight requests
-		if c.Request.Method == "OPTIONS" {
+		// Handle preflight requests; other OPTIONS requests reach the route
+		if IsPreflightRequest(c.Request) {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
@@ -118,10 +129,37 @@ func CORS(config ...CORSConfig) gin.HandlerFunc {
 	}
 }
 
+// originAllowed matches an origin against exact origins, "*" and wildcard patterns
+func originAllowed(allowOrigins []string, origin string) bool {
+	for _, allowedOrigin := range allowOrigins {
+		if allowedOrigin == "*" || allowedOrigin == origin {
+			return true
+		}
+		prefix, suffix, wildcard := strings.Cut(allowedOrigin, "*")
+		if !wildcard || len(origin) <= len(prefix)+len(suffix) ||
+			!strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+		// The wildcard stands for subdomain labels, never for a scheme, port or path
+		if subdomain := origin[len(prefix) : len(origin)-len(suffix)]; !strings.ContainsAny(subdomain, "/:@") &&
+			!strings.HasPrefix(subdomain, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPreflightRequest reports whether a request is a CORS preflight
+func IsPreflightRequest(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
 // RequestLogger logs HTTP requests
 func RequestLogger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("[%s] %s %s %d %s %s\n",
+		line := fmt.Sprintf("[%s] %s %s %d %s %s",
 			param.TimeStamp.Format("2006-01-02 15:04:05"),
 			param.Method,
 			param.Path,
@@ -129,130 +167,66 @@ func RequestLogger() gin.HandlerFunc {
 			param.Latency,
 			param.ClientIP,
 		)
+		if requestID, ok := param.Keys[responses.RequestIDKey].(string); ok {
+			line += " " + requestID
+		}
+		return line + "\n"
 	})
 }
 
 // ErrorHandler handles errors in a FastAPI-like manner
-func ErrorHandler() gin.HandlerFunc {
+// Responses ending with a 5xx status are passed to the reporters, with the last error of the
+// request or a *ServerError. Errors added after the handler sent its response are only reported:
+// a second body would be appended to the first one
+func ErrorHandler(reporters ...ErrorReporter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
 		// Handle errors that occurred during request processing
-		if len(c.Errors) > 0 {
-			err := c.Errors.Last()
-
-			// Check if it's a validation error
-			if validationErrors, ok := err.Err.(validation.ValidationErrors); ok {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"detail": validationErrors,
-					"type":   "validation_error",
-				})
-				return
-			}
-
-			// Handle other types of errors
-			switch err.Type {
-			case gin.ErrorTypeBind:
-				c.JSON(http.StatusBadRequest, gin.H{
-					"detail": "Invalid request format",
-					"type":   "bind_error",
-				})
-			case gin.ErrorTypePublic:
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"detail": err.Error(),
-					"type":   "public_error",
-				})
-			default:
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"detail": "Internal server error",
-					"type":   "internal_error",
-				})
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			sendError(c, c.Errors.Last())
+		}
+		if status := c.Writer.Status(); status >= http.StatusInternalServerError && len(reporters) > 0 {
+			var err error = &ServerError{Status: status}
+			if len(c.Errors) > 0 {
+				err = c.Errors.Last().Err
 			}
+			report(c, reporters, err, nil)
 		}
 	}
 }
 
-// RateLimitConfig represents rate limiting configuration
-type RateLimitConfig struct {
-	RequestsPerMinute int
-	BurstSize         int
-}
-
-// RateLimit provides basic rate limiting (simplified implementation)
-func RateLimit(config RateLimitConfig) gin.HandlerFunc {
-	// This is a simplified rate limiter
-	// In production, you'd want to use a more sophisticated implementation
-	// with Redis or similar for distributed rate limiting
-
-	requestCounts := make(map[string]int)
-	lastReset := time.Now()
-
-	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-
-		// Reset counts every minute
-		if time.Since(lastReset) > time.Minute {
-			requestCounts = make(map[string]int)
-			lastReset = time.Now()
-		}
-
-		// Check current request count
-		currentCount := requestCounts[clientIP]
-		if currentCount >= config.RequestsPerMinute {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"detail": "Rate limit exceeded",
-				"type":   "rate_limit_error",
-			})
-			c.Abort()
-			return
-		}
-
-		// Increment request count
-		requestCounts[clientIP] = currentCount + 1
-
-		c.Next()
+// sendError writes the response of an error added to the context
+func sendError(c *gin.Context, err *gin.Error) {
+	// Check if it's a validation error
+	if validationErrors, ok := err.Err.(validation.ValidationErrors); ok {
+		responses.ValidationError(c, responses.FromValidationErrors(validationErrors))
+		return
 	}
-}
 
-// Security headers middleware
-func SecurityHeaders() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("X-Content-Type-Options", "nosniff")
-		c.Header("X-Frame-Options", "DENY")
-		c.Header("X-XSS-Protection", "1; mode=block")
-		c.Header("Referrer-Policy", "strict-origin-when-cross-origin")
-
-		// CSP más permisivo para permitir que Swagger UI y ReDoc funcionen
-		csp := "default-src 'self'; " +
-			"style-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net https://fonts.googleapis.com; " +
-			"script-src 'self' 'unsafe-inline' 'unsafe-eval' https://cdn.jsdelivr.net; " +
-			"worker-src 'self' blob:; " +
-			"font-src 'self' https://fonts.gstatic.com; " +
-			"img-src 'self' data: https:; " +
-			"connect-src 'self'"
-
-		c.Header("Content-Security-Policy", csp)
-		c.Next()
+	// Errors carrying a registered error code are sent with its status
+	var codedError *responses.CodedError
+	if errors.As(err.Err, &codedError) {
+		codedError.Send(c)
+		return
 	}
-}
 
-// RequestID adds a unique request ID to each request
-func RequestID() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			requestID = generateRequestID()
-		}
-
-		c.Header("X-Request-ID", requestID)
-		c.Set("request_id", requestID)
-		c.Next()
+	// Dependencies that need a user, such as *dependencies.CurrentUser, fail with a 401
+	if errors.Is(err.Err, dependencies.ErrNotAuthenticated) {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.JSON(http.StatusUnauthorized, errorBody(c, i18n.M(i18n.KeyAuthorizationRequired), "authentication_error"))
+		return
 	}
-}
 
-// generateRequestID generates a simple request ID
-func generateRequestID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+	// Handle other types of errors
+	switch err.Type {
+	case gin.ErrorTypeBind:
+		c.JSON(http.StatusBadRequest, errorBody(c, i18n.M(i18n.KeyInvalidRequest), "bind_error"))
+	case gin.ErrorTypePublic:
+		c.JSON(http.StatusInternalServerError, errorBody(c, err.Error(), "public_error"))
+	default:
+		c.JSON(http.StatusInternalServerError, errorBody(c, i18n.M(i18n.KeyInternalError), "internal_error"))
+	}
 }
 
 // Timeout middleware adds request timeout
@@ -269,57 +243,22 @@ func Timeout(timeout time.Duration) gin.HandlerFunc {
 }
 
 // Recovery middleware with custom error handling
-func Recovery() gin.HandlerFunc {
+// Panics are passed to the reporters as a *PanicError, with the stack of the panic; the client
+// only gets the generic message, see DebugRecovery for the details. A panic after the handler
+// sent its response leaves that response as is
+func Recovery(reporters ...ErrorReporter) gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		if err, ok := recovered.(string); ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"detail": fmt.Sprintf("Internal server error: %s", err),
-				"type":   "panic_error",
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"detail": "Internal server error",
-				"type":   "panic_error",
-			})
+		if len(reporters) > 0 {
+			report(c, reporters, &PanicError{Value: recovered}, debug.Stack())
 		}
-		c.Abort()
+		abortWithJSON(c, http.StatusInternalServerError, errorBody(c, i18n.M(i18n.KeyInternalError), "panic_error"))
 	})
 }
 
-// Authentication middleware (basic implementation)
+// Authentication verifies bearer JWTs signed with an HMAC secret (HS256, HS384 or HS512)
+// See JWTAuth for public keys, issuer and audience checks, and optional authentication
 func Authentication(secretKey string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		token := c.GetHeader("Authorization")
-		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"detail": "Authorization header required",
-				"type":   "authentication_error",
-			})
-			c.Abort()
-			return
-		}
-
-		// Remove "Bearer " prefix if present
-		if len(token) > 7 && token[:7] == "Bearer " {
-			token = token[7:]
-		}
-
-		// In a real implementation, you would validate the JWT token here
-		// For now, we'll just check if it matches a simple secret
-		if token != secretKey {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"detail": "Invalid token",
-				"type":   "authentication_error",
-			})
-			c.Abort()
-			return
-		}
-
-		// Set user information in context (mock data)
-		c.Set("user_id", "user123")
-		c.Set("username", "testuser")
-		c.Next()
-	}
+	return JWTAuth(JWTConfig{Key: []byte(secretKey)})
 }
 
 // Compression middleware
@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+)
+
+// KeyFunc identifies the client a rate limit applies to; requests with the same key share a budget
+type KeyFunc func(c *gin.Context) string
+
+// KeyByIP limits every client IP separately
+func KeyByIP() KeyFunc {
+	return func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// KeyByHeader limits every value of a header separately; requests without it fall back to the IP
+// The value is not verified: a client sending a new value on every request gets a full bucket
+// each time, so use it only for headers set by a trusted proxy. For API keys, use KeyByUser after
+// the middleware verifying them (the apikeys plugin sets the "user_id" of the key owner)
+func KeyByHeader(name string) KeyFunc {
+	return func(c *gin.Context) string {
+		if value := c.GetHeader(name); value != "" {
+			return "header:" + value
+		}
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// KeyByUser limits every authenticated user separately, using the "user_id" set by the
+// authentication middleware (the JWT subject); anonymous requests fall back to the IP
+// The authentication middleware must run before the rate limit
+func KeyByUser() KeyFunc {
+	return defaultPrincipal
+}
+
+// RateLimitConfig represents rate limiting configuration
+// Limits are token buckets: clients can send BurstSize requests at once, refilled at the given rate
+type RateLimitConfig struct {
+	RequestsPerMinute int     // Refill rate, used when RequestsPerSecond is 0
+	RequestsPerSecond float64 // Refill rate
+	BurstSize         int     // Bucket size (default: the requests of one minute, at least 1)
+	Key               KeyFunc // Client identification (default KeyByIP)
+}
+
+// RateLimitResult is the state of a bucket after a request
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int           // Bucket size
+	Remaining  int           // Requests left right now
+	Reset      time.Duration // Time until the bucket is full again
+	RetryAfter time.Duration // Time until the next request is allowed, when rejected
+}
+
+// RateLimiter keeps a token bucket per key
+type RateLimiter struct {
+	rate  float64 // Tokens per second
+	burst float64
+	key   KeyFunc
+
+	mutex     sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket is the budget of a single key
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a rate limiter
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	rate := config.RequestsPerSecond
+	if rate <= 0 {
+		rate = float64(config.RequestsPerMinute) / 60
+	}
+	burst := config.BurstSize
+	if burst <= 0 {
+		burst = max(1, int(math.Ceil(rate*60)))
+	}
+	key := config.Key
+	if key == nil {
+		key = KeyByIP()
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		key:     key,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// RateLimit limits requests with a token bucket per client
+// Responses carry X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset; rejected
+// requests get 429 with Retry-After
+func RateLimit(config RateLimitConfig) gin.HandlerFunc {
+	return NewRateLimiter(config).Middleware()
+}
+
+// Middleware returns the handler enforcing the limit
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result := l.Allow(l.key(c))
+		header := c.Writer.Header()
+		header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			header.Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
+			c.JSON(http.StatusTooManyRequests, errorBody(c, i18n.M(i18n.KeyRateLimitExceeded), "rate_limit_error"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Allow takes a token from the bucket of a key
+func (l *RateLimiter) Allow(key string) RateLimitResult {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[key] = bucket
+	}
+	if l.rate > 0 {
+		bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate)
+	}
+	bucket.updated = now
+
+	result := RateLimitResult{Limit: int(l.burst)}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else if l.rate > 0 {
+		result.RetryAfter = l.refillTime(1 - bucket.tokens)
+	} else {
+		result.RetryAfter = time.Hour
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = l.refillTime(l.burst - bucket.tokens)
+	return result
+}
+
+// RateLimitBucket is the state of the bucket of a key
+type RateLimitBucket struct {
+	Key       string `json:"key"`
+	Remaining int    `json:"remaining"`
+	Limit     int    `json:"limit"`
+}
+
+// Buckets returns the buckets that are not full, emptiest first, to monitor the clients close to
+// their limit
+func (l *RateLimiter) Buckets() []RateLimitBucket {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := time.Now()
+	buckets := make([]RateLimitBucket, 0, len(l.buckets))
+	for key, bucket := range l.buckets {
+		tokens := bucket.tokens
+		if l.rate > 0 {
+			tokens = math.Min(l.burst, tokens+now.Sub(bucket.updated).Seconds()*l.rate)
+		}
+		if tokens < l.burst {
+			buckets = append(buckets, RateLimitBucket{Key: key, Remaining: int(tokens), Limit: int(l.burst)})
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Remaining != buckets[j].Remaining {
+			return buckets[i].Remaining < buckets[j].Remaining
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets
+}
+
+// sweep forgets the buckets that are full again, at most once a minute; the caller holds the mutex
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if l.rate > 0 && bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// refillTime returns the time needed to refill a number of tokens
+func (l *RateLimiter) refillTime(tokens float64) time.Duration {
+	if l.rate <= 0 || tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
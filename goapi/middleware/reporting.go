@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorReporter receives the failures of requests, to send them to an error tracker
+// err is a *PanicError for panics, with the stack where the panic happened, or the error of a 5xx
+// response, with a nil stack. Reporters run before the response is sent: keep them fast and hand
+// slow work to a goroutine, copying what it needs from c
+type ErrorReporter func(c *gin.Context, err error, stack []byte)
+
+// PanicError is the error reported for a recovered panic
+type PanicError struct {
+	Value interface{} // Value passed to panic
+}
+
+// Error implements error
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value of the panic when it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ServerError is the error reported for a 5xx response written without an error in c.Errors
+type ServerError struct {
+	Status int
+}
+
+// Error implements error
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+}
+
+// report calls the reporters; a panicking reporter does not stop the others nor the response
+func report(c *gin.Context, reporters []ErrorReporter, err error, stack []byte) {
+	for _, reporter := range reporters {
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					log.Printf("[GoAPI] error reporter panicked: %v", recovered)
+				}
+			}()
+			reporter(c, err, stack)
+		}()
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// RequestIDHeader is the default header carrying request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the incoming IDs that are kept
+const maxRequestIDLength = 128
+
+// RequestIDConfig configures the RequestID middleware
+type RequestIDConfig struct {
+	Header         string        // Header read and written (default X-Request-ID)
+	Generator      func() string // Generates new IDs (default NewRequestID, a UUIDv7)
+	IgnoreIncoming bool          // Always generate an ID, for edges not behind a trusted proxy
+}
+
+// requestIDContextKey holds the request ID in the request context
+type requestIDContextKey struct{}
+
+// RequestID adds a unique request ID to each request
+// An incoming ID is kept when it is printable and at most 128 characters long. The ID is written to
+// the response and request headers, stored in the gin context and in the request context.Context
+// (see RequestIDFromContext), and echoed as "request_id" in the error responses of the framework
+func RequestID(config ...RequestIDConfig) gin.HandlerFunc {
+	var cfg RequestIDConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Header == "" {
+		cfg.Header = RequestIDHeader
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = NewRequestID
+	}
+
+	return func(c *gin.Context) {
+		var requestID string
+		if !cfg.IgnoreIncoming {
+			requestID = c.GetHeader(cfg.Header)
+			if requestID == "" {
+				// In-process calls (Invoke, batch) keep the ID of the calling request
+				requestID = RequestIDFromContext(c.Request.Context())
+			}
+			if !validRequestID(requestID) {
+				requestID = ""
+			}
+		}
+		if requestID == "" {
+			requestID = cfg.Generator()
+		}
+
+		c.Header(cfg.Header, requestID)
+		c.Request.Header.Set(cfg.Header, requestID)
+		c.Set(responses.RequestIDKey, requestID)
+		c.Request = c.Request.WithContext(ContextWithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID of the request ("" when the RequestID middleware did not run)
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(responses.RequestIDKey)
+}
+
+// RequestIDFromContext returns the request ID stored in a context, for code that only receives
+// c.Request.Context() (repositories, outbound clients, background tasks)
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// ContextWithRequestID returns a copy of ctx carrying a request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// NewRequestID generates a UUIDv7: 48 bits of milliseconds followed by random bits, so IDs
+// sort by creation time and do not collide across instances
+func NewRequestID() string {
+	var id [16]byte
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(time.Now().UnixMilli()))
+	copy(id[:6], timestamp[2:])
+	_, _ = rand.Read(id[6:])
+	id[6] = id[6]&0x0f | 0x70 // Version 7
+	id[8] = id[8]&0x3f | 0x80 // RFC 9562 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
+// validRequestID rejects incoming IDs that could corrupt logs or headers
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// errorBody builds the {"detail", "type"} body of middleware errors, with the request ID when known
+func errorBody(c *gin.Context, detail interface{}, errorType string) gin.H {
+	body := gin.H{"detail": i18n.Localize(c, detail), "type": errorType}
+	if requestID := GetRequestID(c); requestID != "" {
+		body["request_id"] = requestID
+	}
+	return body
+}
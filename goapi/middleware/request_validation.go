@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// Context keys holding the values checked by RequestValidation
+const (
+	ValidatedQueryKey = "goapi.validated_query"
+	ValidatedBodyKey  = "goapi.validated_body"
+)
+
+// RequestValidation validates incoming requests against the parameters declared on a route
+// (WithQueryParameter, WithPathParameter, WithParameter and WithRequestBody) and rejects
+// mismatches before the handler runs, with 422 unless the API configures another status
+// The request body is left intact so the handler can still bind it
+func RequestValidation(route router.Route, validator *validation.Validator) gin.HandlerFunc {
+	var queryParams []validation.QueryParam
+	var pathParams, headerParams []router.Parameter
+	var bodyParam *router.Parameter
+
+	for i, parameter := range route.Parameters {
+		switch parameter.In {
+		case "query":
+			queryParams = append(queryParams, validation.QueryParam{
+				Name:     parameter.Name,
+				Type:     parameter.Type,
+				Required: parameter.Required,
+				Nullable: parameter.Nullable,
+			})
+		case "path":
+			pathParams = append(pathParams, parameter)
+		case "header":
+			headerParams = append(headerParams, parameter)
+		case "body":
+			bodyParam = &route.Parameters[i]
+		}
+	}
+
+	// The body of streaming routes belongs to the handler
+	if route.Streaming {
+		bodyParam = nil
+	}
+	bodyType := bodySchemaType(bodyParam)
+
+	return func(c *gin.Context) {
+		locale := i18n.Locale(c)
+		var validationErrors validation.ValidationErrors
+
+		// Path parameters are always present; only their type can be wrong
+		pathValues := make(map[string][]string, len(pathParams))
+		var pathQueryParams []validation.QueryParam
+		for _, parameter := range pathParams {
+			pathValues[parameter.Name] = []string{c.Param(parameter.Name)}
+			pathQueryParams = append(pathQueryParams, validation.QueryParam{Name: parameter.Name, Type: parameter.Type})
+		}
+		if _, err := validation.ParseQueryParamsLocale(pathValues, pathQueryParams, locale); err != nil {
+			validationErrors = append(validationErrors, locate(err.(validation.ValidationErrors), "path")...)
+		}
+
+		query, err := validation.ParseQueryParamsLocale(c.Request.URL.Query(), queryParams, locale)
+		if err != nil {
+			validationErrors = append(validationErrors, locate(err.(validation.ValidationErrors), "query")...)
+		}
+
+		for _, parameter := range headerParams {
+			if parameter.Required && c.GetHeader(parameter.Name) == "" {
+				validationErrors = append(validationErrors, validation.ValidationError{
+					Field:   parameter.Name,
+					Tag:     "required",
+					Message: validation.Message(locale, validation.MessageHeaderRequired, map[string]string{"field": parameter.Name}),
+					In:      "header",
+				})
+			}
+		}
+
+		var body interface{}
+		if bodyParam != nil {
+			var bodyErrors validation.ValidationErrors
+			body, bodyErrors = validateBody(c, bodyParam, bodyType, validator, locale)
+			validationErrors = append(validationErrors, locate(bodyErrors, "body")...)
+		}
+
+		if len(validationErrors) > 0 {
+			responses.ValidationError(c, responses.FromValidationErrors(validationErrors),
+				responses.WithDefaultValidationStatus(http.StatusUnprocessableEntity))
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedQueryKey, query)
+		if body != nil {
+			c.Set(ValidatedBodyKey, body)
+		}
+		c.Next()
+	}
+}
+
+// ValidatedQuery returns the query parameters parsed by RequestValidation
+func ValidatedQuery(c *gin.Context) map[string]interface{} {
+	if query, exists := c.Get(ValidatedQueryKey); exists {
+		if values, ok := query.(map[string]interface{}); ok {
+			return values
+		}
+	}
+	return nil
+}
+
+// NullableQuery returns a nullable query parameter (declared with WithNullableQueryParameter)
+// It reads the value parsed by RequestValidation and otherwise (validation disabled) parses the
+// raw query as T; a value that does not parse is reported as not provided
+func NullableQuery[T any](c *gin.Context, name string) validation.Nullable[T] {
+	if query := ValidatedQuery(c); query != nil {
+		if _, parsed := query[name]; parsed {
+			return validation.NullableFrom[T](query, name)
+		}
+	}
+	value, _ := validation.ParseNullable[T](c.Request.URL.Query(), name)
+	return value
+}
+
+// ValidatedBody returns the request body decoded and validated by RequestValidation
+// The value is a pointer to a new instance of the type declared with WithRequestBody
+func ValidatedBody(c *gin.Context) interface{} {
+	body, _ := c.Get(ValidatedBodyKey)
+	return body
+}
+
+// bodySchemaType returns the struct type declared for the request body, or nil
+func bodySchemaType(bodyParam *router.Parameter) reflect.Type {
+	if bodyParam == nil || bodyParam.Schema == nil {
+		return nil
+	}
+	schemaType := reflect.TypeOf(bodyParam.Schema)
+	for schemaType.Kind() == reflect.Ptr {
+		schemaType = schemaType.Elem()
+	}
+	if schemaType.Kind() != reflect.Struct {
+		return nil
+	}
+	return schemaType
+}
+
+// validateBody decodes the request body into the declared type and validates it
+func validateBody(c *gin.Context, bodyParam *router.Parameter, bodyType reflect.Type, validator *validation.Validator, locale string) (interface{}, validation.ValidationErrors) {
+	var raw []byte
+	if c.Request.Body != nil {
+		var err error
+		raw, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil, validation.ValidationErrors{bodyError(locale, validation.MessageBodyInvalid)}
+		}
+		// Restore the body so the handler can bind it again
+		c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+	}
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if bodyParam.Required {
+			return nil, validation.ValidationErrors{bodyError(locale, validation.MessageBodyRequired)}
+		}
+		return nil, nil
+	}
+
+	// Los cuerpos XML (y los sobres SOAP) se decodifican con encoding/xml
+	if validation.IsXMLContentType(c.ContentType()) {
+		if bodyType == nil {
+			if err := validation.DecodeXML(raw, new(struct{})); err != nil {
+				return nil, validation.ValidationErrors{bodyError(locale, validation.MessageBodyInvalidXML)}
+			}
+			return nil, nil
+		}
+		target := reflect.New(bodyType).Interface()
+		if err := validation.DecodeXML(raw, target); err != nil {
+			return nil, validation.ValidationErrors{bodyError(locale, validation.MessageBodyInvalidXML)}
+		}
+		if err := validator.ValidateStruct(target); err != nil {
+			return nil, validation.FormatValidationErrorsLocale(err, locale)
+		}
+		return target, nil
+	}
+
+	if bodyType == nil {
+		// Without a struct schema only the JSON syntax can be checked
+		if !json.Valid(raw) {
+			return nil, validation.ValidationErrors{bodyError(locale, validation.MessageBodyInvalid)}
+		}
+		return nil, nil
+	}
+
+	target := reflect.New(bodyType).Interface()
+	if err := validation.DecodeJSON(raw, target, IsStrictJSON(c)); err != nil {
+		var typeError *json.UnmarshalTypeError
+		if errors.As(err, &typeError) {
+			return nil, validation.ValidationErrors{validation.FromUnmarshalTypeError(typeError, locale)}
+		}
+		var unknownFields *validation.UnknownFieldsError
+		if errors.As(err, &unknownFields) {
+			return nil, unknownFields.ValidationErrors(locale)
+		}
+		return nil, validation.ValidationErrors{bodyError(locale, validation.MessageBodyInvalid)}
+	}
+
+	if err := validator.ValidateStruct(target); err != nil {
+		return nil, validation.FormatValidationErrorsLocale(err, locale)
+	}
+	return target, nil
+}
+
+// locate sets the location of validation errors
+func locate(validationErrors validation.ValidationErrors, in string) validation.ValidationErrors {
+	for i := range validationErrors {
+		validationErrors[i].In = in
+	}
+	return validationErrors
+}
+
+// bodyError builds a validation error reported on the whole body
+func bodyError(locale, key string) validation.ValidationError {
+	return validation.ValidationError{
+		Field:   "body",
+		Tag:     key,
+		Message: validation.Message(locale, key, nil),
+	}
+}
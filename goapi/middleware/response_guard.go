@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseGuard makes sure a request gets a single response
+// Once the headers are sent, a new status (c.JSON, c.String, c.Status...) starts a second
+// response: its status and body are dropped, instead of being appended to the first body while
+// Gin logs "headers were already written". Debug mode logs the dropped response once. Streaming
+// writes without a new status go through. GoAPI installs it before Recovery
+func ResponseGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer = &guardedWriter{ResponseWriter: c.Writer, c: c}
+		c.Next()
+	}
+}
+
+// guardedWriter drops the responses written after the first one
+type guardedWriter struct {
+	gin.ResponseWriter
+	c       *gin.Context
+	discard bool // A second response started: its writes are dropped
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *guardedWriter) WriteHeader(code int) {
+	if code <= 0 {
+		// Gin pasa -1 al renderizar sin cambiar el estado (SSE)
+		return
+	}
+	if w.ResponseWriter.Written() {
+		if !w.discard && gin.IsDebugging() {
+			log.Printf("[GoAPI] %s %s: response already sent with status %d, dropping a second response with status %d",
+				w.c.Request.Method, w.c.Request.URL.Path, w.ResponseWriter.Status(), code)
+		}
+		w.discard = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter
+func (w *guardedWriter) Write(data []byte) (int, error) {
+	if w.discard {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter
+func (w *guardedWriter) WriteString(s string) (int, error) {
+	if w.discard {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController
+func (w *guardedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// abortWithJSON writes an error response, unless the handler already sent one, and aborts
+// The handler response is kept as is: a second body would corrupt it
+func abortWithJSON(c *gin.Context, status int, body interface{}) {
+	if !c.Writer.Written() {
+		c.JSON(status, body)
+	}
+	c.Abort()
+}
@@ -0,0 +1,393 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// ResponseValidationConfig configures the validation of handler responses
+type ResponseValidationConfig struct {
+	// FailOnMismatch replaces mismatching responses with a 500 describing the drift
+	// When false mismatches are only logged
+	FailOnMismatch bool
+	// Logger receives the mismatch reports (default: log.Printf)
+	Logger func(format string, args ...interface{})
+}
+
+// ResponseValidation checks the JSON responses of a route against its declared response models
+// (WithResponseModel) and reports undocumented, missing or mistyped fields
+// Responses are buffered, so this middleware is meant for development only
+func ResponseValidation(route router.Route, validator *validation.Validator, config ResponseValidationConfig) gin.HandlerFunc {
+	logger := config.Logger
+	if logger == nil {
+		logger = log.Printf
+	}
+
+	return func(c *gin.Context) {
+		if len(route.ResponseModels) == 0 {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.streaming {
+			return
+		}
+
+		model, declared := route.ResponseModels[writer.status]
+		if declared && isJSONContentType(writer.Header().Get("Content-Type")) {
+			if mismatches := ValidateResponseBody(model, writer.body.Bytes(), validator); len(mismatches) > 0 {
+				logger("[GoAPI] response of %s %s (%d) does not match its declared model:\n  - %s",
+					route.Method, route.Path, writer.status, strings.Join(mismatches, "\n  - "))
+
+				if config.FailOnMismatch {
+					writer.Header().Del("Content-Length")
+					c.JSON(http.StatusInternalServerError, errorBody(c, mismatches, "response_validation_error"))
+					return
+				}
+			}
+		}
+
+		writer.ResponseWriter.WriteHeader(writer.status)
+		_, _ = writer.ResponseWriter.Write(writer.body.Bytes())
+	}
+}
+
+// ValidateResponseBody compares a JSON payload with a response model
+// It returns one message per undocumented, missing, mistyped or returned write-only field
+// (goapi:"writeonly"); when the structure matches, the validation tags of the model are checked
+// too. An empty result means the payload matches
+func ValidateResponseBody(model interface{}, body []byte, validator *validation.Validator) []string {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return []string{fmt.Sprintf("body is not valid JSON: %v", err)}
+	}
+
+	modelType := reflect.TypeOf(model)
+	mismatches := compareJSON("$", modelType, payload)
+	if len(mismatches) > 0 || validator == nil {
+		return mismatches
+	}
+
+	for modelType != nil && modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	if modelType != nil && modelType.Kind() == reflect.Struct {
+		target := reflect.New(modelType).Interface()
+		if err := json.Unmarshal(body, target); err == nil {
+			for _, validationError := range validation.FormatValidationErrors(validator.ValidateStruct(target)) {
+				mismatches = append(mismatches, validationError.Message)
+			}
+		}
+	}
+	return mismatches
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// compareJSON compares a decoded JSON value with the Go type that should have produced it
+func compareJSON(path string, expected reflect.Type, value interface{}) []string {
+	if expected == nil {
+		return nil
+	}
+	if expected.Kind() == reflect.Ptr {
+		if value == nil {
+			return nil
+		}
+		return compareJSON(path, expected.Elem(), value)
+	}
+	if expected.Implements(marshalerType) || reflect.PointerTo(expected).Implements(marshalerType) {
+		if expected == timeType {
+			return expectKind(path, "string", value)
+		}
+		// Custom encodings cannot be predicted from the type
+		return nil
+	}
+
+	switch expected.Kind() {
+	case reflect.Interface:
+		return nil
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object, got %s", path, jsonKind(value))}
+		}
+		return compareObject(path, expected, object)
+	case reflect.Map:
+		if value == nil {
+			return nil
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object, got %s", path, jsonKind(value))}
+		}
+		var mismatches []string
+		for _, key := range sortedKeys(object) {
+			mismatches = append(mismatches, compareJSON(path+"."+key, expected.Elem(), object[key])...)
+		}
+		return mismatches
+	case reflect.Slice, reflect.Array:
+		if expected.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string
+			return expectKind(path, "string", value)
+		}
+		if value == nil && expected.Kind() == reflect.Slice {
+			return nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array, got %s", path, jsonKind(value))}
+		}
+		var mismatches []string
+		for i, item := range items {
+			mismatches = append(mismatches, compareJSON(fmt.Sprintf("%s[%d]", path, i), expected.Elem(), item)...)
+		}
+		return mismatches
+	case reflect.String:
+		return expectKind(path, "string", value)
+	case reflect.Bool:
+		return expectKind(path, "boolean", value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return expectKind(path, "number", value)
+	}
+	return nil
+}
+
+// compareObject compares a JSON object with the exported fields of a struct
+func compareObject(path string, expected reflect.Type, object map[string]interface{}) []string {
+	var mismatches []string
+	documented := make(map[string]bool)
+
+	for _, field := range jsonFields(expected) {
+		documented[field.name] = true
+		value, present := object[field.name]
+		if field.writeOnly {
+			// Secrets such as passwords may be zeroed, never returned
+			if present && value != nil && value != "" {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s: write-only field returned", path, field.name))
+			}
+			continue
+		}
+		if !present {
+			if !field.optional {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s: missing field", path, field.name))
+			}
+			continue
+		}
+		if field.asString {
+			mismatches = append(mismatches, expectKind(path+"."+field.name, "string", value)...)
+			continue
+		}
+		mismatches = append(mismatches, compareJSON(path+"."+field.name, field.fieldType, value)...)
+	}
+
+	for _, key := range sortedKeys(object) {
+		if !documented[key] {
+			mismatches = append(mismatches, fmt.Sprintf("%s.%s: undocumented field", path, key))
+		}
+	}
+	return mismatches
+}
+
+// jsonField describes how a struct field is encoded
+type jsonField struct {
+	name      string
+	fieldType reflect.Type
+	optional  bool // omitempty or omitzero option
+	asString  bool // ",string" option
+	writeOnly bool // goapi:"writeonly" option
+}
+
+// jsonFields lists the fields encoding/json produces for a struct, flattening embedded structs
+func jsonFields(structType reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, jsonField{
+			name:      name,
+			fieldType: field.Type,
+			optional:  hasOption(options, "omitempty") || hasOption(options, "omitzero"),
+			asString:  hasOption(options, "string"),
+			writeOnly: hasOption(field.Tag.Get("goapi"), "writeonly"),
+		})
+	}
+	return fields
+}
+
+// hasOption reports whether a json tag option list contains an option
+func hasOption(options, option string) bool {
+	for _, current := range strings.Split(options, ",") {
+		if current == option {
+			return true
+		}
+	}
+	return false
+}
+
+// expectKind checks the kind of a JSON value
+func expectKind(path, kind string, value interface{}) []string {
+	if actual := jsonKind(value); actual != kind {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, kind, actual)}
+	}
+	return nil
+}
+
+// jsonKind names the kind of a decoded JSON value
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// sortedKeys returns the keys of a JSON object in a stable order
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isJSONContentType reports whether a content type carries JSON
+func isJSONContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// bufferedResponseWriter holds the response until the handler returns so it can be inspected
+// Flushing (streaming responses) switches it to pass-through mode
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	status    int
+	written   bool
+	streaming bool
+}
+
+// WriteHeader records the status code
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	w.status = statusCode
+}
+
+// WriteHeaderNow marks the response as written without sending it
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+// Write buffers the body
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	w.written = true
+	return w.body.Write(data)
+}
+
+// WriteString buffers the body
+func (w *bufferedResponseWriter) WriteString(data string) (int, error) {
+	return w.Write([]byte(data))
+}
+
+// Status returns the recorded status code
+func (w *bufferedResponseWriter) Status() int {
+	if w.streaming {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+// Size returns the buffered body size
+func (w *bufferedResponseWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Written reports whether the handler produced a response
+func (w *bufferedResponseWriter) Written() bool {
+	if w.streaming {
+		return w.ResponseWriter.Written()
+	}
+	return w.written
+}
+
+// Flush sends the buffered data and streams the rest of the response unchecked
+func (w *bufferedResponseWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		w.ResponseWriter.WriteHeader(w.status)
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+		w.body.Reset()
+	}
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,321 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// securityHeadersKey holds the security headers configuration of a request, for route overrides
+const securityHeadersKey = "goapi.security_headers"
+
+// Content-Security-Policy source keywords
+const (
+	CSPSelf          = "'self'"
+	CSPNone          = "'none'"
+	CSPUnsafeInline  = "'unsafe-inline'"
+	CSPUnsafeEval    = "'unsafe-eval'"
+	CSPStrictDynamic = "'strict-dynamic'"
+)
+
+// SecurityHeadersConfig configures the SecurityHeaders middleware
+// Empty strings and nil policies omit the corresponding header
+type SecurityHeadersConfig struct {
+	ContentTypeOptions      string              // X-Content-Type-Options
+	FrameOptions            string              // X-Frame-Options: "DENY" or "SAMEORIGIN"
+	XSSProtection           string              // X-XSS-Protection
+	ReferrerPolicy          string              // Referrer-Policy
+	ContentSecurityPolicy   *CSP                // Content-Security-Policy
+	CSPReportOnly           bool                // Send the policy as Content-Security-Policy-Report-Only
+	HSTS                    *HSTSConfig         // Strict-Transport-Security, sent only on HTTPS requests
+	PermissionsPolicy       map[string][]string // Permissions-Policy: feature => allowed origins ("self", "*" or URLs; none = disabled)
+	CrossOriginOpenerPolicy string              // Cross-Origin-Opener-Policy
+}
+
+// HSTSConfig configures Strict-Transport-Security
+// Preloading requires a MaxAge of at least one year and IncludeSubDomains
+type HSTSConfig struct {
+	MaxAge            time.Duration
+	IncludeSubDomains bool
+	Preload           bool
+}
+
+// DefaultSecurityHeadersConfig returns the headers applied by default, without HSTS
+// The CSP is permissive enough for Swagger UI and ReDoc: it allows their CDN assets and inline scripts
+func DefaultSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		ContentTypeOptions:    "nosniff",
+		FrameOptions:          "DENY",
+		XSSProtection:         "1; mode=block",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		ContentSecurityPolicy: DefaultCSP(),
+	}
+}
+
+// DefaultCSP returns the default Content-Security-Policy
+func DefaultCSP() *CSP {
+	return NewCSP().
+		DefaultSrc(CSPSelf).
+		StyleSrc(CSPSelf, CSPUnsafeInline, "https://cdn.jsdelivr.net", "https://fonts.googleapis.com").
+		ScriptSrc(CSPSelf, CSPUnsafeInline, CSPUnsafeEval, "https://cdn.jsdelivr.net").
+		WorkerSrc(CSPSelf, "blob:").
+		FontSrc(CSPSelf, "https://fonts.gstatic.com").
+		ImgSrc(CSPSelf, "data:", "https:").
+		ConnectSrc(CSPSelf)
+}
+
+// SecurityHeaders adds security headers to every response
+// Routes can adjust them with SecurityHeadersOverride (goapi.WithSecurityHeaders)
+func SecurityHeaders(config ...SecurityHeadersConfig) gin.HandlerFunc {
+	cfg := DefaultSecurityHeadersConfig()
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	return func(c *gin.Context) {
+		c.Set(securityHeadersKey, cfg)
+		cfg.apply(c)
+		c.Next()
+	}
+}
+
+// SecurityHeadersOverride returns a route middleware adjusting the security headers of the route,
+// starting from the configuration of the global middleware
+func SecurityHeadersOverride(override func(config *SecurityHeadersConfig)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := DefaultSecurityHeadersConfig()
+		if value, exists := c.Get(securityHeadersKey); exists {
+			cfg = value.(SecurityHeadersConfig)
+			clearSecurityHeaders(c)
+		}
+		cfg = cfg.clone()
+		override(&cfg)
+		c.Set(securityHeadersKey, cfg)
+		cfg.apply(c)
+		c.Next()
+	}
+}
+
+// apply writes the configured headers
+func (config SecurityHeadersConfig) apply(c *gin.Context) {
+	setHeader(c, "X-Content-Type-Options", config.ContentTypeOptions)
+	setHeader(c, "X-Frame-Options", config.FrameOptions)
+	setHeader(c, "X-XSS-Protection", config.XSSProtection)
+	setHeader(c, "Referrer-Policy", config.ReferrerPolicy)
+	setHeader(c, "Cross-Origin-Opener-Policy", config.CrossOriginOpenerPolicy)
+	setHeader(c, "Permissions-Policy", permissionsPolicy(config.PermissionsPolicy))
+	if config.ContentSecurityPolicy != nil {
+		setHeader(c, config.cspHeader(), config.ContentSecurityPolicy.String())
+	}
+	if config.HSTS != nil && isHTTPS(c) {
+		setHeader(c, "Strict-Transport-Security", config.HSTS.String())
+	}
+}
+
+// clearSecurityHeaders removes the headers written by apply, before an override writes its own
+func clearSecurityHeaders(c *gin.Context) {
+	header := c.Writer.Header()
+	for _, name := range []string{
+		"X-Content-Type-Options", "X-Frame-Options", "X-XSS-Protection", "Referrer-Policy",
+		"Cross-Origin-Opener-Policy", "Permissions-Policy", "Strict-Transport-Security",
+		"Content-Security-Policy", "Content-Security-Policy-Report-Only",
+	} {
+		header.Del(name)
+	}
+}
+
+// clone copies the config so an override does not modify the global one
+func (config SecurityHeadersConfig) clone() SecurityHeadersConfig {
+	if config.ContentSecurityPolicy != nil {
+		config.ContentSecurityPolicy = config.ContentSecurityPolicy.Clone()
+	}
+	if config.HSTS != nil {
+		hsts := *config.HSTS
+		config.HSTS = &hsts
+	}
+	if config.PermissionsPolicy != nil {
+		policy := make(map[string][]string, len(config.PermissionsPolicy))
+		for feature, origins := range config.PermissionsPolicy {
+			policy[feature] = origins
+		}
+		config.PermissionsPolicy = policy
+	}
+	return config
+}
+
+// cspHeader returns the name of the CSP header
+func (config SecurityHeadersConfig) cspHeader() string {
+	if config.CSPReportOnly {
+		return "Content-Security-Policy-Report-Only"
+	}
+	return "Content-Security-Policy"
+}
+
+// String renders the Strict-Transport-Security value
+func (hsts HSTSConfig) String() string {
+	value := fmt.Sprintf("max-age=%d", int64(hsts.MaxAge.Seconds()))
+	if hsts.IncludeSubDomains {
+		value += "; includeSubDomains"
+	}
+	if hsts.Preload {
+		value += "; preload"
+	}
+	return value
+}
+
+// CSP builds a Content-Security-Policy; directives keep the order they were first set in
+type CSP struct {
+	directives []cspDirective
+}
+
+// cspDirective is a directive with its sources
+type cspDirective struct {
+	name    string
+	sources []string
+}
+
+// NewCSP creates an empty policy
+func NewCSP() *CSP {
+	return &CSP{}
+}
+
+// Directive sets a directive, replacing its previous sources
+func (p *CSP) Directive(name string, sources ...string) *CSP {
+	for i := range p.directives {
+		if p.directives[i].name == name {
+			p.directives[i].sources = sources
+			return p
+		}
+	}
+	p.directives = append(p.directives, cspDirective{name: name, sources: sources})
+	return p
+}
+
+// Append adds sources to a directive, creating it when missing
+func (p *CSP) Append(name string, sources ...string) *CSP {
+	for i := range p.directives {
+		if p.directives[i].name == name {
+			p.directives[i].sources = append(p.directives[i].sources[:len(p.directives[i].sources):len(p.directives[i].sources)], sources...)
+			return p
+		}
+	}
+	return p.Directive(name, sources...)
+}
+
+// Remove deletes a directive
+func (p *CSP) Remove(name string) *CSP {
+	for i := range p.directives {
+		if p.directives[i].name == name {
+			p.directives = append(p.directives[:i:i], p.directives[i+1:]...)
+			break
+		}
+	}
+	return p
+}
+
+// DefaultSrc sets default-src
+func (p *CSP) DefaultSrc(sources ...string) *CSP { return p.Directive("default-src", sources...) }
+
+// ScriptSrc sets script-src
+func (p *CSP) ScriptSrc(sources ...string) *CSP { return p.Directive("script-src", sources...) }
+
+// StyleSrc sets style-src
+func (p *CSP) StyleSrc(sources ...string) *CSP { return p.Directive("style-src", sources...) }
+
+// ImgSrc sets img-src
+func (p *CSP) ImgSrc(sources ...string) *CSP { return p.Directive("img-src", sources...) }
+
+// FontSrc sets font-src
+func (p *CSP) FontSrc(sources ...string) *CSP { return p.Directive("font-src", sources...) }
+
+// ConnectSrc sets connect-src
+func (p *CSP) ConnectSrc(sources ...string) *CSP { return p.Directive("connect-src", sources...) }
+
+// WorkerSrc sets worker-src
+func (p *CSP) WorkerSrc(sources ...string) *CSP { return p.Directive("worker-src", sources...) }
+
+// MediaSrc sets media-src
+func (p *CSP) MediaSrc(sources ...string) *CSP { return p.Directive("media-src", sources...) }
+
+// ObjectSrc sets object-src
+func (p *CSP) ObjectSrc(sources ...string) *CSP { return p.Directive("object-src", sources...) }
+
+// FrameSrc sets frame-src
+func (p *CSP) FrameSrc(sources ...string) *CSP { return p.Directive("frame-src", sources...) }
+
+// FrameAncestors sets frame-ancestors, the pages allowed to embed the response
+func (p *CSP) FrameAncestors(sources ...string) *CSP {
+	return p.Directive("frame-ancestors", sources...)
+}
+
+// BaseURI sets base-uri
+func (p *CSP) BaseURI(sources ...string) *CSP { return p.Directive("base-uri", sources...) }
+
+// FormAction sets form-action
+func (p *CSP) FormAction(sources ...string) *CSP { return p.Directive("form-action", sources...) }
+
+// ReportURI sets report-uri, where browsers post violations
+func (p *CSP) ReportURI(uri string) *CSP { return p.Directive("report-uri", uri) }
+
+// ReportTo sets report-to, naming a Reporting-Endpoints group
+func (p *CSP) ReportTo(group string) *CSP { return p.Directive("report-to", group) }
+
+// UpgradeInsecureRequests adds upgrade-insecure-requests
+func (p *CSP) UpgradeInsecureRequests() *CSP { return p.Directive("upgrade-insecure-requests") }
+
+// Clone copies the policy
+func (p *CSP) Clone() *CSP {
+	clone := &CSP{directives: make([]cspDirective, len(p.directives))}
+	for i, directive := range p.directives {
+		clone.directives[i] = cspDirective{name: directive.name, sources: append([]string(nil), directive.sources...)}
+	}
+	return clone
+}
+
+// String renders the policy
+func (p *CSP) String() string {
+	parts := make([]string, 0, len(p.directives))
+	for _, directive := range p.directives {
+		parts = append(parts, strings.TrimSpace(directive.name+" "+strings.Join(directive.sources, " ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// permissionsPolicy renders a Permissions-Policy, sorted by feature: camera=(), geolocation=(self "https://maps.example.com")
+func permissionsPolicy(policy map[string][]string) string {
+	features := make([]string, 0, len(policy))
+	for feature := range policy {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	parts := make([]string, 0, len(features))
+	for _, feature := range features {
+		origins := make([]string, 0, len(policy[feature]))
+		for _, origin := range policy[feature] {
+			switch origin {
+			case "self", "*", "src":
+				origins = append(origins, origin)
+			default:
+				origins = append(origins, `"`+origin+`"`)
+			}
+		}
+		parts = append(parts, feature+"=("+strings.Join(origins, " ")+")")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// setHeader writes a header unless the value is empty
+func setHeader(c *gin.Context, name, value string) {
+	if value != "" {
+		c.Header(name, value)
+	}
+}
+
+// isHTTPS reports whether the client connected over HTTPS, directly or through a proxy
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
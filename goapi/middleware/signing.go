@@ -0,0 +1,383 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// Headers set by ResponseSigning
+const (
+	ContentDigestHeader    = "Content-Digest"
+	DefaultSignatureHeader = "X-JWS-Signature"
+)
+
+// ErrInvalidSignature is returned when a response signature or digest does not match its body
+var ErrInvalidSignature = errors.New("invalid response signature")
+
+// MinHMACSecretSize is the shortest HMAC secret accepted for signing: the size of a SHA-256 hash
+const MinHMACSecretSize = 32
+
+// ErrWeakSecret is returned for HMAC secrets shorter than MinHMACSecretSize, such as the empty
+// secret of an unset environment variable
+var ErrWeakSecret = fmt.Errorf("HMAC secret shorter than %d bytes", MinHMACSecretSize)
+
+// CheckHMACSecret returns ErrWeakSecret for secrets shorter than MinHMACSecretSize
+func CheckHMACSecret(secret []byte) error {
+	if len(secret) < MinHMACSecretSize {
+		return ErrWeakSecret
+	}
+	return nil
+}
+
+// ResponseSigner signs the JWS signing input of a response
+type ResponseSigner interface {
+	Algorithm() string // JWS "alg" header parameter
+	Sign(signingInput []byte) ([]byte, error)
+}
+
+// ResponseSigningConfig configures the signing of response bodies
+type ResponseSigningConfig struct {
+	// Digest adds a Content-Digest header (RFC 9530); always on when Signer is nil
+	Digest bool
+	// DigestAlgorithm is "sha-256" (default) or "sha-512"
+	DigestAlgorithm string
+	// Signer adds a detached JWS (RFC 7515, appendix F) of the body
+	Signer ResponseSigner
+	// KeyID is sent as the "kid" header parameter so consumers can pick the verification key
+	KeyID string
+	// SignatureHeader carries the detached JWS (default "X-JWS-Signature")
+	SignatureHeader string
+	// Logger receives signing failures (default: log.Printf)
+	Logger func(format string, args ...interface{})
+}
+
+// ResponseSigning signs response bodies so downstream consumers can verify their integrity
+// Responses are buffered until the handler returns; streamed (flushed) responses are sent unsigned
+// If signing fails the response is replaced by a 500 rather than sent without its signature
+func ResponseSigning(config ResponseSigningConfig) gin.HandlerFunc {
+	if config.Signer == nil {
+		config.Digest = true
+	}
+	if config.DigestAlgorithm == "" {
+		config.DigestAlgorithm = "sha-256"
+	}
+	if config.SignatureHeader == "" {
+		config.SignatureHeader = DefaultSignatureHeader
+	}
+	if config.Logger == nil {
+		config.Logger = log.Printf
+	}
+	if _, err := newDigestHash(config.DigestAlgorithm); err != nil {
+		panic(err)
+	}
+
+	return func(c *gin.Context) {
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.streaming {
+			return
+		}
+
+		body := writer.body.Bytes()
+		if config.Digest {
+			digest, _ := ContentDigest(config.DigestAlgorithm, body)
+			writer.Header().Set(ContentDigestHeader, digest)
+		}
+		if config.Signer != nil {
+			signature, err := SignDetached(config.Signer, config.KeyID, body)
+			if err != nil {
+				config.Logger("[GoAPI] error signing response of %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+				writer.Header().Del("Content-Length")
+				writer.Header().Del(ContentDigestHeader)
+				responses.InternalServerError(c, "Error signing response")
+				return
+			}
+			writer.Header().Set(config.SignatureHeader, signature)
+		}
+
+		writer.ResponseWriter.WriteHeader(writer.status)
+		_, _ = writer.ResponseWriter.Write(body)
+	}
+}
+
+// ContentDigest builds the Content-Digest header value of a body: sha-256=:<base64>:
+func ContentDigest(algorithm string, body []byte) (string, error) {
+	digestHash, err := newDigestHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	digestHash.Write(body)
+	return algorithm + "=:" + base64.StdEncoding.EncodeToString(digestHash.Sum(nil)) + ":", nil
+}
+
+// VerifyContentDigest checks a Content-Digest header value against a body
+// Every supported digest of the header must match; unknown algorithms are ignored
+func VerifyContentDigest(header string, body []byte) error {
+	verified := false
+	for _, member := range strings.Split(header, ",") {
+		algorithm, value, found := strings.Cut(strings.TrimSpace(member), "=")
+		if !found {
+			continue
+		}
+		algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+		if _, err := newDigestHash(algorithm); err != nil {
+			continue
+		}
+		expected, _ := ContentDigest(algorithm, body)
+		if !hmac.Equal([]byte(algorithm+"="+strings.TrimSpace(value)), []byte(expected)) {
+			return fmt.Errorf("%w: %s digest mismatch", ErrInvalidSignature, algorithm)
+		}
+		verified = true
+	}
+	if !verified {
+		return fmt.Errorf("%w: no supported digest", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// newDigestHash returns the hash of a Content-Digest algorithm
+func newDigestHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha-256":
+		return sha256.New(), nil
+	case "sha-512":
+		return sha512.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported digest algorithm %q", algorithm)
+}
+
+// SignDetached builds a detached JWS of a payload: <protected header>..<signature>
+func SignDetached(signer ResponseSigner, keyID string, payload []byte) (string, error) {
+	header := map[string]string{"alg": signer.Algorithm()}
+	if keyID != "" {
+		header["kid"] = keyID
+	}
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+
+	protected := base64.RawURLEncoding.EncodeToString(encodedHeader)
+	signingInput := protected + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := signer.Sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return protected + ".." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyDetached checks a detached JWS against a payload
+// key is the HMAC secret ([]byte), *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey
+func VerifyDetached(signature string, payload []byte, key interface{}) error {
+	parts := strings.Split(signature, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return fmt.Errorf("%w: malformed detached JWS", ErrInvalidSignature)
+	}
+
+	encodedHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(encodedHeader, &header); err != nil {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+	rawSignature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+
+	signingInput := []byte(parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload))
+	if !verifySignature(header.Alg, key, signingInput, rawSignature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifySignature checks a JWS signature with the key type matching its algorithm
+func verifySignature(algorithm string, key interface{}, signingInput, signature []byte) bool {
+	switch key := key.(type) {
+	case []byte:
+		// Un secreto vacío valida las firmas de cualquiera
+		hashFunc, ok := jwsHash(algorithm, "HS")
+		if !ok || len(key) == 0 {
+			return false
+		}
+		mac := hmac.New(hashFunc.New, key)
+		mac.Write(signingInput)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case *rsa.PublicKey:
+		hashFunc, ok := jwsHash(algorithm, "RS")
+		if !ok {
+			return false
+		}
+		return rsa.VerifyPKCS1v15(key, hashFunc, digest(hashFunc, signingInput), signature) == nil
+	case *ecdsa.PublicKey:
+		hashFunc, ok := jwsHash(algorithm, "ES")
+		if !ok || hashFunc != curveHash(key.Curve) {
+			return false
+		}
+		size := curveSize(key.Curve)
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest(hashFunc, signingInput), r, s)
+	case ed25519.PublicKey:
+		return algorithm == "EdDSA" && ed25519.Verify(key, signingInput, signature)
+	}
+	return false
+}
+
+// jwsHash maps a JWS algorithm of a family ("HS", "RS", "ES") to its hash
+func jwsHash(algorithm, family string) (crypto.Hash, bool) {
+	if !strings.HasPrefix(algorithm, family) {
+		return 0, false
+	}
+	switch strings.TrimPrefix(algorithm, family) {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// digest hashes data with a crypto.Hash
+func digest(hashFunc crypto.Hash, data []byte) []byte {
+	hasher := hashFunc.New()
+	hasher.Write(data)
+	return hasher.Sum(nil)
+}
+
+// curveHash returns the hash JWS pairs with an elliptic curve
+func curveHash(curve elliptic.Curve) crypto.Hash {
+	switch curve.Params().BitSize {
+	case 384:
+		return crypto.SHA384
+	case 521:
+		return crypto.SHA512
+	}
+	return crypto.SHA256
+}
+
+// curveSize returns the byte length of a coordinate of an elliptic curve
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+// hmacSigner signs with HS256
+type hmacSigner struct {
+	secret []byte
+}
+
+// NewHMACSigner creates an HS256 signer; consumers verify with the same secret
+// A secret shorter than MinHMACSecretSize makes every signature fail with ErrWeakSecret
+func NewHMACSigner(secret []byte) ResponseSigner {
+	return &hmacSigner{secret: secret}
+}
+
+func (s *hmacSigner) Algorithm() string { return "HS256" }
+
+func (s *hmacSigner) Sign(signingInput []byte) ([]byte, error) {
+	if err := CheckHMACSecret(s.secret); err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(signingInput)
+	return mac.Sum(nil), nil
+}
+
+// rsaSigner signs with RS256
+type rsaSigner struct {
+	key *rsa.PrivateKey
+}
+
+// NewRSASigner creates an RS256 (RSASSA-PKCS1-v1_5 with SHA-256) signer
+func NewRSASigner(key *rsa.PrivateKey) ResponseSigner {
+	return &rsaSigner{key: key}
+}
+
+func (s *rsaSigner) Algorithm() string { return "RS256" }
+
+func (s *rsaSigner) Sign(signingInput []byte) ([]byte, error) {
+	return rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest(crypto.SHA256, signingInput))
+}
+
+// ecdsaSigner signs with ES256, ES384 or ES512 depending on the curve
+type ecdsaSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewECDSASigner creates an ECDSA signer: ES256 (P-256), ES384 (P-384) or ES512 (P-521)
+func NewECDSASigner(key *ecdsa.PrivateKey) ResponseSigner {
+	return &ecdsaSigner{key: key}
+}
+
+func (s *ecdsaSigner) Algorithm() string {
+	switch curveHash(s.key.Curve) {
+	case crypto.SHA384:
+		return "ES384"
+	case crypto.SHA512:
+		return "ES512"
+	}
+	return "ES256"
+}
+
+func (s *ecdsaSigner) Sign(signingInput []byte) ([]byte, error) {
+	r, sValue, err := ecdsa.Sign(rand.Reader, s.key, digest(curveHash(s.key.Curve), signingInput))
+	if err != nil {
+		return nil, err
+	}
+
+	// JWS uses the fixed-size r || s encoding instead of ASN.1
+	size := curveSize(s.key.Curve)
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	sValue.FillBytes(signature[size:])
+	return signature, nil
+}
+
+// ed25519Signer signs with EdDSA
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewEd25519Signer creates an EdDSA (Ed25519) signer
+func NewEd25519Signer(key ed25519.PrivateKey) ResponseSigner {
+	return &ed25519Signer{key: key}
+}
+
+func (s *ed25519Signer) Algorithm() string { return "EdDSA" }
+
+func (s *ed25519Signer) Sign(signingInput []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, signingInput), nil
+}
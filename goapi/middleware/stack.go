@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+)
+
+// StackFrame is a function call of a stack trace
+type StackFrame struct {
+	Function string `json:"function"` // Package path and name: "github.com/acme/app/users.(*Handler).Get"
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// ParseStack parses the output of debug.Stack, the most recent call first
+// For stacks taken while recovering, the frames newer than the panic (the recovery itself) are
+// left out, as are the frames of the runtime
+//
+//	goroutine 1 [running]:
+//	main.handler(...)
+//		/app/main.go:42 +0x1d
+func ParseStack(stack []byte) []StackFrame {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	var frames []StackFrame
+	for i := 1; i+1 < len(lines); i += 2 {
+		function := strings.TrimSpace(lines[i])
+		location := strings.TrimSpace(lines[i+1])
+		if created, found := strings.CutPrefix(function, "created by "); found {
+			function, _, _ = strings.Cut(created, " in goroutine ")
+		} else if open := strings.LastIndex(function, "("); open > 0 {
+			function = function[:open]
+		}
+		if space := strings.LastIndex(location, " +0x"); space > 0 {
+			location = location[:space]
+		}
+		colon := strings.LastIndex(location, ":")
+		if colon < 0 {
+			continue
+		}
+		if function == "panic" {
+			// Las llamadas más recientes que el pánico son las de la recuperación
+			frames = frames[:0]
+			continue
+		}
+		if strings.HasPrefix(function, "runtime.") || strings.HasPrefix(function, "runtime/debug.") {
+			continue
+		}
+		line, _ := strconv.Atoi(location[colon+1:])
+		frames = append(frames, StackFrame{Function: function, File: location[:colon], Line: line})
+	}
+	return frames
+}
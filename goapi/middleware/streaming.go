@@ -0,0 +1,13 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// StreamingKey is set on requests to routes declared with WithStreaming
+const StreamingKey = "goapi.streaming"
+
+// IsStreaming reports whether the request targets a streaming route
+// Middlewares that read, buffer or limit the request body (logging bodies, idempotency keys,
+// body size limits) must leave it untouched so the handler can consume it as it arrives
+func IsStreaming(c *gin.Context) bool {
+	return c.GetBool(StreamingKey)
+}
@@ -0,0 +1,12 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// StrictJSONKey is set on requests to routes that reject unknown JSON properties
+// (APIConfig.StrictJSON or WithStrictJSON)
+const StrictJSONKey = "goapi.strict_json"
+
+// IsStrictJSON reports whether the JSON body of the request must match its declared type exactly
+func IsStrictJSON(c *gin.Context) bool {
+	return c.GetBool(StrictJSONKey)
+}
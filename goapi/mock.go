@@ -0,0 +1,326 @@
+package goapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// MockStatusPreference selects the mocked response of a request, as in Prefer: code=404
+const MockStatusPreference = "code"
+
+// RunMock runs the server answering every route with an example of its documented response
+// instead of calling its handler, so clients can integrate against the declared contract before
+// the implementation exists. Bodies come from the declared response models: the model value,
+// the example tags of its fields, or a placeholder of each type. The lowest 2xx status is
+// answered by default; the header Prefer: code=404 selects another declared status
+// Global middlewares and request validation still run; route middlewares and startup hooks do not
+func (a *GoAPI) RunMock(addr ...string) error {
+	serverAddr := ":8080"
+	if len(addr) > 0 {
+		serverAddr = addr[0]
+	}
+
+	a.routesMutex.Lock()
+	a.mock = true
+	if a.routesMounted {
+		if err := a.rebuildEngine(); err != nil {
+			a.routesMutex.Unlock()
+			return err
+		}
+	}
+	a.routesMutex.Unlock()
+	a.SetupRoutes()
+
+	server := a.newServer(serverAddr)
+	host := serverAddr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+	log.Println("Mock server started at http://" + host)
+	log.Println("Documentation available at http://" + host + "/docs")
+
+	a.trackServer(server)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// mockHandlers builds the handler chain of a route in mock mode: request validation and the
+// example response of its operation
+func (apiInstance *GoAPI) mockHandlers(route router.Route, document *openapi.Document) []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	if apiInstance.validatesRequest(route) {
+		handlers = append(handlers, middleware.RequestValidation(route, apiInstance.validator))
+	}
+
+	return append(handlers, mockOperation(apiInstance.routeOperation(document, route), document.Definitions))
+}
+
+// mockOperation answers requests with the examples of the documented responses of an operation
+// The examples are generated once, when the routes are mounted
+func mockOperation(operation *openapi.Operation, definitions map[string]*openapi.Schema) gin.HandlerFunc {
+	examples := make(map[int]interface{})
+	var statuses []int
+	if operation != nil {
+		for code, response := range operation.Responses {
+			status, err := strconv.Atoi(code)
+			if err != nil {
+				continue // "default" no tiene un código que responder
+			}
+			statuses = append(statuses, status)
+			if example, found := response.Examples[gin.MIMEJSON]; found {
+				examples[status] = example
+			} else if response.Schema != nil {
+				examples[status] = generateExample(response.Schema, definitions, 0)
+			}
+		}
+	}
+	sort.Ints(statuses)
+
+	// Por defecto el menor 2xx; las operaciones sin respuestas exitosas usan el menor código
+	fallback := http.StatusOK
+	if len(statuses) > 0 {
+		fallback = statuses[0]
+	}
+	for _, status := range statuses {
+		if status >= 200 && status < 300 {
+			fallback = status
+			break
+		}
+	}
+
+	return func(c *gin.Context) {
+		status := fallback
+		if preferred, requested := mockPreference(c.Request); requested {
+			if !slices.Contains(statuses, preferred) {
+				responses.BadRequest(c, fmt.Sprintf("mock: status %d is not documented for this operation", preferred))
+				return
+			}
+			status = preferred
+		}
+
+		example, hasBody := examples[status]
+		if !hasBody || status == http.StatusNoContent || status == http.StatusNotModified || c.Request.Method == http.MethodHead {
+			c.Status(status)
+			return
+		}
+		c.JSON(status, example)
+	}
+}
+
+// mockPreference returns the status requested with Prefer: code=404
+func mockPreference(r *http.Request) (int, bool) {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			name, value, found := strings.Cut(strings.TrimSpace(preference), "=")
+			if !found || !strings.EqualFold(strings.TrimSpace(name), MockStatusPreference) {
+				continue
+			}
+			if status, err := strconv.Atoi(strings.Trim(strings.TrimSpace(value), `"`)); err == nil {
+				return status, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// maxExampleDepth bounds the examples of recursive definitions
+const maxExampleDepth = 8
+
+// generateExample generates an example value of a schema, for mock responses and body examples
+// Properties use their example, default or first enum value, falling back to a placeholder of
+// their type and format; a model value documented as the example of an object overrides the
+// members it sets to non-zero values
+func generateExample(schema *openapi.Schema, definitions map[string]*openapi.Schema, depth int) interface{} {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if schema.Ref != "" {
+		return generateExample(definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")], definitions, depth+1)
+	}
+
+	switch {
+	case schema.Type == "object" || len(schema.Properties) > 0:
+		object := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			object[name] = generateExample(property, definitions, depth+1)
+		}
+		if len(schema.Properties) == 0 && schema.AdditionalProperties != nil {
+			object["key"] = generateExample(schema.AdditionalProperties, definitions, depth+1)
+		}
+		return mergeExample(object, jsonValue(schema.Example))
+	case schema.Type == "array":
+		if declared, ok := jsonValue(schema.Example).([]interface{}); ok && len(declared) > 0 {
+			return declared
+		}
+		count := 1
+		if schema.MinItems != nil && *schema.MinItems > 1 {
+			count = int(*schema.MinItems)
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i] = generateExample(schema.Items, definitions, depth+1)
+		}
+		return items
+	}
+
+	if schema.Example != nil {
+		return scalarExample(schema, schema.Example)
+	}
+	if schema.Default != nil {
+		return scalarExample(schema, schema.Default)
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	return placeholder(schema)
+}
+
+// scalarExample converts an example or default written as text to the type of its schema
+func scalarExample(schema *openapi.Schema, example interface{}) interface{} {
+	text, isText := example.(string)
+	if !isText {
+		return jsonValue(example)
+	}
+	switch schema.Type {
+	case "integer":
+		if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return value
+		}
+	case "number":
+		if value, err := strconv.ParseFloat(text, 64); err == nil {
+			return value
+		}
+	case "boolean":
+		if value, err := strconv.ParseBool(text); err == nil {
+			return value
+		}
+	}
+	return text
+}
+
+// placeholder returns a value of the type and format of a schema that satisfies its bounds
+func placeholder(schema *openapi.Schema) interface{} {
+	switch schema.Type {
+	case "integer":
+		if schema.Minimum != nil && *schema.Minimum > 0 {
+			return int64(*schema.Minimum) + 1
+		}
+		return int64(1)
+	case "number":
+		if schema.Minimum != nil && *schema.Minimum > 0 {
+			return *schema.Minimum + 1
+		}
+		return 1.5
+	case "boolean":
+		return true
+	}
+
+	var value string
+	switch schema.Format {
+	case "date-time":
+		value = "2024-01-15T10:30:00Z"
+	case "date":
+		value = "2024-01-15"
+	case "email":
+		value = "user@example.com"
+	case "uuid":
+		value = "01890a5d-ac96-774b-bcce-b302099a8057"
+	case "uri", "url":
+		value = "https://example.com"
+	case "ipv4", "ip":
+		value = "192.0.2.1"
+	case "ipv6":
+		value = "2001:db8::1"
+	case "decimal":
+		value = "12.50"
+	case "byte":
+		value = "aGVsbG8="
+	default:
+		value = "string"
+	}
+	if schema.MinLength != nil && int64(len(value)) < *schema.MinLength {
+		value += strings.Repeat("x", int(*schema.MinLength)-len(value))
+	}
+	if schema.MaxLength != nil && int64(len(value)) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+	return value
+}
+
+// jsonValue converts a Go value, such as a documented model, to generic JSON values
+func jsonValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, string, bool, float64, json.Number:
+		return value
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil
+	}
+	return decoded
+}
+
+// mergeExample overrides a generated example with the non-zero values of a declared one
+func mergeExample(generated, declared interface{}) interface{} {
+	object, isObject := generated.(map[string]interface{})
+	members, isDeclared := declared.(map[string]interface{})
+	if !isObject || !isDeclared {
+		if zeroJSON(declared) {
+			return generated
+		}
+		return declared
+	}
+	for name, value := range members {
+		object[name] = mergeExample(object[name], value)
+	}
+	return object
+}
+
+// zeroJSON reports whether a decoded JSON value is the zero value of its type
+func zeroJSON(value interface{}) bool {
+	if text, isText := value.(string); isText && text == "0001-01-01T00:00:00Z" {
+		return true // time.Time sin asignar
+	}
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case bool:
+		return !typed
+	case float64:
+		return typed == 0
+	case map[string]interface{}:
+		for _, member := range typed {
+			if !zeroJSON(member) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		return len(typed) == 0
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// BeforeSaveHook is implemented by models that prepare themselves before every write, such as
+// normalizing fields; it runs before BeforeCreate and BeforeUpdate. An error aborts the write
+type BeforeSaveHook interface {
+	BeforeSave(ctx context.Context) error
+}
+
+// BeforeCreateHook is implemented by models that prepare themselves before the store creates them
+// An error aborts the creation and is returned by Create
+type BeforeCreateHook interface {
+	BeforeCreate(ctx context.Context) error
+}
+
+// BeforeUpdateHook is implemented by models that prepare themselves before the store updates them
+// An error aborts the update and is returned by Update
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// SoftDeleter is implemented by models that are marked deleted instead of removed, like BaseModel
+// Stores skip deleted models in Get, Update and Delete, and in List unless IncludeDeleted is set.
+// SQLStore keeps the mark in the deleted_at column
+type SoftDeleter interface {
+	IsDeleted() bool
+	MarkDeleted(at time.Time)
+}
+
+// AfterLoadHook is implemented by models that complete themselves after a store reads them,
+// typically filling computed fields. It runs on every model returned by Get, List, Create and
+// Update; an error is returned by the store method
+type AfterLoadHook interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// Serializer is implemented by models that choose their representation in responses, such as a
+// DTO without internal fields. Resource responds with the result of Serialize and documents it,
+// so Serialize must also work on the zero value of the model
+type Serializer interface {
+	Serialize(ctx context.Context) (interface{}, error)
+}
+
+// beforeSave runs the BeforeSave hook of a model, if it has one
+func beforeSave[T any](ctx context.Context, item *T) error {
+	if hook, ok := any(item).(BeforeSaveHook); ok {
+		return hook.BeforeSave(ctx)
+	}
+	return nil
+}
+
+// beforeCreate runs the BeforeCreate hook of a model, if it has one
+func beforeCreate[T any](ctx context.Context, item *T) error {
+	if hook, ok := any(item).(BeforeCreateHook); ok {
+		return hook.BeforeCreate(ctx)
+	}
+	return nil
+}
+
+// beforeUpdate runs the BeforeUpdate hook of a model, if it has one
+func beforeUpdate[T any](ctx context.Context, item *T) error {
+	if hook, ok := any(item).(BeforeUpdateHook); ok {
+		return hook.BeforeUpdate(ctx)
+	}
+	return nil
+}
+
+// afterLoad runs the AfterLoad hook of a model, if it has one
+func afterLoad[T any](ctx context.Context, item *T) error {
+	if hook, ok := any(item).(AfterLoadHook); ok {
+		return hook.AfterLoad(ctx)
+	}
+	return nil
+}
+
+// softDeleter returns the SoftDeleter of a model, if it is one
+func softDeleter[T any](item *T) (SoftDeleter, bool) {
+	deleter, ok := any(item).(SoftDeleter)
+	return deleter, ok
+}
+
+// deleted reports whether a model is soft-deleted
+func deleted[T any](item *T) bool {
+	deleter, soft := softDeleter(item)
+	return soft && deleter.IsDeleted()
+}
+
+// Serialize returns the representation of a model for a response: the result of its Serialize
+// method, or the model itself. Slices and arrays are serialized element by element
+//
+//	data, err := models.Serialize(c, user)
+func Serialize(ctx context.Context, value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return value, nil
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		if !serializes(v.Type().Elem()) {
+			return value, nil
+		}
+		serialized := make([]interface{}, v.Len())
+		for i := range serialized {
+			element, err := Serialize(ctx, v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			serialized[i] = element
+		}
+		return serialized, nil
+	}
+	if serializer, ok := value.(Serializer); ok {
+		return serializer.Serialize(ctx)
+	}
+	// Serialize may be declared on the pointer
+	if v.Kind() != reflect.Ptr {
+		pointer := reflect.New(v.Type())
+		pointer.Elem().Set(v)
+		if serializer, ok := pointer.Interface().(Serializer); ok {
+			return serializer.Serialize(ctx)
+		}
+	}
+	return value, nil
+}
+
+var serializerInterface = reflect.TypeOf((*Serializer)(nil)).Elem()
+
+// serializes reports whether values of a type, or pointers to them, implement Serializer
+func serializes(t reflect.Type) bool {
+	return t.Implements(serializerInterface) || (t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(serializerInterface))
+}
+
+// ClearComputed resets the fields tagged goapi:"computed" of a pointer to a model, including
+// those of embedded structs. Computed fields are derived by the model (usually in AfterLoad), so
+// values sent by clients are dropped; Resource does it for every body
+func ClearComputed(target interface{}) {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return
+	}
+	clearComputed(value.Elem())
+}
+
+func clearComputed(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		structField := value.Type().Field(i)
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+			clearComputed(value.Field(i))
+			continue
+		}
+		if computed(structField) && value.Field(i).CanSet() {
+			value.Field(i).Set(reflect.Zero(structField.Type))
+		}
+	}
+}
+
+// WithoutWriteOnly returns a copy of a model, a pointer to one or a slice of them with the fields
+// tagged goapi:"writeonly" reset, including those of embedded structs. Write-only fields, such as
+// passwords, are sent by clients but never returned: tag them omitempty too so the zeroed field is
+// left out. Resource does it for every response
+func WithoutWriteOnly(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return value
+	}
+	base := v.Type()
+	for base.Kind() == reflect.Ptr || base.Kind() == reflect.Slice {
+		base = base.Elem()
+	}
+	if !declaresWriteOnly(base) {
+		return value
+	}
+	return withoutWriteOnly(v).Interface()
+}
+
+func withoutWriteOnly(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(withoutWriteOnly(value.Elem()))
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(withoutWriteOnly(value.Index(i)))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		clearWriteOnly(copied)
+		return copied
+	}
+	return value
+}
+
+func clearWriteOnly(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		structField := value.Type().Field(i)
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+			clearWriteOnly(value.Field(i))
+			continue
+		}
+		if tagged(structField, "writeonly") && value.Field(i).CanSet() {
+			value.Field(i).Set(reflect.Zero(structField.Type))
+		}
+	}
+}
+
+// declaresWriteOnly reports whether a struct type has write-only fields, embedded ones included
+func declaresWriteOnly(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if tagged(structField, "writeonly") || (structField.Anonymous && declaresWriteOnly(structField.Type)) {
+			return true
+		}
+	}
+	return false
+}
+
+// computed reports whether a field is tagged goapi:"computed"
+func computed(structField reflect.StructField) bool {
+	return tagged(structField, "computed")
+}
+
+// tagged reports whether the goapi tag of a field includes an option
+func tagged(structField reflect.StructField, option string) bool {
+	for _, current := range strings.Split(structField.Tag.Get("goapi"), ",") {
+		if current == option {
+			return true
+		}
+	}
+	return false
+}
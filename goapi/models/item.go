@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"errors"
 )
 
@@ -28,36 +29,41 @@ func (i *Item) Validate() error {
 }
 
 // ItemStore almacena los items en memoria para este ejemplo
+// Es un MemoryStore de Item; Store() lo expone como Store genérico
 type ItemStore struct {
-	Items []Item
+	store *MemoryStore[Item, string]
 }
 
 // NewItemStore crea un nuevo almacén de items con datos de ejemplo
 func NewItemStore() *ItemStore {
 	return &ItemStore{
-		Items: []Item{
-			{ID: "1", Name: "Item 1", Price: 10.5, IsUsed: false},
-			{ID: "2", Name: "Item 2", Price: 20.0, IsUsed: true},
-		},
+		store: NewMemoryStore(func(item *Item) *string { return &item.ID },
+			Item{ID: "1", Name: "Item 1", Price: 10.5, IsUsed: false},
+			Item{ID: "2", Name: "Item 2", Price: 20.0, IsUsed: true},
+		),
 	}
 }
 
+// Store devuelve el almacén genérico de los items, con filtros y paginación
+func (s *ItemStore) Store() Store[Item, string] {
+	return s.store
+}
+
 // GetAll devuelve todos los items
 func (s *ItemStore) GetAll() []Item {
-	return s.Items
+	items, _, _ := s.store.List(context.Background(), ListOptions{})
+	return items
 }
 
 // GetByID devuelve un item por su ID
 func (s *ItemStore) GetByID(id string) (Item, bool) {
-	for _, item := range s.Items {
-		if item.ID == id {
-			return item, true
-		}
-	}
-	return Item{}, false
+	item, err := s.store.Get(context.Background(), id)
+	return item, err == nil
 }
 
 // Create añade un nuevo item al almacén
-func (s *ItemStore) Create(item Item) {
-	s.Items = append(s.Items, item)
+// Devuelve ErrConflict si ya existe un item con ese ID
+func (s *ItemStore) Create(item Item) error {
+	_, err := s.store.Create(context.Background(), item)
+	return err
 }
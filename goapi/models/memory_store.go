@@ -0,0 +1,272 @@
+package models
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps the models in memory, in insertion order
+// It is safe for concurrent use; useful for examples, prototypes and tests of services. Persist
+// keeps snapshots so the models survive restarts
+type MemoryStore[T any, ID comparable] struct {
+	// NextID generates the ID of models created without one. When nil, integer IDs continue from
+	// the highest ID stored and other IDs must be set by the caller
+	NextID func() ID
+
+	mu       sync.RWMutex
+	id       func(*T) *ID
+	fields   []field
+	items    []T
+	position map[ID]int
+	sequence int64
+
+	// Snapshots (see Persist)
+	persistence Persistence
+	interval    time.Duration
+	dirty       bool
+	saveMu      sync.Mutex
+	stop        chan struct{}
+	stopped     chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewMemoryStore creates a store; id returns a pointer to the ID field of a model, and seed are
+// the models stored initially
+//
+//	users := models.NewMemoryStore(func(u *User) *int { return &u.ID }, seedUsers...)
+func NewMemoryStore[T any, ID comparable](id func(*T) *ID, seed ...T) *MemoryStore[T, ID] {
+	store := &MemoryStore[T, ID]{
+		id:       id,
+		fields:   fieldsOf(reflect.TypeOf((*T)(nil)).Elem()),
+		position: make(map[ID]int),
+	}
+	for _, item := range seed {
+		if _, err := store.Create(context.Background(), item); err != nil {
+			panic(fmt.Sprintf("models: seeding the store: %v", err))
+		}
+	}
+	return store
+}
+
+// List implements Store
+func (s *MemoryStore[T, ID]) List(ctx context.Context, options ListOptions) ([]T, int, error) {
+	filters := make(map[string]string, len(options.Filters))
+	filterFields := make([]field, 0, len(options.Filters))
+	for name, value := range options.Filters {
+		f, ok := lookupField(s.fields, name)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: %s", ErrInvalidField, name)
+		}
+		filters[name] = fmt.Sprint(value)
+		filterFields = append(filterFields, f)
+	}
+	sortName, descending := sortField(options.Sort)
+	var sortBy field
+	if sortName != "" {
+		var ok bool
+		if sortBy, ok = lookupField(s.fields, sortName); !ok {
+			return nil, 0, fmt.Errorf("%w: %s", ErrInvalidField, sortName)
+		}
+	}
+
+	s.mu.RLock()
+	matched := make([]T, 0, len(s.items))
+	for _, item := range s.items {
+		if !options.IncludeDeleted && deleted(&item) {
+			continue
+		}
+		value := reflect.ValueOf(&item).Elem()
+		include := true
+		for _, f := range filterFields {
+			if fmt.Sprint(value.FieldByIndex(f.index).Interface()) != filters[f.name] {
+				include = false
+				break
+			}
+		}
+		if include {
+			matched = append(matched, item)
+		}
+	}
+	s.mu.RUnlock()
+
+	if sortName != "" {
+		slices.SortStableFunc(matched, func(a, b T) int {
+			order := compareValues(
+				reflect.ValueOf(&a).Elem().FieldByIndex(sortBy.index),
+				reflect.ValueOf(&b).Elem().FieldByIndex(sortBy.index),
+			)
+			if descending {
+				return -order
+			}
+			return order
+		})
+	}
+
+	total := len(matched)
+	if options.PageSize > 0 {
+		start := min(options.Offset(), total)
+		matched = matched[start:min(start+options.PageSize, total)]
+	}
+	for i := range matched {
+		if err := afterLoad(ctx, &matched[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+	return matched, total, nil
+}
+
+// Get implements Store
+func (s *MemoryStore[T, ID]) Get(ctx context.Context, id ID) (T, error) {
+	s.mu.RLock()
+	position, ok := s.position[id]
+	if !ok || deleted(&s.items[position]) {
+		s.mu.RUnlock()
+		var zero T
+		return zero, ErrNotFound
+	}
+	item := s.items[position]
+	s.mu.RUnlock()
+	return item, afterLoad(ctx, &item)
+}
+
+// Create implements Store; the BeforeSave and BeforeCreate hooks of the model run first
+func (s *MemoryStore[T, ID]) Create(ctx context.Context, item T) (T, error) {
+	if err := beforeSave(ctx, &item); err != nil {
+		return item, err
+	}
+	if err := beforeCreate(ctx, &item); err != nil {
+		return item, err
+	}
+	err := s.write(func() error {
+		id := s.id(&item)
+		var zero ID
+		if *id == zero {
+			if s.NextID != nil {
+				*id = s.NextID()
+			} else if err := setGeneratedID(id, s.sequence+1); err != nil {
+				return err
+			}
+		}
+		if _, exists := s.position[*id]; exists {
+			return ErrConflict
+		}
+		s.track(*id)
+		s.position[*id] = len(s.items)
+		s.items = append(s.items, item)
+		return nil
+	})
+	if err != nil {
+		return item, err
+	}
+	return item, afterLoad(ctx, &item)
+}
+
+// Update implements Store; the model replaces the stored one and gets its ID
+// The BeforeSave and BeforeUpdate hooks of the model run first, and fields tagged
+// db:",noupdate" keep their stored value
+func (s *MemoryStore[T, ID]) Update(ctx context.Context, id ID, item T) (T, error) {
+	*s.id(&item) = id
+	if err := beforeSave(ctx, &item); err != nil {
+		return item, err
+	}
+	if err := beforeUpdate(ctx, &item); err != nil {
+		return item, err
+	}
+	err := s.write(func() error {
+		position, ok := s.position[id]
+		if !ok || deleted(&s.items[position]) {
+			return ErrNotFound
+		}
+		stored := reflect.ValueOf(&s.items[position]).Elem()
+		value := reflect.ValueOf(&item).Elem()
+		for _, f := range s.fields {
+			if f.noUpdate {
+				value.FieldByIndex(f.index).Set(stored.FieldByIndex(f.index))
+			}
+		}
+		s.items[position] = item
+		return nil
+	})
+	if err != nil {
+		return item, err
+	}
+	return item, afterLoad(ctx, &item)
+}
+
+// Delete implements Store; soft-deleted models (see SoftDeleter) are marked instead of removed
+func (s *MemoryStore[T, ID]) Delete(_ context.Context, id ID) error {
+	return s.write(func() error {
+		position, ok := s.position[id]
+		if !ok || deleted(&s.items[position]) {
+			return ErrNotFound
+		}
+		if deleter, soft := softDeleter(&s.items[position]); soft {
+			deleter.MarkDeleted(time.Now().UTC())
+			return nil
+		}
+		s.items = slices.Delete(s.items, position, position+1)
+		delete(s.position, id)
+		for i := position; i < len(s.items); i++ {
+			s.position[*s.id(&s.items[i])] = i
+		}
+		return nil
+	})
+}
+
+// write applies a change under the lock and, with synchronous persistence, saves it
+func (s *MemoryStore[T, ID]) write(change func() error) error {
+	s.mu.Lock()
+	if err := change(); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	save := s.changed()
+	s.mu.Unlock()
+	if save {
+		return s.Snapshot()
+	}
+	return nil
+}
+
+// track keeps the integer sequence above the IDs stored
+func (s *MemoryStore[T, ID]) track(id ID) {
+	value := reflect.ValueOf(id)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s.sequence = max(s.sequence, value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.sequence = max(s.sequence, int64(value.Uint()))
+	}
+}
+
+// compareValues orders two field values of the same type
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case reflect.Bool:
+		return cmp.Compare(boolRank(a.Bool()), boolRank(b.Bool()))
+	}
+	if instant, ok := a.Interface().(time.Time); ok {
+		return instant.Compare(b.Interface().(time.Time))
+	}
+	return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+}
+
+func boolRank(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
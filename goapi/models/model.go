@@ -2,8 +2,12 @@
 package models
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Model is an interface that all models must implement
@@ -12,13 +16,70 @@ type Model interface {
 }
 
 // BaseModel is a base model that provides common functionality
-type BaseModel struct{}
+// Embedded in a model, it adds a UUID, creation and update timestamps and soft deletion. The
+// stores call its hooks: BeforeCreate generates the ID and sets both timestamps, BeforeUpdate
+// refreshes UpdatedAt, and Delete sets DeletedAt instead of removing the model. The fields are
+// documented as read-only, so clients do not send them
+//
+//	type User struct {
+//		models.BaseModel
+//		Name string `json:"name"`
+//	}
+//
+//	users := models.NewMemoryStore(func(u *User) *string { return &u.ID })
+type BaseModel struct {
+	ID        string     `json:"id" db:"id" validate:"omitempty,uuid" example:"01890a5d-ac96-774b-bcce-b302099a8057" goapi:"readonly"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at,noupdate" goapi:"readonly"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at" goapi:"readonly"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at" goapi:"readonly"`
+}
 
 // Validate implements the Model interface
 func (m *BaseModel) Validate() error {
 	return nil
 }
 
+// BeforeCreate implements BeforeCreateHook: it generates a UUID when the ID is empty and sets
+// the timestamps. Models that define their own BeforeCreate should call this one
+func (m *BaseModel) BeforeCreate(context.Context) error {
+	if m.ID == "" {
+		m.ID = NewUUID()
+	}
+	now := time.Now().UTC()
+	m.CreatedAt = now
+	m.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate implements BeforeUpdateHook: it refreshes UpdatedAt
+func (m *BaseModel) BeforeUpdate(context.Context) error {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// IsDeleted implements SoftDeleter
+func (m *BaseModel) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// MarkDeleted implements SoftDeleter
+func (m *BaseModel) MarkDeleted(at time.Time) {
+	m.DeletedAt = &at
+}
+
+// NewUUID generates a UUIDv7: 48 bits of milliseconds followed by random bits, so the IDs sort
+// by creation time, like the request IDs of the framework
+func NewUUID() string {
+	var id [16]byte
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(time.Now().UnixMilli()))
+	copy(id[:6], timestamp[2:])
+	_, _ = rand.Read(id[6:])
+	id[6] = id[6]&0x0f | 0x70 // Version 7
+	id[8] = id[8]&0x3f | 0x80 // RFC 9562 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
 // ToJSON converts a model to JSON
 func ToJSON(model interface{}) (string, error) {
 	bytes, err := json.Marshal(model)
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Persistence stores the snapshots of a MemoryStore
+// JSONFile writes them to a file; other backends only need to load and save bytes. A bbolt
+// database can be adapted with:
+//
+//	type boltSnapshots struct {
+//		db          *bbolt.DB
+//		bucket, key []byte
+//	}
+//
+//	func (b boltSnapshots) Load() ([]byte, error) {
+//		var data []byte
+//		err := b.db.View(func(tx *bbolt.Tx) error {
+//			if bucket := tx.Bucket(b.bucket); bucket != nil {
+//				data = append([]byte(nil), bucket.Get(b.key)...)
+//			}
+//			return nil
+//		})
+//		return data, err
+//	}
+//
+//	func (b boltSnapshots) Save(data []byte) error {
+//		return b.db.Update(func(tx *bbolt.Tx) error {
+//			bucket, err := tx.CreateBucketIfNotExists(b.bucket)
+//			if err != nil {
+//				return err
+//			}
+//			return bucket.Put(b.key, data)
+//		})
+//	}
+type Persistence interface {
+	Load() ([]byte, error) // Returns nil data and no error when there is no snapshot yet
+	Save(data []byte) error
+}
+
+// JSONFile returns a Persistence that keeps the snapshot in a JSON file
+// Snapshots are written to a temporary file renamed over the previous one, so a crash while
+// saving never leaves a truncated file
+func JSONFile(path string) Persistence {
+	return jsonFile(path)
+}
+
+type jsonFile string
+
+func (path jsonFile) Load() ([]byte, error) {
+	data, err := os.ReadFile(string(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (path jsonFile) Save(data []byte) error {
+	temporary, err := os.CreateTemp(filepath.Dir(string(path)), filepath.Base(string(path))+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporary.Name())
+	if _, err := temporary.Write(data); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Sync(); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporary.Name(), string(path))
+}
+
+// Persist loads the last snapshot into the store, replacing the seed models, and saves a new
+// snapshot after the writes. With an interval of 0 every write is saved before it returns, and a
+// failed save is returned with the write; otherwise changes are saved every interval and failed
+// saves are logged and retried. Call Close on shutdown to save the last changes:
+//
+//	users.Persist(models.JSONFile("users.json"), 5*time.Second)
+//	api.OnShutdown(users.Close)
+func (s *MemoryStore[T, ID]) Persist(persistence Persistence, interval time.Duration) error {
+	data, err := persistence.Load()
+	if err != nil {
+		return fmt.Errorf("models: loading snapshot: %w", err)
+	}
+
+	s.mu.Lock()
+	// Without a snapshot the seed models are saved with the first snapshot
+	s.dirty = data == nil && len(s.items) > 0
+	if data != nil {
+		var items []T
+		if err := json.Unmarshal(data, &items); err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("models: loading snapshot: %w", err)
+		}
+		s.items = s.items[:0]
+		s.position = make(map[ID]int, len(items))
+		for _, item := range items {
+			id := *s.id(&item)
+			if _, exists := s.position[id]; exists {
+				continue
+			}
+			s.track(id)
+			s.position[id] = len(s.items)
+			s.items = append(s.items, item)
+		}
+	}
+	s.persistence = persistence
+	s.interval = interval
+	if interval > 0 {
+		s.stop = make(chan struct{})
+		s.stopped = make(chan struct{})
+		go s.saveEvery(interval, s.stop, s.stopped)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Snapshot saves the models now
+func (s *MemoryStore[T, ID]) Snapshot() error {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	s.mu.Lock()
+	persistence := s.persistence
+	if persistence == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(s.items)
+	s.dirty = false
+	s.mu.Unlock()
+	if err == nil {
+		err = persistence.Save(data)
+	}
+	if err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		return fmt.Errorf("models: saving snapshot: %w", err)
+	}
+	return nil
+}
+
+// Close stops the periodic snapshots and saves the pending changes
+// It has the signature of a shutdown hook
+func (s *MemoryStore[T, ID]) Close(_ context.Context) error {
+	s.mu.RLock()
+	stop, stopped := s.stop, s.stopped
+	s.mu.RUnlock()
+	s.closeOnce.Do(func() {
+		if stop != nil {
+			close(stop)
+			<-stopped
+		}
+	})
+	s.mu.RLock()
+	dirty := s.dirty
+	s.mu.RUnlock()
+	if !dirty {
+		return nil
+	}
+	return s.Snapshot()
+}
+
+// changed marks a write; it is called with s.mu held and reports whether to save right away
+func (s *MemoryStore[T, ID]) changed() bool {
+	if s.persistence == nil {
+		return false
+	}
+	s.dirty = true
+	return s.interval <= 0
+}
+
+// saveEvery saves the pending changes periodically until Close
+func (s *MemoryStore[T, ID]) saveEvery(interval time.Duration, stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.RLock()
+			dirty := s.dirty
+			s.mu.RUnlock()
+			if dirty {
+				if err := s.Snapshot(); err != nil {
+					log.Printf("[models] %v", err)
+				}
+			}
+		case <-stop:
+			return
+		}
+	}
+}
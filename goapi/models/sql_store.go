@@ -0,0 +1,312 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Querier runs queries; *sql.DB, *sql.Tx and *sql.Conn implement it
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Placeholder formats the n-th (1-based) query parameter of a SQL dialect
+type Placeholder func(n int) string
+
+// QuestionPlaceholder formats parameters as ? (MySQL, SQLite)
+func QuestionPlaceholder(int) string { return "?" }
+
+// DollarPlaceholder formats parameters as $1, $2... (PostgreSQL)
+func DollarPlaceholder(n int) string { return "$" + strconv.Itoa(n) }
+
+// SQLStore is a Store backed by a table, with one column per stored field of T
+// The table and column names come from code, never from the request; list filters and sort
+// fields are checked against the columns. Pagination uses LIMIT/OFFSET. Soft-deleted models
+// (see SoftDeleter) with a deleted_at column are marked in it instead of removed
+type SQLStore[T any, ID comparable] struct {
+	DB          Querier
+	Table       string
+	Placeholder Placeholder // Parameter format (default QuestionPlaceholder)
+	// Returning reads generated IDs with INSERT ... RETURNING (PostgreSQL, SQLite 3.35+) instead of
+	// sql.Result.LastInsertId (MySQL, SQLite)
+	Returning bool
+
+	id         func(*T) *ID
+	fields     []field
+	idField    field
+	softDelete bool
+}
+
+// NewSQLStore creates a store on a table; id returns a pointer to the ID field of a model, whose
+// column is used as the primary key
+//
+//	users := models.NewSQLStore(database, "users", func(u *User) *int { return &u.ID })
+func NewSQLStore[T any, ID comparable](database Querier, table string, id func(*T) *ID) *SQLStore[T, ID] {
+	fields := fieldsOf(reflect.TypeOf((*T)(nil)).Elem())
+	_, softDelete := softDeleter(new(T))
+	if _, ok := lookupField(fields, deletedColumn); !ok {
+		softDelete = false
+	}
+	return &SQLStore[T, ID]{
+		DB:          database,
+		Table:       table,
+		Placeholder: QuestionPlaceholder,
+		id:          id,
+		fields:      fields,
+		idField:     idField(fields, id),
+		softDelete:  softDelete,
+	}
+}
+
+// deletedColumn holds the soft deletion time of the models
+const deletedColumn = "deleted_at"
+
+// With returns a copy of the store that runs its queries on another Querier, such as the
+// transaction of the request: store.With(db.Tx(c))
+func (s *SQLStore[T, ID]) With(querier Querier) *SQLStore[T, ID] {
+	clone := *s
+	clone.DB = querier
+	return &clone
+}
+
+// List implements Store
+func (s *SQLStore[T, ID]) List(ctx context.Context, options ListOptions) ([]T, int, error) {
+	var args []interface{}
+	var conditions []string
+	if s.softDelete && !options.IncludeDeleted {
+		conditions = append(conditions, deletedColumn+" IS NULL")
+	}
+	if len(options.Filters) > 0 {
+		// Sorted so the same filters always build the same query
+		names := make([]string, 0, len(options.Filters))
+		for name := range options.Filters {
+			if _, ok := lookupField(s.fields, name); !ok {
+				return nil, 0, fmt.Errorf("%w: %s", ErrInvalidField, name)
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			args = append(args, options.Filters[name])
+			conditions = append(conditions, name+" = "+s.placeholder(len(args)))
+		}
+	}
+	var where string
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := s.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+s.Table+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("models: count %s: %w", s.Table, err)
+	}
+
+	query := "SELECT " + s.columns() + " FROM " + s.Table + where
+	if sortName, descending := sortField(options.Sort); sortName != "" {
+		if _, ok := lookupField(s.fields, sortName); !ok {
+			return nil, 0, fmt.Errorf("%w: %s", ErrInvalidField, sortName)
+		}
+		query += " ORDER BY " + sortName
+		if descending {
+			query += " DESC"
+		}
+	}
+	if options.PageSize > 0 {
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", options.PageSize, options.Offset())
+	}
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("models: list %s: %w", s.Table, err)
+	}
+	defer rows.Close()
+	items := []T{}
+	for rows.Next() {
+		var item T
+		if err := rows.Scan(s.targets(&item)...); err != nil {
+			return nil, 0, fmt.Errorf("models: list %s: %w", s.Table, err)
+		}
+		if err := afterLoad(ctx, &item); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("models: list %s: %w", s.Table, err)
+	}
+	return items, total, nil
+}
+
+// Get implements Store
+func (s *SQLStore[T, ID]) Get(ctx context.Context, id ID) (T, error) {
+	var item T
+	query := "SELECT " + s.columns() + " FROM " + s.Table + " WHERE " + s.idField.name + " = " + s.placeholder(1) + s.notDeleted()
+	err := s.DB.QueryRowContext(ctx, query, id).Scan(s.targets(&item)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return item, ErrNotFound
+	}
+	if err != nil {
+		return item, fmt.Errorf("models: get %s: %w", s.Table, err)
+	}
+	return item, afterLoad(ctx, &item)
+}
+
+// Create implements Store; a model without ID gets the one generated by the database
+// The BeforeSave and BeforeCreate hooks of the model run first
+func (s *SQLStore[T, ID]) Create(ctx context.Context, item T) (T, error) {
+	if err := beforeSave(ctx, &item); err != nil {
+		return item, err
+	}
+	if err := beforeCreate(ctx, &item); err != nil {
+		return item, err
+	}
+	id := s.id(&item)
+	var zero ID
+	generated := *id == zero
+
+	value := reflect.ValueOf(&item).Elem()
+	var columns, placeholders []string
+	var args []interface{}
+	for _, f := range s.fields {
+		if generated && f.name == s.idField.name {
+			continue
+		}
+		args = append(args, value.FieldByIndex(f.index).Interface())
+		columns = append(columns, f.name)
+		placeholders = append(placeholders, s.placeholder(len(args)))
+	}
+	query := "INSERT INTO " + s.Table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(placeholders, ", ") + ")"
+
+	if generated && s.Returning {
+		if err := s.DB.QueryRowContext(ctx, query+" RETURNING "+s.idField.name, args...).Scan(id); err != nil {
+			return item, fmt.Errorf("models: create %s: %w", s.Table, err)
+		}
+		return item, afterLoad(ctx, &item)
+	}
+	result, err := s.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return item, fmt.Errorf("models: create %s: %w", s.Table, err)
+	}
+	if generated {
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return item, fmt.Errorf("models: create %s: %w", s.Table, err)
+		}
+		if err := setGeneratedID(id, lastID); err != nil {
+			return item, err
+		}
+	}
+	return item, afterLoad(ctx, &item)
+}
+
+// Update implements Store; every column but the ID and those tagged db:",noupdate" is written
+// The BeforeSave and BeforeUpdate hooks of the model run first. When columns are kept, the stored
+// row is read back so the returned model has their values. On MySQL, rows left unchanged count as
+// not found unless the DSN sets clientFoundRows=true
+func (s *SQLStore[T, ID]) Update(ctx context.Context, id ID, item T) (T, error) {
+	*s.id(&item) = id
+	if err := beforeSave(ctx, &item); err != nil {
+		return item, err
+	}
+	if err := beforeUpdate(ctx, &item); err != nil {
+		return item, err
+	}
+	value := reflect.ValueOf(&item).Elem()
+	var assignments []string
+	var args []interface{}
+	kept := false
+	for _, f := range s.fields {
+		if f.name == s.idField.name {
+			continue
+		}
+		if f.noUpdate {
+			kept = true
+			continue
+		}
+		args = append(args, value.FieldByIndex(f.index).Interface())
+		assignments = append(assignments, f.name+" = "+s.placeholder(len(args)))
+	}
+	args = append(args, id)
+	query := "UPDATE " + s.Table + " SET " + strings.Join(assignments, ", ") + " WHERE " + s.idField.name + " = " + s.placeholder(len(args)) + s.notDeleted()
+	result, err := s.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return item, fmt.Errorf("models: update %s: %w", s.Table, err)
+	}
+	if err := affected(result, s.Table); err != nil {
+		return item, err
+	}
+	if kept {
+		return s.Get(ctx, id)
+	}
+	return item, afterLoad(ctx, &item)
+}
+
+// Delete implements Store; soft-deleted models get the deletion time in deleted_at instead
+func (s *SQLStore[T, ID]) Delete(ctx context.Context, id ID) error {
+	query := "DELETE FROM " + s.Table + " WHERE " + s.idField.name + " = " + s.placeholder(1)
+	args := []interface{}{id}
+	if s.softDelete {
+		query = "UPDATE " + s.Table + " SET " + deletedColumn + " = " + s.placeholder(1) + " WHERE " + s.idField.name + " = " + s.placeholder(2) + s.notDeleted()
+		args = []interface{}{time.Now().UTC(), id}
+	}
+	result, err := s.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("models: delete %s: %w", s.Table, err)
+	}
+	return affected(result, s.Table)
+}
+
+// columns returns the column list of the stored fields
+func (s *SQLStore[T, ID]) columns() string {
+	names := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		names[i] = f.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// targets returns pointers to the stored fields of a model, in column order
+func (s *SQLStore[T, ID]) targets(item *T) []interface{} {
+	value := reflect.ValueOf(item).Elem()
+	targets := make([]interface{}, len(s.fields))
+	for i, f := range s.fields {
+		targets[i] = value.FieldByIndex(f.index).Addr().Interface()
+	}
+	return targets
+}
+
+// notDeleted returns the condition that skips soft-deleted rows, to append to a WHERE clause
+func (s *SQLStore[T, ID]) notDeleted() string {
+	if !s.softDelete {
+		return ""
+	}
+	return " AND " + deletedColumn + " IS NULL"
+}
+
+func (s *SQLStore[T, ID]) placeholder(n int) string {
+	if s.Placeholder == nil {
+		return QuestionPlaceholder(n)
+	}
+	return s.Placeholder(n)
+}
+
+// affected reports ErrNotFound when a statement changed no row
+func affected(result sql.Result, table string) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("models: %s: %w", table, err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
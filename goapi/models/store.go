@@ -0,0 +1,237 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Errors returned by the stores
+var (
+	ErrNotFound     = errors.New("models: not found")
+	ErrConflict     = errors.New("models: a model with that ID already exists")
+	ErrMissingID    = errors.New("models: the model has no ID and none can be generated")
+	ErrInvalidField = errors.New("models: unknown field")
+)
+
+// Store is a repository of models of type T identified by ID
+// MemoryStore and SQLStore implement it, so handlers and services can be written against the
+// interface and switch storage without changes
+type Store[T any, ID comparable] interface {
+	List(ctx context.Context, options ListOptions) (items []T, total int, err error)
+	Get(ctx context.Context, id ID) (T, error)
+	Create(ctx context.Context, item T) (T, error)
+	Update(ctx context.Context, id ID, item T) (T, error)
+	Delete(ctx context.Context, id ID) error
+}
+
+// ListOptions filters, sorts and paginates a List
+// Fields are named by their db tag, then their json tag, then the snake case of the Go name.
+// Fields of embedded structs are promoted, and shadowed by fields of the same name closer to
+// the model, as in Go. Fields tagged goapi:"computed" are not stored
+type ListOptions struct {
+	Filters  map[string]interface{} // Equality filters by field name
+	Sort     string                 // Field to sort by; a "-" prefix sorts descending (empty = store order)
+	Page     int                    // 1-based page (0 = first page)
+	PageSize int                    // Items per page (0 = all)
+
+	IncludeDeleted bool // Also list soft-deleted models (see SoftDeleter)
+}
+
+// Offset returns the number of items skipped before the page
+func (options ListOptions) Offset() int {
+	if options.PageSize <= 0 || options.Page <= 1 {
+		return 0
+	}
+	return (options.Page - 1) * options.PageSize
+}
+
+// ParseListOptions reads page, page_size and sort from a query string, plus the equality filters
+// that are allowed. Page defaults to 1 and page_size to 10, at most 100
+func ParseListOptions(query url.Values, filters ...string) (ListOptions, error) {
+	options := ListOptions{Page: 1, PageSize: 10, Sort: query.Get("sort")}
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return options, errors.New("invalid 'page' parameter")
+		}
+		options.Page = page
+	}
+	if value := query.Get("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > 100 {
+			return options, errors.New("invalid 'page_size' parameter (must be between 1-100)")
+		}
+		options.PageSize = pageSize
+	}
+	for _, name := range filters {
+		if query.Has(name) {
+			if options.Filters == nil {
+				options.Filters = make(map[string]interface{})
+			}
+			options.Filters[name] = query.Get(name)
+		}
+	}
+	return options, nil
+}
+
+// FieldType returns the Go type of the stored field of T with a name, as used in ListOptions
+func FieldType[T any](name string) (reflect.Type, bool) {
+	modelType := reflect.TypeOf((*T)(nil)).Elem()
+	f, ok := lookupField(fieldsOf(modelType), name)
+	if !ok {
+		return nil, false
+	}
+	return modelType.FieldByIndex(f.index).Type, true
+}
+
+// sortField splits a sort expression into the field name and the direction
+func sortField(sort string) (name string, descending bool) {
+	if strings.HasPrefix(sort, "-") {
+		return sort[1:], true
+	}
+	return sort, false
+}
+
+// field is a stored field of a model
+type field struct {
+	name     string
+	index    []int
+	noUpdate bool // db:",noupdate": written on create only, kept by Update (BaseModel.CreatedAt)
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+// fieldsOf returns the stored fields of a struct type, flattening embedded structs
+func fieldsOf(modelType reflect.Type) []field {
+	if cached, ok := fieldCache.Load(modelType); ok {
+		return cached.([]field)
+	}
+	if modelType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("models: %s is not a struct", modelType))
+	}
+	var fields []field
+	names := make(map[string]int)
+	var walk func(t reflect.Type, prefix []int)
+	walk = func(t reflect.Type, prefix []int) {
+		for i := 0; i < t.NumField(); i++ {
+			structField := t.Field(i)
+			index := append(append([]int{}, prefix...), i)
+			if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+				walk(structField.Type, index)
+				continue
+			}
+			if !structField.IsExported() || computed(structField) {
+				continue
+			}
+			name := fieldName(structField)
+			if name == "" {
+				continue
+			}
+			f := field{name: name, index: index, noUpdate: hasOption(structField.Tag.Get("db"), "noupdate")}
+			if position, exists := names[name]; exists {
+				// The field closest to the model wins
+				if len(index) < len(fields[position].index) {
+					fields[position] = f
+				}
+				continue
+			}
+			names[name] = len(fields)
+			fields = append(fields, f)
+		}
+	}
+	walk(modelType, nil)
+	fieldCache.Store(modelType, fields)
+	return fields
+}
+
+// fieldName returns the stored name of a field, or "" when it is excluded with db:"-"
+func fieldName(structField reflect.StructField) string {
+	if tag, ok := structField.Tag.Lookup("db"); ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	if tag, ok := structField.Tag.Lookup("json"); ok {
+		if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return snakeCase(structField.Name)
+}
+
+// hasOption reports whether a tag such as db:"created_at,noupdate" has an option
+func hasOption(tag, option string) bool {
+	_, options, _ := strings.Cut(tag, ",")
+	for _, candidate := range strings.Split(options, ",") {
+		if candidate == option {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupField returns the stored field with a name
+func lookupField(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// idField returns the field that the ID accessor points to
+func idField[T any, ID comparable](fields []field, id func(*T) *ID) field {
+	var model T
+	target := reflect.ValueOf(id(&model)).Pointer()
+	value := reflect.ValueOf(&model).Elem()
+	for _, f := range fields {
+		if value.FieldByIndex(f.index).Addr().Pointer() == target {
+			return f
+		}
+	}
+	panic(fmt.Sprintf("models: the ID accessor of %T does not point to a stored field", model))
+}
+
+// setGeneratedID stores an integer generated by the database or a sequence in an ID
+func setGeneratedID[ID comparable](id *ID, generated int64) error {
+	value := reflect.ValueOf(id).Elem()
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(generated)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(uint64(generated))
+	default:
+		return ErrMissingID
+	}
+	return nil
+}
+
+// snakeCase converts a Go name such as CreatedAt or UserID to created_at or user_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			lowerBefore := i > 0 && !unicode.IsUpper(runes[i-1])
+			lowerAfter := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerBefore || lowerAfter {
+				builder.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,172 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// APNs endpoints
+const (
+	APNsProduction = "https://api.push.apple.com"
+	APNsSandbox    = "https://api.sandbox.push.apple.com"
+)
+
+// apnsTokenLifetime is how long a provider token is reused; Apple rejects tokens older than one hour
+const apnsTokenLifetime = 50 * time.Minute
+
+// APNsSender delivers notifications to iOS devices through the Apple Push Notification service
+// It authenticates with a provider token signed with the .p8 key of the team
+type APNsSender struct {
+	KeyID    string // Key identifier of the .p8 key
+	TeamID   string // Apple developer team identifier
+	Topic    string // App bundle identifier
+	Endpoint string // APNsProduction or APNsSandbox
+	Client   *http.Client
+
+	privateKey  *ecdsa.PrivateKey
+	tokenMutex  sync.Mutex
+	token       string
+	tokenIssued time.Time
+}
+
+// NewAPNsSender creates an APNs sender from the PEM contents of a .p8 key
+func NewAPNsSender(keyID, teamID, topic string, p8Key []byte, production bool) (*APNsSender, error) {
+	block, _ := pem.Decode(p8Key)
+	if block == nil {
+		return nil, errors.New("invalid APNs key: not PEM encoded")
+	}
+	parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+	privateKey, ok := parsedKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid APNs key: not an ECDSA key")
+	}
+
+	endpoint := APNsSandbox
+	if production {
+		endpoint = APNsProduction
+	}
+
+	return &APNsSender{
+		KeyID:      keyID,
+		TeamID:     teamID,
+		Topic:      topic,
+		Endpoint:   endpoint,
+		Client:     &http.Client{Timeout: 30 * time.Second},
+		privateKey: privateKey,
+	}, nil
+}
+
+// Platform implements Sender
+func (a *APNsSender) Platform() Platform {
+	return PlatformIOS
+}
+
+// Send implements Sender
+func (a *APNsSender) Send(ctx context.Context, device Device, message Message) error {
+	token, err := a.providerToken()
+	if err != nil {
+		return fmt.Errorf("error signing APNs token: %w", err)
+	}
+
+	body, err := json.Marshal(a.buildPayload(message))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Endpoint+"/3/device/"+device.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "bearer "+token)
+	request.Header.Set("apns-topic", a.Topic)
+	request.Header.Set("apns-push-type", "alert")
+	if message.TTL > 0 {
+		request.Header.Set("apns-expiration", strconv.FormatInt(time.Now().Add(message.TTL).Unix(), 10))
+	}
+	switch message.Priority {
+	case "high":
+		request.Header.Set("apns-priority", "10")
+	case "normal":
+		request.Header.Set("apns-priority", "5")
+	}
+
+	response, err := a.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending APNs notification: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(response.Body).Decode(&failure)
+	if response.StatusCode == http.StatusGone || failure.Reason == "BadDeviceToken" || failure.Reason == "Unregistered" {
+		return ErrDeviceGone
+	}
+	return fmt.Errorf("APNs returned %d: %s", response.StatusCode, failure.Reason)
+}
+
+// buildPayload converts a message to the APNs JSON payload
+// Custom data is sent at the top level, next to the aps dictionary
+func (a *APNsSender) buildPayload(message Message) map[string]interface{} {
+	aps := map[string]interface{}{
+		"alert": map[string]string{
+			"title": message.Title,
+			"body":  message.Body,
+		},
+	}
+	if message.Sound != "" {
+		aps["sound"] = message.Sound
+	}
+	if message.Badge != nil {
+		aps["badge"] = *message.Badge
+	}
+
+	payload := map[string]interface{}{"aps": aps}
+	for key, value := range message.Data {
+		payload[key] = value
+	}
+	if message.URL != "" {
+		payload["url"] = message.URL
+	}
+	return payload
+}
+
+// providerToken returns the cached provider token, signing a new one when it is about to expire
+func (a *APNsSender) providerToken() (string, error) {
+	a.tokenMutex.Lock()
+	defer a.tokenMutex.Unlock()
+
+	if a.token != "" && time.Since(a.tokenIssued) < apnsTokenLifetime {
+		return a.token, nil
+	}
+
+	issued := time.Now()
+	token, err := signES256(a.privateKey,
+		map[string]interface{}{"kid": a.KeyID},
+		map[string]interface{}{"iss": a.TeamID, "iat": issued.Unix()})
+	if err != nil {
+		return "", err
+	}
+
+	a.token = token
+	a.tokenIssued = issued
+	return token, nil
+}
@@ -0,0 +1,179 @@
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/mailer"
+	"github.com/esteban-ll-aguilar/goapi/goapi/notifications"
+	"github.com/esteban-ll-aguilar/goapi/goapi/tasks"
+)
+
+// EmailChannel delivers notifications by email: To are the addresses, Body the text body
+type EmailChannel struct {
+	Mailer *mailer.Mailer
+	// HTML renders the body as HTML (default false: plain text); set it before adding the rules,
+	// whose bodies are then html/template templates
+	HTML bool
+}
+
+// NewEmailChannel creates an email channel
+func NewEmailChannel(mails *mailer.Mailer) *EmailChannel {
+	return &EmailChannel{Mailer: mails}
+}
+
+// RendersHTML implements HTMLChannel
+func (e *EmailChannel) RendersHTML() bool {
+	return e.HTML
+}
+
+// Deliver implements Channel
+func (e *EmailChannel) Deliver(ctx context.Context, notification Notification) error {
+	message := mailer.Message{To: notification.To, Subject: notification.Subject}
+	if e.HTML {
+		message.HTML = notification.Body
+	} else {
+		message.Text = notification.Body
+	}
+	err := e.Mailer.Send(ctx, message)
+	if errors.Is(err, mailer.ErrNoRecipients) {
+		return tasks.Permanent(err)
+	}
+	return err
+}
+
+// WebhookChannel posts notifications to a URL
+// The body is the rendered Body of the rule or, when it is empty, a JSON envelope with the ID,
+// the topic and the payload of the event. With a Secret, the X-Signature header carries
+// "sha256=" and the hex HMAC-SHA256 of the body
+type WebhookChannel struct {
+	URL    string
+	Secret []byte
+	Header http.Header // Added to every request, such as an Authorization header
+	Client *http.Client
+}
+
+// NewWebhookChannel creates a webhook channel posting to url
+func NewWebhookChannel(url string, secret []byte) *WebhookChannel {
+	return &WebhookChannel{URL: url, Secret: secret, Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Deliver implements Channel
+func (w *WebhookChannel) Deliver(ctx context.Context, notification Notification) error {
+	body := []byte(notification.Body)
+	if len(body) == 0 {
+		var err error
+		body, err = json.Marshal(map[string]interface{}{
+			"id":    notification.ID,
+			"topic": notification.Topic,
+			"data":  notification.Payload,
+		})
+		if err != nil {
+			return tasks.Permanent(err)
+		}
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return tasks.Permanent(err)
+	}
+	for name, values := range w.Header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+	// Los consumidores descartan reintentos ya procesados por este ID
+	request.Header.Set("X-Notification-Id", notification.ID)
+	request.Header.Set("X-Notification-Topic", notification.Topic)
+	if len(w.Secret) > 0 {
+		mac := hmac.New(sha256.New, w.Secret)
+		mac.Write(body)
+		request.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return post(w.Client, request, "webhook")
+}
+
+// SlackChannel posts notifications to a Slack incoming webhook
+// The message is the subject in bold followed by the body, in Slack markup
+type SlackChannel struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// NewSlackChannel creates a channel posting to a Slack incoming webhook
+func NewSlackChannel(webhookURL string) *SlackChannel {
+	return &SlackChannel{WebhookURL: webhookURL, Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Deliver implements Channel
+func (s *SlackChannel) Deliver(ctx context.Context, notification Notification) error {
+	text := notification.Body
+	if notification.Subject != "" {
+		text = strings.TrimSpace("*" + notification.Subject + "*\n" + text)
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return tasks.Permanent(err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return tasks.Permanent(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return post(s.Client, request, "Slack")
+}
+
+// PushChannel delivers notifications to the devices of users through a notifications.Service
+// To are the user IDs; the subject is the title of the push notification
+type PushChannel struct {
+	Service *notifications.Service
+}
+
+// NewPushChannel creates a push channel
+func NewPushChannel(service *notifications.Service) *PushChannel {
+	return &PushChannel{Service: service}
+}
+
+// Deliver implements Channel
+// Users without devices are skipped; a failed device fails the delivery, and its retry notifies
+// every device of the users again
+func (p *PushChannel) Deliver(ctx context.Context, notification Notification) error {
+	message := notifications.Message{Title: notification.Subject, Body: notification.Body}
+	var failures []error
+	for _, userID := range notification.To {
+		if _, err := p.Service.NotifyUser(ctx, userID, message); err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// post sends a request, turning non 2xx responses into errors
+// Client errors other than 408 and 429 are permanent: the request will not get better
+func post(client *http.Client, request *http.Request, service string) error {
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error calling %s: %w", service, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 4096))
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	err = fmt.Errorf("%s returned %d: %s", service, response.StatusCode, strings.TrimSpace(string(detail)))
+	if response.StatusCode >= 400 && response.StatusCode < 500 &&
+		response.StatusCode != http.StatusRequestTimeout && response.StatusCode != http.StatusTooManyRequests {
+		return tasks.Permanent(err)
+	}
+	return err
+}
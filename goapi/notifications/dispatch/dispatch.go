@@ -0,0 +1,239 @@
+// Package dispatch routes the events of the bus to notification channels
+// Rules match event topics and render templated notifications from the payload; every
+// notification is delivered by the tasks plugin, retried when its channel fails, and tracked as
+// a Delivery in a Store. Channels deliver by email, to webhooks, to Slack or as push
+// notifications
+//
+//	dispatcher := dispatch.New(nil)
+//	dispatcher.AddChannel("email", dispatch.NewEmailChannel(mails))
+//	dispatcher.AddChannel("ops", dispatch.NewSlackChannel(slackWebhookURL))
+//	dispatcher.AddRule(dispatch.Rule{
+//		Name:    "order-shipped",
+//		Topic:   "orders.shipped",
+//		Channel: "email",
+//		To:      []string{"{{.Customer.Email}}"},
+//		Subject: "Your order {{.ID}} is on its way",
+//		Body:    "Track it at {{.TrackingURL}}",
+//	})
+//	api.UsePlugin(dispatch.NewPlugin(dispatcher, bus, pool))
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/events"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+)
+
+// Errors returned by the dispatcher and the stores
+var (
+	// ErrUnknownChannel is returned when a rule names a channel that was not added
+	ErrUnknownChannel = errors.New("unknown notification channel")
+	// ErrDeliveryNotFound is returned by stores for unknown deliveries
+	ErrDeliveryNotFound = errors.New("delivery not found")
+)
+
+// Notification is a rendered notification, as channels receive it
+type Notification struct {
+	ID      string          `json:"id"` // ID of the delivery, stable across retries
+	Rule    string          `json:"rule"`
+	Topic   string          `json:"topic"`
+	To      []string        `json:"to,omitempty"` // Recipients: emails, user IDs... as the channel expects
+	Subject string          `json:"subject,omitempty"`
+	Body    string          `json:"body,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"` // Payload of the event, encoded as JSON
+}
+
+// Channel delivers notifications
+// An error schedules a retry; wrap it with tasks.Permanent when retrying cannot help
+type Channel interface {
+	Deliver(ctx context.Context, notification Notification) error
+}
+
+// HTMLChannel is a channel sending the body as HTML, such as EmailChannel with HTML: the bodies of
+// its rules are html/template templates, which escape the values of the payload
+type HTMLChannel interface {
+	Channel
+	RendersHTML() bool
+}
+
+// ChannelFunc adapts a function to a Channel
+type ChannelFunc func(ctx context.Context, notification Notification) error
+
+// Deliver implements Channel
+func (f ChannelFunc) Deliver(ctx context.Context, notification Notification) error {
+	return f(ctx, notification)
+}
+
+// Rule sends a notification to a channel when an event is published
+// To, Subject and Body are text/template templates executed on the payload of the event; the
+// json function encodes a value: {{json .}}. Body is an html/template template when the channel
+// renders HTML (see HTMLChannel)
+type Rule struct {
+	Name    string
+	Topic   string // Topic pattern, as in events.Bus.Subscribe: "orders.shipped", "orders.*" or "*"
+	Channel string // Name of the channel the notifications go to
+	// When filters the events of the topic; nil accepts every event
+	When    func(event events.Event) bool
+	To      []string
+	Subject string
+	Body    string
+
+	to      []*template.Template
+	subject *template.Template
+	body    bodyTemplate
+}
+
+// bodyTemplate is the text/template or html/template template of a body
+type bodyTemplate interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// templateFuncs are the functions available to the templates of the rules
+var templateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+}
+
+// Dispatcher holds the channels and the rules
+// Add them before installing the plugin, which subscribes the rules to the bus
+type Dispatcher struct {
+	store    Store
+	channels map[string]Channel
+	rules    []*Rule
+	mutex    sync.RWMutex
+}
+
+// New creates a dispatcher; a nil store defaults to an in-memory store
+func New(store Store) *Dispatcher {
+	if store == nil {
+		store = NewMemoryStore(0)
+	}
+	return &Dispatcher{store: store, channels: make(map[string]Channel)}
+}
+
+// Store returns the delivery store of the dispatcher
+func (d *Dispatcher) Store() Store {
+	return d.store
+}
+
+// AddChannel adds (or replaces) a named channel
+func (d *Dispatcher) AddChannel(name string, channel Channel) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.channels[name] = channel
+}
+
+// AddRule adds a rule, parsing its templates
+func (d *Dispatcher) AddRule(rule Rule) error {
+	if rule.Name == "" || rule.Topic == "" {
+		return errors.New("dispatch: a rule needs a Name and a Topic")
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	channel, exists := d.channels[rule.Channel]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownChannel, rule.Channel)
+	}
+
+	var err error
+	for i, to := range rule.To {
+		parsed, err := template.New(fmt.Sprintf("%s.to.%d", rule.Name, i)).Funcs(templateFuncs).Parse(to)
+		if err != nil {
+			return fmt.Errorf("error parsing recipient of rule %s: %w", rule.Name, err)
+		}
+		rule.to = append(rule.to, parsed)
+	}
+	if rule.subject, err = template.New(rule.Name + ".subject").Funcs(templateFuncs).Parse(rule.Subject); err != nil {
+		return fmt.Errorf("error parsing subject of rule %s: %w", rule.Name, err)
+	}
+	// Los cuerpos HTML escapan los datos del evento: nombres o notas no inyectan marcado
+	if html, ok := channel.(HTMLChannel); ok && html.RendersHTML() {
+		rule.body, err = htmltemplate.New(rule.Name + ".body").Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(rule.Body)
+	} else {
+		rule.body, err = template.New(rule.Name + ".body").Funcs(templateFuncs).Parse(rule.Body)
+	}
+	if err != nil {
+		return fmt.Errorf("error parsing body of rule %s: %w", rule.Name, err)
+	}
+	d.rules = append(d.rules, &rule)
+	return nil
+}
+
+// Render renders the notification of a rule for an event
+func (r *Rule) Render(event events.Event) (Notification, error) {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return Notification{}, fmt.Errorf("error encoding payload of %s: %w", event.Topic, err)
+	}
+	notification := Notification{ID: middleware.NewRequestID(), Rule: r.Name, Topic: event.Topic, Payload: payload}
+
+	var buffer bytes.Buffer
+	for _, to := range r.to {
+		buffer.Reset()
+		if err := to.Execute(&buffer, event.Payload); err != nil {
+			return Notification{}, fmt.Errorf("error rendering recipient of rule %s: %w", r.Name, err)
+		}
+		// Un destinatario vacío (campo opcional del evento) se omite
+		if recipient := strings.TrimSpace(buffer.String()); recipient != "" {
+			notification.To = append(notification.To, recipient)
+		}
+	}
+	buffer.Reset()
+	if err := r.subject.Execute(&buffer, event.Payload); err != nil {
+		return Notification{}, fmt.Errorf("error rendering subject of rule %s: %w", r.Name, err)
+	}
+	notification.Subject = strings.TrimSpace(buffer.String())
+	buffer.Reset()
+	if err := r.body.Execute(&buffer, event.Payload); err != nil {
+		return Notification{}, fmt.Errorf("error rendering body of rule %s: %w", r.Name, err)
+	}
+	notification.Body = strings.TrimSpace(buffer.String())
+	return notification, nil
+}
+
+// channel returns a named channel
+func (d *Dispatcher) channel(name string) (Channel, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	channel, exists := d.channels[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownChannel, name)
+	}
+	return channel, nil
+}
+
+// Status is the state of a delivery
+type Status string
+
+// Delivery states
+const (
+	StatusPending   Status = "pending"   // Queued, or waiting for a retry after a failure
+	StatusDelivered Status = "delivered" // Accepted by the channel
+	StatusFailed    Status = "failed"    // Failed on its last attempt
+)
+
+// Delivery tracks a notification sent to a channel
+type Delivery struct {
+	ID           string       `json:"id"`
+	Rule         string       `json:"rule"`
+	Channel      string       `json:"channel"`
+	Status       Status       `json:"status"`
+	Attempts     int          `json:"attempts"`
+	Error        string       `json:"error,omitempty"` // Error of the last attempt
+	Notification Notification `json:"notification"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
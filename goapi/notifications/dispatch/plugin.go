@@ -0,0 +1,238 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/events"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+	"github.com/esteban-ll-aguilar/goapi/goapi/tasks"
+)
+
+// Plugin wires a Dispatcher into a GoAPI instance
+// It subscribes the rules to the bus, delivers the notifications with a task of the pool and
+// registers *Dispatcher as a dependency. With a Path, it serves the deliveries for tracking
+type Plugin struct {
+	Dispatcher *Dispatcher
+	Bus        *events.Bus
+	Tasks      *tasks.Plugin
+	TaskName   string // Name of the delivery task (default "notifications.deliver")
+	MaxRetries int    // Retries of a failed delivery (default 3)
+
+	Path        string               // Path of the delivery endpoints, such as "/admin/notifications"; empty serves none
+	Tags        []string             // Documentation tags of the endpoints
+	Middlewares []gin.HandlerFunc    // Run before the endpoints, for example to require an administrator
+	Options     []router.RouteOption // Added to every endpoint
+
+	unsubscribe []func()
+}
+
+// NewPlugin creates a plugin routing the events of bus with pool
+func NewPlugin(dispatcher *Dispatcher, bus *events.Bus, pool *tasks.Plugin) *Plugin {
+	return &Plugin{
+		Dispatcher: dispatcher,
+		Bus:        bus,
+		Tasks:      pool,
+		TaskName:   "notifications.deliver",
+		MaxRetries: 3,
+		Tags:       []string{"notifications"},
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "dispatch"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	if p.Dispatcher == nil || p.Bus == nil || p.Tasks == nil {
+		return errors.New("dispatch: the plugin needs a Dispatcher, a Bus and a tasks Plugin")
+	}
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Dispatcher, nil
+	}, (*Dispatcher)(nil))
+
+	p.Tasks.Register(p.TaskName, p.deliver, tasks.WithMaxRetries(p.MaxRetries))
+	p.Dispatcher.mutex.RLock()
+	for _, rule := range p.Dispatcher.rules {
+		p.unsubscribe = append(p.unsubscribe, p.Bus.Subscribe(rule.Topic, p.route(rule)))
+	}
+	p.Dispatcher.mutex.RUnlock()
+
+	if p.Path == "" {
+		return nil
+	}
+	idParameter := goapi.WithPathParameter("id", "string", "Delivery ID")
+	api.GET(p.Path+"/deliveries", p.list, p.options(
+		goapi.WithSummary("List notification deliveries"),
+		goapi.WithDescription("Lists the most recent deliveries, with their status and the error of their last attempt"),
+		goapi.WithQueryParameter("rule", "string", "Filter by rule", false),
+		goapi.WithQueryParameter("channel", "string", "Filter by channel", false),
+		goapi.WithQueryParameter("status", "string", "Filter by status: pending, delivered or failed", false),
+		goapi.WithQueryParameter("limit", "integer", "Deliveries returned (default 100)", false),
+		goapi.WithResponseModel(http.StatusOK, []Delivery{}, "Deliveries"),
+	)...)
+	api.GET(p.Path+"/deliveries/:id", p.get, p.options(
+		goapi.WithSummary("Get a notification delivery"),
+		idParameter,
+		goapi.WithResponseModel(http.StatusOK, Delivery{}, "Delivery"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown delivery"),
+	)...)
+	api.POST(p.Path+"/deliveries/:id/retry", p.retry, p.options(
+		goapi.WithSummary("Retry a notification delivery"),
+		goapi.WithDescription("Queues a failed delivery again"),
+		idParameter,
+		goapi.WithResponseModel(http.StatusAccepted, Delivery{}, "Delivery queued"),
+		goapi.WithResponse(http.StatusConflict, "The delivery has not failed"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown delivery"),
+	)...)
+	return nil
+}
+
+// OnShutdown implements goapi.ShutdownPlugin
+func (p *Plugin) OnShutdown(_ context.Context) error {
+	for _, unsubscribe := range p.unsubscribe {
+		unsubscribe()
+	}
+	p.unsubscribe = nil
+	return nil
+}
+
+// options adds the common options of the endpoints
+func (p *Plugin) options(options ...router.RouteOption) []router.RouteOption {
+	options = append(options, goapi.WithTags(p.Tags...), goapi.WithMiddleware(p.Middlewares...))
+	return append(options, p.Options...)
+}
+
+// route returns the bus handler of a rule: it renders the notification, records the delivery
+// and queues it, so the event is not lost once Publish returns
+func (p *Plugin) route(rule *Rule) events.Handler {
+	return func(ctx context.Context, event events.Event) error {
+		if rule.When != nil && !rule.When(event) {
+			return nil
+		}
+		notification, err := rule.Render(event)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		delivery := Delivery{
+			ID:           notification.ID,
+			Rule:         rule.Name,
+			Channel:      rule.Channel,
+			Status:       StatusPending,
+			Notification: notification,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		return p.enqueue(ctx, delivery)
+	}
+}
+
+// enqueue saves a pending delivery and queues its task
+func (p *Plugin) enqueue(ctx context.Context, delivery Delivery) error {
+	if err := p.Dispatcher.store.Save(ctx, delivery); err != nil {
+		return err
+	}
+	_, err := p.Tasks.Enqueue(ctx, p.TaskName, delivery)
+	return err
+}
+
+// deliver is the handler of the delivery task
+func (p *Plugin) deliver(ctx context.Context, task *tasks.Task) error {
+	var delivery Delivery
+	if err := task.Bind(&delivery); err != nil {
+		return tasks.Permanent(err)
+	}
+	// El estado guardado conserva los intentos de reintentos manuales anteriores
+	if stored, err := p.Dispatcher.store.Get(ctx, delivery.ID); err == nil {
+		if stored.Status == StatusDelivered {
+			return nil
+		}
+		delivery = stored
+	}
+
+	channel, err := p.Dispatcher.channel(delivery.Channel)
+	if err == nil {
+		err = channel.Deliver(ctx, delivery.Notification)
+	} else {
+		err = tasks.Permanent(err)
+	}
+
+	delivery.Attempts++
+	delivery.UpdatedAt = time.Now()
+	switch {
+	case err == nil:
+		delivery.Status, delivery.Error = StatusDelivered, ""
+	case tasks.IsPermanent(err) || task.Attempt >= p.MaxRetries:
+		delivery.Status, delivery.Error = StatusFailed, err.Error()
+	default:
+		delivery.Status, delivery.Error = StatusPending, err.Error()
+	}
+	// Guardar con un contexto propio: el de la tarea puede haber expirado
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if saveErr := p.Dispatcher.store.Save(saveCtx, delivery); saveErr != nil && err == nil {
+		return tasks.Permanent(saveErr)
+	}
+	return err
+}
+
+// list handles GET {path}/deliveries
+func (p *Plugin) list(c *gin.Context) {
+	filter := Filter{Rule: c.Query("rule"), Channel: c.Query("channel"), Status: Status(c.Query("status")), Limit: 100}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	deliveries, err := p.Dispatcher.store.List(c.Request.Context(), filter)
+	if err != nil {
+		responses.InternalServerError(c, "Error listing deliveries")
+		return
+	}
+	responses.Success(c, deliveries)
+}
+
+// get handles GET {path}/deliveries/:id
+func (p *Plugin) get(c *gin.Context) {
+	delivery, err := p.Dispatcher.store.Get(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, ErrDeliveryNotFound):
+		responses.NotFound(c, "Delivery not found")
+	case err != nil:
+		responses.InternalServerError(c, "Error reading delivery")
+	default:
+		responses.Success(c, delivery)
+	}
+}
+
+// retry handles POST {path}/deliveries/:id/retry
+func (p *Plugin) retry(c *gin.Context) {
+	delivery, err := p.Dispatcher.store.Get(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, ErrDeliveryNotFound):
+		responses.NotFound(c, "Delivery not found")
+		return
+	case err != nil:
+		responses.InternalServerError(c, "Error reading delivery")
+		return
+	case delivery.Status != StatusFailed:
+		responses.Conflict(c, "Only failed deliveries can be retried")
+		return
+	}
+
+	delivery.Status = StatusPending
+	delivery.UpdatedAt = time.Now()
+	if err := p.enqueue(c.Request.Context(), delivery); err != nil {
+		responses.InternalServerError(c, "Error queuing delivery")
+		return
+	}
+	responses.NewResponse().WithStatus(http.StatusAccepted).WithData(delivery).Send(c)
+}
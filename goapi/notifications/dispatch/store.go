@@ -0,0 +1,97 @@
+package dispatch
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Filter selects deliveries when listing them; empty fields match every delivery
+type Filter struct {
+	Rule    string
+	Channel string
+	Status  Status
+	Limit   int // Most recent deliveries returned (0 = all)
+}
+
+// matches reports whether a delivery is selected by the filter
+func (f Filter) matches(delivery Delivery) bool {
+	return (f.Rule == "" || f.Rule == delivery.Rule) &&
+		(f.Channel == "" || f.Channel == delivery.Channel) &&
+		(f.Status == "" || f.Status == delivery.Status)
+}
+
+// Store persists the deliveries
+// Share it between the instances of a deployment when the task queue is shared
+type Store interface {
+	Save(ctx context.Context, delivery Delivery) error
+	// Get returns ErrDeliveryNotFound for unknown deliveries
+	Get(ctx context.Context, id string) (Delivery, error)
+	// List returns the selected deliveries, the most recent first
+	List(ctx context.Context, filter Filter) ([]Delivery, error)
+}
+
+// MemoryStore keeps the most recent deliveries in memory
+// It is meant for development and single instance deployments
+type MemoryStore struct {
+	capacity   int
+	deliveries map[string]Delivery
+	mutex      sync.RWMutex
+}
+
+// NewMemoryStore creates a store keeping up to capacity deliveries (default 1000)
+// The oldest deliveries are dropped when it is full
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &MemoryStore{capacity: capacity, deliveries: make(map[string]Delivery)}
+}
+
+// Save implements Store
+func (m *MemoryStore) Save(_ context.Context, delivery Delivery) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, exists := m.deliveries[delivery.ID]; !exists && len(m.deliveries) >= m.capacity {
+		var oldest Delivery
+		for _, stored := range m.deliveries {
+			if oldest.ID == "" || stored.CreatedAt.Before(oldest.CreatedAt) {
+				oldest = stored
+			}
+		}
+		delete(m.deliveries, oldest.ID)
+	}
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+// Get implements Store
+func (m *MemoryStore) Get(_ context.Context, id string) (Delivery, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	delivery, exists := m.deliveries[id]
+	if !exists {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// List implements Store
+func (m *MemoryStore) List(_ context.Context, filter Filter) ([]Delivery, error) {
+	m.mutex.RLock()
+	deliveries := make([]Delivery, 0, len(m.deliveries))
+	for _, delivery := range m.deliveries {
+		if filter.matches(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(deliveries) > filter.Limit {
+		deliveries = deliveries[:filter.Limit]
+	}
+	return deliveries, nil
+}
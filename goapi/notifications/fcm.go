@@ -0,0 +1,254 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource returns an OAuth2 access token for the FCM API
+type TokenSource func(ctx context.Context) (string, error)
+
+// fcmEndpoint is the FCM HTTP v1 send endpoint
+const fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+
+// fcmScope is the OAuth2 scope required to send messages
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCMSender delivers notifications to Android devices through Firebase Cloud Messaging (HTTP v1 API)
+type FCMSender struct {
+	ProjectID string
+	Tokens    TokenSource
+	Client    *http.Client
+	Endpoint  string // Overrides the FCM endpoint (testing, emulators)
+}
+
+// NewFCMSender creates an FCM sender for a Firebase project
+func NewFCMSender(projectID string, tokens TokenSource) *FCMSender {
+	return &FCMSender{
+		ProjectID: projectID,
+		Tokens:    tokens,
+		Client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewFCMSenderFromServiceAccount creates an FCM sender from a service account JSON key
+func NewFCMSenderFromServiceAccount(credentialsJSON []byte) (*FCMSender, error) {
+	var credentials struct {
+		ProjectID string `json:"project_id"`
+	}
+	if err := json.Unmarshal(credentialsJSON, &credentials); err != nil {
+		return nil, fmt.Errorf("invalid service account: %w", err)
+	}
+	tokens, err := ServiceAccountTokenSource(credentialsJSON, fcmScope)
+	if err != nil {
+		return nil, err
+	}
+	return NewFCMSender(credentials.ProjectID, tokens), nil
+}
+
+// Platform implements Sender
+func (f *FCMSender) Platform() Platform {
+	return PlatformAndroid
+}
+
+// Send implements Sender
+func (f *FCMSender) Send(ctx context.Context, device Device, message Message) error {
+	accessToken, err := f.Tokens(ctx)
+	if err != nil {
+		return fmt.Errorf("error obtaining FCM access token: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"message": f.buildMessage(device, message)})
+	if err != nil {
+		return err
+	}
+
+	endpoint := f.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf(fcmEndpoint, f.ProjectID)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	response, err := f.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending FCM message: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 300 {
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	if response.StatusCode == http.StatusNotFound || strings.Contains(string(detail), "UNREGISTERED") {
+		return ErrDeviceGone
+	}
+	return fmt.Errorf("FCM returned %d: %s", response.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// buildMessage converts a message to the FCM v1 message resource
+func (f *FCMSender) buildMessage(device Device, message Message) map[string]interface{} {
+	data := make(map[string]string, len(message.Data)+1)
+	for key, value := range message.Data {
+		data[key] = value
+	}
+	if message.URL != "" {
+		data["url"] = message.URL
+	}
+
+	android := map[string]interface{}{}
+	if message.TTL > 0 {
+		android["ttl"] = strconv.Itoa(int(message.TTL.Seconds())) + "s"
+	}
+	if message.Priority != "" {
+		android["priority"] = strings.ToUpper(message.Priority)
+	}
+	if message.Sound != "" {
+		android["notification"] = map[string]string{"sound": message.Sound}
+	}
+
+	resource := map[string]interface{}{
+		"token": device.Token,
+		"notification": map[string]string{
+			"title": message.Title,
+			"body":  message.Body,
+		},
+	}
+	if len(data) > 0 {
+		resource["data"] = data
+	}
+	if len(android) > 0 {
+		resource["android"] = android
+	}
+	return resource
+}
+
+// ServiceAccountTokenSource returns a TokenSource that exchanges a signed service account JWT
+// for an OAuth2 access token, caching the token until shortly before it expires
+func ServiceAccountTokenSource(credentialsJSON []byte, scopes ...string) (TokenSource, error) {
+	var credentials struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(credentialsJSON, &credentials); err != nil {
+		return nil, fmt.Errorf("invalid service account: %w", err)
+	}
+	if credentials.TokenURI == "" {
+		credentials.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	block, _ := pem.Decode([]byte(credentials.PrivateKey))
+	if block == nil {
+		return nil, errors.New("invalid service account: private key is not PEM encoded")
+	}
+	parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account private key: %w", err)
+	}
+	privateKey, ok := parsedKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid service account: private key is not RSA")
+	}
+
+	var (
+		mutex       sync.Mutex
+		cachedToken string
+		expiresAt   time.Time
+	)
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	return func(ctx context.Context) (string, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if cachedToken != "" && time.Until(expiresAt) > time.Minute {
+			return cachedToken, nil
+		}
+
+		now := time.Now()
+		assertion, err := signRS256(privateKey, map[string]interface{}{
+			"iss":   credentials.ClientEmail,
+			"scope": strings.Join(scopes, " "),
+			"aud":   credentials.TokenURI,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		form := url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, credentials.TokenURI, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", err
+		}
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		response, err := client.Do(request)
+		if err != nil {
+			return "", err
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode >= 300 {
+			detail, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+			return "", fmt.Errorf("token endpoint returned %d: %s", response.StatusCode, strings.TrimSpace(string(detail)))
+		}
+
+		var token struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}
+		if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+			return "", fmt.Errorf("invalid token response: %w", err)
+		}
+
+		cachedToken = token.AccessToken
+		expiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+		return cachedToken, nil
+	}, nil
+}
+
+// signRS256 builds a compact JWT signed with RS256
+func signRS256(key *rsa.PrivateKey, claims map[string]interface{}) (string, error) {
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(encodedClaims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
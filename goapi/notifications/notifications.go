@@ -0,0 +1,281 @@
+// Package notifications provides push notifications for GoAPI
+// A Service sends templated messages to the registered devices of a user through
+// platform adapters (Web Push, Firebase Cloud Messaging, Apple Push Notification service)
+// and can be used from handlers as well as from background jobs
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Platform identifies the push service used to reach a device
+type Platform string
+
+// Supported platforms
+const (
+	PlatformWeb     Platform = "web"     // Browsers, through the Web Push protocol
+	PlatformAndroid Platform = "android" // Firebase Cloud Messaging
+	PlatformIOS     Platform = "ios"     // Apple Push Notification service
+)
+
+// Errors returned by the service and the adapters
+var (
+	// ErrDeviceGone is returned by adapters when the push service reports the device as unregistered
+	// The service removes such devices from the store automatically
+	ErrDeviceGone = errors.New("device is no longer registered")
+	// ErrNoSender is returned when no adapter is configured for the platform of a device
+	ErrNoSender = errors.New("no sender configured for platform")
+	// ErrTemplateNotFound is returned when sending an unknown template
+	ErrTemplateNotFound = errors.New("notification template not found")
+	// ErrDeviceNotFound is returned by stores for unknown devices
+	ErrDeviceNotFound = errors.New("device not found")
+)
+
+// Device is a push target registered by a user
+type Device struct {
+	ID        string               `json:"id"`
+	UserID    string               `json:"user_id"`
+	Platform  Platform             `json:"platform" validate:"required,oneof=web android ios"`
+	Token     string               `json:"token,omitempty"`        // FCM registration token or APNs device token
+	WebPush   *WebPushSubscription `json:"subscription,omitempty"` // Browser push subscription
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// Message is the content of a notification
+type Message struct {
+	Title    string            `json:"title"`
+	Body     string            `json:"body"`
+	Data     map[string]string `json:"data,omitempty"`
+	URL      string            `json:"url,omitempty"`   // Opened when the notification is clicked
+	Icon     string            `json:"icon,omitempty"`  // Web only
+	Sound    string            `json:"sound,omitempty"` // Mobile only
+	Badge    *int              `json:"badge,omitempty"` // iOS only
+	TTL      time.Duration     `json:"-"`               // How long the push service keeps the message (0 = adapter default)
+	Priority string            `json:"-"`               // "high" or "normal" (empty = adapter default)
+}
+
+// Sender delivers messages to the devices of a single platform
+type Sender interface {
+	Platform() Platform
+	Send(ctx context.Context, device Device, message Message) error
+}
+
+// DeviceStore persists the devices registered by users
+type DeviceStore interface {
+	Save(ctx context.Context, device Device) error
+	Delete(ctx context.Context, userID, deviceID string) error
+	ListByUser(ctx context.Context, userID string) ([]Device, error)
+}
+
+// Result reports the delivery to a single device
+type Result struct {
+	Device Device
+	Err    error
+}
+
+// Service sends notifications through the configured senders
+type Service struct {
+	store     DeviceStore
+	senders   map[Platform]Sender
+	templates map[string]*messageTemplate
+	mutex     sync.RWMutex
+}
+
+// messageTemplate holds the parsed templates of a notification
+type messageTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// NewService creates a notification service
+// A nil store defaults to an in-memory store
+func NewService(store DeviceStore, senders ...Sender) *Service {
+	if store == nil {
+		store = NewMemoryDeviceStore()
+	}
+	service := &Service{
+		store:     store,
+		senders:   make(map[Platform]Sender),
+		templates: make(map[string]*messageTemplate),
+	}
+	for _, sender := range senders {
+		service.AddSender(sender)
+	}
+	return service
+}
+
+// AddSender adds (or replaces) the sender of a platform
+func (s *Service) AddSender(sender Sender) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.senders[sender.Platform()] = sender
+}
+
+// Store returns the device store of the service
+func (s *Service) Store() DeviceStore {
+	return s.store
+}
+
+// RegisterTemplate registers a notification template
+// Title and body use text/template syntax: "Hello {{.Name}}"
+func (s *Service) RegisterTemplate(name, title, body string) error {
+	titleTemplate, err := template.New(name + ".title").Parse(title)
+	if err != nil {
+		return fmt.Errorf("error parsing title of template %s: %w", name, err)
+	}
+	bodyTemplate, err := template.New(name + ".body").Parse(body)
+	if err != nil {
+		return fmt.Errorf("error parsing body of template %s: %w", name, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.templates[name] = &messageTemplate{title: titleTemplate, body: bodyTemplate}
+	return nil
+}
+
+// Render renders a template into a message
+// base provides the remaining fields (data, URL, TTL...) of the message
+func (s *Service) Render(name string, data interface{}, base ...Message) (Message, error) {
+	s.mutex.RLock()
+	messageTemplate, exists := s.templates[name]
+	s.mutex.RUnlock()
+	if !exists {
+		return Message{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	var message Message
+	if len(base) > 0 {
+		message = base[0]
+	}
+
+	var buffer bytes.Buffer
+	if err := messageTemplate.title.Execute(&buffer, data); err != nil {
+		return Message{}, fmt.Errorf("error rendering title of template %s: %w", name, err)
+	}
+	message.Title = buffer.String()
+
+	buffer.Reset()
+	if err := messageTemplate.body.Execute(&buffer, data); err != nil {
+		return Message{}, fmt.Errorf("error rendering body of template %s: %w", name, err)
+	}
+	message.Body = buffer.String()
+
+	return message, nil
+}
+
+// Send delivers a message to a single device
+func (s *Service) Send(ctx context.Context, device Device, message Message) error {
+	s.mutex.RLock()
+	sender, exists := s.senders[device.Platform]
+	s.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrNoSender, device.Platform)
+	}
+
+	err := sender.Send(ctx, device, message)
+	if errors.Is(err, ErrDeviceGone) {
+		// The push service will never accept this device again
+		_ = s.store.Delete(ctx, device.UserID, device.ID)
+	}
+	return err
+}
+
+// NotifyUser delivers a message to every registered device of a user
+// It returns one result per device; the error is set when at least one delivery failed
+func (s *Service) NotifyUser(ctx context.Context, userID string, message Message) ([]Result, error) {
+	devices, err := s.store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing devices of user %s: %w", userID, err)
+	}
+
+	results := make([]Result, len(devices))
+	var wg sync.WaitGroup
+	for i, device := range devices {
+		wg.Add(1)
+		go func(i int, device Device) {
+			defer wg.Done()
+			results[i] = Result{Device: device, Err: s.Send(ctx, device, message)}
+		}(i, device)
+	}
+	wg.Wait()
+
+	var sendErrors []error
+	for _, result := range results {
+		if result.Err != nil {
+			sendErrors = append(sendErrors, fmt.Errorf("device %s: %w", result.Device.ID, result.Err))
+		}
+	}
+	return results, errors.Join(sendErrors...)
+}
+
+// NotifyUserTemplate renders a template and delivers it to every device of a user
+func (s *Service) NotifyUserTemplate(ctx context.Context, userID, name string, data interface{}, base ...Message) ([]Result, error) {
+	message, err := s.Render(name, data, base...)
+	if err != nil {
+		return nil, err
+	}
+	return s.NotifyUser(ctx, userID, message)
+}
+
+// MemoryDeviceStore keeps devices in memory
+// It is meant for development and tests; production deployments should persist devices
+type MemoryDeviceStore struct {
+	devices map[string]map[string]Device // userID -> deviceID -> device
+	mutex   sync.RWMutex
+}
+
+// NewMemoryDeviceStore creates an empty in-memory store
+func NewMemoryDeviceStore() *MemoryDeviceStore {
+	return &MemoryDeviceStore{devices: make(map[string]map[string]Device)}
+}
+
+// Save implements DeviceStore
+func (m *MemoryDeviceStore) Save(ctx context.Context, device Device) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.devices[device.UserID] == nil {
+		m.devices[device.UserID] = make(map[string]Device)
+	}
+	m.devices[device.UserID][device.ID] = device
+	return nil
+}
+
+// Delete implements DeviceStore
+func (m *MemoryDeviceStore) Delete(ctx context.Context, userID, deviceID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.devices[userID][deviceID]; !exists {
+		return ErrDeviceNotFound
+	}
+	delete(m.devices[userID], deviceID)
+	if len(m.devices[userID]) == 0 {
+		delete(m.devices, userID)
+	}
+	return nil
+}
+
+// ListByUser implements DeviceStore
+// Devices are returned in registration order
+func (m *MemoryDeviceStore) ListByUser(ctx context.Context, userID string) ([]Device, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	devices := make([]Device, 0, len(m.devices[userID]))
+	for _, device := range m.devices[userID] {
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].CreatedAt.Before(devices[j].CreatedAt)
+	})
+	return devices, nil
+}
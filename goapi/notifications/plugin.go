@@ -0,0 +1,165 @@
+package notifications
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// RegisterDeviceRequest is the body of the device registration endpoint
+type RegisterDeviceRequest struct {
+	Platform     Platform             `json:"platform" validate:"required,oneof=web android ios" example:"android"`
+	Token        string               `json:"token,omitempty" validate:"required_unless=Platform web"`
+	Subscription *WebPushSubscription `json:"subscription,omitempty" validate:"required_if=Platform web"`
+}
+
+// Plugin wires a Service into a GoAPI instance
+// It registers the service as a singleton dependency and scaffolds the device registration endpoints
+type Plugin struct {
+	Service *Service
+	Prefix  string                    // Path prefix of the endpoints (default "/notifications")
+	UserID  func(*gin.Context) string // Identifies the authenticated user (default: the "user_id" context value)
+	Tags    []string                  // Documentation tags of the endpoints
+}
+
+// NewPlugin creates a plugin serving the device endpoints under /notifications
+func NewPlugin(service *Service) *Plugin {
+	return &Plugin{
+		Service: service,
+		Prefix:  "/notifications",
+		UserID: func(c *gin.Context) string {
+			return c.GetString("user_id")
+		},
+		Tags: []string{"notifications"},
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "notifications"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Service, nil
+	}, (*Service)(nil))
+
+	api.POST(p.Prefix+"/devices", p.registerDevice,
+		goapi.WithSummary("Register a device"),
+		goapi.WithDescription("Registers a device of the authenticated user for push notifications"),
+		goapi.WithTags(p.Tags...),
+		goapi.WithRequestBody(RegisterDeviceRequest{}, "Device to register"),
+		goapi.WithResponse(http.StatusCreated, "Device registered"),
+		goapi.WithResponse(http.StatusUnauthorized, "Not authenticated"),
+	)
+	api.GET(p.Prefix+"/devices", p.listDevices,
+		goapi.WithSummary("List devices"),
+		goapi.WithDescription("Lists the devices registered by the authenticated user"),
+		goapi.WithTags(p.Tags...),
+	)
+	api.DELETE(p.Prefix+"/devices/:id", p.deleteDevice,
+		goapi.WithSummary("Unregister a device"),
+		goapi.WithTags(p.Tags...),
+		goapi.WithPathParameter("id", "string", "Device identifier"),
+		goapi.WithResponse(http.StatusNoContent, "Device unregistered"),
+		goapi.WithResponse(http.StatusNotFound, "Device not found"),
+	)
+
+	p.Service.mutex.RLock()
+	webSender, hasWebPush := p.Service.senders[PlatformWeb].(*WebPushSender)
+	p.Service.mutex.RUnlock()
+	if hasWebPush {
+		api.GET(p.Prefix+"/vapid-public-key", func(c *gin.Context) {
+			responses.Success(c, gin.H{"public_key": webSender.PublicKey()})
+		},
+			goapi.WithSummary("VAPID public key"),
+			goapi.WithDescription("Application server key to pass to PushManager.subscribe"),
+			goapi.WithTags(p.Tags...),
+		)
+	}
+	return nil
+}
+
+// registerDevice handles POST {prefix}/devices
+func (p *Plugin) registerDevice(c *gin.Context) {
+	userID := p.UserID(c)
+	if userID == "" {
+		responses.Unauthorized(c, i18n.M(i18n.KeyAuthenticationRequired))
+		return
+	}
+
+	var request RegisterDeviceRequest
+	if !goapi.DecodeJSONBody(c, &request) {
+		return
+	}
+	if err := validation.FromContext(c).ValidateStruct(request); err != nil {
+		responses.ValidationFailed(c, err)
+		return
+	}
+
+	device := Device{
+		ID:        newDeviceID(),
+		UserID:    userID,
+		Platform:  request.Platform,
+		Token:     request.Token,
+		WebPush:   request.Subscription,
+		CreatedAt: time.Now(),
+	}
+	if err := p.Service.store.Save(c.Request.Context(), device); err != nil {
+		responses.InternalServerError(c, "Error registering device")
+		return
+	}
+	responses.Created(c, device)
+}
+
+// listDevices handles GET {prefix}/devices
+func (p *Plugin) listDevices(c *gin.Context) {
+	userID := p.UserID(c)
+	if userID == "" {
+		responses.Unauthorized(c, i18n.M(i18n.KeyAuthenticationRequired))
+		return
+	}
+
+	devices, err := p.Service.store.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		responses.InternalServerError(c, "Error listing devices")
+		return
+	}
+	responses.Success(c, devices)
+}
+
+// deleteDevice handles DELETE {prefix}/devices/:id
+func (p *Plugin) deleteDevice(c *gin.Context) {
+	userID := p.UserID(c)
+	if userID == "" {
+		responses.Unauthorized(c, i18n.M(i18n.KeyAuthenticationRequired))
+		return
+	}
+
+	err := p.Service.store.Delete(c.Request.Context(), userID, c.Param("id"))
+	switch {
+	case errors.Is(err, ErrDeviceNotFound):
+		responses.NotFound(c, "Device not found")
+	case err != nil:
+		responses.InternalServerError(c, "Error unregistering device")
+	default:
+		responses.NoContent(c)
+	}
+}
+
+// newDeviceID generates a random device identifier
+func newDeviceID() string {
+	buffer := make([]byte, 16)
+	_, _ = rand.Read(buffer)
+	return hex.EncodeToString(buffer)
+}
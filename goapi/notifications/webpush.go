@@ -0,0 +1,263 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebPushSubscription is the PushSubscription object returned by the browser (PushManager.subscribe)
+type WebPushSubscription struct {
+	Endpoint string `json:"endpoint" validate:"required,url"`
+	Keys     struct {
+		P256dh string `json:"p256dh" validate:"required"`
+		Auth   string `json:"auth" validate:"required"`
+	} `json:"keys"`
+}
+
+// webPushRecordSize is the record size advertised in the aes128gcm header
+const webPushRecordSize = 4096
+
+// WebPushSender delivers notifications to browsers with the Web Push protocol
+// Payloads are encrypted with aes128gcm (RFC 8291) and authenticated with VAPID (RFC 8292)
+type WebPushSender struct {
+	Subject    string        // Contact URI sent to push services ("mailto:admin@example.com")
+	DefaultTTL time.Duration // Used when the message has no TTL
+	Client     *http.Client
+
+	privateKey *ecdsa.PrivateKey
+	publicKey  string // Uncompressed application server key, base64url encoded
+}
+
+// NewWebPushSender creates a Web Push sender from a VAPID key pair
+// privateKey is the raw 32-byte P-256 private key encoded as base64url (as produced by most VAPID tools)
+func NewWebPushSender(subject, privateKey string) (*WebPushSender, error) {
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	publicKey := ecdhKey.PublicKey().Bytes()
+	signingKey := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(publicKey[1:33]),
+			Y:     new(big.Int).SetBytes(publicKey[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+
+	return &WebPushSender{
+		Subject:    subject,
+		DefaultTTL: 24 * time.Hour,
+		Client:     &http.Client{Timeout: 30 * time.Second},
+		privateKey: signingKey,
+		publicKey:  base64.RawURLEncoding.EncodeToString(publicKey),
+	}, nil
+}
+
+// GenerateVAPIDKeys generates a new VAPID key pair encoded as base64url
+// The public key is the applicationServerKey passed to PushManager.subscribe in the browser
+func GenerateVAPIDKeys() (privateKey, publicKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// PublicKey returns the application server key to share with browsers
+func (w *WebPushSender) PublicKey() string {
+	return w.publicKey
+}
+
+// Platform implements Sender
+func (w *WebPushSender) Platform() Platform {
+	return PlatformWeb
+}
+
+// Send implements Sender
+// The message is delivered as JSON; the service worker is responsible for displaying it
+func (w *WebPushSender) Send(ctx context.Context, device Device, message Message) error {
+	if device.WebPush == nil {
+		return errors.New("web push device has no subscription")
+	}
+	subscription := device.WebPush
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	body, err := encryptWebPush(subscription, payload)
+	if err != nil {
+		return fmt.Errorf("error encrypting web push payload: %w", err)
+	}
+
+	authorization, err := w.vapidAuthorization(subscription.Endpoint)
+	if err != nil {
+		return fmt.Errorf("error signing VAPID token: %w", err)
+	}
+
+	ttl := message.TTL
+	if ttl <= 0 {
+		ttl = w.DefaultTTL
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("Content-Encoding", "aes128gcm")
+	request.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	request.Header.Set("Authorization", authorization)
+	if message.Priority == "high" {
+		request.Header.Set("Urgency", "high")
+	}
+
+	response, err := w.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending web push: %w", err)
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone:
+		return ErrDeviceGone
+	case response.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("web push service returned %d: %s", response.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// vapidAuthorization builds the VAPID Authorization header for a push service endpoint
+func (w *WebPushSender) vapidAuthorization(endpoint string) (string, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := signES256(w.privateKey,
+		map[string]interface{}{"typ": "JWT"},
+		map[string]interface{}{
+			"aud": endpointURL.Scheme + "://" + endpointURL.Host,
+			"exp": time.Now().Add(12 * time.Hour).Unix(),
+			"sub": w.Subject,
+		})
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + token + ", k=" + w.publicKey, nil
+}
+
+// encryptWebPush encrypts a payload for a subscription with the aes128gcm content encoding
+func encryptWebPush(subscription *WebPushSubscription, payload []byte) ([]byte, error) {
+	userAgentKeyBytes, err := decodeBase64URL(subscription.Keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	userAgentKey, err := ecdh.P256().NewPublicKey(userAgentKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeBase64URL(subscription.Keys.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	// Ephemeral application server key pair, one per message
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serverPublicKey := serverKey.PublicKey().Bytes()
+
+	sharedSecret, err := serverKey.ECDH(userAgentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public)
+	keyPRK, err := hkdf.Extract(sha256.New, sharedSecret, authSecret)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(userAgentKeyBytes) + string(serverPublicKey)
+	inputKey, err := hkdf.Expand(sha256.New, keyPRK, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	contentPRK, err := hkdf.Extract(sha256.New, inputKey, salt)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdf.Expand(sha256.New, contentPRK, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, contentPRK, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Single record: payload followed by the 0x02 last-record delimiter
+	record := append(append([]byte(nil), payload...), 0x02)
+	if len(record)+gcm.Overhead() > webPushRecordSize {
+		return nil, errors.New("web push payload too large")
+	}
+
+	// Header: salt (16) || record size (4) || key id length (1) || key id
+	header := make([]byte, 0, 21+len(serverPublicKey))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(serverPublicKey)))
+	header = append(header, serverPublicKey...)
+
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// decodeBase64URL decodes base64url values with or without padding
+func decodeBase64URL(value string) ([]byte, error) {
+	value = strings.TrimRight(strings.TrimSpace(value), "=")
+	value = strings.NewReplacer("+", "-", "/", "_").Replace(value)
+	return base64.RawURLEncoding.DecodeString(value)
+}
@@ -0,0 +1,433 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change is a difference between two versions of a document
+// Breaking changes are those that can make existing clients fail: removed operations, parameters
+// or response fields, narrowed types and new required inputs
+type Change struct {
+	Breaking bool   `json:"breaking"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Location string `json:"location,omitempty"` // Part of the operation, such as "response 200 body.name"
+	Message  string `json:"message"`
+}
+
+// String formats a change as "GET /users/{id}: response 200 body.name: property removed"
+func (change Change) String() string {
+	text := change.Method + " " + change.Path + ": "
+	if change.Location != "" {
+		text += change.Location + ": "
+	}
+	return text + change.Message
+}
+
+// Breaking returns the breaking changes of a list
+func Breaking(changes []Change) []Change {
+	var breaking []Change
+	for _, change := range changes {
+		if change.Breaking {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+// Diff compares a previous version of a document, such as a committed contract, with the current
+// one and returns their differences sorted by path and method
+// Requests are compared as the server receives them, so a current schema may accept more than the
+// previous one but not less; responses as clients receive them, so a current schema may return
+// more but not less
+func Diff(previous, current *Document) []Change {
+	d := &differ{previous: previous, current: current}
+	for _, path := range sortedKeys(previous.Paths) {
+		currentItem := current.Paths[path]
+		for _, method := range Methods {
+			previousOperation := previous.Paths[path].Operation(method)
+			if previousOperation == nil {
+				continue
+			}
+			var currentOperation *Operation
+			if currentItem != nil {
+				currentOperation = currentItem.Operation(method)
+			}
+			if currentOperation == nil {
+				d.add(true, method, path, "", "operation removed")
+				continue
+			}
+			d.operation(method, path, previousOperation, currentOperation)
+		}
+	}
+	for _, path := range sortedKeys(current.Paths) {
+		for _, method := range Methods {
+			if current.Paths[path].Operation(method) == nil {
+				continue
+			}
+			if item := previous.Paths[path]; item == nil || item.Operation(method) == nil {
+				d.add(false, method, path, "", "operation added")
+			}
+		}
+	}
+
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		if d.changes[i].Path != d.changes[j].Path {
+			return d.changes[i].Path < d.changes[j].Path
+		}
+		return methodIndex(d.changes[i].Method) < methodIndex(d.changes[j].Method)
+	})
+	return d.changes
+}
+
+// direction tells how a schema is used: received by the server or by the clients
+type direction int
+
+const (
+	request direction = iota
+	response
+)
+
+// differ accumulates the changes between two documents
+type differ struct {
+	previous, current *Document
+	changes           []Change
+	method, path      string
+}
+
+func (d *differ) add(breaking bool, method, path, location, message string) {
+	d.changes = append(d.changes, Change{Breaking: breaking, Method: method, Path: path, Location: location, Message: message})
+}
+
+// report adds a change of the operation being compared
+func (d *differ) report(breaking bool, location, format string, args ...interface{}) {
+	d.add(breaking, d.method, d.path, location, fmt.Sprintf(format, args...))
+}
+
+// operation compares the parameters and responses of an operation
+func (d *differ) operation(method, path string, previous, current *Operation) {
+	d.method, d.path = method, path
+
+	if !previous.Deprecated && current.Deprecated {
+		d.report(false, "", "operation deprecated")
+	}
+
+	previousParameters := parametersByKey(previous.Parameters)
+	currentParameters := parametersByKey(current.Parameters)
+	for _, key := range sortedKeys(previousParameters) {
+		before := previousParameters[key]
+		location := parameterLocation(before)
+		after, exists := currentParameters[key]
+		if !exists {
+			// Un parámetro path eliminado cambia la ruta; uno opcional se ignora sin más
+			d.report(before.In == "path" || before.In == "body", location, "parameter removed")
+			continue
+		}
+		if !before.Required && after.Required {
+			d.report(true, location, "parameter became required")
+		}
+		if before.In == "body" {
+			d.schema(request, location, before.Schema, after.Schema, nil)
+			continue
+		}
+		d.schema(request, location, parameterSchema(before), parameterSchema(after), nil)
+	}
+	for _, key := range sortedKeys(currentParameters) {
+		if _, exists := previousParameters[key]; exists {
+			continue
+		}
+		after := currentParameters[key]
+		if after.Required {
+			d.report(true, parameterLocation(after), "required parameter added")
+		} else {
+			d.report(false, parameterLocation(after), "optional parameter added")
+		}
+	}
+
+	for _, code := range previous.Responses.StatusCodes() {
+		location := "response " + code
+		before := previous.Responses[code]
+		after, exists := current.Responses[code]
+		if !exists {
+			d.report(true, location, "response removed")
+			continue
+		}
+		if before.Schema != nil && after.Schema == nil {
+			d.report(true, location, "body removed")
+			continue
+		}
+		d.schema(response, location+" body", before.Schema, after.Schema, nil)
+	}
+	for _, code := range current.Responses.StatusCodes() {
+		if _, exists := previous.Responses[code]; !exists {
+			d.report(false, "response "+code, "response added")
+		}
+	}
+}
+
+// schema compares two versions of a schema used in a direction
+// visited holds the pairs of definitions being compared, which end the recursion of cyclic models
+func (d *differ) schema(dir direction, location string, before, after *Schema, visited map[[2]string]bool) {
+	if before == nil || after == nil {
+		return
+	}
+	if before.Ref != "" || after.Ref != "" {
+		pair := [2]string{before.Ref, after.Ref}
+		if visited[pair] {
+			return
+		}
+		if visited == nil {
+			visited = make(map[[2]string]bool)
+		}
+		visited[pair] = true
+		defer delete(visited, pair)
+		before, after = resolve(d.previous, before), resolve(d.current, after)
+		if before == nil || after == nil {
+			return
+		}
+	}
+
+	beforeType, afterType := schemaType(before), schemaType(after)
+	if beforeType != afterType && beforeType != "" {
+		// integer cabe en number: ampliar lo recibido o reducir lo enviado no rompe a los clientes
+		widened := dir == request && beforeType == "integer" && afterType == "number" ||
+			dir == response && beforeType == "number" && afterType == "integer"
+		d.report(!widened, location, "type changed from %s to %s", describeType(before), describeType(after))
+		return
+	}
+	if before.Format != after.Format && before.Format != "" {
+		d.report(true, location, "format changed from %q to %q", before.Format, after.Format)
+	}
+
+	beforeNullable, afterNullable := nullable(before), nullable(after)
+	if dir == request && beforeNullable && !afterNullable {
+		d.report(true, location, "null is no longer accepted")
+	}
+	if dir == response && !beforeNullable && afterNullable {
+		d.report(true, location, "may now be null")
+	}
+
+	d.enum(dir, location, before.Enum, after.Enum)
+	if dir == request {
+		d.constraints(location, before, after)
+	}
+
+	switch afterType {
+	case "array":
+		d.schema(dir, location+"[]", before.Items, after.Items, visited)
+	case "object":
+		d.object(dir, location, before, after, visited)
+	}
+}
+
+// object compares the properties of two object schemas
+func (d *differ) object(dir direction, location string, before, after *Schema, visited map[[2]string]bool) {
+	beforeRequired := toSet(before.Required)
+	afterRequired := toSet(after.Required)
+	for _, name := range sortedKeys(before.Properties) {
+		property := location + "." + name
+		afterProperty, exists := after.Properties[name]
+		if !exists {
+			// Las respuestas pierden un campo; en las peticiones el campo pasa a ignorarse
+			d.report(dir == response, property, "property removed")
+			continue
+		}
+		if dir == request && !beforeRequired[name] && afterRequired[name] && !afterProperty.ReadOnly {
+			d.report(true, property, "property became required")
+		}
+		if dir == response && beforeRequired[name] && !afterRequired[name] {
+			d.report(true, property, "property is no longer always present")
+		}
+		d.schema(dir, property, before.Properties[name], afterProperty, visited)
+	}
+	for _, name := range sortedKeys(after.Properties) {
+		if _, exists := before.Properties[name]; exists {
+			continue
+		}
+		property := location + "." + name
+		if dir == request && afterRequired[name] && !after.Properties[name].ReadOnly {
+			d.report(true, property, "required property added")
+		} else {
+			d.report(false, property, "property added")
+		}
+	}
+}
+
+// enum compares the allowed values: requests must accept the previous ones, and responses may
+// not return new ones unnoticed
+func (d *differ) enum(dir direction, location string, before, after []interface{}) {
+	if len(after) == 0 {
+		return
+	}
+	if len(before) == 0 {
+		d.report(dir == request, location, "values restricted to %s", formatValues(after))
+		return
+	}
+	if removed := missingValues(before, after); len(removed) > 0 && dir == request {
+		d.report(true, location, "values %s no longer accepted", formatValues(removed))
+	}
+	if added := missingValues(after, before); len(added) > 0 && dir == response {
+		d.report(false, location, "values %s may be returned", formatValues(added))
+	}
+}
+
+// constraints reports the validation rules of a request schema that reject previously valid input
+func (d *differ) constraints(location string, before, after *Schema) {
+	narrowed := func(rule string, beforeValue, afterValue interface{}) {
+		d.report(true, location, "%s narrowed from %v to %v", rule, beforeValue, afterValue)
+	}
+	added := func(rule string, value interface{}) {
+		d.report(true, location, "%s %v added", rule, value)
+	}
+
+	switch {
+	case after.Minimum != nil && before.Minimum == nil:
+		added("minimum", *after.Minimum)
+	case after.Minimum != nil && *after.Minimum > *before.Minimum:
+		narrowed("minimum", *before.Minimum, *after.Minimum)
+	}
+	switch {
+	case after.Maximum != nil && before.Maximum == nil:
+		added("maximum", *after.Maximum)
+	case after.Maximum != nil && *after.Maximum < *before.Maximum:
+		narrowed("maximum", *before.Maximum, *after.Maximum)
+	}
+	switch {
+	case after.MinLength != nil && before.MinLength == nil:
+		added("minLength", *after.MinLength)
+	case after.MinLength != nil && *after.MinLength > *before.MinLength:
+		narrowed("minLength", *before.MinLength, *after.MinLength)
+	}
+	switch {
+	case after.MaxLength != nil && before.MaxLength == nil:
+		added("maxLength", *after.MaxLength)
+	case after.MaxLength != nil && *after.MaxLength < *before.MaxLength:
+		narrowed("maxLength", *before.MaxLength, *after.MaxLength)
+	}
+	switch {
+	case after.MinItems != nil && before.MinItems == nil:
+		added("minItems", *after.MinItems)
+	case after.MinItems != nil && *after.MinItems > *before.MinItems:
+		narrowed("minItems", *before.MinItems, *after.MinItems)
+	}
+	switch {
+	case after.MaxItems != nil && before.MaxItems == nil:
+		added("maxItems", *after.MaxItems)
+	case after.MaxItems != nil && *after.MaxItems < *before.MaxItems:
+		narrowed("maxItems", *before.MaxItems, *after.MaxItems)
+	}
+	if after.Pattern != "" && after.Pattern != before.Pattern {
+		d.report(true, location, "pattern changed to %q", after.Pattern)
+	}
+	if !before.ExclusiveMinimum && after.ExclusiveMinimum || !before.ExclusiveMaximum && after.ExclusiveMaximum {
+		d.report(true, location, "bounds became exclusive")
+	}
+}
+
+// resolve follows a reference to the definitions of a document
+func resolve(document *Document, schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 32; depth++ {
+		schema = document.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+	}
+	return schema
+}
+
+// schemaType returns the type of a schema; schemas with properties are objects
+func schemaType(schema *Schema) string {
+	if schema.Type == "" && len(schema.Properties) > 0 {
+		return "object"
+	}
+	return schema.Type
+}
+
+func describeType(schema *Schema) string {
+	if schema.Format != "" {
+		return schemaType(schema) + " (" + schema.Format + ")"
+	}
+	return schemaType(schema)
+}
+
+// nullable reports whether a schema accepts null, with the x-nullable extension
+func nullable(schema *Schema) bool {
+	value, _ := schema.Extensions["x-nullable"].(bool)
+	return value
+}
+
+// parameterSchema describes a non-body parameter as a schema
+func parameterSchema(parameter Parameter) *Schema {
+	if parameter.Schema != nil {
+		return parameter.Schema
+	}
+	return &Schema{Type: parameter.Type, Format: parameter.Format, Items: parameter.Items, Enum: parameter.Enum, Extensions: parameter.Extensions}
+}
+
+// parametersByKey indexes parameters by location and name
+func parametersByKey(parameters []Parameter) map[string]Parameter {
+	indexed := make(map[string]Parameter, len(parameters))
+	for _, parameter := range parameters {
+		indexed[parameter.In+" "+parameter.Name] = parameter
+	}
+	return indexed
+}
+
+func parameterLocation(parameter Parameter) string {
+	if parameter.In == "body" {
+		return "request body"
+	}
+	return parameter.In + " parameter " + parameter.Name
+}
+
+// missingValues returns the values of a not present in b
+func missingValues(a, b []interface{}) []interface{} {
+	var missing []interface{}
+	for _, value := range a {
+		found := false
+		for _, other := range b {
+			if reflect.DeepEqual(value, other) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
+
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprintf("%v", value)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+func methodIndex(method string) int {
+	for i, candidate := range Methods {
+		if candidate == method {
+			return i
+		}
+	}
+	return len(Methods)
+}
+
+func sortedKeys[M ~map[string]V, V any](values M) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
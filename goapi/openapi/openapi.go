@@ -0,0 +1,408 @@
+// Package openapi provides a typed model of the OpenAPI (Swagger 2.0) document generated by GoAPI
+// Plugins and tools (mock servers, diff tools, client generators) can work on these
+// structures directly instead of re-parsing the JSON specification
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Version is the specification version emitted by GoAPI
+const Version = "2.0"
+
+// Document is the root object of the specification
+type Document struct {
+	Swagger             string                     `json:"swagger"`
+	Info                Info                       `json:"info"`
+	Host                string                     `json:"host,omitempty"`
+	BasePath            string                     `json:"basePath,omitempty"`
+	Schemes             []string                   `json:"schemes,omitempty"`
+	Consumes            []string                   `json:"consumes,omitempty"`
+	Produces            []string                   `json:"produces,omitempty"`
+	Paths               Paths                      `json:"paths"`
+	Definitions         map[string]*Schema         `json:"definitions,omitempty"`
+	SecurityDefinitions map[string]*SecurityScheme `json:"securityDefinitions,omitempty"`
+	Security            []SecurityRequirement      `json:"security,omitempty"`
+	Tags                []Tag                      `json:"tags,omitempty"`
+	ExternalDocs        *ExternalDocs              `json:"externalDocs,omitempty"`
+	Extensions          Extensions                 `json:"-"`
+}
+
+// NewDocument creates an empty document with the given metadata
+func NewDocument(info Info) *Document {
+	return &Document{
+		Swagger: Version,
+		Info:    info,
+		Paths:   make(Paths),
+	}
+}
+
+// Info contains the API metadata
+type Info struct {
+	Title          string   `json:"title"`
+	Description    string   `json:"description,omitempty"`
+	TermsOfService string   `json:"termsOfService,omitempty"`
+	Version        string   `json:"version"`
+	Contact        *Contact `json:"contact,omitempty"`
+	License        *License `json:"license,omitempty"`
+}
+
+// Contact contains contact information for the API
+type Contact struct {
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// License contains license information for the API
+type License struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Tag adds metadata to a tag used by operations
+type Tag struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+}
+
+// ExternalDocs references external documentation
+type ExternalDocs struct {
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+}
+
+// Paths maps a templated path ("/users/{id}") to its operations
+type Paths map[string]*PathItem
+
+// PathItem describes the operations available on a single path
+type PathItem struct {
+	Get        *Operation  `json:"get,omitempty"`
+	Put        *Operation  `json:"put,omitempty"`
+	Post       *Operation  `json:"post,omitempty"`
+	Delete     *Operation  `json:"delete,omitempty"`
+	Options    *Operation  `json:"options,omitempty"`
+	Head       *Operation  `json:"head,omitempty"`
+	Patch      *Operation  `json:"patch,omitempty"`
+	Parameters []Parameter `json:"parameters,omitempty"`
+}
+
+// Operation returns the operation for an HTTP method (case insensitive), or nil
+func (item *PathItem) Operation(method string) *Operation {
+	if slot := item.slot(method); slot != nil {
+		return *slot
+	}
+	return nil
+}
+
+// SetOperation sets the operation for an HTTP method (case insensitive)
+func (item *PathItem) SetOperation(method string, operation *Operation) {
+	if slot := item.slot(method); slot != nil {
+		*slot = operation
+	}
+}
+
+// Operations returns the defined operations keyed by upper-case HTTP method
+func (item *PathItem) Operations() map[string]*Operation {
+	operations := make(map[string]*Operation)
+	for _, method := range Methods {
+		if operation := item.Operation(method); operation != nil {
+			operations[method] = operation
+		}
+	}
+	return operations
+}
+
+// Methods lists the HTTP methods supported by a path item, in document order
+var Methods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH"}
+
+// slot returns a pointer to the field holding the operation for a method
+func (item *PathItem) slot(method string) **Operation {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return &item.Get
+	case "PUT":
+		return &item.Put
+	case "POST":
+		return &item.Post
+	case "DELETE":
+		return &item.Delete
+	case "OPTIONS":
+		return &item.Options
+	case "HEAD":
+		return &item.Head
+	case "PATCH":
+		return &item.Patch
+	}
+	return nil
+}
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	Tags         []string              `json:"tags,omitempty"`
+	Summary      string                `json:"summary,omitempty"`
+	Description  string                `json:"description,omitempty"`
+	ExternalDocs *ExternalDocs         `json:"externalDocs,omitempty"`
+	OperationID  string                `json:"operationId,omitempty"`
+	Consumes     []string              `json:"consumes,omitempty"`
+	Produces     []string              `json:"produces,omitempty"`
+	Parameters   []Parameter           `json:"parameters,omitempty"`
+	Responses    Responses             `json:"responses"`
+	Deprecated   bool                  `json:"deprecated,omitempty"`
+	Security     []SecurityRequirement `json:"security,omitempty"`
+	Extensions   Extensions            `json:"-"`
+}
+
+// Responses maps a status code ("200", "default") to a response
+type Responses map[string]*Response
+
+// StatusCodes returns the declared status codes in ascending order
+func (responses Responses) StatusCodes() []string {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Parameter describes a single operation parameter
+type Parameter struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required"`
+	Type        string        `json:"type,omitempty"`
+	Format      string        `json:"format,omitempty"`
+	Items       *Schema       `json:"items,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Schema      *Schema       `json:"schema,omitempty"`
+	Extensions  Extensions    `json:"-"`
+}
+
+// Response describes a single response from an operation
+type Response struct {
+	Description string                 `json:"description"`
+	Schema      *Schema                `json:"schema,omitempty"`
+	Headers     map[string]*Header     `json:"headers,omitempty"`
+	Examples    map[string]interface{} `json:"examples,omitempty"`
+	Extensions  Extensions             `json:"-"`
+}
+
+// Header describes a response header
+type Header struct {
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+}
+
+// Schema describes the structure of a body, property or array item
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int64             `json:"minLength,omitempty"`
+	MaxLength            *int64             `json:"maxLength,omitempty"`
+	MinItems             *int64             `json:"minItems,omitempty"`
+	MaxItems             *int64             `json:"maxItems,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	XML                  *XML               `json:"xml,omitempty"`
+	Extensions           Extensions         `json:"-"`
+}
+
+// XML describes how a schema is represented in XML payloads
+type XML struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	Attribute bool   `json:"attribute,omitempty"`
+	Wrapped   bool   `json:"wrapped,omitempty"` // Arrays enclosed in an element of their own name
+}
+
+// SecurityScheme describes a security scheme usable by operations
+type SecurityScheme struct {
+	Type             string            `json:"type"`
+	Description      string            `json:"description,omitempty"`
+	Name             string            `json:"name,omitempty"`
+	In               string            `json:"in,omitempty"`
+	Flow             string            `json:"flow,omitempty"`
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes,omitempty"`
+}
+
+// SecurityRequirement maps a security scheme name to the required scopes
+type SecurityRequirement map[string][]string
+
+// Extensions holds vendor extensions ("x-..." fields)
+type Extensions map[string]interface{}
+
+// Set sets a vendor extension, adding the "x-" prefix when missing
+func (extensions *Extensions) Set(name string, value interface{}) {
+	if *extensions == nil {
+		*extensions = make(Extensions)
+	}
+	if !strings.HasPrefix(name, "x-") {
+		name = "x-" + name
+	}
+	(*extensions)[name] = value
+}
+
+// JSON returns the indented JSON representation of the document
+func (document *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// Parse decodes a JSON specification into a typed document
+func Parse(data []byte) (*Document, error) {
+	document := &Document{}
+	if err := json.Unmarshal(data, document); err != nil {
+		return nil, err
+	}
+	if document.Paths == nil {
+		document.Paths = make(Paths)
+	}
+	return document, nil
+}
+
+// MarshalJSON encodes the document including its vendor extensions
+func (document Document) MarshalJSON() ([]byte, error) {
+	type plain Document
+	return marshalWithExtensions(plain(document), document.Extensions)
+}
+
+// UnmarshalJSON decodes the document including its vendor extensions
+func (document *Document) UnmarshalJSON(data []byte) error {
+	type plain Document
+	if err := json.Unmarshal(data, (*plain)(document)); err != nil {
+		return err
+	}
+	return unmarshalExtensions(data, &document.Extensions)
+}
+
+// MarshalJSON encodes the operation including its vendor extensions
+// An empty, non-nil Security is written as "security": [], which lifts the global requirements
+func (operation Operation) MarshalJSON() ([]byte, error) {
+	type plain Operation
+	extensions := operation.Extensions
+	if operation.Security != nil && len(operation.Security) == 0 {
+		extensions = make(Extensions, len(operation.Extensions)+1)
+		for name, extension := range operation.Extensions {
+			extensions[name] = extension
+		}
+		extensions["security"] = []SecurityRequirement{}
+	}
+	return marshalWithExtensions(plain(operation), extensions)
+}
+
+// UnmarshalJSON decodes the operation including its vendor extensions
+func (operation *Operation) UnmarshalJSON(data []byte) error {
+	type plain Operation
+	if err := json.Unmarshal(data, (*plain)(operation)); err != nil {
+		return err
+	}
+	return unmarshalExtensions(data, &operation.Extensions)
+}
+
+// MarshalJSON encodes the parameter including its vendor extensions
+func (parameter Parameter) MarshalJSON() ([]byte, error) {
+	type plain Parameter
+	return marshalWithExtensions(plain(parameter), parameter.Extensions)
+}
+
+// UnmarshalJSON decodes the parameter including its vendor extensions
+func (parameter *Parameter) UnmarshalJSON(data []byte) error {
+	type plain Parameter
+	if err := json.Unmarshal(data, (*plain)(parameter)); err != nil {
+		return err
+	}
+	return unmarshalExtensions(data, &parameter.Extensions)
+}
+
+// MarshalJSON encodes the response including its vendor extensions
+func (response Response) MarshalJSON() ([]byte, error) {
+	type plain Response
+	return marshalWithExtensions(plain(response), response.Extensions)
+}
+
+// UnmarshalJSON decodes the response including its vendor extensions
+func (response *Response) UnmarshalJSON(data []byte) error {
+	type plain Response
+	if err := json.Unmarshal(data, (*plain)(response)); err != nil {
+		return err
+	}
+	return unmarshalExtensions(data, &response.Extensions)
+}
+
+// MarshalJSON encodes the schema including its vendor extensions
+func (schema Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	return marshalWithExtensions(plain(schema), schema.Extensions)
+}
+
+// UnmarshalJSON decodes the schema including its vendor extensions
+func (schema *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	if err := json.Unmarshal(data, (*plain)(schema)); err != nil {
+		return err
+	}
+	return unmarshalExtensions(data, &schema.Extensions)
+}
+
+// marshalWithExtensions encodes a value and merges the vendor extensions into the resulting object
+func marshalWithExtensions(value interface{}, extensions Extensions) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil || len(extensions) == 0 {
+		return data, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, extension := range extensions {
+		encoded, err := json.Marshal(extension)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = encoded
+	}
+	return json.Marshal(fields)
+}
+
+// unmarshalExtensions collects the "x-" fields of a JSON object
+func unmarshalExtensions(data []byte, extensions *Extensions) error {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name, raw := range fields {
+		if !strings.HasPrefix(name, "x-") {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		extensions.Set(name, value)
+	}
+	return nil
+}
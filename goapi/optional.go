@@ -0,0 +1,27 @@
+package goapi
+
+import "github.com/esteban-ll-aguilar/goapi/goapi/validation"
+
+// Optional is a request or response field that tells "absent" from "null" from a value
+// Decoding JSON sets Present for members in the document and Null for null members, so a
+// partial update can leave absent fields untouched, clear null ones and store the rest:
+//
+//	type UpdateUser struct {
+//		Name     goapi.Optional[string] `json:"name,omitzero" validate:"omitempty,min=2"`
+//		Nickname goapi.Optional[string] `json:"nickname,omitzero"`
+//	}
+//
+// The validate tags apply to the value: omitempty skips absent and null fields and required
+// rejects them. The spec documents the wrapped type with x-nullable, and omitzero leaves absent
+// fields out of encoded responses
+type Optional[T any] = validation.Nullable[T]
+
+// Some returns an Optional holding a value
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Present: true, Value: value}
+}
+
+// Null returns an Optional that is explicitly null
+func Null[T any]() Optional[T] {
+	return Optional[T]{Present: true, Null: true}
+}
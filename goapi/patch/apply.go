@@ -0,0 +1,88 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// Media types of the patch documents
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// ErrUnsupportedMediaType is returned by Apply for requests that are not a patch document
+var ErrUnsupportedMediaType = errors.New("patch: unsupported media type")
+
+// Apply applies the patch in the body of a request to target, a pointer to the current model,
+// and validates the result with the validator of the request
+// The Content-Type selects the format: application/json-patch+json is a JSON Patch, and
+// application/merge-patch+json or application/json a merge patch. target only changes when the
+// patch applies and the result is valid; validation failures are returned as
+// validation.ValidationErrors. Status maps the errors to a response status
+//
+//	user, _ := users.Get(c, id)
+//	if err := patch.Apply(c, &user); err != nil {
+//		c.JSON(patch.Status(err), responses.ErrorResponse{Detail: err.Error()})
+//		return
+//	}
+//	users.Update(c, id, user)
+func Apply(c *gin.Context, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("patch: target must be a non-nil pointer")
+	}
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	var applyPatch func(target interface{}, patch []byte) error
+	switch mediaType {
+	case JSONPatchType:
+		applyPatch = ApplyJSONPatch
+	case MergePatchType, "application/json", "":
+		applyPatch = ApplyMergePatch
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		return fmt.Errorf("patch: reading body: %w", err)
+	}
+
+	// Patched on a copy so an invalid result leaves target untouched
+	patched := reflect.New(value.Elem().Type())
+	patched.Elem().Set(value.Elem())
+	if err := applyPatch(patched.Interface(), body); err != nil {
+		return err
+	}
+	if patched.Elem().Kind() == reflect.Struct {
+		if err := validation.FromContext(c).ValidateStruct(patched.Interface()); err != nil {
+			return validation.FormatValidationErrorsLocale(err, i18n.Locale(c))
+		}
+	}
+	value.Elem().Set(patched.Elem())
+	return nil
+}
+
+// Status returns the response status of an error of Apply: 415 for other media types, 400 for
+// malformed patches, 409 when a test operation fails and 422 for missing paths and invalid results
+func Status(err error) int {
+	var validationErrors validation.ValidationErrors
+	switch {
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrTestFailed):
+		return http.StatusConflict
+	case errors.Is(err, ErrPathNotFound), errors.As(err, &validationErrors):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrInvalidPatch):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
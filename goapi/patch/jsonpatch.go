@@ -0,0 +1,300 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Operation is an operation of a JSON Patch (RFC 6902)
+type Operation struct {
+	Op    string          `json:"op" example:"replace"` // add, remove, replace, move, copy or test
+	Path  string          `json:"path" example:"/name"` // JSON Pointer (RFC 6901) of the target
+	From  string          `json:"from,omitempty"`       // Source of move and copy
+	Value json.RawMessage `json:"value,omitempty"`      // Value of add, replace and test
+}
+
+// JSONPatch applies a JSON Patch (RFC 6902) to a JSON document
+// Operations run in order and the patch is atomic: when one fails the document is not changed.
+// A failed test operation returns ErrTestFailed and a missing path ErrPathNotFound
+func JSONPatch(document, patch []byte) ([]byte, error) {
+	var operations []Operation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	target, err := decode(document)
+	if err != nil {
+		return nil, fmt.Errorf("patch: invalid document: %w", err)
+	}
+	for i, operation := range operations {
+		if target, err = applyOperation(target, operation); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, operation.Op, operation.Path, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+// ApplyJSONPatch applies a JSON Patch to a value, such as a pointer to a struct
+func ApplyJSONPatch(target interface{}, patch []byte) error {
+	return applyTo(target, func(document []byte) ([]byte, error) {
+		return JSONPatch(document, patch)
+	})
+}
+
+// applyOperation applies one operation and returns the new document
+func applyOperation(document interface{}, operation Operation) (interface{}, error) {
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		}
+		value, err := decode(operation.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		switch operation.Op {
+		case "add":
+			return add(document, path, value)
+		case "replace":
+			if _, err := get(document, path); err != nil {
+				return nil, err
+			}
+			if document, _, err = remove(document, path); err != nil {
+				return nil, err
+			}
+			return add(document, path, value)
+		default:
+			current, err := get(document, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return document, nil
+		}
+	case "remove":
+		document, _, err = remove(document, path)
+		return document, err
+	case "move", "copy":
+		from, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+		if operation.Op == "copy" {
+			value, err := get(document, from)
+			if err != nil {
+				return nil, err
+			}
+			return add(document, path, deepCopy(value))
+		}
+		if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return nil, fmt.Errorf("%w: cannot move a value into itself", ErrInvalidPatch)
+		}
+		document, value, err := remove(document, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(document, path, value)
+	}
+	return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, operation.Op)
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: path %q must start with /", ErrInvalidPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// get returns the value at a path
+func get(document interface{}, path []string) (interface{}, error) {
+	current := document
+	for _, token := range path {
+		switch container := current.(type) {
+		case map[string]interface{}:
+			value, exists := container[token]
+			if !exists {
+				return nil, ErrPathNotFound
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = container[index]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+	return current, nil
+}
+
+// add inserts a value at a path: it sets object members and inserts into arrays ("-" appends)
+func add(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(document, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		container[token] = value
+		return document, nil
+	case []interface{}:
+		index := len(container)
+		if token != "-" {
+			if index, err = arrayIndex(token, len(container)); err != nil {
+				return nil, err
+			}
+		}
+		grown := append(container[:index:index], append([]interface{}{value}, container[index:]...)...)
+		return replaceAt(document, path[:len(path)-1], grown)
+	}
+	return nil, ErrPathNotFound
+}
+
+// remove deletes the value at a path and returns it
+func remove(document interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, document, nil
+	}
+	parent, err := get(document, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		value, exists := container[token]
+		if !exists {
+			return nil, nil, ErrPathNotFound
+		}
+		delete(container, token)
+		return document, value, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := container[index]
+		shrunk := append(container[:index:index], container[index+1:]...)
+		document, err = replaceAt(document, path[:len(path)-1], shrunk)
+		return document, value, err
+	}
+	return nil, nil, ErrPathNotFound
+}
+
+// replaceAt stores a new array at a path, since growing or shrinking one creates a new slice
+func replaceAt(document interface{}, path []string, array []interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return array, nil
+	}
+	parent, err := get(document, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		container[token] = array
+	case []interface{}:
+		index, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		container[index] = array
+	}
+	return document, nil
+}
+
+// arrayIndex parses an array index token, which must be between 0 and max
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+	if index < 0 || index > max {
+		return 0, ErrPathNotFound
+	}
+	return index, nil
+}
+
+// jsonEqual compares two decoded JSON values; numbers are equal when their values are
+func jsonEqual(a, b interface{}) bool {
+	switch left := a.(type) {
+	case json.Number:
+		right, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okX := new(big.Rat).SetString(left.String())
+		y, okY := new(big.Rat).SetString(right.String())
+		return okX && okY && x.Cmp(y) == 0
+	case map[string]interface{}:
+		right, ok := b.(map[string]interface{})
+		if !ok || len(left) != len(right) {
+			return false
+		}
+		for name, value := range left {
+			other, exists := right[name]
+			if !exists || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		right, ok := b.([]interface{})
+		if !ok || len(left) != len(right) {
+			return false
+		}
+		for i := range left {
+			if !jsonEqual(left[i], right[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// deepCopy copies a decoded JSON value so a copied value is not shared with its source
+func deepCopy(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for name, member := range typed {
+			copied[name] = deepCopy(member)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for i, element := range typed {
+			copied[i] = deepCopy(element)
+		}
+		return copied
+	}
+	return value
+}
@@ -0,0 +1,121 @@
+// Package patch applies partial updates to JSON documents and Go values
+// MergePatch implements JSON Merge Patch (RFC 7386) and JSONPatch implements JSON Patch
+// (RFC 6902). Apply reads either from a request, applies it to an existing model and validates
+// the result, so PATCH handlers can tell a missing field from a zero value
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Errors returned when a patch cannot be applied
+var (
+	ErrInvalidPatch = errors.New("patch: invalid patch document")
+	ErrPathNotFound = errors.New("patch: path not found")
+	ErrTestFailed   = errors.New("patch: test operation failed")
+)
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to a JSON document
+// Members of the patch replace those of the document, null members remove them and objects are
+// merged recursively; a patch that is not an object replaces the whole document
+func MergePatch(document, patch []byte) ([]byte, error) {
+	target, err := decode(document)
+	if err != nil {
+		return nil, fmt.Errorf("patch: invalid document: %w", err)
+	}
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return json.Marshal(mergeValue(target, changes))
+}
+
+// mergeValue implements the MergePatch algorithm of RFC 7386
+func mergeValue(target, patch interface{}) interface{} {
+	changes, isObject := patch.(map[string]interface{})
+	if !isObject {
+		return patch
+	}
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{})
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+		object[name] = mergeValue(object[name], value)
+	}
+	return object
+}
+
+// ApplyMergePatch applies a JSON Merge Patch to a value, such as a pointer to a struct
+// The value is encoded to JSON, patched and decoded again, so fields removed by the patch are
+// reset to their zero value; fields without a JSON representation are kept
+func ApplyMergePatch(target interface{}, patch []byte) error {
+	return applyTo(target, func(document []byte) ([]byte, error) {
+		return MergePatch(document, patch)
+	})
+}
+
+// applyTo patches the JSON encoding of a pointer target and decodes the result into it
+func applyTo(target interface{}, apply func(document []byte) ([]byte, error)) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("patch: target must be a non-nil pointer")
+	}
+	document, err := json.Marshal(target)
+	if err != nil {
+		return fmt.Errorf("patch: encoding target: %w", err)
+	}
+	patched, err := apply(document)
+	if err != nil {
+		return err
+	}
+	// The JSON fields are decoded from zero so removed members do not keep their previous
+	// content; fields hidden from JSON (json:"-", unexported) keep theirs
+	fresh := reflect.New(value.Elem().Type())
+	if fresh.Elem().Kind() == reflect.Struct {
+		fresh.Elem().Set(value.Elem())
+		resetJSONFields(fresh.Elem())
+	}
+	if err := json.Unmarshal(patched, fresh.Interface()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	value.Elem().Set(fresh.Elem())
+	return nil
+}
+
+// resetJSONFields sets the fields of a struct encoded to JSON to their zero value
+func resetJSONFields(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			resetJSONFields(value.Field(i))
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("json") == "-" || !value.Field(i).CanSet() {
+			continue
+		}
+		value.Field(i).Set(reflect.Zero(field.Type))
+	}
+}
+
+// decode parses JSON keeping numbers exact
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return value, nil
+}
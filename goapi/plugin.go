@@ -0,0 +1,113 @@
+package goapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Plugin is an extension that can be packaged separately and installed into a GoAPI instance
+// Install receives the API so the plugin can register routes, middlewares, dependencies and hooks
+type Plugin interface {
+	Name() string
+	Install(api *GoAPI) error
+}
+
+// StartupPlugin is implemented by plugins that need to run code when the server starts
+type StartupPlugin interface {
+	Plugin
+	OnStartup(ctx context.Context) error
+}
+
+// ShutdownPlugin is implemented by plugins that need to release resources when the server stops
+type ShutdownPlugin interface {
+	Plugin
+	OnShutdown(ctx context.Context) error
+}
+
+// LifecycleHook is a function executed on server startup or shutdown
+type LifecycleHook func(ctx context.Context) error
+
+// ErrPluginAlreadyInstalled is returned when a plugin with the same name is installed twice
+var ErrPluginAlreadyInstalled = errors.New("plugin already installed")
+
+// UsePlugin installs one or more plugins into the API
+// Plugins implementing StartupPlugin or ShutdownPlugin are automatically wired into the lifecycle
+func (a *GoAPI) UsePlugin(plugins ...Plugin) error {
+	for _, plugin := range plugins {
+		name := plugin.Name()
+		if _, exists := a.Plugin(name); exists {
+			return fmt.Errorf("%w: %s", ErrPluginAlreadyInstalled, name)
+		}
+
+		if err := plugin.Install(a); err != nil {
+			return fmt.Errorf("error installing plugin %s: %w", name, err)
+		}
+
+		if startupPlugin, ok := plugin.(StartupPlugin); ok {
+			a.OnStartup(startupPlugin.OnStartup)
+		}
+		if shutdownPlugin, ok := plugin.(ShutdownPlugin); ok {
+			a.OnShutdown(shutdownPlugin.OnShutdown)
+		}
+
+		a.plugins = append(a.plugins, plugin)
+	}
+	return nil
+}
+
+// Plugin returns an installed plugin by name
+func (a *GoAPI) Plugin(name string) (Plugin, bool) {
+	for _, plugin := range a.plugins {
+		if plugin.Name() == name {
+			return plugin, true
+		}
+	}
+	return nil, false
+}
+
+// Plugins returns the installed plugins in installation order
+func (a *GoAPI) Plugins() []Plugin {
+	return append([]Plugin(nil), a.plugins...)
+}
+
+// OnStartup registers a hook executed before the server starts accepting requests
+// Hooks run in registration order; an error aborts the startup
+func (a *GoAPI) OnStartup(hook LifecycleHook) {
+	a.startupHooks = append(a.startupHooks, hook)
+}
+
+// OnShutdown registers a hook executed when the server is shut down
+// Hooks run in reverse registration order, after in-flight requests have been drained
+func (a *GoAPI) OnShutdown(hook LifecycleHook) {
+	a.shutdownHooks = append(a.shutdownHooks, hook)
+}
+
+// Startup runs the startup hooks
+// It is called automatically by Run, and only needs to be called when serving the router manually
+func (a *GoAPI) Startup(ctx context.Context) error {
+	for _, hook := range a.startupHooks {
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("startup hook failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// runShutdownHooks runs the shutdown hooks in reverse order, collecting every error and duration
+func (a *GoAPI) runShutdownHooks(ctx context.Context) ([]HookReport, error) {
+	var hookErrors []error
+	reports := make([]HookReport, 0, len(a.shutdownHooks))
+	for i := len(a.shutdownHooks) - 1; i >= 0; i-- {
+		started := time.Now()
+		err := a.shutdownHooks[i](ctx)
+		report := HookReport{Name: functionName(a.shutdownHooks[i]), DurationMS: time.Since(started).Milliseconds()}
+		if err != nil {
+			report.Error = err.Error()
+			hookErrors = append(hookErrors, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, errors.Join(hookErrors...)
+}
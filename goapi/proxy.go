@@ -0,0 +1,45 @@
+package goapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/proxy"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// Proxy registers a route forwarding requests to another service, documented like any other route
+// A "*name" wildcard selects the part of the path appended to the target: "/payments/*path" forwards
+// /payments/charges/1 to target + "/charges/1"; without a wildcard the whole path is appended
+// Bodies are streamed to the upstream service (see WithStreaming); opts document the route
+func (a *GoAPI) Proxy(path, target string, config proxy.Config, opts ...router.RouteOption) error {
+	upstream, err := proxy.New(target, config)
+	if err != nil {
+		return err
+	}
+	handler := upstream.Handler(wildcardName(path))
+
+	options := append([]router.RouteOption{
+		router.WithStreaming(),
+		router.WithResponse(http.StatusOK, "Upstream service response"),
+		router.WithResponse(http.StatusBadGateway, "Upstream service unavailable"),
+		router.WithResponse(http.StatusGatewayTimeout, "Upstream service timed out"),
+	}, opts...)
+
+	methods := config.Methods
+	if len(methods) == 0 {
+		methods = router.AnyMethods
+	}
+	for _, method := range methods {
+		a.AddRoute(method, path, handler, router.AnyMethodOptions(method, options)...)
+	}
+	return nil
+}
+
+// wildcardName returns the name of the catch-all parameter of a path ("" if it has none)
+func wildcardName(path string) string {
+	if index := strings.LastIndex(path, "/*"); index >= 0 {
+		return path[index+2:]
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+)
+
+// CacheConfig configures the caching of upstream responses
+// Only 200 responses to anonymous GET requests are cached: without Authorization or Cookie headers
+// nor a user authenticated by a middleware (API keys, for example). Responses are never cached when
+// the upstream answers Cache-Control no-store, no-cache or private, or sets cookies
+type CacheConfig struct {
+	TTL          time.Duration // Lifetime of a response, shortened by an upstream max-age (default 1 minute)
+	MaxEntries   int           // Cached responses kept (default 1000)
+	MaxBodyBytes int64         // Larger responses are not cached (default 1 MB)
+	Vary         []string      // Request headers that are part of the cache key (Accept-Language, for example)
+}
+
+// cacheKey holds the cache key of a request being forwarded in its context
+type cacheKey struct{}
+
+// responseCache keeps upstream responses in memory
+type responseCache struct {
+	config  CacheConfig
+	mutex   sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+// cachedResponse is a stored upstream response
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// newResponseCache creates a cache applying the defaults
+func newResponseCache(config CacheConfig) *responseCache {
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 1000
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 1 << 20
+	}
+	return &responseCache{config: config, entries: make(map[string]*cachedResponse)}
+}
+
+// cacheableRequest reports whether the response to a request may be shared with other clients
+func cacheableRequest(c *gin.Context, request *http.Request) bool {
+	return request.Method == http.MethodGet &&
+		request.Header.Get("Authorization") == "" &&
+		request.Header.Get("Cookie") == "" &&
+		!authenticated(c)
+}
+
+// authenticated reports whether an authentication middleware identified the client, whatever
+// header carried its credentials
+func authenticated(c *gin.Context) bool {
+	for _, key := range []string{dependencies.CurrentUserKey, dependencies.ClaimsKey, dependencies.UserIDKey} {
+		if _, found := c.Get(key); found {
+			return true
+		}
+	}
+	return false
+}
+
+// key identifies a request in the cache
+func (cache *responseCache) key(request *http.Request) string {
+	var key strings.Builder
+	key.WriteString(request.URL.Path)
+	key.WriteString("?")
+	key.WriteString(request.URL.RawQuery)
+	// Bodies are cached as encoded by the upstream service
+	key.WriteString("\n")
+	key.WriteString(request.Header.Get("Accept-Encoding"))
+	for _, name := range cache.config.Vary {
+		key.WriteString("\n")
+		key.WriteString(request.Header.Get(name))
+	}
+	return key.String()
+}
+
+// serve writes a cached response, reporting whether there was a fresh one
+func (cache *responseCache) serve(w http.ResponseWriter, key string) bool {
+	cache.mutex.Lock()
+	entry, found := cache.entries[key]
+	cache.mutex.Unlock()
+	if !found || time.Now().After(entry.expires) {
+		return false
+	}
+
+	header := w.Header()
+	for name, values := range entry.header {
+		header[name] = values
+	}
+	header.Set("X-Cache", "HIT")
+	w.WriteHeader(entry.status)
+	_, _ = w.Write(entry.body)
+	return true
+}
+
+// store caches a response when allowed; the body is read and replaced by a copy
+func (cache *responseCache) store(key string, response *http.Response) error {
+	ttl, storable := cache.lifetime(response)
+	if !storable || response.ContentLength > cache.config.MaxBodyBytes {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, cache.config.MaxBodyBytes+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(body)) > cache.config.MaxBodyBytes {
+		// Too large: forward it without caching
+		response.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), response.Body), Closer: response.Body}
+		return nil
+	}
+	_ = response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := response.Header.Clone()
+	header.Del("X-Cache")
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if len(cache.entries) >= cache.config.MaxEntries {
+		cache.evict()
+	}
+	cache.entries[key] = &cachedResponse{
+		status:  response.StatusCode,
+		header:  header,
+		body:    body,
+		expires: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+// lifetime returns how long a response may be cached
+func (cache *responseCache) lifetime(response *http.Response) (time.Duration, bool) {
+	if response.StatusCode != http.StatusOK || response.Header.Get("Set-Cookie") != "" {
+		return 0, false
+	}
+	ttl := cache.config.TTL
+	for _, directive := range strings.Split(response.Header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store", directive == "no-cache", directive == "private":
+			return 0, false
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(directive[len("max-age="):]); err == nil {
+				ttl = min(ttl, time.Duration(seconds)*time.Second)
+			}
+		}
+	}
+	return ttl, ttl > 0
+}
+
+// evict makes room for a new entry: expired responses go first, then an arbitrary one
+// The caller holds the mutex
+func (cache *responseCache) evict() {
+	now := time.Now()
+	for key, entry := range cache.entries {
+		if now.After(entry.expires) {
+			delete(cache.entries, key)
+		}
+	}
+	for key := range cache.entries {
+		if len(cache.entries) < cache.config.MaxEntries {
+			return
+		}
+		delete(cache.entries, key)
+	}
+}
+
+// readCloser combines a reader with the closer of the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
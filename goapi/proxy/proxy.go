@@ -0,0 +1,178 @@
+// Package proxy forwards requests to other services, so a GoAPI gateway can document and serve
+// several microservices behind a single API
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrTimeout is returned when the upstream service does not answer within Config.Timeout
+var ErrTimeout = errors.New("upstream timeout")
+
+// Config configures a proxied route
+type Config struct {
+	Methods []string // Methods forwarded and documented (default router.AnyMethods)
+
+	Timeout       time.Duration // Per attempt, until the upstream response headers arrive (default 30s)
+	Retries       int           // Extra attempts for idempotent requests without body
+	RetryBackoff  time.Duration // Wait before the first retry, doubled on every attempt (default 100ms)
+	RetryStatuses []int         // Upstream statuses retried, besides connection errors (default 502, 503, 504)
+
+	PreserveHost          bool              // Send the client Host header instead of the target host
+	SetHeaders            map[string]string // Request headers replaced before forwarding (an internal API key, for example)
+	RemoveHeaders         []string          // Request headers not forwarded (Cookie, for example)
+	ResponseHeaders       map[string]string // Response headers replaced on the way back
+	RemoveResponseHeaders []string          // Upstream response headers not returned (Server, for example)
+
+	Cache     *CacheConfig      // Caches successful GET responses (nil = no caching)
+	Transport http.RoundTripper // Transport to the upstream service (default http.DefaultTransport)
+}
+
+// DefaultConfig returns a proxy configuration with a 30 second timeout and no retries
+func DefaultConfig() Config {
+	return Config{
+		Timeout:       30 * time.Second,
+		RetryBackoff:  100 * time.Millisecond,
+		RetryStatuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+// Proxy forwards requests to a target URL
+type Proxy struct {
+	target  *url.URL
+	config  Config
+	reverse *httputil.ReverseProxy
+	cache   *responseCache
+}
+
+// New creates a proxy to a target URL; its path is prepended to the forwarded paths
+func New(target string, config Config) (*Proxy, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy target %q: %w", target, err)
+	}
+	if targetURL.Scheme == "" || targetURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy target %q: scheme and host are required", target)
+	}
+
+	defaults := DefaultConfig()
+	if config.Timeout == 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.RetryBackoff == 0 {
+		config.RetryBackoff = defaults.RetryBackoff
+	}
+	if config.RetryStatuses == nil {
+		config.RetryStatuses = defaults.RetryStatuses
+	}
+	if config.Transport == nil {
+		config.Transport = http.DefaultTransport
+	}
+
+	p := &Proxy{target: targetURL, config: config}
+	if config.Cache != nil {
+		p.cache = newResponseCache(*config.Cache)
+	}
+	p.reverse = &httputil.ReverseProxy{
+		Rewrite:        p.rewrite,
+		Transport:      &retryTransport{config: config},
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.errorHandler,
+	}
+	return p, nil
+}
+
+// Handler returns the gin handler forwarding requests
+// With a wildcard parameter name, only its value is appended to the target path; otherwise the whole path is
+func (p *Proxy) Handler(wildcard string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if wildcard != "" {
+			path = c.Param(wildcard)
+		}
+
+		forwarded := *c.Request.URL
+		forwarded.Path = path
+		forwarded.RawPath = ""
+		request := c.Request.WithContext(c.Request.Context())
+		request.URL = &forwarded
+
+		if p.cache != nil && cacheableRequest(c, request) {
+			key := p.cache.key(request)
+			if request.Header.Get("Cache-Control") != "no-cache" && p.cache.serve(c.Writer, key) {
+				c.Abort()
+				return
+			}
+			request = request.WithContext(context.WithValue(request.Context(), cacheKey{}, key))
+		}
+
+		defer func() {
+			// The upstream body broke after the response started: abort the response quietly
+			if recovered := recover(); recovered != nil {
+				if recovered != http.ErrAbortHandler {
+					panic(recovered)
+				}
+				c.Abort()
+			}
+		}()
+		p.reverse.ServeHTTP(c.Writer, request)
+		c.Abort()
+	}
+}
+
+// rewrite builds the upstream request
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	pr.SetURL(p.target)
+	pr.SetXForwarded()
+	if p.config.PreserveHost {
+		pr.Out.Host = pr.In.Host
+	}
+	for _, name := range p.config.RemoveHeaders {
+		pr.Out.Header.Del(name)
+	}
+	for name, value := range p.config.SetHeaders {
+		pr.Out.Header.Set(name, value)
+	}
+}
+
+// modifyResponse rewrites the upstream response headers and stores cacheable responses
+func (p *Proxy) modifyResponse(response *http.Response) error {
+	for _, name := range p.config.RemoveResponseHeaders {
+		response.Header.Del(name)
+	}
+	for name, value := range p.config.ResponseHeaders {
+		response.Header.Set(name, value)
+	}
+	if key, ok := response.Request.Context().Value(cacheKey{}).(string); ok {
+		response.Header.Set("X-Cache", "MISS")
+		return p.cache.store(key, response)
+	}
+	return nil
+}
+
+// errorHandler answers 502, or 504 when the upstream service timed out
+func (p *Proxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if r.Context().Err() != nil {
+		// The client went away; nobody reads the answer
+		return
+	}
+	log.Printf("[GoAPI] proxy %s %s to %s: %v", r.Method, r.URL.Path, p.target.Host, err)
+
+	status, detail, errorType := http.StatusBadGateway, "Upstream service unavailable", "bad_gateway"
+	if errors.Is(err, ErrTimeout) {
+		status, detail, errorType = http.StatusGatewayTimeout, "Upstream service timed out", "gateway_timeout"
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(gin.H{"detail": detail, "type": errorType})
+}
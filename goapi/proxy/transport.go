@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// retryTransport applies the per-attempt timeout and retries idempotent requests
+type retryTransport struct {
+	config Config
+}
+
+// RoundTrip sends the request, retrying connection errors and the configured statuses
+// Requests with a body are sent once, since it is streamed and cannot be replayed
+func (t *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	attempts := 1
+	if request.Body == nil && idempotent(request.Method) {
+		attempts += max(0, t.config.Retries)
+	}
+
+	backoff := t.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		response, err := t.attempt(request)
+		if attempt == attempts || !t.retryable(response, err) || request.Context().Err() != nil {
+			return response, err
+		}
+		if response != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+			_ = response.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		}
+		backoff *= 2
+	}
+}
+
+// attempt sends the request once; the timeout stops applying when the response headers arrive,
+// so long downloads and event streams are not cut
+func (t *retryTransport) attempt(request *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(request.Context())
+	timer := time.AfterFunc(t.config.Timeout, func() { cancel(ErrTimeout) })
+
+	response, err := t.config.Transport.RoundTrip(request.WithContext(ctx))
+	if !timer.Stop() || err != nil {
+		cancel(nil)
+		if response != nil {
+			_ = response.Body.Close()
+		}
+		if context.Cause(ctx) == ErrTimeout {
+			return nil, ErrTimeout
+		}
+		return nil, err
+	}
+	if response.StatusCode == http.StatusSwitchingProtocols {
+		// Upgraded connections (websockets) need the writable body; the context ends with the request
+		return response, nil
+	}
+	response.Body = &cancelOnClose{ReadCloser: response.Body, cancel: func() { cancel(nil) }}
+	return response, nil
+}
+
+// retryable reports whether an attempt failed in a way worth retrying
+func (t *retryTransport) retryable(response *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return slices.Contains(t.config.RetryStatuses, response.StatusCode)
+}
+
+// idempotent reports whether a method can be sent again safely
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// cancelOnClose releases the attempt context when the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel func()
+}
+
+// Close closes the body and releases the context
+func (body *cancelOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
@@ -0,0 +1,89 @@
+package quota
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+)
+
+// Plugin wires a Limiter into a GoAPI instance
+// It registers the limiter as a singleton dependency, enforces the quotas on every route and
+// documents the 429 response and the quota headers on every operation
+type Plugin struct {
+	Limiter *Limiter
+}
+
+// NewPlugin creates a plugin enforcing the quotas of a limiter
+func NewPlugin(limiter *Limiter) *Plugin {
+	return &Plugin{Limiter: limiter}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "quota"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.RegisterSingletonDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Limiter, nil
+	}, (*Limiter)(nil))
+	api.AddMiddleware(p.Limiter.Middleware())
+	api.OnOpenAPIDocument(p.document)
+	return nil
+}
+
+// document declares the ExceededError body and adds the 429 response to the operations
+func (p *Plugin) document(document *openapi.Document) {
+	if document.Definitions == nil {
+		document.Definitions = make(map[string]*openapi.Schema)
+	}
+	document.Definitions["QuotaExceededError"] = &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"detail":     {Type: "string"},
+			"type":       {Type: "string", Enum: []interface{}{"quota_exceeded", "rate_limit_error"}},
+			"plan":       {Type: "string", Description: "Plan of the API key"},
+			"limit":      {Type: "integer", Format: "int64", Description: "Requests per day, or per second for rate_limit_error"},
+			"reset_at":   {Type: "string", Format: "date-time", Description: "When requests are accepted again"},
+			"request_id": {Type: "string"},
+		},
+		Required: []string{"detail", "type", "plan", "limit", "reset_at"},
+	}
+
+	headers := map[string]*openapi.Header{
+		"X-Quota-Limit":     {Type: "integer", Description: "Requests per day of the plan"},
+		"X-Quota-Remaining": {Type: "integer", Description: "Requests left today"},
+		"X-Quota-Reset":     {Type: "integer", Description: "Seconds until the quota resets (midnight UTC)"},
+	}
+	status := strconv.Itoa(http.StatusTooManyRequests)
+	for _, item := range document.Paths {
+		for _, operation := range item.Operations() {
+			if operation.Responses == nil {
+				operation.Responses = make(openapi.Responses)
+			}
+			if _, declared := operation.Responses[status]; !declared {
+				retryAfter := &openapi.Header{Type: "integer", Description: "Seconds to wait before retrying"}
+				operation.Responses[status] = &openapi.Response{
+					Description: "Quota or rate limit of the API key exceeded",
+					Schema:      &openapi.Schema{Ref: "#/definitions/QuotaExceededError"},
+					Headers:     map[string]*openapi.Header{"Retry-After": retryAfter},
+				}
+			}
+			for code, response := range operation.Responses {
+				if len(code) == 3 && code[0] == '2' {
+					if response.Headers == nil {
+						response.Headers = make(map[string]*openapi.Header)
+					}
+					for name, header := range headers {
+						response.Headers[name] = header
+					}
+				}
+			}
+		}
+	}
+}
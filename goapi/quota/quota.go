@@ -0,0 +1,256 @@
+// Package quota limits the requests of API keys by plan
+// Every key belongs to a Plan: a number of requests per day, counted in a pluggable Store shared
+// by the instances, and a burst limit on the requests of one second, kept by each instance.
+// Responses carry X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset; requests over the quota get
+// 429 with an ExceededError body
+//
+//	plans := quota.StaticPlans(map[string]string{"key-ana": "pro"},
+//		quota.Plan{Name: "free", RequestsPerDay: 1000, Burst: 5},
+//		quota.Plan{Name: "pro", RequestsPerDay: 100000, Burst: 50},
+//	)
+//	api.UsePlugin(quota.NewPlugin(quota.NewLimiter(quota.Config{Plans: plans})))
+package quota
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// StatusKey is the context key holding the Status of the request
+const StatusKey = "goapi.quota"
+
+// ErrUnknownKey is returned by Limiter.Status for keys without a plan
+var ErrUnknownKey = errors.New("quota: unknown API key")
+
+// Plan is the allowance of the API keys of a tier
+type Plan struct {
+	Name           string `json:"name"`
+	RequestsPerDay int64  `json:"requests_per_day"` // Quota of a UTC day (0: unlimited)
+	Burst          int    `json:"burst"`            // Requests allowed within one second (0: unlimited)
+}
+
+// Plans returns the plan of an API key; nil for unknown keys
+type Plans func(ctx context.Context, apiKey string) (*Plan, error)
+
+// StaticPlans maps API keys to the names of plans; keys that are not listed are unknown
+// A nil key map gives the first plan to every key, for keys checked by the authentication
+func StaticPlans(keys map[string]string, plans ...Plan) Plans {
+	byName := make(map[string]*Plan, len(plans))
+	for i := range plans {
+		byName[plans[i].Name] = &plans[i]
+	}
+	return func(_ context.Context, apiKey string) (*Plan, error) {
+		if name, found := keys[apiKey]; found {
+			if plan, found := byName[name]; found {
+				return plan, nil
+			}
+			return nil, fmt.Errorf("quota: unknown plan %q", name)
+		}
+		if keys != nil || len(plans) == 0 {
+			return nil, nil
+		}
+		return &plans[0], nil
+	}
+}
+
+// Config configures a Limiter
+type Config struct {
+	Plans Plans
+	Store Store // Default: in memory
+	// Key reads the API key of a request (default: the X-API-Key header)
+	Key func(c *gin.Context) string
+	// Anonymous is the plan of requests without a key, counted per client IP; nil lets them
+	// through, for routes the authentication rejects or that are public
+	Anonymous *Plan
+	// OnError receives the store failures; requests are then let through (default: log.Printf)
+	OnError func(error)
+}
+
+// Status is the quota of the key of a request
+type Status struct {
+	Plan      string    `json:"plan"`
+	Limit     int64     `json:"limit"` // 0: unlimited
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// ExceededError is the body of the 429 responses
+type ExceededError struct {
+	Detail    string    `json:"detail"`
+	Type      string    `json:"type"` // "quota_exceeded", or "rate_limit_error" for the burst limit
+	Plan      string    `json:"plan"`
+	Limit     int64     `json:"limit"`
+	ResetAt   time.Time `json:"reset_at"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// Limiter enforces the plans of the API keys
+type Limiter struct {
+	config Config
+
+	mutex    sync.Mutex
+	limiters map[string]*middleware.RateLimiter // Burst limiters by plan
+}
+
+// NewLimiter creates a limiter
+func NewLimiter(config Config) *Limiter {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.Key == nil {
+		config.Key = func(c *gin.Context) string {
+			return c.GetHeader("X-API-Key")
+		}
+	}
+	if config.OnError == nil {
+		config.OnError = func(err error) {
+			log.Printf("quota not checked: %v", err)
+		}
+	}
+	return &Limiter{config: config, limiters: make(map[string]*middleware.RateLimiter)}
+}
+
+// Middleware returns the handler enforcing the quotas
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := l.config.Key(c)
+		var plan *Plan
+		client := "key:" + apiKey
+		if apiKey == "" {
+			if plan = l.config.Anonymous; plan == nil {
+				c.Next()
+				return
+			}
+			client = "ip:" + c.ClientIP()
+		} else {
+			var err error
+			if plan, err = l.config.Plans(c.Request.Context(), apiKey); err != nil {
+				l.config.OnError(err)
+				c.Next()
+				return
+			}
+			if plan == nil {
+				responses.Unauthorized(c, "Invalid API key")
+				c.Abort()
+				return
+			}
+		}
+
+		if plan.Burst > 0 {
+			if result := l.burstLimiter(plan).Allow(client); !result.Allowed {
+				c.Header("Retry-After", strconv.Itoa(max(1, int(result.RetryAfter.Round(time.Second)/time.Second))))
+				l.reject(c, plan, "rate_limit_error", int64(plan.Burst), time.Now().Add(result.RetryAfter))
+				return
+			}
+		}
+		status, err := l.consume(c.Request.Context(), plan, client, 1)
+		if err != nil {
+			l.config.OnError(err)
+			c.Next()
+			return
+		}
+		c.Set(StatusKey, status)
+		if status.Limit > 0 {
+			header := c.Writer.Header()
+			header.Set("X-Quota-Limit", strconv.FormatInt(status.Limit, 10))
+			header.Set("X-Quota-Remaining", strconv.FormatInt(status.Remaining, 10))
+			header.Set("X-Quota-Reset", strconv.Itoa(int(time.Until(status.ResetAt).Seconds())))
+			if status.Used > status.Limit {
+				c.Header("Retry-After", strconv.Itoa(int(time.Until(status.ResetAt).Seconds())))
+				l.reject(c, plan, "quota_exceeded", status.Limit, status.ResetAt)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// Status returns the quota of an API key without consuming it
+func (l *Limiter) Status(ctx context.Context, apiKey string) (Status, error) {
+	plan, err := l.config.Plans(ctx, apiKey)
+	if err != nil {
+		return Status{}, err
+	}
+	if plan == nil {
+		return Status{}, ErrUnknownKey
+	}
+	return l.consume(ctx, plan, "key:"+apiKey, 0)
+}
+
+// Get returns the Status of the request, set by the middleware
+func Get(c *gin.Context) (Status, bool) {
+	status, ok := c.Value(StatusKey).(Status)
+	return status, ok
+}
+
+// consume counts the requests of a client in the current day
+func (l *Limiter) consume(ctx context.Context, plan *Plan, client string, cost int64) (Status, error) {
+	status := Status{Plan: plan.Name, Limit: plan.RequestsPerDay}
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	status.ResetAt = day.Add(24 * time.Hour)
+	if plan.RequestsPerDay <= 0 {
+		return status, nil
+	}
+	used, err := l.config.Store.Consume(ctx, counterKey(client, day), cost, status.ResetAt)
+	if err != nil {
+		return Status{}, err
+	}
+	status.Used = used
+	status.Remaining = max(0, status.Limit-used)
+	return status, nil
+}
+
+// burstLimiter returns the burst limiter of a plan, created on first use
+func (l *Limiter) burstLimiter(plan *Plan) *middleware.RateLimiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	name := plan.Name + "/" + strconv.Itoa(plan.Burst)
+	limiter, found := l.limiters[name]
+	if !found {
+		limiter = middleware.NewRateLimiter(middleware.RateLimitConfig{
+			RequestsPerSecond: float64(plan.Burst),
+			BurstSize:         plan.Burst,
+		})
+		l.limiters[name] = limiter
+	}
+	return limiter
+}
+
+// reject answers 429 with an ExceededError
+func (l *Limiter) reject(c *gin.Context, plan *Plan, errorType string, limit int64, resetAt time.Time) {
+	var detail interface{} = "Daily quota exceeded"
+	if errorType == "rate_limit_error" {
+		detail = i18n.M(i18n.KeyRateLimitExceeded)
+	}
+	responses.JSON(c, http.StatusTooManyRequests, ExceededError{
+		Detail:    fmt.Sprint(i18n.Localize(c, detail)),
+		Type:      errorType,
+		Plan:      plan.Name,
+		Limit:     limit,
+		ResetAt:   resetAt.UTC(),
+		RequestID: c.GetString(responses.RequestIDKey),
+	})
+	c.Abort()
+}
+
+// counterKey returns the store key of a client and day; API keys are hashed so the store does
+// not hold usable keys
+func counterKey(client string, day time.Time) string {
+	sum := sha256.Sum256([]byte(client))
+	return hex.EncodeToString(sum[:16]) + ":" + day.Format("2006-01-02")
+}
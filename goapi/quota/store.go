@@ -0,0 +1,104 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps the consumption of the API keys
+type Store interface {
+	// Consume adds cost to a counter and returns its new value; a new counter starts at 0 and is
+	// forgotten at expiresAt. It must be atomic, so instances sharing the store never lose updates.
+	// A cost of 0 reads the counter
+	Consume(ctx context.Context, key string, cost int64, expiresAt time.Time) (int64, error)
+}
+
+// MemoryStore keeps the counters in memory, for tests and single instance deployments
+type MemoryStore struct {
+	counters map[string]counter
+	mutex    sync.Mutex
+	swept    time.Time
+}
+
+// counter is the consumption of a key in a period
+type counter struct {
+	used      int64
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]counter)}
+}
+
+// Consume implements Store
+func (s *MemoryStore) Consume(_ context.Context, key string, cost int64, expiresAt time.Time) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	s.sweep(now)
+
+	current, found := s.counters[key]
+	if !found || !now.Before(current.expiresAt) {
+		current = counter{expiresAt: expiresAt}
+	}
+	current.used += cost
+	s.counters[key] = current
+	return current.used, nil
+}
+
+// sweep drops the expired counters, at most once a minute; the caller holds the mutex
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for key, current := range s.counters {
+		if !now.Before(current.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+// RedisClient is the part of a Redis client used by RedisStore
+// GoAPI does not depend on a Redis driver; wrap the one of the application, for go-redis:
+//
+//	type redisClient struct{ *redis.Client }
+//
+//	func (r redisClient) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+//		return r.Client.IncrBy(ctx, key, value).Result()
+//	}
+//	func (r redisClient) ExpireAt(ctx context.Context, key string, at time.Time) error {
+//		return r.Client.ExpireAt(ctx, key, at).Err()
+//	}
+type RedisClient interface {
+	IncrBy(ctx context.Context, key string, value int64) (int64, error)
+	ExpireAt(ctx context.Context, key string, at time.Time) error
+}
+
+// RedisStore keeps the counters in Redis, shared by every instance and expired by Redis itself
+type RedisStore struct {
+	Client RedisClient
+	Prefix string // Key prefix (default "quota:")
+}
+
+// NewRedisStore creates a store using a Redis client
+func NewRedisStore(client RedisClient) *RedisStore {
+	return &RedisStore{Client: client, Prefix: "quota:"}
+}
+
+// Consume implements Store
+func (s *RedisStore) Consume(ctx context.Context, key string, cost int64, expiresAt time.Time) (int64, error) {
+	used, err := s.Client.IncrBy(ctx, s.Prefix+key, cost)
+	if err != nil {
+		return 0, err
+	}
+	// El primer incremento crea el contador; se fija su caducidad una sola vez
+	if used == cost {
+		if err := s.Client.ExpireAt(ctx, s.Prefix+key, expiresAt); err != nil {
+			return 0, err
+		}
+	}
+	return used, nil
+}
@@ -0,0 +1,130 @@
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// ViewerPath is the default path of the recordings viewer
+const ViewerPath = "/debug/recordings"
+
+// Plugin wires a Recorder into a GoAPI instance
+// It records every request and, in debug mode, serves the recordings under Path like
+// /debug/routes. Recordings hold request data, so the viewer is not served in release mode;
+// mount Viewer and Recording behind authentication to inspect them there
+type Plugin struct {
+	Recorder *Recorder
+	Path     string // Path of the viewer (default ViewerPath); its requests are not recorded
+}
+
+// NewPlugin creates a plugin recording with a recorder
+func NewPlugin(recorder *Recorder) *Plugin {
+	return &Plugin{Recorder: recorder, Path: ViewerPath}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "recorder"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	if p.Path == "" {
+		p.Path = ViewerPath
+	}
+	skip := p.Recorder.config.Skip
+	p.Recorder.config.Skip = func(c *gin.Context) bool {
+		if strings.HasPrefix(c.Request.URL.Path, p.Path) {
+			return true
+		}
+		return skip != nil && skip(c)
+	}
+	api.AddMiddleware(p.Recorder.Middleware())
+
+	if !gin.IsDebugging() {
+		return nil
+	}
+	api.GET(p.Path, p.Recorder.Viewer(),
+		goapi.WithSummary("List recordings"),
+		goapi.WithDescription("Recorded requests and responses, newest first. ?format=text prints a table"),
+		goapi.WithTags("debug"),
+		goapi.WithQueryParameter("limit", "integer", "Recordings returned (default 50)", false),
+		goapi.WithQueryParameter("format", "string", "json (default) or text", false),
+	)
+	api.GET(p.Path+"/:id", p.Recorder.Recording(),
+		goapi.WithSummary("Get a recording"),
+		goapi.WithDescription("A recorded request and response. ?format=curl prints a curl command reproducing the request"),
+		goapi.WithTags("debug"),
+		goapi.WithPathParameter("id", "string", "Recording ID"),
+		goapi.WithQueryParameter("format", "string", "json (default) or curl", false),
+		goapi.WithResponse(http.StatusNotFound, "Recording not found"),
+	)
+	return nil
+}
+
+// Viewer lists the recordings: JSON by default, a table with ?format=text
+func (r *Recorder) Viewer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 50
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				responses.BadRequest(c, "Invalid 'limit' parameter")
+				return
+			}
+			limit = parsed
+		}
+		recordings, err := r.config.Store.List(limit)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if c.Query("format") != "text" {
+			c.JSON(http.StatusOK, recordings)
+			return
+		}
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Status(http.StatusOK)
+		table := tabwriter.NewWriter(c.Writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "TIME\tID\tMETHOD\tURL\tSTATUS\tDURATION")
+		for _, recording := range recordings {
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\t%s\n", recording.Time.Format("15:04:05.000"), recording.ID,
+				recording.Method, recording.URL, recording.Status, recording.Duration)
+		}
+		_ = table.Flush()
+	}
+}
+
+// Recording serves the recording of the :id path parameter: JSON by default, a curl command
+// with ?format=curl
+func (r *Recorder) Recording() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		recording, err := r.config.Store.Get(c.Param("id"))
+		if errors.Is(err, ErrNotFound) {
+			responses.NotFound(c, "Recording not found")
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if c.Query("format") == "curl" {
+			scheme := "http"
+			if c.Request.TLS != nil {
+				scheme = "https"
+			}
+			c.String(http.StatusOK, recording.Curl(scheme+"://"+c.Request.Host)+"\n")
+			return
+		}
+		c.JSON(http.StatusOK, recording)
+	}
+}
@@ -0,0 +1,344 @@
+// Package recorder captures request/response pairs for debugging
+// The Recorder middleware keeps every exchange, or those selected by Config.Keep, in a Store: a
+// ring buffer in memory or a JSON Lines file. Credentials are redacted before anything is stored.
+// The plugin serves the recordings under /debug/recordings in debug mode, and Replay runs a
+// recording against a handler to reproduce a bug locally
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+)
+
+// Redacted replaces the values removed from recordings
+const Redacted = "[REDACTED]"
+
+// Recording is a captured request and its response
+type Recording struct {
+	ID        string        `json:"id"`
+	Time      time.Time     `json:"time"`
+	Duration  time.Duration `json:"duration"`
+	Method    string        `json:"method"`
+	URL       string        `json:"url"`             // Path and query, with redacted parameters
+	Route     string        `json:"route,omitempty"` // Route pattern, such as /users/:id
+	RequestID string        `json:"request_id,omitempty"`
+	Request   Message       `json:"request"`
+	Response  Message       `json:"response"`
+	Status    int           `json:"status"`
+}
+
+// Message is the headers and body of a request or a response
+// Bodies that are not valid UTF-8 are stored in base64, with Encoding set to "base64"
+type Message struct {
+	Header    http.Header `json:"header,omitempty"`
+	Body      string      `json:"body,omitempty"`
+	Encoding  string      `json:"encoding,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"` // The body was longer than MaxBodySize
+}
+
+// Config configures a Recorder
+type Config struct {
+	Store         Store                   // Where recordings are kept (default: a ring of 100)
+	MaxBodySize   int                     // Bytes kept of each body (default 64 KiB)
+	RedactHeaders []string                // Headers whose values are redacted (default: credentials and cookies)
+	RedactFields  []string                // JSON members, form fields and query parameters redacted, case-insensitive (default: passwords, tokens and secrets)
+	Skip          func(*gin.Context) bool // Requests not recorded, checked before the handler runs
+	Keep          func(*Recording) bool   // Recordings stored, checked after the response (default: all), such as Status >= 500 only
+}
+
+// DefaultConfig returns the default recorder configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxBodySize:   64 << 10,
+		RedactHeaders: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-API-Key", "X-Auth-Token"},
+		RedactFields: []string{"password", "new_password", "current_password", "token", "access_token",
+			"refresh_token", "id_token", "secret", "client_secret", "api_key", "apikey", "credit_card", "card_number", "cvv"},
+	}
+}
+
+// Recorder is a recording middleware and the store of its recordings
+type Recorder struct {
+	config  Config
+	headers map[string]bool
+	fields  map[string]bool
+}
+
+// New creates a recorder; zero fields of the configuration take their default value
+func New(config ...Config) *Recorder {
+	cfg := DefaultConfig()
+	if len(config) > 0 {
+		custom := config[0]
+		if custom.Store != nil {
+			cfg.Store = custom.Store
+		}
+		if custom.MaxBodySize > 0 {
+			cfg.MaxBodySize = custom.MaxBodySize
+		}
+		if custom.RedactHeaders != nil {
+			cfg.RedactHeaders = custom.RedactHeaders
+		}
+		if custom.RedactFields != nil {
+			cfg.RedactFields = custom.RedactFields
+		}
+		cfg.Skip = custom.Skip
+		cfg.Keep = custom.Keep
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewRing(100)
+	}
+
+	recorder := &Recorder{config: cfg, headers: make(map[string]bool), fields: make(map[string]bool)}
+	for _, header := range cfg.RedactHeaders {
+		recorder.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, field := range cfg.RedactFields {
+		recorder.fields[strings.ToLower(field)] = true
+	}
+	return recorder
+}
+
+// Store returns the store of the recordings
+func (r *Recorder) Store() Store {
+	return r.config.Store
+}
+
+// Middleware records the requests and their responses
+// Bodies are captured as they stream, up to MaxBodySize, so large uploads and downloads are not
+// buffered; the request body the handler reads is unchanged. Errors of the store are ignored
+func (r *Recorder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if r.config.Skip != nil && r.config.Skip(c) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		recording := &Recording{
+			ID:     middleware.NewRequestID(),
+			Time:   start.UTC(),
+			Method: c.Request.Method,
+			URL:    r.redactURL(c.Request.URL),
+			Route:  c.FullPath(),
+		}
+		recording.Request.Header = r.redactHeader(c.Request.Header)
+
+		var requestBody []byte
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			// The start of the body is read ahead and put back in front of the rest
+			requestBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(r.config.MaxBodySize)+1))
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(requestBody), c.Request.Body), c.Request.Body}
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer, limit: r.config.MaxBodySize}
+		c.Writer = writer
+		defer func() {
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+
+		recording.Duration = time.Since(start)
+		recording.RequestID = middleware.GetRequestID(c)
+		recording.Status = writer.Status()
+		recording.Request.Body, recording.Request.Encoding, recording.Request.Truncated =
+			r.redactBody(requestBody, r.config.MaxBodySize, c.Request.Header.Get("Content-Type"))
+		recording.Response.Header = r.redactHeader(writer.Header())
+		recording.Response.Body, recording.Response.Encoding, recording.Response.Truncated =
+			r.redactBody(writer.body.Bytes(), r.config.MaxBodySize, writer.Header().Get("Content-Type"))
+		if writer.truncated {
+			recording.Response.Truncated = true
+		}
+
+		if r.config.Keep != nil && !r.config.Keep(recording) {
+			return
+		}
+		_ = r.config.Store.Save(*recording)
+	}
+}
+
+// readCloser reads the restored body and closes the original one
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// recordingWriter copies the start of the response body while it is written
+type recordingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(data string) (int, error) {
+	w.capture([]byte(data))
+	return w.ResponseWriter.WriteString(data)
+}
+
+func (w *recordingWriter) capture(data []byte) {
+	room := w.limit - w.body.Len()
+	if len(data) > room {
+		data = data[:max(room, 0)]
+		w.truncated = true
+	}
+	w.body.Write(data)
+}
+
+// redactHeader copies a header with the values of the sensitive headers redacted
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	copied := header.Clone()
+	for name, values := range copied {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			for i := range values {
+				values[i] = Redacted
+			}
+		}
+	}
+	return copied
+}
+
+// redactURL returns the path and query of a URL with the sensitive parameters redacted
+func (r *Recorder) redactURL(requestURL *url.URL) string {
+	if requestURL.RawQuery == "" {
+		return requestURL.RequestURI()
+	}
+	query := requestURL.Query()
+	r.redactValues(query)
+	return requestURL.EscapedPath() + "?" + query.Encode()
+}
+
+func (r *Recorder) redactValues(values url.Values) {
+	for name, list := range values {
+		if r.fields[strings.ToLower(name)] {
+			for i := range list {
+				list[i] = Redacted
+			}
+		}
+	}
+}
+
+// redactBody redacts a captured body and encodes it for storage
+// JSON, form and multipart bodies have their sensitive fields redacted; bodies of those types that
+// do not parse, such as truncated ones, are replaced by Redacted rather than stored with possible
+// secrets
+func (r *Recorder) redactBody(body []byte, limit int, contentType string) (string, string, bool) {
+	truncated := len(body) > limit
+	if truncated {
+		body = body[:limit]
+	}
+	if len(body) == 0 {
+		return "", "", truncated
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var document interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return Redacted, "", truncated
+		}
+		redacted, err := json.Marshal(r.redactJSON(document))
+		if err != nil {
+			return Redacted, "", truncated
+		}
+		return string(redacted), "", truncated
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil || truncated {
+			return Redacted, "", truncated
+		}
+		r.redactValues(values)
+		return values.Encode(), "", truncated
+	case mediaType == "multipart/form-data":
+		redacted, err := r.redactMultipart(body, params["boundary"])
+		if err != nil || truncated {
+			return Redacted, "", truncated
+		}
+		body = redacted
+	}
+
+	if !utf8.Valid(body) {
+		return base64.StdEncoding.EncodeToString(body), "base64", truncated
+	}
+	return string(body), "", truncated
+}
+
+// redactMultipart rewrites a multipart body with the values of its sensitive fields redacted
+func (r *Recorder) redactMultipart(body []byte, boundary string) ([]byte, error) {
+	if boundary == "" {
+		return nil, http.ErrMissingBoundary
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var output bytes.Buffer
+	writer := multipart.NewWriter(&output)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		target, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return nil, err
+		}
+		if r.fields[strings.ToLower(part.FormName())] {
+			_, err = io.WriteString(target, Redacted)
+		} else {
+			_, err = io.Copy(target, part)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// redactJSON replaces the values of the sensitive members of a decoded JSON document
+func (r *Recorder) redactJSON(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for name, member := range typed {
+			if r.fields[strings.ToLower(name)] {
+				typed[name] = Redacted
+				continue
+			}
+			typed[name] = r.redactJSON(member)
+		}
+	case []interface{}:
+		for i, element := range typed {
+			typed[i] = r.redactJSON(element)
+		}
+	}
+	return value
+}
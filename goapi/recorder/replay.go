@@ -0,0 +1,101 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+)
+
+// Bytes returns the decoded body of a message
+func (m Message) Bytes() ([]byte, error) {
+	if m.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(m.Body)
+	}
+	return []byte(m.Body), nil
+}
+
+// hopHeaders are not replayed: the transport or the server sets them
+var hopHeaders = map[string]bool{"Connection": true, "Content-Length": true, "Keep-Alive": true, "Transfer-Encoding": true, "Upgrade": true}
+
+// NewRequest rebuilds the request of a recording against baseURL ("" for a path-only request,
+// as served by Replay)
+// Redacted values are sent as Redacted: set real credentials on the request before sending it,
+// for example with req.Header.Set("Authorization", ...). Truncated bodies are sent truncated
+func (r Recording) NewRequest(ctx context.Context, baseURL string) (*http.Request, error) {
+	body, err := r.Request.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("recorder: decoding body of %s: %w", r.ID, err)
+	}
+	request, err := http.NewRequestWithContext(ctx, r.Method, strings.TrimSuffix(baseURL, "/")+r.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("recorder: rebuilding %s: %w", r.ID, err)
+	}
+	for name, values := range r.Request.Header {
+		if hopHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		if strings.EqualFold(name, "Host") {
+			request.Host = values[0]
+			continue
+		}
+		request.Header[name] = append([]string(nil), values...)
+	}
+	return request, nil
+}
+
+// Replay serves the request of a recording with a handler, such as a GoAPI instance, and returns
+// the response, to reproduce a bug locally or in a regression test
+//
+//	recordings, _ := recorder.Load("recordings.jsonl")
+//	response, _ := recorder.Replay(api, recordings[0], func(req *http.Request) {
+//		req.Header.Set("Authorization", "Bearer "+localToken)
+//	})
+func Replay(handler http.Handler, recording Recording, prepare ...func(*http.Request)) (*httptest.ResponseRecorder, error) {
+	request, err := recording.NewRequest(context.Background(), "")
+	if err != nil {
+		return nil, err
+	}
+	for _, fn := range prepare {
+		fn(request)
+	}
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	return response, nil
+}
+
+// Curl returns a curl command reproducing the request against baseURL
+func (r Recording) Curl(baseURL string) string {
+	var command strings.Builder
+	command.WriteString("curl -X " + r.Method)
+	names := make([]string, 0, len(r.Request.Header))
+	for name := range r.Request.Header {
+		if !hopHeaders[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range r.Request.Header[name] {
+			command.WriteString(" -H " + shellQuote(name+": "+value))
+		}
+	}
+	if r.Request.Body != "" {
+		if r.Request.Encoding == "base64" {
+			command.WriteString(" --data-binary @body.bin")
+		} else {
+			command.WriteString(" --data-raw " + shellQuote(r.Request.Body))
+		}
+	}
+	command.WriteString(" " + shellQuote(strings.TrimSuffix(baseURL, "/")+r.URL))
+	return command.String()
+}
+
+// shellQuote quotes a value for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,176 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrNotFound is returned by Store.Get for unknown recordings
+var ErrNotFound = errors.New("recorder: recording not found")
+
+// Store keeps recordings
+type Store interface {
+	Save(recording Recording) error
+	List(limit int) ([]Recording, error) // Newest first; limit <= 0 returns all
+	Get(id string) (Recording, error)
+}
+
+// Ring is a Store keeping the last recordings in memory
+type Ring struct {
+	mutex      sync.Mutex
+	recordings []Recording
+	next       int
+	full       bool
+}
+
+// NewRing creates a ring buffer of size recordings (at least 1)
+func NewRing(size int) *Ring {
+	return &Ring{recordings: make([]Recording, max(size, 1))}
+}
+
+// Save implements Store, overwriting the oldest recording when the ring is full
+func (r *Ring) Save(recording Recording) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.recordings[r.next] = recording
+	r.next = (r.next + 1) % len(r.recordings)
+	if r.next == 0 {
+		r.full = true
+	}
+	return nil
+}
+
+// List implements Store
+func (r *Ring) List(limit int) ([]Recording, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	count := r.next
+	if r.full {
+		count = len(r.recordings)
+	}
+	if limit > 0 {
+		count = min(count, limit)
+	}
+	list := make([]Recording, count)
+	for i := range list {
+		list[i] = r.recordings[(r.next-1-i+len(r.recordings))%len(r.recordings)]
+	}
+	return list, nil
+}
+
+// Get implements Store
+func (r *Ring) Get(id string) (Recording, error) {
+	list, _ := r.List(0)
+	for _, recording := range list {
+		if recording.ID == id {
+			return recording, nil
+		}
+	}
+	return Recording{}, ErrNotFound
+}
+
+// Clear removes every recording
+func (r *Ring) Clear() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	clear(r.recordings)
+	r.next, r.full = 0, false
+}
+
+// File is a Store appending recordings to a JSON Lines file
+// The file grows without bound; it suits debugging sessions and files shared to reproduce a bug,
+// which Load reads back
+type File struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFile creates a store appending to the file at path, which is created when missing
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Save implements Store
+func (f *File) Save(recording Recording) error {
+	line, err := json.Marshal(recording)
+	if err != nil {
+		return fmt.Errorf("recorder: encoding recording: %w", err)
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("recorder: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("recorder: %w", err)
+	}
+	return file.Close()
+}
+
+// List implements Store
+func (f *File) List(limit int) ([]Recording, error) {
+	f.mutex.Lock()
+	recordings, err := Load(f.path)
+	f.mutex.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Newest first
+	for i, j := 0, len(recordings)-1; i < j; i, j = i+1, j-1 {
+		recordings[i], recordings[j] = recordings[j], recordings[i]
+	}
+	if limit > 0 && len(recordings) > limit {
+		recordings = recordings[:limit]
+	}
+	return recordings, nil
+}
+
+// Get implements Store
+func (f *File) Get(id string) (Recording, error) {
+	recordings, err := f.List(0)
+	if err != nil {
+		return Recording{}, err
+	}
+	for _, recording := range recordings {
+		if recording.ID == id {
+			return recording, nil
+		}
+	}
+	return Recording{}, ErrNotFound
+}
+
+// Load reads the recordings of a JSON Lines file written by File, oldest first
+func Load(path string) ([]Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var recordings []Recording
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var recording Recording
+		if err := json.Unmarshal(scanner.Bytes(), &recording); err != nil {
+			return nil, fmt.Errorf("recorder: %s:%d: %w", path, line, err)
+		}
+		recordings = append(recordings, recording)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("recorder: %w", err)
+	}
+	return recordings, nil
+}
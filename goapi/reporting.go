@@ -0,0 +1,44 @@
+package goapi
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+)
+
+// ErrorHook receives the panics and the 5xx responses of requests; see middleware.ErrorReporter
+type ErrorHook = middleware.ErrorReporter
+
+// OnError registers a hook receiving the panics recovered by the API and the 5xx responses,
+// with the context of the request, to report them to an error tracker
+//
+//	api.OnError(func(c *gin.Context, err error, stack []byte) {
+//		var panicked *middleware.PanicError
+//		log.Printf("%s %s: %v (panic: %t)", c.Request.Method, c.FullPath(), err, errors.As(err, &panicked))
+//	})
+//
+// Hooks run in registration order, before the response is sent
+func (a *GoAPI) OnError(hook ErrorHook) {
+	a.errorMutex.Lock()
+	defer a.errorMutex.Unlock()
+	a.errorHooks = append(a.errorHooks, hook)
+}
+
+// reportError calls the error hooks; it is the reporter of the default Recovery and ErrorHandler
+func (a *GoAPI) reportError(c *gin.Context, err error, stack []byte) {
+	a.errorMutex.RLock()
+	hooks := a.errorHooks
+	a.errorMutex.RUnlock()
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					log.Printf("[GoAPI] error hook panicked: %v", recovered)
+				}
+			}()
+			hook(c, err, stack)
+		}()
+	}
+}
@@ -0,0 +1,459 @@
+package goapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/expand"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/models"
+	"github.com/esteban-ll-aguilar/goapi/goapi/patch"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// Operations generated by Resource
+const (
+	ResourceList   = "list"
+	ResourceGet    = "get"
+	ResourceCreate = "create"
+	ResourceUpdate = "update"
+	ResourcePatch  = "patch"
+	ResourceDelete = "delete"
+)
+
+// ResourceConfig configures the routes generated by Resource
+type ResourceConfig[T any, ID comparable] struct {
+	Name       string                   // Singular name in summaries and operation IDs (default: the type name)
+	Tags       []string                 // OpenAPI tags (default: the last segment of the path)
+	Filters    []string                 // Fields the list route filters by, as query parameters
+	Operations []string                 // Operations generated (default: all six)
+	IDField    string                   // Go name of the ID field, not validated in request bodies (default "ID")
+	ParseID    func(string) (ID, error) // Parses the :id path parameter (default: string and integer IDs)
+	Options    []router.RouteOption     // Added to every route: middlewares, rate limits, requirements...
+	Expand     *expand.Registry         // Expansions of T accepted by list and get with ?expand= (default: none)
+}
+
+// Resource registers the CRUD routes of a store under a path:
+//
+//	GET    /users      list, paginated with page, page_size, sort and the configured filters
+//	GET    /users/:id  get
+//	POST   /users      create, from a validated T
+//	PUT    /users/:id  update, from a validated T
+//	PATCH  /users/:id  partial update, from a JSON Merge Patch or a JSON Patch (see patch.Apply)
+//	DELETE /users/:id  delete
+//
+// Bodies are bound to T and checked with its validate tags, except the ID, which comes from the path
+// or the store; patched models are validated whole. Computed fields (goapi:"computed") are dropped
+// from bodies and write-only fields (goapi:"writeonly") from responses. Responses use the
+// responses helpers with the models serialized by models.Serialize, and every route is documented.
+// With an Expand registry, list and get embed related resources requested with ?expand=
+//
+//	goapi.Resource[User, int](api, "/api/v1/users", users, goapi.ResourceConfig[User, int]{
+//		Filters: []string{"is_active"},
+//	})
+func Resource[T any, ID comparable](api *GoAPI, path string, store models.Store[T, ID], config ...ResourceConfig[T, ID]) {
+	var cfg ResourceConfig[T, ID]
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	path = strings.TrimSuffix(path, "/")
+	collection := path[strings.LastIndex(path, "/")+1:]
+	if cfg.Name == "" {
+		cfg.Name = reflect.TypeOf((*T)(nil)).Elem().Name()
+	}
+	if len(cfg.Tags) == 0 {
+		cfg.Tags = []string{collection}
+	}
+	if len(cfg.Operations) == 0 {
+		cfg.Operations = []string{ResourceList, ResourceGet, ResourceCreate, ResourceUpdate, ResourcePatch, ResourceDelete}
+	}
+	if cfg.IDField == "" {
+		cfg.IDField = "ID"
+	}
+	if cfg.ParseID == nil {
+		cfg.ParseID = parseResourceID[ID]
+	}
+
+	resource := &resource[T, ID]{store: store, config: cfg}
+	itemPath := path + "/:id"
+	idType := schemaType(reflect.TypeOf((*ID)(nil)).Elem())
+	noun := strings.ToLower(cfg.Name[:min(1, len(cfg.Name))]) + cfg.Name[min(1, len(cfg.Name)):]
+	title := strings.ToUpper(collection[:min(1, len(collection))]) + collection[min(1, len(collection)):]
+	var example T
+	// Models with Serialize are documented by their representation
+	var response interface{} = example
+	if serialized, err := models.Serialize(context.Background(), example); err == nil && serialized != nil {
+		response = serialized
+	}
+	page := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(response)), 1, 1)
+	page.Index(0).Set(reflect.ValueOf(response))
+
+	for _, operation := range cfg.Operations {
+		opts := []router.RouteOption{router.WithTags(cfg.Tags...)}
+		switch operation {
+		case ResourceList:
+			opts = append(opts,
+				router.WithSummary("List "+collection),
+				router.WithDescription(fmt.Sprintf("Returns a page of %s", collection)),
+				router.WithOperationID("list"+title),
+				router.WithQueryParameter("page", "integer", "Page number (default 1)", false),
+				router.WithQueryParameter("page_size", "integer", "Page size, between 1 and 100 (default 10)", false),
+				router.WithQueryParameter("sort", "string", "Field to sort by; prefix it with - to sort descending", false),
+			)
+			for _, filter := range cfg.Filters {
+				filterType := "string"
+				if fieldType, ok := models.FieldType[T](filter); ok {
+					filterType = schemaType(fieldType)
+				}
+				opts = append(opts, router.WithQueryParameter(filter, filterType, "Filter by "+filter, false))
+			}
+			if cfg.Expand != nil {
+				opts = append(opts, cfg.Expand.Parameter(example))
+			}
+			opts = append(opts,
+				router.WithResponseModel(http.StatusOK, responses.Response{
+					Data:    responses.PaginatedResponse{Items: page.Interface(), Page: 1, PageSize: 10},
+					Success: true,
+				}, "A page of "+collection),
+				router.WithResponseModel(http.StatusBadRequest, responses.ErrorResponse{}, "Invalid pagination, sort or filter"),
+			)
+			api.GET(path, resource.list, append(opts, cfg.Options...)...)
+		case ResourceGet:
+			opts = append(opts,
+				router.WithSummary("Get "+noun),
+				router.WithDescription(fmt.Sprintf("Returns a %s by its ID", noun)),
+				router.WithOperationID("get"+cfg.Name),
+				router.WithPathParameter("id", idType, cfg.Name+" ID"),
+				router.WithResponseModel(http.StatusOK, responses.Response{Data: response, Success: true}, "The "+noun),
+				router.WithResponseModel(http.StatusNotFound, responses.ErrorResponse{}, cfg.Name+" not found"),
+			)
+			if cfg.Expand != nil {
+				opts = append(opts, cfg.Expand.Parameter(example),
+					router.WithResponseModel(http.StatusBadRequest, responses.ErrorResponse{}, "Invalid expansion"))
+			}
+			api.GET(itemPath, resource.get, append(opts, cfg.Options...)...)
+		case ResourceCreate:
+			opts = append(opts,
+				router.WithSummary("Create "+noun),
+				router.WithDescription(fmt.Sprintf("Creates a %s", noun)),
+				router.WithOperationID("create"+cfg.Name),
+				router.WithRequestBody(example, cfg.Name+" to create"),
+				// The handler validates the body without the ID, which the store assigns
+				router.WithRequestValidation(false),
+				router.WithResponseModel(http.StatusCreated, responses.Response{Data: response, Success: true}, cfg.Name+" created"),
+				router.WithResponseModel(http.StatusBadRequest, responses.ErrorResponse{}, "Invalid body"),
+				router.WithResponseModel(http.StatusConflict, responses.ErrorResponse{}, cfg.Name+" already exists"),
+				router.WithResponseModel(http.StatusUnprocessableEntity, responses.ValidationErrorResponse{}, "Validation error"),
+			)
+			api.POST(path, resource.create, append(opts, cfg.Options...)...)
+		case ResourceUpdate:
+			opts = append(opts,
+				router.WithSummary("Update "+noun),
+				router.WithDescription(fmt.Sprintf("Replaces a %s", noun)),
+				router.WithOperationID("update"+cfg.Name),
+				router.WithPathParameter("id", idType, cfg.Name+" ID"),
+				router.WithRequestBody(example, cfg.Name+" data"),
+				// The handler validates the body without the ID, which comes from the path
+				router.WithRequestValidation(false),
+				router.WithResponseModel(http.StatusOK, responses.Response{Data: response, Success: true}, cfg.Name+" updated"),
+				router.WithResponseModel(http.StatusBadRequest, responses.ErrorResponse{}, "Invalid ID or body"),
+				router.WithResponseModel(http.StatusNotFound, responses.ErrorResponse{}, cfg.Name+" not found"),
+				router.WithResponseModel(http.StatusUnprocessableEntity, responses.ValidationErrorResponse{}, "Validation error"),
+			)
+			api.PUT(itemPath, resource.update, append(opts, cfg.Options...)...)
+		case ResourcePatch:
+			opts = append(opts,
+				router.WithSummary("Patch "+noun),
+				router.WithDescription(fmt.Sprintf("Updates some fields of a %s. The body is a JSON Merge Patch "+
+					"(application/merge-patch+json or application/json) or a JSON Patch (application/json-patch+json)", noun)),
+				router.WithOperationID("patch"+cfg.Name),
+				router.WithPathParameter("id", idType, cfg.Name+" ID"),
+				router.WithRequestBody(example, "Fields to change"),
+				// The body is partial; the patched model is validated by the handler
+				router.WithRequestValidation(false),
+				router.WithResponseModel(http.StatusOK, responses.Response{Data: response, Success: true}, cfg.Name+" updated"),
+				router.WithResponseModel(http.StatusBadRequest, responses.ErrorResponse{}, "Invalid ID or patch"),
+				router.WithResponseModel(http.StatusNotFound, responses.ErrorResponse{}, cfg.Name+" not found"),
+				router.WithResponseModel(http.StatusConflict, responses.ErrorResponse{}, "A test operation failed"),
+				router.WithResponseModel(http.StatusUnsupportedMediaType, responses.ErrorResponse{}, "Not a patch document"),
+				router.WithResponseModel(http.StatusUnprocessableEntity, responses.ValidationErrorResponse{}, "The patched "+noun+" is invalid"),
+			)
+			api.PATCH(itemPath, resource.patch, append(opts, cfg.Options...)...)
+		case ResourceDelete:
+			opts = append(opts,
+				router.WithSummary("Delete "+noun),
+				router.WithDescription(fmt.Sprintf("Deletes a %s by its ID", noun)),
+				router.WithOperationID("delete"+cfg.Name),
+				router.WithPathParameter("id", idType, cfg.Name+" ID"),
+				router.WithResponse(http.StatusNoContent, cfg.Name+" deleted"),
+				router.WithResponseModel(http.StatusNotFound, responses.ErrorResponse{}, cfg.Name+" not found"),
+			)
+			api.DELETE(itemPath, resource.delete, append(opts, cfg.Options...)...)
+		}
+	}
+}
+
+// resource holds the handlers generated by Resource
+type resource[T any, ID comparable] struct {
+	store  models.Store[T, ID]
+	config ResourceConfig[T, ID]
+}
+
+func (r *resource[T, ID]) list(c *gin.Context) {
+	options, err := models.ParseListOptions(c.Request.URL.Query(), r.config.Filters...)
+	if err != nil {
+		responses.BadRequest(c, err.Error())
+		return
+	}
+	// Filters are compared with the type of their field
+	for name, value := range options.Filters {
+		if options.Filters[name], err = filterValue[T](name, value.(string)); err != nil {
+			responses.BadRequest(c, fmt.Sprintf("Invalid '%s' parameter", name))
+			return
+		}
+	}
+	items, total, err := r.store.List(c, options)
+	if errors.Is(err, models.ErrInvalidField) {
+		responses.BadRequest(c, fmt.Sprintf("Invalid 'sort' parameter: %s", options.Sort))
+		return
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if data, ok := r.expand(c, items); ok {
+		responses.Paginated(c, data, total, options.Page, options.PageSize)
+	}
+}
+
+func (r *resource[T, ID]) get(c *gin.Context) {
+	id, ok := r.id(c)
+	if !ok {
+		return
+	}
+	item, err := r.store.Get(c, id)
+	if r.failed(c, err) {
+		return
+	}
+	if data, ok := r.expand(c, item); ok {
+		responses.Success(c, data)
+	}
+}
+
+func (r *resource[T, ID]) create(c *gin.Context) {
+	item, ok := r.bind(c)
+	if !ok {
+		return
+	}
+	created, err := r.store.Create(c, item)
+	if r.failed(c, err) {
+		return
+	}
+	if data, ok := r.serialize(c, created); ok {
+		responses.Created(c, data)
+	}
+}
+
+func (r *resource[T, ID]) update(c *gin.Context) {
+	id, ok := r.id(c)
+	if !ok {
+		return
+	}
+	item, ok := r.bind(c)
+	if !ok {
+		return
+	}
+	updated, err := r.store.Update(c, id, item)
+	if r.failed(c, err) {
+		return
+	}
+	if data, ok := r.serialize(c, updated); ok {
+		responses.Success(c, data)
+	}
+}
+
+func (r *resource[T, ID]) patch(c *gin.Context) {
+	id, ok := r.id(c)
+	if !ok {
+		return
+	}
+	item, err := r.store.Get(c, id)
+	if r.failed(c, err) {
+		return
+	}
+	if err := patch.Apply(c, &item); err != nil {
+		var validationErrors validation.ValidationErrors
+		switch status := patch.Status(err); {
+		case errors.As(err, &validationErrors):
+			responses.ValidationError(c, responses.FromValidationErrors(validationErrors),
+				responses.WithDefaultValidationStatus(http.StatusUnprocessableEntity))
+		case status == http.StatusInternalServerError:
+			_ = c.Error(err)
+		default:
+			c.JSON(status, responses.ErrorResponse{
+				Detail:    err.Error(),
+				Type:      "invalid_patch",
+				RequestID: c.GetString(responses.RequestIDKey),
+			})
+		}
+		return
+	}
+	models.ClearComputed(&item)
+	updated, err := r.store.Update(c, id, item)
+	if r.failed(c, err) {
+		return
+	}
+	if data, ok := r.serialize(c, updated); ok {
+		responses.Success(c, data)
+	}
+}
+
+func (r *resource[T, ID]) delete(c *gin.Context) {
+	id, ok := r.id(c)
+	if !ok {
+		return
+	}
+	if r.failed(c, r.store.Delete(c, id)) {
+		return
+	}
+	responses.NoContent(c)
+}
+
+// id parses the ID of the path, answering 400 when it is invalid
+func (r *resource[T, ID]) id(c *gin.Context) (ID, bool) {
+	id, err := r.config.ParseID(c.Param("id"))
+	if err != nil {
+		responses.BadRequest(c, fmt.Sprintf("Invalid %s ID", r.config.Name))
+		return id, false
+	}
+	return id, true
+}
+
+// bind decodes and validates the body, answering 400 or 422 when it is invalid
+func (r *resource[T, ID]) bind(c *gin.Context) (T, bool) {
+	var item T
+	if !DecodeJSONBody(c, &item, responses.WithDefaultValidationStatus(http.StatusUnprocessableEntity)) {
+		return item, false
+	}
+	models.ClearComputed(&item)
+	if err := validation.FromContext(c).ValidateStructExcept(item, r.config.IDField); err != nil {
+		validationErrors := validation.FormatValidationErrorsLocale(err, i18n.Locale(c))
+		responses.ValidationError(c, responses.FromValidationErrors(validationErrors),
+			responses.WithDefaultValidationStatus(http.StatusUnprocessableEntity))
+		return item, false
+	}
+	return item, true
+}
+
+// serialize returns the representation of models for a response, leaving errors to the error handler
+func (r *resource[T, ID]) serialize(c *gin.Context, value interface{}) (interface{}, bool) {
+	data, err := models.Serialize(c, models.WithoutWriteOnly(value))
+	if err != nil {
+		_ = c.Error(err)
+		return nil, false
+	}
+	return data, true
+}
+
+// expand embeds the expansions of the request in the models of list and get, answering 400 for
+// invalid ones; without a registry or expansions the models are only serialized
+func (r *resource[T, ID]) expand(c *gin.Context, value interface{}) (interface{}, bool) {
+	if r.config.Expand == nil || len(expand.Parse(c)) == 0 {
+		return r.serialize(c, value)
+	}
+	data, err := r.config.Expand.Apply(c, models.WithoutWriteOnly(value))
+	switch {
+	case errors.Is(err, expand.ErrUnknownExpansion), errors.Is(err, expand.ErrTooDeep):
+		responses.BadRequest(c, err.Error())
+		return nil, false
+	case err != nil:
+		_ = c.Error(err)
+		return nil, false
+	}
+	return data, true
+}
+
+// failed answers the store errors; unknown ones are left to the error handler
+func (r *resource[T, ID]) failed(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, models.ErrNotFound):
+		responses.NotFound(c, r.config.Name+" not found")
+	case errors.Is(err, models.ErrConflict):
+		responses.Conflict(c, r.config.Name+" already exists")
+	default:
+		_ = c.Error(err)
+	}
+	return true
+}
+
+// parseResourceID parses string and integer IDs
+func parseResourceID[ID comparable](value string) (ID, error) {
+	var id ID
+	target := reflect.ValueOf(&id).Elem()
+	switch target.Kind() {
+	case reflect.String:
+		if value == "" {
+			return id, errors.New("empty ID")
+		}
+		target.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(value, 10, target.Type().Bits())
+		if err != nil {
+			return id, err
+		}
+		target.SetInt(number)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := strconv.ParseUint(value, 10, target.Type().Bits())
+		if err != nil {
+			return id, err
+		}
+		target.SetUint(number)
+	default:
+		return id, fmt.Errorf("unsupported ID type %T; set ResourceConfig.ParseID", id)
+	}
+	return id, nil
+}
+
+// filterValue converts a query filter to the type of its field
+func filterValue[T any](name, value string) (interface{}, error) {
+	fieldType, ok := models.FieldType[T](name)
+	if !ok {
+		return value, nil
+	}
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	}
+	return value, nil
+}
+
+// schemaType returns the OpenAPI type of a Go type
+func schemaType(goType reflect.Type) string {
+	switch goType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	}
+	return "string"
+}
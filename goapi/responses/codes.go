@@ -0,0 +1,147 @@
+package responses
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+)
+
+// ErrorCodeKey is the context key holding the error code sent by SendErrorCode
+const ErrorCodeKey = "goapi.error_code"
+
+// ErrorCode is an application error code with its HTTP status and default message
+// Codes are stable identifiers clients can branch on ("USER_NOT_FOUND"), unlike messages
+type ErrorCode struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Message     string `json:"message"`
+	Description string `json:"description,omitempty"` // Documentation of when the code is returned
+}
+
+var (
+	errorCodes      = make(map[string]ErrorCode)
+	errorCodesMutex sync.RWMutex
+)
+
+// RegisterErrorCode registers an error code, replacing any previous registration
+func RegisterErrorCode(code string, status int, message string) {
+	RegisterErrorCodes(ErrorCode{Code: code, Status: status, Message: message})
+}
+
+// RegisterErrorCodes registers several error codes at once
+func RegisterErrorCodes(codes ...ErrorCode) {
+	errorCodesMutex.Lock()
+	defer errorCodesMutex.Unlock()
+	for _, code := range codes {
+		errorCodes[code.Code] = code
+	}
+}
+
+// LookupErrorCode returns a registered error code
+func LookupErrorCode(code string) (ErrorCode, bool) {
+	errorCodesMutex.RLock()
+	defer errorCodesMutex.RUnlock()
+	errorCode, registered := errorCodes[code]
+	return errorCode, registered
+}
+
+// RegisteredErrorCodes returns every registered error code sorted by code
+func RegisteredErrorCodes() []ErrorCode {
+	errorCodesMutex.RLock()
+	defer errorCodesMutex.RUnlock()
+
+	codes := make([]ErrorCode, 0, len(errorCodes))
+	for _, code := range errorCodes {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Code < codes[j].Code
+	})
+	return codes
+}
+
+// CodedErrorResponse is the body of responses sent with an error code
+type CodedErrorResponse struct {
+	Detail    interface{} `json:"detail"`
+	Type      string      `json:"type,omitempty"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// SendErrorCode responds with the status of a registered error code
+// The detail defaults to the registered message, or to the i18n catalog message keyed by the code
+// in the locale of the request; unregistered codes are logged and sent as 500
+func SendErrorCode(c *gin.Context, code string, detail ...interface{}) {
+	errorCode, registered := LookupErrorCode(code)
+	if !registered {
+		log.Printf("[GoAPI] error code %s is not registered", code)
+		errorCode = ErrorCode{Code: code, Status: http.StatusInternalServerError, Message: i18n.T(c, i18n.KeyInternalError)}
+	}
+
+	var body interface{} = errorCode.Message
+	if message, translated := i18n.Lookup(i18n.Locale(c), code); registered && translated {
+		body = message
+	}
+	if len(detail) > 0 {
+		body = i18n.Localize(c, detail[0])
+	}
+	c.Set(ErrorCodeKey, code)
+	JSON(c, errorCode.Status, CodedErrorResponse{
+		Detail:    body,
+		Type:      StatusType(errorCode.Status),
+		Code:      code,
+		RequestID: c.GetString(RequestIDKey),
+	})
+}
+
+// CodedError is an error carrying a registered error code
+// Handlers can pass it to c.Error; the ErrorHandler middleware sends it with SendErrorCode
+type CodedError struct {
+	Code   string
+	Detail interface{}
+}
+
+// NewCodedError creates an error for a registered code, with an optional detail
+func NewCodedError(code string, detail ...interface{}) *CodedError {
+	err := &CodedError{Code: code}
+	if len(detail) > 0 {
+		err.Detail = detail[0]
+	}
+	return err
+}
+
+// Error implements error
+func (e *CodedError) Error() string {
+	if e.Detail != nil {
+		return fmt.Sprintf("%s: %v", e.Code, e.Detail)
+	}
+	if errorCode, registered := LookupErrorCode(e.Code); registered {
+		return e.Code + ": " + errorCode.Message
+	}
+	return e.Code
+}
+
+// Send responds with the error
+func (e *CodedError) Send(c *gin.Context) {
+	if e.Detail != nil {
+		SendErrorCode(c, e.Code, e.Detail)
+		return
+	}
+	SendErrorCode(c, e.Code)
+}
+
+// StatusType returns the "type" of error responses for a status ("not_found" for 404)
+func StatusType(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
@@ -0,0 +1,185 @@
+package responses
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/securecookie"
+)
+
+// ErrCookieKeys is returned by the signed and encrypted cookie helpers before SetCookieKeys is called,
+// and by the encrypted ones when the keys have no BlockKey
+var ErrCookieKeys = errors.New("cookie keys are not configured; call responses.SetCookieKeys")
+
+var (
+	cookieCodecsMutex sync.RWMutex
+	cookieSigner      *securecookie.Codec // Signs values without encrypting them
+	cookieEncrypter   *securecookie.Codec // Signs and encrypts values; nil without block keys
+)
+
+// SetCookieKeys configures the keys of the signed and encrypted cookies
+// The first key signs new cookies and the others still validate older ones, so keys can be rotated by
+// prepending a new one. Encrypted cookies require a BlockKey in every key
+func SetCookieKeys(keys ...securecookie.Key) error {
+	signingKeys := make([]securecookie.Key, len(keys))
+	encrypted := len(keys) > 0
+	for i, key := range keys {
+		signingKeys[i] = securecookie.Key{HashKey: key.HashKey}
+		encrypted = encrypted && key.BlockKey != nil
+	}
+	signer, err := securecookie.New(signingKeys...)
+	if err != nil {
+		return err
+	}
+	var encrypter *securecookie.Codec
+	if encrypted {
+		if encrypter, err = securecookie.New(keys...); err != nil {
+			return err
+		}
+	}
+
+	cookieCodecsMutex.Lock()
+	defer cookieCodecsMutex.Unlock()
+	cookieSigner, cookieEncrypter = signer, encrypter
+	return nil
+}
+
+// CookieOption customizes a cookie written by the cookie helpers
+type CookieOption func(*http.Cookie)
+
+// WithCookieMaxAge makes the cookie persistent for a duration (default: a session cookie)
+func WithCookieMaxAge(maxAge time.Duration) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.MaxAge = int(maxAge.Seconds())
+		cookie.Expires = time.Now().Add(maxAge)
+	}
+}
+
+// WithCookiePath restricts the cookie to a path (default "/")
+func WithCookiePath(path string) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.Path = path
+	}
+}
+
+// WithCookieDomain shares the cookie with the subdomains of a domain (default: the request host only)
+func WithCookieDomain(domain string) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.Domain = domain
+	}
+}
+
+// WithCookieSameSite sets the SameSite mode (default Lax); SameSiteNoneMode keeps the cookie Secure
+func WithCookieSameSite(mode http.SameSite) CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.SameSite = mode
+	}
+}
+
+// WithInsecureCookie sends the cookie over plain HTTP, for local development only
+func WithInsecureCookie() CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.Secure = false
+	}
+}
+
+// WithCookieScriptAccess lets JavaScript read the cookie (it is HttpOnly by default)
+func WithCookieScriptAccess() CookieOption {
+	return func(cookie *http.Cookie) {
+		cookie.HttpOnly = false
+	}
+}
+
+// SetCookie writes a cookie with secure defaults: Path "/", HttpOnly, Secure and SameSite=Lax
+func SetCookie(c *gin.Context, name, value string, opts ...CookieOption) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	for _, option := range opts {
+		option(cookie)
+	}
+	if cookie.SameSite == http.SameSiteNoneMode {
+		// Browsers reject SameSite=None cookies that are not Secure
+		cookie.Secure = true
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// DeleteCookie expires a cookie; pass the path and domain options it was set with
+func DeleteCookie(c *gin.Context, name string, opts ...CookieOption) {
+	SetCookie(c, name, "", append(opts[:len(opts):len(opts)], func(cookie *http.Cookie) {
+		cookie.MaxAge = -1
+		cookie.Expires = time.Time{}
+	})...)
+}
+
+// SetSignedCookie writes a cookie whose value is signed with HMAC-SHA256: clients can read it but
+// not change it
+func SetSignedCookie(c *gin.Context, name, value string, opts ...CookieOption) error {
+	return setEncodedCookie(c, currentCookieCodec(false), name, value, opts)
+}
+
+// GetSignedCookie returns the value of a cookie written by SetSignedCookie
+// Cookies signed more than maxAge ago fail with securecookie.ErrExpired (0 accepts any age); missing
+// cookies return http.ErrNoCookie, and tampered ones securecookie.ErrInvalid
+func GetSignedCookie(c *gin.Context, name string, maxAge time.Duration) (string, error) {
+	return getEncodedCookie(c, currentCookieCodec(false), name, maxAge)
+}
+
+// SetEncryptedCookie writes a cookie whose value is encrypted with AES-GCM and signed
+func SetEncryptedCookie(c *gin.Context, name, value string, opts ...CookieOption) error {
+	return setEncodedCookie(c, currentCookieCodec(true), name, value, opts)
+}
+
+// GetEncryptedCookie returns the value of a cookie written by SetEncryptedCookie
+func GetEncryptedCookie(c *gin.Context, name string, maxAge time.Duration) (string, error) {
+	return getEncodedCookie(c, currentCookieCodec(true), name, maxAge)
+}
+
+// currentCookieCodec returns the configured signing or encrypting codec
+func currentCookieCodec(encrypted bool) *securecookie.Codec {
+	cookieCodecsMutex.RLock()
+	defer cookieCodecsMutex.RUnlock()
+	if encrypted {
+		return cookieEncrypter
+	}
+	return cookieSigner
+}
+
+// setEncodedCookie encodes a value with a codec and writes it
+func setEncodedCookie(c *gin.Context, codec *securecookie.Codec, name, value string, opts []CookieOption) error {
+	if codec == nil {
+		return ErrCookieKeys
+	}
+	encoded, err := codec.Encode(name, []byte(value))
+	if err != nil {
+		return err
+	}
+	SetCookie(c, name, encoded, opts...)
+	return nil
+}
+
+// getEncodedCookie reads and decodes a cookie
+func getEncodedCookie(c *gin.Context, codec *securecookie.Codec, name string, maxAge time.Duration) (string, error) {
+	if codec == nil {
+		return "", ErrCookieKeys
+	}
+	encoded, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	value, err := codec.Decode(name, encoded, maxAge)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
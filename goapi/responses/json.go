@@ -0,0 +1,172 @@
+package responses
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPooledBuffer is the capacity above which buffers are dropped instead of returned to the
+// pool, so an occasional large response does not keep its memory alive
+const maxPooledBuffer = 64 << 10
+
+// pooledEncoder is a buffer and the encoder writing into it, reused across responses
+type pooledEncoder struct {
+	buffer  bytes.Buffer
+	encoder jsonEncoder
+}
+
+// jsonEncoder is the encoder of the JSON backend selected at build time
+type jsonEncoder interface {
+	Encode(value interface{}) error
+	SetIndent(prefix, indent string)
+	SetEscapeHTML(on bool)
+}
+
+// JSONConfig selects how JSON responses are written
+type JSONConfig struct {
+	Pretty bool   // Indent every response
+	Indent string // Indentation of pretty responses (default two spaces)
+	// PrettyQuery lets requests choose: ?pretty=true (or a bare ?pretty) indents the response and
+	// ?pretty=false compacts it
+	PrettyQuery bool
+	// DisableHTMLEscaping writes <, > and & as is instead of \u003c, \u003e and \u0026
+	DisableHTMLEscaping bool
+	// StreamThreshold is the size in bytes above which responses holding large arrays are
+	// streamed with StreamJSON (0 = DefaultStreamThreshold, negative = never)
+	StreamThreshold int
+}
+
+// JSONConfigKey is the gin context key holding the JSON configuration of the API
+const JSONConfigKey = "goapi.json_config"
+
+var (
+	defaultJSONConfig JSONConfig
+	jsonConfigMutex   sync.RWMutex
+)
+
+// SetDefaultJSONConfig sets the process-wide JSON configuration
+// It applies to requests that were not configured by JSONConfigMiddleware (GoAPI.New installs it)
+func SetDefaultJSONConfig(config JSONConfig) {
+	jsonConfigMutex.Lock()
+	defer jsonConfigMutex.Unlock()
+	defaultJSONConfig = config
+}
+
+// JSONConfigMiddleware stores a JSON configuration in the context of every request
+func JSONConfigMiddleware(config JSONConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(JSONConfigKey, config)
+		c.Next()
+	}
+}
+
+// JSONConfigFromContext returns the JSON configuration of a request
+func JSONConfigFromContext(c *gin.Context) JSONConfig {
+	if c != nil {
+		if value, exists := c.Get(JSONConfigKey); exists {
+			if config, ok := value.(JSONConfig); ok {
+				return config
+			}
+		}
+	}
+	jsonConfigMutex.RLock()
+	defer jsonConfigMutex.RUnlock()
+	return defaultJSONConfig
+}
+
+// indentation returns the indentation of a response, empty for compact responses
+func (config JSONConfig) indentation(c *gin.Context) string {
+	pretty := config.Pretty
+	if config.PrettyQuery && c.Request != nil {
+		if value, present := c.GetQuery("pretty"); present {
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				pretty = parsed
+			} else if value == "" {
+				pretty = true
+			}
+		}
+	}
+	switch {
+	case !pretty:
+		return ""
+	case config.Indent == "":
+		return "  "
+	default:
+		return config.Indent
+	}
+}
+
+// encoderPool recycles the encoders of JSON responses
+var encoderPool = sync.Pool{
+	New: func() any {
+		pooled := &pooledEncoder{}
+		pooled.encoder = newEncoder(&pooled.buffer)
+		return pooled
+	},
+}
+
+// JSON sends a JSON response encoded into a pooled buffer, the path used by every helper of this
+// package; unlike c.JSON it does not allocate the encoded body on each request
+// The encoder follows the gin build tags: -tags=sonic (with avx, on amd64), jsoniter or go_json
+// switch both gin and this package to that backend. Indentation and HTML escaping follow the
+// JSONConfig of the request. The body length is sent in Content-Length, unless a compression
+// middleware already set a Content-Encoding or the response is streamed (see StreamJSON)
+func JSON(c *gin.Context, statusCode int, value interface{}) {
+	header := c.Writer.Header()
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	if !bodyAllowed(statusCode) {
+		c.Status(statusCode)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	// Las respuestas con arrays grandes cuyo tamaño estimado supera el umbral pasan por el
+	// streaming, que solo empieza a enviar al superarlo
+	config := JSONConfigFromContext(c)
+	if threshold := config.streamThreshold(); threshold >= 0 {
+		var plan streamPlan
+		if template, found := plan.replace(value); found && plan.estimate() > threshold {
+			streamJSON(c, statusCode, &plan, template, config, threshold)
+			return
+		}
+	}
+
+	pooled := encoderPool.Get().(*pooledEncoder)
+	pooled.buffer.Reset()
+	defer func() {
+		if pooled.buffer.Cap() <= maxPooledBuffer {
+			encoderPool.Put(pooled)
+		}
+	}()
+
+	// El encoder se reutiliza: su configuración se fija en cada respuesta
+	pooled.encoder.SetIndent("", config.indentation(c))
+	pooled.encoder.SetEscapeHTML(!config.DisableHTMLEscaping)
+	if err := pooled.encoder.Encode(value); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	// Los encoders terminan con un salto de línea que c.JSON no escribe
+	body := bytes.TrimSuffix(pooled.buffer.Bytes(), []byte("\n"))
+
+	if header.Get("Content-Encoding") == "" {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	c.Status(statusCode)
+	_, _ = c.Writer.Write(body)
+}
+
+// bodyAllowed reports whether a status code allows a response body
+func bodyAllowed(statusCode int) bool {
+	switch {
+	case statusCode >= 100 && statusCode <= 199:
+		return false
+	case statusCode == http.StatusNoContent, statusCode == http.StatusNotModified:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,14 @@
+//go:build go_json
+
+package responses
+
+import (
+	"io"
+
+	json "github.com/goccy/go-json"
+)
+
+// newEncoder creates a go-json encoder
+func newEncoder(w io.Writer) jsonEncoder {
+	return json.NewEncoder(w)
+}
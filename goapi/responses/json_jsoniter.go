@@ -0,0 +1,14 @@
+//go:build jsoniter
+
+package responses
+
+import (
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// newEncoder creates a json-iterator encoder
+func newEncoder(w io.Writer) jsonEncoder {
+	return jsoniter.ConfigCompatibleWithStandardLibrary.NewEncoder(w)
+}
@@ -0,0 +1,14 @@
+//go:build sonic && avx && (linux || windows || darwin) && amd64
+
+package responses
+
+import (
+	"io"
+
+	"github.com/bytedance/sonic"
+)
+
+// newEncoder creates a sonic encoder
+func newEncoder(w io.Writer) jsonEncoder {
+	return sonic.ConfigStd.NewEncoder(w)
+}
@@ -0,0 +1,13 @@
+//go:build !jsoniter && !go_json && !(sonic && avx && (linux || windows || darwin) && amd64)
+
+package responses
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// newEncoder creates an encoding/json encoder
+func newEncoder(w io.Writer) jsonEncoder {
+	return json.NewEncoder(w)
+}
@@ -0,0 +1,30 @@
+package responses
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+)
+
+// URLSigner signs URLs granting access to stored objects, such as a storage.Storage
+type URLSigner interface {
+	SignedURL(ctx context.Context, key, method string, expires time.Duration) (string, error)
+}
+
+// SignedRedirect answers 307 Temporary Redirect to a signed download URL of key, valid for
+// expires, so large files are served by the object storage instead of the API
+func SignedRedirect(c *gin.Context, signer URLSigner, key string, expires time.Duration) {
+	url, err := signer.SignedURL(c.Request.Context(), key, http.MethodGet, expires)
+	if err != nil {
+		_ = c.Error(err)
+		InternalServerError(c, i18n.M(i18n.KeyInternalError))
+		return
+	}
+	// La URL caduca, así que la redirección no se guarda en caché
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusTemporaryRedirect, url)
+}
@@ -7,8 +7,15 @@ import (
 	"reflect"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
 )
 
+// RequestIDKey is the context key holding the request ID set by middleware.RequestID
+// Error responses echo it as "request_id", so a client report can be matched with the logs
+const RequestIDKey = "request_id"
+
 // Response represents a standardized API response
 type Response struct {
 	Data    interface{} `json:"data,omitempty"`
@@ -19,21 +26,29 @@ type Response struct {
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Detail interface{} `json:"detail"`
-	Type   string      `json:"type,omitempty"`
+	Detail    interface{} `json:"detail"`
+	Type      string      `json:"type,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 // ValidationErrorResponse represents validation errors
 type ValidationErrorResponse struct {
-	Detail []ResponseValidationError `json:"detail"`
-	Type   string                    `json:"type"`
+	Detail    []ResponseValidationError `json:"detail"`
+	Type      string                    `json:"type"`
+	RequestID string                    `json:"request_id,omitempty"`
 }
 
 // ResponseValidationError represents a single validation error
 type ResponseValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
-	Value   string `json:"value,omitempty"`
+	Field     string      `json:"field"`
+	Path      string      `json:"path,omitempty"`    // JSON path of the field: "items[2].email"
+	Pointer   string      `json:"pointer,omitempty"` // RFC 6901 pointer of the field: "/items/2/email"
+	Message   string      `json:"message"`
+	Value     string      `json:"value,omitempty"`
+	ValueType string      `json:"value_type,omitempty"` // JSON type of the value received
+	RawValue  interface{} `json:"raw_value,omitempty"`  // Value received, as in the JSON; omitted for null
+	Tag       string      `json:"tag,omitempty"`        // Failed validation rule
+	In        string      `json:"in,omitempty"`         // Location of the field: "body", "query", "path" or "header"
 }
 
 // PaginatedResponse represents a paginated response
@@ -92,8 +107,8 @@ func (rb *ResponseBuilder) Send(c *gin.Context) {
 		Success: rb.statusCode >= 200 && rb.statusCode < 300,
 		Errors:  rb.errors,
 	}
-	
-	c.JSON(rb.statusCode, response)
+
+	JSON(c, rb.statusCode, response)
 }
 
 // Success response helpers
@@ -115,51 +130,99 @@ func NoContent(c *gin.Context) {
 
 // Error response helpers
 func BadRequest(c *gin.Context, detail interface{}) {
-	c.JSON(http.StatusBadRequest, ErrorResponse{
-		Detail: detail,
-		Type:   "bad_request",
+	JSON(c, http.StatusBadRequest, ErrorResponse{
+		Detail:    i18n.Localize(c, detail),
+		Type:      "bad_request",
+		RequestID: c.GetString(RequestIDKey),
 	})
 }
 
 func Unauthorized(c *gin.Context, detail interface{}) {
-	c.JSON(http.StatusUnauthorized, ErrorResponse{
-		Detail: detail,
-		Type:   "unauthorized",
+	JSON(c, http.StatusUnauthorized, ErrorResponse{
+		Detail:    i18n.Localize(c, detail),
+		Type:      "unauthorized",
+		RequestID: c.GetString(RequestIDKey),
 	})
 }
 
 func Forbidden(c *gin.Context, detail interface{}) {
-	c.JSON(http.StatusForbidden, ErrorResponse{
-		Detail: detail,
-		Type:   "forbidden",
+	JSON(c, http.StatusForbidden, ErrorResponse{
+		Detail:    i18n.Localize(c, detail),
+		Type:      "forbidden",
+		RequestID: c.GetString(RequestIDKey),
 	})
 }
 
 func NotFound(c *gin.Context, detail interface{}) {
-	c.JSON(http.StatusNotFound, ErrorResponse{
-		Detail: detail,
-		Type:   "not_found",
+	JSON(c, http.StatusNotFound, ErrorResponse{
+		Detail:    i18n.Localize(c, detail),
+		Type:      "not_found",
+		RequestID: c.GetString(RequestIDKey),
+	})
+}
+
+func Conflict(c *gin.Context, detail interface{}) {
+	JSON(c, http.StatusConflict, ErrorResponse{
+		Detail:    i18n.Localize(c, detail),
+		Type:      "conflict",
+		RequestID: c.GetString(RequestIDKey),
+	})
+}
+
+func MethodNotAllowed(c *gin.Context, detail interface{}) {
+	JSON(c, http.StatusMethodNotAllowed, ErrorResponse{
+		Detail:    i18n.Localize(c, detail),
+		Type:      "method_not_allowed",
+		RequestID: c.GetString(RequestIDKey),
 	})
 }
 
 func InternalServerError(c *gin.Context, detail interface{}) {
-	c.JSON(http.StatusInternalServerError, ErrorResponse{
-		Detail: detail,
-		Type:   "internal_server_error",
+	JSON(c, http.StatusInternalServerError, ErrorResponse{
+		Detail:    i18n.Localize(c, detail),
+		Type:      "internal_server_error",
+		RequestID: c.GetString(RequestIDKey),
 	})
 }
 
-func ValidationError(c *gin.Context, errors []ResponseValidationError) {
-	c.JSON(http.StatusBadRequest, ValidationErrorResponse{
-		Detail: errors,
-		Type:   "validation_error",
+// Message sends an error response whose detail is a catalog message, rendered in the locale
+// negotiated from the Accept-Language header of the request
+//
+//	responses.Message(c, http.StatusNotFound, "order.not_found", i18n.Params{"id": id})
+//
+// The error helpers above accept an i18n.M message as detail too
+func Message(c *gin.Context, statusCode int, key string, params ...i18n.Params) {
+	JSON(c, statusCode, ErrorResponse{
+		Detail:    i18n.T(c, key, params...),
+		Type:      StatusType(statusCode),
+		RequestID: c.GetString(RequestIDKey),
 	})
 }
 
+// FromValidationErrors converts validator errors (including struct-level ones) into response errors
+// Each error keeps the field it was reported on
+func FromValidationErrors(errors validation.ValidationErrors) []ResponseValidationError {
+	responseErrors := make([]ResponseValidationError, 0, len(errors))
+	for _, err := range errors {
+		responseErrors = append(responseErrors, ResponseValidationError{
+			Field:     err.Field,
+			Path:      err.Path,
+			Pointer:   err.Pointer,
+			Message:   err.Message,
+			Value:     err.Value,
+			ValueType: err.ValueType,
+			RawValue:  err.RawValue,
+			Tag:       err.Tag,
+			In:        err.In,
+		})
+	}
+	return responseErrors
+}
+
 // Paginated response helper
 func Paginated(c *gin.Context, items interface{}, total, page, pageSize int) {
 	totalPages := (total + pageSize - 1) / pageSize
-	
+
 	response := PaginatedResponse{
 		Items:      items,
 		Total:      total,
@@ -167,7 +230,7 @@ func Paginated(c *gin.Context, items interface{}, total, page, pageSize int) {
 		PageSize:   pageSize,
 		TotalPages: totalPages,
 	}
-	
+
 	Success(c, response)
 }
 
@@ -234,7 +297,7 @@ func (rs ResponseSchemas) AddNotFound(description string) ResponseSchemas {
 
 // JSONResponse sends a JSON response with the specified status code
 func JSONResponse(c *gin.Context, statusCode int, data interface{}) {
-	c.JSON(statusCode, data)
+	JSON(c, statusCode, data)
 }
 
 // XMLResponse sends an XML response with the specified status code
@@ -294,25 +357,30 @@ var (
 		Message: "Operation successful",
 		Success: true,
 	})
-	
+
 	// ErrorResponseModel is the error response model
 	ErrorResponseModel = NewResponseModel(ErrorResponse{}, "Error response", ErrorResponse{
 		Detail: "Error description",
 		Type:   "error_type",
 	})
-	
+
 	// ValidationErrorResponseModel is the validation error response model
 	ValidationErrorResponseModel = NewResponseModel(ValidationErrorResponse{}, "Validation error response", ValidationErrorResponse{
 		Detail: []ResponseValidationError{
 			{
-				Field:   "field_name",
-				Message: "Field is required",
-				Value:   "invalid_value",
+				Field:     "email",
+				Path:      "items[2].email",
+				Pointer:   "/items/2/email",
+				Message:   "The field 'email' must be a valid email",
+				Value:     "invalid_value",
+				ValueType: "string",
+				RawValue:  "invalid_value",
+				Tag:       "email",
 			},
 		},
 		Type: "validation_error",
 	})
-	
+
 	// PaginatedResponseModel is the paginated response model
 	PaginatedResponseModel = NewResponseModel(PaginatedResponse{}, "Paginated response", PaginatedResponse{
 		Items:      []interface{}{"item1", "item2"},
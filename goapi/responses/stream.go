@@ -0,0 +1,311 @@
+package responses
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultStreamThreshold is the size above which JSON responses holding large arrays are streamed
+const DefaultStreamThreshold = 1 << 20
+
+const (
+	streamMinItems  = 64       // Arrays shorter than this are encoded in one piece
+	streamFlushSize = 32 << 10 // Output written between flushes of a streamed response
+)
+
+// streamMarker starts the placeholders of the arrays encoded element by element; the random part
+// keeps response data from matching it
+var streamMarker = func() []byte {
+	nonce := make([]byte, 8)
+	_, _ = rand.Read(nonce)
+	return []byte(`"goapi-stream-` + hex.EncodeToString(nonce) + "-")
+}()
+
+// StreamJSON sends a JSON response written to the client as it is encoded
+// Large arrays, at the top level or in interface{} fields and map values (the data of Success,
+// the items of Paginated, gin.H values), are encoded element by element, and the output is
+// flushed as it goes, so the whole body is never held in memory. The response has no
+// Content-Length, and an encoding error past the first bytes can only truncate it: the error is
+// added to the context. JSON switches to this path by itself above JSONConfig.StreamThreshold
+func StreamJSON(c *gin.Context, statusCode int, value interface{}) {
+	if !bodyAllowed(statusCode) {
+		JSON(c, statusCode, value)
+		return
+	}
+	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	var plan streamPlan
+	template, _ := plan.replace(value)
+	streamJSON(c, statusCode, &plan, template, JSONConfigFromContext(c), 0)
+}
+
+// streamThreshold returns the size above which responses are streamed, negative for never
+func (config JSONConfig) streamThreshold() int {
+	if config.StreamThreshold == 0 {
+		return DefaultStreamThreshold
+	}
+	return config.StreamThreshold
+}
+
+// streamJSON encodes the template of a response and its arrays, buffering the output up to
+// threshold bytes: smaller responses are sent with their Content-Length, larger ones are streamed
+func streamJSON(c *gin.Context, statusCode int, plan *streamPlan, template interface{}, config JSONConfig, threshold int) {
+	scratch := encoderPool.Get().(*pooledEncoder)
+	output := encoderPool.Get().(*pooledEncoder)
+	scratch.buffer.Reset()
+	output.buffer.Reset()
+	defer func() {
+		for _, pooled := range []*pooledEncoder{scratch, output} {
+			if pooled.buffer.Cap() <= maxPooledBuffer {
+				encoderPool.Put(pooled)
+			}
+		}
+	}()
+
+	indent := config.indentation(c)
+	scratch.encoder.SetIndent("", indent)
+	scratch.encoder.SetEscapeHTML(!config.DisableHTMLEscaping)
+	if err := scratch.encoder.Encode(template); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	// La plantilla se copia: el buffer del encoder se reutiliza para los elementos
+	encoded := append([]byte(nil), bytes.TrimSuffix(scratch.buffer.Bytes(), []byte("\n"))...)
+
+	writer := &streamWriter{c: c, statusCode: statusCode, threshold: threshold, buffer: &output.buffer}
+	rest := encoded
+	for len(rest) > 0 && writer.err == nil {
+		start := bytes.Index(rest, streamMarker)
+		if start < 0 {
+			writer.write(rest)
+			break
+		}
+		writer.write(rest[:start])
+		end := start + len(streamMarker) + bytes.IndexByte(rest[start+len(streamMarker):], '"')
+		index, _ := strconv.Atoi(string(rest[start+len(streamMarker) : end]))
+		// Los elementos se sangran como la línea del marcador
+		lineIndent := ""
+		if indent != "" {
+			offset := len(encoded) - len(rest) + start
+			line := encoded[bytes.LastIndexByte(encoded[:offset], '\n')+1 : offset]
+			lineIndent = string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+		}
+		if err := writer.array(plan.arrays[index], scratch, lineIndent, indent); err != nil {
+			writer.fail(err)
+			return
+		}
+		rest = rest[end+1:]
+	}
+	writer.finish()
+}
+
+// streamPlan collects the arrays of a response to encode element by element
+type streamPlan struct {
+	arrays []reflect.Value
+}
+
+// estimate returns the approximate encoded size of the arrays, from the size of a few elements
+func (p *streamPlan) estimate() int {
+	size := 0
+	for _, array := range p.arrays {
+		length := array.Len()
+		samples := []int{0, length / 2, length - 1}
+		sampled := 0
+		for _, index := range samples {
+			encoded, _ := json.Marshal(element(array, index))
+			sampled += len(encoded) + 1 // La coma
+		}
+		size += sampled * length / len(samples)
+	}
+	return size
+}
+
+// element returns the element of an array to encode; addressable elements are encoded through
+// their pointer, as encoding/json does, so pointer-receiver marshalers apply
+func element(array reflect.Value, index int) interface{} {
+	item := array.Index(index)
+	if item.CanAddr() {
+		return item.Addr().Interface()
+	}
+	return item.Interface()
+}
+
+// jsonMarshalerType and textMarshalerType encode themselves: their content is left alone
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// replace returns a copy of value with its large arrays replaced by placeholders, and whether it
+// replaced any
+func (p *streamPlan) replace(value interface{}) (interface{}, bool) {
+	current := reflect.ValueOf(value)
+	for current.Kind() == reflect.Ptr || current.Kind() == reflect.Interface {
+		if current.IsNil() {
+			return value, false
+		}
+		current = current.Elem()
+	}
+	if !current.IsValid() {
+		return value, false
+	}
+	for _, marshaler := range []reflect.Type{jsonMarshalerType, textMarshalerType} {
+		if current.Type().Implements(marshaler) || reflect.PointerTo(current.Type()).Implements(marshaler) {
+			return value, false
+		}
+	}
+
+	switch current.Kind() {
+	case reflect.Slice, reflect.Array:
+		if current.Len() < streamMinItems || current.Type().Elem().Kind() == reflect.Uint8 {
+			return value, false
+		}
+		p.arrays = append(p.arrays, current)
+		return json.RawMessage(string(streamMarker) + strconv.Itoa(len(p.arrays)-1) + `"`), true
+	case reflect.Struct:
+		var copied reflect.Value
+		for i := 0; i < current.NumField(); i++ {
+			field := current.Field(i)
+			if field.Kind() != reflect.Interface || field.IsNil() || !current.Type().Field(i).IsExported() {
+				continue
+			}
+			replaced, ok := p.replace(field.Interface())
+			if !ok {
+				continue
+			}
+			if !copied.IsValid() {
+				copied = reflect.New(current.Type()).Elem()
+				copied.Set(current)
+			}
+			copied.Field(i).Set(reflect.ValueOf(replaced))
+		}
+		if copied.IsValid() {
+			// Una copia de un struct direccionable se pasa por puntero: sus campos siguen usando
+			// los marshalers de receptor puntero
+			if current.CanAddr() {
+				return copied.Addr().Interface(), true
+			}
+			return copied.Interface(), true
+		}
+	case reflect.Map:
+		if current.Type().Key().Kind() != reflect.String || current.Type().Elem().Kind() != reflect.Interface {
+			return value, false
+		}
+		var copied reflect.Value
+		iterator := current.MapRange()
+		for iterator.Next() {
+			if iterator.Value().IsNil() {
+				continue
+			}
+			replaced, ok := p.replace(iterator.Value().Interface())
+			if !ok {
+				continue
+			}
+			if !copied.IsValid() {
+				// El mapa de la aplicación no se modifica
+				copied = reflect.MakeMapWithSize(current.Type(), current.Len())
+				for _, key := range current.MapKeys() {
+					copied.SetMapIndex(key, current.MapIndex(key))
+				}
+			}
+			copied.SetMapIndex(iterator.Key(), reflect.ValueOf(replaced))
+		}
+		if copied.IsValid() {
+			return copied.Interface(), true
+		}
+	}
+	return value, false
+}
+
+// streamWriter buffers a response until it exceeds the threshold, then streams it
+type streamWriter struct {
+	c          *gin.Context
+	statusCode int
+	threshold  int
+	buffer     *bytes.Buffer
+	committed  bool  // The status and headers were sent
+	err        error // Writing to the client failed
+}
+
+// array writes an array element by element, indented as its line
+func (w *streamWriter) array(array reflect.Value, scratch *pooledEncoder, lineIndent, indent string) error {
+	w.write([]byte("["))
+	if indent != "" {
+		scratch.encoder.SetIndent(lineIndent+indent, indent)
+	}
+	for i := 0; i < array.Len() && w.err == nil; i++ {
+		if i > 0 {
+			w.write([]byte(","))
+		}
+		if indent != "" {
+			w.write([]byte("\n" + lineIndent + indent))
+		}
+		scratch.buffer.Reset()
+		if err := scratch.encoder.Encode(element(array, i)); err != nil {
+			return err
+		}
+		w.write(bytes.TrimSuffix(scratch.buffer.Bytes(), []byte("\n")))
+	}
+	if indent != "" {
+		w.write([]byte("\n" + lineIndent))
+	}
+	w.write([]byte("]"))
+	return nil
+}
+
+// write adds output, sending the response once it exceeds the threshold
+func (w *streamWriter) write(data []byte) {
+	if w.err != nil {
+		return
+	}
+	w.buffer.Write(data)
+	if !w.committed && w.buffer.Len() > w.threshold {
+		w.c.Status(w.statusCode)
+		w.committed = true
+	}
+	if w.committed && w.buffer.Len() >= streamFlushSize {
+		w.flush()
+	}
+}
+
+// flush sends the buffered output to the client
+func (w *streamWriter) flush() {
+	if _, err := w.c.Writer.Write(w.buffer.Bytes()); err != nil {
+		w.err = err
+	}
+	w.buffer.Reset()
+	w.c.Writer.Flush()
+}
+
+// finish sends the rest of the response; responses below the threshold get their Content-Length
+func (w *streamWriter) finish() {
+	if w.committed {
+		if w.buffer.Len() > 0 {
+			w.flush()
+		}
+		return
+	}
+	if w.c.Writer.Header().Get("Content-Encoding") == "" {
+		w.c.Writer.Header().Set("Content-Length", strconv.Itoa(w.buffer.Len()))
+	}
+	w.c.Status(w.statusCode)
+	_, _ = w.c.Writer.Write(w.buffer.Bytes())
+}
+
+// fail reports an encoding error: a 500 while nothing was sent, a truncated response otherwise
+func (w *streamWriter) fail(err error) {
+	if !w.committed {
+		_ = w.c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	w.flush()
+	_ = w.c.Error(err)
+	w.c.Abort()
+}
@@ -0,0 +1,178 @@
+package responses
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// ValidationErrorFormat renders the body of a validation failure
+type ValidationErrorFormat func(errors []ResponseValidationError) interface{}
+
+// ValidationConfig selects the status code and body shape of validation failures
+type ValidationConfig struct {
+	StatusCode int                   // 0 = the default of each call site (400, or 422 for request validation)
+	Format     ValidationErrorFormat // nil = DefaultValidationFormat
+}
+
+// ValidationOption overrides the validation configuration for a single response
+type ValidationOption func(*validationSettings)
+
+// validationSettings is the resolved configuration of a single response
+type validationSettings struct {
+	statusCode        int
+	defaultStatusCode int
+	format            ValidationErrorFormat
+}
+
+// ValidationConfigKey is the gin context key holding the validation configuration of the API
+const ValidationConfigKey = "goapi.validation_config"
+
+var (
+	defaultValidationConfig ValidationConfig
+	validationConfigMutex   sync.RWMutex
+)
+
+// SetDefaultValidationConfig sets the process-wide validation configuration
+// It applies to requests that were not configured by ValidationConfigMiddleware (GoAPI.New installs it)
+func SetDefaultValidationConfig(config ValidationConfig) {
+	validationConfigMutex.Lock()
+	defer validationConfigMutex.Unlock()
+	defaultValidationConfig = config
+}
+
+// ValidationConfigMiddleware stores a validation configuration in the context of every request
+func ValidationConfigMiddleware(config ValidationConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ValidationConfigKey, config)
+		c.Next()
+	}
+}
+
+// ValidationConfigFromContext returns the validation configuration of a request
+func ValidationConfigFromContext(c *gin.Context) ValidationConfig {
+	if c != nil {
+		if value, exists := c.Get(ValidationConfigKey); exists {
+			if config, ok := value.(ValidationConfig); ok {
+				return config
+			}
+		}
+	}
+	validationConfigMutex.RLock()
+	defer validationConfigMutex.RUnlock()
+	return defaultValidationConfig
+}
+
+// WithValidationStatus overrides the status code of a validation failure
+func WithValidationStatus(statusCode int) ValidationOption {
+	return func(settings *validationSettings) {
+		settings.statusCode = statusCode
+	}
+}
+
+// WithValidationFormat overrides the body shape of a validation failure
+func WithValidationFormat(format ValidationErrorFormat) ValidationOption {
+	return func(settings *validationSettings) {
+		settings.format = format
+	}
+}
+
+// WithDefaultValidationStatus sets the status code used when neither the API nor the call chooses one
+func WithDefaultValidationStatus(statusCode int) ValidationOption {
+	return func(settings *validationSettings) {
+		settings.defaultStatusCode = statusCode
+	}
+}
+
+// ValidationError sends a validation error response
+// The status code (400 by default) and body shape follow the API configuration and the options
+func ValidationError(c *gin.Context, errors []ResponseValidationError, opts ...ValidationOption) {
+	config := ValidationConfigFromContext(c)
+	settings := validationSettings{
+		statusCode:        config.StatusCode,
+		defaultStatusCode: http.StatusBadRequest,
+		format:            config.Format,
+	}
+	for _, option := range opts {
+		option(&settings)
+	}
+
+	statusCode := settings.statusCode
+	if statusCode == 0 {
+		statusCode = settings.defaultStatusCode
+	}
+	format := settings.format
+	if format == nil {
+		format = DefaultValidationFormat
+	}
+
+	body := format(errors)
+	if response, ok := body.(ValidationErrorResponse); ok {
+		response.RequestID = c.GetString(RequestIDKey)
+		body = response
+	}
+	JSON(c, statusCode, body)
+}
+
+// ValidationFailed sends the validation error response of a validator error
+// Messages are rendered in the locale of the request
+func ValidationFailed(c *gin.Context, err error, opts ...ValidationOption) {
+	var validationErrors validation.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		validationErrors = validation.FormatValidationErrorsLocale(err, i18n.Locale(c))
+	}
+	ValidationError(c, FromValidationErrors(validationErrors), opts...)
+}
+
+// DefaultValidationFormat renders {"detail": [{"field", "message", "value"}], "type": "validation_error"}
+func DefaultValidationFormat(errors []ResponseValidationError) interface{} {
+	return ValidationErrorResponse{
+		Detail: errors,
+		Type:   "validation_error",
+	}
+}
+
+// FastAPIValidationError is a single error in the FastAPI validation error format
+type FastAPIValidationError struct {
+	Loc   []string `json:"loc"`
+	Msg   string   `json:"msg"`
+	Type  string   `json:"type"`
+	Input string   `json:"input,omitempty"`
+}
+
+// FastAPIValidationFormat renders the FastAPI body: {"detail": [{"loc": ["body", "name"], "msg", "type"}]}
+func FastAPIValidationFormat(errors []ResponseValidationError) interface{} {
+	detail := make([]FastAPIValidationError, 0, len(errors))
+	for _, err := range errors {
+		location := err.In
+		if location == "" {
+			location = "body"
+		}
+		loc := []string{location}
+		switch {
+		case err.Path != "":
+			loc = append(loc, validation.SplitPath(err.Path)...)
+		case err.Field != "" && err.Field != location:
+			loc = append(loc, strings.Split(err.Field, ".")...)
+		}
+
+		errorType := "value_error"
+		if err.Tag != "" {
+			errorType += "." + err.Tag
+		}
+
+		detail = append(detail, FastAPIValidationError{
+			Loc:   loc,
+			Msg:   err.Message,
+			Type:  errorType,
+			Input: err.Value,
+		})
+	}
+	return gin.H{"detail": detail}
+}
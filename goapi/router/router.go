@@ -2,6 +2,10 @@
 package router
 
 import (
+	"net/http"
+	"strings"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,6 +24,103 @@ type Route struct {
 	Description string
 	Responses   map[int]string
 	Parameters  []Parameter
+	// Translations holds localized documentation keyed by locale (e.g. "es")
+	Translations map[string]RouteTranslation
+	// Middlewares run before the handler, only for this route
+	Middlewares []gin.HandlerFunc
+	// ValidateRequest overrides the API-wide request validation setting (nil = inherit)
+	ValidateRequest *bool
+	// StrictJSON overrides the API-wide rejection of unknown JSON properties (nil = inherit)
+	StrictJSON *bool
+	// ResponseModels holds the declared response body model by status code
+	ResponseModels map[int]interface{}
+	// OperationID uniquely identifies the operation for client code generators
+	OperationID string
+	// ExternalDocs links to additional documentation of the operation
+	ExternalDocs *ExternalDocs
+	// ErrorCodes lists the registered error codes the handler can return
+	ErrorCodes []string
+	// Streaming routes read the request body themselves; middlewares must not buffer it
+	Streaming bool
+	// Listeners restricts the route to the named listeners of RunListeners (empty = every listener)
+	Listeners []string
+	// RateLimit limits the requests to this route, separately from the global limit
+	RateLimit *RateLimit
+	// Coalesce shares the response of identical concurrent GET requests (see middleware.Coalesce)
+	Coalesce *Coalesce
+	// Cache builds the response caching middleware of the route, given the handler refreshing
+	// stale responses in the background (see cache.Policy)
+	Cache func(revalidate http.Handler) gin.HandlerFunc
+	// CORS replaces the global CORS policy for this route and its preflights (see middleware.RouteCORS)
+	CORS gin.HandlerFunc
+	// Consumes and Produces list the media types of the request and response bodies (default JSON)
+	Consumes []string
+	Produces []string
+	// RequestExamples and ResponseExamples hold named sample payloads, in declaration order
+	RequestExamples  []Example
+	ResponseExamples map[int][]Example
+	// Specs lists the named specifications documenting the route (empty = the main one)
+	Specs []string
+	// Callbacks documents the requests the API sends back to the client after this operation
+	Callbacks []Callback
+	// Auth overrides the authentication of the global auth middleware for this route
+	Auth AuthMode
+}
+
+// AuthMode tells the authentication middleware whether a route needs an authenticated user
+type AuthMode int
+
+// Authentication modes of a route
+const (
+	// AuthDefault follows the configuration of the authentication middleware
+	AuthDefault AuthMode = iota
+	// AuthRequired rejects requests without valid credentials, even with optional authentication
+	AuthRequired
+	// AuthAnonymous serves requests without credentials; valid credentials still identify the user
+	AuthAnonymous
+)
+
+// Callback is a request the API sends to a URL given by the client, such as a subscription
+// endpoint; Expression is the runtime expression of that URL ("{$request.body#/callbackUrl}")
+type Callback struct {
+	Name        string
+	Expression  string
+	Method      string
+	Payload     interface{}
+	Description string
+}
+
+// Example is a named sample payload shown in the documentation
+type Example struct {
+	Name  string
+	Value interface{}
+}
+
+// RateLimit is the token bucket of a route: Burst requests at once, refilled at RequestsPerSecond
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+	Key               func(c *gin.Context) string // Client identification (nil = client IP)
+}
+
+// Coalesce enables request coalescing on a route
+type Coalesce struct {
+	Key func(c *gin.Context) string // Identifies identical requests (nil = middleware.CoalesceKey)
+}
+
+// ExternalDocs references external documentation
+type ExternalDocs struct {
+	URL         string
+	Description string
+}
+
+// AnyMethods lists the methods registered by Any, in the order they are reported in Allow headers
+var AnyMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// RouteTranslation contains the localized documentation of a route
+type RouteTranslation struct {
+	Summary     string
+	Description string
 }
 
 // Parameter represents a parameter in the API
@@ -29,6 +130,7 @@ type Parameter struct {
 	Type        string // "string", "integer", "boolean", etc.
 	Format      string // "int64", "date-time", etc.
 	Required    bool
+	Nullable    bool // Query parameters accepting the literal "null"
 	Description string
 	Schema      interface{} // For body parameters
 }
@@ -52,6 +154,26 @@ func WithTags(tags ...string) RouteOption {
 	}
 }
 
+// WithSpec documents a route in named specifications instead of the main one
+func WithSpec(names ...string) RouteOption {
+	return func(route *Route) {
+		route.Specs = append(route.Specs, names...)
+	}
+}
+
+// WithCallback documents a POST request the API sends to a URL taken from the request
+func WithCallback(name, expression string, payload interface{}, description string) RouteOption {
+	return func(route *Route) {
+		route.Callbacks = append(route.Callbacks, Callback{
+			Name:        name,
+			Expression:  expression,
+			Method:      http.MethodPost,
+			Payload:     payload,
+			Description: description,
+		})
+	}
+}
+
 // WithSummary adds a summary to a route for API documentation
 // The summary provides a brief description of what the endpoint does
 func WithSummary(summary string) RouteOption {
@@ -68,6 +190,158 @@ func WithDescription(description string) RouteOption {
 	}
 }
 
+// WithOperationID sets the operationId of a route
+// Client code generators use it to name the generated method, so it must be unique
+func WithOperationID(operationID string) RouteOption {
+	return func(route *Route) {
+		route.OperationID = operationID
+	}
+}
+
+// WithExternalDocs links a route to external documentation
+func WithExternalDocs(url, description string) RouteOption {
+	return func(route *Route) {
+		route.ExternalDocs = &ExternalDocs{URL: url, Description: description}
+	}
+}
+
+// WithErrorCodes declares the registered error codes a route can return (see responses.RegisterErrorCode)
+func WithErrorCodes(codes ...string) RouteOption {
+	return func(route *Route) {
+		route.ErrorCodes = append(route.ErrorCodes, codes...)
+	}
+}
+
+// WithStreaming marks a route as streaming: the handler consumes the request body as it arrives
+// (large uploads piped to a blob store) and middlewares skip reading or buffering it
+func WithStreaming() RouteOption {
+	return func(route *Route) {
+		route.Streaming = true
+	}
+}
+
+// WithAuthRequired requires valid credentials on a route, even when the authentication
+// middleware lets anonymous requests through; the 401 response is documented
+func WithAuthRequired() RouteOption {
+	return func(route *Route) {
+		route.Auth = AuthRequired
+		if _, declared := route.Responses[http.StatusUnauthorized]; !declared {
+			WithResponse(http.StatusUnauthorized, "Authentication required")(route)
+		}
+	}
+}
+
+// WithAllowAnonymous keeps a route public when authentication is enabled globally (health checks,
+// login); credentials sent anyway still identify the user, and invalid ones are ignored
+func WithAllowAnonymous() RouteOption {
+	return func(route *Route) {
+		route.Auth = AuthAnonymous
+	}
+}
+
+// WithListeners restricts a route to the named listeners
+func WithListeners(names ...string) RouteOption {
+	return func(route *Route) {
+		route.Listeners = append(route.Listeners, names...)
+	}
+}
+
+// WithRateLimit limits the requests to a route per client, identified by the optional key function
+func WithRateLimit(requestsPerSecond float64, burst int, key ...func(c *gin.Context) string) RouteOption {
+	return func(route *Route) {
+		route.RateLimit = &RateLimit{RequestsPerSecond: requestsPerSecond, Burst: burst}
+		if len(key) > 0 {
+			route.RateLimit.Key = key[0]
+		}
+		if _, declared := route.Responses[http.StatusTooManyRequests]; !declared {
+			WithResponse(http.StatusTooManyRequests, "Rate limit exceeded")(route)
+		}
+	}
+}
+
+// WithCoalescing deduplicates identical concurrent GET requests to a route, identified by the
+// optional key function
+func WithCoalescing(key ...func(c *gin.Context) string) RouteOption {
+	return func(route *Route) {
+		route.Coalesce = &Coalesce{}
+		if len(key) > 0 {
+			route.Coalesce.Key = key[0]
+		}
+	}
+}
+
+// WithCache caches the responses of a route with the middleware built by cache
+func WithCache(cache func(revalidate http.Handler) gin.HandlerFunc) RouteOption {
+	return func(route *Route) {
+		route.Cache = cache
+	}
+}
+
+// WithLocalizedSummary adds a summary for a specific locale
+// Localized summaries are served when the spec is requested with ?lang=<locale>
+func WithLocalizedSummary(locale, summary string) RouteOption {
+	return func(route *Route) {
+		translation := route.translation(locale)
+		translation.Summary = summary
+		route.Translations[NormalizeLocale(locale)] = translation
+	}
+}
+
+// WithLocalizedDescription adds a description for a specific locale
+// Localized descriptions are served when the spec is requested with ?lang=<locale>
+func WithLocalizedDescription(locale, description string) RouteOption {
+	return func(route *Route) {
+		translation := route.translation(locale)
+		translation.Description = description
+		route.Translations[NormalizeLocale(locale)] = translation
+	}
+}
+
+// translation returns the current translation for a locale, initializing the map if needed
+func (route *Route) translation(locale string) RouteTranslation {
+	if route.Translations == nil {
+		route.Translations = make(map[string]RouteTranslation)
+	}
+	return route.Translations[NormalizeLocale(locale)]
+}
+
+// LocalizedSummary returns the summary for the locale, falling back to the default summary
+func (route Route) LocalizedSummary(locale string) string {
+	if translation, ok := route.lookupTranslation(locale); ok && translation.Summary != "" {
+		return translation.Summary
+	}
+	return route.Summary
+}
+
+// LocalizedDescription returns the description for the locale, falling back to the default description
+func (route Route) LocalizedDescription(locale string) string {
+	if translation, ok := route.lookupTranslation(locale); ok && translation.Description != "" {
+		return translation.Description
+	}
+	return route.Description
+}
+
+// lookupTranslation finds the translation for a locale, trying the base language ("es" for "es-MX") second
+func (route Route) lookupTranslation(locale string) (RouteTranslation, bool) {
+	if locale == "" || len(route.Translations) == 0 {
+		return RouteTranslation{}, false
+	}
+	locale = NormalizeLocale(locale)
+	if translation, ok := route.Translations[locale]; ok {
+		return translation, true
+	}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		translation, ok := route.Translations[base]
+		return translation, ok
+	}
+	return RouteTranslation{}, false
+}
+
+// NormalizeLocale normalizes a locale identifier ("es_MX", "ES-mx" -> "es-mx")
+func NormalizeLocale(locale string) string {
+	return i18n.NormalizeLocale(locale)
+}
+
 // WithResponse adds an expected response configuration to a route
 // This defines the possible HTTP status codes and their descriptions
 func WithResponse(statusCode int, description string) RouteOption {
@@ -79,6 +353,40 @@ func WithResponse(statusCode int, description string) RouteOption {
 	}
 }
 
+// WithResponseModel declares the model of the response body for a status code
+// The model is documented in the spec and, in debug mode, can be checked against real payloads
+func WithResponseModel(statusCode int, model interface{}, description string) RouteOption {
+	return func(route *Route) {
+		WithResponse(statusCode, description)(route)
+		if route.ResponseModels == nil {
+			route.ResponseModels = make(map[int]interface{})
+		}
+		route.ResponseModels[statusCode] = model
+	}
+}
+
+// WithRequestExample adds a named example of the request body
+// Several examples document the variants of a payload; the first one is the default
+func WithRequestExample(name string, value interface{}) RouteOption {
+	return func(route *Route) {
+		route.RequestExamples = append(route.RequestExamples, Example{Name: name, Value: value})
+	}
+}
+
+// WithResponseExample adds a named example of the response body for a status code
+// The status is declared with its standard description when the route does not document it
+func WithResponseExample(statusCode int, name string, value interface{}) RouteOption {
+	return func(route *Route) {
+		if _, declared := route.Responses[statusCode]; !declared {
+			WithResponse(statusCode, http.StatusText(statusCode))(route)
+		}
+		if route.ResponseExamples == nil {
+			route.ResponseExamples = make(map[int][]Example)
+		}
+		route.ResponseExamples[statusCode] = append(route.ResponseExamples[statusCode], Example{Name: name, Value: value})
+	}
+}
+
 // WithParameter adds a parameter configuration to a route
 // This allows for flexible parameter definitions with custom locations and types
 func WithParameter(name, in, paramType, description string, required bool) RouteOption {
@@ -104,6 +412,15 @@ func WithQueryParameter(name, paramType, description string, required bool) Rout
 	return WithParameter(name, "query", paramType, description, required)
 }
 
+// WithNullableQueryParameter adds an optional query parameter that also accepts the literal "null"
+// Handlers can then tell a missing parameter (?) from an explicit null (?manager_id=null) and a value
+func WithNullableQueryParameter(name, paramType, description string) RouteOption {
+	return func(route *Route) {
+		WithQueryParameter(name, paramType, description, false)(route)
+		route.Parameters[len(route.Parameters)-1].Nullable = true
+	}
+}
+
 // WithRequestBody adds a request body schema configuration to a route
 // This defines the expected structure and format of the request payload
 func WithRequestBody(schema interface{}, description string) RouteOption {
@@ -119,17 +436,64 @@ func WithRequestBody(schema interface{}, description string) RouteOption {
 	}
 }
 
+// WithConsumes documents the media types accepted in the request body, such as application/xml
+func WithConsumes(mediaTypes ...string) RouteOption {
+	return func(route *Route) {
+		route.Consumes = append(route.Consumes, mediaTypes...)
+	}
+}
+
+// WithProduces documents the media types of the response bodies, such as application/xml
+func WithProduces(mediaTypes ...string) RouteOption {
+	return func(route *Route) {
+		route.Produces = append(route.Produces, mediaTypes...)
+	}
+}
+
 // WithJSONSchema creates a JSON schema configuration from a struct example
 // This automatically generates OpenAPI schema from Go struct definitions
 func WithJSONSchema(example interface{}, description string) RouteOption {
 	return WithRequestBody(example, description)
 }
 
+// WithMiddleware adds middlewares that run only for this route, before its handler
+func WithMiddleware(middlewares ...gin.HandlerFunc) RouteOption {
+	return func(route *Route) {
+		route.Middlewares = append(route.Middlewares, middlewares...)
+	}
+}
+
+// WithCORS sets the CORS handler of the route, built with middleware.RouteCORS
+// Routes sharing a path and method can only have one policy; preflights use the policy of the
+// method they ask for
+func WithCORS(handler gin.HandlerFunc) RouteOption {
+	return func(route *Route) {
+		route.CORS = handler
+	}
+}
+
+// WithRequestValidation enables or disables the validation of incoming requests for this route
+// Requests are validated against the declared parameters and request body; see GoAPI.EnableRequestValidation
+func WithRequestValidation(enabled bool) RouteOption {
+	return func(route *Route) {
+		route.ValidateRequest = &enabled
+	}
+}
+
+// WithStrictJSON enables or disables the rejection of unknown properties in the JSON body of this
+// route; see APIConfig.StrictJSON
+func WithStrictJSON(enabled bool) RouteOption {
+	return func(route *Route) {
+		route.StrictJSON = &enabled
+	}
+}
+
 // RouterGroup represents a group of routes with a common path prefix
 // It allows for organizing related routes and applying common middleware
 type RouterGroup struct {
-	apiProvider APIProvider // The API instance that will handle route registration
-	pathPrefix  string      // Common path prefix for all routes in this group
+	apiProvider APIProvider   // The API instance that will handle route registration
+	pathPrefix  string        // Common path prefix for all routes in this group
+	options     []RouteOption // Options applied to every route of the group, before the route's own
 }
 
 // NewRouterGroup creates and initializes a new route group
@@ -145,38 +509,81 @@ func NewRouterGroup(apiProvider APIProvider, pathPrefix string) *RouterGroup {
 	}
 }
 
+// WithOptions applies route options to every route registered afterwards in the group and its
+// subgroups (e.g. a CORS policy or tags); options passed to a route are applied after them
+func (routerGroup *RouterGroup) WithOptions(opts ...RouteOption) *RouterGroup {
+	routerGroup.options = append(routerGroup.options, opts...)
+	return routerGroup
+}
+
+// routeOptions prepends the group options to the options of a route
+func (routerGroup *RouterGroup) routeOptions(opts []RouteOption) []RouteOption {
+	return append(routerGroup.options[:len(routerGroup.options):len(routerGroup.options)], opts...)
+}
+
 // GET registers a new GET route in the group with the specified path and handler
 // The final route path will be the group prefix combined with the provided path
 func (routerGroup *RouterGroup) GET(path string, handler gin.HandlerFunc, opts ...RouteOption) {
-	routerGroup.apiProvider.AddRoute("GET", routerGroup.pathPrefix+path, handler, opts...)
+	routerGroup.apiProvider.AddRoute("GET", routerGroup.pathPrefix+path, handler, routerGroup.routeOptions(opts)...)
 }
 
 // POST registers a new POST route in the group with the specified path and handler
 // The final route path will be the group prefix combined with the provided path
 func (routerGroup *RouterGroup) POST(path string, handler gin.HandlerFunc, opts ...RouteOption) {
-	routerGroup.apiProvider.AddRoute("POST", routerGroup.pathPrefix+path, handler, opts...)
+	routerGroup.apiProvider.AddRoute("POST", routerGroup.pathPrefix+path, handler, routerGroup.routeOptions(opts)...)
 }
 
 // PUT registers a new PUT route in the group with the specified path and handler
 // The final route path will be the group prefix combined with the provided path
 func (routerGroup *RouterGroup) PUT(path string, handler gin.HandlerFunc, opts ...RouteOption) {
-	routerGroup.apiProvider.AddRoute("PUT", routerGroup.pathPrefix+path, handler, opts...)
+	routerGroup.apiProvider.AddRoute("PUT", routerGroup.pathPrefix+path, handler, routerGroup.routeOptions(opts)...)
 }
 
 // DELETE registers a new DELETE route in the group with the specified path and handler
 // The final route path will be the group prefix combined with the provided path
 func (routerGroup *RouterGroup) DELETE(path string, handler gin.HandlerFunc, opts ...RouteOption) {
-	routerGroup.apiProvider.AddRoute("DELETE", routerGroup.pathPrefix+path, handler, opts...)
+	routerGroup.apiProvider.AddRoute("DELETE", routerGroup.pathPrefix+path, handler, routerGroup.routeOptions(opts)...)
 }
 
 // PATCH registers a new PATCH route in the group with the specified path and handler
 // The final route path will be the group prefix combined with the provided path
 func (routerGroup *RouterGroup) PATCH(path string, handler gin.HandlerFunc, opts ...RouteOption) {
-	routerGroup.apiProvider.AddRoute("PATCH", routerGroup.pathPrefix+path, handler, opts...)
+	routerGroup.apiProvider.AddRoute("PATCH", routerGroup.pathPrefix+path, handler, routerGroup.routeOptions(opts)...)
+}
+
+// HEAD registers a new HEAD route in the group with the specified path and handler
+// GET routes already answer HEAD automatically; use it only for a dedicated handler
+func (routerGroup *RouterGroup) HEAD(path string, handler gin.HandlerFunc, opts ...RouteOption) {
+	routerGroup.apiProvider.AddRoute("HEAD", routerGroup.pathPrefix+path, handler, routerGroup.routeOptions(opts)...)
+}
+
+// OPTIONS registers a new OPTIONS route in the group with the specified path and handler
+// Every path already answers OPTIONS automatically; use it only for a dedicated handler
+func (routerGroup *RouterGroup) OPTIONS(path string, handler gin.HandlerFunc, opts ...RouteOption) {
+	routerGroup.apiProvider.AddRoute("OPTIONS", routerGroup.pathPrefix+path, handler, routerGroup.routeOptions(opts)...)
+}
+
+// Any registers the handler in the group for every method of AnyMethods
+func (routerGroup *RouterGroup) Any(path string, handler gin.HandlerFunc, opts ...RouteOption) {
+	for _, method := range AnyMethods {
+		routerGroup.apiProvider.AddRoute(method, routerGroup.pathPrefix+path, handler, AnyMethodOptions(method, routerGroup.routeOptions(opts))...)
+	}
+}
+
+// AnyMethodOptions returns the options of one method registered by Any
+// The operationId gets the method as suffix so every operation keeps a unique one
+func AnyMethodOptions(method string, opts []RouteOption) []RouteOption {
+	return append(opts[:len(opts):len(opts)], func(route *Route) {
+		if route.OperationID != "" {
+			route.OperationID += "_" + strings.ToLower(method)
+		}
+	})
 }
 
 // Group creates a new route subgroup with an additional path prefix
 // This allows for nested route organization and hierarchical path structures
 func (routerGroup *RouterGroup) Group(path string) *RouterGroup {
-	return NewRouterGroup(routerGroup.apiProvider, routerGroup.pathPrefix+path)
+	group := NewRouterGroup(routerGroup.apiProvider, routerGroup.pathPrefix+path)
+	group.options = routerGroup.routeOptions(nil)
+	return group
 }
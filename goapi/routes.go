@@ -0,0 +1,231 @@
+package goapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// DebugRoutesPath serves the route table in debug mode
+const DebugRoutesPath = "/debug/routes"
+
+// RouteInfo describes a registered route for listings (goapi routes, /debug/routes)
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`           // Path the route is served on, including BasePath in BasePathApply mode
+	Name        string   `json:"name,omitempty"` // operationId
+	Tags        []string `json:"tags,omitempty"`
+	Middlewares []string `json:"middlewares"` // Global and route middlewares, in execution order
+	Handler     string   `json:"handler"`
+	Location    string   `json:"location,omitempty"` // file:line of the handler
+}
+
+// RouteInfoKey is the context key holding the *RouteInfo of the matched route
+const RouteInfoKey = "goapi.route"
+
+// CurrentRoute returns the metadata of the route matching a request, or nil for unknown routes
+// It is precomputed when routes are mounted, so middlewares (metrics, authorization, rate limits)
+// can key on the route template — "/users/:id", not "/users/42" — without parsing the path or
+// allocating. HEAD requests to GET routes get the GET route
+//
+//	if route := goapi.CurrentRoute(c); route != nil {
+//		requests.WithLabelValues(route.Method, route.Path).Inc()
+//	}
+//
+// The value must not be modified; it is shared by every request to the route
+func CurrentRoute(c *gin.Context) *RouteInfo {
+	if value, exists := c.Get(RouteInfoKey); exists {
+		info, _ := value.(*RouteInfo)
+		return info
+	}
+	return nil
+}
+
+// RouteTable describes every registered route with its middleware chain and handler
+func (a *GoAPI) RouteTable() []RouteInfo {
+	a.routesMutex.Lock()
+	defer a.routesMutex.Unlock()
+
+	global := a.globalMiddlewareNames()
+	table := make([]RouteInfo, 0, len(a.routes))
+	for _, route := range a.routes {
+		table = append(table, a.routeInfo(route, a.routeHandlers(route), global))
+	}
+	return table
+}
+
+// globalMiddlewareNames returns the names of the global middlewares, in execution order
+func (a *GoAPI) globalMiddlewareNames() []string {
+	global := make([]string, 0, len(a.middlewares))
+	for _, middleware := range a.middlewares {
+		global = append(global, middlewareName(middleware))
+	}
+	return global
+}
+
+// routeInfo describes a route given its handler chain
+func (a *GoAPI) routeInfo(route router.Route, handlers []gin.HandlerFunc, global []string) RouteInfo {
+	middlewares := append([]string(nil), global...)
+	for _, handler := range handlers[:len(handlers)-1] {
+		middlewares = append(middlewares, middlewareName(handler))
+	}
+	return RouteInfo{
+		Method:      route.Method,
+		Path:        a.mountPath(route.Path),
+		Name:        route.OperationID,
+		Tags:        route.Tags,
+		Middlewares: middlewares,
+		Handler:     functionName(route.Handler),
+		Location:    functionLocation(route.Handler),
+	}
+}
+
+// mountedRoute is the data of a mounted route looked up on each request
+type mountedRoute struct {
+	info       *RouteInfo
+	streaming  bool
+	strictJSON bool
+	cors       gin.HandlerFunc
+	auth       router.AuthMode
+}
+
+// routeLookup indexes the mounted routes by method and route template
+// Two levels avoid building a "METHOD path" key on every request
+type routeLookup map[string]map[string]*mountedRoute
+
+// add indexes a mounted route
+func (l routeLookup) add(method, path string, route *mountedRoute) {
+	if l[method] == nil {
+		l[method] = make(map[string]*mountedRoute)
+	}
+	l[method][path] = route
+}
+
+// find returns the route of a method and template; HEAD falls back to GET
+func (l routeLookup) find(method, path string) *mountedRoute {
+	if route, found := l[method][path]; found {
+		return route
+	}
+	if method == http.MethodHead {
+		return l[http.MethodGet][path]
+	}
+	return nil
+}
+
+// WriteRouteTable prints routes as an aligned table
+// The middlewares shared by every route are printed once above the table
+func WriteRouteTable(w io.Writer, routes []RouteInfo) error {
+	shared := sharedMiddlewares(routes)
+	if len(shared) > 0 {
+		fmt.Fprintf(w, "Global middleware: %s\n\n", strings.Join(shared, " > "))
+	}
+
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "METHOD\tPATH\tNAME\tTAGS\tMIDDLEWARE\tHANDLER")
+	for _, route := range routes {
+		handler := route.Handler
+		if route.Location != "" {
+			handler += " (" + route.Location + ")"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			route.Method, route.Path, orDash(route.Name), orDash(strings.Join(route.Tags, ",")),
+			orDash(strings.Join(route.Middlewares[len(shared):], " > ")), handler)
+	}
+	return table.Flush()
+}
+
+// sharedMiddlewares returns the middleware chain prefix common to every route
+func sharedMiddlewares(routes []RouteInfo) []string {
+	if len(routes) == 0 {
+		return nil
+	}
+	shared := routes[0].Middlewares
+	for _, route := range routes[1:] {
+		length := 0
+		for length < len(shared) && length < len(route.Middlewares) && shared[length] == route.Middlewares[length] {
+			length++
+		}
+		shared = shared[:length]
+	}
+	return shared
+}
+
+// serveRouteTable handles GET /debug/routes: JSON by default, a table with ?format=text
+func (a *GoAPI) serveRouteTable(c *gin.Context) {
+	routes := a.RouteTable()
+	if c.Query("format") == "text" {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = WriteRouteTable(c.Writer, routes)
+		return
+	}
+	c.JSON(http.StatusOK, routes)
+}
+
+// closureSuffix matches the names the compiler gives to closures (".func1", ".func2.1")
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+(\.\d+)*$`)
+
+// functionName returns the package-qualified name of a function ("handlers.ListUsers")
+func functionName(fn interface{}) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return "-"
+	}
+	function := runtime.FuncForPC(value.Pointer())
+	if function == nil {
+		return "-"
+	}
+	name := function.Name()
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
+
+// middlewareName names a middleware after the constructor that created it ("middleware.CORS")
+func middlewareName(fn interface{}) string {
+	name := closureSuffix.ReplaceAllString(functionName(fn), "")
+	return strings.TrimPrefix(name, "goapi.(*GoAPI).")
+}
+
+// functionLocation returns the file:line where a function is defined, relative to the working directory
+func functionLocation(fn interface{}) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return ""
+	}
+	function := runtime.FuncForPC(value.Pointer())
+	if function == nil {
+		return ""
+	}
+	file, line := function.FileLine(function.Entry())
+	if file == "<autogenerated>" {
+		// Method values are wrapped by the compiler; the wrapper has no useful location
+		return ""
+	}
+	if workingDirectory, err := os.Getwd(); err == nil {
+		if relative, err := filepath.Rel(workingDirectory, file); err == nil && !strings.HasPrefix(relative, "..") {
+			file = relative
+		}
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// orDash returns "-" for empty table cells
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,101 @@
+package goapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+)
+
+// builtinSchemaTypes documents well-known types by their package path and name, so the
+// packages defining them need not be dependencies
+var builtinSchemaTypes = map[string]openapi.Schema{
+	"time.Time":                             {Type: "string", Format: "date-time"},
+	"time.Duration":                         {Type: "integer", Format: "int64", Description: "Duration in nanoseconds"},
+	"math/big.Int":                          {Type: "integer"},
+	"net/netip.Addr":                        {Type: "string", Format: "ip"},
+	"github.com/google/uuid.UUID":           {Type: "string", Format: "uuid"},
+	"github.com/gofrs/uuid.UUID":            {Type: "string", Format: "uuid"},
+	"github.com/gofrs/uuid/v5.UUID":         {Type: "string", Format: "uuid"},
+	"github.com/satori/go.uuid.UUID":        {Type: "string", Format: "uuid"},
+	"github.com/shopspring/decimal.Decimal": {Type: "string", Format: "decimal", Pattern: `^-?\d+(\.\d+)?$`},
+	"github.com/cockroachdb/apd/v3.Decimal": {Type: "string", Format: "decimal", Pattern: `^-?\d+(\.\d+)?$`},
+	"github.com/jackc/pgx/v5/pgtype.UUID":   {Type: "string", Format: "uuid"},
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// RegisterSchemaType documents every field of a type with a schema, replacing the one derived
+// from its Go kind. It describes types with custom JSON encodings, such as money amounts sent
+// as strings or IDs wrapping a struct
+//
+//	api.RegisterSchemaType(reflect.TypeOf(Money{}), &openapi.Schema{Type: "string", Pattern: `^\d+\.\d{2}$`})
+//
+// Built-in mappings cover time.Time, time.Duration, UUIDs (google, gofrs) and decimals
+// (shopspring, apd); other types implementing encoding.TextMarshaler are documented as strings.
+// Register types before building the specification
+func (apiInstance *GoAPI) RegisterSchemaType(t reflect.Type, schema *openapi.Schema) {
+	if apiInstance.schemaTypes == nil {
+		apiInstance.schemaTypes = make(map[reflect.Type]*openapi.Schema)
+	}
+	apiInstance.schemaTypes[t] = schema
+}
+
+// RegisterEnum documents a named type as an enumeration of its values, in the given order
+//
+//	type Status string
+//	api.RegisterEnum(StatusActive, StatusSuspended, StatusClosed)
+//
+// The schema uses the JSON encoding of the values; RegisterEnum panics when they have different types
+func (apiInstance *GoAPI) RegisterEnum(values ...interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	t := reflect.TypeOf(values[0])
+	enum := make([]interface{}, len(values))
+	for i, value := range values {
+		if reflect.TypeOf(value) != t {
+			panic(fmt.Sprintf("goapi: RegisterEnum values of different types (%s and %T)", t, value))
+		}
+		enum[i] = jsonValue(value)
+	}
+	// El tipo es el de la codificación JSON: los enteros con MarshalText se envían como texto
+	schema := &openapi.Schema{Type: "string", Enum: enum}
+	switch enum[0].(type) {
+	case bool:
+		schema.Type = "boolean"
+	case float64:
+		schema.Type = "number"
+		if kind := t.Kind(); kind >= reflect.Int && kind <= reflect.Uint64 {
+			schema.Type = "integer"
+		}
+	}
+	apiInstance.RegisterSchemaType(t, schema)
+}
+
+// typeSchema returns a copy of the schema documenting a type: the registered one, a built-in
+// mapping or a string for text marshalers. Types encoded as JSON by themselves keep the schema
+// of their kind, as it cannot be predicted
+func (apiInstance *GoAPI) typeSchema(t reflect.Type) (*openapi.Schema, bool) {
+	if schema, registered := apiInstance.schemaTypes[t]; registered && schema != nil {
+		copied := *schema
+		return &copied, true
+	}
+	if schema, builtin := builtinSchemaTypes[t.PkgPath()+"."+t.Name()]; builtin && t.Name() != "" {
+		return &schema, true
+	}
+	if t.Kind() != reflect.Interface && !implements(t, jsonMarshalerType) && implements(t, textMarshalerType) {
+		return &openapi.Schema{Type: "string"}, true
+	}
+	return nil, false
+}
+
+// implements reports whether values of a type, or pointers to them, implement an interface
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || (t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(iface))
+}
@@ -0,0 +1,157 @@
+// Package securecookie signs and optionally encrypts cookie values
+// Values are authenticated with HMAC-SHA256 over the cookie name, so a valid value cannot be moved to
+// another cookie, and encrypted with AES-GCM when a block key is set. Keys can be rotated: the first
+// one encodes new values and all of them decode existing ones
+package securecookie
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Errors returned when decoding
+var (
+	// ErrInvalid is returned for values that are malformed, were tampered with or use an unknown key
+	ErrInvalid = errors.New("invalid cookie value")
+	// ErrExpired is returned for values older than the maximum age
+	ErrExpired = errors.New("expired cookie value")
+)
+
+// MinHashKeySize is the minimum size of the HMAC keys
+const MinHashKeySize = 32
+
+// Key is a signing key with an optional encryption key
+type Key struct {
+	HashKey  []byte // HMAC-SHA256 key, at least 32 bytes
+	BlockKey []byte // AES key of 16, 24 or 32 bytes; nil signs values without encrypting them
+}
+
+// Codec encodes and decodes cookie values
+type Codec struct {
+	keys []codecKey
+}
+
+// codecKey is a key ready to use
+type codecKey struct {
+	hash []byte
+	aead cipher.AEAD
+}
+
+// New creates a codec; the first key encodes values, the following ones only decode older values
+func New(keys ...Key) (*Codec, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("securecookie: at least one key is required")
+	}
+	codec := &Codec{}
+	for i, key := range keys {
+		if len(key.HashKey) < MinHashKeySize {
+			return nil, fmt.Errorf("securecookie: hash key %d must be at least %d bytes", i, MinHashKeySize)
+		}
+		prepared := codecKey{hash: key.HashKey}
+		if key.BlockKey != nil {
+			block, err := aes.NewCipher(key.BlockKey)
+			if err != nil {
+				return nil, fmt.Errorf("securecookie: block key %d: %w", i, err)
+			}
+			if prepared.aead, err = cipher.NewGCM(block); err != nil {
+				return nil, fmt.Errorf("securecookie: block key %d: %w", i, err)
+			}
+		}
+		codec.keys = append(codec.keys, prepared)
+	}
+	return codec, nil
+}
+
+// GenerateKey returns a random key of the given size, for HashKey (32) or BlockKey (32)
+func GenerateKey(size int) []byte {
+	key := make([]byte, size)
+	_, _ = rand.Read(key)
+	return key
+}
+
+// Encode signs, and encrypts when the current key has a block key, the value of a cookie
+// The result is URL safe: base64(timestamp || payload) "." base64(mac)
+func (codec *Codec) Encode(name string, value []byte) (string, error) {
+	key := codec.keys[0]
+
+	body := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(body, uint64(time.Now().Unix()))
+	body = append(body, value...)
+
+	if key.aead != nil {
+		nonce := make([]byte, key.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		body = key.aead.Seal(nonce, nonce, body, []byte(name))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(body) + "." +
+		base64.RawURLEncoding.EncodeToString(key.sign(name, body)), nil
+}
+
+// Decode verifies and decrypts the value of a cookie
+// Values encoded more than maxAge ago fail with ErrExpired; 0 accepts any age
+func (codec *Codec) Decode(name, encoded string, maxAge time.Duration) ([]byte, error) {
+	encodedBody, encodedMAC, found := strings.Cut(encoded, ".")
+	if !found {
+		return nil, ErrInvalid
+	}
+	body, err := base64.RawURLEncoding.DecodeString(encodedBody)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	for _, key := range codec.keys {
+		if !hmac.Equal(mac, key.sign(name, body)) {
+			continue
+		}
+		plain := body
+		if key.aead != nil {
+			size := key.aead.NonceSize()
+			if len(body) < size {
+				return nil, ErrInvalid
+			}
+			if plain, err = key.aead.Open(nil, body[:size], body[size:], []byte(name)); err != nil {
+				return nil, ErrInvalid
+			}
+		}
+		if len(plain) < 8 {
+			return nil, ErrInvalid
+		}
+		created := time.Unix(int64(binary.BigEndian.Uint64(plain[:8])), 0)
+		if maxAge > 0 && time.Since(created) > maxAge {
+			return nil, ErrExpired
+		}
+		return plain[8:], nil
+	}
+	return nil, ErrInvalid
+}
+
+// sign computes the MAC of a value bound to the cookie name and to whether it is encrypted, so a
+// signed-only codec never accepts an encrypted value as plain text
+func (key codecKey) sign(name string, body []byte) []byte {
+	mode := byte('s')
+	if key.aead != nil {
+		mode = 'e'
+	}
+	mac := hmac.New(sha256.New, key.hash)
+	mac.Write([]byte{mode})
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write(body)
+	return mac.Sum(nil)
+}
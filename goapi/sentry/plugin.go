@@ -0,0 +1,34 @@
+package sentry
+
+import (
+	"context"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+)
+
+// Plugin reports the errors of a GoAPI instance with a Reporter
+// It registers the reporter with api.OnError and, on shutdown, waits for the queued events
+type Plugin struct {
+	Reporter *Reporter
+}
+
+// NewPlugin creates a plugin for a reporter
+func NewPlugin(reporter *Reporter) *Plugin {
+	return &Plugin{Reporter: reporter}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "sentry"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.OnError(p.Reporter.Report)
+	return nil
+}
+
+// OnShutdown implements goapi.ShutdownPlugin
+func (p *Plugin) OnShutdown(ctx context.Context) error {
+	return p.Reporter.Flush(ctx)
+}
@@ -0,0 +1,296 @@
+// Package sentry reports the panics and 5xx responses of a GoAPI instance to Sentry
+// It talks to the envelope endpoint of the project given by the DSN, so GoAPI does not depend on
+// the Sentry SDK; events carry the request, the route, the request ID and the stack of panics
+//
+//	reporter, err := sentry.New(os.Getenv("SENTRY_DSN"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	reporter.Environment = "production"
+//	api.UsePlugin(sentry.NewPlugin(reporter))
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+)
+
+// sensitiveHeaders are sent to Sentry as "[Filtered]"
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+	"X-Csrf-Token":        true,
+}
+
+// Event is a Sentry event; BeforeSend can change it before it is sent
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"` // "fatal" for panics, "error" otherwise
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger,omitempty"`
+	Transaction string            `json:"transaction,omitempty"` // Method and route: "GET /users/:id"
+	ServerName  string            `json:"server_name,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	User        *User             `json:"user,omitempty"`
+	Request     *Request          `json:"request,omitempty"`
+	Exception   struct {
+		Values []Exception `json:"values"`
+	} `json:"exception"`
+}
+
+// User identifies the user of a request
+type User struct {
+	ID        string `json:"id,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+}
+
+// Request describes the request that failed
+type Request struct {
+	URL         string            `json:"url"`
+	Method      string            `json:"method"`
+	QueryString string            `json:"query_string,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+// Exception is an error of an event, with its stack
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Mechanism  *Mechanism  `json:"mechanism,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Mechanism tells how an exception was captured
+type Mechanism struct {
+	Type    string `json:"type"`
+	Handled bool   `json:"handled"`
+}
+
+// Stacktrace is a stack, the oldest frame first
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a function call of a stack
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Reporter sends events to a Sentry project
+// Events are sent in the background; Flush waits for the queued ones
+type Reporter struct {
+	Environment string
+	Release     string
+	ServerName  string // Default: the host name
+	// BeforeSend changes an event, or drops it by returning false; it runs on the request goroutine
+	BeforeSend func(c *gin.Context, event *Event) bool
+	Client     *http.Client
+
+	endpoint string
+	auth     string
+	queue    chan []byte
+	pending  sync.WaitGroup
+	once     sync.Once
+}
+
+// New creates a reporter for a DSN: https://<key>@<host>/<project>
+func New(dsn string) (*Reporter, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Sentry DSN: %w", err)
+	}
+	project := strings.TrimPrefix(parsed.Path, "/")
+	if parsed.User == nil || parsed.User.Username() == "" || parsed.Host == "" || project == "" {
+		return nil, errors.New("invalid Sentry DSN: expected https://<key>@<host>/<project>")
+	}
+	path := ""
+	// Las instalaciones propias pueden servir Sentry bajo una ruta: https://key@host/sentry/42
+	if slash := strings.LastIndex(project, "/"); slash >= 0 {
+		path, project = "/"+project[:slash], project[slash+1:]
+	}
+	serverName, _ := os.Hostname()
+	return &Reporter{
+		ServerName: serverName,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		endpoint:   parsed.Scheme + "://" + parsed.Host + path + "/api/" + project + "/envelope/",
+		auth:       "Sentry sentry_version=7, sentry_client=goapi, sentry_key=" + parsed.User.Username(),
+		queue:      make(chan []byte, 100),
+	}, nil
+}
+
+// Report implements middleware.ErrorReporter
+// Events are dropped, and logged, when 100 of them are already waiting to be sent
+func (r *Reporter) Report(c *gin.Context, err error, stack []byte) {
+	event := r.event(c, err, stack)
+	if r.BeforeSend != nil && !r.BeforeSend(c, event) {
+		return
+	}
+	envelope, encodeErr := encodeEnvelope(event)
+	if encodeErr != nil {
+		log.Printf("[sentry] error encoding event: %v", encodeErr)
+		return
+	}
+
+	r.once.Do(func() { go r.work() })
+	r.pending.Add(1)
+	select {
+	case r.queue <- envelope:
+	default:
+		r.pending.Done()
+		log.Printf("[sentry] queue full, dropping event %s: %v", event.EventID, err)
+	}
+}
+
+// Flush waits until the queued events are sent or ctx is done
+func (r *Reporter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work sends the queued events
+func (r *Reporter) work() {
+	for envelope := range r.queue {
+		if err := r.send(envelope); err != nil {
+			log.Printf("[sentry] %v", err)
+		}
+		r.pending.Done()
+	}
+}
+
+// send posts an envelope to Sentry
+func (r *Reporter) send(envelope []byte) error {
+	request, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-sentry-envelope")
+	request.Header.Set("X-Sentry-Auth", r.auth)
+	response, err := r.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending event: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("Sentry returned %d: %s", response.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// event builds the event of a failed request
+// It reads everything it needs from c now: the context is reused once the request ends
+func (r *Reporter) event(c *gin.Context, err error, stack []byte) *Event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	event := &Event{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   time.Now().UTC(),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "goapi",
+		ServerName:  r.ServerName,
+		Environment: r.Environment,
+		Release:     r.Release,
+		Tags:        map[string]string{},
+		User:        &User{ID: c.GetString("user_id"), IPAddress: c.ClientIP()},
+	}
+	if route := c.FullPath(); route != "" {
+		event.Transaction = c.Request.Method + " " + route
+	}
+	if requestID := c.GetString(responses.RequestIDKey); requestID != "" {
+		event.Tags["request_id"] = requestID
+	}
+	if status := c.Writer.Status(); status >= http.StatusInternalServerError {
+		event.Tags["status_code"] = fmt.Sprint(status)
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	headers := make(map[string]string, len(c.Request.Header))
+	for name, values := range c.Request.Header {
+		headers[name] = strings.Join(values, ", ")
+		if sensitiveHeaders[name] {
+			headers[name] = "[Filtered]"
+		}
+	}
+	event.Request = &Request{
+		URL:         scheme + "://" + c.Request.Host + c.Request.URL.Path,
+		Method:      c.Request.Method,
+		QueryString: c.Request.URL.RawQuery,
+		Headers:     headers,
+	}
+
+	exception := Exception{Type: fmt.Sprintf("%T", err), Value: err.Error()}
+	var panicked *middleware.PanicError
+	if errors.As(err, &panicked) {
+		event.Level = "fatal"
+		exception.Type = "panic"
+		if cause := panicked.Unwrap(); cause != nil {
+			exception.Type = fmt.Sprintf("%T", cause)
+		}
+		exception.Value = fmt.Sprint(panicked.Value)
+		exception.Mechanism = &Mechanism{Type: "goapi.recovery", Handled: false}
+	}
+	if frames := parseStack(stack); len(frames) > 0 {
+		exception.Stacktrace = &Stacktrace{Frames: frames}
+	}
+	event.Exception.Values = []Exception{exception}
+	return event
+}
+
+// encodeEnvelope encodes an event as a Sentry envelope: a header, an item header and the event
+func encodeEnvelope(event *Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	header, _ := json.Marshal(map[string]interface{}{"event_id": event.EventID, "sent_at": time.Now().UTC()})
+	item, _ := json.Marshal(map[string]interface{}{"type": "event", "length": len(payload)})
+	buffer.Write(header)
+	buffer.WriteByte('\n')
+	buffer.Write(item)
+	buffer.WriteByte('\n')
+	buffer.Write(payload)
+	buffer.WriteByte('\n')
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,41 @@
+package sentry
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+)
+
+// goapiModule is the package path prefix of the framework, whose frames are not in the app
+const goapiModule = "github.com/esteban-ll-aguilar/goapi/goapi"
+
+// parseStack converts the output of debug.Stack into Sentry frames, the oldest first
+func parseStack(stack []byte) []Frame {
+	parsed := middleware.ParseStack(stack)
+	frames := make([]Frame, len(parsed))
+	for i, frame := range parsed {
+		module, name := splitFunction(frame.Function)
+		// debug.Stack lista primero la llamada más reciente; Sentry espera la más antigua
+		frames[len(parsed)-1-i] = Frame{
+			Function: name,
+			Module:   module,
+			Filename: filepath.Base(frame.File),
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    !strings.HasPrefix(module, "github.com/gin-gonic/") && !strings.HasPrefix(module, "net/") && !strings.HasPrefix(module, goapiModule),
+		}
+	}
+	return frames
+}
+
+// splitFunction splits "github.com/acme/app/users.(*Handler).Get" into the package path and
+// the function name
+func splitFunction(function string) (string, string) {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return "", function
+	}
+	return function[:slash+1+dot], function[slash+2+dot:]
+}
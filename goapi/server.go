@@ -0,0 +1,154 @@
+package goapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ServerConfig configures the servers created by Run, RunTLS, RunAutoTLS and RunListeners
+// Zero durations disable the corresponding timeout, as in net/http
+type ServerConfig struct {
+	ReadTimeout       time.Duration // Reading the whole request, body included
+	ReadHeaderTimeout time.Duration // Reading the request headers
+	WriteTimeout      time.Duration // Writing the response; keep it 0 for SSE and streaming downloads
+	IdleTimeout       time.Duration // Keep-alive connections waiting for the next request
+	MaxHeaderBytes    int           // Request header size limit (0 = http.DefaultMaxHeaderBytes, 1 MB)
+	H2C               bool          // Accept HTTP/2 without TLS (h2c), e.g. behind a proxy terminating TLS
+}
+
+// DefaultServerConfig returns server settings that protect against slow clients
+// without cutting long-lived responses
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+}
+
+// AutoTLSConfig configures certificates obtained automatically from Let's Encrypt
+type AutoTLSConfig struct {
+	Hosts    []string // Host names certificates are requested for; other hosts are refused
+	Email    string   // Contact address for the ACME account (optional)
+	CacheDir string   // Directory where certificates are stored between restarts (default "certs")
+	Addr     string   // HTTPS address (default ":443")
+	HTTPAddr string   // HTTP address answering the http-01 challenge and redirecting to HTTPS (default ":80")
+	NoHTTP   bool     // Do not listen on HTTPAddr; challenges are then answered with tls-alpn-01 only
+}
+
+// RunTLS runs the server over HTTPS with a certificate and key in PEM files
+// HTTP/2 is negotiated automatically; the call returns nil after a graceful Shutdown
+func (a *GoAPI) RunTLS(addr, certFile, keyFile string) error {
+	server := a.newServer(addr)
+	return a.serve(server, "https", func() error {
+		return server.ListenAndServeTLS(certFile, keyFile)
+	})
+}
+
+// RunAutoTLS runs the server over HTTPS with certificates from Let's Encrypt, renewed automatically
+// Only the whitelisted hosts get certificates, so random SNI names cannot exhaust the ACME rate limits
+func (a *GoAPI) RunAutoTLS(config AutoTLSConfig) error {
+	if len(config.Hosts) == 0 {
+		return errors.New("autotls: at least one host is required")
+	}
+	if config.CacheDir == "" {
+		config.CacheDir = "certs"
+	}
+	if config.Addr == "" {
+		config.Addr = ":443"
+	}
+	if config.HTTPAddr == "" {
+		config.HTTPAddr = ":80"
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(config.Hosts...),
+		Cache:      autocert.DirCache(config.CacheDir),
+		Email:      config.Email,
+	}
+
+	server := a.newServer(config.Addr)
+	server.TLSConfig = manager.TLSConfig()
+
+	if !config.NoHTTP {
+		// El servidor HTTP responde el desafío http-01 y redirige el resto a HTTPS
+		challenge := a.newServer(config.HTTPAddr)
+		challenge.Handler = manager.HTTPHandler(nil)
+		go func() {
+			if err := challenge.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("[GoAPI] autotls HTTP server: %v", err)
+			}
+		}()
+		a.trackServer(challenge)
+	}
+
+	return a.serve(server, "https", func() error {
+		return server.ListenAndServeTLS("", "")
+	})
+}
+
+// newServer creates an http.Server for the API with the configured timeouts
+func (a *GoAPI) newServer(addr string) *http.Server {
+	config := a.config.Server
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           a,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+		ConnState:         a.trackConnections,
+	}
+	if config.H2C {
+		server.Protocols = new(http.Protocols)
+		server.Protocols.SetHTTP1(true)
+		server.Protocols.SetHTTP2(true)
+		server.Protocols.SetUnencryptedHTTP2(true)
+	}
+	return server
+}
+
+// serve runs the startup hooks, tracks the server for Shutdown and blocks in listen
+func (a *GoAPI) serve(server *http.Server, scheme string, listen func() error) error {
+	// Configure routes
+	a.SetupRoutes()
+
+	if err := a.Startup(context.Background()); err != nil {
+		return err
+	}
+
+	if scheme == "unix" {
+		log.Println("Server started at unix:" + server.Addr)
+	} else {
+		host := server.Addr
+		if strings.HasPrefix(host, ":") {
+			host = "localhost" + host
+		}
+		base := fmt.Sprintf("%s://%s", scheme, host)
+		log.Println("Server started at " + base)
+		log.Println("Documentation available at:")
+		log.Println("- Swagger UI: " + base + "/docs")
+		log.Println("- ReDoc: " + base + "/redoc")
+	}
+
+	a.trackServer(server)
+	if err := listen(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// trackServer registers a server stopped by Shutdown
+func (a *GoAPI) trackServer(server *http.Server) {
+	a.serverMutex.Lock()
+	a.servers = append(a.servers, server)
+	a.serverMutex.Unlock()
+}
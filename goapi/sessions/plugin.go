@@ -0,0 +1,40 @@
+package sessions
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+)
+
+// Plugin wires a Manager into a GoAPI instance
+// It installs the sessions middleware globally and registers *Session as a dependency, resolved
+// to the session of the request being handled. *dependencies.CurrentUser also resolves to the
+// user signed in with Login when the request carries no token
+type Plugin struct {
+	Manager *Manager
+}
+
+// NewPlugin creates a plugin for a session manager
+func NewPlugin(manager *Manager) *Plugin {
+	return &Plugin{Manager: manager}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "sessions"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.AddMiddleware(p.Manager.Middleware())
+	api.RegisterDependency(func(c *gin.Context) (interface{}, error) {
+		session := Get(c)
+		if session == nil {
+			return nil, ErrNoSession
+		}
+		return session, nil
+	}, (*Session)(nil))
+	api.RegisterDependency(dependencies.CurrentUserProvider(UserSource()), (*dependencies.CurrentUser)(nil))
+	return nil
+}
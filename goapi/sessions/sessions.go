@@ -0,0 +1,417 @@
+// Package sessions provides cookie based sessions for GoAPI
+// Session data lives in a signed (and optionally encrypted) cookie, or in a server-side Store with
+// the cookie carrying only the signed session ID. Sessions expire after a period of inactivity and
+// after an absolute lifetime, whichever comes first
+package sessions
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/securecookie"
+)
+
+// contextKey is the gin context key holding the session state of a request
+const contextKey = "goapi.session"
+
+// maxCookieSize is the size browsers accept for a cookie
+const maxCookieSize = 4096
+
+// ErrNoSession is returned by the dependency provider when the sessions middleware did not run
+var ErrNoSession = errors.New("sessions middleware is not installed")
+
+// Config configures the sessions
+type Config struct {
+	Keys            []securecookie.Key // The first key signs new cookies; the others still validate older ones
+	Store           Store              // Server-side store; nil keeps the data in the cookie (4 KB at most)
+	CookieName      string             // Default "session"
+	IdleTimeout     time.Duration      // Inactivity after which the session expires (default 30 minutes)
+	AbsoluteTimeout time.Duration      // Lifetime of a session, even when active (default 24 hours)
+	Path            string             // Cookie path (default "/")
+	Domain          string             // Cookie domain (default: the host of the request)
+	SameSite        http.SameSite      // Default http.SameSiteLaxMode
+	Insecure        bool               // Send the cookie over plain HTTP, for local development only
+}
+
+// Manager loads and saves the sessions of the requests
+type Manager struct {
+	config Config
+	codec  *securecookie.Codec
+}
+
+// New creates a session manager
+func New(config Config) (*Manager, error) {
+	codec, err := securecookie.New(config.Keys...)
+	if err != nil {
+		return nil, err
+	}
+	if config.CookieName == "" {
+		config.CookieName = "session"
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 30 * time.Minute
+	}
+	if config.AbsoluteTimeout <= 0 {
+		config.AbsoluteTimeout = 24 * time.Hour
+	}
+	if config.Path == "" {
+		config.Path = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	return &Manager{config: config, codec: codec}, nil
+}
+
+// Session is the data of a user across requests
+// Values are stored as JSON, so read them back with Get or Value into the type they were set with
+type Session struct {
+	mutex       sync.Mutex
+	id          string
+	values      map[string]json.RawMessage
+	createdAt   time.Time
+	accessedAt  time.Time
+	isNew       bool
+	modified    bool
+	destroyed   bool
+	previousIDs []string // IDs replaced by Regenerate, deleted from the store on save
+}
+
+// record is the stored form of a session
+type record struct {
+	ID         string                     `json:"id"`
+	Values     map[string]json.RawMessage `json:"values"`
+	CreatedAt  time.Time                  `json:"created_at"`
+	AccessedAt time.Time                  `json:"accessed_at"`
+}
+
+// ID returns the identifier of the session
+func (s *Session) ID() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.id
+}
+
+// IsNew reports whether the session was created by this request
+func (s *Session) IsNew() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isNew
+}
+
+// CreatedAt returns when the session was created
+func (s *Session) CreatedAt() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.createdAt
+}
+
+// Get decodes a value into target, reporting whether it was present and decodable
+func (s *Session) Get(key string, target interface{}) bool {
+	s.mutex.Lock()
+	data, found := s.values[key]
+	s.mutex.Unlock()
+	return found && json.Unmarshal(data, target) == nil
+}
+
+// Set stores a value, which must be JSON encodable
+func (s *Session) Set(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values[key] = data
+	s.modified = true
+	return nil
+}
+
+// Delete removes a value
+func (s *Session) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, found := s.values[key]; found {
+		delete(s.values, key)
+		s.modified = true
+	}
+}
+
+// Clear removes every value, keeping the session
+func (s *Session) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values = make(map[string]json.RawMessage)
+	s.modified = true
+}
+
+// Regenerate gives the session a new ID, keeping its values
+// Call it when the privileges change (login, logout) to prevent session fixation
+func (s *Session) Regenerate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.isNew {
+		s.previousIDs = append(s.previousIDs, s.id)
+	}
+	s.id = newSessionID()
+	s.modified = true
+}
+
+// Destroy deletes the session and its cookie at the end of the request
+func (s *Session) Destroy() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.destroyed = true
+}
+
+// Value returns a session value decoded as T
+func Value[T any](s *Session, key string) (T, bool) {
+	var value T
+	found := s.Get(key, &value)
+	return value, found
+}
+
+// Get returns the session of the request, loading it on first use (nil when the middleware did not run)
+func Get(c *gin.Context) *Session {
+	value, _ := c.Get(contextKey)
+	state, ok := value.(*requestState)
+	if !ok {
+		return nil
+	}
+	return state.load()
+}
+
+// Middleware makes the session of each request available through Get
+// The session is loaded when first used and saved, when it changed, before the response is written
+func (m *Manager) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := &requestState{manager: m, c: c}
+		c.Set(contextKey, state)
+		c.Writer = &sessionWriter{ResponseWriter: c.Writer, state: state}
+		c.Next()
+		// Responses without a body (204, redirects written by gin) have not been flushed yet
+		state.commit()
+	}
+}
+
+// requestState is the session of a request
+type requestState struct {
+	manager   *Manager
+	c         *gin.Context
+	once      sync.Once
+	session   *Session
+	committed bool
+	mutex     sync.Mutex
+}
+
+// load reads the session of the request, or starts a new one
+func (state *requestState) load() *Session {
+	state.once.Do(func() {
+		state.session = state.manager.load(state.c)
+	})
+	return state.session
+}
+
+// commit saves the session once, if it was used
+func (state *requestState) commit() {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.committed {
+		return
+	}
+	state.committed = true
+	if state.session != nil {
+		state.manager.save(state.c, state.session)
+	}
+}
+
+// load reads the session from the cookie and the store
+func (m *Manager) load(c *gin.Context) *Session {
+	cookie, err := c.Cookie(m.config.CookieName)
+	if err != nil || cookie == "" {
+		return m.newSession()
+	}
+	data, err := m.codec.Decode(m.config.CookieName, cookie, m.config.AbsoluteTimeout)
+	if err != nil {
+		return m.newSession()
+	}
+
+	if m.config.Store != nil {
+		id := string(data)
+		if data, err = m.config.Store.Get(c.Request.Context(), id); err != nil {
+			log.Printf("[GoAPI] error loading session: %v", err)
+			return m.newSession()
+		}
+		if data == nil {
+			return m.newSession()
+		}
+	}
+
+	var stored record
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return m.newSession()
+	}
+	now := time.Now()
+	if now.Sub(stored.AccessedAt) > m.config.IdleTimeout || now.Sub(stored.CreatedAt) > m.config.AbsoluteTimeout {
+		if m.config.Store != nil {
+			_ = m.config.Store.Delete(c.Request.Context(), stored.ID)
+		}
+		return m.newSession()
+	}
+	if stored.Values == nil {
+		stored.Values = make(map[string]json.RawMessage)
+	}
+	return &Session{
+		id:         stored.ID,
+		values:     stored.Values,
+		createdAt:  stored.CreatedAt,
+		accessedAt: stored.AccessedAt,
+	}
+}
+
+// newSession starts an empty session
+func (m *Manager) newSession() *Session {
+	now := time.Now()
+	return &Session{
+		id:         newSessionID(),
+		values:     make(map[string]json.RawMessage),
+		createdAt:  now,
+		accessedAt: now,
+		isNew:      true,
+	}
+}
+
+// save writes the session and its cookie when it changed or its idle period must be extended
+func (m *Manager) save(c *gin.Context, session *Session) {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+	ctx := c.Request.Context()
+
+	if m.config.Store != nil {
+		for _, id := range session.previousIDs {
+			if err := m.config.Store.Delete(ctx, id); err != nil {
+				log.Printf("[GoAPI] error deleting session: %v", err)
+			}
+		}
+	}
+	if session.destroyed {
+		if m.config.Store != nil && !session.isNew {
+			if err := m.config.Store.Delete(ctx, session.id); err != nil {
+				log.Printf("[GoAPI] error deleting session: %v", err)
+			}
+		}
+		if !session.isNew || len(session.previousIDs) > 0 {
+			m.setCookie(c, "", time.Time{})
+		}
+		return
+	}
+
+	now := time.Now()
+	// Active sessions are touched at most every tenth of the idle timeout
+	touch := now.Sub(session.accessedAt) > m.config.IdleTimeout/10
+	if !session.modified && !touch {
+		return
+	}
+	if session.isNew && len(session.values) == 0 {
+		// Nothing worth a cookie yet
+		return
+	}
+	session.accessedAt = now
+
+	data, err := json.Marshal(record{
+		ID:         session.id,
+		Values:     session.values,
+		CreatedAt:  session.createdAt,
+		AccessedAt: session.accessedAt,
+	})
+	if err != nil {
+		log.Printf("[GoAPI] error encoding session: %v", err)
+		return
+	}
+
+	expires := now.Add(m.config.IdleTimeout)
+	if absolute := session.createdAt.Add(m.config.AbsoluteTimeout); absolute.Before(expires) {
+		expires = absolute
+	}
+	if m.config.Store != nil {
+		if err := m.config.Store.Set(ctx, session.id, data, time.Until(expires)); err != nil {
+			log.Printf("[GoAPI] error saving session: %v", err)
+			return
+		}
+		data = []byte(session.id)
+	}
+
+	value, err := m.codec.Encode(m.config.CookieName, data)
+	if err != nil {
+		log.Printf("[GoAPI] error encoding session cookie: %v", err)
+		return
+	}
+	if len(value) > maxCookieSize {
+		log.Printf("[GoAPI] session of %d bytes does not fit in a cookie; use a Store", len(value))
+		return
+	}
+	m.setCookie(c, value, expires)
+}
+
+// setCookie writes the session cookie; an empty value deletes it
+func (m *Manager) setCookie(c *gin.Context, value string, expires time.Time) {
+	cookie := &http.Cookie{
+		Name:     m.config.CookieName,
+		Value:    value,
+		Path:     m.config.Path,
+		Domain:   m.config.Domain,
+		Expires:  expires,
+		Secure:   !m.config.Insecure,
+		HttpOnly: true,
+		SameSite: m.config.SameSite,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// newSessionID returns a random session identifier
+func newSessionID() string {
+	id := make([]byte, 32)
+	_, _ = rand.Read(id)
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+// sessionWriter saves the session right before the response headers are sent
+type sessionWriter struct {
+	gin.ResponseWriter
+	state *requestState
+}
+
+// Write implements http.ResponseWriter
+func (w *sessionWriter) Write(data []byte) (int, error) {
+	w.state.commit()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements gin.ResponseWriter
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.state.commit()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// WriteHeaderNow implements gin.ResponseWriter
+func (w *sessionWriter) WriteHeaderNow() {
+	w.state.commit()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush implements http.Flusher
+func (w *sessionWriter) Flush() {
+	w.state.commit()
+	w.ResponseWriter.Flush()
+}
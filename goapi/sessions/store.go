@@ -0,0 +1,129 @@
+package sessions
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps session data on the server; the cookie then carries only the signed session ID
+type Store interface {
+	// Get returns the data of a session, or nil without error when it does not exist or expired
+	Get(ctx context.Context, id string) ([]byte, error)
+	// Set saves the data of a session for ttl
+	Set(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	// Delete removes a session
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore keeps sessions in process memory
+// Sessions are lost on restart and not shared between instances; use it for development and single
+// instance deployments
+type MemoryStore struct {
+	mutex     sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// memoryEntry is a stored session
+type memoryEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryStore creates an in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(_ context.Context, id string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, found := s.entries[id]
+	if !found || time.Now().After(entry.expires) {
+		return nil, nil
+	}
+	return entry.data, nil
+}
+
+// Set implements Store
+func (s *MemoryStore) Set(_ context.Context, id string, data []byte, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	s.entries[id] = memoryEntry{data: data, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.entries, id)
+	return nil
+}
+
+// sweep removes expired sessions, at most once a minute; the caller holds the mutex
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for id, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, id)
+		}
+	}
+}
+
+// RedisClient is the part of a Redis client used by RedisStore
+// GoAPI does not depend on a Redis driver; wrap the one of the application, for go-redis:
+//
+//	type redisClient struct{ *redis.Client }
+//
+//	func (r redisClient) Get(ctx context.Context, key string) ([]byte, error) {
+//		data, err := r.Client.Get(ctx, key).Bytes()
+//		if errors.Is(err, redis.Nil) {
+//			return nil, nil
+//		}
+//		return data, err
+//	}
+//	func (r redisClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+//		return r.Client.Set(ctx, key, value, ttl).Err()
+//	}
+//	func (r redisClient) Del(ctx context.Context, key string) error {
+//		return r.Client.Del(ctx, key).Err()
+//	}
+type RedisClient interface {
+	Get(ctx context.Context, key string) ([]byte, error) // nil without error for missing keys
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// RedisStore keeps sessions in Redis, shared by every instance and expired by Redis itself
+type RedisStore struct {
+	Client RedisClient
+	Prefix string // Key prefix (default "session:")
+}
+
+// NewRedisStore creates a store using a Redis client
+func NewRedisStore(client RedisClient) *RedisStore {
+	return &RedisStore{Client: client, Prefix: "session:"}
+}
+
+// Get implements Store
+func (s *RedisStore) Get(ctx context.Context, id string) ([]byte, error) {
+	return s.Client.Get(ctx, s.Prefix+id)
+}
+
+// Set implements Store
+func (s *RedisStore) Set(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return s.Client.Set(ctx, s.Prefix+id, data, ttl)
+}
+
+// Delete implements Store
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	return s.Client.Del(ctx, s.Prefix+id)
+}
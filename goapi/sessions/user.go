@@ -0,0 +1,50 @@
+package sessions
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/dependencies"
+)
+
+// UserKey is the session value holding the user signed in with Login
+const UserKey = "goapi.user"
+
+// MFAKey is the session value recording the second factor of the signed-in user (see
+// mfa.MarkVerified); Login and Logout remove it
+const MFAKey = "goapi.mfa"
+
+// Login stores the user in the session of the request, giving it a new ID
+// The user is then the *dependencies.CurrentUser of the requests of the session
+func Login(c *gin.Context, user *dependencies.CurrentUser) error {
+	session := Get(c)
+	if session == nil {
+		return ErrNoSession
+	}
+	session.Regenerate()
+	session.Delete(MFAKey)
+	return session.Set(UserKey, user)
+}
+
+// Logout removes the user from the session of the request, giving it a new ID
+func Logout(c *gin.Context) {
+	if session := Get(c); session != nil {
+		session.Delete(UserKey)
+		session.Delete(MFAKey)
+		session.Regenerate()
+	}
+}
+
+// UserSource reads the user signed in with Login, for dependencies.CurrentUserProvider
+func UserSource() dependencies.UserSource {
+	return func(c *gin.Context) (*dependencies.CurrentUser, error) {
+		session := Get(c)
+		if session == nil {
+			return nil, nil
+		}
+		user, found := Value[*dependencies.CurrentUser](session, UserKey)
+		if !found || user == nil {
+			return nil, nil
+		}
+		return user, nil
+	}
+}
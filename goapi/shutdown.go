@@ -0,0 +1,286 @@
+package goapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ShutdownReport summarizes a graceful shutdown, for post-deploy verification
+type ShutdownReport struct {
+	StartedAt   time.Time         `json:"started_at"`
+	DurationMS  int64             `json:"duration_ms"`
+	Requests    RequestsReport    `json:"requests"`
+	Connections ConnectionsReport `json:"connections"`
+	Jobs        JobsReport        `json:"jobs"`
+	Hooks       []HookReport      `json:"hooks"`
+	Errors      []string          `json:"errors,omitempty"`
+	Clean       bool              `json:"clean"`               // Nothing was aborted, dropped or failed
+	Listeners   []string          `json:"listeners,omitempty"` // Addresses that were stopped
+}
+
+// RequestsReport counts the requests being served when the shutdown started
+type RequestsReport struct {
+	InFlight int64 `json:"in_flight"` // Requests being served when the shutdown started
+	Drained  int64 `json:"drained"`   // Of those, requests that completed
+	Aborted  int64 `json:"aborted"`   // Requests still running when connections were closed
+}
+
+// ConnectionsReport counts client connections
+type ConnectionsReport struct {
+	Open           int64 `json:"open"`            // Connections open when the shutdown started
+	ForciblyClosed int64 `json:"forcibly_closed"` // Connections closed after the deadline
+}
+
+// JobsReport counts the background tasks started with Go
+type JobsReport struct {
+	Running   int64 `json:"running"`   // Tasks running when the shutdown started
+	Completed int64 `json:"completed"` // Of those, tasks that finished before the deadline
+	Dropped   int64 `json:"dropped"`   // Tasks cancelled at the deadline, plus tasks refused during shutdown
+}
+
+// HookReport describes the execution of a shutdown hook
+type HookReport struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ShutdownReportHook receives the report of a graceful shutdown
+type ShutdownReportHook func(ctx context.Context, report *ShutdownReport) error
+
+// OnShutdownReport registers a hook receiving the shutdown report after everything has stopped
+// The report is also logged; hooks get a fresh context of 10 seconds, since the shutdown one may be done
+func (a *GoAPI) OnShutdownReport(hook ShutdownReportHook) {
+	a.reportHooks = append(a.reportHooks, hook)
+}
+
+// ShutdownWebhook returns a report hook posting the report as JSON to an admin webhook
+func ShutdownWebhook(url string) ShutdownReportHook {
+	return func(ctx context.Context, report *ShutdownReport) error {
+		body, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "application/json")
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		if response.StatusCode >= 300 {
+			return fmt.Errorf("shutdown webhook answered %d", response.StatusCode)
+		}
+		return nil
+	}
+}
+
+// Go runs a background task tracked by Shutdown, which waits for it until its deadline and then
+// cancels its context; it returns false, without running the task, once the shutdown has started
+func (a *GoAPI) Go(name string, task func(ctx context.Context)) bool {
+	return a.jobs.start(name, task)
+}
+
+// Shutdown gracefully stops the server started by Run and runs the shutdown hooks
+// In-flight requests are drained until the context is done; connections still open at that point
+// are closed. A ShutdownReport is logged and passed to the OnShutdownReport hooks
+func (a *GoAPI) Shutdown(ctx context.Context) error {
+	_, err := a.ShutdownWithReport(ctx)
+	return err
+}
+
+// ShutdownWithReport is Shutdown returning the report
+func (a *GoAPI) ShutdownWithReport(ctx context.Context) (*ShutdownReport, error) {
+	report := &ShutdownReport{StartedAt: time.Now()}
+
+	a.serverMutex.Lock()
+	servers := a.servers
+	a.servers = nil
+	a.serverMutex.Unlock()
+
+	inFlight := a.inFlight.Load()
+	runningJobs, jobsIdle := a.jobs.close()
+	report.Requests.InFlight = inFlight
+	report.Connections.Open = a.connections.Load()
+	for _, server := range servers {
+		report.Listeners = append(report.Listeners, server.Addr)
+	}
+
+	// Los servidores comparten el plazo, así que se detienen a la vez
+	shutdownErrs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shutdownErrs[i] = server.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	if ctx.Err() != nil && len(servers) > 0 {
+		// El plazo terminó: cerrar las conexiones que siguen activas
+		report.Connections.ForciblyClosed = a.connections.Load()
+		for i, server := range servers {
+			shutdownErrs[i] = errors.Join(shutdownErrs[i], server.Close())
+		}
+	}
+	for _, err := range shutdownErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	report.Requests.Aborted = min(a.inFlight.Load(), inFlight)
+	report.Requests.Drained = inFlight - report.Requests.Aborted
+
+	report.Jobs = a.jobs.wait(ctx, jobsIdle)
+	report.Jobs.Running = runningJobs
+
+	hooks, hookErr := a.runShutdownHooks(ctx)
+	report.Hooks = hooks
+	if hookErr != nil {
+		errs = append(errs, hookErr)
+	}
+
+	err := errors.Join(errs...)
+	for _, failure := range errs {
+		report.Errors = append(report.Errors, failure.Error())
+	}
+	report.DurationMS = time.Since(report.StartedAt).Milliseconds()
+	report.Clean = err == nil && report.Requests.Aborted == 0 && report.Connections.ForciblyClosed == 0 && report.Jobs.Dropped == 0
+
+	a.publishShutdownReport(report)
+	return report, err
+}
+
+// publishShutdownReport logs the report and runs the report hooks
+func (a *GoAPI) publishShutdownReport(report *ShutdownReport) {
+	reportContext, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, hook := range a.reportHooks {
+		if err := hook(reportContext, report); err != nil {
+			log.Printf("[GoAPI] shutdown report hook failed: %v", err)
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("[GoAPI] error encoding shutdown report: %v", err)
+		return
+	}
+	log.Printf("[GoAPI] shutdown report: %s", data)
+}
+
+// trackConnections counts the open connections of a server
+func (a *GoAPI) trackConnections(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		a.connections.Add(1)
+	case http.StateHijacked, http.StateClosed:
+		a.connections.Add(-1)
+	}
+}
+
+// backgroundJobs tracks the tasks started with GoAPI.Go
+type backgroundJobs struct {
+	mutex     sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	running   map[*job]bool
+	closing   bool
+	completed int64 // Tasks finished since the shutdown started
+	refused   int64 // Tasks refused during the shutdown
+	idle      chan struct{}
+}
+
+// job is a running background task
+type job struct {
+	name string
+}
+
+// start runs a task unless the shutdown has started
+func (jobs *backgroundJobs) start(name string, task func(ctx context.Context)) bool {
+	jobs.mutex.Lock()
+	if jobs.closing {
+		jobs.refused++
+		jobs.mutex.Unlock()
+		log.Printf("[GoAPI] background task %s refused: shutting down", name)
+		return false
+	}
+	if jobs.ctx == nil {
+		jobs.ctx, jobs.cancel = context.WithCancel(context.Background())
+		jobs.running = make(map[*job]bool)
+	}
+	current := &job{name: name}
+	jobs.running[current] = true
+	ctx := jobs.ctx
+	jobs.mutex.Unlock()
+
+	go func() {
+		defer jobs.finish(current)
+		task(ctx)
+	}()
+	return true
+}
+
+// finish removes a finished task
+func (jobs *backgroundJobs) finish(current *job) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+	delete(jobs.running, current)
+	if jobs.closing {
+		jobs.completed++
+		if len(jobs.running) == 0 && jobs.idle != nil {
+			close(jobs.idle)
+			jobs.idle = nil
+		}
+	}
+}
+
+// close refuses new tasks and returns the number of running ones and a channel closed when they finish
+func (jobs *backgroundJobs) close() (int64, <-chan struct{}) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+	jobs.closing = true
+	idle := make(chan struct{})
+	if len(jobs.running) == 0 {
+		close(idle)
+	} else {
+		jobs.idle = idle
+	}
+	return int64(len(jobs.running)), idle
+}
+
+// wait waits for the running tasks until the context is done and cancels the rest
+func (jobs *backgroundJobs) wait(ctx context.Context, idle <-chan struct{}) JobsReport {
+	select {
+	case <-idle:
+	case <-ctx.Done():
+	}
+
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+	for current := range jobs.running {
+		log.Printf("[GoAPI] background task %s dropped at shutdown", current.name)
+	}
+	if jobs.cancel != nil {
+		jobs.cancel()
+	}
+	jobs.idle = nil
+	return JobsReport{
+		Completed: jobs.completed,
+		Dropped:   int64(len(jobs.running)) + jobs.refused,
+	}
+}
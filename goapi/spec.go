@@ -0,0 +1,1223 @@
+package goapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swaggo/swag"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// DocumentHook is a function that can modify the generated OpenAPI document
+// Hooks run every time a document is built, after all routes have been added
+type DocumentHook func(document *openapi.Document)
+
+// OnOpenAPIDocument registers a hook that can modify the generated OpenAPI document
+// This allows plugins to add definitions, extensions or security schemes in a structured way
+func (a *GoAPI) OnOpenAPIDocument(hook DocumentHook) {
+	a.routesMutex.Lock()
+	defer a.routesMutex.Unlock()
+	a.documentHooks = append(a.documentHooks, hook)
+}
+
+// OpenAPIDocument returns the typed OpenAPI document generated from the registered routes
+// A new document is built on every call, so callers are free to modify the result
+// It documents every route, whatever its specification; SpecDocument returns the served ones
+func (a *GoAPI) OpenAPIDocument() *openapi.Document {
+	a.routesMutex.Lock()
+	defer a.routesMutex.Unlock()
+	return a.buildDocument("")
+}
+
+// LocalizedOpenAPIDocument returns the typed OpenAPI document for a specific locale
+func (a *GoAPI) LocalizedOpenAPIDocument(locale string) *openapi.Document {
+	a.routesMutex.Lock()
+	defer a.routesMutex.Unlock()
+	return a.buildDocument(router.NormalizeLocale(locale))
+}
+
+// buildDocument builds the document of every route for a locale; the caller holds routesMutex
+func (a *GoAPI) buildDocument(locale string) *openapi.Document {
+	return a.buildSpecDocument(specSelection{all: true}, locale)
+}
+
+// specDocument is a generated OpenAPI document ready to be served
+type specDocument struct {
+	content []byte // JSON document
+	gzipped []byte // gzip-compressed content
+	etag    string // Weak validator shared by both encodings
+}
+
+// specSnapshot holds the generated documents by locale ("" is the default language)
+type specSnapshot struct {
+	documents map[string]*specDocument
+	specs     map[string]map[string]*specDocument // Named specifications, by name and locale
+}
+
+// InvalidateSpec regenerates the cached openapi.json
+// Route changes already regenerate it; call it after changing the document in other ways
+// (for example a DocumentHook whose output changed)
+func (a *GoAPI) InvalidateSpec() {
+	a.routesMutex.Lock()
+	defer a.routesMutex.Unlock()
+	a.refreshSpec()
+}
+
+// refreshSpec generates every document once and publishes them for serveSpec
+// The caller holds routesMutex
+func (a *GoAPI) refreshSpec() {
+	snapshot := &specSnapshot{
+		documents: a.specDocuments(specSelection{}),
+		specs:     make(map[string]map[string]*specDocument),
+	}
+	for _, name := range a.specNames() {
+		snapshot.specs[name] = a.specDocuments(specSelection{name: name})
+	}
+	a.spec.Store(snapshot)
+	a.registerSwaggerDoc()
+}
+
+// specDocuments generates the documents of a specification in every documentation locale
+func (a *GoAPI) specDocuments(selection specSelection) map[string]*specDocument {
+	documents := map[string]*specDocument{"": newSpecDocument(a.getSwaggerJSON(selection, ""))}
+	for _, locale := range a.documentationLocales() {
+		documents[locale] = newSpecDocument(a.getSwaggerJSON(selection, locale))
+	}
+	return documents
+}
+
+// newSpecDocument compresses a document and computes its ETag
+func newSpecDocument(content string) *specDocument {
+	document := &specDocument{content: []byte(content)}
+
+	sum := sha256.Sum256(document.content)
+	document.etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	var buffer bytes.Buffer
+	writer, _ := gzip.NewWriterLevel(&buffer, gzip.BestCompression)
+	_, _ = writer.Write(document.content)
+	_ = writer.Close()
+	document.gzipped = buffer.Bytes()
+
+	return document
+}
+
+// serveSpec serves the cached openapi.json, localized with ?lang=<locale>
+// Responses carry an ETag, honour If-None-Match and are gzip-compressed when the client accepts it
+func (a *GoAPI) serveSpec(c *gin.Context) {
+	a.serveSpecDocument(c, a.spec.Load().documents)
+}
+
+// serveSpecDocument serves the document of a specification in the locale requested with ?lang
+func (a *GoAPI) serveSpecDocument(c *gin.Context, documents map[string]*specDocument) {
+	document := documents[""]
+	if locale := router.NormalizeLocale(c.Query("lang")); locale != "" {
+		if localized, ok := documents[locale]; ok {
+			document = localized
+		} else if base, _, found := strings.Cut(locale, "-"); found && documents[base] != nil {
+			document = documents[base]
+		}
+	}
+
+	c.Header("ETag", document.etag)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Vary", "Accept-Encoding")
+	if etagMatches(c.GetHeader("If-None-Match"), document.etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	if acceptsGzip(c.GetHeader("Accept-Encoding")) {
+		c.Header("Content-Encoding", "gzip")
+		c.Data(http.StatusOK, "application/json", document.gzipped)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", document.content)
+}
+
+// etagMatches reports whether an If-None-Match header matches an ETag (weak comparison)
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, coding := range strings.Split(header, ",") {
+		name, parameters, _ := strings.Cut(coding, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		quality := 1.0
+		for _, parameter := range strings.Split(parameters, ";") {
+			if key, value, found := strings.Cut(parameter, "="); found && strings.TrimSpace(key) == "q" {
+				quality, _ = strconv.ParseFloat(strings.TrimSpace(value), 64)
+			}
+		}
+		return quality > 0
+	}
+	return false
+}
+
+// documentationLocales returns every locale used by route or tag translations
+func (a *GoAPI) documentationLocales() []string {
+	seen := make(map[string]bool)
+	var locales []string
+	addLocale := func(locale string) {
+		if !seen[locale] {
+			seen[locale] = true
+			locales = append(locales, locale)
+		}
+	}
+
+	for _, route := range a.routes {
+		for locale := range route.Translations {
+			addLocale(locale)
+		}
+	}
+	for _, translations := range a.tagLocales {
+		for locale := range translations {
+			addLocale(locale)
+		}
+	}
+
+	sort.Strings(locales)
+	return locales
+}
+
+// getTags builds the top-level "tags" section for a locale
+// Tags registered with AddTag come first, in registration order, followed by translated-only tags
+func (a *GoAPI) getTags(locale string) []openapi.Tag {
+	var tags []openapi.Tag
+	registered := make(map[string]bool, len(a.tags))
+	for _, tag := range a.tags {
+		registered[tag.Name] = true
+		if description, ok := a.tagDescription(tag.Name, locale); ok {
+			tag.Description = description
+		}
+		tags = append(tags, tag)
+	}
+	if locale == "" {
+		return tags
+	}
+
+	tagNames := make([]string, 0, len(a.tagLocales))
+	for tagName := range a.tagLocales {
+		if !registered[tagName] {
+			tagNames = append(tagNames, tagName)
+		}
+	}
+	sort.Strings(tagNames)
+
+	for _, tagName := range tagNames {
+		if description, ok := a.tagDescription(tagName, locale); ok {
+			tags = append(tags, openapi.Tag{
+				Name:        tagName,
+				Description: description,
+			})
+		}
+	}
+
+	return tags
+}
+
+// usedTags keeps the tags of a document used by its operations, so a specification does not
+// reveal the tags of the others
+func usedTags(document *openapi.Document) []openapi.Tag {
+	used := make(map[string]bool)
+	pathItems := make([]*openapi.PathItem, 0, len(document.Paths))
+	for _, pathItem := range document.Paths {
+		pathItems = append(pathItems, pathItem)
+	}
+	if webhooks, ok := document.Extensions["x-webhooks"].(map[string]*openapi.PathItem); ok {
+		for _, pathItem := range webhooks {
+			pathItems = append(pathItems, pathItem)
+		}
+	}
+	for _, pathItem := range pathItems {
+		for _, operation := range pathItem.Operations() {
+			for _, tag := range operation.Tags {
+				used[tag] = true
+			}
+		}
+	}
+	tags := make([]openapi.Tag, 0, len(document.Tags))
+	for _, tag := range document.Tags {
+		if used[tag.Name] {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// tagDescription returns the translated description of a tag, trying the base language second
+func (a *GoAPI) tagDescription(tagName, locale string) (string, bool) {
+	if locale == "" {
+		return "", false
+	}
+	translations := a.tagLocales[tagName]
+	description, ok := translations[locale]
+	if !ok {
+		if base, _, found := strings.Cut(locale, "-"); found {
+			description, ok = translations[base]
+		}
+	}
+	return description, ok
+}
+
+// swaggerDoc serves through swag (/swagger/doc.json) the default document of the application that
+// generated it last; it reads the published snapshot, so route changes do not race with readers
+type swaggerDoc struct {
+	api atomic.Pointer[GoAPI]
+}
+
+// ReadDoc implements swag.Swagger
+func (d *swaggerDoc) ReadDoc() string {
+	api := d.api.Load()
+	if api == nil {
+		return ""
+	}
+	return string(api.spec.Load().documents[""].content)
+}
+
+// registeredSwaggerDoc is registered in swag once, under the name read by gin-swagger
+var (
+	registeredSwaggerDoc     = &swaggerDoc{}
+	registeredSwaggerDocOnce sync.Once
+)
+
+// registerSwaggerDoc publica en swag el documento por defecto de la aplicación
+// Una documentación registrada antes por la aplicación (swag init) se conserva
+func (a *GoAPI) registerSwaggerDoc() {
+	registeredSwaggerDocOnce.Do(func() {
+		if swag.GetSwagger(swag.Name) == nil {
+			swag.Register(swag.Name, registeredSwaggerDoc)
+		}
+	})
+	registeredSwaggerDoc.api.Store(a)
+}
+
+// getSwaggerJSON returns the Swagger JSON of a specification for a locale (empty for the default language)
+func (a *GoAPI) getSwaggerJSON(selection specSelection, locale string) string {
+	// Convertir a JSON string
+	specBytes, _ := a.buildSpecDocument(selection, locale).JSON()
+	return string(specBytes)
+}
+
+// isDocumentationPath reports whether a path belongs to the built-in documentation routes
+// "/" is an application path when the index page is disabled
+func (a *GoAPI) isDocumentationPath(path string) bool {
+	switch path {
+	case "/":
+		return !a.config.DisableIndex
+	case "/docs", "/redoc", "/swagger/*any", "/redoc/index.html",
+		"/openapi.json", "/docs-static/*filepath":
+		return true
+	}
+	return false
+}
+
+// buildSpecDocument construye el documento OpenAPI tipado basándose en las rutas seleccionadas
+func (a *GoAPI) buildSpecDocument(selection specSelection, locale string) *openapi.Document {
+	title, description, version := a.specInfo(selection)
+	document := openapi.NewDocument(openapi.Info{
+		Title:       title,
+		Description: description,
+		Version:     version,
+		Contact: &openapi.Contact{
+			Name:  a.config.Contact.Name,
+			URL:   a.config.Contact.URL,
+			Email: a.config.Contact.Email,
+		},
+		License: &openapi.License{
+			Name: a.config.License.Name,
+			URL:  a.config.License.URL,
+		},
+	})
+	document.Host = a.config.Host
+	basePath, specPath := a.specPaths(a.routes)
+	document.BasePath = basePath
+	document.Schemes = a.config.Schemes
+
+	// Generar paths basándose en las rutas registradas
+	for _, route := range a.routes {
+		if a.isDocumentationPath(route.Path) || !selection.includes(route) {
+			continue // Skip documentation routes and those of other specifications
+		}
+
+		// Convertir ruta de Gin (:id) a formato OpenAPI ({id}), relativa al basePath
+		openAPIPath := a.convertToOpenAPIPath(specPath(route.Path))
+
+		// Obtener o crear el pathItem para esta ruta
+		pathItem, exists := document.Paths[openAPIPath]
+		if !exists {
+			pathItem = &openapi.PathItem{}
+			document.Paths[openAPIPath] = pathItem
+		}
+
+		pathItem.SetOperation(route.Method, a.buildOperation(route, locale))
+	}
+
+	a.addWebhooks(document, selection, locale)
+
+	document.Tags = a.getTags(locale)
+	if !selection.all && len(a.specNames()) > 0 {
+		document.Tags = usedTags(document)
+	}
+
+	for _, hook := range a.documentHooks {
+		hook(document)
+	}
+
+	return document
+}
+
+// buildOperation builds the typed operation of a route
+func (a *GoAPI) buildOperation(route router.Route, locale string) *openapi.Operation {
+	operation := &openapi.Operation{
+		Summary:     route.LocalizedSummary(locale),
+		Description: route.LocalizedDescription(locale),
+		Tags:        route.Tags,
+		OperationID: route.OperationID,
+		Parameters:  a.getRouteParameters(route),
+		Responses:   a.getRouteResponses(route),
+		Consumes:    route.Consumes,
+		Produces:    route.Produces,
+	}
+	addRouteExamples(route, operation)
+	a.addCallbacks(route, operation, locale)
+	if route.ExternalDocs != nil {
+		operation.ExternalDocs = &openapi.ExternalDocs{
+			URL:         route.ExternalDocs.URL,
+			Description: route.ExternalDocs.Description,
+		}
+	}
+
+	if operation.Summary == "" {
+		operation.Summary = "API endpoint"
+	}
+	if operation.Description == "" {
+		operation.Description = "API endpoint description"
+	}
+	if len(route.Tags) == 0 {
+		operation.Tags = []string{"default"}
+	}
+
+	return operation
+}
+
+// getRouteResponses builds the responses of a route, using a generic 200 response when none are declared
+func (a *GoAPI) getRouteResponses(route router.Route) openapi.Responses {
+	responses := make(openapi.Responses)
+	for statusCode, description := range route.Responses {
+		response := &openapi.Response{Description: description}
+		if model, declared := route.ResponseModels[statusCode]; declared && model != nil {
+			response.Schema = a.generateSchemaFromStruct(model, responseDirection)
+			if usesXML(route.Produces) {
+				xmlRoot(response.Schema, model)
+			}
+		}
+		responses[strconv.Itoa(statusCode)] = response
+	}
+
+	a.addErrorCodeResponses(route, responses)
+
+	if len(responses) == 0 {
+		responses["200"] = &openapi.Response{
+			Description: "Successful response",
+			Schema:      &openapi.Schema{Type: "object"},
+		}
+	}
+
+	// Las rutas validadas automáticamente pueden responder 422
+	if _, declared := responses["422"]; !declared && a.validatesRequest(route) && len(route.Parameters) > 0 {
+		responses["422"] = &openapi.Response{Description: "Validation error"}
+	}
+
+	return responses
+}
+
+// addErrorCodeResponses documents the declared error codes of a route grouped by status
+// Each status gets an error schema whose "code" enumerates the codes, listed in the description
+func (a *GoAPI) addErrorCodeResponses(route router.Route, documented openapi.Responses) {
+	byStatus := make(map[int][]responses.ErrorCode)
+	for _, code := range route.ErrorCodes {
+		if errorCode, registered := responses.LookupErrorCode(code); registered {
+			byStatus[errorCode.Status] = append(byStatus[errorCode.Status], errorCode)
+		}
+	}
+
+	for status, codes := range byStatus {
+		key := strconv.Itoa(status)
+		response, declared := documented[key]
+		if !declared {
+			response = &openapi.Response{Description: http.StatusText(status)}
+			documented[key] = response
+		}
+
+		enum := make([]interface{}, 0, len(codes))
+		lines := make([]string, 0, len(codes))
+		for _, code := range codes {
+			enum = append(enum, code.Code)
+			line := fmt.Sprintf("- `%s`: %s", code.Code, code.Message)
+			if code.Description != "" {
+				line += " (" + code.Description + ")"
+			}
+			lines = append(lines, line)
+		}
+		response.Description += "\n\nError codes:\n" + strings.Join(lines, "\n")
+
+		if response.Schema == nil {
+			response.Schema = &openapi.Schema{
+				Type: "object",
+				Properties: map[string]*openapi.Schema{
+					"detail": {Type: "string", Example: codes[0].Message},
+					"type":   {Type: "string", Example: responses.StatusType(status)},
+					"code":   {Type: "string", Enum: enum},
+				},
+				Required: []string{"detail", "code"},
+			}
+		}
+	}
+}
+
+// addRouteExamples documents the named request and response examples of a route
+// Swagger 2.0 has one example per body, so the first example fills it (the schema example, the
+// examples of the response and the x-examples of the body parameter); all of them are listed in
+// x-named-examples and, when there are several, in the description so every variant is visible
+func addRouteExamples(route router.Route, operation *openapi.Operation) {
+	if len(route.RequestExamples) > 0 {
+		index := slices.IndexFunc(operation.Parameters, func(parameter openapi.Parameter) bool {
+			return parameter.In == "body"
+		})
+		if index < 0 {
+			operation.Parameters = append(operation.Parameters, openapi.Parameter{
+				Name:     "body",
+				In:       "body",
+				Required: true,
+				Schema:   &openapi.Schema{Type: "object"},
+			})
+			index = len(operation.Parameters) - 1
+		}
+		body := &operation.Parameters[index]
+		body.Schema = withExample(body.Schema, route.RequestExamples[0].Value)
+		body.Extensions.Set("x-examples", examplesByMediaType(route.RequestExamples, route.Consumes))
+		body.Extensions.Set("x-named-examples", namedExamples(route.RequestExamples))
+		body.Description = appendExamples(body.Description, route.RequestExamples)
+	}
+
+	for status, examples := range route.ResponseExamples {
+		response := operation.Responses[strconv.Itoa(status)]
+		if response == nil || len(examples) == 0 {
+			continue
+		}
+		response.Schema = withExample(response.Schema, examples[0].Value)
+		response.Examples = examplesByMediaType(examples, route.Produces)
+		response.Extensions.Set("x-named-examples", namedExamples(examples))
+		response.Description = appendExamples(response.Description, examples)
+	}
+}
+
+// withExample returns a copy of an inline schema with an example; references are kept as they are
+func withExample(schema *openapi.Schema, example interface{}) *openapi.Schema {
+	if schema == nil || schema.Ref != "" {
+		return schema
+	}
+	copied := *schema
+	copied.Example = example
+	return &copied
+}
+
+// examplesByMediaType maps each media type (JSON by default) to the first example
+func examplesByMediaType(examples []router.Example, mediaTypes []string) map[string]interface{} {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{gin.MIMEJSON}
+	}
+	byMediaType := make(map[string]interface{}, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		byMediaType[mediaType] = examples[0].Value
+	}
+	return byMediaType
+}
+
+// namedExamples builds the Example Objects of x-named-examples, the shape of OpenAPI 3 examples
+func namedExamples(examples []router.Example) map[string]interface{} {
+	named := make(map[string]interface{}, len(examples))
+	for _, example := range examples {
+		named[example.Name] = map[string]interface{}{"summary": example.Name, "value": example.Value}
+	}
+	return named
+}
+
+// appendExamples lista los ejemplos en la descripción (Markdown) cuando hay más de uno
+func appendExamples(description string, examples []router.Example) string {
+	if len(examples) < 2 {
+		return description
+	}
+	var builder strings.Builder
+	builder.WriteString(description)
+	if description != "" {
+		builder.WriteString("\n\n")
+	}
+	builder.WriteString("Examples:")
+	for _, example := range examples {
+		data, err := json.MarshalIndent(example.Value, "", "  ")
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&builder, "\n\n**%s**\n\n```json\n%s\n```", example.Name, data)
+	}
+	return builder.String()
+}
+
+// getRouteParameters obtiene los parámetros de una ruta, priorizando los configurados por el usuario
+func (a *GoAPI) getRouteParameters(route router.Route) []openapi.Parameter {
+	var parameters []openapi.Parameter
+
+	// Primero, usar parámetros configurados por el usuario
+	for _, param := range route.Parameters {
+		parameter := openapi.Parameter{
+			Name:        param.Name,
+			In:          param.In,
+			Required:    param.Required,
+			Description: param.Description,
+		}
+
+		// Manejar parámetros de body con schema
+		if param.In == "body" && param.Schema != nil {
+			parameter.Schema = a.generateSchemaFromStruct(param.Schema, requestDirection)
+			if usesXML(route.Consumes) {
+				xmlRoot(parameter.Schema, param.Schema)
+			}
+		} else {
+			parameter.Type = param.Type
+			parameter.Format = param.Format
+		}
+		if param.Nullable {
+			// Swagger 2.0 no define nullable; la extensión es la usada por go-swagger y otros generadores
+			parameter.Extensions.Set("x-nullable", true)
+		}
+
+		parameters = append(parameters, parameter)
+	}
+
+	// Si no hay parámetros configurados, usar detección automática para parámetros de ruta
+	if len(route.Parameters) == 0 {
+		parameters = a.extractParameters(route.Path)
+	}
+
+	return parameters
+}
+
+// convertToOpenAPIPath convierte rutas de Gin (:id, *path) a formato OpenAPI ({id}, {path})
+func (a *GoAPI) convertToOpenAPIPath(path string) string {
+	// Reemplazar :param y *param con {param}
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			paramName := segment[1:] // Remover el ":"
+			segments[i] = "{" + paramName + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// extractParameters extrae parámetros de una ruta para la especificación OpenAPI
+func (a *GoAPI) extractParameters(path string) []openapi.Parameter {
+	var parameters []openapi.Parameter
+
+	// Dividir la ruta en segmentos
+	segments := strings.Split(path, "/")
+
+	for _, segment := range segments {
+		// Buscar parámetros de ruta (formato :param o comodín *param)
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			paramName := segment[1:] // Remover el ":" o "*"
+
+			parameter := openapi.Parameter{
+				Name:     paramName,
+				In:       "path",
+				Required: true,
+				Type:     "string",
+			}
+
+			// Personalizar descripción según el nombre del parámetro
+			switch {
+			case segment[0] == '*':
+				parameter.Description = "Resto de la ruta"
+			case paramName == "id":
+				parameter.Description = "ID del recurso"
+				parameter.Type = "integer"
+				parameter.Format = "int64"
+			case paramName == "userId", paramName == "user_id":
+				parameter.Description = "ID del usuario"
+				parameter.Type = "integer"
+				parameter.Format = "int64"
+			default:
+				parameter.Description = fmt.Sprintf("Parámetro %s", paramName)
+			}
+
+			parameters = append(parameters, parameter)
+		}
+	}
+
+	return parameters
+}
+
+// SchemaOf returns the schema documenting a model, as generated for request and response bodies
+// Plugins use it to document models in definitions or vendor extensions. Read-only and write-only
+// fields are both included, marked readOnly and x-writeOnly
+func (a *GoAPI) SchemaOf(model interface{}) *openapi.Schema {
+	return a.generateSchemaFromStruct(model, anyDirection)
+}
+
+// schemaDirection indica en qué cuerpos se usa un schema: las peticiones omiten los campos de solo
+// lectura (goapi:"readonly") y las respuestas los de solo escritura (goapi:"writeonly")
+type schemaDirection int
+
+const (
+	anyDirection schemaDirection = iota
+	requestDirection
+	responseDirection
+)
+
+// generateSchemaFromStruct genera un schema OpenAPI desde un struct de Go
+func (a *GoAPI) generateSchemaFromStruct(example interface{}, direction schemaDirection) *openapi.Schema {
+	return a.modelSchema(example, direction, schemaPath{})
+}
+
+// schemaPath holds the struct types being documented: a type found again inside itself belongs to
+// a recursive model (type Node struct{ Children []Node }) and is documented as a plain object
+type schemaPath map[reflect.Type]bool
+
+// modelSchema documenta un modelo; path lleva los structs que lo contienen
+func (a *GoAPI) modelSchema(example interface{}, direction schemaDirection, path schemaPath) *openapi.Schema {
+	schema := &openapi.Schema{
+		Type:       "object",
+		Properties: make(map[string]*openapi.Schema),
+	}
+
+	// Si el ejemplo es directamente un valor, usarlo como ejemplo
+	if example != nil {
+		schema.Example = example
+
+		// Usar reflection para generar propiedades del schema
+		v := reflect.ValueOf(example)
+		t := reflect.TypeOf(example)
+
+		// Si es un puntero, obtener el valor al que apunta
+		if v.Kind() == reflect.Ptr {
+			if !v.IsNil() {
+				v = v.Elem()
+				t = t.Elem()
+			}
+		}
+
+		if v.Kind() == reflect.Map {
+			schema.Properties = nil
+			schema.AdditionalProperties = a.elementSchema(t.Elem(), direction, path)
+			schema.Example = bodyExample(schema, example, direction)
+			return schema
+		}
+
+		// Los slices documentan el schema de sus elementos
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			item := reflect.Zero(t.Elem()).Interface()
+			if v.Len() > 0 {
+				item = v.Index(0).Interface()
+			}
+			items := a.modelSchema(item, direction, path)
+			items.Example = nil
+			schema.Type = "array"
+			schema.Properties = nil
+			schema.Items = items
+			schema.Example = bodyExample(schema, example, direction)
+			return schema
+		}
+
+		// Solo procesar structs
+		if v.Kind() == reflect.Struct {
+			if path[t] {
+				return &openapi.Schema{Type: "object"}
+			}
+			path[t] = true
+			defer delete(path, t)
+			var required, promotedRequired []string
+			promoted := make(map[string]bool)
+
+			for i := 0; i < v.NumField(); i++ {
+				field := t.Field(i)
+				fieldValue := v.Field(i)
+
+				// XMLName documenta el nombre del elemento XML del struct, no es una propiedad
+				if field.Name == "XMLName" && field.Type == reflect.TypeOf(xml.Name{}) {
+					if name, namespace := xmlTagName(field); name != "" {
+						schema.XML = &openapi.XML{Name: name, Namespace: namespace}
+					}
+					continue
+				}
+
+				// Obtener el nombre del campo JSON; json:"-" excluye el campo, como en encoding/json
+				jsonTag := field.Tag.Get("json")
+				if jsonTag == "-" {
+					continue
+				}
+				name, options, _ := strings.Cut(jsonTag, ",")
+
+				// Los structs embebidos sin nombre JSON aportan sus campos, como en encoding/json,
+				// también a través de punteros (opcionales, se omiten si es nulo) o con un tipo no
+				// exportado; los campos propios tienen prioridad sobre los promovidos
+				if field.Anonymous && name == "" {
+					if embedded, ok := embeddedStruct(fieldValue); ok {
+						embeddedSchema := a.modelSchema(embedded, direction, path)
+						for name, property := range embeddedSchema.Properties {
+							if _, exists := schema.Properties[name]; !exists {
+								schema.Properties[name] = property
+								promoted[name] = true
+							}
+						}
+						if field.Type.Kind() != reflect.Ptr {
+							promotedRequired = append(promotedRequired, embeddedSchema.Required...)
+						}
+						continue
+					}
+				}
+				if !field.IsExported() {
+					continue
+				}
+
+				// Los campos de solo lectura (id, created_at) no se envían y los de solo escritura
+				// (contraseñas, secretos) nunca se devuelven
+				if (direction == requestDirection && readOnlyField(field)) || (direction == responseDirection && writeOnlyField(field)) {
+					continue
+				}
+
+				fieldName := field.Name
+				if name != "" {
+					fieldName = name
+				}
+
+				// Un campo es requerido salvo con omitempty u omitzero, si es Optional o de solo
+				// lectura; validate:"required" lo hace requerido en todo caso
+				omitted := hasOption(options, "omitempty") || hasOption(options, "omitzero")
+				validatedRequired := slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required")
+				if !readOnlyField(field) && (validatedRequired || (!omitted && !optionalField(fieldValue, field))) {
+					required = append(required, fieldName)
+				}
+
+				// Generar el tipo del campo
+				property := a.getFieldSchema(fieldValue, field, direction, path)
+				if hasOption(options, "string") {
+					// La opción ",string" codifica números y booleanos como texto
+					property = stringEncoded(property)
+				}
+				if readOnlyField(field) {
+					property.ReadOnly = true
+				}
+				if writeOnlyField(field) {
+					// Swagger 2.0 no define writeOnly; es la extensión usada por otros generadores
+					property.Extensions.Set("x-writeOnly", true)
+				}
+				if hasTagOption(field, "goapi", "computed") {
+					property.Extensions.Set("x-computed", true)
+				}
+				applyXMLTag(property, field, fieldName)
+				schema.Properties[fieldName] = property
+				delete(promoted, fieldName)
+			}
+
+			for _, name := range promotedRequired {
+				if promoted[name] {
+					required = append(required, name)
+				}
+			}
+			schema.Required = required
+			schema.Example = bodyExample(schema, example, direction)
+		}
+	}
+
+	return schema
+}
+
+// bodyExample returns the example of a model schema: the model itself when it has values, or an
+// example generated from the example tags, defaults and types of its fields, so
+// WithRequestBody(Order{}) documents a realistic payload instead of zero values. Models of request
+// and response bodies lose the members their schema omits
+func bodyExample(schema *openapi.Schema, model interface{}, direction schemaDirection) interface{} {
+	value := reflect.ValueOf(model)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	zero := value.IsZero()
+	if kind := value.Kind(); kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map {
+		zero = value.Len() == 0
+	}
+	if !zero && direction == anyDirection {
+		return model
+	}
+	if !zero {
+		return pruneExample(jsonValue(model), schema)
+	}
+	schema.Example = nil
+	return generateExample(schema, nil, 0)
+}
+
+// pruneExample quita de un ejemplo los miembros que no documenta su schema
+func pruneExample(example interface{}, schema *openapi.Schema) interface{} {
+	switch value := example.(type) {
+	case map[string]interface{}:
+		if len(schema.Properties) == 0 {
+			return value
+		}
+		for name, member := range value {
+			property, documented := schema.Properties[name]
+			if !documented {
+				delete(value, name)
+				continue
+			}
+			value[name] = pruneExample(member, property)
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range value {
+				value[i] = pruneExample(item, schema.Items)
+			}
+		}
+	}
+	return example
+}
+
+// usesXML indica si alguno de los tipos de contenido de una ruta es XML
+func usesXML(mediaTypes []string) bool {
+	return slices.ContainsFunc(mediaTypes, validation.IsXMLContentType)
+}
+
+// xmlRoot documenta el elemento raíz de un cuerpo XML: sin XMLName, encoding/xml usa el nombre del tipo
+func xmlRoot(schema *openapi.Schema, model interface{}) {
+	modelType := reflect.TypeOf(model)
+	for modelType != nil && modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	if modelType == nil || modelType.Kind() != reflect.Struct || modelType.Name() == "" || schema.XML != nil {
+		return
+	}
+	schema.XML = &openapi.XML{Name: modelType.Name()}
+}
+
+// xmlTagName devuelve el nombre y el espacio de nombres del tag xml de un campo ("ns nombre,attr")
+func xmlTagName(field reflect.StructField) (string, string) {
+	name, _, _ := strings.Cut(field.Tag.Get("xml"), ",")
+	if space := strings.LastIndex(name, " "); space >= 0 {
+		return name[space+1:], name[:space]
+	}
+	return name, ""
+}
+
+// applyXMLTag documenta la representación XML de una propiedad según su tag xml: el nombre del
+// elemento cuando difiere de la propiedad, los atributos y las listas envueltas (xml:"items>item")
+func applyXMLTag(property *openapi.Schema, field reflect.StructField, propertyName string) {
+	tag := field.Tag.Get("xml")
+	if tag == "" || tag == "-" {
+		return
+	}
+	for _, option := range strings.Split(tag, ",")[1:] {
+		if option == "chardata" || option == "innerxml" || option == "comment" {
+			return // No son elementos; Swagger 2.0 no los describe
+		}
+	}
+
+	name, namespace := xmlTagName(field)
+	info := openapi.XML{Namespace: namespace, Attribute: hasTagOption(field, "xml", "attr")}
+	if property.Type == "array" && property.Items != nil {
+		element := name
+		if parent, child, nested := strings.Cut(name, ">"); nested {
+			info.Name, info.Wrapped = parent, true
+			element = child[strings.LastIndex(child, ">")+1:]
+		}
+		// El XMLName del tipo del elemento tiene prioridad, como en encoding/xml
+		if element != "" && element != propertyName && (property.Items.XML == nil || property.Items.XML.Name == "") {
+			if property.Items.XML == nil {
+				property.Items.XML = &openapi.XML{}
+			}
+			property.Items.XML.Name = element
+		}
+	} else if name = name[strings.LastIndex(name, ">")+1:]; name != "" && name != propertyName {
+		info.Name = name
+	}
+
+	if property.XML != nil && property.XML.Name != "" {
+		info.Name = property.XML.Name
+		if info.Namespace == "" {
+			info.Namespace = property.XML.Namespace
+		}
+	}
+	if info != (openapi.XML{}) {
+		property.XML = &info
+	}
+}
+
+// elementSchema documenta los valores de un mapa a partir del valor cero de su tipo; interface{}
+// admite cualquier valor. Los structs que ya están en path (type Dir struct{ Entries map[string]Dir })
+// se cortan en modelSchema
+func (a *GoAPI) elementSchema(elementType reflect.Type, direction schemaDirection, path schemaPath) *openapi.Schema {
+	if elementType.Kind() == reflect.Interface {
+		return &openapi.Schema{} // Cualquier valor JSON
+	}
+	if _, mapped := a.typeSchema(elementType); !mapped && elementType.Kind() == reflect.Struct {
+		items := a.modelSchema(reflect.New(elementType).Elem().Interface(), direction, path)
+		items.Example = nil
+		return items
+	}
+	return a.getFieldSchema(reflect.Zero(elementType), reflect.StructField{}, direction, path)
+}
+
+// embeddedStruct devuelve el valor de un struct embebido, directamente o a través de un puntero
+// Los punteros nulos y los tipos no exportados documentan el valor cero de su tipo
+func embeddedStruct(fieldValue reflect.Value) (interface{}, bool) {
+	structType := fieldValue.Type()
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+		if !fieldValue.IsNil() {
+			fieldValue = fieldValue.Elem()
+		}
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if fieldValue.Kind() == reflect.Struct && fieldValue.CanInterface() {
+		return fieldValue.Interface(), true
+	}
+	return reflect.New(structType).Elem().Interface(), true
+}
+
+// stringEncoded documenta un campo con la opción ",string" de encoding/json
+func stringEncoded(property *openapi.Schema) *openapi.Schema {
+	switch property.Type {
+	case "integer", "number", "boolean":
+		encoded := &openapi.Schema{Type: "string", Description: property.Description, Extensions: property.Extensions}
+		if property.Example != nil {
+			encoded.Example = fmt.Sprint(property.Example)
+		}
+		return encoded
+	}
+	return property
+}
+
+// hasOption indica si una lista de opciones de un tag ("id,omitempty") incluye una opción
+func hasOption(options, option string) bool {
+	for _, candidate := range strings.Split(options, ",") {
+		if candidate == option {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTagOption indica si el tag de un campo incluye una opción, como goapi:"readonly"
+func hasTagOption(field reflect.StructField, tag, option string) bool {
+	for _, candidate := range strings.Split(field.Tag.Get(tag), ",") {
+		if candidate == option {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyField indica si el cliente no envía un campo: goapi:"readonly" o un campo calculado
+// (goapi:"computed"), que solo aparece en las respuestas
+func readOnlyField(field reflect.StructField) bool {
+	return hasTagOption(field, "goapi", "readonly") || hasTagOption(field, "goapi", "computed")
+}
+
+// writeOnlyField indica si un campo solo lo envía el cliente, como una contraseña: goapi:"writeonly"
+func writeOnlyField(field reflect.StructField) bool {
+	return hasTagOption(field, "goapi", "writeonly")
+}
+
+// optionalField indica si un campo es Optional y su tag validate no lo hace requerido
+func optionalField(fieldValue reflect.Value, field reflect.StructField) bool {
+	if _, nullable := validation.NullableValue(fieldValue); !nullable {
+		return false
+	}
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		if rule == "required" {
+			return false
+		}
+	}
+	return true
+}
+
+// getFieldSchema obtiene el schema de un campo específico
+func (a *GoAPI) getFieldSchema(fieldValue reflect.Value, field reflect.StructField, direction schemaDirection, path schemaPath) *openapi.Schema {
+	fieldSchema := &openapi.Schema{}
+
+	example := field.Tag.Get("example")
+
+	// Los campos Optional documentan el tipo que contienen, marcado como nullable
+	if value, nullable := validation.NullableValue(fieldValue); nullable {
+		valueSchema := a.getFieldSchema(value, field, direction, path)
+		valueSchema.Extensions.Set("x-nullable", true)
+		return valueSchema
+	}
+
+	// Los tipos registrados (RegisterSchemaType, RegisterEnum) y los conocidos, como time.Time,
+	// UUID o decimal, usan su schema en lugar del de su kind
+	if fieldValue.IsValid() {
+		if mapped, found := a.typeSchema(fieldValue.Type()); found {
+			fieldSchema = mapped
+			if example != "" {
+				fieldSchema.Example = tagExample(fieldSchema, example)
+			}
+			a.validator.ApplySchemaConstraints(fieldSchema, field.Tag.Get("validate"))
+			return fieldSchema
+		}
+	}
+
+	// Determinar el tipo basándose en el tipo de Go
+	switch fieldValue.Kind() {
+	case reflect.String:
+		fieldSchema.Type = "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fieldSchema.Type = "integer"
+		fieldSchema.Format = "int64"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fieldSchema.Type = "integer"
+		fieldSchema.Format = "int64"
+	case reflect.Float32, reflect.Float64:
+		fieldSchema.Type = "number"
+		if fieldValue.Kind() == reflect.Float32 {
+			fieldSchema.Format = "float"
+		} else {
+			fieldSchema.Format = "double"
+		}
+	case reflect.Bool:
+		fieldSchema.Type = "boolean"
+	case reflect.Slice, reflect.Array:
+		elementType := fieldValue.Type().Elem()
+		if fieldValue.Kind() == reflect.Slice && elementType.Kind() == reflect.Uint8 {
+			// encoding/json codifica []byte en base64
+			fieldSchema.Type = "string"
+			fieldSchema.Format = "byte"
+			break
+		}
+		fieldSchema.Type = "array"
+		element := reflect.Zero(elementType)
+		if fieldValue.Len() > 0 {
+			element = fieldValue.Index(0)
+		}
+		// Los elementos struct documentan sus propiedades y el resto el schema de su tipo
+		if _, mapped := a.typeSchema(elementType); !mapped && elementType.Kind() == reflect.Struct && element.CanInterface() {
+			items := a.modelSchema(element.Interface(), direction, path)
+			items.Example = nil
+			fieldSchema.Items = items
+		} else {
+			fieldSchema.Items = a.getFieldSchema(element, reflect.StructField{}, direction, path)
+		}
+	case reflect.Map:
+		// Los mapas son objetos con claves libres y valores del tipo de sus elementos
+		fieldSchema.Type = "object"
+		fieldSchema.AdditionalProperties = a.elementSchema(fieldValue.Type().Elem(), direction, path)
+	case reflect.Ptr, reflect.Interface:
+		// Para punteros e interfaces, analizar el valor al que apuntan
+		if !fieldValue.IsNil() {
+			return a.getFieldSchema(fieldValue.Elem(), field, direction, path)
+		}
+		// Los punteros nulos documentan el tipo apuntado; los structs no, por los tipos recursivos
+		if fieldValue.Kind() == reflect.Ptr {
+			elementType := fieldValue.Type().Elem()
+			if _, mapped := a.typeSchema(elementType); mapped || elementType.Kind() != reflect.Struct {
+				return a.getFieldSchema(reflect.Zero(elementType), field, direction, path)
+			}
+		}
+		fieldSchema.Type = "string" // Por defecto para punteros nulos
+	case reflect.Struct:
+		if !fieldValue.CanInterface() {
+			fieldSchema.Type = "string"
+			break
+		}
+		// Los structs anidados documentan sus propiedades
+		// El ejemplo del body ya incluye el de los structs anidados; solo se repite el del tag
+		nested := a.modelSchema(fieldValue.Interface(), direction, path)
+		nested.Example = nil
+		if example != "" {
+			nested.Example = tagExample(nested, example)
+		}
+		return nested
+	default:
+		fieldSchema.Type = "string" // Por defecto
+	}
+
+	if example != "" {
+		fieldSchema.Example = tagExample(fieldSchema, example)
+	}
+
+	// Documentar las restricciones del tag validate (min, max, email, slug...)
+	a.validator.ApplySchemaConstraints(fieldSchema, field.Tag.Get("validate"))
+
+	return fieldSchema
+}
+
+// tagExample convierte un tag example al tipo JSON del schema del campo
+// Los arrays aceptan un array JSON o valores separados por comas y los objetos un objeto JSON
+func tagExample(schema *openapi.Schema, text string) interface{} {
+	switch schema.Type {
+	case "integer":
+		if value, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return value
+		}
+	case "number":
+		if value, err := strconv.ParseFloat(text, 64); err == nil {
+			return value
+		}
+	case "boolean":
+		if value, err := strconv.ParseBool(text); err == nil {
+			return value
+		}
+	case "array":
+		var values []interface{}
+		if json.Unmarshal([]byte(text), &values) == nil {
+			return values
+		}
+		items := schema.Items
+		if items == nil {
+			items = &openapi.Schema{Type: "string"}
+		}
+		values = make([]interface{}, 0)
+		for _, item := range strings.Split(text, ",") {
+			values = append(values, tagExample(items, strings.TrimSpace(item)))
+		}
+		return values
+	case "object":
+		var value map[string]interface{}
+		if json.Unmarshal([]byte(text), &value) == nil {
+			return value
+		}
+	}
+	return text
+}
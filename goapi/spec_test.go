@@ -0,0 +1,63 @@
+package goapi_test
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// node is a recursive model through a slice
+type node struct {
+	Name     string `json:"name"`
+	Children []node `json:"children"`
+}
+
+func TestSchemaOfRecursiveSlice(t *testing.T) {
+	api := goapi.New(goapi.DefaultConfig())
+	schema := api.SchemaOf(node{})
+
+	children := schema.Properties["children"]
+	if children == nil || children.Type != "array" || children.Items == nil {
+		t.Fatalf("children = %+v, want an array", children)
+	}
+	if children.Items.Type != "object" || len(children.Items.Properties) != 0 {
+		t.Errorf("children items = %+v, want a plain object", children.Items)
+	}
+}
+
+// dir is a recursive model through the values of a map
+type dir struct {
+	Entries map[string]dir `json:"entries"`
+}
+
+func TestSchemaOfRecursiveMap(t *testing.T) {
+	api := goapi.New(goapi.DefaultConfig())
+	schema := api.SchemaOf(dir{})
+
+	entries := schema.Properties["entries"]
+	if entries == nil || entries.Type != "object" || entries.AdditionalProperties == nil {
+		t.Fatalf("entries = %+v, want a map", entries)
+	}
+	if entries.AdditionalProperties.Type != "object" || len(entries.AdditionalProperties.Properties) != 0 {
+		t.Errorf("entries values = %+v, want a plain object", entries.AdditionalProperties)
+	}
+	if api.SchemaOf(map[string]dir{}).AdditionalProperties == nil {
+		t.Error("map model without a schema for its values")
+	}
+}
+
+func TestOpenAPIDocumentRecursiveBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	api := goapi.New(goapi.DefaultConfig())
+	api.POST("/nodes", func(c *gin.Context) {},
+		router.WithRequestBody(node{}, "Árbol de nodos"),
+		router.WithResponseModel(200, []node{}, "Árbol de nodos"))
+
+	document := api.OpenAPIDocument()
+	if document.Paths["/nodes"] == nil || document.Paths["/nodes"].Post == nil {
+		t.Fatal("POST /nodes is not documented")
+	}
+}
@@ -0,0 +1,157 @@
+package goapi
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/core"
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// SpecConfig configures a specification served with its own documentation
+type SpecConfig struct {
+	Title       string // Default: the API title followed by the name of the specification
+	Description string // Default: the API description
+	Version     string // Default: the API version
+	// Middlewares run before the documentation of the specification is served (its UIs and
+	// JSON document), for example to require authentication on internal APIs
+	Middlewares []gin.HandlerFunc
+}
+
+// WithSpec assigns a route to named specifications instead of the main one
+// Each specification is served on /docs/<name> (Swagger UI), /redoc/<name> and
+// /docs/<name>/openapi.json; groups assign their routes with WithOptions:
+//
+//	internal := api.Group("/internal").WithOptions(goapi.WithSpec("internal"))
+func WithSpec(names ...string) router.RouteOption {
+	return router.WithSpec(names...)
+}
+
+// DefineSpec configures a named specification, or the main one with an empty name
+// Specifications used by WithSpec need not be defined; define them before SetupRoutes
+//
+//	api.DefineSpec("internal", goapi.SpecConfig{
+//		Title:       "Internal API",
+//		Middlewares: []gin.HandlerFunc{gin.BasicAuth(gin.Accounts{"ops": secret})},
+//	})
+func (a *GoAPI) DefineSpec(name string, config SpecConfig) {
+	a.routesMutex.Lock()
+	defer a.routesMutex.Unlock()
+	if a.specConfigs == nil {
+		a.specConfigs = make(map[string]SpecConfig)
+	}
+	a.specConfigs[name] = config
+}
+
+// SpecDocument returns the document of a named specification ("" for the main one)
+func (a *GoAPI) SpecDocument(name string) *openapi.Document {
+	a.routesMutex.Lock()
+	defer a.routesMutex.Unlock()
+	return a.buildSpecDocument(specSelection{name: name}, "")
+}
+
+// specSelection selects the routes of a document: every route, or those of a specification
+type specSelection struct {
+	all  bool
+	name string // "" selects the routes without WithSpec
+}
+
+// includes reports whether a route belongs to the selected document
+func (selection specSelection) includes(route router.Route) bool {
+	if selection.all {
+		return true
+	}
+	if selection.name == "" {
+		return len(route.Specs) == 0
+	}
+	return slices.Contains(route.Specs, selection.name)
+}
+
+// specNames returns the names of the specifications used by routes or defined, sorted
+func (a *GoAPI) specNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, route := range a.routes {
+		for _, name := range route.Specs {
+			add(name)
+		}
+	}
+	for name := range a.specConfigs {
+		add(name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// specInfo returns the title, description and version of the selected document
+func (a *GoAPI) specInfo(selection specSelection) (string, string, string) {
+	title, description, version := a.config.Title, a.config.Description, a.config.Version
+	if selection.all {
+		return title, description, version
+	}
+	if selection.name != "" {
+		title += " (" + selection.name + ")"
+	}
+	config := a.specConfigs[selection.name]
+	if config.Title != "" {
+		title = config.Title
+	}
+	if config.Description != "" {
+		description = config.Description
+	}
+	if config.Version != "" {
+		version = config.Version
+	}
+	return title, description, version
+}
+
+// specRoutes returns the routes of the main specification, listed on the index page
+func (a *GoAPI) specRoutes(routes []router.Route) []router.Route {
+	var selected []router.Route
+	for _, route := range routes {
+		if len(route.Specs) == 0 {
+			selected = append(selected, route)
+		}
+	}
+	return selected
+}
+
+// setupSpecDocs serves the documentation of the named specifications, each behind the
+// middlewares of its SpecConfig
+func (a *GoAPI) setupSpecDocs(engine *gin.Engine) []core.SpecLink {
+	var links []core.SpecLink
+	for _, name := range a.specNames() {
+		name := name
+		docsPath, specPath := "/docs/"+name, "/docs/"+name+"/openapi.json"
+		handlers := a.specConfigs[name].Middlewares
+
+		swagger := ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL(specPath))
+		engine.Match(documentationMethods, docsPath, append(slices.Clip(handlers), func(c *gin.Context) {
+			c.Redirect(http.StatusMovedPermanently, docsPath+"/index.html")
+		})...)
+		engine.Match(documentationMethods, docsPath+"/*any", append(slices.Clip(handlers), func(c *gin.Context) {
+			if c.Param("any") == "/openapi.json" {
+				a.serveSpecDocument(c, a.spec.Load().specs[name])
+				return
+			}
+			headAsGet(swagger)(c)
+		})...)
+		engine.Match(documentationMethods, "/redoc/"+name, append(slices.Clip(handlers), core.RedocPageHandler(specPath))...)
+
+		title, _, _ := a.specInfo(specSelection{name: name})
+		links = append(links, core.SpecLink{Name: name, Title: title, DocsURL: docsPath, RedocURL: "/redoc/" + name, SpecURL: specPath})
+	}
+	return links
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiskStorage stores objects as files under a directory
+// Its signed URLs point to Handler, mounted by the plugin, and carry an HMAC of the method, the
+// key and the expiration. Content types are inferred from the key extension
+type DiskStorage struct {
+	Dir    string
+	URL    string // URL Handler is served on; set by the plugin from its Path when empty
+	Secret []byte // Key signing the URLs
+}
+
+// NewDiskStorage creates a storage in dir, signing URLs with secret
+func NewDiskStorage(dir string, secret []byte) *DiskStorage {
+	return &DiskStorage{Dir: dir, Secret: secret}
+}
+
+// Put implements Storage
+// The file is written aside and renamed, so readers never see a partial object
+func (s *DiskStorage) Put(_ context.Context, key string, body io.Reader, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	temporary, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(temporary, body); err != nil {
+		temporary.Close()
+		os.Remove(temporary.Name())
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		os.Remove(temporary.Name())
+		return err
+	}
+	return os.Rename(temporary.Name(), path)
+}
+
+// Get implements Storage
+func (s *DiskStorage) Get(_ context.Context, key string) (io.ReadCloser, Object, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, Object{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, Object{}, err
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, Object{}, ErrNotFound
+	}
+	return file, Object{Key: key, Size: info.Size(), ContentType: mime.TypeByExtension(filepath.Ext(path)), ModTime: info.ModTime()}, nil
+}
+
+// SignedURL implements Storage
+func (s *DiskStorage) SignedURL(_ context.Context, key, method string, expires time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	if len(s.Secret) == 0 {
+		return "", errors.New("storage: DiskStorage needs a Secret to sign URLs")
+	}
+	expiresAt := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	query := url.Values{"expires": {expiresAt}, "signature": {s.sign(method, key, expiresAt)}}
+	return strings.TrimSuffix(s.URL, "/") + "/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// Delete implements Storage
+func (s *DiskStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Handler serves the signed URLs: GET (and HEAD) downloads an object, PUT uploads it
+// The object key is the *key path parameter
+func (s *DiskStorage) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.Param("key"), "/")
+		method := c.Request.Method
+		if method == http.MethodHead {
+			method = http.MethodGet
+		}
+		if !s.verify(method, key, c.Query("expires"), c.Query("signature")) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		if method == http.MethodPut {
+			if err := s.Put(c.Request.Context(), key, c.Request.Body, c.ContentType()); err != nil {
+				_ = c.Error(err)
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			c.Status(http.StatusCreated)
+			return
+		}
+		file, object, err := s.Get(c.Request.Context(), key)
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		defer file.Close()
+		if object.ContentType != "" {
+			c.Header("Content-Type", object.ContentType)
+		}
+		http.ServeContent(c.Writer, c.Request, path.Base(key), object.ModTime, file.(io.ReadSeeker))
+	}
+}
+
+// path returns the file of a key; keys cannot leave the directory
+func (s *DiskStorage) path(key string) (string, error) {
+	clean := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if clean == "" || clean != key {
+		return "", errors.New("storage: invalid key " + strconv.Quote(key))
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(clean)), nil
+}
+
+// sign returns the signature of a URL
+func (s *DiskStorage) sign(method, key, expires string) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(method + "\n" + key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks the signature and the expiration of a URL
+func (s *DiskStorage) verify(method, key, expires, signature string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || len(s.Secret) == 0 || time.Now().Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(method, key, expires)))
+}
+
+// escapeKey escapes the segments of a key for a URL path
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// GCSClient is the part of a Google Cloud Storage client used by GCSStorage, bound to a bucket
+// GoAPI does not depend on the GCS SDK; wrap the bucket handle of the application, for
+// cloud.google.com/go/storage:
+//
+//	type gcsClient struct {
+//		bucket *gcs.BucketHandle
+//	}
+//
+//	func (c gcsClient) NewWriter(ctx context.Context, name, contentType string) io.WriteCloser {
+//		writer := c.bucket.Object(name).NewWriter(ctx)
+//		writer.ContentType = contentType
+//		return writer
+//	}
+//	func (c gcsClient) NewReader(ctx context.Context, name string) (io.ReadCloser, storage.Object, error) {
+//		reader, err := c.bucket.Object(name).NewReader(ctx)
+//		if errors.Is(err, gcs.ErrObjectNotExist) {
+//			return nil, storage.Object{}, storage.ErrNotFound
+//		}
+//		if err != nil {
+//			return nil, storage.Object{}, err
+//		}
+//		return reader, storage.Object{Key: name, Size: reader.Attrs.Size, ContentType: reader.Attrs.ContentType, ModTime: reader.Attrs.LastModified}, nil
+//	}
+//	func (c gcsClient) Delete(ctx context.Context, name string) error {
+//		if err := c.bucket.Object(name).Delete(ctx); !errors.Is(err, gcs.ErrObjectNotExist) {
+//			return err
+//		}
+//		return nil
+//	}
+//	func (c gcsClient) SignedURL(name, method string, expires time.Time) (string, error) {
+//		return c.bucket.SignedURL(name, &gcs.SignedURLOptions{Method: method, Expires: expires, Scheme: gcs.SigningSchemeV4})
+//	}
+type GCSClient interface {
+	// NewWriter returns a writer storing an object, committed when it is closed
+	NewWriter(ctx context.Context, name, contentType string) io.WriteCloser
+	// NewReader returns ErrNotFound for missing objects
+	NewReader(ctx context.Context, name string) (io.ReadCloser, Object, error)
+	// Delete returns nil, or ErrNotFound, for missing objects
+	Delete(ctx context.Context, name string) error
+	SignedURL(name, method string, expires time.Time) (string, error)
+}
+
+// GCSStorage stores objects in a Google Cloud Storage bucket
+type GCSStorage struct {
+	Client GCSClient
+	Prefix string // Prefix of the object names, such as "reports/"
+}
+
+// NewGCSStorage creates a storage on the bucket of client
+func NewGCSStorage(client GCSClient, prefix string) *GCSStorage {
+	return &GCSStorage{Client: client, Prefix: prefix}
+}
+
+// Put implements Storage
+// A failed copy cancels the writer, so the previous object is kept
+func (s *GCSStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	writer := s.Client.NewWriter(ctx, s.Prefix+key, contentType)
+	if _, err := io.Copy(writer, body); err != nil {
+		cancel()
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// Get implements Storage
+func (s *GCSStorage) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	body, object, err := s.Client.NewReader(ctx, s.Prefix+key)
+	object.Key = key
+	return body, object, err
+}
+
+// SignedURL implements Storage
+func (s *GCSStorage) SignedURL(_ context.Context, key, method string, expires time.Duration) (string, error) {
+	return s.Client.SignedURL(s.Prefix+key, method, time.Now().Add(expires))
+}
+
+// Delete implements Storage
+func (s *GCSStorage) Delete(ctx context.Context, key string) error {
+	err := s.Client.Delete(ctx, s.Prefix+key)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
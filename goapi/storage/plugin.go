@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+)
+
+// Plugin registers a Storage as a dependency
+// With a DiskStorage it also serves its signed URLs on {Path}/*key
+type Plugin struct {
+	Storage Storage
+	Path    string   // Path of the signed URLs of a DiskStorage (default "/storage")
+	Tags    []string // Documentation tags of those endpoints
+}
+
+// NewPlugin creates a plugin for a storage
+func NewPlugin(storage Storage) *Plugin {
+	return &Plugin{Storage: storage, Path: "/storage", Tags: []string{"storage"}}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "storage"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	api.RegisterDependency(func(c *gin.Context) (interface{}, error) {
+		return p.Storage, nil
+	}, (*Storage)(nil))
+
+	disk, ok := p.Storage.(*DiskStorage)
+	if !ok {
+		return nil
+	}
+	if disk.URL == "" {
+		disk.URL = api.ServedPath(p.Path)
+	}
+	api.GET(p.Path+"/*key", disk.Handler(),
+		goapi.WithSummary("Download a stored object"),
+		goapi.WithDescription("Serves an object through a signed URL"),
+		goapi.WithTags(p.Tags...),
+		goapi.WithQueryParameter("expires", "integer", "Expiration of the URL, as a Unix time", true),
+		goapi.WithQueryParameter("signature", "string", "Signature of the URL", true),
+		goapi.WithResponse(http.StatusOK, "Content of the object"),
+		goapi.WithResponse(http.StatusForbidden, "Invalid or expired signature"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown object"),
+	)
+	api.PUT(p.Path+"/*key", disk.Handler(),
+		goapi.WithSummary("Upload an object"),
+		goapi.WithDescription("Stores the request body through a signed URL"),
+		goapi.WithTags(p.Tags...),
+		goapi.WithQueryParameter("expires", "integer", "Expiration of the URL, as a Unix time", true),
+		goapi.WithQueryParameter("signature", "string", "Signature of the URL", true),
+		goapi.WithResponse(http.StatusCreated, "Object stored"),
+		goapi.WithResponse(http.StatusForbidden, "Invalid or expired signature"),
+	)
+	return nil
+}
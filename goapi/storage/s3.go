@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// S3Client is the part of an S3 client used by S3Storage, bound to a bucket
+// GoAPI does not depend on an S3 SDK; wrap the client of the application, for aws-sdk-go-v2:
+//
+//	type s3Client struct {
+//		client   *s3.Client
+//		uploader *manager.Uploader
+//		presign  *s3.PresignClient
+//		bucket   string
+//	}
+//
+//	func (c s3Client) PutObject(ctx context.Context, key string, body io.Reader, contentType string) error {
+//		_, err := c.uploader.Upload(ctx, &s3.PutObjectInput{Bucket: &c.bucket, Key: &key, Body: body, ContentType: &contentType})
+//		return err
+//	}
+//	func (c s3Client) GetObject(ctx context.Context, key string) (io.ReadCloser, storage.Object, error) {
+//		output, err := c.client.GetObject(ctx, &s3.GetObjectInput{Bucket: &c.bucket, Key: &key})
+//		var noKey *types.NoSuchKey
+//		if errors.As(err, &noKey) {
+//			return nil, storage.Object{}, storage.ErrNotFound
+//		}
+//		if err != nil {
+//			return nil, storage.Object{}, err
+//		}
+//		return output.Body, storage.Object{Key: key, Size: *output.ContentLength, ContentType: *output.ContentType, ModTime: *output.LastModified}, nil
+//	}
+//	func (c s3Client) PresignURL(ctx context.Context, method, key string, expires time.Duration) (string, error) {
+//		var request *v4.PresignedHTTPRequest
+//		var err error
+//		if method == http.MethodPut {
+//			request, err = c.presign.PresignPutObject(ctx, &s3.PutObjectInput{Bucket: &c.bucket, Key: &key}, s3.WithPresignExpires(expires))
+//		} else {
+//			request, err = c.presign.PresignGetObject(ctx, &s3.GetObjectInput{Bucket: &c.bucket, Key: &key}, s3.WithPresignExpires(expires))
+//		}
+//		if err != nil {
+//			return "", err
+//		}
+//		return request.URL, nil
+//	}
+//	// DeleteObject calls the method of the same name
+type S3Client interface {
+	PutObject(ctx context.Context, key string, body io.Reader, contentType string) error
+	// GetObject returns ErrNotFound for missing keys
+	GetObject(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	DeleteObject(ctx context.Context, key string) error
+	PresignURL(ctx context.Context, method, key string, expires time.Duration) (string, error)
+}
+
+// S3Storage stores objects in an S3 bucket, or any S3-compatible service (MinIO, R2...)
+type S3Storage struct {
+	Client S3Client
+	Prefix string // Prefix of the object keys, such as "reports/"
+}
+
+// NewS3Storage creates a storage on the bucket of client
+func NewS3Storage(client S3Client, prefix string) *S3Storage {
+	return &S3Storage{Client: client, Prefix: prefix}
+}
+
+// Put implements Storage
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	return s.Client.PutObject(ctx, s.Prefix+key, body, contentType)
+}
+
+// Get implements Storage
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	body, object, err := s.Client.GetObject(ctx, s.Prefix+key)
+	object.Key = key
+	return body, object, err
+}
+
+// SignedURL implements Storage
+func (s *S3Storage) SignedURL(ctx context.Context, key, method string, expires time.Duration) (string, error) {
+	return s.Client.PresignURL(ctx, method, s.Prefix+key, expires)
+}
+
+// Delete implements Storage
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	return s.Client.DeleteObject(ctx, s.Prefix+key)
+}
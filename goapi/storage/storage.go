@@ -0,0 +1,46 @@
+// Package storage stores files in object storage behind one interface, with local disk, S3 and
+// Google Cloud Storage implementations
+// The plugin registers the storage as a dependency, and responses.SignedRedirect sends clients
+// to a signed URL so large downloads do not go through the API:
+//
+//	api.UsePlugin(storage.NewPlugin(storage.NewS3Storage(s3Client, "reports/")))
+//
+//	api.GET("/reports/:id/download", func(c *gin.Context) {
+//		var files storage.Storage
+//		if err := api.GetDependencyContainer().Resolve(c, &files); err != nil {
+//			...
+//		}
+//		responses.SignedRedirect(c, files, c.Param("id")+".pdf", 5*time.Minute)
+//	})
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned for missing objects
+var ErrNotFound = errors.New("object not found")
+
+// Object describes a stored object
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage stores objects by key; keys use "/" as separator ("invoices/2024/42.pdf")
+type Storage interface {
+	// Put stores body under key, replacing the previous object
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Get returns the content of an object, or ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	// SignedURL returns a URL granting method (GET to download, PUT to upload) on key until
+	// expires elapses, without credentials
+	SignedURL(ctx context.Context, key, method string, expires time.Duration) (string, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
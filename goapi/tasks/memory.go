@@ -0,0 +1,104 @@
+package tasks
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryQueue keeps tasks in process, ordered by RunAt
+// Waiting tasks are lost when the process exits; it is meant for single-instance deployments,
+// development and tests
+type MemoryQueue struct {
+	mutex  sync.Mutex
+	tasks  taskHeap
+	wake   chan struct{} // Closed and replaced on every Push, waking the waiting workers
+	closed bool
+}
+
+// NewMemoryQueue creates an empty in-process queue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{wake: make(chan struct{})}
+}
+
+// Push implements Queue
+func (m *MemoryQueue) Push(_ context.Context, task Task) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	heap.Push(&m.tasks, task)
+	close(m.wake)
+	m.wake = make(chan struct{})
+	return nil
+}
+
+// Pop implements Queue
+func (m *MemoryQueue) Pop(ctx context.Context) (Task, error) {
+	for {
+		m.mutex.Lock()
+		if m.closed {
+			m.mutex.Unlock()
+			return Task{}, ErrClosed
+		}
+		var timer <-chan time.Time
+		if len(m.tasks) > 0 {
+			wait := time.Until(m.tasks[0].RunAt)
+			if wait <= 0 {
+				task := heap.Pop(&m.tasks).(Task)
+				m.mutex.Unlock()
+				return task, nil
+			}
+			timer = time.After(wait)
+		}
+		wake := m.wake
+		m.mutex.Unlock()
+
+		select {
+		case <-wake:
+		case <-timer:
+		case <-ctx.Done():
+			return Task{}, ctx.Err()
+		}
+	}
+}
+
+// Len implements Queue
+func (m *MemoryQueue) Len(_ context.Context) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.tasks), nil
+}
+
+// Close drops the waiting tasks; Push and Pop then return ErrClosed
+func (m *MemoryQueue) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if !m.closed {
+		m.closed = true
+		m.tasks = nil
+		close(m.wake)
+	}
+	return nil
+}
+
+// taskHeap orders tasks by RunAt, then by enqueue time
+type taskHeap []Task
+
+func (h taskHeap) Len() int { return len(h) }
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].RunAt.Equal(h[j].RunAt) {
+		return h[i].EnqueuedAt.Before(h[j].EnqueuedAt)
+	}
+	return h[i].RunAt.Before(h[j].RunAt)
+}
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *taskHeap) Push(x any)   { *h = append(*h, x.(Task)) }
+func (h *taskHeap) Pop() any {
+	old := *h
+	task := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return task
+}
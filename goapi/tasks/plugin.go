@@ -0,0 +1,320 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+)
+
+// defaultPlugin is the last installed plugin, used by the package-level Enqueue
+var defaultPlugin atomic.Pointer[Plugin]
+
+// Enqueue adds a task to the queue of the installed plugin
+func Enqueue(ctx context.Context, name string, payload interface{}) (string, error) {
+	plugin := defaultPlugin.Load()
+	if plugin == nil {
+		return "", ErrNotInstalled
+	}
+	return plugin.Enqueue(ctx, name, payload)
+}
+
+// Option configures a registered task
+type Option func(*registration)
+
+// WithMaxRetries overrides the retries of the plugin for a task
+func WithMaxRetries(retries int) Option {
+	return func(r *registration) { r.maxRetries = retries }
+}
+
+// WithTimeout overrides the timeout of the plugin for a task
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *registration) { r.timeout = timeout }
+}
+
+// WithConcurrency limits how many tasks of a name run at the same time on this instance
+// Due tasks over the limit are delayed, leaving the workers to other tasks
+func WithConcurrency(limit int) Option {
+	return func(r *registration) { r.limit = limit }
+}
+
+// registration is a registered handler and its settings
+type registration struct {
+	handler    Handler
+	maxRetries int
+	timeout    time.Duration
+	limit      int
+	running    atomic.Int64
+}
+
+// Stats is a snapshot of the pool metrics
+// Counters are totals since the server started on this instance
+type Stats struct {
+	Queued    int   `json:"queued"`    // Tasks waiting in the queue, -1 when the queue failed to answer
+	Running   int64 `json:"running"`   // Tasks being handled
+	Enqueued  int64 `json:"enqueued"`  // Tasks added with Enqueue
+	Succeeded int64 `json:"succeeded"` // Tasks handled without error
+	Retried   int64 `json:"retried"`   // Failed attempts scheduled again
+	Failed    int64 `json:"failed"`    // Tasks that failed on their last attempt or with a permanent error
+}
+
+// Plugin runs the registered task handlers on a pool of workers
+// Workers start when the server starts; on shutdown they stop picking tasks, the tasks being
+// handled get until the shutdown deadline and those still running are requeued
+type Plugin struct {
+	Queue        Queue
+	Concurrency  int           // Workers of this instance; 0 only enqueues (default 4)
+	MaxRetries   int           // Retries of a failed task (default 3)
+	Backoff      time.Duration // Wait before the first retry, doubled on each one (default 1s)
+	MaxBackoff   time.Duration // Maximum wait between attempts (default 5m)
+	Timeout      time.Duration // Maximum duration of an attempt; 0 means no limit
+	PollInterval time.Duration // Wait after a queue error before polling again (default 1s)
+	OnError      func(task *Task, err error)
+
+	handlers map[string]*registration
+	cancel   context.CancelFunc // Stops picking tasks
+	abort    context.CancelFunc // Cancels the tasks being handled
+	running  sync.WaitGroup
+
+	enqueued, succeeded, retried, failed, active atomic.Int64
+}
+
+// NewPlugin creates a worker pool on a queue; a nil queue defaults to an in-memory queue
+// Tasks that fail on their last attempt are logged by default
+func NewPlugin(queue Queue) *Plugin {
+	if queue == nil {
+		queue = NewMemoryQueue()
+	}
+	return &Plugin{
+		Queue:        queue,
+		Concurrency:  4,
+		MaxRetries:   3,
+		Backoff:      time.Second,
+		MaxBackoff:   5 * time.Minute,
+		PollInterval: time.Second,
+		OnError: func(task *Task, err error) {
+			log.Printf("[tasks] %s %s failed after %d attempts: %v", task.Name, task.ID, task.Attempt+1, err)
+		},
+		handlers: make(map[string]*registration),
+	}
+}
+
+// Register sets the handler of a task name; register every task before the server starts
+// Panics of the handler are recovered as errors
+func (p *Plugin) Register(name string, handler Handler, options ...Option) *Plugin {
+	r := &registration{handler: handler, maxRetries: -1, timeout: -1}
+	for _, option := range options {
+		option(r)
+	}
+	p.handlers[name] = r
+	return p
+}
+
+// Enqueue adds a task to the queue, returning its ID; the payload is encoded as JSON
+func (p *Plugin) Enqueue(ctx context.Context, name string, payload interface{}) (string, error) {
+	return p.EnqueueIn(ctx, 0, name, payload)
+}
+
+// EnqueueIn adds a task to run once the delay has passed
+func (p *Plugin) EnqueueIn(ctx context.Context, delay time.Duration, name string, payload interface{}) (string, error) {
+	if _, exists := p.handlers[name]; !exists {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTask, name)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	task := Task{ID: middleware.NewRequestID(), Name: name, Payload: data, EnqueuedAt: now, RunAt: now.Add(delay)}
+	if err := p.Queue.Push(ctx, task); err != nil {
+		return "", err
+	}
+	p.enqueued.Add(1)
+	return task.ID, nil
+}
+
+// Stats returns a snapshot of the pool metrics
+func (p *Plugin) Stats(ctx context.Context) Stats {
+	queued, err := p.Queue.Len(ctx)
+	if err != nil {
+		queued = -1
+	}
+	return Stats{
+		Queued:    queued,
+		Running:   p.active.Load(),
+		Enqueued:  p.enqueued.Load(),
+		Succeeded: p.succeeded.Load(),
+		Retried:   p.retried.Load(),
+		Failed:    p.failed.Load(),
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "tasks"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	defaultPlugin.Store(p)
+	api.RegisterDependency(func(c *gin.Context) (interface{}, error) {
+		return p, nil
+	}, (*Plugin)(nil))
+	return nil
+}
+
+// OnStartup implements goapi.StartupPlugin
+func (p *Plugin) OnStartup(_ context.Context) error {
+	// Los workers viven hasta el apagado, no hasta el fin del contexto de arranque
+	polling, cancel := context.WithCancel(context.Background())
+	working, abort := context.WithCancel(context.Background())
+	p.cancel, p.abort = cancel, abort
+	for range p.Concurrency {
+		p.running.Add(1)
+		go p.work(polling, working)
+	}
+	return nil
+}
+
+// OnShutdown implements goapi.ShutdownPlugin
+func (p *Plugin) OnShutdown(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	stopped := make(chan struct{})
+	go func() {
+		p.running.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		p.abort()
+		<-stopped
+		return errors.New("tasks: tasks still running at shutdown were requeued")
+	}
+}
+
+// work picks and handles tasks until polling is canceled
+func (p *Plugin) work(polling, working context.Context) {
+	defer p.running.Done()
+	for {
+		task, err := p.Queue.Pop(polling)
+		if polling.Err() != nil {
+			if err == nil {
+				p.requeue(task) // Reclamada justo al empezar el apagado
+			}
+			return
+		}
+		if err != nil {
+			log.Printf("[tasks] queue: %v", err)
+			select {
+			case <-time.After(p.PollInterval):
+			case <-polling.Done():
+				return
+			}
+			continue
+		}
+		p.handle(working, task)
+	}
+}
+
+// handle runs an attempt of a task and schedules its retry when it fails
+func (p *Plugin) handle(ctx context.Context, task Task) {
+	r, exists := p.handlers[task.Name]
+	if !exists {
+		p.fail(&task, fmt.Errorf("%w: %s", ErrUnknownTask, task.Name))
+		return
+	}
+	if running := r.running.Add(1); r.limit > 0 && running > int64(r.limit) {
+		r.running.Add(-1)
+		task.RunAt = time.Now().Add(p.PollInterval)
+		p.requeue(task)
+		return
+	}
+	defer r.running.Add(-1)
+
+	timeout := p.Timeout
+	if r.timeout >= 0 {
+		timeout = r.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	p.active.Add(1)
+	err := runHandler(ctx, r.handler, &task)
+	p.active.Add(-1)
+
+	maxRetries := p.MaxRetries
+	if r.maxRetries >= 0 {
+		maxRetries = r.maxRetries
+	}
+	switch {
+	case err == nil:
+		p.succeeded.Add(1)
+	case errors.Is(ctx.Err(), context.Canceled):
+		// El apagado interrumpió la tarea: vuelve a la cola sin gastar un intento
+		p.requeue(task)
+	case IsPermanent(err) || task.Attempt >= maxRetries:
+		p.fail(&task, err)
+	default:
+		task.RunAt = time.Now().Add(p.backoff(task.Attempt))
+		task.Attempt++
+		p.retried.Add(1)
+		p.requeue(task)
+	}
+}
+
+// runHandler calls a handler, turning a panic into an error
+func runHandler(ctx context.Context, handler Handler, task *Task) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, task)
+}
+
+// fail reports a task that will not be retried
+func (p *Plugin) fail(task *Task, err error) {
+	p.failed.Add(1)
+	if p.OnError != nil {
+		p.OnError(task, err)
+	}
+}
+
+// requeue pushes a task back to the queue
+func (p *Plugin) requeue(task Task) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Queue.Push(ctx, task); err != nil {
+		log.Printf("[tasks] requeuing %s %s: %v", task.Name, task.ID, err)
+	}
+}
+
+// backoff returns the wait before the retry following an attempt
+func (p *Plugin) backoff(attempt int) time.Duration {
+	wait := p.Backoff
+	for range attempt {
+		if wait >= p.MaxBackoff {
+			break
+		}
+		wait *= 2
+	}
+	return min(wait, p.MaxBackoff)
+}
@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// RedisClient is the part of a Redis client used by RedisQueue
+// GoAPI does not depend on a Redis driver; wrap the one of the application, for go-redis:
+//
+//	type redisClient struct{ *redis.Client }
+//
+//	func (r redisClient) ZAdd(ctx context.Context, key string, score float64, member string) error {
+//		return r.Client.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+//	}
+//	func (r redisClient) ZRangeByScore(ctx context.Context, key string, max float64, count int) ([]string, error) {
+//		return r.Client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+//			Min: "-inf", Max: strconv.FormatFloat(max, 'f', -1, 64), Count: int64(count),
+//		}).Result()
+//	}
+//	func (r redisClient) ZRem(ctx context.Context, key, member string) (bool, error) {
+//		removed, err := r.Client.ZRem(ctx, key, member).Result()
+//		return removed > 0, err
+//	}
+//	func (r redisClient) ZCard(ctx context.Context, key string) (int, error) {
+//		count, err := r.Client.ZCard(ctx, key).Result()
+//		return int(count), err
+//	}
+type RedisClient interface {
+	ZAdd(ctx context.Context, key string, score float64, member string) error
+	// ZRangeByScore returns up to count members with a score lower than or equal to max
+	ZRangeByScore(ctx context.Context, key string, max float64, count int) ([]string, error)
+	// ZRem reports whether the member was removed, i.e. was still in the set
+	ZRem(ctx context.Context, key, member string) (bool, error)
+	ZCard(ctx context.Context, key string) (int, error)
+}
+
+// RedisQueue keeps tasks in a Redis sorted set scored by RunAt, shared by every instance
+// A worker claims a task by removing it from the set, so each task runs on one instance; a task
+// claimed by an instance that crashes before finishing it is lost
+type RedisQueue struct {
+	Client       RedisClient
+	Key          string        // Sorted set of the queue (default "tasks")
+	PollInterval time.Duration // Wait between polls while no task is due (default 1s)
+}
+
+// NewRedisQueue creates a queue using a Redis client
+func NewRedisQueue(client RedisClient) *RedisQueue {
+	return &RedisQueue{Client: client, Key: "tasks", PollInterval: time.Second}
+}
+
+// Push implements Queue
+func (r *RedisQueue) Push(ctx context.Context, task Task) error {
+	member, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	return r.Client.ZAdd(ctx, r.Key, score(task.RunAt), string(member))
+}
+
+// Pop implements Queue
+func (r *RedisQueue) Pop(ctx context.Context) (Task, error) {
+	for {
+		members, err := r.Client.ZRangeByScore(ctx, r.Key, score(time.Now()), 10)
+		if err != nil {
+			return Task{}, err
+		}
+		for _, member := range members {
+			// Otra instancia puede haber reclamado la tarea entre la lectura y el borrado
+			claimed, err := r.Client.ZRem(ctx, r.Key, member)
+			if err != nil {
+				return Task{}, err
+			}
+			if !claimed {
+				continue
+			}
+			var task Task
+			if err := json.Unmarshal([]byte(member), &task); err != nil {
+				return Task{}, err
+			}
+			return task, nil
+		}
+
+		select {
+		case <-time.After(r.PollInterval):
+		case <-ctx.Done():
+			return Task{}, ctx.Err()
+		}
+	}
+}
+
+// Len implements Queue
+func (r *RedisQueue) Len(ctx context.Context) (int, error) {
+	return r.Client.ZCard(ctx, r.Key)
+}
+
+// score returns the sorted set score of a time, in Unix milliseconds
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
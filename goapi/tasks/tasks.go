@@ -0,0 +1,86 @@
+// Package tasks runs background jobs on a pool of workers
+// Handlers enqueue a task by name with a JSON payload; the workers of the Plugin pick it from a
+// Queue, call the handler registered for the name and retry failed tasks with exponential
+// backoff. MemoryQueue runs in process; RedisQueue shares the tasks between the instances of a
+// deployment. Workers start and stop with the API
+//
+//	pool := tasks.NewPlugin(nil)
+//	pool.Register("email.welcome", func(ctx context.Context, task *tasks.Task) error {
+//		var user User
+//		if err := task.Bind(&user); err != nil {
+//			return tasks.Permanent(err)
+//		}
+//		return sendWelcome(ctx, user)
+//	})
+//	api.UsePlugin(pool)
+//
+//	api.POST("/users", func(c *gin.Context) {
+//		...
+//		tasks.Enqueue(c.Request.Context(), "email.welcome", user)
+//	})
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrUnknownTask is returned when enqueuing a task without a registered handler
+	ErrUnknownTask = errors.New("unknown task")
+	// ErrNotInstalled is returned by Enqueue before a Plugin is installed
+	ErrNotInstalled = errors.New("tasks plugin not installed")
+	// ErrClosed is returned by queues that were closed
+	ErrClosed = errors.New("queue is closed")
+)
+
+// Task is a unit of work and its delivery state
+type Task struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Attempt    int             `json:"attempt"` // Retries already done (0 on the first run)
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+	RunAt      time.Time       `json:"run_at"` // The task is not picked before this time
+}
+
+// Bind decodes the payload of the task
+func (t *Task) Bind(target interface{}) error {
+	return json.Unmarshal(t.Payload, target)
+}
+
+// Handler processes a task; an error schedules a retry until the attempts run out
+// ctx is canceled when the task times out or the shutdown deadline is reached
+type Handler func(ctx context.Context, task *Task) error
+
+// permanentError marks an error that must not be retried
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps an error so the task fails without retries, e.g. for an invalid payload
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether an error was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// Queue stores the tasks waiting to run
+type Queue interface {
+	// Push adds a task, to be picked once its RunAt time is reached
+	Push(ctx context.Context, task Task) error
+	// Pop waits for the next due task until ctx is canceled; each task is returned once
+	Pop(ctx context.Context) (Task, error)
+	// Len returns the number of waiting tasks, due or scheduled
+	Len(ctx context.Context) (int, error)
+}
@@ -0,0 +1,170 @@
+package uploads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DiskStorage keeps uploads in a directory: the data in a file named after the upload ID and
+// the state next to it, in <id>.json
+// The stored offset is the size of the data file, so bytes written before a crash are kept
+type DiskStorage struct {
+	Dir string
+}
+
+// NewDiskStorage creates a storage in dir, created on the first upload
+func NewDiskStorage(dir string) *DiskStorage {
+	return &DiskStorage{Dir: dir}
+}
+
+// Create implements Storage
+func (s *DiskStorage) Create(_ context.Context, upload Upload) error {
+	path, err := s.path(upload.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o750); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	upload.Location = path
+	return s.save(upload)
+}
+
+// Get implements Storage
+func (s *DiskStorage) Get(_ context.Context, id string) (Upload, error) {
+	return s.load(id)
+}
+
+// Append implements Storage
+func (s *DiskStorage) Append(_ context.Context, id string, offset int64, data io.Reader) (Upload, error) {
+	upload, err := s.load(id)
+	if err != nil {
+		return Upload{}, err
+	}
+	if upload.Complete {
+		return upload, ErrUploadComplete
+	}
+	if upload.Offset != offset {
+		return upload, ErrOffsetMismatch
+	}
+
+	file, err := os.OpenFile(upload.Location, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return upload, err
+	}
+	written, copyErr := io.Copy(file, data)
+	closeErr := file.Close()
+	upload.Offset += written
+	upload.UpdatedAt = time.Now()
+	if err := errors.Join(copyErr, closeErr); err != nil {
+		// Lo escrito se conserva: el cliente reanuda desde el nuevo offset
+		_ = s.save(upload)
+		return upload, err
+	}
+	return upload, s.save(upload)
+}
+
+// Finish implements Storage
+func (s *DiskStorage) Finish(_ context.Context, id string) (Upload, error) {
+	upload, err := s.load(id)
+	if err != nil {
+		return Upload{}, err
+	}
+	upload.Size, upload.Complete, upload.UpdatedAt = upload.Offset, true, time.Now()
+	return upload, s.save(upload)
+}
+
+// Open implements Storage
+func (s *DiskStorage) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	upload, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(upload.Location)
+}
+
+// Delete implements Storage
+func (s *DiskStorage) Delete(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	err = errors.Join(os.Remove(path), os.Remove(path+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrUploadNotFound
+	}
+	return err
+}
+
+// path returns the data file of an upload; IDs cannot leave the directory
+func (s *DiskStorage) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", ErrUploadNotFound
+	}
+	return filepath.Join(s.Dir, id), nil
+}
+
+// load reads the state of an upload, taking the offset from the data file
+func (s *DiskStorage) load(id string) (Upload, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return Upload{}, err
+	}
+	data, err := os.ReadFile(path + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return Upload{}, ErrUploadNotFound
+	}
+	if err != nil {
+		return Upload{}, err
+	}
+	var upload Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return Upload{}, err
+	}
+	upload.Location = path
+	if !upload.Complete {
+		info, err := os.Stat(path)
+		if err != nil {
+			return Upload{}, err
+		}
+		upload.Offset = info.Size()
+	}
+	return upload, nil
+}
+
+// save writes the state of an upload, replacing the previous one atomically
+func (s *DiskStorage) save(upload Upload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	path := upload.Location + ".json"
+	temporary, err := os.CreateTemp(s.Dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := temporary.Write(data); err != nil {
+		temporary.Close()
+		os.Remove(temporary.Name())
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		os.Remove(temporary.Name())
+		return err
+	}
+	return os.Rename(temporary.Name(), path)
+}
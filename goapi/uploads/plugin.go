@@ -0,0 +1,337 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/responses"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// CreateRequest is the body of POST {path}
+type CreateRequest struct {
+	// Size in bytes; without it the client completes the upload with POST {path}/{id}/complete
+	Size     *int64            `json:"size,omitempty" validate:"omitempty,min=0"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Plugin serves resumable uploads on Path
+type Plugin struct {
+	Path        string               // Path of the upload endpoints (default "/uploads")
+	Tags        []string             // Documentation tags of the endpoints
+	MaxSize     int64                // Largest upload accepted, in bytes (default 1 GB)
+	Middlewares []gin.HandlerFunc    // Run before the endpoints, for example to authenticate
+	Options     []router.RouteOption // Added to every endpoint, such as goapi.WithSpec or a security requirement
+	// OnProgress is called after every appended chunk
+	OnProgress func(upload Upload)
+	// OnComplete is called once an upload is complete, before answering the last request; an
+	// error is answered 500, and the upload stays complete
+	OnComplete func(ctx context.Context, upload Upload) error
+
+	storage Storage
+	api     *goapi.GoAPI
+	mutex   sync.Mutex
+	busy    map[string]bool // Uploads with a request in progress
+}
+
+// NewPlugin creates a plugin serving /uploads
+// A nil storage defaults to a DiskStorage in the temporary directory
+func NewPlugin(storage Storage) *Plugin {
+	if storage == nil {
+		storage = NewDiskStorage(filepath.Join(os.TempDir(), "goapi-uploads"))
+	}
+	return &Plugin{
+		Path:    "/uploads",
+		Tags:    []string{"uploads"},
+		MaxSize: 1 << 30,
+		storage: storage,
+		busy:    make(map[string]bool),
+	}
+}
+
+// Name implements goapi.Plugin
+func (p *Plugin) Name() string {
+	return "uploads"
+}
+
+// Install implements goapi.Plugin
+func (p *Plugin) Install(api *goapi.GoAPI) error {
+	p.api = api
+	offsetHeader := goapi.WithParameter("Upload-Offset", "header", "integer", "Offset the chunk starts at: the offset of the upload", true)
+	idParameter := goapi.WithPathParameter("id", "string", "Upload ID")
+
+	api.POST(p.Path, p.create, p.options(
+		goapi.WithSummary("Create an upload"),
+		goapi.WithDescription(fmt.Sprintf("Creates a resumable upload of up to %d bytes. Send the data with PATCH on the URL of the Location header", p.MaxSize)),
+		goapi.WithRequestBody(CreateRequest{}, "Size and metadata of the upload"),
+		goapi.WithResponseModel(http.StatusCreated, Upload{}, "Upload created"),
+		goapi.WithResponse(http.StatusRequestEntityTooLarge, "Size over the limit"),
+	)...)
+	api.GET(p.Path+"/:id", p.get, p.options(
+		goapi.WithSummary("Get an upload"),
+		goapi.WithDescription("Returns the state and progress of an upload. The Upload-Offset header is the offset to resume from; HEAD returns only the headers"),
+		idParameter,
+		goapi.WithResponseModel(http.StatusOK, Upload{}, "State of the upload"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown upload"),
+	)...)
+	api.PATCH(p.Path+"/:id", p.append, p.options(
+		goapi.WithSummary("Append a chunk"),
+		goapi.WithDescription("Appends the request body at Upload-Offset. When a chunk fails, get the upload and resend from its offset. "+
+			"Uploads with a declared size complete with their last byte"),
+		idParameter,
+		offsetHeader,
+		goapi.WithConsumes("application/offset+octet-stream", "application/octet-stream"),
+		goapi.WithResponseModel(http.StatusOK, Upload{}, "Chunk stored"),
+		goapi.WithResponse(http.StatusConflict, "Upload-Offset is not the offset of the upload, the upload is complete or another chunk is being sent"),
+		goapi.WithResponse(http.StatusRequestEntityTooLarge, "Chunk past the size of the upload"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown upload"),
+	)...)
+	api.POST(p.Path+"/:id/complete", p.complete, p.options(
+		goapi.WithSummary("Complete an upload"),
+		goapi.WithDescription("Completes an upload with the bytes received so far. Uploads with a declared size complete on their own"),
+		idParameter,
+		goapi.WithResponseModel(http.StatusOK, Upload{}, "Upload completed"),
+		goapi.WithResponse(http.StatusConflict, "Bytes missing for the declared size"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown upload"),
+	)...)
+	api.DELETE(p.Path+"/:id", p.delete, p.options(
+		goapi.WithSummary("Delete an upload"),
+		goapi.WithDescription("Aborts an upload, or deletes a complete one, with its data"),
+		idParameter,
+		goapi.WithResponse(http.StatusNoContent, "Upload deleted"),
+		goapi.WithResponse(http.StatusNotFound, "Unknown upload"),
+	)...)
+	return nil
+}
+
+// Storage returns the storage of the plugin, to read complete uploads
+func (p *Plugin) Storage() Storage {
+	return p.storage
+}
+
+// options adds the common options of the endpoints
+func (p *Plugin) options(options ...router.RouteOption) []router.RouteOption {
+	options = append(options, goapi.WithTags(p.Tags...), goapi.WithMiddleware(p.Middlewares...))
+	return append(options, p.Options...)
+}
+
+// create handles POST {path}
+func (p *Plugin) create(c *gin.Context) {
+	var request CreateRequest
+	if c.Request.ContentLength != 0 {
+		if !goapi.DecodeJSONBody(c, &request) {
+			return
+		}
+	}
+	size := int64(-1)
+	if request.Size != nil {
+		if *request.Size < 0 {
+			responses.BadRequest(c, "The size cannot be negative")
+			return
+		}
+		size = *request.Size
+	}
+	if size > p.MaxSize {
+		responses.Message(c, http.StatusRequestEntityTooLarge, i18n.KeyPayloadTooLarge)
+		return
+	}
+
+	now := time.Now()
+	upload := Upload{ID: middleware.NewRequestID(), Size: size, Metadata: request.Metadata, CreatedAt: now, UpdatedAt: now}
+	if err := p.storage.Create(c.Request.Context(), upload); err != nil {
+		log.Printf("[GoAPI] uploads: creating upload: %v", err)
+		responses.InternalServerError(c, "Error creating the upload")
+		return
+	}
+	c.Header("Location", p.api.ServedPath(p.Path+"/"+upload.ID))
+	if size == 0 {
+		var err error
+		if upload, err = p.finish(c, upload.ID); err != nil {
+			return
+		}
+	}
+	p.send(c, http.StatusCreated, upload)
+}
+
+// get handles GET and HEAD {path}/:id
+func (p *Plugin) get(c *gin.Context) {
+	upload, err := p.storage.Get(c.Request.Context(), c.Param("id"))
+	if p.sendStorageError(c, err) {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	p.send(c, http.StatusOK, upload)
+}
+
+// append handles PATCH {path}/:id
+func (p *Plugin) append(c *gin.Context) {
+	id := c.Param("id")
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		responses.BadRequest(c, "The Upload-Offset header must be the offset of the upload")
+		return
+	}
+	if !p.lock(c, id) {
+		return
+	}
+	defer p.unlock(id)
+
+	ctx := c.Request.Context()
+	upload, err := p.storage.Get(ctx, id)
+	if p.sendStorageError(c, err) {
+		return
+	}
+	limit := p.MaxSize - offset
+	if upload.Size >= 0 {
+		limit = upload.Size - offset
+	}
+	if limit <= 0 && offset == upload.Offset && !upload.Complete {
+		responses.Message(c, http.StatusRequestEntityTooLarge, i18n.KeyPayloadTooLarge)
+		return
+	}
+
+	// El cuerpo se transmite al almacenamiento sin cargarlo en memoria
+	body := goapi.BodyReader(c, goapi.WithBodyLimit(max(limit, 1)))
+	upload, err = p.storage.Append(ctx, id, offset, body)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		// Los bytes hasta el tamaño declarado se guardaron: la carga se completa igualmente
+		if upload.Offset == upload.Size {
+			if upload, err = p.finish(c, id); err != nil {
+				return
+			}
+		}
+		c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		responses.Message(c, http.StatusRequestEntityTooLarge, i18n.KeyPayloadTooLarge)
+		return
+	case p.sendStorageError(c, err):
+		return
+	}
+	if p.OnProgress != nil {
+		p.OnProgress(upload)
+	}
+	if upload.Size >= 0 && upload.Offset == upload.Size {
+		if upload, err = p.finish(c, id); err != nil {
+			return
+		}
+	}
+	p.send(c, http.StatusOK, upload)
+}
+
+// complete handles POST {path}/:id/complete
+func (p *Plugin) complete(c *gin.Context) {
+	id := c.Param("id")
+	if !p.lock(c, id) {
+		return
+	}
+	defer p.unlock(id)
+
+	upload, err := p.storage.Get(c.Request.Context(), id)
+	if p.sendStorageError(c, err) {
+		return
+	}
+	if upload.Complete {
+		p.send(c, http.StatusOK, upload)
+		return
+	}
+	if upload.Size >= 0 && upload.Offset < upload.Size {
+		c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		responses.Conflict(c, fmt.Sprintf("%d of %d bytes received", upload.Offset, upload.Size))
+		return
+	}
+	if upload, err = p.finish(c, id); err == nil {
+		p.send(c, http.StatusOK, upload)
+	}
+}
+
+// delete handles DELETE {path}/:id
+func (p *Plugin) delete(c *gin.Context) {
+	id := c.Param("id")
+	if !p.lock(c, id) {
+		return
+	}
+	defer p.unlock(id)
+
+	if p.sendStorageError(c, p.storage.Delete(c.Request.Context(), id)) {
+		return
+	}
+	responses.NoContent(c)
+}
+
+// finish completes an upload and calls OnComplete; on error the response is already sent
+func (p *Plugin) finish(c *gin.Context, id string) (Upload, error) {
+	upload, err := p.storage.Finish(c.Request.Context(), id)
+	if p.sendStorageError(c, err) {
+		return upload, err
+	}
+	if p.OnComplete != nil {
+		if err := p.OnComplete(c.Request.Context(), upload); err != nil {
+			log.Printf("[GoAPI] uploads: completing upload %s: %v", id, err)
+			responses.InternalServerError(c, "Error processing the upload")
+			return upload, err
+		}
+	}
+	return upload, nil
+}
+
+// lock reserves an upload for a request, answering 409 when another request holds it
+func (p *Plugin) lock(c *gin.Context, id string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.busy[id] {
+		responses.Conflict(c, "Another request is in progress for this upload")
+		return false
+	}
+	p.busy[id] = true
+	return true
+}
+
+// unlock releases an upload
+func (p *Plugin) unlock(id string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.busy, id)
+}
+
+// send answers an upload with its offset and size headers
+func (p *Plugin) send(c *gin.Context, status int, upload Upload) {
+	upload.Progress = upload.percent()
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	if upload.Size >= 0 {
+		c.Header("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	}
+	c.JSON(status, upload)
+}
+
+// sendStorageError answers a storage error, reporting whether there was one
+func (p *Plugin) sendStorageError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, ErrUploadNotFound):
+		responses.NotFound(c, "Upload not found")
+	case errors.Is(err, ErrOffsetMismatch), errors.Is(err, ErrUploadComplete):
+		if upload, getErr := p.storage.Get(c.Request.Context(), c.Param("id")); getErr == nil {
+			c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		}
+		responses.Conflict(c, "Upload-Offset does not match the offset of the upload, or the upload is complete")
+	default:
+		log.Printf("[GoAPI] uploads: %v", err)
+		responses.InternalServerError(c, "Error storing the upload")
+	}
+	return true
+}
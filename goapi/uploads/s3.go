@@ -0,0 +1,271 @@
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// S3Part is an uploaded part of a multipart upload
+type S3Part struct {
+	Number int32  `json:"number"`
+	ETag   string `json:"etag"`
+}
+
+// S3Client is the part of an S3 client used by S3Storage, bound to a bucket
+// GoAPI does not depend on an S3 SDK; wrap the client of the application, for aws-sdk-go-v2:
+//
+//	type s3Client struct {
+//		client *s3.Client
+//		bucket string
+//	}
+//
+//	func (c s3Client) PutObject(ctx context.Context, key string, body []byte) error {
+//		_, err := c.client.PutObject(ctx, &s3.PutObjectInput{Bucket: &c.bucket, Key: &key, Body: bytes.NewReader(body)})
+//		return err
+//	}
+//	func (c s3Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+//		output, err := c.client.GetObject(ctx, &s3.GetObjectInput{Bucket: &c.bucket, Key: &key})
+//		var noKey *types.NoSuchKey
+//		if errors.As(err, &noKey) {
+//			return nil, uploads.ErrUploadNotFound
+//		}
+//		if err != nil {
+//			return nil, err
+//		}
+//		return output.Body, nil
+//	}
+//	func (c s3Client) UploadPart(ctx context.Context, key, uploadID string, number int32, body []byte) (string, error) {
+//		output, err := c.client.UploadPart(ctx, &s3.UploadPartInput{
+//			Bucket: &c.bucket, Key: &key, UploadId: &uploadID, PartNumber: &number, Body: bytes.NewReader(body),
+//		})
+//		if err != nil {
+//			return "", err
+//		}
+//		return *output.ETag, nil
+//	}
+//	// DeleteObject (which succeeds for missing keys, as in S3), CreateMultipartUpload,
+//	// CompleteMultipartUpload and AbortMultipartUpload call the methods of the same name
+type S3Client interface {
+	PutObject(ctx context.Context, key string, body []byte) error
+	// GetObject returns ErrUploadNotFound for missing keys
+	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+	DeleteObject(ctx context.Context, key string) error
+	CreateMultipartUpload(ctx context.Context, key string) (uploadID string, err error)
+	UploadPart(ctx context.Context, key, uploadID string, number int32, body []byte) (etag string, err error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []S3Part) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+}
+
+// S3MinPartSize is the smallest part S3 accepts, except for the last one
+const S3MinPartSize = 5 << 20
+
+// S3Storage stores each upload as an S3 multipart upload of the object <Prefix><id>, completed
+// when the upload is
+// Chunks are sent as parts of PartSize bytes; the bytes that do not fill a part are kept in the
+// object <id>.pending until the next chunk, because S3 requires parts of at least 5 MB. The
+// state of the upload, with the part ETags, is the object <id>.info
+type S3Storage struct {
+	Client   S3Client
+	Prefix   string // Prefix of the object keys, such as "uploads/"
+	PartSize int64  // Bytes of the parts, at least S3MinPartSize (default 8 MB)
+}
+
+// s3State is the state of an upload kept in <id>.info
+type s3State struct {
+	Upload
+	MultipartID string   `json:"multipart_id"`
+	Parts       []S3Part `json:"parts"`
+	Pending     int64    `json:"pending"` // Bytes in <id>.pending, included in Offset
+}
+
+// NewS3Storage creates a storage on the bucket of client
+func NewS3Storage(client S3Client, prefix string) *S3Storage {
+	return &S3Storage{Client: client, Prefix: prefix, PartSize: 8 << 20}
+}
+
+// Create implements Storage
+func (s *S3Storage) Create(ctx context.Context, upload Upload) error {
+	key := s.Prefix + upload.ID
+	multipartID, err := s.Client.CreateMultipartUpload(ctx, key)
+	if err != nil {
+		return err
+	}
+	upload.Location = key
+	return s.save(ctx, s3State{Upload: upload, MultipartID: multipartID})
+}
+
+// Get implements Storage
+func (s *S3Storage) Get(ctx context.Context, id string) (Upload, error) {
+	state, err := s.load(ctx, id)
+	return state.Upload, err
+}
+
+// Append implements Storage
+func (s *S3Storage) Append(ctx context.Context, id string, offset int64, data io.Reader) (Upload, error) {
+	state, err := s.load(ctx, id)
+	if err != nil {
+		return Upload{}, err
+	}
+	if state.Complete {
+		return state.Upload, ErrUploadComplete
+	}
+	if state.Offset != offset {
+		return state.Upload, ErrOffsetMismatch
+	}
+
+	part := make([]byte, 0, s.partSize())
+	if state.Pending > 0 {
+		pending, err := s.readObject(ctx, state.Location+".pending")
+		if err != nil {
+			return state.Upload, err
+		}
+		part = append(part, pending...)
+	}
+	for {
+		start := len(part)
+		n, readErr := io.ReadFull(data, part[start:cap(part)])
+		part = part[:start+n]
+		state.Offset += int64(n)
+		full := len(part) == cap(part)
+		last := state.Size >= 0 && state.Offset == state.Size
+
+		// Se envía una parte cuando está llena o es la última; el resto queda pendiente
+		if full || (last && len(part) > 0) {
+			if err := s.uploadPart(ctx, &state, part); err != nil {
+				state.Offset -= int64(n)
+				return state.Upload, err
+			}
+			part = part[:0]
+		} else if n > 0 {
+			if err := s.Client.PutObject(ctx, state.Location+".pending", part); err != nil {
+				state.Offset -= int64(n)
+				return state.Upload, err
+			}
+			state.Pending = int64(len(part))
+		}
+		state.UpdatedAt = time.Now()
+		if err := s.save(ctx, state); err != nil {
+			return state.Upload, err
+		}
+
+		switch {
+		case readErr == io.EOF || readErr == io.ErrUnexpectedEOF:
+			return state.Upload, nil
+		case readErr != nil:
+			return state.Upload, readErr
+		}
+	}
+}
+
+// Finish implements Storage
+func (s *S3Storage) Finish(ctx context.Context, id string) (Upload, error) {
+	state, err := s.load(ctx, id)
+	if err != nil || state.Complete {
+		return state.Upload, err
+	}
+	// S3 no completa una carga sin partes: un archivo vacío se sube como una parte vacía
+	if state.Pending > 0 || len(state.Parts) == 0 {
+		var pending []byte
+		if state.Pending > 0 {
+			if pending, err = s.readObject(ctx, state.Location+".pending"); err != nil {
+				return state.Upload, err
+			}
+		}
+		if err := s.uploadPart(ctx, &state, pending); err != nil {
+			return state.Upload, err
+		}
+	}
+	if err := s.Client.CompleteMultipartUpload(ctx, state.Location, state.MultipartID, state.Parts); err != nil {
+		return state.Upload, err
+	}
+	state.Size, state.Complete, state.UpdatedAt = state.Offset, true, time.Now()
+	if err := s.save(ctx, state); err != nil {
+		return state.Upload, err
+	}
+	return state.Upload, s.Client.DeleteObject(ctx, state.Location+".pending")
+}
+
+// Open implements Storage
+func (s *S3Storage) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	state, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Complete {
+		return nil, errors.New("uploads: upload " + id + " is not complete")
+	}
+	return s.Client.GetObject(ctx, state.Location)
+}
+
+// Delete implements Storage
+func (s *S3Storage) Delete(ctx context.Context, id string) error {
+	state, err := s.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if state.Complete {
+		err = s.Client.DeleteObject(ctx, state.Location)
+	} else {
+		err = errors.Join(
+			s.Client.AbortMultipartUpload(ctx, state.Location, state.MultipartID),
+			s.Client.DeleteObject(ctx, state.Location+".pending"),
+		)
+	}
+	return errors.Join(err, s.Client.DeleteObject(ctx, state.Location+".info"))
+}
+
+// uploadPart sends the next part of an upload, which no longer has pending bytes
+func (s *S3Storage) uploadPart(ctx context.Context, state *s3State, part []byte) error {
+	number := int32(len(state.Parts) + 1)
+	etag, err := s.Client.UploadPart(ctx, state.Location, state.MultipartID, number, part)
+	if err != nil {
+		return err
+	}
+	state.Parts = append(state.Parts, S3Part{Number: number, ETag: etag})
+	state.Pending = 0
+	return nil
+}
+
+// partSize returns the size of the parts
+func (s *S3Storage) partSize() int64 {
+	return max(s.PartSize, S3MinPartSize)
+}
+
+// load reads the state of an upload
+func (s *S3Storage) load(ctx context.Context, id string) (s3State, error) {
+	data, err := s.readObject(ctx, s.Prefix+id+".info")
+	if err != nil {
+		return s3State{}, err
+	}
+	var state s3State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return s3State{}, err
+	}
+	state.Location = s.Prefix + id
+	return state, nil
+}
+
+// save writes the state of an upload
+func (s *S3Storage) save(ctx context.Context, state s3State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.Client.PutObject(ctx, state.Location+".info", data)
+}
+
+// readObject reads a whole object
+func (s *S3Storage) readObject(ctx context.Context, key string) ([]byte, error) {
+	object, err := s.Client.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	var buffer bytes.Buffer
+	_, err = buffer.ReadFrom(object)
+	return buffer.Bytes(), err
+}
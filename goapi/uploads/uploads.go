@@ -0,0 +1,79 @@
+// Package uploads receives large files in chunks that can be resumed after a failure, in the
+// style of the tus protocol: a client creates an upload, appends chunks at the offset the server
+// has stored, asks for that offset again when a chunk fails, and completes the upload
+//
+//	files := uploads.NewPlugin(uploads.NewDiskStorage("/var/lib/app/uploads"))
+//	files.Path = "/v1/files"
+//	files.OnComplete = func(ctx context.Context, upload uploads.Upload) error {
+//		content, err := files.Storage().Open(ctx, upload.ID)
+//		...
+//	}
+//	api.UsePlugin(files)
+//
+// The plugin serves:
+//
+//	POST   /uploads              create an upload, optionally declaring its size
+//	GET    /uploads/:id          state and progress (HEAD returns Upload-Offset and Upload-Length)
+//	PATCH  /uploads/:id          append the body at the Upload-Offset header
+//	POST   /uploads/:id/complete complete an upload without declared size
+//	DELETE /uploads/:id          abort an upload and delete its data
+package uploads
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Errors returned by storages
+var (
+	ErrUploadNotFound = errors.New("upload not found")
+	ErrOffsetMismatch = errors.New("upload offset mismatch")
+	ErrUploadComplete = errors.New("upload already complete")
+)
+
+// Upload is the state of an upload
+type Upload struct {
+	ID       string            `json:"id"`
+	Size     int64             `json:"size"`     // Declared size in bytes, or -1 until complete when not declared
+	Offset   int64             `json:"offset"`   // Bytes stored
+	Progress int               `json:"progress"` // Percentage stored, 0 when the size is not declared
+	Complete bool              `json:"complete"`
+	Metadata map[string]string `json:"metadata,omitempty"` // Filename, content type... sent when creating it
+	// Location is where the storage keeps the data (a file path, an object key)
+	Location  string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// percent returns the percentage stored
+func (u Upload) percent() int {
+	switch {
+	case u.Complete:
+		return 100
+	case u.Size <= 0:
+		return 0
+	}
+	return int(u.Offset * 100 / u.Size)
+}
+
+// Storage keeps the state and the data of uploads
+// The plugin serializes the requests to an upload within an instance; with several instances,
+// route the requests of an upload to the same one or use a storage that locks
+type Storage interface {
+	// Create saves a new upload
+	Create(ctx context.Context, upload Upload) error
+	// Get returns an upload, or ErrUploadNotFound
+	Get(ctx context.Context, id string) (Upload, error)
+	// Append stores data at offset, which must be the stored offset (or ErrOffsetMismatch)
+	// The bytes read before an error of data are kept, so the client resumes after them; the
+	// returned upload has the new offset even when the error is not nil
+	Append(ctx context.Context, id string, offset int64, data io.Reader) (Upload, error)
+	// Finish marks an upload complete, setting its size to its offset
+	Finish(ctx context.Context, id string) (Upload, error)
+	// Open returns the data of a complete upload
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+	// Delete removes an upload and its data
+	Delete(ctx context.Context, id string) error
+}
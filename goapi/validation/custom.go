@@ -0,0 +1,266 @@
+package validation
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+)
+
+// Func is a custom validation function (alias of the go-playground type)
+type Func = validator.Func
+
+// FieldLevel gives a custom validation function access to the validated field
+type FieldLevel = validator.FieldLevel
+
+// SchemaConstraint applies the OpenAPI constraints of a validation tag to a schema
+// param is the tag parameter ("8" for "min=8") and may be empty
+type SchemaConstraint func(schema *openapi.Schema, param string)
+
+// Patterns used by the built-in validators and their schema constraints
+const (
+	SlugPattern    = `^[a-z0-9]+(?:-[a-z0-9]+)*$`
+	PhonePattern   = `^\+[1-9]\d{1,14}$`
+	CountryPattern = `^[A-Z]{2}$`
+)
+
+var slugRegex = regexp.MustCompile(SlugPattern)
+
+// StrongPasswordMinLength is the minimum length required by the strong_password validator
+const StrongPasswordMinLength = 8
+
+// RegisterValidation registers a custom validation tag
+// The optional constraint documents the tag in the generated OpenAPI schema
+func (v *Validator) RegisterValidation(tag string, fn Func, constraint ...SchemaConstraint) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if err := v.validator.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	if len(constraint) > 0 && constraint[0] != nil {
+		v.constraints[tag] = constraint[0]
+	}
+	return nil
+}
+
+// RegisterAlias registers a tag that expands to other tags ("country" -> "iso3166_1_alpha2")
+// The alias shares the schema constraint of the tags it expands to when it has none of its own
+func (v *Validator) RegisterAlias(alias, tags string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.registerAlias(alias, tags)
+}
+
+// registerAlias registers an alias without locking
+func (v *Validator) registerAlias(alias, tags string) {
+	v.validator.RegisterAlias(alias, tags)
+	v.aliases[alias] = tags
+}
+
+// RegisterSchemaConstraint documents an existing validation tag in the generated OpenAPI schema
+func (v *Validator) RegisterSchemaConstraint(tag string, constraint SchemaConstraint) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.constraints[tag] = constraint
+}
+
+// ApplySchemaConstraints applies the constraints of a "validate" struct tag to a schema
+// Tags without a registered constraint are ignored
+func (v *Validator) ApplySchemaConstraints(schema *openapi.Schema, validateTag string) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	v.applySchemaConstraints(schema, validateTag)
+}
+
+// applySchemaConstraints applies the constraints of a "validate" tag without locking
+func (v *Validator) applySchemaConstraints(schema *openapi.Schema, validateTag string) {
+	if validateTag == "" || validateTag == "-" {
+		return
+	}
+
+	for _, rule := range strings.Split(validateTag, ",") {
+		// Only the first alternative of an "a|b" rule can be documented
+		rule, _, _ = strings.Cut(rule, "|")
+		tag, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if tag == "dive" {
+			// Rules after dive apply to the elements of a collection
+			if schema.Items != nil {
+				remaining := strings.SplitN(validateTag, "dive", 2)[1]
+				v.applySchemaConstraints(schema.Items, strings.TrimPrefix(remaining, ","))
+			}
+			return
+		}
+		v.applyConstraint(schema, tag, param)
+	}
+}
+
+// applyConstraint applies the constraint of a single tag, resolving aliases
+func (v *Validator) applyConstraint(schema *openapi.Schema, tag, param string) {
+	if constraint, ok := v.constraints[tag]; ok {
+		constraint(schema, param)
+		return
+	}
+	if expanded, ok := v.aliases[tag]; ok {
+		v.applySchemaConstraints(schema, expanded)
+	}
+}
+
+// registerExtraValidators registers the validators shipped with GoAPI on top of go-playground's built-ins
+func (v *Validator) registerExtraValidators() {
+	_ = v.validator.RegisterValidation("slug", validateSlug)
+	_ = v.validator.RegisterValidation("strong_password", validateStrongPassword)
+	v.registerAlias("phone", "e164")
+	v.registerAlias("country", "iso3166_1_alpha2")
+}
+
+// registerDefaultConstraints registers the schema constraints of the built-in and extra tags
+func (v *Validator) registerDefaultConstraints() {
+	v.constraints["min"] = func(schema *openapi.Schema, param string) { applyBound(schema, param, true, false) }
+	v.constraints["max"] = func(schema *openapi.Schema, param string) { applyBound(schema, param, false, false) }
+	v.constraints["gte"] = v.constraints["min"]
+	v.constraints["lte"] = v.constraints["max"]
+	v.constraints["gt"] = func(schema *openapi.Schema, param string) { applyBound(schema, param, true, true) }
+	v.constraints["lt"] = func(schema *openapi.Schema, param string) { applyBound(schema, param, false, true) }
+	v.constraints["len"] = func(schema *openapi.Schema, param string) {
+		applyBound(schema, param, true, false)
+		applyBound(schema, param, false, false)
+	}
+	v.constraints["oneof"] = func(schema *openapi.Schema, param string) {
+		schema.Enum = nil
+		for _, option := range strings.Fields(param) {
+			schema.Enum = append(schema.Enum, typedValue(schema.Type, option))
+		}
+	}
+	v.constraints["email"] = formatConstraint("email")
+	v.constraints["url"] = formatConstraint("uri")
+	v.constraints["uri"] = formatConstraint("uri")
+	v.constraints["uuid"] = formatConstraint("uuid")
+	v.constraints["uuid4"] = formatConstraint("uuid")
+	v.constraints["ipv4"] = formatConstraint("ipv4")
+	v.constraints["ipv6"] = formatConstraint("ipv6")
+	v.constraints["hostname"] = formatConstraint("hostname")
+	v.constraints["datetime"] = formatConstraint("date-time")
+	v.constraints["slug"] = patternConstraint(SlugPattern)
+	v.constraints["e164"] = patternConstraint(PhonePattern)
+	v.constraints["iso3166_1_alpha2"] = patternConstraint(CountryPattern)
+	v.constraints["strong_password"] = func(schema *openapi.Schema, param string) {
+		schema.Format = "password"
+		minLength := int64(StrongPasswordMinLength)
+		schema.MinLength = &minLength
+		schema.Description = joinDescription(schema.Description,
+			"Must contain upper and lower case letters, a digit and a symbol")
+	}
+}
+
+// validateSlug validates lowercase URL slugs ("my-first-post")
+func validateSlug(fieldLevel FieldLevel) bool {
+	return slugRegex.MatchString(fieldLevel.Field().String())
+}
+
+// validateStrongPassword requires upper and lower case letters, a digit, a symbol and a minimum length
+func validateStrongPassword(fieldLevel FieldLevel) bool {
+	password := fieldLevel.Field().String()
+	if len([]rune(password)) < StrongPasswordMinLength {
+		return false
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, character := range password {
+		switch {
+		case unicode.IsUpper(character):
+			hasUpper = true
+		case unicode.IsLower(character):
+			hasLower = true
+		case unicode.IsDigit(character):
+			hasDigit = true
+		case unicode.IsPunct(character) || unicode.IsSymbol(character):
+			hasSymbol = true
+		}
+	}
+	return hasUpper && hasLower && hasDigit && hasSymbol
+}
+
+// formatConstraint returns a constraint that sets the schema format
+func formatConstraint(format string) SchemaConstraint {
+	return func(schema *openapi.Schema, param string) {
+		schema.Format = format
+	}
+}
+
+// patternConstraint returns a constraint that sets the schema pattern
+func patternConstraint(pattern string) SchemaConstraint {
+	return func(schema *openapi.Schema, param string) {
+		schema.Pattern = pattern
+	}
+}
+
+// applyBound applies a lower or upper bound according to the schema type
+// Strings bound their length, arrays their item count and numbers their value
+func applyBound(schema *openapi.Schema, param string, lower, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "string", "array":
+		count := int64(value)
+		if exclusive {
+			if lower {
+				count++
+			} else {
+				count--
+			}
+		}
+		switch {
+		case schema.Type == "string" && lower:
+			schema.MinLength = &count
+		case schema.Type == "string":
+			schema.MaxLength = &count
+		case lower:
+			schema.MinItems = &count
+		default:
+			schema.MaxItems = &count
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum = &value
+			schema.ExclusiveMinimum = exclusive
+		} else {
+			schema.Maximum = &value
+			schema.ExclusiveMaximum = exclusive
+		}
+	}
+}
+
+// typedValue converts an enum option to the JSON type of the schema
+func typedValue(schemaType, value string) interface{} {
+	switch schemaType {
+	case "integer":
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return parsed
+		}
+	case "number":
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	case "boolean":
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return value
+}
+
+// joinDescription appends a sentence to an existing description
+func joinDescription(description, sentence string) string {
+	if description == "" {
+		return sentence
+	}
+	return description + ". " + sentence
+}
@@ -0,0 +1,181 @@
+package validation
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TagUnknownField is the tag (and message key) of the properties rejected by strict decoding
+const TagUnknownField = "unknown_field"
+
+// UnknownFieldsError lists the properties of a JSON document that match no field of the target
+type UnknownFieldsError struct {
+	Paths []string // JSON paths of the properties: "items[0].emial"
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return "json: unknown fields " + strings.Join(e.Paths, ", ")
+}
+
+// ValidationErrors reports every unknown property as a validation error
+func (e *UnknownFieldsError) ValidationErrors(locale string) ValidationErrors {
+	validationErrors := make(ValidationErrors, 0, len(e.Paths))
+	for _, path := range e.Paths {
+		parts := SplitPath(path)
+		field := parts[len(parts)-1]
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   field,
+			Path:    path,
+			Pointer: JSONPointer(path),
+			Tag:     TagUnknownField,
+			Message: Message(locale, TagUnknownField, map[string]string{"field": path}),
+		})
+	}
+	return validationErrors
+}
+
+// DecodeJSON decodes a JSON document into target, like json.Unmarshal
+// In strict mode properties that match no field are rejected, as json.Decoder.DisallowUnknownFields
+// does, but the *UnknownFieldsError lists all of them instead of the first one
+func DecodeJSON(data []byte, target interface{}, strict bool) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(target); err != nil {
+		if strict && strings.HasPrefix(err.Error(), "json: unknown field ") {
+			return unknownFieldsError(data, reflect.TypeOf(target), err)
+		}
+		return err
+	}
+	// Como json.Unmarshal, no se admite nada tras el documento
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return &json.SyntaxError{Offset: decoder.InputOffset()}
+	}
+	return nil
+}
+
+// unknownFieldsError collects the unknown properties of a document the decoder rejected
+func unknownFieldsError(data []byte, targetType reflect.Type, decodeErr error) error {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return decodeErr
+	}
+	var paths []string
+	collectUnknownFields(document, targetType, "", &paths)
+	if len(paths) == 0 {
+		// El decodificador ve campos que el recorrido no sigue (decodificadores propios)
+		name, _ := strconv.Unquote(strings.TrimPrefix(decodeErr.Error(), "json: unknown field "))
+		paths = []string{name}
+	}
+	return &UnknownFieldsError{Paths: paths}
+}
+
+// unmarshalerTypes decode themselves: their properties are not checked
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// collectUnknownFields walks a decoded document along the Go type it is decoded into
+func collectUnknownFields(value interface{}, valueType reflect.Type, path string, paths *[]string) {
+	valueType = indirectType(valueType)
+	if reflect.PointerTo(valueType).Implements(jsonUnmarshalerType) || reflect.PointerTo(valueType).Implements(textUnmarshalerType) {
+		return
+	}
+	switch valueType.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(valueType)
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldType, found := lookupJSONField(fields, key)
+			if !found {
+				*paths = append(*paths, joinPath(path, key))
+				continue
+			}
+			collectUnknownFields(object[key], fieldType, joinPath(path, key), paths)
+		}
+	case reflect.Slice, reflect.Array:
+		items, _ := value.([]interface{})
+		for i, item := range items {
+			collectUnknownFields(item, valueType.Elem(), path+"["+strconv.Itoa(i)+"]", paths)
+		}
+	case reflect.Map:
+		object, _ := value.(map[string]interface{})
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectUnknownFields(object[key], valueType.Elem(), path+"["+key+"]", paths)
+		}
+	}
+}
+
+// jsonFields returns the types of the fields of a struct by JSON name, with the fields of the
+// untagged embedded structs promoted as encoding/json does
+func jsonFields(structType reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, structType.NumField())
+	var embedded []reflect.Type
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name := jsonName(field)
+		if strings.HasSuffix(name, embeddedMarker) {
+			embedded = append(embedded, indirectType(field.Type))
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	// Los campos propios tienen prioridad sobre los promovidos
+	for _, embeddedType := range embedded {
+		for name, fieldType := range jsonFields(embeddedType) {
+			if _, exists := fields[name]; !exists {
+				fields[name] = fieldType
+			}
+		}
+	}
+	return fields
+}
+
+// lookupJSONField finds the field of a property; like encoding/json, an exact name is preferred
+// and the names are otherwise matched case-insensitively
+func lookupJSONField(fields map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if fieldType, found := fields[key]; found {
+		return fieldType, true
+	}
+	for name, fieldType := range fields {
+		if strings.EqualFold(name, key) {
+			return fieldType, true
+		}
+	}
+	return nil, false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,112 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// NullLiteral is the query value that nullable parameters parse as Null
+const NullLiteral = "null"
+
+// NullValue is the parsed value of a nullable parameter sent as "null"
+type NullValue struct{}
+
+// Null is stored in the parsed query parameters for an explicit null
+var Null = NullValue{}
+
+// Nullable is a tri-state parameter: not provided, explicitly null, or a value
+// Filters use it to tell "any manager" (?) from "no manager" (?manager_id=null) and "manager 5"
+type Nullable[T any] struct {
+	Present bool // The parameter was sent
+	Null    bool // The parameter was sent as null
+	Value   T    // The parsed value, when present and not null
+}
+
+// HasValue reports whether the parameter was sent with a value other than null
+func (n Nullable[T]) HasValue() bool {
+	return n.Present && !n.Null
+}
+
+// Get returns the value and whether there is one
+func (n Nullable[T]) Get() (T, bool) {
+	return n.Value, n.HasValue()
+}
+
+// NullableFrom reads a nullable parameter from the values returned by ParseQueryParams
+// Numeric values are converted to T (int64 to int, for example); values of another type
+// are reported as not provided
+func NullableFrom[T any](values map[string]interface{}, name string) Nullable[T] {
+	raw, exists := values[name]
+	if !exists || raw == nil {
+		return Nullable[T]{}
+	}
+	if _, isNull := raw.(NullValue); isNull {
+		return Nullable[T]{Present: true, Null: true}
+	}
+	if value, ok := raw.(T); ok {
+		return Nullable[T]{Present: true, Value: value}
+	}
+
+	var value T
+	target := reflect.TypeOf(value)
+	source := reflect.ValueOf(raw)
+	if target != nil && isNumeric(source.Kind()) && isNumeric(target.Kind()) {
+		reflect.ValueOf(&value).Elem().Set(source.Convert(target))
+		return Nullable[T]{Present: true, Value: value}
+	}
+	return Nullable[T]{}
+}
+
+// ParseNullable parses a nullable parameter straight from query values, without a declaration
+// An empty value counts as not provided, like in ParseQueryParams; the value is parsed as T
+// and, when it does not parse, the parameter is reported as not provided along with the error
+func ParseNullable[T any](queryValues map[string][]string, name string) (Nullable[T], error) {
+	values := queryValues[name]
+	if len(values) == 0 || values[0] == "" {
+		return Nullable[T]{}, nil
+	}
+	if values[0] == NullLiteral {
+		return Nullable[T]{Present: true, Null: true}, nil
+	}
+
+	var value T
+	target := reflect.ValueOf(&value).Elem()
+	raw := values[0]
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, target.Type().Bits())
+		if err != nil {
+			return Nullable[T]{}, err
+		}
+		target.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, target.Type().Bits())
+		if err != nil {
+			return Nullable[T]{}, err
+		}
+		target.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, target.Type().Bits())
+		if err != nil {
+			return Nullable[T]{}, err
+		}
+		target.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Nullable[T]{}, err
+		}
+		target.SetBool(parsed)
+	default:
+		return Nullable[T]{}, fmt.Errorf("unsupported nullable type %s", target.Type())
+	}
+	return Nullable[T]{Present: true, Value: value}, nil
+}
+
+// isNumeric reports whether a kind is an integer or floating point number
+func isNumeric(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
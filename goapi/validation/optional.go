@@ -0,0 +1,115 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Valuer is implemented by wrapper types that are validated as the value they hold, like Nullable
+// ValidationValue returns nil when there is no value, so omitempty skips the field and required
+// rejects it; otherwise the tags of the field apply to the value
+type Valuer interface {
+	ValidationValue() interface{}
+}
+
+// ValidationValue returns the value to validate, or nil when the field is absent or null
+func (n Nullable[T]) ValidationValue() interface{} {
+	if !n.HasValue() {
+		return nil
+	}
+	return n.Value
+}
+
+// MarshalJSON encodes the value, or null when there is none
+// Tag the field with omitzero to leave it out of the output when it is absent
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.HasValue() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// UnmarshalJSON marks the field as present, and as null when the member is null
+// Members missing from the document never call it, so they stay not provided
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*n = Nullable[T]{Present: true, Null: true}
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*n = Nullable[T]{Present: true, Value: value}
+	return nil
+}
+
+// IsZero reports whether the field was not provided, for the omitzero JSON option
+func (n Nullable[T]) IsZero() bool {
+	return !n.Present
+}
+
+// nullable marks the Nullable types for NullableValue
+func (Nullable[T]) nullable() {}
+
+var nullableInterface = reflect.TypeOf((*interface{ nullable() })(nil)).Elem()
+
+// NullableValue returns the Value field of a Nullable and whether v holds one
+// The schema generator uses it to document the wrapped type
+func NullableValue(v reflect.Value) (reflect.Value, bool) {
+	if !v.IsValid() || v.Kind() != reflect.Struct || !v.Type().Implements(nullableInterface) {
+		return reflect.Value{}, false
+	}
+	return v.FieldByName("Value"), true
+}
+
+var valuerInterface = reflect.TypeOf((*Valuer)(nil)).Elem()
+
+// registerValuers registers the Valuer types used by the fields of a struct with the validator
+// The fields of every struct type are walked once; new Valuer types are registered under the
+// write lock, so they must not be registered while a validation holds the read lock
+func (v *Validator) registerValuers(s interface{}) {
+	structType := reflect.TypeOf(s)
+	for structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return
+	}
+	if _, walked := v.walked.Load(structType); walked {
+		return
+	}
+
+	var valuers []interface{}
+	visited := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		if t.Implements(valuerInterface) {
+			valuers = append(valuers, reflect.Zero(t).Interface())
+			return
+		}
+		if t.Kind() == reflect.Struct {
+			for i := 0; i < t.NumField(); i++ {
+				walk(t.Field(i).Type)
+			}
+		}
+	}
+	walk(structType)
+
+	if len(valuers) > 0 {
+		v.mutex.Lock()
+		v.validator.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+			return field.Interface().(Valuer).ValidationValue()
+		}, valuers...)
+		v.mutex.Unlock()
+	}
+	v.walked.Store(structType, true)
+}
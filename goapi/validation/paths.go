@@ -0,0 +1,224 @@
+package validation
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// embeddedMarker ends the names of untagged embedded structs, whose fields encoding/json promotes
+// to the parent: the segment is dropped from the paths. A comma cannot be part of a JSON name
+const embeddedMarker = ","
+
+// jsonName is the tag name function of the validator: errors are named as the JSON fields
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch {
+	case name == "-":
+		return ""
+	case name != "":
+		return name
+	case field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct:
+		return field.Name + embeddedMarker
+	default:
+		return ""
+	}
+}
+
+// jsonFieldName returns the JSON name of a struct field by its Go name
+func jsonFieldName(structType reflect.Type, name string) string {
+	if field, found := indirectType(structType).FieldByName(name); found {
+		if json := strings.TrimSuffix(jsonName(field), embeddedMarker); json != "" {
+			return json
+		}
+	}
+	return name
+}
+
+// fieldName returns the JSON name of the field of an error
+func fieldName(fieldError validator.FieldError) string {
+	return strings.TrimSuffix(fieldError.Field(), embeddedMarker)
+}
+
+// fieldPath returns the JSON path of the field of an error ("items[2].email"), without the root
+// struct and the embedded structs
+func fieldPath(fieldError validator.FieldError) string {
+	segments := strings.Split(fieldError.Namespace(), ".")
+	if len(segments) > 1 {
+		segments = segments[1:]
+	}
+	path := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if name, index, _ := strings.Cut(segment, "["); strings.HasSuffix(name, embeddedMarker) {
+			// Los índices de un embebido se aplican al campo anterior
+			if index != "" && len(path) > 0 {
+				path[len(path)-1] += "[" + index
+			}
+			continue
+		}
+		path = append(path, segment)
+	}
+	return strings.Join(path, ".")
+}
+
+// SplitPath splits a JSON path into its keys and indices: "items[2].email" is
+// ["items", "2", "email"]
+func SplitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	var parts []string
+	for _, segment := range strings.Split(path, ".") {
+		name, indices, _ := strings.Cut(segment, "[")
+		if name != "" {
+			parts = append(parts, name)
+		}
+		for indices != "" {
+			var index string
+			index, indices, _ = strings.Cut(indices, "]")
+			parts = append(parts, index)
+			indices = strings.TrimPrefix(indices, "[")
+		}
+	}
+	return parts
+}
+
+// JSONPointer returns the RFC 6901 pointer of a JSON path: "items[2].email" is "/items/2/email"
+func JSONPointer(path string) string {
+	var pointer strings.Builder
+	for _, part := range SplitPath(path) {
+		pointer.WriteString("/")
+		pointer.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(part))
+	}
+	return pointer.String()
+}
+
+// textMarshalerType encodes as a JSON string, like time.Time
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// ValueType returns the JSON type of a value: "string", "integer", "number", "boolean", "array",
+// "object" or "null"
+func ValueType(value interface{}) string {
+	current := reflect.ValueOf(value)
+	for current.Kind() == reflect.Ptr || current.Kind() == reflect.Interface {
+		if current.IsNil() {
+			return "null"
+		}
+		current = current.Elem()
+	}
+	if !current.IsValid() {
+		return "null"
+	}
+	if current.Type().Implements(textMarshalerType) || reflect.PointerTo(current.Type()).Implements(textMarshalerType) {
+		return "string"
+	}
+	switch current.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		if current.IsNil() {
+			return "null"
+		}
+		if current.Type().Elem().Kind() == reflect.Uint8 {
+			return "string" // encoding/json codifica []byte en base64
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	case reflect.Map:
+		if current.IsNil() {
+			return "null"
+		}
+		return "object"
+	case reflect.Struct:
+		return "object"
+	default:
+		return ""
+	}
+}
+
+// jsonValue returns a value as it is encoded in JSON: pointers are dereferenced, nil for null, and
+// the text of values encoding/json cannot encode
+func jsonValue(value interface{}) interface{} {
+	current := reflect.ValueOf(value)
+	for current.Kind() == reflect.Ptr || current.Kind() == reflect.Interface {
+		if current.IsNil() {
+			return nil
+		}
+		current = current.Elem()
+	}
+	if !current.IsValid() || ValueType(value) == "null" {
+		return nil
+	}
+	value = current.Interface()
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return value
+}
+
+// valueText returns a value as the text of ValidationError.Value: empty for null
+func valueText(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// FromUnmarshalTypeError reports a JSON value of the wrong type for its field
+func FromUnmarshalTypeError(typeError *json.UnmarshalTypeError, locale string) ValidationError {
+	valueType, literal, _ := strings.Cut(typeError.Value, " ") // "number -5", "bool", "array"
+	if valueType == "bool" {
+		valueType = "boolean"
+	}
+	// encoding/json solo da el valor de los números
+	var value interface{}
+	if literal != "" {
+		value = json.Number(literal)
+		if !strings.ContainsAny(literal, ".eE") {
+			valueType = "integer"
+		}
+	}
+	// encoding/json separa también los índices con puntos: "items.0.qty"
+	var path, field string
+	for _, segment := range strings.Split(typeError.Field, ".") {
+		if _, err := strconv.Atoi(segment); err == nil && path != "" {
+			path += "[" + segment + "]"
+			continue
+		}
+		if path != "" {
+			path += "."
+		}
+		path += segment
+		field = segment
+	}
+	return ValidationError{
+		Field:     field,
+		Path:      path,
+		Pointer:   JSONPointer(path),
+		Tag:       "type",
+		Value:     valueText(value),
+		ValueType: valueType,
+		RawValue:  value,
+		Message:   Message(locale, MessageBodyType, map[string]string{"field": path, "param": typeError.Type.String()}),
+	}
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
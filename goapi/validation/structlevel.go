@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// StructLevel gives a struct-level validation function access to the validated struct
+type StructLevel = validator.StructLevel
+
+// StructLevelFunc validates a whole struct and reports errors on specific fields
+type StructLevelFunc = validator.StructLevelFunc
+
+// Tags reported by the built-in struct-level helpers
+const (
+	TagRequiredOneOf = "required_one_of"
+	TagAfter         = "after"
+)
+
+// RegisterStructValidation registers a struct-level validation for the given struct types
+// Errors are reported with sl.ReportError so they are mapped to a specific field:
+//
+//	v.RegisterStructValidation(func(sl validation.StructLevel) {
+//		event := sl.Current().Interface().(Event)
+//		if !event.EndDate.After(event.StartDate) {
+//			sl.ReportError(event.EndDate, "EndDate", "EndDate", "after", "StartDate")
+//		}
+//	}, Event{})
+func (v *Validator) RegisterStructValidation(fn StructLevelFunc, types ...interface{}) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.validator.RegisterStructValidation(fn, types...)
+}
+
+// RequireOneOf returns a struct-level validation requiring at least one of the fields to be set
+// Every listed field is reported when all of them are empty ("either email or phone required")
+func RequireOneOf(fields ...string) StructLevelFunc {
+	return func(sl StructLevel) {
+		current := sl.Current()
+		for _, name := range fields {
+			if field := current.FieldByName(name); field.IsValid() && !field.IsZero() {
+				return
+			}
+		}
+		names := make([]string, len(fields))
+		for i, name := range fields {
+			names[i] = jsonFieldName(current.Type(), name)
+		}
+		for i, name := range fields {
+			if field := current.FieldByName(name); field.IsValid() {
+				sl.ReportError(field.Interface(), names[i], name, TagRequiredOneOf, strings.Join(names, " "))
+			}
+		}
+	}
+}
+
+// FieldAfter returns a struct-level validation requiring a time field to be after another one
+// Empty fields are skipped so they can be validated with "required" on their own
+func FieldAfter(field, other string) StructLevelFunc {
+	return func(sl StructLevel) {
+		later, laterOK := timeField(sl.Current(), field)
+		earlier, earlierOK := timeField(sl.Current(), other)
+		if !laterOK || !earlierOK || later.IsZero() || earlier.IsZero() {
+			return
+		}
+		if !later.After(earlier) {
+			structType := sl.Current().Type()
+			sl.ReportError(later, jsonFieldName(structType, field), field, TagAfter, jsonFieldName(structType, other))
+		}
+	}
+}
+
+// CombineStructValidations runs several struct-level validations on the same struct type
+// go-playground keeps a single struct-level function per type, so combine them before registering
+func CombineStructValidations(validations ...StructLevelFunc) StructLevelFunc {
+	return func(sl StructLevel) {
+		for _, validation := range validations {
+			validation(sl)
+		}
+	}
+}
+
+// timeField returns the value of a time.Time (or *time.Time) field
+func timeField(current reflect.Value, name string) (time.Time, bool) {
+	field := current.FieldByName(name)
+	if !field.IsValid() {
+		return time.Time{}, false
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return time.Time{}, false
+		}
+		field = field.Elem()
+	}
+	value, ok := field.Interface().(time.Time)
+	return value, ok
+}
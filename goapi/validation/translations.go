@@ -0,0 +1,199 @@
+package validation
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	esTranslations "github.com/go-playground/validator/v10/translations/es"
+)
+
+// DefaultLocale is the locale used when no supported locale is requested
+const DefaultLocale = i18n.DefaultLocale
+
+// Message keys used for errors that are not produced by validation tags
+const (
+	MessageQueryRequired  = "query.required"
+	MessageQueryType      = "query.type"
+	MessageDefault        = "default"
+	MessageHeaderRequired = "header.required"
+	MessageBodyRequired   = "body.required"
+	MessageBodyInvalid    = "body.invalid"
+	MessageBodyType       = "body.type"
+)
+
+// MessageCatalog maps a validation tag (or message key) to a message template
+// Templates may use the placeholders {field}, {param}, {tag} and {value}
+type MessageCatalog map[string]string
+
+// EnglishCatalog contains the default English validation messages
+var EnglishCatalog = MessageCatalog{
+	"required":            "The field '{field}' is required",
+	"min":                 "The field '{field}' must have a minimum value of {param}",
+	"max":                 "The field '{field}' must have a maximum value of {param}",
+	"email":               "The field '{field}' must be a valid email",
+	"url":                 "The field '{field}' must be a valid URL",
+	"len":                 "The field '{field}' must be exactly {param} characters long",
+	"gte":                 "The field '{field}' must be greater than or equal to {param}",
+	"lte":                 "The field '{field}' must be less than or equal to {param}",
+	"slug":                "The field '{field}' must be a lowercase slug (letters, digits and hyphens)",
+	"phone":               "The field '{field}' must be a phone number in E.164 format",
+	"e164":                "The field '{field}' must be a phone number in E.164 format",
+	"strong_password":     "The field '{field}' must have at least 8 characters with upper and lower case letters, a digit and a symbol",
+	"country":             "The field '{field}' must be an ISO 3166-1 alpha-2 country code",
+	"iso3166_1_alpha2":    "The field '{field}' must be an ISO 3166-1 alpha-2 country code",
+	"uuid4":               "The field '{field}' must be a valid UUID v4",
+	TagRequiredOneOf:      "At least one of the fields {param} is required",
+	TagAfter:              "The field '{field}' must be after '{param}'",
+	MessageQueryRequired:  "The query parameter '{field}' is required",
+	MessageQueryType:      "The parameter '{field}' must be of type {param}",
+	MessageHeaderRequired: "The header '{field}' is required",
+	MessageBodyRequired:   "The request body is required",
+	MessageBodyInvalid:    "The request body is not valid JSON",
+	MessageBodyInvalidXML: "The request body is not valid XML",
+	MessageBodyType:       "The field '{field}' must be of type {param}",
+	TagUnknownField:       "The field '{field}' is not allowed",
+	MessageDefault:        "The field '{field}' does not satisfy the '{tag}' validation",
+}
+
+// SpanishCatalog contains the Spanish validation messages
+var SpanishCatalog = MessageCatalog{
+	"required":            "El campo '{field}' es requerido",
+	"min":                 "El campo '{field}' debe tener un valor mínimo de {param}",
+	"max":                 "El campo '{field}' debe tener un valor máximo de {param}",
+	"email":               "El campo '{field}' debe ser un email válido",
+	"url":                 "El campo '{field}' debe ser una URL válida",
+	"len":                 "El campo '{field}' debe tener exactamente {param} caracteres",
+	"gte":                 "El campo '{field}' debe ser mayor o igual a {param}",
+	"lte":                 "El campo '{field}' debe ser menor o igual a {param}",
+	"slug":                "El campo '{field}' debe ser un slug en minúsculas (letras, dígitos y guiones)",
+	"phone":               "El campo '{field}' debe ser un teléfono en formato E.164",
+	"e164":                "El campo '{field}' debe ser un teléfono en formato E.164",
+	"strong_password":     "El campo '{field}' debe tener al menos 8 caracteres con mayúsculas, minúsculas, un dígito y un símbolo",
+	"country":             "El campo '{field}' debe ser un código de país ISO 3166-1 alfa-2",
+	"iso3166_1_alpha2":    "El campo '{field}' debe ser un código de país ISO 3166-1 alfa-2",
+	"uuid4":               "El campo '{field}' debe ser un UUID v4 válido",
+	TagRequiredOneOf:      "Al menos uno de los campos {param} es requerido",
+	TagAfter:              "El campo '{field}' debe ser posterior a '{param}'",
+	MessageQueryRequired:  "El parámetro de consulta '{field}' es requerido",
+	MessageQueryType:      "El parámetro '{field}' debe ser de tipo {param}",
+	MessageHeaderRequired: "El encabezado '{field}' es requerido",
+	MessageBodyRequired:   "El cuerpo de la solicitud es requerido",
+	MessageBodyInvalid:    "El cuerpo de la solicitud no es un JSON válido",
+	MessageBodyInvalidXML: "El cuerpo de la solicitud no es un XML válido",
+	MessageBodyType:       "El campo '{field}' debe ser de tipo {param}",
+	TagUnknownField:       "El campo '{field}' no está permitido",
+	MessageDefault:        "El campo '{field}' no cumple con la validación '{tag}'",
+}
+
+// MessageKeyPrefix prefixes the keys of validation messages in the i18n catalogs, so they can also
+// be registered with i18n.Register ("validation.required")
+const MessageKeyPrefix = "validation."
+
+func init() {
+	RegisterCatalog("en", EnglishCatalog)
+	RegisterCatalog("es", SpanishCatalog)
+}
+
+// RegisterCatalog registers (or extends) the message catalog of a locale
+// Existing messages are overwritten by the entries of the new catalog. The messages are stored in
+// the i18n catalogs, which also negotiate the locale of each request
+func RegisterCatalog(locale string, catalog MessageCatalog) {
+	prefixed := make(i18n.Catalog, len(catalog))
+	for key, message := range catalog {
+		prefixed[MessageKeyPrefix+key] = message
+	}
+	i18n.Register(locale, prefixed)
+}
+
+// SupportedLocales returns the locales with a registered catalog
+func SupportedLocales() []string {
+	return i18n.SupportedLocales()
+}
+
+// Message renders the message of a key for a locale, falling back to English and then to the default message
+func Message(locale, key string, placeholders map[string]string) string {
+	template, found := lookupMessage(locale, key)
+	if !found {
+		template, _ = lookupMessage(locale, MessageDefault)
+	}
+	return renderMessage(template, placeholders)
+}
+
+// lookupMessage finds a message template for a locale, trying the base language and English
+func lookupMessage(locale, key string) (string, bool) {
+	return i18n.Lookup(locale, MessageKeyPrefix+key)
+}
+
+// renderMessage replaces the {placeholder} markers of a template
+func renderMessage(template string, placeholders map[string]string) string {
+	for name, value := range placeholders {
+		template = strings.ReplaceAll(template, "{"+name+"}", value)
+	}
+	return template
+}
+
+// DetectLocale returns the best supported locale for an Accept-Language header value
+//
+// Deprecated: use i18n.Negotiate
+func DetectLocale(acceptLanguage string) string {
+	return i18n.Negotiate(acceptLanguage)
+}
+
+// LocaleFromRequest detects the locale of a request from its Accept-Language header
+//
+// Deprecated: use i18n.Locale, which also honors the locale set with i18n.SetLocale
+func LocaleFromRequest(request *http.Request) string {
+	return i18n.LocaleFromRequest(request)
+}
+
+// universalTranslator holds the go-playground translators shared by every Validator
+// Translation functions are registered per validator instance but keyed by these translators
+var universalTranslator = ut.New(en.New(), en.New(), es.New())
+
+// registerDefaultTranslations registers the go-playground default translations on a validator
+func registerDefaultTranslations(validate *validator.Validate) {
+	if translator, found := universalTranslator.GetTranslator("en"); found {
+		_ = enTranslations.RegisterDefaultTranslations(validate, translator)
+	}
+	if translator, found := universalTranslator.GetTranslator("es"); found {
+		_ = esTranslations.RegisterDefaultTranslations(validate, translator)
+	}
+}
+
+// translateFieldError renders the message of a single validator error for a locale
+// Catalog messages take precedence; go-playground translations cover the remaining tags
+func translateFieldError(fieldError validator.FieldError, locale string) string {
+	placeholders := map[string]string{
+		"field": fieldName(fieldError),
+		"param": fieldError.Param(),
+		"tag":   fieldError.Tag(),
+		"value": fmt.Sprintf("%v", fieldError.Value()),
+	}
+
+	if template, found := lookupMessage(locale, fieldError.Tag()); found {
+		return renderMessage(template, placeholders)
+	}
+
+	for _, candidate := range i18n.Candidates(locale) {
+		if translator, found := universalTranslator.GetTranslator(candidate); found {
+			if message := fieldError.Translate(translator); message != "" && !isUntranslated(message, fieldError) {
+				return message
+			}
+			break
+		}
+	}
+
+	return Message(locale, MessageDefault, placeholders)
+}
+
+// isUntranslated reports whether go-playground returned its raw fallback error text
+func isUntranslated(message string, fieldError validator.FieldError) bool {
+	return strings.HasPrefix(message, "Key: ") || message == fieldError.Error()
+}
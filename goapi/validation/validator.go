@@ -7,33 +7,112 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 // Validator wraps the go-playground validator
+// A Validator is safe for concurrent use and caches the parsed tags of every struct it validates,
+// so a single instance should be shared: use Default, FromContext or GoAPI.GetValidator
 type Validator struct {
-	validator *validator.Validate
+	validator   *validator.Validate
+	constraints map[string]SchemaConstraint // OpenAPI constraints by validation tag
+	aliases     map[string]string           // Tags registered with RegisterAlias
+	mutex       sync.RWMutex                // Guards registrations against concurrent validations
+	walked      sync.Map                    // Struct types whose Valuer fields are registered
 }
 
 // NewValidator creates a new validator instance
+// Building a validator is expensive; creating one per request is deprecated in favour of
+// the shared instance returned by Default or FromContext
 func NewValidator() *Validator {
-	return &Validator{
-		validator: validator.New(),
+	validate := validator.New()
+	validate.RegisterTagNameFunc(jsonName)
+	registerDefaultTranslations(validate)
+
+	v := &Validator{
+		validator:   validate,
+		constraints: make(map[string]SchemaConstraint),
+		aliases:     make(map[string]string),
 	}
+	v.registerExtraValidators()
+	v.registerDefaultConstraints()
+
+	return v
+}
+
+var (
+	defaultValidator     *Validator
+	defaultValidatorOnce sync.Once
+)
+
+// Default returns the process-wide shared validator
+func Default() *Validator {
+	defaultValidatorOnce.Do(func() {
+		defaultValidator = NewValidator()
+	})
+	return defaultValidator
+}
+
+// ContextKey is the gin context key holding the validator of the API
+const ContextKey = "goapi.validator"
+
+// Middleware stores a validator in the context of every request so handlers can use FromContext
+func Middleware(v *Validator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ContextKey, v)
+		c.Next()
+	}
+}
+
+// FromContext returns the validator stored by Middleware, or the shared Default validator
+func FromContext(c *gin.Context) *Validator {
+	if c != nil {
+		if value, exists := c.Get(ContextKey); exists {
+			if v, ok := value.(*Validator); ok {
+				return v
+			}
+		}
+	}
+	return Default()
 }
 
 // ValidateStruct validates a struct using tags
 func (v *Validator) ValidateStruct(s interface{}) error {
+	v.registerValuers(s)
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
 	return v.validator.Struct(s)
 }
 
+// ValidateStructExcept validates a struct using tags, skipping the named fields (Go names)
+func (v *Validator) ValidateStructExcept(s interface{}, fields ...string) error {
+	v.registerValuers(s)
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return v.validator.StructExcept(s, fields...)
+}
+
+// Translate formats validator errors with the messages of the given locale
+func (v *Validator) Translate(err error, locale string) ValidationErrors {
+	return FormatValidationErrorsLocale(err, locale)
+}
+
 // ValidationError represents a validation error
+// Field is the JSON name of the field; Path locates it from the root of the body
+// ("items[2].email") and Pointer is the same location as an RFC 6901 pointer ("/items/2/email")
 type ValidationError struct {
-	Field   string `json:"field"`
-	Tag     string `json:"tag"`
-	Value   string `json:"value"`
-	Message string `json:"message"`
+	Field     string      `json:"field"`
+	Path      string      `json:"path,omitempty"`
+	Pointer   string      `json:"pointer,omitempty"`
+	Tag       string      `json:"tag"`
+	Value     string      `json:"value"`                // Value received, as text; empty for null
+	ValueType string      `json:"value_type,omitempty"` // JSON type of the value received: "string", "integer", "null"...
+	RawValue  interface{} `json:"raw_value,omitempty"`  // Value received, as in the JSON (5, true...); omitted for null
+	Message   string      `json:"message"`
+	In        string      `json:"in,omitempty"` // Location of the field when known: "body", "query", "path" or "header"
 }
 
 // ValidationErrors represents multiple validation errors
@@ -48,43 +127,32 @@ func (ve ValidationErrors) Error() string {
 }
 
 // FormatValidationErrors formats validator errors into a more readable format
+// Messages are rendered in the default locale (English)
 func FormatValidationErrors(err error) ValidationErrors {
+	return FormatValidationErrorsLocale(err, DefaultLocale)
+}
+
+// FormatValidationErrorsLocale formats validator errors with the messages of the given locale
+// Unsupported locales fall back to English
+func FormatValidationErrorsLocale(err error, locale string) ValidationErrors {
 	var validationErrors ValidationErrors
-	
+
 	if validatorErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, fieldError := range validatorErrors {
-			validationError := ValidationError{
-				Field: fieldError.Field(),
-				Tag:   fieldError.Tag(),
-				Value: fmt.Sprintf("%v", fieldError.Value()),
-			}
-			
-			// Generate human-readable messages
-			switch fieldError.Tag() {
-			case "required":
-				validationError.Message = fmt.Sprintf("El campo '%s' es requerido", fieldError.Field())
-			case "min":
-				validationError.Message = fmt.Sprintf("El campo '%s' debe tener un valor mínimo de %s", fieldError.Field(), fieldError.Param())
-			case "max":
-				validationError.Message = fmt.Sprintf("El campo '%s' debe tener un valor máximo de %s", fieldError.Field(), fieldError.Param())
-			case "email":
-				validationError.Message = fmt.Sprintf("El campo '%s' debe ser un email válido", fieldError.Field())
-			case "url":
-				validationError.Message = fmt.Sprintf("El campo '%s' debe ser una URL válida", fieldError.Field())
-			case "len":
-				validationError.Message = fmt.Sprintf("El campo '%s' debe tener exactamente %s caracteres", fieldError.Field(), fieldError.Param())
-			case "gte":
-				validationError.Message = fmt.Sprintf("El campo '%s' debe ser mayor o igual a %s", fieldError.Field(), fieldError.Param())
-			case "lte":
-				validationError.Message = fmt.Sprintf("El campo '%s' debe ser menor o igual a %s", fieldError.Field(), fieldError.Param())
-			default:
-				validationError.Message = fmt.Sprintf("El campo '%s' no cumple con la validación '%s'", fieldError.Field(), fieldError.Tag())
-			}
-			
-			validationErrors = append(validationErrors, validationError)
+			path := fieldPath(fieldError)
+			validationErrors = append(validationErrors, ValidationError{
+				Field:     fieldName(fieldError),
+				Path:      path,
+				Pointer:   JSONPointer(path),
+				Tag:       fieldError.Tag(),
+				Value:     valueText(jsonValue(fieldError.Value())),
+				ValueType: ValueType(fieldError.Value()),
+				RawValue:  jsonValue(fieldError.Value()),
+				Message:   translateFieldError(fieldError, locale),
+			})
 		}
 	}
-	
+
 	return validationErrors
 }
 
@@ -93,6 +161,7 @@ type QueryParam struct {
 	Name         string
 	Type         string
 	Required     bool
+	Nullable     bool // Accepts the literal "null", parsed as Null
 	DefaultValue interface{}
 	Description  string
 	Example      interface{}
@@ -108,22 +177,28 @@ type PathParam struct {
 
 // ParseQueryParams parses and validates query parameters from a request
 func ParseQueryParams(queryValues map[string][]string, params []QueryParam) (map[string]interface{}, error) {
+	return ParseQueryParamsLocale(queryValues, params, DefaultLocale)
+}
+
+// ParseQueryParamsLocale parses and validates query parameters, reporting errors in the given locale
+func ParseQueryParamsLocale(queryValues map[string][]string, params []QueryParam, locale string) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 	var validationErrors ValidationErrors
 
 	for _, param := range params {
 		values, exists := queryValues[param.Name]
-		
+
 		// Check if required parameter is missing
 		if param.Required && (!exists || len(values) == 0 || values[0] == "") {
 			validationErrors = append(validationErrors, ValidationError{
 				Field:   param.Name,
+				Path:    param.Name,
 				Tag:     "required",
-				Message: fmt.Sprintf("El parámetro de consulta '%s' es requerido", param.Name),
+				Message: Message(locale, MessageQueryRequired, map[string]string{"field": param.Name}),
 			})
 			continue
 		}
-		
+
 		// Use default value if parameter is not provided
 		if !exists || len(values) == 0 || values[0] == "" {
 			if param.DefaultValue != nil {
@@ -131,27 +206,34 @@ func ParseQueryParams(queryValues map[string][]string, params []QueryParam) (map
 			}
 			continue
 		}
-		
+
 		// Parse the value based on type
 		value := values[0]
+		if param.Nullable && value == NullLiteral {
+			result[param.Name] = Null
+			continue
+		}
 		parsedValue, err := parseValue(value, param.Type)
 		if err != nil {
 			validationErrors = append(validationErrors, ValidationError{
-				Field:   param.Name,
-				Tag:     "type",
-				Value:   value,
-				Message: fmt.Sprintf("El parámetro '%s' debe ser de tipo %s", param.Name, param.Type),
+				Field:     param.Name,
+				Path:      param.Name,
+				Tag:       "type",
+				Value:     value,
+				ValueType: "string",
+				RawValue:  value,
+				Message:   Message(locale, MessageQueryType, map[string]string{"field": param.Name, "param": param.Type}),
 			})
 			continue
 		}
-		
+
 		result[param.Name] = parsedValue
 	}
-	
+
 	if len(validationErrors) > 0 {
 		return nil, validationErrors
 	}
-	
+
 	return result, nil
 }
 
@@ -162,11 +244,11 @@ func parseValue(value, valueType string) (interface{}, error) {
 		return value, nil
 	case "int":
 		return strconv.Atoi(value)
-	case "int64":
+	case "int64", "integer":
 		return strconv.ParseInt(value, 10, 64)
-	case "float64":
+	case "float64", "number":
 		return strconv.ParseFloat(value, 64)
-	case "bool":
+	case "bool", "boolean":
 		return strconv.ParseBool(value)
 	default:
 		return value, nil
@@ -179,13 +261,12 @@ func BindAndValidate(data interface{}, target interface{}) error {
 	if err := bindData(data, target); err != nil {
 		return fmt.Errorf("error binding data: %w", err)
 	}
-	
+
 	// Then validate the struct
-	validator := NewValidator()
-	if err := validator.ValidateStruct(target); err != nil {
+	if err := Default().ValidateStruct(target); err != nil {
 		return FormatValidationErrors(err)
 	}
-	
+
 	return nil
 }
 
@@ -194,11 +275,11 @@ func bindData(_, target interface{}) error {
 	// This is a simplified implementation
 	// In a real implementation, you would use reflection to properly bind data
 	targetValue := reflect.ValueOf(target)
-	
+
 	if targetValue.Kind() != reflect.Ptr || targetValue.Elem().Kind() != reflect.Struct {
 		return errors.New("target must be a pointer to a struct")
 	}
-	
+
 	// For now, we assume data is already in the correct format
 	// This would need more sophisticated implementation for real use
 	return nil
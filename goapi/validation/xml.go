@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"strings"
+)
+
+// MessageBodyInvalidXML is the message key of request bodies that are not valid XML
+const MessageBodyInvalidXML = "body.invalid_xml"
+
+// SOAP envelope namespaces of SOAP 1.1 and 1.2
+const (
+	SOAP11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	SOAP12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// IsXMLContentType reports whether a Content-Type is an XML media type: application/xml,
+// text/xml or a +xml suffix such as application/soap+xml
+func IsXMLContentType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// DecodeXML decodes an XML document into target
+// SOAP envelopes are unwrapped, so target receives the first element of their Body, as partners
+// integrating over SOAP send the same payload wrapped in an envelope
+func DecodeXML(data []byte, target interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root, err := nextElement(decoder)
+	if err != nil {
+		return err
+	}
+	if root.Name.Local != "Envelope" || (root.Name.Space != SOAP11Namespace && root.Name.Space != SOAP12Namespace) {
+		return decoder.DecodeElement(target, &root)
+	}
+
+	// Los elementos del sobre (Header, Body) se recorren hasta encontrar el primero del Body
+	for {
+		element, err := nextElement(decoder)
+		if err != nil {
+			return err
+		}
+		if element.Name.Space != root.Name.Space || element.Name.Local != "Body" {
+			if err := decoder.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		payload, err := nextElement(decoder)
+		if err != nil {
+			return err
+		}
+		return decoder.DecodeElement(target, &payload)
+	}
+}
+
+// nextElement returns the next start element, skipping the prolog, comments and text
+func nextElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return xml.StartElement{}, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			return element, nil
+		case xml.EndElement:
+			return xml.StartElement{}, errors.New("xml: element expected")
+		}
+	}
+}
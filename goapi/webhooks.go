@@ -0,0 +1,84 @@
+package goapi
+
+import (
+	"net/http"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/openapi"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+)
+
+// DocumentWebhook documents an event the API sends to its consumers, with the model of its payload
+// Route options add the rest of the contract: WithSummary, WithTags, WithParameter for signature
+// headers, WithResponse for the statuses consumers should answer, WithRequestExample...
+//
+//	api.DocumentWebhook("order.created", OrderEvent{}, "Sent when an order is placed",
+//		goapi.WithParameter("X-Signature", "header", "string", "HMAC-SHA256 of the body", true))
+//
+// Swagger 2.0 has no webhooks section, so webhooks are published in the x-webhooks extension
+// with the layout of OpenAPI 3.1 webhooks, which ReDoc renders next to the endpoints
+func (a *GoAPI) DocumentWebhook(name string, payloadModel interface{}, description string, opts ...router.RouteOption) {
+	a.webhooks = append(a.webhooks, outboundRoute(name, name, payloadModel, description, opts))
+}
+
+// WithCallback documents a POST request the API sends back to the client after an operation,
+// to the URL given by a runtime expression ("{$request.body#/callbackUrl}")
+// Callbacks are published in the x-callbacks extension of the operation
+func WithCallback(name, expression string, payloadModel interface{}, description string) router.RouteOption {
+	return router.WithCallback(name, expression, payloadModel, description)
+}
+
+// outboundRoute describes a request sent by the API as a route, so it is documented like an endpoint
+func outboundRoute(name, path string, payloadModel interface{}, description string, opts []router.RouteOption) router.Route {
+	validate := false
+	route := router.Route{
+		Method:          http.MethodPost,
+		Path:            path,
+		Summary:         name,
+		Description:     description,
+		ValidateRequest: &validate,
+	}
+	if payloadModel != nil {
+		router.WithRequestBody(payloadModel, description)(&route)
+	}
+	for _, opt := range opts {
+		opt(&route)
+	}
+	if len(route.Responses) == 0 {
+		router.WithResponse(http.StatusOK, "Return a 2xx status to acknowledge the event")(&route)
+	}
+	return route
+}
+
+// addWebhooks publishes the webhooks of the selected specification in a document
+func (a *GoAPI) addWebhooks(document *openapi.Document, selection specSelection, locale string) {
+	webhooks := make(map[string]*openapi.PathItem)
+	for _, webhook := range a.webhooks {
+		if selection.includes(webhook) {
+			pathItem := &openapi.PathItem{}
+			pathItem.SetOperation(webhook.Method, a.buildOperation(webhook, locale))
+			webhooks[webhook.Path] = pathItem
+		}
+	}
+	if len(webhooks) > 0 {
+		document.Extensions.Set("x-webhooks", webhooks)
+	}
+}
+
+// addCallbacks publishes the callbacks of a route in its operation
+func (a *GoAPI) addCallbacks(route router.Route, operation *openapi.Operation, locale string) {
+	if len(route.Callbacks) == 0 {
+		return
+	}
+	callbacks := make(map[string]map[string]*openapi.PathItem, len(route.Callbacks))
+	for _, callback := range route.Callbacks {
+		outbound := outboundRoute(callback.Name, callback.Expression, callback.Payload, callback.Description, nil)
+		outbound.Method = callback.Method
+		pathItem := &openapi.PathItem{}
+		pathItem.SetOperation(outbound.Method, a.buildOperation(outbound, locale))
+		if callbacks[callback.Name] == nil {
+			callbacks[callback.Name] = make(map[string]*openapi.PathItem)
+		}
+		callbacks[callback.Name][callback.Expression] = pathItem
+	}
+	operation.Extensions.Set("x-callbacks", callbacks)
+}
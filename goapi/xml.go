@@ -0,0 +1,78 @@
+package goapi
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/esteban-ll-aguilar/goapi/goapi/i18n"
+	"github.com/esteban-ll-aguilar/goapi/goapi/middleware"
+	"github.com/esteban-ll-aguilar/goapi/goapi/router"
+	"github.com/esteban-ll-aguilar/goapi/goapi/validation"
+)
+
+// XML media types documented by WithXML
+const (
+	MIMEXML     = "application/xml"
+	MIMETextXML = "text/xml"
+)
+
+// BindXML decodes the XML body of a request into target, a pointer to a struct, and validates it
+// with the validator of the request, so validate tags apply as with JSON bodies
+// SOAP envelopes are unwrapped: target receives the first element of their Body. Failures are
+// returned as validation.ValidationErrors, ready for responses.ValidationFailed. On routes with
+// request validation the body decoded by the middleware is reused
+//
+//	var order Order
+//	if err := goapi.BindXML(c, &order); err != nil {
+//		responses.ValidationFailed(c, err)
+//		return
+//	}
+func BindXML(c *gin.Context, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("BindXML: target must be a non-nil pointer")
+	}
+	locale := i18n.Locale(c)
+
+	if validated := reflect.ValueOf(middleware.ValidatedBody(c)); validated.IsValid() && validated.Type() == value.Type() {
+		value.Elem().Set(validated.Elem())
+		return nil
+	}
+
+	body, err := c.GetRawData()
+	if err != nil || validation.DecodeXML(body, target) != nil {
+		return validation.ValidationErrors{{
+			Field:   "body",
+			Tag:     validation.MessageBodyInvalidXML,
+			Message: validation.Message(locale, validation.MessageBodyInvalidXML, nil),
+			In:      "body",
+		}}
+	}
+	if value.Elem().Kind() == reflect.Struct {
+		if err := validation.FromContext(c).ValidateStruct(target); err != nil {
+			return validation.FormatValidationErrorsLocale(err, locale)
+		}
+	}
+	return nil
+}
+
+// WithConsumes documents the media types accepted in the request body
+func WithConsumes(mediaTypes ...string) router.RouteOption {
+	return router.WithConsumes(mediaTypes...)
+}
+
+// WithProduces documents the media types of the response bodies
+func WithProduces(mediaTypes ...string) router.RouteOption {
+	return router.WithProduces(mediaTypes...)
+}
+
+// WithXML documents a route that accepts and returns XML (application/xml and text/xml)
+// The schemas follow the xml struct tags: element names, attributes and wrapped lists
+func WithXML() router.RouteOption {
+	return func(route *router.Route) {
+		router.WithConsumes(MIMEXML, MIMETextXML)(route)
+		router.WithProduces(MIMEXML, MIMETextXML)(route)
+	}
+}